@@ -22,6 +22,7 @@ import (
 type MCPServer struct {
 	contactRepo   repository.ContactRepository
 	userRepo      repository.UserRepository
+	historyRepo   repository.ContactHistoryRepository
 	contactService *services.ContactService
 	bulkService   *services.BulkService
 	analyticsService *services.AnalyticsService
@@ -85,14 +86,16 @@ func main() {
 	db := database.GetDB()
 	contactRepo := repository.NewContactRepository(db)
 	userRepo := repository.NewUserRepository(db)
+	historyRepo := repository.NewContactHistoryRepository(db)
 	
 	contactService := services.NewContactService(contactRepo, userRepo)
-	bulkService := services.NewBulkService(contactRepo, userRepo)
+	bulkService := services.NewBulkService(contactRepo, userRepo, historyRepo, repository.NewValidationRuleRepository(db))
 	analyticsService := services.NewAnalyticsService(db)
 
 	server := &MCPServer{
 		contactRepo:      contactRepo,
 		userRepo:         userRepo,
+		historyRepo:      historyRepo,
 		contactService:   contactService,
 		bulkService:      bulkService,
 		analyticsService: analyticsService,
@@ -534,6 +537,7 @@ func (s *MCPServer) executeUpdateContact(args map[string]interface{}) (ToolResul
 	if err != nil {
 		return ToolResult{IsError: true}, fmt.Errorf("contact not found: %v", err)
 	}
+	before := *contact
 
 	// Update fields if provided
 	if name, ok := args["name"].(string); ok && name != "" {
@@ -562,6 +566,10 @@ func (s *MCPServer) executeUpdateContact(args map[string]interface{}) (ToolResul
 		return ToolResult{IsError: true}, fmt.Errorf("failed to update contact: %v", err)
 	}
 
+	if err := s.historyRepo.RecordChanges(&before, contact, nil, models.ChangeSourceMCP, "Updated via MCP"); err != nil {
+		log.Printf("Failed to record contact history: %v", err)
+	}
+
 	result := fmt.Sprintf("Successfully updated contact: %s (ID: %d)", contact.Name, contact.ID)
 	return ToolResult{
 		Content: []ContentBlock{{Type: "text", Text: result}},
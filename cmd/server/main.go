@@ -3,8 +3,13 @@ package main
 import (
 	"contact-service/internal/handlers"
 	"contact-service/internal/middleware"
+	"contact-service/internal/models"
+	"contact-service/internal/repository"
+	"contact-service/internal/services"
 	"contact-service/pkg/database"
 	"contact-service/pkg/logger"
+	"contact-service/pkg/mailer"
+	"contact-service/pkg/sms"
 	"log"
 	"net/http"
 	"os"
@@ -66,9 +71,138 @@ func main() {
 	router.Use(middleware.CORS())
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler()
 	dashboardHandler := handlers.NewDashboardContactHandler()
 	contactHandler := handlers.NewContactHandler()
+	duplicateHandler := handlers.NewDuplicateHandler()
+	templateHandler := handlers.NewTemplateHandler()
+
+	contactRepo := repository.NewContactRepository(database.DB)
+	historyRepo := repository.NewContactHistoryRepository(database.DB)
+	ruleRepo := repository.NewValidationRuleRepository(database.DB)
+	bulkService := services.NewBulkService(contactRepo, repository.NewUserRepository(database.DB), historyRepo, ruleRepo)
+	importService := services.NewImportJobService(repository.NewImportBatchRepository(database.DB), contactRepo, historyRepo, ruleRepo, services.LoadImportJobConfig())
+	if err := importService.Start(); err != nil {
+		log.Fatal("Failed to start import job runner:", err)
+	}
+	bulkHandler := handlers.NewBulkHandler(bulkService, importService)
+
+	// Background export runner
+	analyticsService := services.NewAnalyticsService(database.DB)
+	exportService := services.NewExportJobService(
+		repository.NewExportJobRepository(database.DB),
+		bulkService,
+		analyticsService,
+		services.LoadExportJobConfig(),
+	)
+	if err := exportService.Start(); err != nil {
+		log.Fatal("Failed to start export job runner:", err)
+	}
+	exportHandler := handlers.NewExportHandler(exportService)
+
+	// Cached analytics with background precompute
+	analyticsCache := services.NewAnalyticsCacheService(database.DB, analyticsService, services.LoadAnalyticsCacheConfig())
+	if err := analyticsCache.Start(); err != nil {
+		log.Fatal("Failed to start analytics cache:", err)
+	}
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsCache, exportService)
+
+	// Outbound email delivery
+	mail, err := mailer.New(mailer.LoadConfig())
+	if err != nil {
+		log.Fatal("Failed to configure mailer:", err)
+	}
+	emailConfig := services.LoadEmailConfig()
+	emailTracker := services.LoadEmailTracker()
+	emailService := services.NewEmailService(
+		repository.NewCommunicationRepository(database.DB),
+		contactRepo,
+		services.NewTemplateService(database.DB),
+		mail,
+		emailTracker,
+		emailConfig,
+	)
+	if err := emailService.Start(); err != nil {
+		log.Fatal("Failed to start email delivery workers:", err)
+	}
+	communicationHandler := handlers.NewCommunicationHandler(emailService)
+
+	// Sessions, two-factor authentication, lockout and password resets
+	sessionService := services.NewSessionService(database.DB, services.LoadSessionConfig())
+	passwordService, err := services.NewPasswordService(database.DB, services.LoadPasswordConfig(), &services.EmailPasswordResetNotifier{
+		Mailer: mail,
+		From:   mailer.Address{Name: emailConfig.FromName, Email: emailConfig.FromEmail},
+	}, sessionService)
+	if err != nil {
+		log.Fatal("Failed to load password policy:", err)
+	}
+	passwordService.Start()
+	twoFactorService := services.NewTwoFactorService(database.DB, services.LoadTwoFactorConfig())
+	if err := twoFactorService.EncryptStoredSecrets(); err != nil {
+		log.Fatal("Failed to encrypt two-factor secrets:", err)
+	}
+	authHandler := handlers.NewAuthHandler(
+		sessionService,
+		twoFactorService,
+		services.NewLoginLockoutService(database.DB, services.LoadLoginLockoutConfig()),
+		passwordService,
+	)
+	permissionService := services.NewPermissionService(database.DB, services.LoadPermissionConfig())
+	models.SetPermissionResolver(permissionService)
+	roleHandler := handlers.NewRoleHandler(permissionService)
+	userHandler := handlers.NewUserHandler(services.NewUserManagementService(
+		database.DB,
+		services.LoadUserInvitationConfig(),
+		&services.EmailUserInvitationNotifier{
+			Mailer: mail,
+			From:   mailer.Address{Name: emailConfig.FromName, Email: emailConfig.FromEmail},
+		},
+		passwordService,
+		sessionService,
+	))
+
+	// Appointment scheduling, recurring series and calendar invites
+	calendarConfig := services.LoadCalendarConfig()
+	schedulingService := services.NewSchedulingService(database.DB)
+	schedulingService.SetCalendarInviter(services.NewCalendarInviter(database.DB, emailService, calendarConfig))
+	schedulingService.StartSeriesMaterializer()
+	schedulingHandler := handlers.NewSchedulingHandler(schedulingService)
+	calendarSyncConfig := services.LoadCalendarSyncConfig()
+	calendarSync := services.NewCalendarSyncService(database.DB, schedulingService, calendarConfig, calendarSyncConfig, map[string]services.CalendarProvider{
+		models.CalendarProviderCalDAV: &services.CalDAVProvider{Timeout: 30 * time.Second, AllowedHosts: calendarSyncConfig.AllowedHosts},
+	})
+	calendarSync.Start()
+	calendarHandler := handlers.NewCalendarHandler(services.NewCalendarService(database.DB, schedulingService, calendarConfig), calendarSync)
+
+	// Appointment reminder dispatch
+	textSender, err := sms.New(sms.LoadConfig())
+	if err != nil {
+		log.Fatal("Failed to configure SMS sender:", err)
+	}
+	reminderDispatcher := services.NewReminderDispatcher(database.DB, map[string]services.ReminderSender{
+		models.ReminderTypeEmail: &services.EmailReminderSender{
+			Mailer: mail,
+			From:   mailer.Address{Name: emailConfig.FromName, Email: emailConfig.FromEmail},
+		},
+		models.ReminderTypeSMS: &services.SMSReminderSender{Sender: textSender},
+	}, services.LoadReminderDispatcherConfig())
+	if err := reminderDispatcher.Start(); err != nil {
+		log.Fatal("Failed to start appointment reminder dispatcher:", err)
+	}
+	bookingHandler := handlers.NewBookingHandler(services.NewBookingService(database.DB, schedulingService, services.LoadBookingConfig()))
+	lifecycleService := services.NewLifecycleService(database.DB)
+	lifecycleService.SetScheduling(schedulingService)
+	trackingHandler := handlers.NewTrackingHandler(
+		services.NewEmailTrackingService(database.DB, emailTracker, lifecycleService),
+	)
+
+	// Scheduled status transitions
+	transitionSweeper := services.NewStatusTransitionSweeper(database.DB, lifecycleService, services.LoadStatusTransitionSweeperConfig())
+	transitionSweeper.Start()
+	lifecycleRulesHandler := handlers.NewLifecycleRulesHandler(transitionSweeper)
+
+	// Periodic rescoring, so scores of contacts that stopped engaging decay
+	services.NewScoreSweeper(database.DB, lifecycleService, services.LoadScoreSweeperConfig()).Start()
+	notificationHandler := handlers.NewNotificationHandler(services.NewNotificationService(database.DB))
 
 	// ===== HEALTH CHECK ENDPOINTS =====
 	router.GET("/health", simpleHealthCheck)
@@ -95,16 +229,239 @@ func main() {
 		{
 			auth.POST("/login", authHandler.Login)
 			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.POST("/password/forgot", authHandler.ForgotPassword)
+			auth.POST("/password/reset", authHandler.ResetPassword)
+			auth.POST("/activate", userHandler.ActivateAccount)
 			auth.POST("/logout", middleware.AuthMiddleware(), authHandler.Logout)
 			auth.GET("/profile", middleware.AuthMiddleware(), authHandler.GetProfile)
 			auth.POST("/change-password", middleware.AuthMiddleware(), authHandler.ChangePassword)
 			auth.GET("/validate", middleware.AuthMiddleware(), authHandler.ValidateToken)
+			auth.GET("/permissions", middleware.AuthMiddleware(), roleHandler.GetMyPermissions)
+			auth.GET("/sessions", middleware.AuthMiddleware(), authHandler.GetSessions)
+			auth.DELETE("/sessions/:id", middleware.AuthMiddleware(), authHandler.RevokeSession)
+
+			// Two-factor authentication
+			auth.POST("/mfa/verify", authHandler.VerifyMFA)
+			auth.GET("/mfa", middleware.AuthMiddleware(), authHandler.GetTwoFactorStatus)
+			auth.POST("/mfa/enroll", middleware.AuthMiddleware(), authHandler.EnrollTwoFactor)
+			auth.POST("/mfa/confirm", middleware.AuthMiddleware(), authHandler.ConfirmTwoFactor)
+			auth.POST("/mfa/recovery-codes", middleware.AuthMiddleware(), authHandler.RegenerateRecoveryCodes)
+			auth.POST("/mfa/disable", middleware.AuthMiddleware(), authHandler.DisableTwoFactor)
+		}
+
+		// User administration routes; changing a user's role or permissions also needs roles:manage
+		users := api.Group("/users")
+		users.Use(middleware.AuthMiddleware(), middleware.RequirePermission("users:manage"))
+		{
+			users.GET("", userHandler.GetUsers)
+			users.POST("/invite", userHandler.InviteUser)
+			users.GET("/two-factor-policies", authHandler.GetTwoFactorPolicies)
+			users.PUT("/two-factor-policies/:role", authHandler.SetTwoFactorPolicy)
+			users.GET("/:id/sessions", authHandler.GetUserSessions)
+			users.DELETE("/:id/sessions", authHandler.RevokeUserSessions)
+			users.POST("/:id/unlock", authHandler.UnlockUser)
+			users.GET("/:id", userHandler.GetUser)
+			users.POST("/:id/invite", userHandler.ResendInvitation)
+			users.PUT("/:id/role", middleware.RequirePermission("roles:manage"), userHandler.UpdateUserRole)
+			users.POST("/:id/deactivate", userHandler.DeactivateUser)
+			users.POST("/:id/reactivate", userHandler.ReactivateUser)
+			users.GET("/:id/permissions", roleHandler.GetUserPermissions)
+			users.PUT("/:id/permissions", middleware.RequirePermission("roles:manage"), roleHandler.SetUserPermission)
+			users.DELETE("/:id/permissions/:permission", middleware.RequirePermission("roles:manage"), roleHandler.DeleteUserPermission)
+		}
+
+		// Role and permission administration routes
+		roles := api.Group("/roles")
+		roles.Use(middleware.AuthMiddleware(), middleware.RequirePermission("roles:manage"))
+		{
+			roles.GET("", roleHandler.GetRoles)
+			roles.POST("", roleHandler.CreateRole)
+			roles.GET("/:name", roleHandler.GetRole)
+			roles.PUT("/:name", roleHandler.UpdateRole)
+			roles.DELETE("/:name", roleHandler.DeleteRole)
 		}
 
 		// Public contact submission endpoints (uses full contacts table with CRM)
 		public := api.Group("/public")
 		{
 			public.POST("/contact", contactHandler.SubmitContact)
+
+			// Self-service booking; bookings are managed through the signed links in the booking response
+			public.GET("/booking/:slug", bookingHandler.GetPublicBookingPage)
+			public.GET("/booking/:slug/slots", bookingHandler.GetPublicSlots)
+			public.POST("/booking/:slug/holds", bookingHandler.HoldSlot)
+			public.POST("/booking/:slug/book", bookingHandler.BookAppointment)
+			public.GET("/bookings/:id/:signature", bookingHandler.GetPublicBooking)
+			public.POST("/bookings/:id/:signature/reschedule", bookingHandler.ReschedulePublicBooking)
+			public.POST("/bookings/:id/:signature/cancel", bookingHandler.CancelPublicBooking)
+
+			// Subscribable calendar feeds (the token in the URL is the credential)
+			public.GET("/calendar/feeds/:token", calendarHandler.ServeFeed)
+		}
+
+		// Public email tracking endpoints (signed links embedded in outgoing email)
+		track := api.Group("/track")
+		{
+			track.GET("/open/:id/:signature", trackingHandler.TrackOpen)
+			track.GET("/click/:id/:signature", trackingHandler.TrackClick)
+		}
+
+		// Contact routes
+		contacts := api.Group("/contacts")
+		contacts.Use(middleware.AuthMiddleware())
+		{
+			contacts.GET("/:id/history", contactHandler.GetContactHistory)
+			contacts.GET("/:id/communications", communicationHandler.GetContactCommunications)
+			contacts.GET("/:id/appointments", schedulingHandler.GetContactAppointments)
+		}
+
+		// Appointment scheduling routes
+		appointments := api.Group("/appointments")
+		appointments.Use(middleware.AuthMiddleware())
+		{
+			appointments.POST("", schedulingHandler.CreateAppointment)
+			appointments.GET("/my", schedulingHandler.GetMyAppointments)
+			appointments.GET("/today", schedulingHandler.GetTodaysAppointments)
+			appointments.GET("/upcoming", schedulingHandler.GetUpcomingAppointments)
+			appointments.GET("/user", schedulingHandler.GetUserAppointments)
+			appointments.POST("/available-slots", schedulingHandler.FindAvailableSlots)
+			appointments.GET("/availability", schedulingHandler.GetUserAvailability)
+			appointments.GET("/series/:id", schedulingHandler.GetAppointmentSeries)
+			appointments.GET("/:id", schedulingHandler.GetAppointment)
+			appointments.PUT("/:id", schedulingHandler.UpdateAppointment)
+			appointments.PUT("/:id/status", schedulingHandler.UpdateAppointmentStatus)
+			appointments.POST("/:id/reschedule", schedulingHandler.RescheduleAppointment)
+			appointments.POST("/:id/cancel", schedulingHandler.CancelAppointment)
+		}
+
+		// Booking page routes
+		bookingPages := api.Group("/booking-pages")
+		bookingPages.Use(middleware.AuthMiddleware())
+		{
+			bookingPages.GET("", bookingHandler.GetBookingPages)
+			bookingPages.GET("/:id", bookingHandler.GetBookingPage)
+			bookingPages.POST("", middleware.RequirePermission("booking_pages:manage"), bookingHandler.CreateBookingPage)
+			bookingPages.PUT("/:id", middleware.RequirePermission("booking_pages:manage"), bookingHandler.UpdateBookingPage)
+		}
+
+		// Calendar feed management routes
+		calendar := api.Group("/calendar")
+		calendar.Use(middleware.AuthMiddleware())
+		{
+			calendar.GET("/feed", calendarHandler.GetFeed)
+			calendar.POST("/feed/rotate", calendarHandler.RotateFeed)
+			calendar.DELETE("/feed", calendarHandler.RevokeFeed)
+			calendar.GET("/integrations", calendarHandler.GetIntegrations)
+			calendar.POST("/integrations", calendarHandler.CreateIntegration)
+			calendar.GET("/integrations/:id", calendarHandler.GetIntegration)
+			calendar.PUT("/integrations/:id", calendarHandler.UpdateIntegration)
+			calendar.DELETE("/integrations/:id", calendarHandler.DeleteIntegration)
+			calendar.POST("/integrations/:id/sync", calendarHandler.SyncIntegration)
+		}
+
+		// Lifecycle rule routes
+		lifecycle := api.Group("/lifecycle")
+		lifecycle.Use(middleware.AuthMiddleware())
+		{
+			lifecycle.POST("/transition-rules/sweep", middleware.RequirePermission("lifecycle:manage"), lifecycleRulesHandler.SweepTransitionRules)
+		}
+
+		// In-app notification routes
+		notifications := api.Group("/notifications")
+		notifications.Use(middleware.AuthMiddleware())
+		{
+			notifications.GET("", notificationHandler.GetNotifications)
+			notifications.POST("/:id/read", notificationHandler.MarkNotificationRead)
+			notifications.POST("/read-all", notificationHandler.MarkAllNotificationsRead)
+		}
+
+		// Working hours and availability exception routes
+		availability := api.Group("/availability")
+		availability.Use(middleware.AuthMiddleware())
+		{
+			availability.GET("/users/:id/schedule", schedulingHandler.GetWeeklySchedule)
+			availability.PUT("/users/:id/schedule", schedulingHandler.SetWeeklySchedule)
+			availability.GET("/users/:id/exceptions", schedulingHandler.GetAvailabilityExceptions)
+			availability.POST("/users/:id/exceptions", schedulingHandler.CreateAvailabilityException)
+			availability.PUT("/exceptions/:id", schedulingHandler.UpdateAvailabilityException)
+			availability.DELETE("/exceptions/:id", schedulingHandler.DeleteAvailabilityException)
+		}
+
+		// Duplicate detection and merge routes
+		duplicates := api.Group("/duplicates")
+		duplicates.Use(middleware.AuthMiddleware())
+		{
+			duplicates.POST("/detect", duplicateHandler.DetectDuplicates)
+			duplicates.GET("/groups", duplicateHandler.GetDuplicateGroups)
+			duplicates.GET("/groups/:id", duplicateHandler.GetDuplicateGroup)
+			duplicates.PUT("/groups/:id/status", middleware.RequirePermission("contacts:merge"), duplicateHandler.UpdateDuplicateGroupStatus)
+			duplicates.POST("/merge", middleware.RequirePermission("contacts:merge"), duplicateHandler.MergeContacts)
+			duplicates.POST("/merges/:id/unmerge", middleware.RequirePermission("contacts:merge"), duplicateHandler.UnmergeContact)
+			duplicates.GET("/contacts/:id/merges", duplicateHandler.GetMergeHistory)
+		}
+
+		// Outbound communication routes
+		communications := api.Group("/communications")
+		communications.Use(middleware.AuthMiddleware())
+		{
+			communications.POST("/email", communicationHandler.SendEmail)
+			communications.GET("/:id", communicationHandler.GetCommunication)
+			communications.POST("/:id/retry", communicationHandler.RetryCommunication)
+		}
+
+		// Communication template routes
+		templates := api.Group("/templates")
+		templates.Use(middleware.AuthMiddleware())
+		{
+			templates.GET("", templateHandler.GetTemplates)
+			templates.POST("", templateHandler.CreateTemplate)
+			templates.GET("/variables", templateHandler.GetTemplateVariables)
+			templates.GET("/:id", templateHandler.GetTemplate)
+			templates.PUT("/:id", templateHandler.UpdateTemplate)
+			templates.DELETE("/:id", templateHandler.DeleteTemplate)
+			templates.POST("/:id/render", templateHandler.RenderTemplate)
+		}
+
+		// Bulk operation and import job routes
+		bulk := api.Group("/bulk")
+		bulk.Use(middleware.AuthMiddleware())
+		{
+			bulk.POST("/contacts/import", bulkHandler.ImportContacts)
+			bulk.GET("/contacts/export", bulkHandler.ExportContacts)
+			bulk.POST("/contacts/update", bulkHandler.BulkUpdateContacts)
+			bulk.POST("/contacts/delete", bulkHandler.BulkDeleteContacts)
+			bulk.GET("/contacts/template", bulkHandler.GetImportTemplate)
+			bulk.GET("/contacts/status", bulkHandler.GetBulkOperationStatus)
+			bulk.GET("/imports", bulkHandler.ListImportJobs)
+			bulk.GET("/imports/:id", bulkHandler.GetImportJob)
+			bulk.POST("/imports/:id/cancel", bulkHandler.CancelImportJob)
+			bulk.GET("/imports/:id/failed-records", bulkHandler.DownloadFailedRecords)
+		}
+
+		// Background export routes
+		exports := api.Group("/exports")
+		exports.Use(middleware.AuthMiddleware())
+		{
+			exports.POST("", exportHandler.CreateExport)
+			exports.GET("", exportHandler.GetExports)
+			exports.GET("/:id", exportHandler.GetExport)
+			exports.GET("/:id/download", exportHandler.DownloadExport)
+		}
+
+		// Analytics routes
+		analytics := api.Group("/analytics")
+		analytics.Use(middleware.AuthMiddleware())
+		{
+			analytics.GET("/contacts", analyticsHandler.GetContactAnalytics)
+			analytics.GET("/appointments", analyticsHandler.GetAppointmentAnalytics)
+			analytics.GET("/performance", analyticsHandler.GetUserPerformanceAnalytics)
+			analytics.GET("/conversion", analyticsHandler.GetConversionMetrics)
+			analytics.GET("/response-times", analyticsHandler.GetResponseTimeMetrics)
+			analytics.GET("/sources", analyticsHandler.GetSourceAnalytics)
+			analytics.GET("/realtime", analyticsHandler.GetRealtimeMetrics)
+			analytics.GET("/dashboard", analyticsHandler.GetDashboardSummary)
+			analytics.GET("/business-intelligence", analyticsHandler.GetBusinessIntelligence)
+			analytics.GET("/export", analyticsHandler.GetAnalyticsExport)
 		}
 
 		// Test endpoint
@@ -152,13 +509,150 @@ func main() {
 	log.Printf("    POST /api/v1/dashboard/contacts/bulk-update - Bulk update")
 	log.Printf("  AUTH ENDPOINTS:")
 	log.Printf("    POST /api/v1/auth/login - Login")
-	log.Printf("    POST /api/v1/auth/refresh - Refresh token")
+	log.Printf("    POST /api/v1/auth/refresh - Refresh token (single use, rotated)")
+	log.Printf("    POST /api/v1/auth/password/forgot - Email a password reset link")
+	log.Printf("    POST /api/v1/auth/password/reset - Reset password with a reset token")
+	log.Printf("    POST /api/v1/auth/activate - Activate an invited account")
 	log.Printf("    POST /api/v1/auth/logout - Logout")
 	log.Printf("    GET  /api/v1/auth/profile - Get profile")
 	log.Printf("    POST /api/v1/auth/change-password - Change password")
 	log.Printf("    GET  /api/v1/auth/validate - Validate token")
+	log.Printf("    GET  /api/v1/auth/permissions - My permissions")
+	log.Printf("    GET  /api/v1/auth/sessions - My active sessions")
+	log.Printf("    DELETE /api/v1/auth/sessions/:id - Revoke one of my sessions")
+	log.Printf("    POST /api/v1/auth/mfa/verify - Complete a two-factor login")
+	log.Printf("    GET  /api/v1/auth/mfa - My two-factor status")
+	log.Printf("    POST /api/v1/auth/mfa/enroll - Start two-factor enrollment")
+	log.Printf("    POST /api/v1/auth/mfa/confirm - Confirm enrollment (returns recovery codes)")
+	log.Printf("    POST /api/v1/auth/mfa/recovery-codes - Regenerate recovery codes")
+	log.Printf("    POST /api/v1/auth/mfa/disable - Disable two-factor authentication")
+	log.Printf("  USER ADMINISTRATION ENDPOINTS (admin):")
+	log.Printf("    GET  /api/v1/users - List users (?role=&active=&search=)")
+	log.Printf("    POST /api/v1/users/invite - Invite a user (emails an activation link)")
+	log.Printf("    GET  /api/v1/users/:id - Get user")
+	log.Printf("    POST /api/v1/users/:id/invite - Resend an invitation")
+	log.Printf("    PUT  /api/v1/users/:id/role - Change a user's role")
+	log.Printf("    POST /api/v1/users/:id/deactivate - Deactivate a user (reassigns or unassigns open contacts)")
+	log.Printf("    POST /api/v1/users/:id/reactivate - Reactivate a user")
+	log.Printf("    GET  /api/v1/users/:id/permissions - A user's role permissions and overrides")
+	log.Printf("    PUT  /api/v1/users/:id/permissions - Grant or deny a permission to a user")
+	log.Printf("    DELETE /api/v1/users/:id/permissions/:permission - Remove a permission override")
+	log.Printf("  ROLE ENDPOINTS (admin):")
+	log.Printf("    GET  /api/v1/roles - Roles with their permissions")
+	log.Printf("    POST /api/v1/roles - Create a custom role")
+	log.Printf("    GET  /api/v1/roles/:name - Get role")
+	log.Printf("    PUT  /api/v1/roles/:name - Update a role's description or permissions")
+	log.Printf("    DELETE /api/v1/roles/:name - Delete an unused custom role")
+	log.Printf("    GET  /api/v1/users/two-factor-policies - Two-factor policies by role")
+	log.Printf("    PUT  /api/v1/users/two-factor-policies/:role - Require two-factor for a role")
+	log.Printf("    GET  /api/v1/users/:id/sessions - A user's active sessions")
+	log.Printf("    DELETE /api/v1/users/:id/sessions - Revoke all sessions of a user")
+	log.Printf("    POST /api/v1/users/:id/unlock - Unlock a locked-out user")
+	log.Printf("  CONTACT ENDPOINTS:")
+	log.Printf("    GET  /api/v1/contacts/:id/history - Contact field history (?at= for point-in-time view)")
+	log.Printf("    GET  /api/v1/contacts/:id/communications - Contact communications")
+	log.Printf("    GET  /api/v1/contacts/:id/appointments - Contact appointments")
+	log.Printf("  APPOINTMENT ENDPOINTS:")
+	log.Printf("    POST /api/v1/appointments - Create appointment (recurrence.rrule creates a series)")
+	log.Printf("    GET  /api/v1/appointments/my - My appointments")
+	log.Printf("    GET  /api/v1/appointments/today - Today's appointments")
+	log.Printf("    GET  /api/v1/appointments/upcoming - Upcoming appointments")
+	log.Printf("    GET  /api/v1/appointments/user - User appointments")
+	log.Printf("    POST /api/v1/appointments/available-slots - Find free slots from working hours and exceptions")
+	log.Printf("    GET  /api/v1/appointments/availability - User availability on a date")
+	log.Printf("    GET  /api/v1/appointments/series/:id - Get recurring series")
+	log.Printf("    GET  /api/v1/appointments/:id - Get appointment")
+	log.Printf("    PUT  /api/v1/appointments/:id - Update appointment (?scope=this|following|all)")
+	log.Printf("    PUT  /api/v1/appointments/:id/status - Update appointment status")
+	log.Printf("    POST /api/v1/appointments/:id/reschedule - Reschedule appointment")
+	log.Printf("    POST /api/v1/appointments/:id/cancel - Cancel appointment (?scope=this|following|all)")
+	log.Printf("  BOOKING PAGE ENDPOINTS:")
+	log.Printf("    GET  /api/v1/booking-pages - List booking pages")
+	log.Printf("    POST /api/v1/booking-pages - Create booking page")
+	log.Printf("    GET  /api/v1/booking-pages/:id - Get booking page")
+	log.Printf("    PUT  /api/v1/booking-pages/:id - Update booking page")
+	log.Printf("  CALENDAR ENDPOINTS:")
+	log.Printf("    GET  /api/v1/calendar/feed - Get my .ics feed URL")
+	log.Printf("    POST /api/v1/calendar/feed/rotate - Rotate my feed token")
+	log.Printf("    DELETE /api/v1/calendar/feed - Revoke my feed token")
+	log.Printf("    GET  /api/v1/calendar/integrations - List my external calendars")
+	log.Printf("    POST /api/v1/calendar/integrations - Connect a CalDAV calendar")
+	log.Printf("    GET  /api/v1/calendar/integrations/:id - Get integration and sync status")
+	log.Printf("    PUT  /api/v1/calendar/integrations/:id - Update integration")
+	log.Printf("    DELETE /api/v1/calendar/integrations/:id - Disconnect calendar")
+	log.Printf("    POST /api/v1/calendar/integrations/:id/sync - Sync now")
+	log.Printf("  LIFECYCLE ENDPOINTS:")
+	log.Printf("    POST /api/v1/lifecycle/transition-rules/sweep - Run scheduled status transitions (?dry_run=false to apply)")
+	log.Printf("  NOTIFICATION ENDPOINTS:")
+	log.Printf("    GET  /api/v1/notifications - List my notifications (?unread=true)")
+	log.Printf("    POST /api/v1/notifications/:id/read - Mark a notification read")
+	log.Printf("    POST /api/v1/notifications/read-all - Mark all notifications read")
+	log.Printf("  AVAILABILITY ENDPOINTS:")
+	log.Printf("    GET  /api/v1/availability/users/:id/schedule - Get weekly working hours")
+	log.Printf("    PUT  /api/v1/availability/users/:id/schedule - Replace weekly working hours")
+	log.Printf("    GET  /api/v1/availability/users/:id/exceptions - List availability exceptions")
+	log.Printf("    POST /api/v1/availability/users/:id/exceptions - Create availability exception")
+	log.Printf("    PUT  /api/v1/availability/exceptions/:id - Update availability exception")
+	log.Printf("    DELETE /api/v1/availability/exceptions/:id - Delete availability exception")
+	log.Printf("  DUPLICATE ENDPOINTS:")
+	log.Printf("    POST /api/v1/duplicates/detect - Detect duplicate contacts")
+	log.Printf("    GET  /api/v1/duplicates/groups - List duplicate groups")
+	log.Printf("    GET  /api/v1/duplicates/groups/:id - Get duplicate group")
+	log.Printf("    PUT  /api/v1/duplicates/groups/:id/status - Update duplicate group status")
+	log.Printf("    POST /api/v1/duplicates/merge - Merge duplicate contacts")
+	log.Printf("    POST /api/v1/duplicates/merges/:id/unmerge - Undo a contact merge")
+	log.Printf("    GET  /api/v1/duplicates/contacts/:id/merges - Contact merge history")
+	log.Printf("  COMMUNICATION ENDPOINTS:")
+	log.Printf("    POST /api/v1/communications/email - Queue an email to a contact")
+	log.Printf("    GET  /api/v1/communications/:id - Get communication delivery status")
+	log.Printf("    POST /api/v1/communications/:id/retry - Retry a failed email")
+	log.Printf("  TEMPLATE ENDPOINTS:")
+	log.Printf("    GET  /api/v1/templates - List communication templates")
+	log.Printf("    POST /api/v1/templates - Create template")
+	log.Printf("    GET  /api/v1/templates/variables - Available template variables")
+	log.Printf("    GET  /api/v1/templates/:id - Get template")
+	log.Printf("    PUT  /api/v1/templates/:id - Update template")
+	log.Printf("    DELETE /api/v1/templates/:id - Delete template")
+	log.Printf("    POST /api/v1/templates/:id/render - Render template for a contact")
+	log.Printf("  BULK ENDPOINTS:")
+	log.Printf("    POST /api/v1/bulk/contacts/import - Start background CSV import")
+	log.Printf("    GET  /api/v1/bulk/contacts/export - Export contacts to CSV")
+	log.Printf("    POST /api/v1/bulk/contacts/update - Bulk update contacts")
+	log.Printf("    POST /api/v1/bulk/contacts/delete - Bulk delete contacts")
+	log.Printf("    GET  /api/v1/bulk/contacts/template - Import template")
+	log.Printf("    GET  /api/v1/bulk/contacts/status - Bulk operation status")
+	log.Printf("    GET  /api/v1/bulk/imports - List import jobs")
+	log.Printf("    GET  /api/v1/bulk/imports/:id - Get import job progress")
+	log.Printf("    POST /api/v1/bulk/imports/:id/cancel - Cancel import job")
+	log.Printf("    GET  /api/v1/bulk/imports/:id/failed-records - Download failed rows as CSV")
+	log.Printf("  EXPORT ENDPOINTS:")
+	log.Printf("    POST /api/v1/exports - Queue background export")
+	log.Printf("    GET  /api/v1/exports - List export jobs")
+	log.Printf("    GET  /api/v1/exports/:id - Get export job status")
+	log.Printf("    GET  /api/v1/exports/:id/download - Download export file")
+	log.Printf("  ANALYTICS ENDPOINTS:")
+	log.Printf("    GET  /api/v1/analytics/contacts - Contact analytics (cached)")
+	log.Printf("    GET  /api/v1/analytics/appointments - Appointment analytics (cached)")
+	log.Printf("    GET  /api/v1/analytics/performance - User performance (cached)")
+	log.Printf("    GET  /api/v1/analytics/conversion - Conversion metrics (cached)")
+	log.Printf("    GET  /api/v1/analytics/response-times - Response time metrics (cached)")
+	log.Printf("    GET  /api/v1/analytics/sources - Source analytics (cached)")
+	log.Printf("    GET  /api/v1/analytics/realtime - Realtime metrics")
+	log.Printf("    GET  /api/v1/analytics/dashboard - Dashboard summary")
+	log.Printf("    GET  /api/v1/analytics/business-intelligence - Business intelligence (cached)")
+	log.Printf("    GET  /api/v1/analytics/export - Queue analytics export")
 	log.Printf("  OTHER ENDPOINTS:")
 	log.Printf("    POST /api/v1/public/contact - Public contact submission")
+	log.Printf("    GET  /api/v1/public/booking/:slug - Public booking page")
+	log.Printf("    GET  /api/v1/public/booking/:slug/slots - Open slots across the page's hosts")
+	log.Printf("    POST /api/v1/public/booking/:slug/holds - Hold a slot")
+	log.Printf("    POST /api/v1/public/booking/:slug/book - Book an appointment")
+	log.Printf("    GET  /api/v1/public/bookings/:id/:signature - View booking (signed link)")
+	log.Printf("    POST /api/v1/public/bookings/:id/:signature/reschedule - Reschedule booking (signed link)")
+	log.Printf("    POST /api/v1/public/bookings/:id/:signature/cancel - Cancel booking (signed link)")
+	log.Printf("    GET  /api/v1/public/calendar/feeds/:token - Subscribable .ics feed")
+	log.Printf("    GET  /api/v1/track/open/:id/:signature - Email open tracking pixel")
+	log.Printf("    GET  /api/v1/track/click/:id/:signature - Email click tracking redirect")
 	log.Printf("    GET  /api/v1/test - Test endpoint")
 	
 	if err := router.Run(":" + port); err != nil {
@@ -3,7 +3,6 @@ package handlers
 import (
 	"contact-service/internal/models"
 	"contact-service/internal/services"
-	"contact-service/pkg/database"
 	"contact-service/pkg/logger"
 	"fmt"
 	"net/http"
@@ -14,15 +13,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// AnalyticsHandler handles analytics and metrics requests
+// AnalyticsHandler handles analytics and metrics requests. Analytics are served through the
+// analytics cache; the response meta says whether they came from the cache and how old they are.
 type AnalyticsHandler struct {
-	analyticsService *services.AnalyticsService
+	analyticsCache *services.AnalyticsCacheService
+	exportService  *services.ExportJobService
 }
 
 // NewAnalyticsHandler creates a new analytics handler
-func NewAnalyticsHandler() *AnalyticsHandler {
+func NewAnalyticsHandler(analyticsCache *services.AnalyticsCacheService, exportService *services.ExportJobService) *AnalyticsHandler {
 	return &AnalyticsHandler{
-		analyticsService: services.NewAnalyticsService(database.DB),
+		analyticsCache: analyticsCache,
+		exportService:  exportService,
 	}
 }
 
@@ -38,6 +40,8 @@ func NewAnalyticsHandler() *AnalyticsHandler {
 // @Param sources query string false "Comma-separated sources to filter"
 // @Param granularity query string false "Data granularity (day, week, month)" default(day)
 // @Success 200 {object} APIResponse{data=models.ContactMetricsResponse}
+// @Header 200 {string} X-Cache "HIT when served from the analytics cache, MISS when computed"
+// @Header 200 {int} Age "Seconds since the analytics were computed"
 // @Failure 400 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Security BearerAuth
@@ -49,7 +53,7 @@ func (h *AnalyticsHandler) GetContactAnalytics(c *gin.Context) {
 		return
 	}
 
-	analytics, err := h.analyticsService.GetContactAnalytics(request)
+	analytics, cacheInfo, err := h.analyticsCache.GetContactAnalytics(request)
 	if err != nil {
 		logger.Error("Failed to get contact analytics", err, map[string]interface{}{
 			"start_date": request.StartDate,
@@ -59,7 +63,7 @@ func (h *AnalyticsHandler) GetContactAnalytics(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, NewSuccessResponse("Contact analytics retrieved successfully", analytics))
+	respondWithCachedAnalytics(c, "Contact analytics retrieved successfully", analytics, cacheInfo)
 }
 
 // GetAppointmentAnalytics godoc
@@ -73,6 +77,8 @@ func (h *AnalyticsHandler) GetContactAnalytics(c *gin.Context) {
 // @Param user_ids query string false "Comma-separated user IDs to filter"
 // @Param granularity query string false "Data granularity (day, week, month)" default(day)
 // @Success 200 {object} APIResponse{data=models.AppointmentMetricsResponse}
+// @Header 200 {string} X-Cache "HIT when served from the analytics cache, MISS when computed"
+// @Header 200 {int} Age "Seconds since the analytics were computed"
 // @Failure 400 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Security BearerAuth
@@ -84,7 +90,7 @@ func (h *AnalyticsHandler) GetAppointmentAnalytics(c *gin.Context) {
 		return
 	}
 
-	analytics, err := h.analyticsService.GetAppointmentAnalytics(request)
+	analytics, cacheInfo, err := h.analyticsCache.GetAppointmentAnalytics(request)
 	if err != nil {
 		logger.Error("Failed to get appointment analytics", err, map[string]interface{}{
 			"start_date": request.StartDate,
@@ -94,7 +100,7 @@ func (h *AnalyticsHandler) GetAppointmentAnalytics(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, NewSuccessResponse("Appointment analytics retrieved successfully", analytics))
+	respondWithCachedAnalytics(c, "Appointment analytics retrieved successfully", analytics, cacheInfo)
 }
 
 // GetUserPerformanceAnalytics godoc
@@ -107,6 +113,8 @@ func (h *AnalyticsHandler) GetAppointmentAnalytics(c *gin.Context) {
 // @Param end_date query string true "End date (YYYY-MM-DD)"
 // @Param user_ids query string false "Comma-separated user IDs to analyze"
 // @Success 200 {object} APIResponse{data=models.UserPerformanceResponse}
+// @Header 200 {string} X-Cache "HIT when served from the analytics cache, MISS when computed"
+// @Header 200 {int} Age "Seconds since the analytics were computed"
 // @Failure 400 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Security BearerAuth
@@ -118,7 +126,7 @@ func (h *AnalyticsHandler) GetUserPerformanceAnalytics(c *gin.Context) {
 		return
 	}
 
-	analytics, err := h.analyticsService.GetUserPerformanceAnalytics(request)
+	analytics, cacheInfo, err := h.analyticsCache.GetUserPerformanceAnalytics(request)
 	if err != nil {
 		logger.Error("Failed to get user performance analytics", err, map[string]interface{}{
 			"start_date": request.StartDate,
@@ -129,7 +137,7 @@ func (h *AnalyticsHandler) GetUserPerformanceAnalytics(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, NewSuccessResponse("User performance analytics retrieved successfully", analytics))
+	respondWithCachedAnalytics(c, "User performance analytics retrieved successfully", analytics, cacheInfo)
 }
 
 // GetConversionMetrics godoc
@@ -143,6 +151,8 @@ func (h *AnalyticsHandler) GetUserPerformanceAnalytics(c *gin.Context) {
 // @Param sources query string false "Comma-separated sources to filter"
 // @Param granularity query string false "Data granularity (day, week, month)" default(day)
 // @Success 200 {object} APIResponse{data=models.ConversionMetricsResponse}
+// @Header 200 {string} X-Cache "HIT when served from the analytics cache, MISS when computed"
+// @Header 200 {int} Age "Seconds since the analytics were computed"
 // @Failure 400 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Security BearerAuth
@@ -154,7 +164,7 @@ func (h *AnalyticsHandler) GetConversionMetrics(c *gin.Context) {
 		return
 	}
 
-	metrics, err := h.analyticsService.GetConversionMetrics(request)
+	metrics, cacheInfo, err := h.analyticsCache.GetConversionMetrics(request)
 	if err != nil {
 		logger.Error("Failed to get conversion metrics", err, map[string]interface{}{
 			"start_date": request.StartDate,
@@ -164,7 +174,7 @@ func (h *AnalyticsHandler) GetConversionMetrics(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, NewSuccessResponse("Conversion metrics retrieved successfully", metrics))
+	respondWithCachedAnalytics(c, "Conversion metrics retrieved successfully", metrics, cacheInfo)
 }
 
 // GetResponseTimeMetrics godoc
@@ -178,6 +188,8 @@ func (h *AnalyticsHandler) GetConversionMetrics(c *gin.Context) {
 // @Param user_ids query string false "Comma-separated user IDs to filter"
 // @Param granularity query string false "Data granularity (day, week, month)" default(day)
 // @Success 200 {object} APIResponse{data=models.ResponseTimeMetricsResponse}
+// @Header 200 {string} X-Cache "HIT when served from the analytics cache, MISS when computed"
+// @Header 200 {int} Age "Seconds since the analytics were computed"
 // @Failure 400 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Security BearerAuth
@@ -189,7 +201,7 @@ func (h *AnalyticsHandler) GetResponseTimeMetrics(c *gin.Context) {
 		return
 	}
 
-	metrics, err := h.analyticsService.GetResponseTimeMetrics(request)
+	metrics, cacheInfo, err := h.analyticsCache.GetResponseTimeMetrics(request)
 	if err != nil {
 		logger.Error("Failed to get response time metrics", err, map[string]interface{}{
 			"start_date": request.StartDate,
@@ -199,7 +211,7 @@ func (h *AnalyticsHandler) GetResponseTimeMetrics(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, NewSuccessResponse("Response time metrics retrieved successfully", metrics))
+	respondWithCachedAnalytics(c, "Response time metrics retrieved successfully", metrics, cacheInfo)
 }
 
 // GetSourceAnalytics godoc
@@ -212,6 +224,8 @@ func (h *AnalyticsHandler) GetResponseTimeMetrics(c *gin.Context) {
 // @Param end_date query string true "End date (YYYY-MM-DD)"
 // @Param sources query string false "Comma-separated sources to analyze"
 // @Success 200 {object} APIResponse{data=[]models.SourceMetric}
+// @Header 200 {string} X-Cache "HIT when served from the analytics cache, MISS when computed"
+// @Header 200 {int} Age "Seconds since the analytics were computed"
 // @Failure 400 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Security BearerAuth
@@ -224,7 +238,7 @@ func (h *AnalyticsHandler) GetSourceAnalytics(c *gin.Context) {
 	}
 
 	// Get contact analytics which includes source metrics
-	contactAnalytics, err := h.analyticsService.GetContactAnalytics(request)
+	contactAnalytics, cacheInfo, err := h.analyticsCache.GetContactAnalytics(request)
 	if err != nil {
 		logger.Error("Failed to get source analytics", err, map[string]interface{}{
 			"start_date": request.StartDate,
@@ -234,7 +248,7 @@ func (h *AnalyticsHandler) GetSourceAnalytics(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, NewSuccessResponse("Source analytics retrieved successfully", contactAnalytics.Analytics.TopSources))
+	respondWithCachedAnalytics(c, "Source analytics retrieved successfully", contactAnalytics.Analytics.TopSources, cacheInfo)
 }
 
 // GetRealtimeMetrics godoc
@@ -248,7 +262,7 @@ func (h *AnalyticsHandler) GetSourceAnalytics(c *gin.Context) {
 // @Security BearerAuth
 // @Router /analytics/realtime [get]
 func (h *AnalyticsHandler) GetRealtimeMetrics(c *gin.Context) {
-	metrics, err := h.analyticsService.GetRealtimeMetrics()
+	metrics, err := h.analyticsCache.GetRealtimeMetrics()
 	if err != nil {
 		logger.Error("Failed to get realtime metrics", err, nil)
 		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get realtime metrics", err.Error()))
@@ -266,6 +280,8 @@ func (h *AnalyticsHandler) GetRealtimeMetrics(c *gin.Context) {
 // @Produce json
 // @Param period query string false "Period for metrics (today, week, month, quarter, year)" default(month)
 // @Success 200 {object} APIResponse{data=models.QuickStatsSnapshot}
+// @Header 200 {string} X-Cache "HIT when served from the analytics cache, MISS when computed"
+// @Header 200 {int} Age "Seconds since the analytics were computed"
 // @Failure 400 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Security BearerAuth
@@ -302,7 +318,7 @@ func (h *AnalyticsHandler) GetDashboardSummary(c *gin.Context) {
 	}
 
 	// Get realtime metrics which includes quick stats
-	realtimeMetrics, err := h.analyticsService.GetRealtimeMetrics()
+	realtimeMetrics, err := h.analyticsCache.GetRealtimeMetrics()
 	if err != nil {
 		logger.Error("Failed to get dashboard summary", err, map[string]interface{}{
 			"period": period,
@@ -312,12 +328,14 @@ func (h *AnalyticsHandler) GetDashboardSummary(c *gin.Context) {
 	}
 
 	// Enhance quick stats with period-specific data
-	contactAnalytics, err := h.analyticsService.GetContactAnalytics(request)
-	if err == nil {
-		realtimeMetrics.QuickStats.ConversionRate = contactAnalytics.Analytics.ConversionRate
+	contactAnalytics, cacheInfo, err := h.analyticsCache.GetContactAnalytics(request)
+	if err != nil {
+		c.JSON(http.StatusOK, NewSuccessResponse("Dashboard summary retrieved successfully", realtimeMetrics.QuickStats))
+		return
 	}
+	realtimeMetrics.QuickStats.ConversionRate = contactAnalytics.Analytics.ConversionRate
 
-	c.JSON(http.StatusOK, NewSuccessResponse("Dashboard summary retrieved successfully", realtimeMetrics.QuickStats))
+	respondWithCachedAnalytics(c, "Dashboard summary retrieved successfully", realtimeMetrics.QuickStats, cacheInfo)
 }
 
 // GetBusinessIntelligence godoc
@@ -329,6 +347,8 @@ func (h *AnalyticsHandler) GetDashboardSummary(c *gin.Context) {
 // @Param start_date query string true "Start date (YYYY-MM-DD)"
 // @Param end_date query string true "End date (YYYY-MM-DD)"
 // @Success 200 {object} APIResponse{data=models.BusinessIntelligenceResponse}
+// @Header 200 {string} X-Cache "HIT when served from the analytics cache, MISS when computed"
+// @Header 200 {int} Age "Seconds since the analytics were computed"
 // @Failure 400 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Security BearerAuth
@@ -340,68 +360,14 @@ func (h *AnalyticsHandler) GetBusinessIntelligence(c *gin.Context) {
 		return
 	}
 
-	// Build business intelligence from multiple analytics
-	intelligence := &models.BusinessIntelligence{}
-	intelligence.Period = fmt.Sprintf("%s to %s", request.StartDate.Format("2006-01-02"), request.EndDate.Format("2006-01-02"))
-
-	// Get contact analytics
-	contactAnalytics, err := h.analyticsService.GetContactAnalytics(request)
-	if err == nil {
-		intelligence.LeadConversionRate = contactAnalytics.Analytics.ConversionRate
-		intelligence.AverageLeadValue = 1000.0 // TODO: Calculate from actual deal values
-	}
-
-	// Get user performance analytics
-	userPerformance, err := h.analyticsService.GetUserPerformanceAnalytics(request)
-	if err == nil {
-		intelligence.TeamProductivity = float64(userPerformance.TeamAverage.ActivityScore) / 100.0 * 100
-		
-		// Build top performing users
-		topUsers := make([]models.UserPerformanceMetric, 0)
-		for _, user := range userPerformance.Users {
-			if len(topUsers) < 5 { // Top 5 performers
-				topUsers = append(topUsers, models.UserPerformanceMetric{
-					UserID:         user.UserID,
-					Username:       user.Username,
-					FullName:       user.FullName,
-					Score:          user.ActivityScore,
-					ConversionRate: user.ConversionRate,
-					Revenue:        0, // TODO: Calculate from deals
-				})
-			}
-		}
-		intelligence.TopPerformingUsers = topUsers
-	}
-
-	// Get conversion metrics
-	conversionMetrics, err := h.analyticsService.GetConversionMetrics(request)
-	if err == nil {
-		intelligence.ConversionFunnel = conversionMetrics.ConversionFunnel
-		
-		// Build revenue by source (placeholder values)
-		revenueBySource := make([]models.RevenueSourceMetric, 0)
-		for _, source := range conversionMetrics.BySource {
-			revenueBySource = append(revenueBySource, models.RevenueSourceMetric{
-				Source:   source.Source,
-				Revenue:  float64(source.Count) * 1000, // Placeholder calculation
-				Count:    source.Count,
-				AvgValue: 1000.0,
-				Growth:   5.0, // Placeholder
-			})
-		}
-		intelligence.RevenueBySource = revenueBySource
-	}
-
-	// Build KPI summary
-	intelligence.KPISummary = models.KPISummary{
-		TotalLeads:         contactAnalytics.Analytics.TotalContacts,
-		QualifiedLeads:     contactAnalytics.Analytics.ActiveContacts,
-		ConvertedLeads:     contactAnalytics.Analytics.ConvertedContacts,
-		LeadConversionRate: intelligence.LeadConversionRate,
-		AverageLeadValue:   intelligence.AverageLeadValue,
-		TotalRevenue:       float64(contactAnalytics.Analytics.ConvertedContacts) * intelligence.AverageLeadValue,
-		RevenueGrowth:      contactAnalytics.Analytics.GrowthRate,
-		TeamProductivity:   intelligence.TeamProductivity,
+	intelligence, cacheInfo, err := h.analyticsCache.GetBusinessIntelligence(request)
+	if err != nil {
+		logger.Error("Failed to get business intelligence", err, map[string]interface{}{
+			"start_date": request.StartDate,
+			"end_date":   request.EndDate,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get business intelligence", err.Error()))
+		return
 	}
 
 	response := &models.BusinessIntelligenceResponse{
@@ -409,21 +375,22 @@ func (h *AnalyticsHandler) GetBusinessIntelligence(c *gin.Context) {
 		Intelligence: *intelligence,
 	}
 
-	c.JSON(http.StatusOK, NewSuccessResponse("Business intelligence retrieved successfully", response))
+	respondWithCachedAnalytics(c, "Business intelligence retrieved successfully", response, cacheInfo)
 }
 
 // GetAnalyticsExport godoc
 // @Summary Export analytics data
-// @Description Export analytics data in various formats (CSV, Excel, PDF)
+// @Description Queue a background export of analytics data. Download the file from the returned download URL once completed.
 // @Tags analytics
 // @Accept json
 // @Produce json
 // @Param start_date query string true "Start date (YYYY-MM-DD)"
 // @Param end_date query string true "End date (YYYY-MM-DD)"
-// @Param format query string true "Export format (csv, excel, pdf)"
-// @Param type query string true "Analytics type (contacts, appointments, performance, conversion)"
-// @Success 200 {object} APIResponse{data=map[string]interface{}}
+// @Param format query string true "Export format (csv, json, xlsx, pdf). PDF is only available for business_intelligence."
+// @Param type query string true "Analytics type (contacts, appointments, performance, conversion, response_time, business_intelligence)"
+// @Success 202 {object} APIResponse{data=models.ExportJob}
 // @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
 // @Failure 500 {object} APIResponse
 // @Security BearerAuth
 // @Router /analytics/export [get]
@@ -442,30 +409,64 @@ func (h *AnalyticsHandler) GetAnalyticsExport(c *gin.Context) {
 		return
 	}
 
-	// TODO: Implement actual export functionality
-	// For now, return a placeholder response
-	exportData := map[string]interface{}{
-		"export_id":   "exp_" + strconv.FormatInt(time.Now().Unix(), 10),
-		"format":      format,
-		"type":        analyticsType,
-		"period":      fmt.Sprintf("%s to %s", request.StartDate.Format("2006-01-02"), request.EndDate.Format("2006-01-02")),
-		"status":      "processing",
-		"download_url": fmt.Sprintf("/api/v1/analytics/export/download?id=exp_%d", time.Now().Unix()),
-		"expires_at":  time.Now().Add(24 * time.Hour),
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	job, err := h.exportService.CreateJob(models.ExportJobRequest{
+		JobType:      models.ExportJobTypeAnalytics,
+		ExportFormat: format,
+		StartDate:    request.StartDate.Format("2006-01-02"),
+		EndDate:      request.EndDate.Format("2006-01-02"),
+		Filters: map[string]interface{}{
+			"type":        analyticsType,
+			"granularity": request.Granularity,
+		},
+		UserIDs: request.UserIDs,
+	}, *userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "unsupported") || strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid export request", err.Error()))
+			return
+		}
+		logger.Error("Failed to queue analytics export", err, map[string]interface{}{
+			"format":         format,
+			"analytics_type": analyticsType,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to queue export", err.Error()))
+		return
 	}
 
 	logger.Info("Analytics export requested", map[string]interface{}{
+		"export_id":      job.ExportID,
 		"format":         format,
 		"analytics_type": analyticsType,
 		"start_date":     request.StartDate,
 		"end_date":       request.EndDate,
 	})
 
-	c.JSON(http.StatusOK, NewSuccessResponse("Export initiated successfully", exportData))
+	c.JSON(http.StatusAccepted, NewSuccessResponse("Export initiated successfully", job))
 }
 
 // Helper methods
 
+// respondWithCachedAnalytics reports in the response meta, and in the X-Cache and Age headers,
+// whether the analytics came from the cache and how old they are
+func respondWithCachedAnalytics(c *gin.Context, message string, data interface{}, cacheInfo *models.AnalyticsCacheInfo) {
+	if cacheInfo.Cached {
+		c.Header("X-Cache", "HIT")
+	} else {
+		c.Header("X-Cache", "MISS")
+	}
+	c.Header("Age", strconv.FormatInt(cacheInfo.AgeSeconds, 10))
+
+	response := NewSuccessResponse(message, data)
+	response.Meta = gin.H{"cache": cacheInfo}
+	c.JSON(http.StatusOK, response)
+}
+
 // parseAnalyticsRequest parses and validates analytics request parameters
 func (h *AnalyticsHandler) parseAnalyticsRequest(c *gin.Context) (*models.AnalyticsRequest, error) {
 	request := &models.AnalyticsRequest{}
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"contact-service/internal/models"
+	"contact-service/internal/services"
 	"contact-service/pkg/auth"
 	"contact-service/pkg/database"
 	"contact-service/pkg/logger"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,19 +18,27 @@ import (
 
 // AuthHandler handles authentication requests
 type AuthHandler struct {
-	db *gorm.DB
+	db        *gorm.DB
+	sessions  *services.SessionService
+	twoFactor *services.TwoFactorService
+	lockout   *services.LoginLockoutService
+	passwords *services.PasswordService
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler() *AuthHandler {
+func NewAuthHandler(sessions *services.SessionService, twoFactor *services.TwoFactorService, lockout *services.LoginLockoutService, passwords *services.PasswordService) *AuthHandler {
 	return &AuthHandler{
-		db: database.DB,
+		db:        database.DB,
+		sessions:  sessions,
+		twoFactor: twoFactor,
+		lockout:   lockout,
+		passwords: passwords,
 	}
 }
 
 // Login godoc
 // @Summary User login
-// @Description Authenticate user with email and password
+// @Description Authenticate user with email and password. Users with two-factor authentication get an mfa_token instead of tokens, to exchange with a code at /auth/mfa/verify.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -60,72 +70,91 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// Locked accounts are refused before the password is checked, so guessing stops while locked
+	if user.IsLocked(time.Now()) {
+		h.respondLocked(c, &user, *user.LockedUntil)
+		return
+	}
+
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
 		logger.LogSecurityEvent("login_failed", &user.ID, c.ClientIP(), map[string]interface{}{
 			"email":  req.Email,
 			"reason": "invalid_password",
 		})
-		
-		// Update failed login attempts
-		h.updateFailedLoginAttempts(&user, c.ClientIP())
-		
-		c.JSON(http.StatusUnauthorized, NewErrorResponse("Invalid credentials", ""))
+		h.recordFailedLogin(c, &user)
 		return
 	}
 
-	// Check if account has too many failed attempts (simple check)
-	if user.LoginAttempts >= 5 {
-		logger.LogSecurityEvent("login_blocked", &user.ID, c.ClientIP(), map[string]interface{}{
-			"email":          req.Email,
-			"reason":         "too_many_attempts",
-			"login_attempts": user.LoginAttempts,
+	// The password alone is not enough when two-factor authentication is on; failed attempts are
+	// only reset once the code is verified, so codes cannot be guessed by logging in again
+	if user.TwoFactorEnabled {
+		mfaToken, err := auth.GenerateMFAToken(user.ID, user.Email, user.Role)
+		if err != nil {
+			logger.Error("Failed to generate two-factor token", err, map[string]interface{}{
+				"user_id": user.ID,
+			})
+			c.JSON(http.StatusInternalServerError, NewErrorResponse("Login failed", ""))
+			return
+		}
+
+		logger.LogSecurityEvent("login_mfa_pending", &user.ID, c.ClientIP(), map[string]interface{}{
+			"email": user.Email,
 		})
-		c.JSON(http.StatusUnauthorized, NewErrorResponse("Too many failed attempts", "Please try again later"))
+
+		c.JSON(http.StatusOK, NewSuccessResponse("Two-factor code required", &LoginResponse{
+			User:         newUserResponse(&user),
+			MFARequired:  true,
+			MFAToken:     mfaToken,
+			MFAExpiresIn: int64(auth.MFATokenTTL().Seconds()),
+		}))
 		return
 	}
 
-	// Reset failed attempts on successful login
-	if user.LoginAttempts > 0 {
-		h.db.Model(&user).Updates(map[string]interface{}{
-			"login_attempts": 0,
-		})
-	}
+	h.completeLogin(c, &user, false)
+}
 
-	// Generate token pair
-	tokenPair, err := auth.GenerateTokenPair(user.ID, user.Email, user.Role)
+// completeLogin opens a session for an authenticated user and responds with its tokens
+func (h *AuthHandler) completeLogin(c *gin.Context, user *models.AdminUser, mfaVerified bool) {
+	// Open a session and issue its tokens
+	tokenPair, session, err := h.sessions.CreateSession(user, c.ClientIP(), c.Request.UserAgent(), mfaVerified)
 	if err != nil {
-		logger.Error("Failed to generate tokens", err, map[string]interface{}{
+		logger.Error("Failed to create session", err, map[string]interface{}{
 			"user_id": user.ID,
 		})
 		c.JSON(http.StatusInternalServerError, NewErrorResponse("Login failed", ""))
 		return
 	}
 
-	// Update user login info
+	// Reset failed attempts and update user login info
+	if err := h.lockout.RecordSuccess(user.ID); err != nil {
+		logger.Error("Failed to reset failed login attempts", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+	}
 	now := time.Now()
-	h.db.Model(&user).Updates(map[string]interface{}{
+	h.db.Model(user).Updates(map[string]interface{}{
 		"last_login_at":    now,
 		"last_activity_at": now,
 	})
 
 	// Log successful login
 	logger.LogSecurityEvent("login_success", &user.ID, c.ClientIP(), map[string]interface{}{
-		"email": user.Email,
-		"role":  user.Role,
+		"email":        user.Email,
+		"role":         user.Role,
+		"session_id":   session.ID,
+		"mfa_verified": mfaVerified,
 	})
 
 	response := &LoginResponse{
-		User: UserResponse{
-			ID        : user.ID,
-			Email     : user.Email,
-			Name      : user.Name,
-			Role      : user.Role,
-			IsActive  : user.IsActive,
-			CreatedAt : user.CreatedAt,
-			UpdatedAt : user.UpdatedAt,
-		},
-		Tokens: *tokenPair,
+		User:   newUserResponse(user),
+		Tokens: tokenPair,
+	}
+
+	// Sessions of roles that require two-factor authentication cannot use admin and manager
+	// endpoints until it is set up
+	if !mfaVerified && h.twoFactor.RequiredForRole(user.Role) {
+		response.MFASetupRequired = true
 	}
 
 	c.JSON(http.StatusOK, NewSuccessResponse("Login successful", response))
@@ -133,7 +162,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 // RefreshToken godoc
 // @Summary Refresh access token
-// @Description Refresh an expired access token using a valid refresh token
+// @Description Exchange a refresh token for a new token pair. Refresh tokens are single use; presenting one again revokes its session.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -150,8 +179,8 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	// Refresh the token
-	tokenPair, err := auth.RefreshAccessToken(req.RefreshToken)
+	// Rotate the session's refresh token
+	tokenPair, err := h.sessions.Refresh(req.RefreshToken, c.ClientIP())
 	if err != nil {
 		logger.LogSecurityEvent("token_refresh_failed", nil, c.ClientIP(), map[string]interface{}{
 			"error": err.Error(),
@@ -173,31 +202,235 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 
 // Logout godoc
 // @Summary User logout
-// @Description Logout user and invalidate token
+// @Description Logout user and revoke the session of the token
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Success 200 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
 // @Security BearerAuth
 // @Router /auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
-	// Get user from context (set by auth middleware)
-	userID := getUserIDFromContext(c)
-	
-	if userID != nil {
-		logger.LogSecurityEvent("logout", userID, c.ClientIP(), map[string]interface{}{
-			"user_id": *userID,
+	claims := getTokenClaimsFromContext(c)
+	if claims == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	if err := h.sessions.RevokeToken(claims, models.SessionRevokedLogout); err != nil {
+		logger.Error("Failed to revoke session", err, map[string]interface{}{
+			"user_id": claims.UserID,
 		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Logout failed", ""))
+		return
 	}
 
-	// In a production system, you might want to:
-	// 1. Add the token to a blacklist
-	// 2. Store token revocation in Redis/database
-	// For now, we'll just return success since JWT tokens are stateless
-	
+	logger.LogSecurityEvent("logout", &claims.UserID, c.ClientIP(), map[string]interface{}{
+		"user_id": claims.UserID,
+	})
+
 	c.JSON(http.StatusOK, NewSuccessResponse("Logout successful", nil))
 }
 
+// GetSessions godoc
+// @Summary List my sessions
+// @Description List the current user's active sessions; the session of the requesting token is marked current
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} APIResponse{data=[]models.UserSession}
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /auth/sessions [get]
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	claims := getTokenClaimsFromContext(c)
+	if claims == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	sessions, err := h.sessions.ListSessions(claims.UserID, claims.ID)
+	if err != nil {
+		logger.Error("Failed to get sessions", err, map[string]interface{}{
+			"user_id": claims.UserID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get sessions", ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Sessions retrieved successfully", sessions))
+}
+
+// RevokeSession godoc
+// @Summary Revoke one of my sessions
+// @Description End one of the current user's sessions; its access and refresh tokens stop working
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param id path int true "Session ID"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid session ID", err.Error()))
+		return
+	}
+
+	if err := h.sessions.RevokeSession(*userID, uint(sessionID), models.SessionRevokedByUser); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Session"))
+			return
+		}
+		logger.Error("Failed to revoke session", err, map[string]interface{}{
+			"user_id":    *userID,
+			"session_id": sessionID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to revoke session", ""))
+		return
+	}
+
+	logger.LogSecurityEvent("session_revoked", userID, c.ClientIP(), map[string]interface{}{
+		"session_id": sessionID,
+	})
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Session revoked successfully", nil))
+}
+
+// GetUserSessions godoc
+// @Summary List a user's sessions
+// @Description List a user's active sessions (requires users:manage)
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} APIResponse{data=[]models.UserSession}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/{id}/sessions [get]
+func (h *AuthHandler) GetUserSessions(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid user ID", err.Error()))
+		return
+	}
+
+	currentToken := ""
+	if claims := getTokenClaimsFromContext(c); claims != nil {
+		currentToken = claims.ID
+	}
+
+	sessions, err := h.sessions.ListSessions(uint(userID), currentToken)
+	if err != nil {
+		logger.Error("Failed to get user sessions", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get sessions", ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Sessions retrieved successfully", sessions))
+}
+
+// RevokeUserSessions godoc
+// @Summary Revoke all sessions of a user
+// @Description End every active session of a user, signing them out everywhere (requires users:manage)
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} APIResponse{data=RevokeSessionsResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/{id}/sessions [delete]
+func (h *AuthHandler) RevokeUserSessions(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid user ID", err.Error()))
+		return
+	}
+
+	revoked, err := h.sessions.RevokeAllSessions(uint(userID), models.SessionRevokedByAdmin)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("User"))
+			return
+		}
+		logger.Error("Failed to revoke user sessions", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to revoke sessions", ""))
+		return
+	}
+
+	logger.LogSecurityEvent("user_sessions_revoked", getUserIDFromContext(c), c.ClientIP(), map[string]interface{}{
+		"target_user_id": userID,
+		"revoked":        revoked,
+	})
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Sessions revoked successfully", &RevokeSessionsResponse{
+		Revoked: revoked,
+	}))
+}
+
+// UnlockUser godoc
+// @Summary Unlock a user
+// @Description Lift the lockout of a user locked out by failed logins and clear their failed attempts (requires users:manage)
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/{id}/unlock [post]
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid user ID", err.Error()))
+		return
+	}
+
+	if err := h.lockout.Unlock(uint(userID)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("User"))
+			return
+		}
+		logger.Error("Failed to unlock user", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to unlock user", ""))
+		return
+	}
+
+	logger.LogSecurityEvent("account_unlocked", getUserIDFromContext(c), c.ClientIP(), map[string]interface{}{
+		"target_user_id": userID,
+	})
+
+	c.JSON(http.StatusOK, NewSuccessResponse("User unlocked successfully", nil))
+}
+
 // GetProfile godoc
 // @Summary Get user profile
 // @Description Get current user's profile information
@@ -225,17 +458,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	response := UserResponse{
-		ID        : user.ID,
-		Email     : user.Email,
-		Name      : user.Name,
-		Role      : user.Role,
-		IsActive  : user.IsActive,
-		CreatedAt : user.CreatedAt,
-		UpdatedAt : user.UpdatedAt,
-	}
-
-	c.JSON(http.StatusOK, NewSuccessResponse("Profile retrieved successfully", response))
+	c.JSON(http.StatusOK, NewSuccessResponse("Profile retrieved successfully", newUserResponse(&user)))
 }
 
 // ChangePassword godoc
@@ -258,48 +481,32 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	userID := getUserIDFromContext(c)
-	if userID == nil {
+	claims := getTokenClaimsFromContext(c)
+	if claims == nil {
 		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
 		return
 	}
 
-	var user models.AdminUser
-	if err := h.db.First(&user, *userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, NewErrorResponse("User not found", ""))
-		return
-	}
-
-	// Verify current password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)); err != nil {
-		logger.LogSecurityEvent("password_change_failed", userID, c.ClientIP(), map[string]interface{}{
-			"reason": "invalid_current_password",
-		})
-		c.JSON(http.StatusBadRequest, NewErrorResponse("Current password is incorrect", ""))
-		return
-	}
-
-	// Hash new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
-	if err != nil {
-		logger.Error("Failed to hash password", err, map[string]interface{}{
-			"user_id": *userID,
-		})
-		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to change password", ""))
-		return
-	}
-
-	// Update password
-	if err := h.db.Model(&user).Update("password_hash", string(hashedPassword)).Error; err != nil {
-		logger.Error("Failed to update password", err, map[string]interface{}{
-			"user_id": *userID,
-		})
-		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to change password", ""))
+	if err := h.passwords.ChangePassword(claims, req.CurrentPassword, req.NewPassword); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "incorrect"):
+			logger.LogSecurityEvent("password_change_failed", &claims.UserID, c.ClientIP(), map[string]interface{}{
+				"reason": "invalid_current_password",
+			})
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Current password is incorrect", ""))
+		case strings.Contains(err.Error(), "invalid password"):
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Password does not meet the password policy", err.Error()))
+		default:
+			logger.Error("Failed to change password", err, map[string]interface{}{
+				"user_id": claims.UserID,
+			})
+			c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to change password", ""))
+		}
 		return
 	}
 
-	logger.LogSecurityEvent("password_changed", userID, c.ClientIP(), map[string]interface{}{
-		"user_id": *userID,
+	logger.LogSecurityEvent("password_changed", &claims.UserID, c.ClientIP(), map[string]interface{}{
+		"user_id": claims.UserID,
 	})
 
 	c.JSON(http.StatusOK, NewSuccessResponse("Password changed successfully", nil))
@@ -337,21 +544,44 @@ func (h *AuthHandler) ValidateToken(c *gin.Context) {
 
 // Helper methods
 
-func (h *AuthHandler) updateFailedLoginAttempts(user *models.AdminUser, clientIP string) {
-	attempts := user.LoginAttempts + 1
-	
-	updates := map[string]interface{}{
-		"login_attempts": attempts,
+// getTokenClaimsFromContext returns the claims of the request's access token, set by the auth middleware
+func getTokenClaimsFromContext(c *gin.Context) *auth.JWTClaims {
+	if value, exists := c.Get("token_claims"); exists {
+		if claims, ok := value.(*auth.JWTClaims); ok {
+			return claims
+		}
 	}
+	return nil
+}
 
-	// Log warning after 3 failed attempts
-	if attempts >= 3 {
-		logger.LogSecurityEvent("multiple_failed_attempts", &user.ID, clientIP, map[string]interface{}{
-			"failed_attempts": attempts,
+// recordFailedLogin counts a failed password or code and responds, telling the user when the
+// failure locked the account
+func (h *AuthHandler) recordFailedLogin(c *gin.Context, user *models.AdminUser) {
+	lockedUntil, err := h.lockout.RecordFailure(user, c.ClientIP())
+	if err != nil {
+		logger.Error("Failed to record failed login", err, map[string]interface{}{
+			"user_id": user.ID,
 		})
 	}
+	if lockedUntil != nil {
+		h.respondLocked(c, user, *lockedUntil)
+		return
+	}
+
+	c.JSON(http.StatusUnauthorized, NewErrorResponse("Invalid credentials", ""))
+}
+
+func (h *AuthHandler) respondLocked(c *gin.Context, user *models.AdminUser, lockedUntil time.Time) {
+	logger.LogSecurityEvent("login_blocked", &user.ID, c.ClientIP(), map[string]interface{}{
+		"email":        user.Email,
+		"reason":       "account_locked",
+		"locked_until": lockedUntil,
+	})
 
-	h.db.Model(user).Updates(updates)
+	retryAfter := int(time.Until(lockedUntil).Seconds()) + 1
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusUnauthorized, NewErrorResponse("Too many failed attempts",
+		"Account locked until "+lockedUntil.UTC().Format(time.RFC3339)))
 }
 
 // Request/Response types
@@ -362,18 +592,36 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	User   UserResponse      `json:"user"`
-	Tokens auth.TokenPair    `json:"tokens"`
+	User             UserResponse    `json:"user"`
+	Tokens           *auth.TokenPair `json:"tokens,omitempty"`
+	MFARequired      bool            `json:"mfa_required,omitempty"`       // Exchange MFAToken and a code at /auth/mfa/verify
+	MFAToken         string          `json:"mfa_token,omitempty"`          // The mfa_pending token
+	MFAExpiresIn     int64           `json:"mfa_expires_in,omitempty"`     // Seconds
+	MFASetupRequired bool            `json:"mfa_setup_required,omitempty"` // The role requires two-factor authentication
 }
 
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Email     string    `json:"email"`
-	Name      string    `json:"name"`
-	Role      string    `json:"role"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               uint      `json:"id"`
+	Email            string    `json:"email"`
+	Name             string    `json:"name"`
+	Role             string    `json:"role"`
+	IsActive         bool      `json:"is_active"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func newUserResponse(user *models.AdminUser) UserResponse {
+	return UserResponse{
+		ID:               user.ID,
+		Email:            user.Email,
+		Name:             user.Name,
+		Role:             user.Role,
+		IsActive:         user.IsActive,
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+	}
 }
 
 type RefreshTokenRequest struct {
@@ -382,7 +630,11 @@ type RefreshTokenRequest struct {
 
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" binding:"required,min=6"`
-	NewPassword     string `json:"new_password" binding:"required,min=8"`
+	NewPassword     string `json:"new_password" binding:"required"` // Checked against the password policy
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
 }
 
 type TokenValidationResponse struct {
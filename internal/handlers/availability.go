@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/logger"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWeeklySchedule godoc
+// @Summary Get weekly schedule
+// @Description Get a user's weekly working hours; users without a schedule get default Monday-Friday business hours
+// @Tags availability
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} APIResponse{data=models.WeeklyScheduleResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /availability/users/{id}/schedule [get]
+func (h *SchedulingHandler) GetWeeklySchedule(c *gin.Context) {
+	targetID, _, ok := h.availabilityTarget(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.schedulingService.GetWeeklySchedule(targetID)
+	if err != nil {
+		logger.Error("Failed to get weekly schedule", err, map[string]interface{}{
+			"user_id": targetID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get weekly schedule", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Weekly schedule retrieved successfully", schedule))
+}
+
+// SetWeeklySchedule godoc
+// @Summary Set weekly schedule
+// @Description Replace a user's weekly working hours and breaks; weekdays left out are days off
+// @Tags availability
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param schedule body models.WeeklyScheduleRequest true "Weekly schedule"
+// @Success 200 {object} APIResponse{data=models.WeeklyScheduleResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /availability/users/{id}/schedule [put]
+func (h *SchedulingHandler) SetWeeklySchedule(c *gin.Context) {
+	targetID, userID, ok := h.availabilityTarget(c)
+	if !ok {
+		return
+	}
+
+	var req models.WeeklyScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	schedule, err := h.schedulingService.SetWeeklySchedule(targetID, &req, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Failed to set weekly schedule", err.Error()))
+			return
+		}
+		logger.Error("Failed to set weekly schedule", err, map[string]interface{}{
+			"user_id": targetID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to set weekly schedule", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Weekly schedule updated successfully", schedule))
+}
+
+// GetAvailabilityExceptions godoc
+// @Summary List availability exceptions
+// @Description List a user's holidays, out-of-office blocks and custom hours; recurring exceptions are always included
+// @Tags availability
+// @Produce json
+// @Param id path int true "User ID"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD)"
+// @Success 200 {object} APIResponse{data=[]models.AvailabilityException}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /availability/users/{id}/exceptions [get]
+func (h *SchedulingHandler) GetAvailabilityExceptions(c *gin.Context) {
+	targetID, _, ok := h.availabilityTarget(c)
+	if !ok {
+		return
+	}
+
+	var from, to *time.Time
+	for param, target := range map[string]**time.Time{"from": &from, "to": &to} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid "+param+" date", "Use YYYY-MM-DD format"))
+			return
+		}
+		*target = &date
+	}
+
+	exceptions, err := h.schedulingService.ListAvailabilityExceptions(targetID, from, to)
+	if err != nil {
+		logger.Error("Failed to list availability exceptions", err, map[string]interface{}{
+			"user_id": targetID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to list availability exceptions", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Availability exceptions retrieved successfully", exceptions))
+}
+
+// CreateAvailabilityException godoc
+// @Summary Create availability exception
+// @Description Add a holiday, out-of-office block or custom working hours for a user; rrule makes it recur
+// @Tags availability
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param exception body models.AvailabilityExceptionRequest true "Exception data"
+// @Success 201 {object} APIResponse{data=models.AvailabilityException}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /availability/users/{id}/exceptions [post]
+func (h *SchedulingHandler) CreateAvailabilityException(c *gin.Context) {
+	targetID, userID, ok := h.availabilityTarget(c)
+	if !ok {
+		return
+	}
+
+	var req models.AvailabilityExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	exception, err := h.schedulingService.CreateAvailabilityException(targetID, &req, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Failed to create availability exception", err.Error()))
+			return
+		}
+		logger.Error("Failed to create availability exception", err, map[string]interface{}{
+			"user_id": targetID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to create availability exception", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewSuccessResponse("Availability exception created successfully", exception))
+}
+
+// UpdateAvailabilityException godoc
+// @Summary Update availability exception
+// @Description Replace the details of an availability exception
+// @Tags availability
+// @Accept json
+// @Produce json
+// @Param id path int true "Exception ID"
+// @Param exception body models.AvailabilityExceptionRequest true "Exception data"
+// @Success 200 {object} APIResponse{data=models.AvailabilityException}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /availability/exceptions/{id} [put]
+func (h *SchedulingHandler) UpdateAvailabilityException(c *gin.Context) {
+	exception, userID, ok := h.loadAvailabilityException(c)
+	if !ok {
+		return
+	}
+
+	var req models.AvailabilityExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	updated, err := h.schedulingService.UpdateAvailabilityException(exception.ID, &req, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Failed to update availability exception", err.Error()))
+			return
+		}
+		logger.Error("Failed to update availability exception", err, map[string]interface{}{
+			"exception_id": exception.ID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to update availability exception", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Availability exception updated successfully", updated))
+}
+
+// DeleteAvailabilityException godoc
+// @Summary Delete availability exception
+// @Description Remove an availability exception
+// @Tags availability
+// @Produce json
+// @Param id path int true "Exception ID"
+// @Success 200 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /availability/exceptions/{id} [delete]
+func (h *SchedulingHandler) DeleteAvailabilityException(c *gin.Context) {
+	exception, userID, ok := h.loadAvailabilityException(c)
+	if !ok {
+		return
+	}
+
+	if err := h.schedulingService.DeleteAvailabilityException(exception.ID, userID); err != nil {
+		logger.Error("Failed to delete availability exception", err, map[string]interface{}{
+			"exception_id": exception.ID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to delete availability exception", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Availability exception deleted successfully", nil))
+}
+
+// availabilityTarget resolves the user named in the path and checks the caller may manage their availability
+func (h *SchedulingHandler) availabilityTarget(c *gin.Context) (uint, uint, bool) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return 0, 0, false
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid user ID", err.Error()))
+		return 0, 0, false
+	}
+
+	if uint(targetID) != *userID && !canManageAll(c, "availability") {
+		c.JSON(http.StatusForbidden, NewForbiddenResponse())
+		return 0, 0, false
+	}
+	return uint(targetID), *userID, true
+}
+
+// loadAvailabilityException fetches the exception named in the path and checks the caller may manage it
+func (h *SchedulingHandler) loadAvailabilityException(c *gin.Context) (*models.AvailabilityException, uint, bool) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return nil, 0, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid exception ID", err.Error()))
+		return nil, 0, false
+	}
+
+	exception, err := h.schedulingService.GetAvailabilityException(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Availability exception"))
+			return nil, 0, false
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get availability exception", err.Error()))
+		return nil, 0, false
+	}
+
+	if exception.UserID != *userID && !canManageAll(c, "availability") {
+		c.JSON(http.StatusForbidden, NewForbiddenResponse())
+		return nil, 0, false
+	}
+	return exception, *userID, true
+}
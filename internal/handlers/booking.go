@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"contact-service/internal/models"
+	"contact-service/internal/services"
+	"contact-service/pkg/logger"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BookingHandler handles booking page management and the public self-service booking endpoints
+type BookingHandler struct {
+	bookingService *services.BookingService
+}
+
+// NewBookingHandler creates a new booking handler
+func NewBookingHandler(bookingService *services.BookingService) *BookingHandler {
+	return &BookingHandler{
+		bookingService: bookingService,
+	}
+}
+
+// GetBookingPages godoc
+// @Summary List booking pages
+// @Description List the public booking pages and their host pools
+// @Tags booking
+// @Produce json
+// @Success 200 {object} APIResponse{data=[]models.BookingPage}
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /booking-pages [get]
+func (h *BookingHandler) GetBookingPages(c *gin.Context) {
+	pages, err := h.bookingService.ListBookingPages()
+	if err != nil {
+		logger.Error("Failed to list booking pages", err, nil)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to list booking pages", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Booking pages retrieved successfully", pages))
+}
+
+// GetBookingPage godoc
+// @Summary Get booking page
+// @Description Get a booking page by ID
+// @Tags booking
+// @Produce json
+// @Param id path int true "Booking page ID"
+// @Success 200 {object} APIResponse{data=models.BookingPage}
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /booking-pages/{id} [get]
+func (h *BookingHandler) GetBookingPage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid booking page ID", err.Error()))
+		return
+	}
+
+	page, err := h.bookingService.GetBookingPage(uint(id))
+	if err != nil {
+		h.respondError(c, "Failed to get booking page", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Booking page retrieved successfully", page))
+}
+
+// CreateBookingPage godoc
+// @Summary Create booking page
+// @Description Create a public booking page for a pool of hosts
+// @Tags booking
+// @Accept json
+// @Produce json
+// @Param page body models.BookingPageRequest true "Booking page data"
+// @Success 201 {object} APIResponse{data=models.BookingPage}
+// @Failure 400 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /booking-pages [post]
+func (h *BookingHandler) CreateBookingPage(c *gin.Context) {
+	var req models.BookingPageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	page, err := h.bookingService.CreateBookingPage(&req, *userID)
+	if err != nil {
+		h.respondError(c, "Failed to create booking page", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewSuccessResponse("Booking page created successfully", page))
+}
+
+// UpdateBookingPage godoc
+// @Summary Update booking page
+// @Description Replace the settings of a booking page
+// @Tags booking
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking page ID"
+// @Param page body models.BookingPageRequest true "Booking page data"
+// @Success 200 {object} APIResponse{data=models.BookingPage}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /booking-pages/{id} [put]
+func (h *BookingHandler) UpdateBookingPage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid booking page ID", err.Error()))
+		return
+	}
+
+	var req models.BookingPageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	page, err := h.bookingService.UpdateBookingPage(uint(id), &req, *userID)
+	if err != nil {
+		h.respondError(c, "Failed to update booking page", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Booking page updated successfully", page))
+}
+
+// GetPublicBookingPage godoc
+// @Summary Get public booking page
+// @Description Get the details of an active booking page
+// @Tags public-booking
+// @Produce json
+// @Param slug path string true "Booking page slug"
+// @Success 200 {object} APIResponse{data=models.PublicBookingPage}
+// @Failure 404 {object} APIResponse
+// @Router /public/booking/{slug} [get]
+func (h *BookingHandler) GetPublicBookingPage(c *gin.Context) {
+	page, err := h.bookingService.GetPublicBookingPage(c.Param("slug"))
+	if err != nil {
+		h.respondError(c, "Failed to get booking page", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Booking page retrieved successfully", page))
+}
+
+// GetPublicSlots godoc
+// @Summary List open slots
+// @Description List the times at which any host of the booking page is free, in the visitor's timezone
+// @Tags public-booking
+// @Produce json
+// @Param slug path string true "Booking page slug"
+// @Param start_date query string true "First date (YYYY-MM-DD)"
+// @Param end_date query string true "Last date (YYYY-MM-DD)"
+// @Param timezone query string false "Visitor timezone (defaults to the page timezone)"
+// @Success 200 {object} APIResponse{data=[]models.PublicSlot}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Router /public/booking/{slug}/slots [get]
+func (h *BookingHandler) GetPublicSlots(c *gin.Context) {
+	timezone := c.Query("timezone")
+	loc := time.UTC
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid timezone", err.Error()))
+			return
+		}
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", c.Query("start_date"), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid start_date", "Use YYYY-MM-DD format"))
+		return
+	}
+	to, err := time.ParseInLocation("2006-01-02", c.Query("end_date"), loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid end_date", "Use YYYY-MM-DD format"))
+		return
+	}
+
+	slots, err := h.bookingService.ListPublicSlots(c.Param("slug"), from, to.AddDate(0, 0, 1), timezone)
+	if err != nil {
+		h.respondError(c, "Failed to list open slots", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Open slots retrieved successfully", slots))
+}
+
+// HoldSlot godoc
+// @Summary Hold a slot
+// @Description Reserve an open slot for a few minutes while the visitor fills in the booking form. A client may only hold a few slots on a page at a time.
+// @Tags public-booking
+// @Accept json
+// @Produce json
+// @Param slug path string true "Booking page slug"
+// @Param hold body models.BookingHoldRequest true "Slot start time"
+// @Success 201 {object} APIResponse{data=models.BookingHoldResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 429 {object} APIResponse
+// @Router /public/booking/{slug}/holds [post]
+func (h *BookingHandler) HoldSlot(c *gin.Context) {
+	var req models.BookingHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	hold, err := h.bookingService.HoldSlot(c.Param("slug"), req.StartTime, c.ClientIP())
+	if err != nil {
+		h.respondError(c, "Failed to hold slot", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewSuccessResponse("Slot held successfully", hold))
+}
+
+// BookAppointment godoc
+// @Summary Book an appointment
+// @Description Book a slot as a visitor. The contact is matched by email or created, and the response carries signed links to reschedule or cancel. The appointment and meeting type come from the booking page; appointment_type and meeting_type are ignored.
+// @Tags public-booking
+// @Accept json
+// @Produce json
+// @Param slug path string true "Booking page slug"
+// @Param booking body models.PublicAppointmentRequest true "Visitor and slot details"
+// @Success 201 {object} APIResponse{data=models.PublicBookingResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Router /public/booking/{slug}/book [post]
+func (h *BookingHandler) BookAppointment(c *gin.Context) {
+	var req models.PublicAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	// Honeypot spam detection
+	if req.Website != "" {
+		logger.LogSecurityEvent("spam_detected", nil, c.ClientIP(), map[string]interface{}{
+			"honeypot": "website_field_filled",
+			"email":    req.Email,
+			"booking":  c.Param("slug"),
+		})
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid form submission", ""))
+		return
+	}
+
+	booking, err := h.bookingService.Book(c.Param("slug"), &req)
+	if err != nil {
+		if response, ok := validationFailureResponse(err); ok {
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		h.respondError(c, "Failed to book appointment", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewSuccessResponse("Appointment booked successfully", booking))
+}
+
+// GetPublicBooking godoc
+// @Summary Get a booking
+// @Description Get a booking through the signed link sent to the visitor
+// @Tags public-booking
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param signature path string true "Link signature"
+// @Success 200 {object} APIResponse{data=models.PublicBookingResponse}
+// @Failure 404 {object} APIResponse
+// @Router /public/bookings/{id}/{signature} [get]
+func (h *BookingHandler) GetPublicBooking(c *gin.Context) {
+	id, ok := bookingLinkID(c)
+	if !ok {
+		return
+	}
+
+	booking, err := h.bookingService.GetPublicBooking(id, c.Param("signature"))
+	if err != nil {
+		h.respondError(c, "Failed to get booking", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Booking retrieved successfully", booking))
+}
+
+// ReschedulePublicBooking godoc
+// @Summary Reschedule a booking
+// @Description Move a booking to a newly held slot through the signed link sent to the visitor
+// @Tags public-booking
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param signature path string true "Link signature"
+// @Param reschedule body models.PublicRescheduleRequest true "Hold of the new slot"
+// @Success 200 {object} APIResponse{data=models.PublicBookingResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Router /public/bookings/{id}/{signature}/reschedule [post]
+func (h *BookingHandler) ReschedulePublicBooking(c *gin.Context) {
+	id, ok := bookingLinkID(c)
+	if !ok {
+		return
+	}
+
+	var req models.PublicRescheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	booking, err := h.bookingService.ReschedulePublicBooking(id, c.Param("signature"), &req)
+	if err != nil {
+		h.respondError(c, "Failed to reschedule booking", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Booking rescheduled successfully", booking))
+}
+
+// CancelPublicBooking godoc
+// @Summary Cancel a booking
+// @Description Cancel a booking through the signed link sent to the visitor
+// @Tags public-booking
+// @Accept json
+// @Produce json
+// @Param id path int true "Appointment ID"
+// @Param signature path string true "Link signature"
+// @Param cancel body models.PublicCancelRequest false "Cancellation reason"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Router /public/bookings/{id}/{signature}/cancel [post]
+func (h *BookingHandler) CancelPublicBooking(c *gin.Context) {
+	id, ok := bookingLinkID(c)
+	if !ok {
+		return
+	}
+
+	var req models.PublicCancelRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+			return
+		}
+	}
+
+	if err := h.bookingService.CancelPublicBooking(id, c.Param("signature"), req.Reason); err != nil {
+		h.respondError(c, "Failed to cancel booking", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Booking cancelled successfully", nil))
+}
+
+// bookingLinkID parses the appointment ID of a signed booking link
+func bookingLinkID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusNotFound, NewNotFoundResponse("Booking"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondError maps booking service errors to HTTP responses
+func (h *BookingHandler) respondError(c *gin.Context, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, NewErrorResponse(message, err.Error()))
+	case strings.Contains(err.Error(), "no longer available"),
+		strings.Contains(err.Error(), "expired"),
+		strings.Contains(err.Error(), "already"):
+		c.JSON(http.StatusConflict, NewConflictResponse(err.Error()))
+	case strings.Contains(err.Error(), "invalid"), strings.Contains(err.Error(), "cannot"):
+		c.JSON(http.StatusBadRequest, NewErrorResponse(message, err.Error()))
+	case strings.Contains(err.Error(), "too many"):
+		c.JSON(http.StatusTooManyRequests, NewErrorResponseWithCode("RATE_LIMIT", message, err.Error()))
+	default:
+		logger.Error(message, err, map[string]interface{}{
+			"path": c.Request.URL.Path,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(message, err.Error()))
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"mime/multipart"
 	"net/http"
@@ -10,35 +11,45 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"contact-service/internal/models"
 	"contact-service/internal/services"
+	"contact-service/pkg/logger"
 )
 
 // BulkHandler handles bulk operations for contacts
 type BulkHandler struct {
 	bulkService *services.BulkService
+	importJobs  *services.ImportJobService
 }
 
 // NewBulkHandler creates a new bulk handler
-func NewBulkHandler(bulkService *services.BulkService) *BulkHandler {
+func NewBulkHandler(bulkService *services.BulkService, importJobs *services.ImportJobService) *BulkHandler {
 	return &BulkHandler{
 		bulkService: bulkService,
+		importJobs:  importJobs,
 	}
 }
 
 // ImportContactsRequest represents the import request structure
 type ImportContactsRequest struct {
-	SkipHeader bool `form:"skip_header" json:"skip_header"`
+	SkipHeader     bool   `form:"skip_header" json:"skip_header"`         // First row holds the column headers
+	BatchName      string `form:"batch_name" json:"batch_name"`
+	UpdateExisting bool   `form:"update_existing" json:"update_existing"` // Update contacts whose email already exists
+	FieldMapping   string `form:"field_mapping" json:"field_mapping"`     // JSON object of CSV header -> contact field
 }
 
 // @Summary Import contacts from CSV file
-// @Description Import multiple contacts from uploaded CSV file with validation and duplicate detection
+// @Description Start a background import of contacts from an uploaded CSV file. Poll the returned batch for progress.
 // @Tags Bulk Operations
 // @Accept multipart/form-data
 // @Produce json
 // @Security BearerAuth
 // @Param file formData file true "CSV file containing contact data"
-// @Param skip_header formData boolean false "Skip first row as header"
-// @Success 200 {object} APIResponse{data=services.BulkImportResult} "Import completed successfully"
+// @Param skip_header formData boolean false "Treat first row as header"
+// @Param batch_name formData string false "Name for the import batch"
+// @Param update_existing formData boolean false "Update contacts whose email already exists instead of skipping them"
+// @Param field_mapping formData string false "JSON object mapping CSV headers to contact fields"
+// @Success 202 {object} APIResponse{data=models.ContactImportBatch} "Import started"
 // @Failure 400 {object} ErrorResponse "Invalid request or file format"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 413 {object} ErrorResponse "File too large"
@@ -51,6 +62,24 @@ func (h *BulkHandler) ImportContacts(c *gin.Context) {
 		return
 	}
 
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	options := models.ImportJobOptions{
+		BatchName:      request.BatchName,
+		HasHeader:      request.SkipHeader,
+		UpdateExisting: request.UpdateExisting,
+	}
+	if request.FieldMapping != "" {
+		if err := json.Unmarshal([]byte(request.FieldMapping), &options.FieldMapping); err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid field mapping", err.Error()))
+			return
+		}
+	}
+
 	// Get uploaded file
 	file, header, err := c.Request.FormFile("file")
 	if err != nil {
@@ -65,42 +94,192 @@ func (h *BulkHandler) ImportContacts(c *gin.Context) {
 		return
 	}
 
-	// Check file size (max 10MB)
-	if header.Size > 10*1024*1024 {
-		c.JSON(http.StatusRequestEntityTooLarge, NewErrorResponse("File too large", "Maximum file size is 10MB"))
+	// Check file size
+	if header.Size > maxImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, NewErrorResponse("File too large", "Maximum file size is 50MB"))
 		return
 	}
 
-	// Import contacts
-	result, err := h.bulkService.ImportContactsFromCSV(file, request.SkipHeader)
+	batch, err := h.importJobs.StartImport(file, header.Filename, header.Size, options, *userID)
 	if err != nil {
+		if strings.Contains(err.Error(), "CSV") || strings.Contains(err.Error(), "mapping") || strings.Contains(err.Error(), "column") {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Import failed", err.Error()))
+			return
+		}
+		logger.Error("Failed to start contact import", err, map[string]interface{}{
+			"file_name": header.Filename,
+			"user_id":   *userID,
+		})
 		c.JSON(http.StatusInternalServerError, NewErrorResponse("Import failed", err.Error()))
 		return
 	}
 
-	// Determine response status based on results
-	statusCode := http.StatusOK
-	message := "Import completed successfully"
-	
-	if result.ErrorCount > 0 {
-		if result.SuccessCount == 0 {
-			statusCode = http.StatusBadRequest
-			message = "Import failed - all records had errors"
-		} else {
-			message = fmt.Sprintf("Import completed with %d errors out of %d records", result.ErrorCount, result.TotalRecords)
+	c.JSON(http.StatusAccepted, NewSuccessResponse("Import started", batch))
+}
+
+// @Summary List import jobs
+// @Description List background contact import jobs, newest first. Users with imports:manage see every import; other users see their own.
+// @Tags Bulk Operations
+// @Produce json
+// @Security BearerAuth
+// @Param mine query boolean false "Only show imports started by the current user"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} APIResponse{data=PaginatedResponse{items=[]models.ContactImportBatch}} "Import jobs"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/v1/bulk/imports [get]
+func (h *BulkHandler) ListImportJobs(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	page, limit := parsePaginationParams(c)
+
+	var importedBy *uint
+	if c.Query("mine") == "true" || !canManageAll(c, "imports") {
+		importedBy = userID
+	}
+
+	batches, total, err := h.importJobs.ListImports(importedBy, page, limit)
+	if err != nil {
+		logger.Error("Failed to list import jobs", err, nil)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to list import jobs", err.Error()))
+		return
+	}
+
+	response := NewPaginatedResponseWithItems(batches, int(total), page, limit)
+	c.JSON(http.StatusOK, NewSuccessResponse("Import jobs retrieved successfully", response))
+}
+
+// @Summary Get import job
+// @Description Get the status, progress and counters of a background contact import started by the current user (users with imports:manage can see any)
+// @Tags Bulk Operations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Import batch ID"
+// @Success 200 {object} APIResponse{data=models.ContactImportBatch} "Import job"
+// @Failure 400 {object} ErrorResponse "Invalid import ID"
+// @Failure 403 {object} ErrorResponse "Import belongs to another user"
+// @Failure 404 {object} ErrorResponse "Import not found"
+// @Router /api/v1/bulk/imports/{id} [get]
+func (h *BulkHandler) GetImportJob(c *gin.Context) {
+	batch, ok := h.loadImport(c)
+	if !ok {
+		return
+	}
+
+	// Failed rows are served by the download endpoint
+	batch.FailedRecords = nil
+	c.JSON(http.StatusOK, NewSuccessResponse("Import job retrieved successfully", batch))
+}
+
+// @Summary Cancel import job
+// @Description Stop a pending or running import started by the current user (users with imports:manage can cancel any). Rows already imported are kept.
+// @Tags Bulk Operations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Import batch ID"
+// @Success 200 {object} APIResponse{data=models.ContactImportBatch} "Import cancelled"
+// @Failure 400 {object} ErrorResponse "Invalid import ID"
+// @Failure 403 {object} ErrorResponse "Import belongs to another user"
+// @Failure 404 {object} ErrorResponse "Import not found"
+// @Failure 409 {object} ErrorResponse "Import already finished"
+// @Router /api/v1/bulk/imports/{id}/cancel [post]
+func (h *BulkHandler) CancelImportJob(c *gin.Context) {
+	batch, ok := h.loadImport(c)
+	if !ok {
+		return
+	}
+
+	batch, err := h.importJobs.CancelImport(batch.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Import batch"))
+			return
 		}
+		if strings.Contains(err.Error(), "already") {
+			c.JSON(http.StatusConflict, NewConflictResponse(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to cancel import", err.Error()))
+		return
 	}
 
-	c.JSON(statusCode, NewSuccessResponse(message, result))
+	batch.FailedRecords = nil
+	c.JSON(http.StatusOK, NewSuccessResponse("Import cancelled", batch))
 }
 
-// @Summary Export contacts to CSV
-// @Description Export contacts to CSV format with filtering and field selection options
+// @Summary Download failed import rows
+// @Description Download the rows of an import that failed, with their original columns and the errors, as CSV. Only the user who started the import and users with imports:manage can download them.
+// @Tags Bulk Operations
+// @Produce text/csv
+// @Security BearerAuth
+// @Param id path int true "Import batch ID"
+// @Success 200 {file} file "CSV file of failed rows"
+// @Failure 400 {object} ErrorResponse "Invalid import ID"
+// @Failure 403 {object} ErrorResponse "Import belongs to another user"
+// @Failure 404 {object} ErrorResponse "Import not found"
+// @Router /api/v1/bulk/imports/{id}/failed-records [get]
+func (h *BulkHandler) DownloadFailedRecords(c *gin.Context) {
+	batch, ok := h.loadImport(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.importJobs.FailedRecordsCSV(batch)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to export failed rows", err.Error()))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"import_%d_failed_rows.csv\"", batch.ID))
+	c.Header("Content-Length", strconv.Itoa(len(data)))
+	c.Data(http.StatusOK, "text/csv", data)
+}
+
+// loadImport fetches the import batch named in the path and checks the caller may see it
+func (h *BulkHandler) loadImport(c *gin.Context) (*models.ContactImportBatch, bool) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid import ID", err.Error()))
+		return nil, false
+	}
+
+	batch, err := h.importJobs.GetImport(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Import batch"))
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get import job", err.Error()))
+		return nil, false
+	}
+
+	if batch.ImportedBy != *userID && !canManageAll(c, "imports") {
+		c.JSON(http.StatusForbidden, NewForbiddenResponse())
+		return nil, false
+	}
+	return batch, true
+}
+
+// @Summary Export contacts
+// @Description Export contacts as CSV, JSON or XLSX with filtering and field selection options. XLSX workbooks hold contacts, activities and appointments on separate sheets.
 // @Tags Bulk Operations
 // @Accept json
 // @Produce text/csv
+// @Produce application/json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Security BearerAuth
-// @Param format query string false "Export format (csv, json)" default(csv)
+// @Param format query string false "Export format (csv, json, xlsx)" default(csv)
 // @Param status query string false "Filter by contact status"
 // @Param type_id query integer false "Filter by contact type ID"
 // @Param source_id query integer false "Filter by contact source ID"
@@ -108,7 +287,7 @@ func (h *BulkHandler) ImportContacts(c *gin.Context) {
 // @Param limit query integer false "Maximum number of records to export" default(10000)
 // @Param sort_by query string false "Sort field" default(created_at)
 // @Param sort_order query string false "Sort order (asc, desc)" default(desc)
-// @Success 200 {file} file "CSV file containing contact data"
+// @Success 200 {file} file "Export file containing contact data"
 // @Failure 400 {object} ErrorResponse "Invalid query parameters"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 500 {object} ErrorResponse "Export failed"
@@ -125,9 +304,9 @@ func (h *BulkHandler) ExportContacts(c *gin.Context) {
 	sortOrder := c.DefaultQuery("sort_order", "desc")
 
 	// Validate format
-	exportFormat := services.ExportFormat(format)
-	if exportFormat != services.ExportFormatCSV && exportFormat != services.ExportFormatJSON {
-		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid format", "Supported formats: csv, json"))
+	exportFormat := services.NormalizeExportFormat(format)
+	if exportFormat != services.ExportFormatCSV && exportFormat != services.ExportFormatJSON && exportFormat != services.ExportFormatXLSX {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid format", "Supported formats: csv, json, xlsx"))
 		return
 	}
 
@@ -172,21 +351,9 @@ func (h *BulkHandler) ExportContacts(c *gin.Context) {
 	}
 
 	// Export data
-	var data []byte
-	var contentType string
-	var filename string
-
-	switch exportFormat {
-	case services.ExportFormatCSV:
-		data, err = h.bulkService.ExportContactsToCSV(request)
-		contentType = "text/csv"
-		filename = fmt.Sprintf("contacts_%s.csv", getCurrentTimestamp())
-		
-	case services.ExportFormatJSON:
-		data, err = h.bulkService.ExportContactsToJSON(request)
-		contentType = "application/json"
-		filename = fmt.Sprintf("contacts_%s.json", getCurrentTimestamp())
-	}
+	data, _, err := h.bulkService.ExportContacts(request)
+	contentType := services.ExportContentType(exportFormat)
+	filename := fmt.Sprintf("contacts_%s.%s", getCurrentTimestamp(), services.ExportFileExtension(exportFormat))
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, NewErrorResponse("Export failed", err.Error()))
@@ -328,22 +495,41 @@ Jane Smith,jane.smith@techcorp.com,+1-555-987-6543,TechCorp,CTO,contacted,Requir
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Router /api/v1/bulk/contacts/status [get]
 func (h *BulkHandler) GetBulkOperationStatus(c *gin.Context) {
-	// This would typically query a job queue or operation log
-	// For now, return static information
+	summary, err := h.importJobs.GetSummary()
+	if err != nil {
+		logger.Error("Failed to get bulk operation status", err, nil)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get bulk operation status", err.Error()))
+		return
+	}
+
 	stats := BulkOperationStats{
-		TotalImports:    0,
+		TotalImports:    int(summary.TotalImports),
+		ActiveImports:   int(summary.ActiveImports),
 		TotalExports:    0,
 		TotalUpdates:    0,
 		TotalDeletes:    0,
-		RecentImports:   make([]BulkOperationInfo, 0),
+		RecentImports:   make([]BulkOperationInfo, 0, len(summary.RecentImports)),
 		RecentExports:   make([]BulkOperationInfo, 0),
 		SystemLimits: BulkOperationLimits{
-			MaxImportSize:      10 * 1024 * 1024, // 10MB
+			MaxImportSize:      maxImportFileSize,
 			MaxExportRecords:   100000,
 			MaxUpdateBatchSize: 1000,
 			MaxDeleteBatchSize: 100,
 		},
 	}
+	for _, batch := range summary.RecentImports {
+		info := BulkOperationInfo{
+			ID:          strconv.FormatUint(uint64(batch.ID), 10),
+			Type:        "import",
+			Status:      string(batch.Status),
+			RecordCount: batch.TotalRecords,
+			CreatedAt:   batch.StartedAt.Format(time.RFC3339),
+		}
+		if batch.CompletedAt != nil {
+			info.CompletedAt = batch.CompletedAt.Format(time.RFC3339)
+		}
+		stats.RecentImports = append(stats.RecentImports, info)
+	}
 
 	c.JSON(http.StatusOK, NewSuccessResponse("Bulk operation status retrieved", stats))
 }
@@ -355,6 +541,7 @@ type BulkDeleteRequest struct {
 
 type BulkOperationStats struct {
 	TotalImports  int                   `json:"total_imports"`
+	ActiveImports int                   `json:"active_imports"`
 	TotalExports  int                   `json:"total_exports"`
 	TotalUpdates  int                   `json:"total_updates"`
 	TotalDeletes  int                   `json:"total_deletes"`
@@ -379,6 +566,9 @@ type BulkOperationLimits struct {
 	MaxDeleteBatchSize int   `json:"max_delete_batch_size"`
 }
 
+// Maximum size of an uploaded import file
+const maxImportFileSize = 50 * 1024 * 1024 // 50MB
+
 // Helper functions
 func isCSVFile(header *multipart.FileHeader) bool {
 	// Check file extension
@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"contact-service/internal/models"
+	"contact-service/internal/services"
+	"contact-service/pkg/logger"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarHandler handles subscribable iCalendar feeds and external calendar integrations
+type CalendarHandler struct {
+	calendarService *services.CalendarService
+	syncService     *services.CalendarSyncService
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(calendarService *services.CalendarService, syncService *services.CalendarSyncService) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+		syncService:     syncService,
+	}
+}
+
+// GetFeed godoc
+// @Summary Get calendar feed
+// @Description Get the current user's subscribable .ics feed, issuing one on first use. The URL is only returned when a feed is issued; rotate the feed to get a new one.
+// @Tags calendar
+// @Produce json
+// @Success 200 {object} APIResponse{data=models.CalendarFeedResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /calendar/feed [get]
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	feed, err := h.calendarService.GetFeed(*userID)
+	if err != nil {
+		logger.Error("Failed to get calendar feed", err, map[string]interface{}{
+			"user_id": *userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get calendar feed", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Calendar feed retrieved successfully", feed))
+}
+
+// RotateFeed godoc
+// @Summary Rotate calendar feed
+// @Description Issue a new feed token and return its URL; subscriptions using the old URL stop working
+// @Tags calendar
+// @Produce json
+// @Success 200 {object} APIResponse{data=models.CalendarFeedResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /calendar/feed/rotate [post]
+func (h *CalendarHandler) RotateFeed(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	feed, err := h.calendarService.RotateFeed(*userID)
+	if err != nil {
+		logger.Error("Failed to rotate calendar feed", err, map[string]interface{}{
+			"user_id": *userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to rotate calendar feed", err.Error()))
+		return
+	}
+
+	logger.Info("Calendar feed rotated", map[string]interface{}{
+		"user_id": *userID,
+	})
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Calendar feed rotated successfully", feed))
+}
+
+// RevokeFeed godoc
+// @Summary Revoke calendar feed
+// @Description Revoke the current user's feed token
+// @Tags calendar
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /calendar/feed [delete]
+func (h *CalendarHandler) RevokeFeed(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	if err := h.calendarService.RevokeFeed(*userID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Calendar feed"))
+			return
+		}
+		logger.Error("Failed to revoke calendar feed", err, map[string]interface{}{
+			"user_id": *userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to revoke calendar feed", err.Error()))
+		return
+	}
+
+	logger.Info("Calendar feed revoked", map[string]interface{}{
+		"user_id": *userID,
+	})
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Calendar feed revoked successfully", nil))
+}
+
+// ServeFeed godoc
+// @Summary Calendar feed
+// @Description Serve a user's appointments as an iCalendar feed; the token in the URL is the credential
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token, optionally suffixed with .ics"
+// @Success 200 {string} string "iCalendar data"
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /public/calendar/feeds/{token} [get]
+func (h *CalendarHandler) ServeFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	body, err := h.calendarService.RenderFeed(token)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Calendar feed"))
+			return
+		}
+		logger.Error("Failed to render calendar feed", err, nil)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to render calendar feed", err.Error()))
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=UTF-8", body)
+}
+
+// GetIntegrations godoc
+// @Summary List calendar integrations
+// @Description List the current user's external calendar integrations with their sync status
+// @Tags calendar
+// @Produce json
+// @Success 200 {object} APIResponse{data=[]models.CalendarIntegration}
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /calendar/integrations [get]
+func (h *CalendarHandler) GetIntegrations(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	integrations, err := h.syncService.ListIntegrations(*userID)
+	if err != nil {
+		logger.Error("Failed to list calendar integrations", err, map[string]interface{}{
+			"user_id": *userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to list calendar integrations", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Calendar integrations retrieved successfully", integrations))
+}
+
+// GetIntegration godoc
+// @Summary Get calendar integration
+// @Description Get an external calendar integration with its sync status and errors
+// @Tags calendar
+// @Produce json
+// @Param id path int true "Integration ID"
+// @Success 200 {object} APIResponse{data=models.CalendarIntegration}
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Security BearerAuth
+// @Router /calendar/integrations/{id} [get]
+func (h *CalendarHandler) GetIntegration(c *gin.Context) {
+	integration, _, ok := h.loadIntegration(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Calendar integration retrieved successfully", integration))
+}
+
+// CreateIntegration godoc
+// @Summary Connect external calendar
+// @Description Connect a CalDAV calendar collection; appointments are pushed to it and its busy time blocks booking slots. Calendars on loopback, private or link-local addresses are refused.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param integration body models.CalendarIntegrationRequest true "Calendar connection"
+// @Success 201 {object} APIResponse{data=models.CalendarIntegration}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /calendar/integrations [post]
+func (h *CalendarHandler) CreateIntegration(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	var req models.CalendarIntegrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	integration, err := h.syncService.CreateIntegration(*userID, &req)
+	if err != nil {
+		h.respondError(c, "Failed to create calendar integration", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewSuccessResponse("Calendar integration created successfully", integration))
+}
+
+// UpdateIntegration godoc
+// @Summary Update calendar integration
+// @Description Change credentials, sync direction or enablement; pointing it at another calendar restarts sync from scratch
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param id path int true "Integration ID"
+// @Param integration body models.CalendarIntegrationUpdateRequest true "Changes"
+// @Success 200 {object} APIResponse{data=models.CalendarIntegration}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Security BearerAuth
+// @Router /calendar/integrations/{id} [put]
+func (h *CalendarHandler) UpdateIntegration(c *gin.Context) {
+	integration, userID, ok := h.loadIntegration(c)
+	if !ok {
+		return
+	}
+
+	var req models.CalendarIntegrationUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	updated, err := h.syncService.UpdateIntegration(integration.ID, &req, userID)
+	if err != nil {
+		h.respondError(c, "Failed to update calendar integration", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Calendar integration updated successfully", updated))
+}
+
+// DeleteIntegration godoc
+// @Summary Disconnect external calendar
+// @Description Disconnect a calendar; events pushed to it are removed where possible
+// @Tags calendar
+// @Produce json
+// @Param id path int true "Integration ID"
+// @Success 200 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Security BearerAuth
+// @Router /calendar/integrations/{id} [delete]
+func (h *CalendarHandler) DeleteIntegration(c *gin.Context) {
+	integration, userID, ok := h.loadIntegration(c)
+	if !ok {
+		return
+	}
+
+	if err := h.syncService.DeleteIntegration(integration.ID, userID); err != nil {
+		h.respondError(c, "Failed to delete calendar integration", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Calendar integration deleted successfully", nil))
+}
+
+// SyncIntegration godoc
+// @Summary Sync calendar integration
+// @Description Push appointments and pull busy time now, and return the recorded sync status and errors
+// @Tags calendar
+// @Produce json
+// @Param id path int true "Integration ID"
+// @Success 200 {object} APIResponse{data=models.CalendarIntegration}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Security BearerAuth
+// @Router /calendar/integrations/{id}/sync [post]
+func (h *CalendarHandler) SyncIntegration(c *gin.Context) {
+	integration, _, ok := h.loadIntegration(c)
+	if !ok {
+		return
+	}
+
+	synced, err := h.syncService.SyncIntegration(integration.ID)
+	if err != nil {
+		h.respondError(c, "Failed to sync calendar integration", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Calendar integration synced", synced))
+}
+
+// loadIntegration resolves the integration in the path; users reach their own, those with calendar:manage any
+func (h *CalendarHandler) loadIntegration(c *gin.Context) (*models.CalendarIntegration, uint, bool) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return nil, 0, false
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid integration ID", err.Error()))
+		return nil, 0, false
+	}
+
+	integration, err := h.syncService.GetIntegration(uint(id))
+	if err != nil {
+		h.respondError(c, "Failed to get calendar integration", err)
+		return nil, 0, false
+	}
+	if integration.UserID != *userID && !canManageAll(c, "calendar") {
+		c.JSON(http.StatusForbidden, NewForbiddenResponse())
+		return nil, 0, false
+	}
+	return integration, *userID, true
+}
+
+func (h *CalendarHandler) respondError(c *gin.Context, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, NewNotFoundResponse("Calendar integration"))
+	case strings.Contains(err.Error(), "invalid"), strings.Contains(err.Error(), "cannot"):
+		c.JSON(http.StatusBadRequest, NewErrorResponse(message, err.Error()))
+	default:
+		logger.Error(message, err, nil)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(message, err.Error()))
+	}
+}
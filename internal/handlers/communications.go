@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"contact-service/internal/models"
+	"contact-service/internal/services"
+	"contact-service/pkg/logger"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CommunicationHandler handles outbound email and communication history requests
+type CommunicationHandler struct {
+	emailService *services.EmailService
+}
+
+// NewCommunicationHandler creates a new communication handler
+func NewCommunicationHandler(emailService *services.EmailService) *CommunicationHandler {
+	return &CommunicationHandler{
+		emailService: emailService,
+	}
+}
+
+// SendEmail godoc
+// @Summary Send an email to a contact
+// @Description Render a template or ad-hoc content for a contact and queue it for delivery. The message is recorded in contact_communications with a matching activity.
+// @Tags communications
+// @Accept json
+// @Produce json
+// @Param request body models.SendEmailRequest true "Email"
+// @Success 202 {object} APIResponse{data=models.ContactCommunication}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 422 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /communications/email [post]
+func (h *CommunicationHandler) SendEmail(c *gin.Context) {
+	var req models.SendEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	communication, err := h.emailService.QueueEmail(&req, *userID)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "communication template not found"):
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Template"))
+		case strings.Contains(err.Error(), "parent communication not found"):
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Parent communication"))
+		case strings.Contains(err.Error(), "appointment not found"):
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Appointment"))
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Contact"))
+		case strings.Contains(err.Error(), "unsubscribed"):
+			c.JSON(http.StatusConflict, NewConflictResponse(err.Error()))
+		case strings.Contains(err.Error(), "missing template variables"):
+			c.JSON(http.StatusUnprocessableEntity, NewErrorResponseWithCode("MISSING_VARIABLES", "Email has missing variables", err.Error()))
+		case strings.Contains(err.Error(), "invalid"), strings.Contains(err.Error(), "inactive"):
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Cannot send email", err.Error()))
+		default:
+			logger.Error("Failed to queue email", err, map[string]interface{}{
+				"contact_id": req.ContactID,
+			})
+			c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to queue email", err.Error()))
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, NewSuccessResponse("Email queued for delivery", communication))
+}
+
+// GetCommunication godoc
+// @Summary Get a communication
+// @Description Get a communication with its delivery status and attempts
+// @Tags communications
+// @Produce json
+// @Param id path int true "Communication ID"
+// @Success 200 {object} APIResponse{data=models.ContactCommunication}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Security BearerAuth
+// @Router /communications/{id} [get]
+func (h *CommunicationHandler) GetCommunication(c *gin.Context) {
+	communicationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid communication ID", err.Error()))
+		return
+	}
+
+	communication, err := h.emailService.GetCommunication(uint(communicationID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Communication"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get communication", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Communication retrieved successfully", communication))
+}
+
+// RetryCommunication godoc
+// @Summary Retry a failed email
+// @Description Requeue a failed email with a fresh set of delivery attempts
+// @Tags communications
+// @Produce json
+// @Param id path int true "Communication ID"
+// @Success 202 {object} APIResponse{data=models.ContactCommunication}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Security BearerAuth
+// @Router /communications/{id}/retry [post]
+func (h *CommunicationHandler) RetryCommunication(c *gin.Context) {
+	communicationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid communication ID", err.Error()))
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	communication, err := h.emailService.RetryCommunication(uint(communicationID), *userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Communication"))
+			return
+		}
+		if strings.Contains(err.Error(), "only failed") {
+			c.JSON(http.StatusConflict, NewConflictResponse(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to retry email", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, NewSuccessResponse("Email requeued for delivery", communication))
+}
+
+// GetContactCommunications godoc
+// @Summary List a contact's communications
+// @Description List communications exchanged with a contact, newest first
+// @Tags communications
+// @Produce json
+// @Param id path int true "Contact ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} APIResponse{data=PaginatedResponse{items=[]models.ContactCommunication}}
+// @Failure 400 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /contacts/{id}/communications [get]
+func (h *CommunicationHandler) GetContactCommunications(c *gin.Context) {
+	contactID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid contact ID", err.Error()))
+		return
+	}
+
+	page, limit := parsePaginationParams(c)
+	communications, total, err := h.emailService.ListContactCommunications(uint(contactID), page, limit)
+	if err != nil {
+		logger.Error("Failed to get contact communications", err, map[string]interface{}{
+			"contact_id": contactID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get communications", err.Error()))
+		return
+	}
+
+	response := NewPaginatedResponseWithItems(communications, int(total), page, limit)
+	c.JSON(http.StatusOK, NewSuccessResponse("Communications retrieved successfully", response))
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"contact-service/internal/models"
 	"contact-service/internal/services"
+	"contact-service/pkg/errors"
 	"contact-service/pkg/logger"
 	"encoding/json"
 	"net/http"
@@ -58,6 +59,11 @@ func (h *ContactHandler) CreateContact(c *gin.Context) {
 	logger.LogAPIRequest(c.Request.Method, c.Request.URL.Path, userID, duration, http.StatusCreated)
 
 	if err != nil {
+		if response, ok := validationFailureResponse(err); ok {
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+
 		logger.Error("Failed to create contact", err, map[string]interface{}{
 			"email":   req.Email,
 			"user_id": userID,
@@ -155,6 +161,12 @@ func (h *ContactHandler) UpdateContact(c *gin.Context) {
 	duration := time.Since(start)
 
 	if err != nil {
+		if response, ok := validationFailureResponse(err); ok {
+			logger.LogAPIRequest(c.Request.Method, c.Request.URL.Path, userID, duration, http.StatusBadRequest)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+
 		status := http.StatusInternalServerError
 		if strings.Contains(err.Error(), "not found") {
 			status = http.StatusNotFound
@@ -321,6 +333,72 @@ func (h *ContactHandler) ListContacts(c *gin.Context) {
 	c.JSON(http.StatusOK, NewPaginatedResponse("Contacts retrieved successfully", responses, meta))
 }
 
+// GetContactHistory godoc
+// @Summary Get contact field history
+// @Description List field-level changes to a contact, or reconstruct the contact as it was at a given time
+// @Tags contacts
+// @Produce json
+// @Param id path int true "Contact ID"
+// @Param at query string false "Reconstruct the contact as of this timestamp (RFC3339)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} APIResponse{data=PaginatedResponse{items=[]models.ContactFieldHistory}}
+// @Success 200 {object} APIResponse{data=models.ContactHistoryResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /contacts/{id}/history [get]
+func (h *ContactHandler) GetContactHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid contact ID", ""))
+		return
+	}
+
+	if atParam := c.Query("at"); atParam != "" {
+		at, err := time.Parse(time.RFC3339, atParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid timestamp", "at must be an RFC3339 timestamp"))
+			return
+		}
+
+		snapshot, err := h.contactService.GetContactAsOf(uint(id), at)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "did not exist") {
+				c.JSON(http.StatusNotFound, NewErrorResponse("Contact not found", err.Error()))
+				return
+			}
+			logger.Error("Failed to reconstruct contact", err, map[string]interface{}{
+				"contact_id": id,
+				"at":         atParam,
+			})
+			c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to reconstruct contact", ""))
+			return
+		}
+
+		c.JSON(http.StatusOK, NewSuccessResponse("Contact history retrieved successfully", snapshot))
+		return
+	}
+
+	page, limit := parsePaginationParams(c)
+	history, total, err := h.contactService.GetContactHistory(uint(id), page, limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewErrorResponse("Contact not found", ""))
+			return
+		}
+		logger.Error("Failed to get contact history", err, map[string]interface{}{
+			"contact_id": id,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get contact history", ""))
+		return
+	}
+
+	response := NewPaginatedResponseWithItems(history, int(total), page, limit)
+	c.JSON(http.StatusOK, NewSuccessResponse("Contact history retrieved successfully", response))
+}
+
 // UpdateContactStatus godoc
 // @Summary Update contact status
 // @Description Update the status of a contact
@@ -493,6 +571,7 @@ func (h *ContactHandler) mapContactToResponse(contact *models.Contact) *models.C
 		DaysInStatus:          contact.DaysInStatus(),
 		IsHighPriority:        contact.IsHighPriority(),
 		IsHotLead:             contact.IsHotLead(),
+		ValidationWarnings:    contact.ValidationWarnings,
 	}
 
 	// Include contact type and source if loaded
@@ -636,12 +715,17 @@ func (h *ContactHandler) submitContactNewFormat(c *gin.Context, req *models.Publ
 	}
 
 	start := time.Now()
-	contact, err := h.contactService.CreateContact(contactReq, nil) // No authenticated user
+	contact, err := h.contactService.CreateContactFromChannel(contactReq, nil, models.ValidationChannelManual) // No authenticated user
 	duration := time.Since(start)
 
 	logger.LogAPIRequest(c.Request.Method, c.Request.URL.Path, nil, duration, http.StatusCreated)
 
 	if err != nil {
+		if response, ok := validationFailureResponse(err); ok {
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+
 		// Check if this is a duplicate email error
 		if strings.Contains(err.Error(), "already exists") {
 			logger.Warn("Duplicate contact submission", map[string]interface{}{
@@ -697,6 +781,17 @@ func getDefaultContactSourceID(provided *uint) uint {
 	return 1 // Website Contact Form
 }
 
+// validationFailureResponse builds a 400 response listing rule violations when err is a validation AppError
+func validationFailureResponse(err error) (*APIResponse, bool) {
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.Code != errors.ErrCodeValidation {
+		return nil, false
+	}
+	response := NewValidationErrorResponse(appErr.Message)
+	response.Data = appErr.FieldErrors
+	return response, true
+}
+
 func getUserIDFromContext(c *gin.Context) *uint {
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(uint); ok {
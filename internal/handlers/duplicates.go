@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"contact-service/internal/models"
+	"contact-service/internal/services"
+	"contact-service/pkg/database"
+	"contact-service/pkg/errors"
+	"contact-service/pkg/logger"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DuplicateHandler handles duplicate detection and contact merge requests
+type DuplicateHandler struct {
+	duplicateService *services.DuplicateService
+}
+
+// NewDuplicateHandler creates a new duplicate handler
+func NewDuplicateHandler() *DuplicateHandler {
+	return &DuplicateHandler{
+		duplicateService: services.NewDuplicateService(database.DB),
+	}
+}
+
+// DetectDuplicates godoc
+// @Summary Detect duplicate contacts
+// @Description Score candidate contact pairs and persist duplicate groups above the confidence threshold
+// @Tags duplicates
+// @Accept json
+// @Produce json
+// @Param request body models.DuplicateDetectionRequest false "Detection options"
+// @Success 200 {object} APIResponse{data=models.DuplicateDetectionResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /duplicates/detect [post]
+func (h *DuplicateHandler) DetectDuplicates(c *gin.Context) {
+	var req models.DuplicateDetectionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+			return
+		}
+	}
+
+	result, err := h.duplicateService.DetectDuplicates(&req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Contact"))
+			return
+		}
+		logger.Error("Failed to detect duplicates", err, map[string]interface{}{
+			"contact_id": req.ContactID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to detect duplicates", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Duplicate detection completed", result))
+}
+
+// GetDuplicateGroups godoc
+// @Summary List duplicate groups
+// @Description Get detected duplicate groups ordered by confidence score
+// @Tags duplicates
+// @Produce json
+// @Param status query string false "Group status (detected, reviewing, merged, ignored, split)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} APIResponse{data=PaginatedResponse}
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /duplicates/groups [get]
+func (h *DuplicateHandler) GetDuplicateGroups(c *gin.Context) {
+	page, limit := parsePaginationParams(c)
+
+	groups, total, err := h.duplicateService.ListDuplicateGroups(c.Query("status"), page, limit)
+	if err != nil {
+		logger.Error("Failed to get duplicate groups", err, nil)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get duplicate groups", err.Error()))
+		return
+	}
+
+	response := NewPaginatedResponseWithItems(groups, int(total), page, limit)
+	c.JSON(http.StatusOK, NewSuccessResponse("Duplicate groups retrieved successfully", response))
+}
+
+// GetDuplicateGroup godoc
+// @Summary Get a duplicate group
+// @Description Get a duplicate group with its member contacts and similarity scores
+// @Tags duplicates
+// @Produce json
+// @Param id path int true "Duplicate group ID"
+// @Success 200 {object} APIResponse{data=models.ContactDuplicateGroup}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Security BearerAuth
+// @Router /duplicates/groups/{id} [get]
+func (h *DuplicateHandler) GetDuplicateGroup(c *gin.Context) {
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid group ID", err.Error()))
+		return
+	}
+
+	group, err := h.duplicateService.GetDuplicateGroup(uint(groupID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Duplicate group"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get duplicate group", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Duplicate group retrieved successfully", group))
+}
+
+// UpdateDuplicateGroupStatus godoc
+// @Summary Update duplicate group status
+// @Description Mark a duplicate group as reviewing, ignored or split
+// @Tags duplicates
+// @Accept json
+// @Produce json
+// @Param id path int true "Duplicate group ID"
+// @Param request body models.DuplicateGroupStatusRequest true "Status update"
+// @Success 200 {object} APIResponse{data=models.ContactDuplicateGroup}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Security BearerAuth
+// @Router /duplicates/groups/{id}/status [put]
+func (h *DuplicateHandler) UpdateDuplicateGroupStatus(c *gin.Context) {
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid group ID", err.Error()))
+		return
+	}
+
+	var req models.DuplicateGroupStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	group, err := h.duplicateService.UpdateDuplicateGroupStatus(uint(groupID), &req, *userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Duplicate group"))
+			return
+		}
+		if strings.Contains(err.Error(), "already been merged") {
+			c.JSON(http.StatusConflict, NewConflictResponse(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to update duplicate group", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Duplicate group updated successfully", group))
+}
+
+// MergeContacts godoc
+// @Summary Merge duplicate contacts
+// @Description Merge duplicate contacts into a master contact using per-field strategies, moving activities, appointments, tags and assignments
+// @Tags duplicates
+// @Accept json
+// @Produce json
+// @Param request body models.ContactMergeRequest true "Merge request"
+// @Success 200 {object} APIResponse{data=models.ContactMergeResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /duplicates/merge [post]
+func (h *DuplicateHandler) MergeContacts(c *gin.Context) {
+	var req models.ContactMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	result, err := h.duplicateService.MergeContacts(&req, *userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewErrorResponse("Contact not found", err.Error()))
+			return
+		}
+		if strings.Contains(err.Error(), "cannot be merged") || strings.Contains(err.Error(), "invalid merge strategy") ||
+			strings.Contains(err.Error(), "more than once") {
+			c.JSON(http.StatusBadRequest, NewValidationErrorResponse(err.Error()))
+			return
+		}
+		logger.Error("Failed to merge contacts", err, map[string]interface{}{
+			"master_contact_id": req.MasterContactID,
+			"duplicate_ids":     req.DuplicateContactIDs,
+			"user_id":           *userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to merge contacts", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Contacts merged successfully", result))
+}
+
+// UnmergeContact godoc
+// @Summary Undo a contact merge
+// @Description Restore the merged contact from merge history and move its activities, appointments, tags and assignments back. Fails with 409 listing conflicting fields if the master was edited since the merge.
+// @Tags duplicates
+// @Accept json
+// @Produce json
+// @Param id path int true "Merge history ID"
+// @Param request body models.ContactUnmergeRequest false "Unmerge notes"
+// @Success 200 {object} APIResponse{data=models.ContactUnmergeResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse{data=[]errors.FieldError}
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /duplicates/merges/{id}/unmerge [post]
+func (h *DuplicateHandler) UnmergeContact(c *gin.Context) {
+	historyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid merge history ID", err.Error()))
+		return
+	}
+
+	var req models.ContactUnmergeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+			return
+		}
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	result, err := h.duplicateService.UnmergeContact(uint(historyID), &req, *userID)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Code == errors.ErrCodeConflict {
+			response := NewConflictResponse(appErr.Message)
+			response.Data = appErr.FieldErrors
+			c.JSON(http.StatusConflict, response)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewErrorResponse("Merge not found", err.Error()))
+			return
+		}
+		if strings.Contains(err.Error(), "already been undone") || strings.Contains(err.Error(), "already active") {
+			c.JSON(http.StatusConflict, NewConflictResponse(err.Error()))
+			return
+		}
+		logger.Error("Failed to unmerge contact", err, map[string]interface{}{
+			"merge_history_id": historyID,
+			"user_id":          *userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to unmerge contact", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Merge undone successfully", result))
+}
+
+// GetMergeHistory godoc
+// @Summary Get contact merge history
+// @Description Get merge operations where the contact was the master or the merged duplicate
+// @Tags duplicates
+// @Produce json
+// @Param id path int true "Contact ID"
+// @Success 200 {object} APIResponse{data=[]models.ContactMergeHistory}
+// @Failure 400 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /duplicates/contacts/{id}/merges [get]
+func (h *DuplicateHandler) GetMergeHistory(c *gin.Context) {
+	contactID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid contact ID", err.Error()))
+		return
+	}
+
+	history, err := h.duplicateService.GetMergeHistory(uint(contactID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get merge history", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Merge history retrieved successfully", history))
+}
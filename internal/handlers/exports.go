@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"contact-service/internal/middleware"
+	"contact-service/internal/models"
+	"contact-service/internal/services"
+	"contact-service/pkg/logger"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler handles background export job requests
+type ExportHandler struct {
+	exportService *services.ExportJobService
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportService *services.ExportJobService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// CreateExport godoc
+// @Summary Queue an export
+// @Description Queue a contacts or analytics export to be written in the background. Poll the job and download the file once completed.
+// @Tags exports
+// @Accept json
+// @Produce json
+// @Param request body models.ExportJobRequest true "Export parameters"
+// @Success 202 {object} APIResponse{data=models.ExportJob}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /exports [post]
+func (h *ExportHandler) CreateExport(c *gin.Context) {
+	var req models.ExportJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	job, err := h.exportService.CreateJob(req, *userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "unsupported") || strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid export request", err.Error()))
+			return
+		}
+		logger.Error("Failed to queue export", err, map[string]interface{}{
+			"job_type": req.JobType,
+			"user_id":  *userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to queue export", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, NewSuccessResponse("Export queued", job))
+}
+
+// GetExports godoc
+// @Summary List exports
+// @Description List export jobs newest first. Users with exports:manage see every job, other users only their own.
+// @Tags exports
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} APIResponse{data=PaginatedResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /exports [get]
+func (h *ExportHandler) GetExports(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	page, limit := parsePaginationParams(c)
+
+	var createdBy *uint
+	if !canManageAll(c, "exports") {
+		createdBy = userID
+	}
+
+	jobs, total, err := h.exportService.ListJobs(createdBy, page, limit)
+	if err != nil {
+		logger.Error("Failed to list exports", err, nil)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to list exports", err.Error()))
+		return
+	}
+
+	response := NewPaginatedResponseWithItems(jobs, int(total), page, limit)
+	c.JSON(http.StatusOK, NewSuccessResponse("Exports retrieved successfully", response))
+}
+
+// GetExport godoc
+// @Summary Get export
+// @Description Get the status and progress of an export job
+// @Tags exports
+// @Produce json
+// @Param id path string true "Export ID"
+// @Success 200 {object} APIResponse{data=models.ExportJob}
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Security BearerAuth
+// @Router /exports/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	job, ok := h.loadExport(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Export retrieved successfully", job))
+}
+
+// DownloadExport godoc
+// @Summary Download export
+// @Description Download the file of a completed export. Each download is counted.
+// @Tags exports
+// @Produce application/octet-stream
+// @Param id path string true "Export ID"
+// @Success 200 {file} file "Export file"
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse "Export not finished"
+// @Failure 410 {object} APIResponse "Export expired"
+// @Security BearerAuth
+// @Router /exports/{id}/download [get]
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	job, ok := h.loadExport(c)
+	if !ok {
+		return
+	}
+
+	path, err := h.exportService.PrepareDownload(job)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "expired"):
+			c.JSON(http.StatusGone, NewErrorResponse("Export has expired", err.Error()))
+		case strings.Contains(err.Error(), "not ready"):
+			c.JSON(http.StatusConflict, NewConflictResponse(err.Error()))
+		default:
+			logger.Error("Failed to serve export", err, map[string]interface{}{
+				"export_id": job.ExportID,
+			})
+			c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to download export", err.Error()))
+		}
+		return
+	}
+
+	c.FileAttachment(path, h.exportService.DownloadFileName(job))
+}
+
+// loadExport fetches the export named in the path and checks the caller may see it
+func (h *ExportHandler) loadExport(c *gin.Context) (*models.ExportJob, bool) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return nil, false
+	}
+
+	job, err := h.exportService.GetJob(c.Param("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Export"))
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get export", err.Error()))
+		return nil, false
+	}
+
+	if job.CreatedBy != *userID && !canManageAll(c, "exports") {
+		c.JSON(http.StatusForbidden, NewForbiddenResponse())
+		return nil, false
+	}
+	return job, true
+}
+
+// canManageAll reports whether the authenticated user may see and manage every user's records of
+// the resource, not just their own
+func canManageAll(c *gin.Context, resource string) bool {
+	return middleware.CanAccessResource(c, resource, "manage")
+}
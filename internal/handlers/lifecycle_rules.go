@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"contact-service/internal/models"
+	"contact-service/internal/services"
 	"contact-service/pkg/database"
 	"contact-service/pkg/logger"
 	"net/http"
@@ -13,13 +14,15 @@ import (
 
 // LifecycleRulesHandler handles lifecycle rules management requests
 type LifecycleRulesHandler struct {
-	db *gorm.DB
+	db      *gorm.DB
+	sweeper *services.StatusTransitionSweeper
 }
 
 // NewLifecycleRulesHandler creates a new lifecycle rules handler
-func NewLifecycleRulesHandler() *LifecycleRulesHandler {
+func NewLifecycleRulesHandler(sweeper *services.StatusTransitionSweeper) *LifecycleRulesHandler {
 	return &LifecycleRulesHandler{
-		db: database.DB,
+		db:      database.DB,
+		sweeper: sweeper,
 	}
 }
 
@@ -369,6 +372,11 @@ func (h *LifecycleRulesHandler) CreateTransitionRule(c *gin.Context) {
 		return
 	}
 
+	if err := req.ValidateActions(); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
 	// Get user ID from context (set by auth middleware)
 	userID := getUserIDFromContext(c)
 	if userID == nil {
@@ -556,6 +564,11 @@ func (h *LifecycleRulesHandler) UpdateTransitionRule(c *gin.Context) {
 		return
 	}
 
+	if err := req.ValidateActions(); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
 	// Get user ID from context (set by auth middleware)
 	userID := getUserIDFromContext(c)
 	if userID == nil {
@@ -687,4 +700,41 @@ func (h *LifecycleRulesHandler) DeleteTransitionRule(c *gin.Context) {
 	})
 
 	c.JSON(http.StatusOK, NewSuccessResponse("Status transition rule deleted successfully", nil))
-}
\ No newline at end of file
+}
+
+// SweepTransitionRules godoc
+// @Summary Run scheduled status transitions
+// @Description Evaluate scheduled transition rules against all eligible contacts now. With dry_run=true the transitions are reported but not applied.
+// @Tags lifecycle-rules
+// @Produce json
+// @Param dry_run query bool false "Report without applying" default(true)
+// @Success 200 {object} APIResponse{data=models.TransitionSweepReport}
+// @Failure 400 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /lifecycle/transition-rules/sweep [post]
+func (h *LifecycleRulesHandler) SweepTransitionRules(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "true"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid dry_run value", err.Error()))
+		return
+	}
+
+	report, err := h.sweeper.Sweep(dryRun)
+	if err != nil {
+		logger.Error("Failed to sweep status transition rules", err, map[string]interface{}{
+			"dry_run": dryRun,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to run status transitions", err.Error()))
+		return
+	}
+
+	if !dryRun {
+		logger.Info("Status transition sweep run manually", map[string]interface{}{
+			"user_id": getUserIDFromContext(c),
+			"applied": report.Applied,
+		})
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Status transition sweep completed", report))
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"contact-service/internal/services"
+	"contact-service/pkg/logger"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler handles the current user's in-app notifications
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// GetNotifications godoc
+// @Summary List notifications
+// @Description List the current user's notifications, newest first, with the unread count
+// @Tags notifications
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} APIResponse{data=models.NotificationListResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /notifications [get]
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	page, limit := parsePaginationParams(c)
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, err := h.notificationService.ListNotifications(*userID, unreadOnly, page, limit)
+	if err != nil {
+		logger.Error("Failed to list notifications", err, map[string]interface{}{
+			"user_id": *userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to list notifications", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Notifications retrieved successfully", notifications))
+}
+
+// MarkNotificationRead godoc
+// @Summary Mark notification read
+// @Description Mark one of the current user's notifications as read
+// @Tags notifications
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} APIResponse{data=models.Notification}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Security BearerAuth
+// @Router /notifications/{id}/read [post]
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid notification ID", err.Error()))
+		return
+	}
+
+	notification, err := h.notificationService.MarkRead(*userID, uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Notification"))
+			return
+		}
+		logger.Error("Failed to mark notification read", err, map[string]interface{}{
+			"user_id":         *userID,
+			"notification_id": id,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to mark notification read", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Notification marked read", notification))
+}
+
+// MarkAllNotificationsRead godoc
+// @Summary Mark all notifications read
+// @Description Mark all of the current user's notifications as read
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	updated, err := h.notificationService.MarkAllRead(*userID)
+	if err != nil {
+		logger.Error("Failed to mark notifications read", err, map[string]interface{}{
+			"user_id": *userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to mark notifications read", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Notifications marked read", gin.H{"updated": updated}))
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/logger"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Send a single-use password reset link to the account with the email address. The link is sent in the background, so the response and its timing are the same whether or not the account exists.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Router /auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	h.passwords.RequestReset(req.Email, c.ClientIP())
+	c.JSON(http.StatusOK, NewSuccessResponse("If an account exists for this email, a password reset link has been sent", nil))
+}
+
+// ResetPassword godoc
+// @Summary Reset a password
+// @Description Set a new password with a reset token. The token works once; the reset unlocks the account and signs the user out everywhere.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userID, err := h.passwords.ResetPassword(req.Token, req.NewPassword)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "invalid or expired reset token"):
+			logger.LogSecurityEvent("password_reset_failed", nil, c.ClientIP(), map[string]interface{}{
+				"reason": "invalid_token",
+			})
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid or expired reset token", ""))
+		case strings.Contains(err.Error(), "invalid password"):
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Password does not meet the password policy", err.Error()))
+		default:
+			logger.Error("Failed to reset password", err, nil)
+			c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to reset password", ""))
+		}
+		return
+	}
+
+	logger.LogSecurityEvent("password_reset", &userID, c.ClientIP(), nil)
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Password reset successfully; please log in with your new password", nil))
+}
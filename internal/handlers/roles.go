@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"contact-service/internal/models"
+	"contact-service/internal/services"
+	"contact-service/pkg/logger"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler handles roles, their permissions and per-user permission overrides
+type RoleHandler struct {
+	permissions *services.PermissionService
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(permissions *services.PermissionService) *RoleHandler {
+	return &RoleHandler{
+		permissions: permissions,
+	}
+}
+
+// GetRoles godoc
+// @Summary List roles
+// @Description List every role with its permissions and how many users have it (requires roles:manage)
+// @Tags roles
+// @Produce json
+// @Success 200 {object} APIResponse{data=[]models.RoleResponse}
+// @Failure 403 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /roles [get]
+func (h *RoleHandler) GetRoles(c *gin.Context) {
+	roles, err := h.permissions.ListRoles()
+	if err != nil {
+		logger.Error("Failed to list roles", err, nil)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to list roles", ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Roles retrieved successfully", roles))
+}
+
+// GetRole godoc
+// @Summary Get role
+// @Description Get a role with its permissions (requires roles:manage)
+// @Tags roles
+// @Produce json
+// @Param name path string true "Role name"
+// @Success 200 {object} APIResponse{data=models.RoleResponse}
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /roles/{name} [get]
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, err := h.permissions.GetRole(c.Param("name"))
+	if err != nil {
+		respondRoleError(c, "Failed to get role", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Role retrieved successfully", role))
+}
+
+// CreateRole godoc
+// @Summary Create a role
+// @Description Create a custom role. Permissions are "resource:action", "resource:*" or "*" (requires roles:manage).
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param request body models.RoleRequest true "Role"
+// @Success 201 {object} APIResponse{data=models.RoleResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req models.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	role, err := h.permissions.CreateRole(&req)
+	if err != nil {
+		respondRoleError(c, "Failed to create role", err)
+		return
+	}
+
+	logger.LogSecurityEvent("role_created", getUserIDFromContext(c), c.ClientIP(), map[string]interface{}{
+		"role":        role.Name,
+		"permissions": role.Permissions,
+	})
+
+	c.JSON(http.StatusCreated, NewSuccessResponse("Role created successfully", role))
+}
+
+// UpdateRole godoc
+// @Summary Update a role
+// @Description Change a role's description and, when given, replace its permissions. The admin role cannot be changed (requires roles:manage).
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param name path string true "Role name"
+// @Param request body models.UpdateRoleRequest true "Changes"
+// @Success 200 {object} APIResponse{data=models.RoleResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /roles/{name} [put]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	role, err := h.permissions.UpdateRole(c.Param("name"), &req)
+	if err != nil {
+		respondRoleError(c, "Failed to update role", err)
+		return
+	}
+
+	logger.LogSecurityEvent("role_updated", getUserIDFromContext(c), c.ClientIP(), map[string]interface{}{
+		"role":        role.Name,
+		"permissions": role.Permissions,
+	})
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Role updated successfully", role))
+}
+
+// DeleteRole godoc
+// @Summary Delete a role
+// @Description Delete a custom role that no user has (requires roles:manage)
+// @Tags roles
+// @Produce json
+// @Param name path string true "Role name"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /roles/{name} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.permissions.DeleteRole(name); err != nil {
+		respondRoleError(c, "Failed to delete role", err)
+		return
+	}
+
+	logger.LogSecurityEvent("role_deleted", getUserIDFromContext(c), c.ClientIP(), map[string]interface{}{
+		"role": name,
+	})
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Role deleted successfully", nil))
+}
+
+// GetUserPermissions godoc
+// @Summary Get a user's permissions
+// @Description Get the permissions a user has through their role and their permission overrides (requires users:manage)
+// @Tags roles
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} APIResponse{data=models.UserPermissionsResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/{id}/permissions [get]
+func (h *RoleHandler) GetUserPermissions(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	permissions, err := h.permissions.GetUserPermissions(userID)
+	if err != nil {
+		respondRoleError(c, "Failed to get user permissions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("User permissions retrieved successfully", permissions))
+}
+
+// SetUserPermission godoc
+// @Summary Override a user's permission
+// @Description Grant a permission to a user or deny it from them on top of their role. Denials win over any grant (requires roles:manage).
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body models.UserPermissionOverrideRequest true "Permission and effect"
+// @Success 200 {object} APIResponse{data=models.UserPermissionOverride}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/{id}/permissions [put]
+func (h *RoleHandler) SetUserPermission(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	var req models.UserPermissionOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+	adminID := getUserIDFromContext(c)
+	if adminID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	override, err := h.permissions.SetUserOverride(userID, &req, *adminID)
+	if err != nil {
+		respondRoleError(c, "Failed to set user permission", err)
+		return
+	}
+
+	logger.LogSecurityEvent("user_permission_overridden", adminID, c.ClientIP(), map[string]interface{}{
+		"target_user_id": userID,
+		"permission":     override.Permission,
+		"effect":         override.Effect,
+	})
+
+	c.JSON(http.StatusOK, NewSuccessResponse("User permission set successfully", override))
+}
+
+// DeleteUserPermission godoc
+// @Summary Remove a user's permission override
+// @Description Remove a user's grant or denial of a permission, leaving it to their role (requires roles:manage)
+// @Tags roles
+// @Produce json
+// @Param id path int true "User ID"
+// @Param permission path string true "Permission, e.g. contacts:delete"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/{id}/permissions/{permission} [delete]
+func (h *RoleHandler) DeleteUserPermission(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	permission := c.Param("permission")
+
+	if err := h.permissions.DeleteUserOverride(userID, permission); err != nil {
+		respondRoleError(c, "Failed to remove user permission", err)
+		return
+	}
+
+	logger.LogSecurityEvent("user_permission_override_removed", getUserIDFromContext(c), c.ClientIP(), map[string]interface{}{
+		"target_user_id": userID,
+		"permission":     permission,
+	})
+
+	c.JSON(http.StatusOK, NewSuccessResponse("User permission override removed successfully", nil))
+}
+
+// GetMyPermissions godoc
+// @Summary Get my permissions
+// @Description Get the permissions the current user has through their role and their permission overrides
+// @Tags auth
+// @Produce json
+// @Success 200 {object} APIResponse{data=models.UserPermissionsResponse}
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /auth/permissions [get]
+func (h *RoleHandler) GetMyPermissions(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	permissions, err := h.permissions.GetUserPermissions(*userID)
+	if err != nil {
+		respondRoleError(c, "Failed to get permissions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Permissions retrieved successfully", permissions))
+}
+
+func respondRoleError(c *gin.Context, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "already exists"):
+		c.JSON(http.StatusConflict, NewConflictResponse(err.Error()))
+	case strings.Contains(err.Error(), "invalid"), strings.Contains(err.Error(), "cannot"):
+		c.JSON(http.StatusBadRequest, NewErrorResponse(message, err.Error()))
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, NewErrorResponse(message, err.Error()))
+	default:
+		logger.Error(message, err, nil)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(message, ""))
+	}
+}
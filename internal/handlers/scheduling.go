@@ -19,9 +19,9 @@ type SchedulingHandler struct {
 }
 
 // NewSchedulingHandler creates a new scheduling handler
-func NewSchedulingHandler() *SchedulingHandler {
+func NewSchedulingHandler(schedulingService *services.SchedulingService) *SchedulingHandler {
 	return &SchedulingHandler{
-		schedulingService: services.NewSchedulingService(database.DB),
+		schedulingService: schedulingService,
 	}
 }
 
@@ -67,6 +67,10 @@ func (h *SchedulingHandler) CreateAppointment(c *gin.Context) {
 			c.JSON(http.StatusConflict, NewErrorResponse("Scheduling conflict", err.Error()))
 			return
 		}
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "cannot") {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Failed to create appointment", err.Error()))
+			return
+		}
 		
 		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to create appointment", err.Error()))
 		return
@@ -119,6 +123,7 @@ func (h *SchedulingHandler) GetAppointment(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Appointment ID"
+// @Param scope query string false "For series occurrences: this (default), following or all"
 // @Param appointment body models.AppointmentRequest true "Updated appointment data"
 // @Success 200 {object} APIResponse{data=models.AppointmentResponse}
 // @Failure 400 {object} APIResponse
@@ -134,6 +139,12 @@ func (h *SchedulingHandler) UpdateAppointment(c *gin.Context) {
 		return
 	}
 
+	scope, err := models.ParseSeriesEditScope(c.Query("scope"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid scope", err.Error()))
+		return
+	}
+
 	var req models.AppointmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
@@ -147,19 +158,33 @@ func (h *SchedulingHandler) UpdateAppointment(c *gin.Context) {
 		return
 	}
 
-	appointment, err := h.schedulingService.UpdateAppointment(uint(appointmentID), &req, *userID)
+	var appointment interface{}
+	if scope == models.SeriesScopeThis {
+		appointment, err = h.schedulingService.UpdateAppointment(uint(appointmentID), &req, *userID)
+	} else {
+		appointment, err = h.schedulingService.UpdateSeriesAppointment(uint(appointmentID), scope, &req, *userID)
+	}
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, NewErrorResponse("Appointment not found", ""))
+			c.JSON(http.StatusNotFound, NewErrorResponse("Appointment not found", err.Error()))
 			return
 		}
 		if strings.Contains(err.Error(), "conflict") {
 			c.JSON(http.StatusConflict, NewErrorResponse("Scheduling conflict", err.Error()))
 			return
 		}
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "cannot") {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Failed to update appointment", err.Error()))
+			return
+		}
+		if strings.Contains(err.Error(), "already") {
+			c.JSON(http.StatusConflict, NewConflictResponse(err.Error()))
+			return
+		}
 		
 		logger.Error("Failed to update appointment", err, map[string]interface{}{
 			"appointment_id": appointmentID,
+			"scope":          scope,
 			"user_id":        *userID,
 		})
 		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to update appointment", err.Error()))
@@ -287,6 +312,7 @@ func (h *SchedulingHandler) RescheduleAppointment(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Appointment ID"
+// @Param scope query string false "For series occurrences: this (default), following or all"
 // @Param cancel body map[string]string true "Cancellation data"
 // @Success 200 {object} APIResponse
 // @Failure 400 {object} APIResponse
@@ -301,6 +327,12 @@ func (h *SchedulingHandler) CancelAppointment(c *gin.Context) {
 		return
 	}
 
+	scope, err := models.ParseSeriesEditScope(c.Query("scope"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid scope", err.Error()))
+		return
+	}
+
 	var requestData map[string]string
 	if err := c.ShouldBindJSON(&requestData); err != nil {
 		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
@@ -319,10 +351,22 @@ func (h *SchedulingHandler) CancelAppointment(c *gin.Context) {
 		return
 	}
 
-	err = h.schedulingService.CancelAppointment(uint(appointmentID), reason, *userID)
+	if scope == models.SeriesScopeThis {
+		err = h.schedulingService.CancelAppointment(uint(appointmentID), reason, *userID)
+	} else {
+		err = h.schedulingService.CancelSeriesAppointment(uint(appointmentID), scope, reason, *userID)
+	}
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, NewErrorResponse("Appointment not found", ""))
+			c.JSON(http.StatusNotFound, NewErrorResponse("Appointment not found", err.Error()))
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "cannot") {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Failed to cancel appointment", err.Error()))
+			return
+		}
+		if strings.Contains(err.Error(), "already") {
+			c.JSON(http.StatusConflict, NewConflictResponse(err.Error()))
 			return
 		}
 		
@@ -338,6 +382,42 @@ func (h *SchedulingHandler) CancelAppointment(c *gin.Context) {
 	c.JSON(http.StatusOK, NewSuccessResponse("Appointment cancelled successfully", nil))
 }
 
+// GetAppointmentSeries godoc
+// @Summary Get appointment series
+// @Description Get a recurring appointment series with its upcoming occurrences
+// @Tags appointments
+// @Produce json
+// @Param id path int true "Series ID"
+// @Success 200 {object} APIResponse{data=models.AppointmentSeriesResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /appointments/series/{id} [get]
+func (h *SchedulingHandler) GetAppointmentSeries(c *gin.Context) {
+	seriesID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid series ID", err.Error()))
+		return
+	}
+
+	series, err := h.schedulingService.GetAppointmentSeries(uint(seriesID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Appointment series"))
+			return
+		}
+
+		logger.Error("Failed to get appointment series", err, map[string]interface{}{
+			"series_id": seriesID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get appointment series", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Appointment series retrieved successfully", series))
+}
+
 // GetUserAppointments godoc
 // @Summary Get user appointments
 // @Description Get appointments for a specific user with optional filters
@@ -462,6 +542,10 @@ func (h *SchedulingHandler) FindAvailableSlots(c *gin.Context) {
 			"end_date":   req.EndDate,
 			"duration":   req.Duration,
 		})
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Failed to find available slots", err.Error()))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to find available slots", err.Error()))
 		return
 	}
@@ -471,7 +555,7 @@ func (h *SchedulingHandler) FindAvailableSlots(c *gin.Context) {
 
 // GetUserAvailability godoc
 // @Summary Get user availability
-// @Description Get availability information for a user on a specific date
+// @Description Get a user's working hours, free 30-minute slots and appointments on a date, in the user's schedule timezone
 // @Tags availability
 // @Accept json
 // @Produce json
@@ -659,7 +743,7 @@ func (h *SchedulingHandler) GetUpcomingAppointments(c *gin.Context) {
 
 // GetAppointments retrieves appointments with filtering
 func GetAppointments(c *gin.Context) {
-	_ = NewSchedulingHandler()
+	_ = NewSchedulingHandler(services.NewSchedulingService(database.DB))
 	
 	// Parse query parameters
 	_, _ = parsePaginationParams(c)
@@ -683,7 +767,7 @@ func GetAppointments(c *gin.Context) {
 
 // ConfirmAppointment confirms an appointment
 func ConfirmAppointment(c *gin.Context) {
-	handler := NewSchedulingHandler()
+	handler := NewSchedulingHandler(services.NewSchedulingService(database.DB))
 	
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -714,7 +798,7 @@ func ConfirmAppointment(c *gin.Context) {
 
 // RescheduleAppointment reschedules an appointment
 func RescheduleAppointment(c *gin.Context) {
-	handler := NewSchedulingHandler()
+	handler := NewSchedulingHandler(services.NewSchedulingService(database.DB))
 	
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -746,7 +830,7 @@ func RescheduleAppointment(c *gin.Context) {
 
 // CancelAppointment cancels an appointment
 func CancelAppointment(c *gin.Context) {
-	handler := NewSchedulingHandler()
+	handler := NewSchedulingHandler(services.NewSchedulingService(database.DB))
 	
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
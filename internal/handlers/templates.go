@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"contact-service/internal/models"
+	"contact-service/internal/services"
+	"contact-service/pkg/database"
+	"contact-service/pkg/logger"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TemplateHandler handles communication template requests
+type TemplateHandler struct {
+	templateService *services.TemplateService
+}
+
+// NewTemplateHandler creates a new template handler
+func NewTemplateHandler() *TemplateHandler {
+	return &TemplateHandler{
+		templateService: services.NewTemplateService(database.DB),
+	}
+}
+
+// GetTemplates godoc
+// @Summary List communication templates
+// @Description Get communication templates ordered by usage
+// @Tags templates
+// @Produce json
+// @Param communication_type query string false "Channel (email, sms, whatsapp)"
+// @Param category query string false "Template category"
+// @Param active query bool false "Only return active templates"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} APIResponse{data=PaginatedResponse}
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /templates [get]
+func (h *TemplateHandler) GetTemplates(c *gin.Context) {
+	page, limit := parsePaginationParams(c)
+	activeOnly := c.Query("active") == "true"
+
+	templates, total, err := h.templateService.ListTemplates(c.Query("communication_type"), c.Query("category"), activeOnly, page, limit)
+	if err != nil {
+		logger.Error("Failed to get communication templates", err, nil)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get templates", err.Error()))
+		return
+	}
+
+	response := NewPaginatedResponseWithItems(templates, int(total), page, limit)
+	c.JSON(http.StatusOK, NewSuccessResponse("Templates retrieved successfully", response))
+}
+
+// GetTemplate godoc
+// @Summary Get a communication template
+// @Description Get a communication template by ID
+// @Tags templates
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} APIResponse{data=models.CommunicationTemplate}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Security BearerAuth
+// @Router /templates/{id} [get]
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid template ID", err.Error()))
+		return
+	}
+
+	template, err := h.templateService.GetTemplate(uint(templateID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Template"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get template", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Template retrieved successfully", template))
+}
+
+// CreateTemplate godoc
+// @Summary Create a communication template
+// @Description Create an email, SMS or WhatsApp template with {{variable}} placeholders
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param request body models.CommunicationTemplateRequest true "Template"
+// @Success 201 {object} APIResponse{data=models.CommunicationTemplate}
+// @Failure 400 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /templates [post]
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	var req models.CommunicationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	template, err := h.templateService.CreateTemplate(&req, *userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid template", err.Error()))
+			return
+		}
+		logger.Error("Failed to create communication template", err, map[string]interface{}{
+			"name": req.Name,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to create template", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewSuccessResponse("Template created successfully", template))
+}
+
+// UpdateTemplate godoc
+// @Summary Update a communication template
+// @Description Replace the content and configuration of a communication template
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param request body models.CommunicationTemplateRequest true "Template"
+// @Success 200 {object} APIResponse{data=models.CommunicationTemplate}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /templates/{id} [put]
+func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid template ID", err.Error()))
+		return
+	}
+
+	var req models.CommunicationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	template, err := h.templateService.UpdateTemplate(uint(templateID), &req, *userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Template"))
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid template", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to update template", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Template updated successfully", template))
+}
+
+// DeleteTemplate godoc
+// @Summary Delete a communication template
+// @Description Delete a communication template; system templates cannot be deleted
+// @Tags templates
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Security BearerAuth
+// @Router /templates/{id} [delete]
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid template ID", err.Error()))
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	if err := h.templateService.DeleteTemplate(uint(templateID), *userID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Template"))
+			return
+		}
+		if strings.Contains(err.Error(), "cannot be deleted") {
+			c.JSON(http.StatusConflict, NewConflictResponse(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to delete template", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Template deleted successfully", nil))
+}
+
+// RenderTemplate godoc
+// @Summary Render a communication template
+// @Description Resolve a template's variables for a contact, optionally with an appointment and sender, and report missing variables
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param request body models.TemplateRenderRequest true "Render request"
+// @Success 200 {object} APIResponse{data=models.RenderedTemplate}
+// @Failure 400 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 422 {object} APIResponse{data=models.RenderedTemplate}
+// @Security BearerAuth
+// @Router /templates/{id}/render [post]
+func (h *TemplateHandler) RenderTemplate(c *gin.Context) {
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid template ID", err.Error()))
+		return
+	}
+
+	var req models.TemplateRenderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	rendered, err := h.templateService.RenderTemplate(uint(templateID), &req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "communication template not found"):
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Template"))
+		case strings.Contains(err.Error(), "contact not found"):
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Contact"))
+		case strings.Contains(err.Error(), "appointment not found"):
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Appointment"))
+		case strings.Contains(err.Error(), "sender not found"):
+			c.JSON(http.StatusNotFound, NewNotFoundResponse("Sender"))
+		case strings.Contains(err.Error(), "invalid"), strings.Contains(err.Error(), "inactive"):
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Cannot render template", err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to render template", err.Error()))
+		}
+		return
+	}
+
+	// Previews report missing variables alongside the partial render; real renders refuse them
+	if !rendered.IsComplete() && !req.Preview {
+		response := NewErrorResponseWithCode("MISSING_VARIABLES", "Template has missing variables", strings.Join(rendered.MissingVariables, ", "))
+		response.Data = rendered
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Template rendered successfully", rendered))
+}
+
+// GetTemplateVariables godoc
+// @Summary List template variables
+// @Description List the variables templates can reference, grouped by source
+// @Tags templates
+// @Produce json
+// @Success 200 {object} APIResponse{data=[]models.TemplateVariable}
+// @Security BearerAuth
+// @Router /templates/variables [get]
+func (h *TemplateHandler) GetTemplateVariables(c *gin.Context) {
+	c.JSON(http.StatusOK, NewSuccessResponse("Template variables retrieved successfully", h.templateService.GetAvailableVariables()))
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"contact-service/internal/services"
+	"contact-service/pkg/logger"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// transparentGIF is a 1x1 transparent GIF served by the open-tracking pixel
+var transparentGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// TrackingHandler handles the public email open and click tracking endpoints
+type TrackingHandler struct {
+	trackingService *services.EmailTrackingService
+}
+
+// NewTrackingHandler creates a new tracking handler
+func NewTrackingHandler(trackingService *services.EmailTrackingService) *TrackingHandler {
+	return &TrackingHandler{
+		trackingService: trackingService,
+	}
+}
+
+// TrackOpen godoc
+// @Summary Email open tracking pixel
+// @Description Record an email open and return a 1x1 transparent GIF. The pixel is always served, even when the request cannot be recorded.
+// @Tags tracking
+// @Produce image/gif
+// @Param id path int true "Communication ID"
+// @Param signature path string true "Tracking signature"
+// @Success 200 {file} binary
+// @Router /track/open/{id}/{signature} [get]
+func (h *TrackingHandler) TrackOpen(c *gin.Context) {
+	if communicationID, err := strconv.ParseUint(c.Param("id"), 10, 32); err == nil {
+		if err := h.trackingService.RecordOpen(uint(communicationID), c.Param("signature")); err != nil && !isIgnorableTrackingError(err) {
+			logger.Error("Failed to record email open", err, map[string]interface{}{
+				"communication_id": communicationID,
+			})
+		}
+	}
+
+	c.Header("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+	c.Header("Pragma", "no-cache")
+	c.Data(http.StatusOK, "image/gif", transparentGIF)
+}
+
+// TrackClick godoc
+// @Summary Email click tracking redirect
+// @Description Record a click on a link in an email and redirect to the link target. Only signed links are redirected.
+// @Tags tracking
+// @Param id path int true "Communication ID"
+// @Param signature path string true "Tracking signature"
+// @Param url query string true "Link target"
+// @Success 302
+// @Failure 400 {object} APIResponse
+// @Router /track/click/{id}/{signature} [get]
+func (h *TrackingHandler) TrackClick(c *gin.Context) {
+	communicationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid tracking link", err.Error()))
+		return
+	}
+
+	target, err := h.trackingService.RecordClick(uint(communicationID), c.Query("url"), c.Param("signature"))
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid tracking signature") {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid tracking link", err.Error()))
+			return
+		}
+		// The link was signed by us, so still send the reader on their way
+		if !isIgnorableTrackingError(err) {
+			logger.Error("Failed to record email click", err, map[string]interface{}{
+				"communication_id": communicationID,
+			})
+		}
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, target)
+}
+
+// isIgnorableTrackingError reports tracking failures caused by forged or stale links rather than the service
+func isIgnorableTrackingError(err error) bool {
+	return strings.Contains(err.Error(), "invalid tracking signature") || strings.Contains(err.Error(), "not found")
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/auth"
+	"contact-service/pkg/logger"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyMFA godoc
+// @Summary Complete a two-factor login
+// @Description Exchange the mfa_token returned by login and a TOTP or recovery code for the session tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param verification body MFAVerifyRequest true "Pending login token and code"
+// @Success 200 {object} APIResponse{data=LoginResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	claims, err := auth.ValidateMFAToken(req.MFAToken)
+	if err != nil {
+		logger.LogSecurityEvent("mfa_token_invalid", nil, c.ClientIP(), map[string]interface{}{
+			"error": err.Error(),
+		})
+		c.JSON(http.StatusUnauthorized, NewErrorResponse("Invalid or expired two-factor token", "Please log in again"))
+		return
+	}
+
+	var user models.AdminUser
+	if err := h.db.Where("id = ? AND is_active = ?", claims.UserID, true).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, NewErrorResponse("Invalid credentials", ""))
+		return
+	}
+
+	// Codes count towards the same failed attempts and lockout as passwords
+	if user.IsLocked(time.Now()) {
+		h.respondLocked(c, &user, *user.LockedUntil)
+		return
+	}
+
+	method, err := h.twoFactor.Verify(user.ID, req.Code)
+	if err != nil {
+		logger.LogSecurityEvent("login_failed", &user.ID, c.ClientIP(), map[string]interface{}{
+			"email":  user.Email,
+			"reason": "invalid_two_factor_code",
+		})
+		h.recordFailedLogin(c, &user)
+		return
+	}
+
+	if method == "recovery_code" {
+		logger.LogSecurityEvent("recovery_code_used", &user.ID, c.ClientIP(), map[string]interface{}{
+			"email": user.Email,
+		})
+	}
+
+	h.completeLogin(c, &user, true)
+}
+
+// GetTwoFactorStatus godoc
+// @Summary Get my two-factor status
+// @Description Whether two-factor authentication is enabled, how many recovery codes are left and whether the user's role requires it
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} APIResponse{data=models.TwoFactorStatus}
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /auth/mfa [get]
+func (h *AuthHandler) GetTwoFactorStatus(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	status, err := h.twoFactor.Status(*userID)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to get two-factor status", err, *userID)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Two-factor status retrieved successfully", status))
+}
+
+// EnrollTwoFactor godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and its otpauth:// URI for an authenticator app. Two-factor authentication is enabled once a code is confirmed.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} APIResponse{data=models.TwoFactorEnrollment}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /auth/mfa/enroll [post]
+func (h *AuthHandler) EnrollTwoFactor(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	enrollment, err := h.twoFactor.Enroll(*userID)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to start two-factor enrollment", err, *userID)
+		return
+	}
+
+	logger.LogSecurityEvent("mfa_enrollment_started", userID, c.ClientIP(), nil)
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Scan the secret with your authenticator app and confirm a code", enrollment))
+}
+
+// ConfirmTwoFactor godoc
+// @Summary Confirm two-factor enrollment
+// @Description Enable two-factor authentication with a code from the enrolled secret. Returns recovery codes, which are shown only once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} APIResponse{data=models.RecoveryCodesResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /auth/mfa/confirm [post]
+func (h *AuthHandler) ConfirmTwoFactor(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	claims := getTokenClaimsFromContext(c)
+	if claims == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	codes, err := h.twoFactor.Confirm(claims.UserID, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to enable two-factor authentication", err, claims.UserID)
+		return
+	}
+
+	// The session that proved the code counts as verified from now on
+	if err := h.sessions.MarkMFAVerified(claims); err != nil {
+		logger.Error("Failed to mark session two-factor verified", err, map[string]interface{}{
+			"user_id": claims.UserID,
+		})
+	}
+
+	logger.LogSecurityEvent("mfa_enabled", &claims.UserID, c.ClientIP(), nil)
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Two-factor authentication enabled", &models.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	}))
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes after checking a TOTP code. The new codes are shown only once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} APIResponse{data=models.RecoveryCodesResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /auth/mfa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	codes, err := h.twoFactor.RegenerateRecoveryCodes(*userID, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to regenerate recovery codes", err, *userID)
+		return
+	}
+
+	logger.LogSecurityEvent("recovery_codes_regenerated", userID, c.ClientIP(), nil)
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Recovery codes regenerated", &models.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	}))
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off after checking a TOTP or recovery code; not allowed when the user's role requires it
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 401 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /auth/mfa/disable [post]
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userID := getUserIDFromContext(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	if err := h.twoFactor.Disable(*userID, req.Code); err != nil {
+		respondTwoFactorError(c, "Failed to disable two-factor authentication", err, *userID)
+		return
+	}
+
+	logger.LogSecurityEvent("mfa_disabled", userID, c.ClientIP(), nil)
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Two-factor authentication disabled", nil))
+}
+
+// GetTwoFactorPolicies godoc
+// @Summary List two-factor policies
+// @Description List the roles whose two-factor policy has been set (requires users:manage)
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} APIResponse{data=[]models.TwoFactorPolicy}
+// @Failure 403 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/two-factor-policies [get]
+func (h *AuthHandler) GetTwoFactorPolicies(c *gin.Context) {
+	policies, err := h.twoFactor.ListPolicies()
+	if err != nil {
+		logger.Error("Failed to get two-factor policies", err, nil)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to get two-factor policies", ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Two-factor policies retrieved successfully", policies))
+}
+
+// SetTwoFactorPolicy godoc
+// @Summary Set a role's two-factor policy
+// @Description Require two-factor authentication for a role. Sessions of that role without a two-factor login are refused by endpoints that require a permission (requires users:manage).
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param role path string true "Role" Enums(admin, editor, hr_manager, content_writer)
+// @Param policy body models.TwoFactorPolicyRequest true "Policy"
+// @Success 200 {object} APIResponse{data=models.TwoFactorPolicy}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/two-factor-policies/{role} [put]
+func (h *AuthHandler) SetTwoFactorPolicy(c *gin.Context) {
+	var req models.TwoFactorPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	adminID := getUserIDFromContext(c)
+	if adminID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	policy, err := h.twoFactor.SetPolicy(c.Param("role"), *req.Required, *adminID)
+	if err != nil {
+		respondTwoFactorError(c, "Failed to set two-factor policy", err, *adminID)
+		return
+	}
+
+	logger.LogSecurityEvent("mfa_policy_changed", adminID, c.ClientIP(), map[string]interface{}{
+		"role":     policy.Role,
+		"required": policy.Required,
+	})
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Two-factor policy updated successfully", policy))
+}
+
+func respondTwoFactorError(c *gin.Context, message string, err error, userID uint) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, NewErrorResponse(message, err.Error()))
+	case strings.Contains(err.Error(), "invalid"), strings.Contains(err.Error(), "cannot"),
+		strings.Contains(err.Error(), "already"), strings.Contains(err.Error(), "not enabled"),
+		strings.Contains(err.Error(), "not been started"):
+		c.JSON(http.StatusBadRequest, NewErrorResponse(message, err.Error()))
+	default:
+		logger.Error(message, err, map[string]interface{}{
+			"user_id": userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(message, ""))
+	}
+}
+
+// MFAVerifyRequest completes a login that requires a two-factor code
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required,max=32"` // TOTP or recovery code
+}
@@ -0,0 +1,398 @@
+package handlers
+
+import (
+	"contact-service/internal/models"
+	"contact-service/internal/repository"
+	"contact-service/internal/services"
+	"contact-service/pkg/logger"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserHandler handles admin user management requests
+type UserHandler struct {
+	users *services.UserManagementService
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(users *services.UserManagementService) *UserHandler {
+	return &UserHandler{
+		users: users,
+	}
+}
+
+// GetUsers godoc
+// @Summary List users
+// @Description List admin users with filters (requires users:manage)
+// @Tags users
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param role query string false "Filter by role"
+// @Param active query bool false "Filter by active status"
+// @Param search query string false "Search name and email"
+// @Param sort query string false "Sort by name, email, role, created_at or last_login_at" default(name)
+// @Param order query string false "Sort order (asc or desc)" default(asc)
+// @Success 200 {object} APIResponse{data=PaginatedResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users [get]
+func (h *UserHandler) GetUsers(c *gin.Context) {
+	page, limit := parsePaginationParams(c)
+	params := repository.UserListParams{
+		Page:   page,
+		Limit:  limit,
+		Sort:   c.Query("sort"),
+		Order:  c.DefaultQuery("order", "asc"),
+		Role:   c.Query("role"),
+		Search: strings.TrimSpace(c.Query("search")),
+	}
+	if activeStr := c.Query("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid active filter", err.Error()))
+			return
+		}
+		params.Active = &active
+	}
+
+	users, total, err := h.users.ListUsers(params)
+	if err != nil {
+		logger.Error("Failed to list users", err, nil)
+		c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to list users", ""))
+		return
+	}
+
+	items := make([]*models.AdminUserResponse, len(users))
+	for i := range users {
+		items[i] = users[i].ToResponse()
+	}
+	response := NewPaginatedResponseWithItems(items, int(total), page, limit)
+	c.JSON(http.StatusOK, NewSuccessResponse("Users retrieved successfully", response))
+}
+
+// GetUser godoc
+// @Summary Get user
+// @Description Get an admin user (requires users:manage)
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} APIResponse{data=models.AdminUserResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/{id} [get]
+func (h *UserHandler) GetUser(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.users.GetUser(userID)
+	if err != nil {
+		respondUserError(c, "Failed to get user", err, userID)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("User retrieved successfully", user.ToResponse()))
+}
+
+// InviteUser godoc
+// @Summary Invite a user
+// @Description Create an inactive account and email the user a link to activate it by choosing a password. When the email cannot be sent the account is still created and 202 is returned with email_sent false; resend the invitation (requires users:manage).
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body models.InviteUserRequest true "User details"
+// @Success 201 {object} APIResponse{data=models.UserInvitationResponse}
+// @Success 202 {object} APIResponse{data=models.UserInvitationResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/invite [post]
+func (h *UserHandler) InviteUser(c *gin.Context) {
+	var req models.InviteUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	adminID := getUserIDFromContext(c)
+	if adminID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	user, invitation, err := h.users.InviteUser(&req, *adminID)
+	if err != nil && user == nil {
+		respondUserError(c, "Failed to invite user", err, 0)
+		return
+	}
+
+	logger.LogSecurityEvent("user_invited", adminID, c.ClientIP(), map[string]interface{}{
+		"target_user_id": user.ID,
+		"role":           user.Role,
+	})
+
+	response := &models.UserInvitationResponse{
+		User:      user.ToResponse(),
+		ExpiresAt: invitation.ExpiresAt,
+		EmailSent: err == nil,
+	}
+	if err != nil {
+		// The account exists, so a retry has to resend the invitation rather than invite again
+		logger.Error("Failed to send invitation", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+		c.JSON(http.StatusAccepted, NewSuccessResponse("User created, but the invitation email could not be sent; resend the invitation", response))
+		return
+	}
+
+	c.JSON(http.StatusCreated, NewSuccessResponse("User invited successfully", response))
+}
+
+// ResendInvitation godoc
+// @Summary Resend an invitation
+// @Description Email a user who has not activated their account a new activation link; the previous link stops working (requires users:manage)
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} APIResponse{data=models.UserInvitationResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/{id}/invite [post]
+func (h *UserHandler) ResendInvitation(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	adminID := getUserIDFromContext(c)
+	if adminID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	user, invitation, err := h.users.ResendInvitation(userID, *adminID)
+	if err != nil {
+		respondUserError(c, "Failed to resend invitation", err, userID)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Invitation sent successfully", &models.UserInvitationResponse{
+		User:      user.ToResponse(),
+		ExpiresAt: invitation.ExpiresAt,
+		EmailSent: true,
+	}))
+}
+
+// UpdateUserRole godoc
+// @Summary Change a user's role
+// @Description Change an admin user's role. The user is signed out everywhere so the new role applies at once (requires roles:manage).
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body models.UpdateUserRoleRequest true "New role"
+// @Success 200 {object} APIResponse{data=models.AdminUserResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/{id}/role [put]
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	var req models.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+	adminID := getUserIDFromContext(c)
+	if adminID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	user, err := h.users.UpdateRole(userID, req.Role, *adminID)
+	if err != nil {
+		respondUserError(c, "Failed to update user role", err, userID)
+		return
+	}
+
+	logger.LogSecurityEvent("user_role_changed", adminID, c.ClientIP(), map[string]interface{}{
+		"target_user_id": userID,
+		"role":           req.Role,
+	})
+
+	c.JSON(http.StatusOK, NewSuccessResponse("User role updated successfully", user.ToResponse()))
+}
+
+// DeactivateUser godoc
+// @Summary Deactivate a user
+// @Description Disable an admin user's account, sign them out everywhere and revoke their calendar feed. Their open contacts are reassigned to reassign_to, or unassigned when it is not given (requires users:manage).
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body models.DeactivateUserRequest false "Where the user's open contacts go"
+// @Success 200 {object} APIResponse{data=models.DeactivateUserResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/{id}/deactivate [post]
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	var req models.DeactivateUserRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+			return
+		}
+	}
+	adminID := getUserIDFromContext(c)
+	if adminID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	result, err := h.users.DeactivateUser(userID, &req, *adminID)
+	if err != nil {
+		respondUserError(c, "Failed to deactivate user", err, userID)
+		return
+	}
+
+	logger.LogSecurityEvent("user_deactivated", adminID, c.ClientIP(), map[string]interface{}{
+		"target_user_id":      userID,
+		"reassigned_contacts": result.ReassignedContacts,
+		"unassigned_contacts": result.UnassignedContacts,
+	})
+
+	c.JSON(http.StatusOK, NewSuccessResponse("User deactivated successfully", result))
+}
+
+// ReactivateUser godoc
+// @Summary Reactivate a user
+// @Description Re-enable a deactivated admin user's account (requires users:manage)
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} APIResponse{data=models.AdminUserResponse}
+// @Failure 400 {object} APIResponse
+// @Failure 403 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Security BearerAuth
+// @Router /users/{id}/reactivate [post]
+func (h *UserHandler) ReactivateUser(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	adminID := getUserIDFromContext(c)
+	if adminID == nil {
+		c.JSON(http.StatusUnauthorized, NewUnauthorizedResponse())
+		return
+	}
+
+	user, err := h.users.ReactivateUser(userID, *adminID)
+	if err != nil {
+		respondUserError(c, "Failed to reactivate user", err, userID)
+		return
+	}
+
+	logger.LogSecurityEvent("user_reactivated", adminID, c.ClientIP(), map[string]interface{}{
+		"target_user_id": userID,
+	})
+
+	c.JSON(http.StatusOK, NewSuccessResponse("User reactivated successfully", user.ToResponse()))
+}
+
+// ActivateAccount godoc
+// @Summary Activate an invited account
+// @Description Accept an invitation by choosing a password under the password policy. The activation token works once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ActivateAccountRequest true "Activation token and password"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /auth/activate [post]
+func (h *UserHandler) ActivateAccount(c *gin.Context) {
+	var req models.ActivateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	userID, err := h.users.ActivateAccount(req.Token, req.Password)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "invalid or expired activation token"):
+			logger.LogSecurityEvent("account_activation_failed", nil, c.ClientIP(), map[string]interface{}{
+				"reason": "invalid_token",
+			})
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid or expired activation token", ""))
+		case strings.Contains(err.Error(), "invalid password"):
+			c.JSON(http.StatusBadRequest, NewErrorResponse("Password does not meet the password policy", err.Error()))
+		default:
+			logger.Error("Failed to activate account", err, nil)
+			c.JSON(http.StatusInternalServerError, NewErrorResponse("Failed to activate account", ""))
+		}
+		return
+	}
+
+	logger.LogSecurityEvent("account_activated", &userID, c.ClientIP(), nil)
+
+	c.JSON(http.StatusOK, NewSuccessResponse("Account activated; please log in with your new password", nil))
+}
+
+// parseUserIDParam reads the :id path parameter, responding with 400 when it is not a user ID
+func parseUserIDParam(c *gin.Context) (uint, bool) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, NewErrorResponse("Invalid user ID", err.Error()))
+		return 0, false
+	}
+	return uint(userID), true
+}
+
+func respondUserError(c *gin.Context, message string, err error, userID uint) {
+	switch {
+	case strings.Contains(err.Error(), "already exists"):
+		c.JSON(http.StatusConflict, NewConflictResponse(err.Error()))
+	case strings.Contains(err.Error(), "invalid"), strings.Contains(err.Error(), "cannot"):
+		c.JSON(http.StatusBadRequest, NewErrorResponse(message, err.Error()))
+	case strings.Contains(err.Error(), "user not found"):
+		c.JSON(http.StatusNotFound, NewNotFoundResponse("User"))
+	case strings.Contains(err.Error(), "failed to send invitation"):
+		logger.Error(message, err, map[string]interface{}{
+			"user_id": userID,
+		})
+		c.JSON(http.StatusBadGateway, NewErrorResponse(message, "The account was saved but the invitation email could not be sent; resend the invitation"))
+	default:
+		logger.Error(message, err, map[string]interface{}{
+			"user_id": userID,
+		})
+		c.JSON(http.StatusInternalServerError, NewErrorResponse(message, ""))
+	}
+}
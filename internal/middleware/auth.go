@@ -1,14 +1,88 @@
 package middleware
 
 import (
+	"contact-service/internal/services"
 	"contact-service/pkg/auth"
+	"contact-service/pkg/database"
 	"contact-service/pkg/logger"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	sessionsOnce    sync.Once
+	sessions        *services.SessionService
+	twoFactorOnce   sync.Once
+	twoFactor       *services.TwoFactorService
+	permissionsOnce sync.Once
+	permissions     *services.PermissionService
+)
+
+// sessionService returns the session store tokens are checked against. It is created on first use,
+// after the database has been connected.
+func sessionService() *services.SessionService {
+	sessionsOnce.Do(func() {
+		sessions = services.NewSessionService(database.DB, services.LoadSessionConfig())
+	})
+	return sessions
+}
+
+// twoFactorService returns the service holding the per-role two-factor policies
+func twoFactorService() *services.TwoFactorService {
+	twoFactorOnce.Do(func() {
+		twoFactor = services.NewTwoFactorService(database.DB, services.LoadTwoFactorConfig())
+	})
+	return twoFactor
+}
+
+// permissionService returns the service resolving users' permissions from their role and overrides
+func permissionService() *services.PermissionService {
+	permissionsOnce.Do(func() {
+		permissions = services.NewPermissionService(database.DB, services.LoadPermissionConfig())
+	})
+	return permissions
+}
+
+// requireTwoFactor rejects requests from sessions opened without a two-factor code when the user's
+// role requires one. It reports whether the request may continue.
+func requireTwoFactor(c *gin.Context, role string) bool {
+	if verified, _ := c.Get("session_mfa_verified"); verified == true {
+		return true
+	}
+	if !twoFactorService().RequiredForRole(role) {
+		return true
+	}
+
+	logger.LogSecurityEvent("two_factor_required", contextUserID(c), c.ClientIP(), map[string]interface{}{
+		"role": role,
+		"path": c.Request.URL.Path,
+	})
+
+	c.JSON(http.StatusForbidden, gin.H{
+		"success": false,
+		"message": "Two-factor authentication required",
+		"error": map[string]string{
+			"code":    "TWO_FACTOR_REQUIRED",
+			"message": "Your role requires two-factor authentication; enable it through /api/v1/auth/mfa/enroll",
+		},
+	})
+	c.Abort()
+	return false
+}
+
+// contextUserID returns the authenticated user's ID for security logging, or nil
+func contextUserID(c *gin.Context) *uint {
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(uint); ok {
+			return &id
+		}
+	}
+	return nil
+}
+
 // AuthMiddleware validates JWT tokens and sets user context
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -76,11 +150,38 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// The token is only as good as its session, which may have been revoked or gone idle
+		session, err := sessionService().ValidateSession(claims)
+		if err != nil {
+			logger.LogSecurityEvent("invalid_session", &claims.UserID, c.ClientIP(), map[string]interface{}{
+				"error": err.Error(),
+				"path":  c.Request.URL.Path,
+			})
+
+			errorCode := "SESSION_REVOKED"
+			if strings.Contains(err.Error(), "inactivity") {
+				errorCode = "SESSION_EXPIRED"
+			}
+
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"message": "Session is no longer valid",
+				"error": map[string]string{
+					"code":    errorCode,
+					"message": err.Error(),
+				},
+			})
+			c.Abort()
+			return
+		}
+
 		// Set user context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_role", claims.Role)
 		c.Set("token_claims", claims)
+		c.Set("session_id", session.ID)
+		c.Set("session_mfa_verified", session.MFAVerified)
 
 		// Log successful authentication
 		logger.Debug("User authenticated successfully", map[string]interface{}{
@@ -119,11 +220,20 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		session, err := sessionService().ValidateSession(claims)
+		if err != nil {
+			// Revoked or idle session, continue without authentication
+			c.Next()
+			return
+		}
+
 		// Set user context if token is valid
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_role", claims.Role)
 		c.Set("token_claims", claims)
+		c.Set("session_id", session.ID)
+		c.Set("session_mfa_verified", session.MFAVerified)
 
 		c.Next()
 	}
@@ -148,8 +258,7 @@ func AdminOnly() gin.HandlerFunc {
 
 		userRole, ok := role.(string)
 		if !ok || userRole != "admin" {
-			userID, _ := c.Get("user_id")
-			logger.LogSecurityEvent("unauthorized_admin_access", userID.(*uint), c.ClientIP(), map[string]interface{}{
+			logger.LogSecurityEvent("unauthorized_admin_access", contextUserID(c), c.ClientIP(), map[string]interface{}{
 				"role": userRole,
 				"path": c.Request.URL.Path,
 			})
@@ -166,44 +275,36 @@ func AdminOnly() gin.HandlerFunc {
 			return
 		}
 
+		if !requireTwoFactor(c, userRole) {
+			return
+		}
+
 		c.Next()
 	}
 }
 
-// RequirePermission middleware checks if user has specific permission
+// RequirePermission middleware checks if user has specific permission, through their role's
+// permissions and their own overrides, and that they have completed two-factor authentication
+// if their role requires it
 func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("user_role")
-		if !exists {
+		userID := contextUserID(c)
+		if userID == nil {
 			c.JSON(http.StatusForbidden, gin.H{
 				"success": false,
 				"message": "Access denied",
 				"error": map[string]string{
 					"code":    "ACCESS_DENIED",
-					"message": "User role not found",
+					"message": "User not found",
 				},
 			})
 			c.Abort()
 			return
 		}
 
-		userRole, ok := role.(string)
-		if !ok {
-			c.JSON(http.StatusForbidden, gin.H{
-				"success": false,
-				"message": "Access denied",
-				"error": map[string]string{
-					"code":    "ACCESS_DENIED",
-					"message": "Invalid user role",
-				},
-			})
-			c.Abort()
-			return
-		}
-
-		if !auth.HasPermission(userRole, permission) {
-			userID, _ := c.Get("user_id")
-			logger.LogSecurityEvent("insufficient_permissions", userID.(*uint), c.ClientIP(), map[string]interface{}{
+		if !permissionService().HasPermission(*userID, permission) {
+			userRole, _ := GetUserRole(c)
+			logger.LogSecurityEvent("insufficient_permissions", userID, c.ClientIP(), map[string]interface{}{
 				"role":            userRole,
 				"required_permission": permission,
 				"path":            c.Request.URL.Path,
@@ -221,6 +322,11 @@ func RequirePermission(permission string) gin.HandlerFunc {
 			return
 		}
 
+		userRole, _ := GetUserRole(c)
+		if !requireTwoFactor(c, userRole) {
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -262,8 +368,7 @@ func ManagerOrAbove() gin.HandlerFunc {
 		}
 
 		if !isAllowed {
-			userID, _ := c.Get("user_id")
-			logger.LogSecurityEvent("insufficient_role", userID.(*uint), c.ClientIP(), map[string]interface{}{
+			logger.LogSecurityEvent("insufficient_role", contextUserID(c), c.ClientIP(), map[string]interface{}{
 				"role":          userRole,
 				"required_roles": allowedRoles,
 				"path":          c.Request.URL.Path,
@@ -281,6 +386,10 @@ func ManagerOrAbove() gin.HandlerFunc {
 			return
 		}
 
+		if !requireTwoFactor(c, userRole) {
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -336,11 +445,11 @@ func HasRole(c *gin.Context, requiredRole string) bool {
 
 // CanAccessResource checks if user can access a specific resource
 func CanAccessResource(c *gin.Context, resourceType string, action string) bool {
-	role, exists := GetUserRole(c)
-	if !exists {
+	userID := contextUserID(c)
+	if userID == nil {
 		return false
 	}
 	
 	permission := resourceType + ":" + action
-	return auth.HasPermission(role, permission)
+	return permissionService().HasPermission(*userID, permission)
 }
\ No newline at end of file
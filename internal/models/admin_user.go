@@ -10,7 +10,7 @@ type AdminUser struct {
 	Email                string     `json:"email" gorm:"type:varchar(255);uniqueIndex;not null"`
 	PasswordHash         string     `json:"-" gorm:"not null"`
 	Name                 string     `json:"name" gorm:"not null"`
-	Role                 string     `json:"role" gorm:"type:enum('admin','editor','hr_manager','content_writer');default:'editor'"`
+	Role                 string     `json:"role" gorm:"type:varchar(50);not null;default:'editor'"` // Name of a Role
 	AvatarURL            *string    `json:"avatar_url"`
 	Phone                *string    `json:"phone"`
 	JobTitle             *string    `json:"job_title"`
@@ -18,8 +18,15 @@ type AdminUser struct {
 	Location             *string    `json:"location"`
 	Bio                  *string    `json:"bio" gorm:"type:text"`
 	IsActive             bool       `json:"is_active" gorm:"default:true"`
+	DeactivatedAt        *time.Time `json:"deactivated_at"`
+	DeactivatedBy        *uint      `json:"deactivated_by"`
 	LoginAttempts        int        `json:"login_attempts" gorm:"default:0"`
+	LockedUntil          *time.Time `json:"locked_until"`
+	LockoutCount         int        `json:"lockout_count" gorm:"default:0"` // Lockouts since the last successful login; each doubles the next
 	TwoFactorEnabled     bool       `json:"two_factor_enabled" gorm:"default:false"`
+	TwoFactorSecret      *string    `json:"-" gorm:"size:255"` // Encrypted with TOTP_ENCRYPTION_KEY
+	TwoFactorConfirmedAt *time.Time `json:"two_factor_confirmed_at"`
+	TwoFactorLastStep    *int64     `json:"-"` // Last TOTP step accepted, so a code works only once
 	LastLoginAt          *time.Time `json:"last_login_at"`
 	LastActivityAt       *time.Time `json:"last_activity_at"`
 	PasswordChangedAt    *time.Time `json:"password_changed_at"`
@@ -46,6 +53,17 @@ func (u *AdminUser) GetDisplayName() string {
 	return u.Email
 }
 
+// IsLocked reports whether failed logins have locked the account at the given time
+func (u *AdminUser) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// IsInvitationPending reports whether the user was invited and has not activated the account by
+// choosing a password yet
+func (u *AdminUser) IsInvitationPending() bool {
+	return u.PasswordHash == "" && !u.IsActive && u.DeactivatedAt == nil
+}
+
 // IsAdmin checks if the user has admin role
 func (u *AdminUser) IsAdmin() bool {
 	return u.Role == "admin"
@@ -56,22 +74,13 @@ func (u *AdminUser) IsManager() bool {
 	return u.Role == "admin" || u.Role == "hr_manager"
 }
 
-// CanAccessResource checks if the user can access a specific resource
+// CanAccessResource checks if the user can access a specific resource, resolving the permissions of
+// their role and their overrides through the installed PermissionResolver
 func (u *AdminUser) CanAccessResource(resource, action string) bool {
-	// This would integrate with the auth package's HasPermission function
-	// For now, simplified logic based on existing roles
-	switch u.Role {
-	case "admin":
-		return true
-	case "hr_manager":
-		return resource != "admin" // HR managers can access everything except admin functions
-	case "editor":
-		return resource == "contacts" || resource == "activities" || resource == "search" || resource == "blogs"
-	case "content_writer":
-		return resource == "blogs" || (resource == "contacts" && action == "read")
-	default:
+	if permissionResolver == nil || u.ID == 0 {
 		return false
 	}
+	return permissionResolver.HasPermission(u.ID, resource+":"+action)
 }
 
 // AdminUserRequest represents a request to create or update an admin user
@@ -79,7 +88,7 @@ type AdminUserRequest struct {
 	Email            string  `json:"email" binding:"required,email"`
 	Password         *string `json:"password,omitempty" binding:"omitempty,min=8"`
 	Name             string  `json:"name" binding:"required,max=100"`
-	Role             string  `json:"role" binding:"required,oneof=admin editor hr_manager content_writer"`
+	Role             string  `json:"role" binding:"required,max=50"`
 	AvatarURL        *string `json:"avatar_url,omitempty"`
 	Phone            *string `json:"phone,omitempty"`
 	JobTitle         *string `json:"job_title,omitempty"`
@@ -105,7 +114,10 @@ type AdminUserResponse struct {
 	Location         *string    `json:"location,omitempty"`
 	Bio              *string    `json:"bio,omitempty"`
 	IsActive         bool       `json:"is_active"`
+	InvitationPending bool      `json:"invitation_pending"`
+	DeactivatedAt    *time.Time `json:"deactivated_at,omitempty"`
 	LoginAttempts    int        `json:"login_attempts"`
+	LockedUntil      *time.Time `json:"locked_until,omitempty"`
 	TwoFactorEnabled bool       `json:"two_factor_enabled"`
 	LastLoginAt      *time.Time `json:"last_login_at,omitempty"`
 	LastActivityAt   *time.Time `json:"last_activity_at,omitempty"`
@@ -132,7 +144,10 @@ func (u *AdminUser) ToResponse() *AdminUserResponse {
 		Location:          u.Location,
 		Bio:               u.Bio,
 		IsActive:          u.IsActive,
+		InvitationPending: u.IsInvitationPending(),
+		DeactivatedAt:     u.DeactivatedAt,
 		LoginAttempts:     u.LoginAttempts,
+		LockedUntil:       u.LockedUntil,
 		TwoFactorEnabled:  u.TwoFactorEnabled,
 		LastLoginAt:       u.LastLoginAt,
 		LastActivityAt:    u.LastActivityAt,
@@ -443,22 +443,46 @@ func (AnalyticsCache) TableName() string {
 	return "analytics_cache"
 }
 
-// UserSession represents user session tracking
+// AnalyticsCacheInvalidation records a write that made a metric type's cached entries stale. It is
+// written in the same transaction as the write, so it only becomes visible once the write commits;
+// AppliedAt is set when the cache first sees it, and entries computed before then are not served.
+type AnalyticsCacheInvalidation struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	MetricType string     `json:"metric_type" gorm:"size:50;not null;index:idx_metric_applied"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AppliedAt  *time.Time `json:"applied_at" gorm:"index:idx_metric_applied"`
+}
+
+// TableName specifies the table name for AnalyticsCacheInvalidation
+func (AnalyticsCacheInvalidation) TableName() string {
+	return "analytics_cache_invalidations"
+}
+
+// UserSession represents user session tracking. Each login opens a session: its SessionToken is the
+// jti of the session's access tokens and RefreshTokenID the jti of the one refresh token it accepts.
 type UserSession struct {
 	ID               uint       `json:"id" gorm:"primaryKey"`
 	UserID           uint       `json:"user_id" gorm:"not null;index"`
-	SessionToken     string     `json:"session_token" gorm:"size:255;not null;unique;index"`
+	SessionToken     string     `json:"-" gorm:"size:255;not null;unique;index"`
+	RefreshTokenID   *string    `json:"-" gorm:"size:64"`
 	DeviceInfo       *string    `json:"device_info" gorm:"type:text"`
 	BrowserInfo      *string    `json:"browser_info" gorm:"type:text"`
 	IPAddress        *string    `json:"ip_address" gorm:"size:45"`
 	LocationInfo     JSONMap    `json:"location_info" gorm:"type:json"`
 	LoginAt          time.Time  `json:"login_at" gorm:"default:CURRENT_TIMESTAMP"`
 	LastActivity     time.Time  `json:"last_activity" gorm:"default:CURRENT_TIMESTAMP;index"`
+	RefreshedAt      *time.Time `json:"refreshed_at"`
+	ExpiresAt        *time.Time `json:"expires_at"` // When the current refresh token expires
 	LogoutAt         *time.Time `json:"logout_at"`
+	RevokedReason    *string    `json:"revoked_reason" gorm:"size:50"`
 	IsActive         bool       `json:"is_active" gorm:"default:true;index"`
+	MFAVerified      bool       `json:"mfa_verified" gorm:"column:mfa_verified;default:false"` // Opened with a two-factor code
 	PageViews        int        `json:"page_views" gorm:"default:0"`
 	ActionsPerformed int        `json:"actions_performed" gorm:"default:0"`
 	SessionDuration  *int       `json:"session_duration"` // Seconds
+
+	// Current marks the session of the requesting token in listings
+	Current bool `json:"current" gorm:"-"`
 	
 	// Relationships
 	User *AdminUser `json:"user,omitempty" gorm:"foreignKey:UserID"`
@@ -469,6 +493,19 @@ func (UserSession) TableName() string {
 	return "user_sessions"
 }
 
+// Reasons a session was ended
+const (
+	SessionRevokedLogout       = "logout"
+	SessionRevokedByUser       = "revoked"
+	SessionRevokedByAdmin      = "admin_revoked"
+	SessionRevokedIdle         = "idle_timeout"
+	SessionRevokedExpired      = "expired"
+	SessionRevokedRefreshReuse = "refresh_token_reuse"
+	SessionRevokedPassword     = "password_changed"
+	SessionRevokedRoleChanged  = "role_changed"
+	SessionRevokedDeactivated  = "deactivated"
+)
+
 // PerformanceMetric represents system performance metrics
 type PerformanceMetric struct {
 	ID         uint      `json:"id" gorm:"primaryKey"`
@@ -531,12 +568,12 @@ type ExportJob struct {
 	ID           uint       `json:"id" gorm:"primaryKey"`
 	ExportID     string     `json:"export_id" gorm:"size:50;not null;unique;index"`
 	JobType      string     `json:"job_type" gorm:"size:50;not null;index"` // analytics, contacts, appointments, reports
-	ExportFormat string     `json:"export_format" gorm:"size:20;not null"` // csv, excel, pdf, json
+	ExportFormat string     `json:"export_format" gorm:"size:20;not null"` // csv, xlsx, pdf, json
 	StartDate    *time.Time `json:"start_date" gorm:"type:date"`
 	EndDate      *time.Time `json:"end_date" gorm:"type:date"`
 	Filters      JSONMap    `json:"filters" gorm:"type:json"`
 	UserIDs      JSONArray  `json:"user_ids" gorm:"type:json"`
-	Status       string     `json:"status" gorm:"size:20;default:pending;index"` // pending, processing, completed, failed
+	Status       string     `json:"status" gorm:"size:20;default:pending;index"` // pending, processing, completed, failed, expired
 	Progress     int        `json:"progress" gorm:"default:0"` // 0-100 percentage
 	FilePath     *string    `json:"file_path" gorm:"size:500"`
 	FileSize     *int       `json:"file_size"` // Bytes
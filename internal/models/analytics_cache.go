@@ -0,0 +1,127 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// analyticsCacheDate is the layout of the window dates analytics are cached under
+const analyticsCacheDate = "2006-01-02"
+
+// AnalyticsCacheFilters are the AnalyticsRequest filters an analytics cache entry is keyed on. The
+// window is kept to whole days so that requests for the same days share an entry; a window ending
+// today is served as computed up to the moment it was cached.
+type AnalyticsCacheFilters struct {
+	StartDate    string   `json:"start_date"` // YYYY-MM-DD
+	EndDate      string   `json:"end_date"`   // YYYY-MM-DD
+	Granularity  string   `json:"granularity"`
+	UserIDs      []uint   `json:"user_ids,omitempty"`
+	Sources      []string `json:"sources,omitempty"`
+	ContactTypes []string `json:"contact_types,omitempty"`
+	Statuses     []string `json:"statuses,omitempty"`
+	MetricTypes  []string `json:"metric_types,omitempty"`
+}
+
+// NewAnalyticsCacheFilters normalizes a request's filters so that equivalent requests share a key
+func NewAnalyticsCacheFilters(request *AnalyticsRequest) AnalyticsCacheFilters {
+	userIDs := append([]uint(nil), request.UserIDs...)
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+	unique := userIDs[:0]
+	for i, id := range userIDs {
+		if i == 0 || id != userIDs[i-1] {
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		unique = nil
+	}
+
+	return AnalyticsCacheFilters{
+		StartDate:    request.StartDate.Format(analyticsCacheDate),
+		EndDate:      request.EndDate.Format(analyticsCacheDate),
+		Granularity:  request.Granularity,
+		UserIDs:      unique,
+		Sources:      sortedStrings(request.Sources),
+		ContactTypes: sortedStrings(request.ContactTypes),
+		Statuses:     sortedStrings(request.Statuses),
+		MetricTypes:  sortedStrings(request.MetricTypes),
+	}
+}
+
+// CacheKey is the analytics_cache key of a metric computed with these filters
+func (f AnalyticsCacheFilters) CacheKey(metricType string) string {
+	data, _ := json.Marshal(f)
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%s:%s", metricType, hex.EncodeToString(sum[:]))
+}
+
+// Request rebuilds the request the filters describe. The window starts at the beginning of the start
+// day and ends at the start of the end day, or at now when it ends today.
+func (f AnalyticsCacheFilters) Request(now time.Time) (*AnalyticsRequest, error) {
+	startDate, err := time.Parse(analyticsCacheDate, f.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start_date: %v", err)
+	}
+	endDate, err := time.Parse(analyticsCacheDate, f.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end_date: %v", err)
+	}
+	if f.EndDate == now.Format(analyticsCacheDate) {
+		endDate = now
+	}
+
+	return &AnalyticsRequest{
+		StartDate:    startDate,
+		EndDate:      endDate,
+		UserIDs:      f.UserIDs,
+		Sources:      f.Sources,
+		ContactTypes: f.ContactTypes,
+		Statuses:     f.Statuses,
+		Granularity:  f.Granularity,
+		MetricTypes:  f.MetricTypes,
+	}, nil
+}
+
+// JSONMap returns the filters as stored on the cache entry
+func (f AnalyticsCacheFilters) JSONMap() JSONMap {
+	data, _ := json.Marshal(f)
+	var filters JSONMap
+	_ = json.Unmarshal(data, &filters)
+	return filters
+}
+
+// ParseAnalyticsCacheFilters reads the filters stored on a cache entry
+func ParseAnalyticsCacheFilters(filters JSONMap) (AnalyticsCacheFilters, error) {
+	var parsed AnalyticsCacheFilters
+	if err := decodeJSONMap(filters, &parsed); err != nil {
+		return parsed, fmt.Errorf("invalid cache filters: %v", err)
+	}
+	return parsed, nil
+}
+
+// AnalyticsCacheInfo tells the client whether analytics were served from the cache and how old they are
+type AnalyticsCacheInfo struct {
+	Cached      bool      `json:"cached"`
+	GeneratedAt time.Time `json:"generated_at"`
+	AgeSeconds  int64     `json:"age_seconds"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func sortedStrings(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	unique := sorted[:0]
+	for i, value := range sorted {
+		if i == 0 || value != sorted[i-1] {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
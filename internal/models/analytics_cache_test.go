@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsCacheFiltersNormalize(t *testing.T) {
+	first := NewAnalyticsCacheFilters(&AnalyticsRequest{
+		StartDate:   time.Date(2025, 3, 1, 9, 15, 0, 0, time.UTC),
+		EndDate:     time.Date(2025, 3, 31, 17, 0, 0, 0, time.UTC),
+		UserIDs:     []uint{7, 3, 7},
+		Sources:     []string{"web", "referral"},
+		Granularity: "day",
+	})
+	second := NewAnalyticsCacheFilters(&AnalyticsRequest{
+		StartDate:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		UserIDs:     []uint{3, 7},
+		Sources:     []string{"referral", "web", "web"},
+		Granularity: "day",
+	})
+
+	assert.Equal(t, "2025-03-01", first.StartDate)
+	assert.Equal(t, []uint{3, 7}, first.UserIDs)
+	assert.Equal(t, first.CacheKey("contacts"), second.CacheKey("contacts"))
+	assert.NotEqual(t, first.CacheKey("contacts"), first.CacheKey("conversion"))
+
+	second.Granularity = "week"
+	assert.NotEqual(t, first.CacheKey("contacts"), second.CacheKey("contacts"))
+}
+
+func TestAnalyticsCacheFiltersRoundTrip(t *testing.T) {
+	filters := NewAnalyticsCacheFilters(&AnalyticsRequest{
+		StartDate:   time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		Statuses:    []string{"new"},
+		Granularity: "month",
+	})
+
+	parsed, err := ParseAnalyticsCacheFilters(filters.JSONMap())
+	require.NoError(t, err)
+	assert.Equal(t, filters, parsed)
+
+	now := time.Date(2025, 3, 31, 14, 30, 0, 0, time.UTC)
+	request, err := parsed.Request(now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), request.StartDate)
+	assert.Equal(t, now, request.EndDate, "a window ending today runs to now")
+	assert.Equal(t, []string{"new"}, request.Statuses)
+
+	request, err = parsed.Request(now.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), request.EndDate)
+
+	_, err = AnalyticsCacheFilters{StartDate: "March", EndDate: "2025-03-31"}.Request(now)
+	assert.Error(t, err)
+}
@@ -114,8 +114,15 @@ type Appointment struct {
 	
 	// Integration Data
 	CalendarEventID           *string            `json:"calendar_event_id" gorm:"column:calendar_event_id;size:255"`
+	CalendarSequence          int                `json:"calendar_sequence" gorm:"column:calendar_sequence;default:0"` // iCalendar SEQUENCE, bumped on every change sent to calendars
 	ExternalMeetingID         *string            `json:"external_meeting_id" gorm:"column:external_meeting_id;size:255"`
 	BookingSource             string             `json:"booking_source" gorm:"column:booking_source;size:100;default:manual"`
+	BookingPageID             *uint              `json:"booking_page_id" gorm:"column:booking_page_id;index"` // Public booking page the visitor booked through
+	
+	// Recurring Series
+	SeriesID                  *uint              `json:"series_id" gorm:"column:series_id;index"`
+	RecurrenceID              *time.Time         `json:"recurrence_id" gorm:"column:recurrence_id"` // Occurrence start generated by the series rule
+	IsSeriesException         bool               `json:"is_series_exception" gorm:"column:is_series_exception;default:false"` // Edited on its own; series edits leave it alone
 	
 	// Metadata and Custom Fields
 	Tags                      JSONMap            `json:"tags" gorm:"column:tags;type:json"`
@@ -237,6 +244,10 @@ type AppointmentReminder struct {
 	Opened             bool      `json:"opened" gorm:"column:opened;default:false"`
 	Clicked            bool      `json:"clicked" gorm:"column:clicked;default:false"`
 	
+	// Dispatch Claim
+	ClaimedBy          *string    `json:"-" gorm:"column:claimed_by;size:100"` // Dispatcher instance sending the reminder
+	ClaimedAt          *time.Time `json:"-" gorm:"column:claimed_at"`
+	
 	CreatedAt          time.Time `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt          time.Time `json:"updated_at" gorm:"column:updated_at"`
 	
@@ -249,6 +260,27 @@ func (AppointmentReminder) TableName() string {
 	return "appointment_reminders"
 }
 
+// Reminder statuses
+const (
+	ReminderStatusScheduled = "scheduled"
+	ReminderStatusSending   = "sending"
+	ReminderStatusSent      = "sent"
+	ReminderStatusFailed    = "failed"
+	ReminderStatusCancelled = "cancelled"
+)
+
+// Reminder delivery channels
+const (
+	ReminderTypeEmail = "email"
+	ReminderTypeSMS   = "sms"
+)
+
+// ReminderSetting configures one reminder sent before an appointment
+type ReminderSetting struct {
+	ReminderType  string `json:"reminder_type" binding:"required,oneof=email sms"`
+	MinutesBefore int    `json:"minutes_before" binding:"required,min=5,max=20160"`
+}
+
 // Request and Response structures
 
 // AppointmentRequest represents the request structure for creating/updating appointments
@@ -277,6 +309,8 @@ type AppointmentRequest struct {
 	Agenda                   JSONMap             `json:"agenda"`
 	Tags                     JSONMap             `json:"tags"`
 	CustomFields             JSONMap             `json:"custom_fields"`
+	Reminders                []ReminderSetting   `json:"reminders" binding:"omitempty,dive"` // Omit for the default reminders; an empty list disables them
+	Recurrence               *RecurrenceRequest  `json:"recurrence"` // Makes the appointment the first occurrence of a series
 }
 
 // PublicAppointmentRequest represents a simplified request for public appointment booking
@@ -292,6 +326,8 @@ type PublicAppointmentRequest struct {
 	PreferredDate    string              `json:"preferred_date" binding:"required"`
 	PreferredTime    string              `json:"preferred_time" binding:"required"`
 	MeetingType      *MeetingType        `json:"meeting_type"`
+	Timezone         *string             `json:"timezone"`   // Timezone of PreferredDate/PreferredTime; defaults to the booking page's
+	HoldToken        *string             `json:"hold_token"` // Slot hold from POST /public/booking/{slug}/holds
 	// Honeypot field for spam detection
 	Website          string              `json:"website"` // Should be empty for real users
 }
@@ -326,6 +362,10 @@ type AppointmentResponse struct {
 	ConversionProbability     int                 `json:"conversion_probability"`
 	Tags                      JSONMap             `json:"tags"`
 	CustomFields              JSONMap             `json:"custom_fields"`
+	SeriesID                  *uint               `json:"series_id,omitempty"`
+	RecurrenceID              *time.Time          `json:"recurrence_id,omitempty"`
+	IsSeriesException         bool                `json:"is_series_exception"`
+	CalendarSequence          int                 `json:"calendar_sequence"`
 	CreatedAt                 time.Time           `json:"created_at"`
 	UpdatedAt                 time.Time           `json:"updated_at"`
 	// Computed fields
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// SeriesStatus represents the lifecycle of a recurring appointment series
+type SeriesStatus string
+
+const (
+	SeriesActive    SeriesStatus = "active"    // Occurrences are still being materialized
+	SeriesEnded     SeriesStatus = "ended"     // The rule has no occurrences left
+	SeriesCancelled SeriesStatus = "cancelled" // Cancelled as a whole
+)
+
+// SeriesEditScope selects which occurrences of a series an edit or cancellation applies to
+type SeriesEditScope string
+
+const (
+	SeriesScopeThis      SeriesEditScope = "this"      // Only the selected occurrence
+	SeriesScopeFollowing SeriesEditScope = "following" // The selected occurrence and every later one
+	SeriesScopeAll       SeriesEditScope = "all"       // Every upcoming occurrence of the series
+)
+
+// ParseSeriesEditScope validates a scope value; an empty value means SeriesScopeThis
+func ParseSeriesEditScope(value string) (SeriesEditScope, error) {
+	switch scope := SeriesEditScope(value); scope {
+	case "":
+		return SeriesScopeThis, nil
+	case SeriesScopeThis, SeriesScopeFollowing, SeriesScopeAll:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("invalid scope %q: expected this, following or all", value)
+	}
+}
+
+// AppointmentSeries is a recurring appointment defined by an RFC 5545 RRULE. Occurrences are
+// materialized as Appointment rows within a rolling horizon; the series keeps the fields they copy.
+type AppointmentSeries struct {
+	ID         uint `json:"id" gorm:"primaryKey"`
+	ContactID  uint `json:"contact_id" gorm:"column:contact_id;not null;index"`
+	AssignedTo uint `json:"assigned_to" gorm:"column:assigned_to;not null;index"`
+
+	// Recurrence
+	RRule             string       `json:"rrule" gorm:"column:rrule;size:500;not null"`
+	DTStart           time.Time    `json:"dtstart" gorm:"column:dtstart;not null"`
+	Timezone          string       `json:"timezone" gorm:"column:timezone;size:50;default:Asia/Kolkata"`
+	ExDates           JSONArray    `json:"exdates" gorm:"column:exdates;type:json"` // Excluded occurrence dates (YYYY-MM-DD in the series timezone)
+	DurationMinutes   int          `json:"duration_minutes" gorm:"column:duration_minutes;default:60"`
+	MaterializedUntil *time.Time   `json:"materialized_until" gorm:"column:materialized_until;index"`
+	Status            SeriesStatus `json:"status" gorm:"column:status;default:active;index"`
+	ParentSeriesID    *uint        `json:"parent_series_id" gorm:"column:parent_series_id;index"` // Series this one was split from by a "this and following" edit
+
+	// Occurrence Template
+	Title           string          `json:"title" gorm:"column:title;size:255;not null"`
+	Description     *string         `json:"description" gorm:"column:description;type:text"`
+	AppointmentType AppointmentType `json:"appointment_type" gorm:"column:appointment_type;default:consultation"`
+	Priority        ContactPriority `json:"priority" gorm:"column:priority;default:medium"`
+	MeetingType     MeetingType     `json:"meeting_type" gorm:"column:meeting_type;default:video_call"`
+	Location        *string         `json:"location" gorm:"column:location;size:500"`
+	MeetingLink     *string         `json:"meeting_link" gorm:"column:meeting_link;size:500"`
+	MeetingID       *string         `json:"meeting_id" gorm:"column:meeting_id;size:100"`
+	MeetingPassword *string         `json:"-" gorm:"column:meeting_password;size:100"`
+	PhoneNumber     *string         `json:"phone_number" gorm:"column:phone_number;size:20"`
+	Reminders       JSONArray       `json:"reminders" gorm:"column:reminders;type:json"` // ReminderSetting objects; null uses the defaults
+
+	// Audit Fields
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+	CreatedBy *uint     `json:"created_by" gorm:"column:created_by"`
+	UpdatedBy *uint     `json:"updated_by" gorm:"column:updated_by"`
+}
+
+// TableName specifies the table name for AppointmentSeries
+func (AppointmentSeries) TableName() string {
+	return "appointment_series"
+}
+
+// RecurrenceRequest defines the recurrence of a new or edited series
+type RecurrenceRequest struct {
+	RRule   string   `json:"rrule" binding:"required,max=500"` // e.g. FREQ=WEEKLY;BYDAY=TU;COUNT=12
+	ExDates []string `json:"exdates"`                          // Occurrence dates to skip, YYYY-MM-DD
+}
+
+// AppointmentSeriesResponse represents a series with its upcoming occurrences
+type AppointmentSeriesResponse struct {
+	Series      *AppointmentSeries    `json:"series"`
+	Occurrences []AppointmentResponse `json:"occurrences"`
+}
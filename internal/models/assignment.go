@@ -201,7 +201,7 @@ type AssignmentHistory struct {
 	ID               uint                  `json:"id" gorm:"primaryKey"`
 	ContactID        uint                  `json:"contact_id" gorm:"not null;index"`
 	FromUserID       *uint                 `json:"from_user_id"` // Null for initial assignments
-	ToUserID         uint                  `json:"to_user_id" gorm:"not null;index"`
+	ToUserID         *uint                 `json:"to_user_id" gorm:"index"` // Null for unassignments
 	ChangedByID      *uint                 `json:"changed_by_id"`
 	RuleID           *uint                 `json:"rule_id"`
 	
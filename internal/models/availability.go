@@ -0,0 +1,192 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// UserAvailability is one day of a user's weekly working schedule, in the schedule's timezone
+type UserAvailability struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"column:user_id;not null;index"`
+	DayOfWeek   int       `json:"day_of_week" gorm:"column:day_of_week;not null"` // 0=Sunday ... 6=Saturday
+	StartTime   string    `json:"start_time" gorm:"column:start_time;not null"`   // HH:MM:SS
+	EndTime     string    `json:"end_time" gorm:"column:end_time;not null"`
+	IsAvailable bool      `json:"is_available" gorm:"column:is_available;default:true"`
+	BreakTimes  JSONArray `json:"break_times" gorm:"column:break_times;type:json"` // Array of {start, end, title}
+	Timezone    string    `json:"timezone" gorm:"column:timezone;size:50;default:UTC"`
+	Notes       *string   `json:"notes" gorm:"column:notes;type:text"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+	CreatedBy *uint     `json:"created_by" gorm:"column:created_by"`
+	UpdatedBy *uint     `json:"updated_by" gorm:"column:updated_by"`
+}
+
+// TableName specifies the table name for UserAvailability
+func (UserAvailability) TableName() string {
+	return "user_availabilities"
+}
+
+// AvailabilityException overrides a user's weekly schedule on a date: a holiday or out-of-office
+// block when IsAvailable is false, or different working hours when it is true
+type AvailabilityException struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"column:user_id;not null;index"`
+	Date        time.Time `json:"date" gorm:"column:date;type:date;not null;index"`
+	IsAvailable bool      `json:"is_available" gorm:"column:is_available;default:false"`
+	StartTime   *string   `json:"start_time" gorm:"column:start_time"` // Without start/end time the exception covers the whole day
+	EndTime     *string   `json:"end_time" gorm:"column:end_time"`
+
+	Reason      string  `json:"reason" gorm:"column:reason;size:255;not null"` // holiday, out_of_office, custom_hours, etc.
+	Title       string  `json:"title" gorm:"column:title;size:255;not null"`
+	Description *string `json:"description" gorm:"column:description;type:text"`
+
+	IsRecurring bool    `json:"is_recurring" gorm:"column:is_recurring;default:false"`
+	Recurrence  JSONMap `json:"recurrence" gorm:"column:recurrence;type:json"` // {"rrule": "FREQ=YEARLY"}, starting on Date
+
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"column:updated_at"`
+	CreatedBy *uint      `json:"created_by" gorm:"column:created_by"`
+	UpdatedBy *uint      `json:"updated_by" gorm:"column:updated_by"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"column:deleted_at;index"`
+}
+
+// TableName specifies the table name for AvailabilityException
+func (AvailabilityException) TableName() string {
+	return "availability_exceptions"
+}
+
+// RecurrenceRule returns the exception's RRULE, or "" when it does not recur
+func (e *AvailabilityException) RecurrenceRule() string {
+	if !e.IsRecurring {
+		return ""
+	}
+	rule, _ := e.Recurrence["rrule"].(string)
+	return rule
+}
+
+// AvailabilityBreak is a break within a working day, e.g. lunch
+type AvailabilityBreak struct {
+	Start string `json:"start" binding:"required"` // HH:MM
+	End   string `json:"end" binding:"required"`
+	Title string `json:"title"`
+}
+
+// DayScheduleRequest configures one weekday of a weekly schedule
+type DayScheduleRequest struct {
+	DayOfWeek   int                 `json:"day_of_week" binding:"min=0,max=6"`
+	StartTime   string              `json:"start_time" binding:"required"` // HH:MM
+	EndTime     string              `json:"end_time" binding:"required"`
+	IsAvailable *bool               `json:"is_available"`
+	Breaks      []AvailabilityBreak `json:"breaks" binding:"omitempty,dive"`
+	Notes       *string             `json:"notes"`
+}
+
+// WeeklyScheduleRequest replaces a user's weekly schedule; weekdays left out are days off
+type WeeklyScheduleRequest struct {
+	Timezone string               `json:"timezone" binding:"required"`
+	Days     []DayScheduleRequest `json:"days" binding:"required,dive"`
+}
+
+// WeeklyScheduleResponse represents a user's weekly schedule
+type WeeklyScheduleResponse struct {
+	UserID    uint               `json:"user_id"`
+	Timezone  string             `json:"timezone"`
+	IsDefault bool               `json:"is_default"` // No schedule configured; default business hours apply
+	Days      []UserAvailability `json:"days"`
+}
+
+// AvailabilityExceptionRequest represents a request to create or update an availability exception
+type AvailabilityExceptionRequest struct {
+	Date        string  `json:"date" binding:"required"` // YYYY-MM-DD
+	IsAvailable bool    `json:"is_available"`
+	StartTime   *string `json:"start_time"` // HH:MM
+	EndTime     *string `json:"end_time"`
+	Reason      string  `json:"reason" binding:"required,max=255"`
+	Title       string  `json:"title" binding:"required,max=255"`
+	Description *string `json:"description"`
+	RRule       *string `json:"rrule" binding:"omitempty,max=500"` // e.g. FREQ=YEARLY for a public holiday
+}
+
+// TimeWindow is a half-open interval of time [Start, End)
+type TimeWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// ParseClock parses an HH:MM or HH:MM:SS time of day into hours and minutes
+func ParseClock(value string) (int, int, error) {
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Hour(), t.Minute(), nil
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid time %q: expected HH:MM", value)
+}
+
+// ClockWindow returns the window between two times of day on the given date, in the date's location
+func ClockWindow(date time.Time, start, end string) (TimeWindow, error) {
+	sh, sm, err := ParseClock(start)
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	eh, em, err := ParseClock(end)
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	y, m, d := date.Date()
+	window := TimeWindow{
+		Start: time.Date(y, m, d, sh, sm, 0, 0, date.Location()),
+		End:   time.Date(y, m, d, eh, em, 0, 0, date.Location()),
+	}
+	if !window.End.After(window.Start) {
+		return TimeWindow{}, fmt.Errorf("end time %s must be after start time %s", end, start)
+	}
+	return window, nil
+}
+
+// SubtractWindows removes the busy windows from the free windows and returns what is left, in order
+func SubtractWindows(free, busy []TimeWindow) []TimeWindow {
+	sort.Slice(busy, func(i, j int) bool { return busy[i].Start.Before(busy[j].Start) })
+
+	var result []TimeWindow
+	for _, window := range free {
+		remaining := []TimeWindow{window}
+		for _, b := range busy {
+			var next []TimeWindow
+			for _, r := range remaining {
+				if !b.Start.Before(r.End) || !b.End.After(r.Start) {
+					next = append(next, r)
+					continue
+				}
+				if b.Start.After(r.Start) {
+					next = append(next, TimeWindow{Start: r.Start, End: b.Start})
+				}
+				if b.End.Before(r.End) {
+					next = append(next, TimeWindow{Start: b.End, End: r.End})
+				}
+			}
+			remaining = next
+		}
+		result = append(result, remaining...)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Start.Before(result[j].Start) })
+	return result
+}
+
+// SplitWindows cuts free windows into consecutive slots of the given length, dropping any remainder
+func SplitWindows(windows []TimeWindow, length time.Duration) []TimeWindow {
+	var slots []TimeWindow
+	if length <= 0 {
+		return slots
+	}
+	for _, window := range windows {
+		for start := window.Start; !start.Add(length).After(window.End); start = start.Add(length) {
+			slots = append(slots, TimeWindow{Start: start, End: start.Add(length)})
+		}
+	}
+	return slots
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockWindowUsesDateLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+	date := time.Date(2025, 3, 10, 0, 0, 0, 0, loc) // First weekday after the DST change
+
+	window, err := ClockWindow(date, "09:00", "17:30:00")
+	require.NoError(t, err)
+	assert.Equal(t, "13:00", window.Start.UTC().Format("15:04"))
+	assert.Equal(t, 510*time.Minute, window.End.Sub(window.Start))
+
+	_, err = ClockWindow(date, "17:00", "09:00")
+	assert.Error(t, err)
+	_, err = ClockWindow(date, "9am", "17:00")
+	assert.Error(t, err)
+}
+
+func TestSubtractAndSplitWindows(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2025, 1, 6, hour, minute, 0, 0, time.UTC) }
+	free := []TimeWindow{{at(9, 0), at(17, 0)}}
+	busy := []TimeWindow{
+		{at(12, 0), at(13, 0)},  // lunch
+		{at(8, 30), at(9, 45)},  // overlaps the start
+		{at(15, 0), at(15, 30)}, // appointment
+	}
+
+	left := SubtractWindows(free, busy)
+	assert.Equal(t, []TimeWindow{
+		{at(9, 45), at(12, 0)},
+		{at(13, 0), at(15, 0)},
+		{at(15, 30), at(17, 0)},
+	}, left)
+
+	slots := SplitWindows(left, time.Hour)
+	require.Len(t, slots, 5)
+	assert.Equal(t, at(9, 45), slots[0].Start)
+	assert.Equal(t, at(15, 30), slots[4].Start)
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"time"
+)
+
+// BookingAssignmentMode decides which host of a booking page takes a booking
+type BookingAssignmentMode string
+
+const (
+	BookingRoundRobin     BookingAssignmentMode = "round_robin"     // Rotate through the hosts free at the chosen time
+	BookingFirstAvailable BookingAssignmentMode = "first_available" // First free host in the configured order
+)
+
+// BookingSourcePublic marks appointments booked by visitors through a booking page
+const BookingSourcePublic = "public_booking"
+
+// BookingPage is a public booking link through which visitors book time with a pool of hosts
+type BookingPage struct {
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	Slug        string  `json:"slug" gorm:"column:slug;size:100;uniqueIndex;not null"`
+	Name        string  `json:"name" gorm:"column:name;size:255;not null"`
+	Description *string `json:"description" gorm:"column:description;type:text"`
+
+	HostUserIDs        JSONArray             `json:"host_user_ids" gorm:"column:host_user_ids;type:json"`
+	AssignmentMode     BookingAssignmentMode `json:"assignment_mode" gorm:"column:assignment_mode;default:round_robin"`
+	LastAssignedUserID *uint                 `json:"last_assigned_user_id" gorm:"column:last_assigned_user_id"`
+
+	DurationMinutes  int    `json:"duration_minutes" gorm:"column:duration_minutes;default:30"`
+	BufferMinutes    int    `json:"buffer_minutes" gorm:"column:buffer_minutes;default:0"`
+	MinNoticeMinutes int    `json:"min_notice_minutes" gorm:"column:min_notice_minutes;default:240"`
+	MaxDaysAhead     int    `json:"max_days_ahead" gorm:"column:max_days_ahead;default:30"`
+	Timezone         string `json:"timezone" gorm:"column:timezone;size:50;default:Asia/Kolkata"`
+
+	AppointmentType AppointmentType `json:"appointment_type" gorm:"column:appointment_type;default:consultation"`
+	MeetingType     MeetingType     `json:"meeting_type" gorm:"column:meeting_type;default:video_call"`
+	Location        *string         `json:"location" gorm:"column:location;size:500"`
+	MeetingLink     *string         `json:"meeting_link" gorm:"column:meeting_link;size:500"`
+	ContactTypeID   *uint           `json:"contact_type_id" gorm:"column:contact_type_id"`
+	ContactSourceID *uint           `json:"contact_source_id" gorm:"column:contact_source_id"`
+
+	IsActive bool `json:"is_active" gorm:"column:is_active;default:true"`
+
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"column:updated_at"`
+	CreatedBy *uint      `json:"created_by" gorm:"column:created_by"`
+	UpdatedBy *uint      `json:"updated_by" gorm:"column:updated_by"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"column:deleted_at;index"`
+}
+
+// TableName specifies the table name for BookingPage
+func (BookingPage) TableName() string {
+	return "booking_pages"
+}
+
+// HostIDs returns the page's host user IDs in their configured order
+func (p *BookingPage) HostIDs() []uint {
+	var ids []uint
+	for _, value := range p.HostUserIDs {
+		switch id := value.(type) {
+		case float64:
+			ids = append(ids, uint(id))
+		case uint:
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// BookingHold reserves a slot with one host while a visitor fills in the booking form
+type BookingHold struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	BookingPageID uint      `json:"booking_page_id" gorm:"column:booking_page_id;not null"`
+	UserID        uint      `json:"user_id" gorm:"column:user_id;not null"`
+	StartTime     time.Time `json:"start_time" gorm:"column:start_time;not null"`
+	EndTime       time.Time `json:"end_time" gorm:"column:end_time;not null"`
+	Token         string    `json:"token" gorm:"column:token;size:64;uniqueIndex;not null"`
+	ClientIP      *string   `json:"-" gorm:"column:client_ip;size:45"` // Visitor that placed the hold
+	ExpiresAt     time.Time `json:"expires_at" gorm:"column:expires_at;not null;index"`
+	CreatedAt     time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for BookingHold
+func (BookingHold) TableName() string {
+	return "booking_holds"
+}
+
+// BookingPageRequest represents a request to create or update a booking page
+type BookingPageRequest struct {
+	Slug             string                 `json:"slug" binding:"required,min=3,max=100"`
+	Name             string                 `json:"name" binding:"required,max=255"`
+	Description      *string                `json:"description"`
+	HostUserIDs      []uint                 `json:"host_user_ids" binding:"required,min=1"`
+	AssignmentMode   *BookingAssignmentMode `json:"assignment_mode"`
+	DurationMinutes  int                    `json:"duration_minutes" binding:"required,min=15,max=480"`
+	BufferMinutes    *int                   `json:"buffer_minutes" binding:"omitempty,min=0,max=240"`
+	MinNoticeMinutes *int                   `json:"min_notice_minutes" binding:"omitempty,min=0"`
+	MaxDaysAhead     *int                   `json:"max_days_ahead" binding:"omitempty,min=1,max=365"`
+	Timezone         *string                `json:"timezone"`
+	AppointmentType  *AppointmentType       `json:"appointment_type"`
+	MeetingType      *MeetingType           `json:"meeting_type"`
+	Location         *string                `json:"location" binding:"omitempty,max=500"`
+	MeetingLink      *string                `json:"meeting_link" binding:"omitempty,max=500"`
+	ContactTypeID    *uint                  `json:"contact_type_id"`
+	ContactSourceID  *uint                  `json:"contact_source_id"`
+	IsActive         *bool                  `json:"is_active"`
+}
+
+// PublicBookingPage is the visitor-facing view of a booking page
+type PublicBookingPage struct {
+	Slug            string          `json:"slug"`
+	Name            string          `json:"name"`
+	Description     *string         `json:"description"`
+	DurationMinutes int             `json:"duration_minutes"`
+	Timezone        string          `json:"timezone"`
+	AppointmentType AppointmentType `json:"appointment_type"`
+	MeetingType     MeetingType     `json:"meeting_type"`
+	MaxDaysAhead    int             `json:"max_days_ahead"`
+}
+
+// PublicSlot is an open time on a booking page; hosts are not disclosed
+type PublicSlot struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// BookingHoldRequest asks to hold an open slot
+type BookingHoldRequest struct {
+	StartTime time.Time `json:"start_time" binding:"required"` // RFC 3339, as returned by the slots endpoint
+}
+
+// BookingHoldResponse is a held slot
+type BookingHoldResponse struct {
+	HoldToken string    `json:"hold_token"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PublicRescheduleRequest moves a booking to a newly held slot
+type PublicRescheduleRequest struct {
+	HoldToken string  `json:"hold_token" binding:"required"`
+	Reason    *string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// PublicCancelRequest cancels a booking
+type PublicCancelRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// PublicBookingResponse is the visitor-facing view of a booking with its self-service links
+type PublicBookingResponse struct {
+	AppointmentID uint              `json:"appointment_id"`
+	Title         string            `json:"title"`
+	Status        AppointmentStatus `json:"status"`
+	StartTime     time.Time         `json:"start_time"`
+	EndTime       time.Time         `json:"end_time"`
+	Timezone      string            `json:"timezone"`
+	MeetingType   MeetingType       `json:"meeting_type"`
+	Location      *string           `json:"location,omitempty"`
+	MeetingLink   *string           `json:"meeting_link,omitempty"`
+	ManageURL     string            `json:"manage_url"`
+	RescheduleURL string            `json:"reschedule_url"`
+	CancelURL     string            `json:"cancel_url"`
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// CalendarFeed is a user's subscribable iCalendar feed, authenticated by the secret token in its URL.
+// Only the hash of the token is stored; the URL is only shown when the feed is issued.
+type CalendarFeed struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	UserID         uint       `json:"user_id" gorm:"column:user_id;uniqueIndex;not null"`
+	TokenHash      string     `json:"-" gorm:"column:token_hash;size:64;uniqueIndex;not null"`
+	LastAccessedAt *time.Time `json:"last_accessed_at" gorm:"column:last_accessed_at"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for CalendarFeed
+func (CalendarFeed) TableName() string {
+	return "calendar_feeds"
+}
+
+// HashCalendarFeedToken returns the stored hash of a feed token
+func HashCalendarFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CalendarFeedResponse is a user's feed subscription. URL is only set when the feed is issued.
+type CalendarFeedResponse struct {
+	URL            string     `json:"url,omitempty"`
+	LastAccessedAt *time.Time `json:"last_accessed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Calendar sync providers
+const (
+	CalendarProviderCalDAV = "caldav"
+)
+
+// Calendar sync directions
+const (
+	CalendarSyncRead          = "read"          // Pull busy time only
+	CalendarSyncWrite         = "write"         // Push appointments only
+	CalendarSyncBidirectional = "bidirectional" // Both
+)
+
+// Calendar sync statuses
+const (
+	CalendarSyncPending = "pending"
+	CalendarSyncSuccess = "success"
+	CalendarSyncPartial = "partial" // Completed, but some events failed
+	CalendarSyncFailed  = "failed"
+)
+
+// CalendarIntegration connects a user to an external calendar. For CalDAV, ExternalID is the URL
+// of the calendar collection, the username is kept in Settings and AccessToken holds the password.
+type CalendarIntegration struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	UserID       uint   `json:"user_id" gorm:"column:user_id;not null;index"`
+	Provider     string `json:"provider" gorm:"column:provider;size:50;not null"`
+	ExternalID   string `json:"external_id" gorm:"column:external_id;size:255;not null"`
+	CalendarName string `json:"calendar_name" gorm:"column:calendar_name;size:255;not null"`
+
+	IsEnabled     bool   `json:"is_enabled" gorm:"column:is_enabled;default:true"`
+	SyncDirection string `json:"sync_direction" gorm:"column:sync_direction;size:20;default:bidirectional"`
+	AutoSync      bool   `json:"auto_sync" gorm:"column:auto_sync;default:true"`
+
+	AccessToken    *string    `json:"-" gorm:"column:access_token;type:text"`
+	RefreshToken   *string    `json:"-" gorm:"column:refresh_token;type:text"`
+	TokenExpiresAt *time.Time `json:"token_expires_at" gorm:"column:token_expires_at"`
+
+	LastSyncAt     *time.Time `json:"last_sync_at" gorm:"column:last_sync_at"`
+	LastSyncStatus string     `json:"last_sync_status" gorm:"column:last_sync_status;size:50;default:pending"`
+	SyncErrors     JSONArray  `json:"sync_errors" gorm:"column:sync_errors;type:json"`
+
+	DefaultReminders JSONArray `json:"default_reminders" gorm:"column:default_reminders;type:json"`
+	Settings         JSONMap   `json:"settings" gorm:"column:settings;type:json"`
+
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"column:updated_at"`
+	CreatedBy *uint      `json:"created_by" gorm:"column:created_by"`
+	UpdatedBy *uint      `json:"updated_by" gorm:"column:updated_by"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"column:deleted_at;index"`
+}
+
+// TableName specifies the table name for CalendarIntegration
+func (CalendarIntegration) TableName() string {
+	return "calendar_integrations"
+}
+
+// Pushes reports whether appointments are written to the external calendar
+func (i *CalendarIntegration) Pushes() bool {
+	return i.SyncDirection == CalendarSyncWrite || i.SyncDirection == CalendarSyncBidirectional
+}
+
+// Pulls reports whether busy time is read from the external calendar
+func (i *CalendarIntegration) Pulls() bool {
+	return i.SyncDirection == CalendarSyncRead || i.SyncDirection == CalendarSyncBidirectional
+}
+
+// CalendarSyncEvent records an appointment pushed to an integration's calendar
+type CalendarSyncEvent struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	IntegrationID  uint      `json:"integration_id" gorm:"column:integration_id;not null"`
+	AppointmentID  uint      `json:"appointment_id" gorm:"column:appointment_id;not null"`
+	RemoteHref     string    `json:"remote_href" gorm:"column:remote_href;size:1000;not null"`
+	RemoteETag     *string   `json:"remote_etag" gorm:"column:remote_etag;size:255"`
+	SyncedSequence int       `json:"synced_sequence" gorm:"column:synced_sequence;not null"`
+	SyncedAt       time.Time `json:"synced_at" gorm:"column:synced_at;not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for CalendarSyncEvent
+func (CalendarSyncEvent) TableName() string {
+	return "calendar_sync_events"
+}
+
+// CalendarBusyBlock is time a user is busy on an external calendar
+type CalendarBusyBlock struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	IntegrationID uint      `json:"integration_id" gorm:"column:integration_id;not null;index"`
+	UserID        uint      `json:"user_id" gorm:"column:user_id;not null"`
+	ExternalUID   string    `json:"external_uid" gorm:"column:external_uid;size:500;not null"`
+	StartTime     time.Time `json:"start_time" gorm:"column:start_time;not null"`
+	EndTime       time.Time `json:"end_time" gorm:"column:end_time;not null"`
+	CreatedAt     time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for CalendarBusyBlock
+func (CalendarBusyBlock) TableName() string {
+	return "calendar_busy_blocks"
+}
+
+// CalendarIntegrationRequest connects an external calendar
+type CalendarIntegrationRequest struct {
+	Provider      string  `json:"provider" binding:"required,oneof=caldav"`
+	CalendarURL   string  `json:"calendar_url" binding:"required,url,max=255"`
+	CalendarName  string  `json:"calendar_name" binding:"required,max=255"`
+	Username      string  `json:"username" binding:"max=255"`
+	Password      string  `json:"password"`
+	SyncDirection *string `json:"sync_direction" binding:"omitempty,oneof=read write bidirectional"`
+	AutoSync      *bool   `json:"auto_sync"`
+}
+
+// CalendarIntegrationUpdateRequest changes an integration; omitted fields are left unchanged
+type CalendarIntegrationUpdateRequest struct {
+	CalendarURL   *string `json:"calendar_url" binding:"omitempty,url,max=255"`
+	CalendarName  *string `json:"calendar_name" binding:"omitempty,max=255"`
+	Username      *string `json:"username" binding:"omitempty,max=255"`
+	Password      *string `json:"password"`
+	SyncDirection *string `json:"sync_direction" binding:"omitempty,oneof=read write bidirectional"`
+	AutoSync      *bool   `json:"auto_sync"`
+	IsEnabled     *bool   `json:"is_enabled"`
+}
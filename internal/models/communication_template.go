@@ -0,0 +1,238 @@
+package models
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TemplateChannel represents the channel a communication template is written for
+type TemplateChannel string
+
+const (
+	TemplateChannelEmail    TemplateChannel = "email"
+	TemplateChannelSMS      TemplateChannel = "sms"
+	TemplateChannelWhatsApp TemplateChannel = "whatsapp"
+)
+
+// Keys recognised in CommunicationTemplate.PersonalizationRules
+const (
+	PersonalizationDefaults   = "defaults"   // Object of variable -> value used when the variable resolves empty
+	PersonalizationTransforms = "transforms" // Object of variable -> upper, lower, title or trim
+)
+
+// CommunicationTemplate represents a reusable email, SMS or WhatsApp message with {{variable}} placeholders
+type CommunicationTemplate struct {
+	ID                uint            `json:"id" gorm:"primaryKey"`
+	Name              string          `json:"name" gorm:"column:name;size:255;not null;index"`
+	Description       *string         `json:"description" gorm:"column:description;type:text"`
+	CommunicationType TemplateChannel `json:"communication_type" gorm:"column:communication_type;not null;index"`
+	Category          *string         `json:"category" gorm:"column:category;size:100;index"` // welcome, follow_up, proposal, etc.
+
+	// Template Content
+	Subject      *string   `json:"subject" gorm:"column:subject;size:500"` // For email templates
+	HTMLContent  *string   `json:"html_content" gorm:"column:html_content;type:text"`
+	PlainContent *string   `json:"plain_content" gorm:"column:plain_content;type:text"`
+	Variables    JSONArray `json:"variables" gorm:"column:variables;type:json"` // Variables referenced by the content
+
+	// Configuration
+	IsActive   bool `json:"is_active" gorm:"column:is_active;default:true;index"`
+	IsSystem   bool `json:"is_system" gorm:"column:is_system;default:false"`
+	UsageCount int  `json:"usage_count" gorm:"column:usage_count;default:0;index"`
+
+	// Personalization
+	PersonalizationRules JSONMap `json:"personalization_rules" gorm:"column:personalization_rules;type:json"`
+
+	// Metadata
+	Tags JSONArray `json:"tags" gorm:"column:tags;type:json"`
+
+	// Audit Fields
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+	CreatedBy *uint     `json:"created_by" gorm:"column:created_by"`
+	UpdatedBy *uint     `json:"updated_by" gorm:"column:updated_by"`
+}
+
+// TableName specifies the table name for CommunicationTemplate
+func (CommunicationTemplate) TableName() string {
+	return "communication_templates"
+}
+
+// CommunicationTemplateRequest represents a request to create or update a template
+type CommunicationTemplateRequest struct {
+	Name                 string                 `json:"name" binding:"required,min=2,max=255"`
+	Description          *string                `json:"description"`
+	CommunicationType    TemplateChannel        `json:"communication_type" binding:"required,oneof=email sms whatsapp"`
+	Category             *string                `json:"category" binding:"omitempty,max=100"`
+	Subject              *string                `json:"subject" binding:"omitempty,max=500"`
+	HTMLContent          *string                `json:"html_content"`
+	PlainContent         *string                `json:"plain_content"`
+	IsActive             *bool                  `json:"is_active"`
+	PersonalizationRules map[string]interface{} `json:"personalization_rules"`
+	Tags                 []string               `json:"tags"`
+}
+
+// TemplateRenderRequest represents a request to render a template for a contact
+type TemplateRenderRequest struct {
+	ContactID     uint              `json:"contact_id" binding:"required"`
+	AppointmentID *uint             `json:"appointment_id"`
+	SenderID      *uint             `json:"sender_id"` // Admin user the message is sent as
+	Variables     map[string]string `json:"variables"` // Extra values; override resolved variables of the same name
+	Preview       bool              `json:"preview"`   // Preview renders do not count towards usage_count
+}
+
+// RenderedTemplate is the result of rendering a template
+type RenderedTemplate struct {
+	TemplateID       uint     `json:"template_id"`
+	Subject          string   `json:"subject,omitempty"`
+	HTMLContent      string   `json:"html_content,omitempty"`
+	PlainContent     string   `json:"plain_content,omitempty"`
+	Variables        []string `json:"variables"`         // Every variable the template references
+	MissingVariables []string `json:"missing_variables"` // Referenced variables that resolved to nothing
+}
+
+// IsComplete reports whether every referenced variable was resolved
+func (r *RenderedTemplate) IsComplete() bool {
+	return len(r.MissingVariables) == 0
+}
+
+// TemplateVariable describes a variable available to templates
+type TemplateVariable struct {
+	Name        string `json:"name"`
+	Source      string `json:"source"` // contact, appointment, sender, system
+	Description string `json:"description"`
+}
+
+// templatePlaceholder matches {{ variable }} placeholders; names may be dotted, e.g. appointment.scheduled_date
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*)\s*\}\}`)
+
+// ReferencedVariables returns the sorted, de-duplicated variable names used in the template content
+func (t *CommunicationTemplate) ReferencedVariables() []string {
+	seen := make(map[string]bool)
+	for _, content := range []*string{t.Subject, t.HTMLContent, t.PlainContent} {
+		if content == nil {
+			continue
+		}
+		for _, match := range templatePlaceholder.FindAllStringSubmatch(*content, -1) {
+			seen[match[1]] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render substitutes values into the template content. Values are HTML-escaped in html_content.
+// Personalization defaults fill variables that resolve empty; anything still empty is reported as missing.
+func (t *CommunicationTemplate) Render(values map[string]string) *RenderedTemplate {
+	defaults := stringMapRule(t.PersonalizationRules, PersonalizationDefaults)
+	transforms := stringMapRule(t.PersonalizationRules, PersonalizationTransforms)
+
+	result := &RenderedTemplate{
+		TemplateID:       t.ID,
+		Variables:        t.ReferencedVariables(),
+		MissingVariables: []string{},
+	}
+
+	resolved := make(map[string]string, len(result.Variables))
+	for _, name := range result.Variables {
+		value := strings.TrimSpace(values[name])
+		if value == "" {
+			value = defaults[name]
+		}
+		if value == "" {
+			result.MissingVariables = append(result.MissingVariables, name)
+			continue
+		}
+		resolved[name] = applyTemplateTransform(value, transforms[name])
+	}
+
+	substitute := func(content *string, escape bool) string {
+		if content == nil {
+			return ""
+		}
+		return templatePlaceholder.ReplaceAllStringFunc(*content, func(placeholder string) string {
+			value := resolved[templatePlaceholder.FindStringSubmatch(placeholder)[1]]
+			if escape {
+				return html.EscapeString(value)
+			}
+			return value
+		})
+	}
+
+	result.Subject = substitute(t.Subject, false)
+	result.HTMLContent = substitute(t.HTMLContent, true)
+	result.PlainContent = substitute(t.PlainContent, false)
+	return result
+}
+
+// ValidatePersonalizationRules checks that the rules only use supported keys and transforms
+func ValidatePersonalizationRules(rules map[string]interface{}) error {
+	for key, rule := range rules {
+		if key != PersonalizationDefaults && key != PersonalizationTransforms {
+			return fmt.Errorf("unknown personalization rule %q", key)
+		}
+		entries, ok := rule.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("personalization rule %q must be an object of variable names", key)
+		}
+		for name, value := range entries {
+			text, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("personalization rule %q for %s must be a string", key, name)
+			}
+			if key == PersonalizationTransforms {
+				if _, ok := templateTransforms[text]; !ok {
+					return fmt.Errorf("unknown transform %q for %s", text, name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Transforms that personalization rules can apply to a resolved value
+var templateTransforms = map[string]func(string) string{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"title": func(value string) string {
+		words := strings.Fields(strings.ToLower(value))
+		for i, word := range words {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			words[i] = string(runes)
+		}
+		return strings.Join(words, " ")
+	},
+}
+
+func applyTemplateTransform(value, transform string) string {
+	if fn, ok := templateTransforms[transform]; ok {
+		return fn(value)
+	}
+	return value
+}
+
+// stringMapRule reads a personalization rule as variable -> string
+func stringMapRule(rules JSONMap, key string) map[string]string {
+	result := make(map[string]string)
+	entries, ok := rules[key].(map[string]interface{})
+	if !ok {
+		return result
+	}
+	for name, value := range entries {
+		if text, ok := value.(string); ok {
+			result[name] = text
+		}
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommunicationTemplateRender(t *testing.T) {
+	subject := "Welcome {{ first_name }}"
+	htmlContent := "<p>Hi {{first_name}} from {{company}}, see you on {{appointment.scheduled_date}}</p>"
+	plainContent := "Hi {{first_name}}, your rep is {{sender.name}}"
+	template := &CommunicationTemplate{
+		ID:           4,
+		Subject:      &subject,
+		HTMLContent:  &htmlContent,
+		PlainContent: &plainContent,
+		PersonalizationRules: JSONMap{
+			PersonalizationDefaults:   map[string]interface{}{"company": "your team"},
+			PersonalizationTransforms: map[string]interface{}{"first_name": "title"},
+		},
+	}
+
+	assert.Equal(t, []string{"appointment.scheduled_date", "company", "first_name", "sender.name"}, template.ReferencedVariables())
+
+	rendered := template.Render(map[string]string{
+		"first_name":                 "jANE <b>",
+		"appointment.scheduled_date": "2025-03-01",
+	})
+
+	assert.Equal(t, "Welcome Jane <b>", rendered.Subject)
+	assert.Equal(t, "<p>Hi Jane &lt;b&gt; from your team, see you on 2025-03-01</p>", rendered.HTMLContent)
+	assert.Equal(t, "Hi Jane <b>, your rep is ", rendered.PlainContent)
+	assert.Equal(t, []string{"sender.name"}, rendered.MissingVariables)
+	assert.False(t, rendered.IsComplete())
+}
+
+func TestValidatePersonalizationRules(t *testing.T) {
+	assert.NoError(t, ValidatePersonalizationRules(map[string]interface{}{
+		PersonalizationDefaults: map[string]interface{}{"company": "your team"},
+	}))
+	assert.Error(t, ValidatePersonalizationRules(map[string]interface{}{
+		PersonalizationTransforms: map[string]interface{}{"first_name": "reverse"},
+	}))
+	assert.Error(t, ValidatePersonalizationRules(map[string]interface{}{"fallbacks": map[string]interface{}{}}))
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	apperrors "contact-service/pkg/errors"
 )
 
 // ContactStatus represents the status of a contact in the sales pipeline
@@ -187,6 +189,9 @@ type Contact struct {
 	TagAssignments        []ContactTagAssignment `json:"tag_assignments,omitempty" gorm:"foreignKey:ContactID"`
 	// Communications        []ContactCommunication `json:"communications,omitempty" gorm:"foreignKey:ContactID"`
 	Appointments          []Appointment          `json:"appointments,omitempty" gorm:"foreignKey:ContactID"`
+
+	// Non-blocking validation rule violations from the last write; not persisted
+	ValidationWarnings    []apperrors.FieldError `json:"validation_warnings,omitempty" gorm:"-"`
 }
 
 // TableName specifies the table name for Contact
@@ -328,4 +333,5 @@ type ContactResponse struct {
 	DaysInStatus          int                    `json:"days_in_status"`
 	IsHighPriority        bool                   `json:"is_high_priority"`
 	IsHotLead             bool                   `json:"is_hot_lead"`
+	ValidationWarnings    []apperrors.FieldError `json:"validation_warnings,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"time"
+)
+
+// CommunicationType represents the channel of a contact communication
+type CommunicationType string
+
+const (
+	CommunicationEmail     CommunicationType = "email"
+	CommunicationSMS       CommunicationType = "sms"
+	CommunicationPhoneCall CommunicationType = "phone_call"
+	CommunicationWhatsApp  CommunicationType = "whatsapp"
+)
+
+// CommunicationStatus represents the delivery status of a contact communication
+type CommunicationStatus string
+
+const (
+	CommunicationStatusDraft     CommunicationStatus = "draft"
+	CommunicationStatusQueued    CommunicationStatus = "queued"
+	CommunicationStatusSending   CommunicationStatus = "sending"
+	CommunicationStatusSent      CommunicationStatus = "sent"
+	CommunicationStatusDelivered CommunicationStatus = "delivered"
+	CommunicationStatusRead      CommunicationStatus = "read"
+	CommunicationStatusReplied   CommunicationStatus = "replied"
+	CommunicationStatusFailed    CommunicationStatus = "failed"
+	CommunicationStatusBounced   CommunicationStatus = "bounced"
+)
+
+// ActivityEntityCommunication is the related_entity_type of activities logged for a communication
+const ActivityEntityCommunication = "contact_communication"
+
+// ContactCommunication represents a message exchanged with a contact
+type ContactCommunication struct {
+	ID        uint `json:"id" gorm:"primaryKey"`
+	ContactID uint `json:"contact_id" gorm:"column:contact_id;not null;index"`
+
+	// Communication Basic Information
+	CommunicationType CommunicationType   `json:"communication_type" gorm:"column:communication_type;not null;index"`
+	Direction         ActivityDirection   `json:"direction" gorm:"column:direction;not null"`
+	Subject           *string             `json:"subject" gorm:"column:subject;size:500"`
+	Content           *string             `json:"content" gorm:"column:content;type:text"`
+	Status            CommunicationStatus `json:"status" gorm:"column:status;default:draft;index"`
+	Priority          ContactPriority     `json:"priority" gorm:"column:priority;default:medium"`
+
+	// Sender and Recipient Information
+	FromEmail *string   `json:"from_email" gorm:"column:from_email;size:255"`
+	ToEmail   *string   `json:"to_email" gorm:"column:to_email;size:255"`
+	CCEmails  JSONArray `json:"cc_emails" gorm:"column:cc_emails;type:json"`
+	BCCEmails JSONArray `json:"bcc_emails" gorm:"column:bcc_emails;type:json"`
+
+	// Email Specific Fields
+	EmailMessageID  *string `json:"email_message_id" gorm:"column:email_message_id;size:255;index"`
+	EmailThreadID   *string `json:"email_thread_id" gorm:"column:email_thread_id;size:255;index"`
+	EmailTemplateID *uint   `json:"email_template_id" gorm:"column:email_template_id"`
+	HTMLContent     *string `json:"html_content" gorm:"column:html_content;type:text"`
+	PlainContent    *string `json:"plain_content" gorm:"column:plain_content;type:text"`
+
+	// Delivery and Engagement Tracking
+	SentAt         *time.Time `json:"sent_at" gorm:"column:sent_at;index"`
+	DeliveredAt    *time.Time `json:"delivered_at" gorm:"column:delivered_at"`
+	OpenedAt       *time.Time `json:"opened_at" gorm:"column:opened_at"`
+	FirstOpenedAt  *time.Time `json:"first_opened_at" gorm:"column:first_opened_at"`
+	LastOpenedAt   *time.Time `json:"last_opened_at" gorm:"column:last_opened_at"`
+	OpenCount      int        `json:"open_count" gorm:"column:open_count;default:0"`
+	ClickedAt      *time.Time `json:"clicked_at" gorm:"column:clicked_at"`
+	FirstClickedAt *time.Time `json:"first_clicked_at" gorm:"column:first_clicked_at"`
+	LastClickedAt  *time.Time `json:"last_clicked_at" gorm:"column:last_clicked_at"`
+	ClickCount     int        `json:"click_count" gorm:"column:click_count;default:0"`
+	RepliedAt      *time.Time `json:"replied_at" gorm:"column:replied_at"`
+
+	// Delivery Queue
+	SendAttempts    int        `json:"send_attempts" gorm:"column:send_attempts;default:0"`
+	MaxSendAttempts int        `json:"max_send_attempts" gorm:"column:max_send_attempts;default:3"`
+	NextAttemptAt   *time.Time `json:"next_attempt_at" gorm:"column:next_attempt_at"`
+	ClaimedAt       *time.Time `json:"-" gorm:"column:claimed_at"`
+	LastError       *string    `json:"last_error" gorm:"column:last_error;type:text"`
+
+	// Related Information
+	ParentCommunicationID *uint `json:"parent_communication_id" gorm:"column:parent_communication_id;index"`
+	TemplateID            *uint `json:"template_id" gorm:"column:template_id"`
+
+	// User and Assignment
+	SentBy     *uint `json:"sent_by" gorm:"column:sent_by;index"`
+	AssignedTo *uint `json:"assigned_to" gorm:"column:assigned_to;index"`
+
+	// Metadata
+	Tags     JSONArray `json:"tags" gorm:"column:tags;type:json"`
+	Metadata JSONMap   `json:"metadata" gorm:"column:metadata;type:json"`
+
+	// Audit Fields
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"column:updated_at"`
+	CreatedBy *uint      `json:"created_by" gorm:"column:created_by"`
+	UpdatedBy *uint      `json:"updated_by" gorm:"column:updated_by"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" gorm:"column:deleted_at;index"`
+}
+
+// TableName specifies the table name for ContactCommunication
+func (ContactCommunication) TableName() string {
+	return "contact_communications"
+}
+
+// SendEmailRequest represents a request to email a contact, either from a template or with ad-hoc content.
+// Ad-hoc content may use the same {{variable}} placeholders as templates.
+type SendEmailRequest struct {
+	ContactID             uint              `json:"contact_id" binding:"required"`
+	TemplateID            *uint             `json:"template_id"`
+	AppointmentID         *uint             `json:"appointment_id"`
+	Subject               *string           `json:"subject" binding:"omitempty,max=500"`
+	HTMLContent           *string           `json:"html_content"`
+	PlainContent          *string           `json:"plain_content"`
+	CC                    []string          `json:"cc" binding:"omitempty,dive,email"`
+	BCC                   []string          `json:"bcc" binding:"omitempty,dive,email"`
+	Variables             map[string]string `json:"variables"`
+	ParentCommunicationID *uint             `json:"parent_communication_id"` // Reply within the parent's thread
+	Priority              ContactPriority   `json:"priority" binding:"omitempty,oneof=low medium high urgent"`
+}
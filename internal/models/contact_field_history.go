@@ -0,0 +1,242 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FieldChangeType represents the kind of change recorded for a contact field
+type FieldChangeType string
+
+const (
+	FieldChangeCreate FieldChangeType = "create"
+	FieldChangeUpdate FieldChangeType = "update"
+	FieldChangeDelete FieldChangeType = "delete"
+)
+
+// Change sources recorded with each field change
+const (
+	ChangeSourceAPI        = "api"
+	ChangeSourceBulk       = "bulk"
+	ChangeSourceImport     = "import"
+	ChangeSourceLifecycle  = "lifecycle"
+	ChangeSourceAssignment = "assignment"
+	ChangeSourceMCP        = "mcp"
+	ChangeSourceMerge      = "merge"
+	ChangeSourceTracking   = "email_tracking"
+)
+
+// ContactFieldHistory records a single field change on a contact
+type ContactFieldHistory struct {
+	ID         uint            `json:"id" gorm:"primaryKey"`
+	ContactID  uint            `json:"contact_id" gorm:"column:contact_id;not null;index"`
+	FieldName  string          `json:"field_name" gorm:"column:field_name;size:100;not null;index"`
+	OldValue   *string         `json:"old_value" gorm:"column:old_value;type:text"`
+	NewValue   *string         `json:"new_value" gorm:"column:new_value;type:text"`
+	ChangeType FieldChangeType `json:"change_type" gorm:"column:change_type;not null;index"`
+
+	// Change Context
+	ChangedBy    *uint   `json:"changed_by" gorm:"column:changed_by;index"`
+	ChangeReason *string `json:"change_reason" gorm:"column:change_reason;size:255"`
+	ChangeSource string  `json:"change_source" gorm:"column:change_source;size:100"`
+
+	// Validation and Quality
+	ValidationPassed bool      `json:"validation_passed" gorm:"column:validation_passed;default:true"`
+	ValidationErrors JSONArray `json:"validation_errors" gorm:"column:validation_errors;type:json"`
+	DataQualityScore float64   `json:"data_quality_score" gorm:"column:data_quality_score;type:decimal(3,2);default:1.00"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;index"`
+}
+
+// TableName specifies the table name for ContactFieldHistory
+func (ContactFieldHistory) TableName() string {
+	return "contact_field_history"
+}
+
+// ContactHistoryResponse represents a contact reconstructed at a point in time
+type ContactHistoryResponse struct {
+	ContactID uint                  `json:"contact_id"`
+	At        time.Time             `json:"at"`
+	Contact   *Contact              `json:"contact"`
+	Reverted  []ContactFieldHistory `json:"reverted_changes"` // Changes made after At, newest first
+}
+
+// Contact columns that are not tracked in field history
+var untrackedContactFields = map[string]bool{
+	"id":                  true,
+	"created_at":          true,
+	"updated_at":          true,
+	"created_by":          true,
+	"updated_by":          true,
+	"last_activity_date":  true,
+	"contact_type":        true,
+	"contact_source":      true,
+	"activities":          true,
+	"tag_assignments":     true,
+	"appointments":        true,
+	"validation_warnings": true,
+}
+
+// trackedContactFields maps tracked column names to Contact struct field indexes
+var trackedContactFields = func() map[string]int {
+	fields := make(map[string]int)
+	contactType := reflect.TypeOf(Contact{})
+	for i := 0; i < contactType.NumField(); i++ {
+		name := strings.Split(contactType.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" && !untrackedContactFields[name] {
+			fields[name] = i
+		}
+	}
+	return fields
+}()
+
+// FieldValues returns the tracked fields of the contact as history-formatted strings
+func (c *Contact) FieldValues() map[string]*string {
+	values := make(map[string]*string, len(trackedContactFields))
+	contactValue := reflect.ValueOf(c).Elem()
+	for name, index := range trackedContactFields {
+		values[name] = formatFieldValue(contactValue.Field(index))
+	}
+	return values
+}
+
+// ApplyFieldValues sets tracked fields from history-formatted strings
+func (c *Contact) ApplyFieldValues(values map[string]*string) error {
+	contactValue := reflect.ValueOf(c).Elem()
+	for name, value := range values {
+		index, ok := trackedContactFields[name]
+		if !ok {
+			continue
+		}
+		if err := parseFieldValue(contactValue.Field(index), value); err != nil {
+			return fmt.Errorf("invalid value for %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// BuildFieldHistory returns one history row per tracked field that differs between before and after.
+// A nil before records the creation of the contact.
+func BuildFieldHistory(before, after *Contact, changedBy *uint, source, reason string) []ContactFieldHistory {
+	var oldValues map[string]*string
+	if before != nil {
+		oldValues = before.FieldValues()
+	}
+	newValues := after.FieldValues()
+
+	var reasonPtr *string
+	if reason != "" {
+		reasonPtr = &reason
+	}
+
+	var changes []ContactFieldHistory
+	for name, newValue := range newValues {
+		var oldValue *string
+		if oldValues != nil {
+			oldValue = oldValues[name]
+		}
+		if sameFieldValue(oldValue, newValue) || (before == nil && newValue == nil) {
+			continue
+		}
+
+		changeType := FieldChangeUpdate
+		if before == nil {
+			changeType = FieldChangeCreate
+		} else if name == "deleted_at" && newValue != nil {
+			changeType = FieldChangeDelete
+		}
+
+		changes = append(changes, ContactFieldHistory{
+			ContactID:        after.ID,
+			FieldName:        name,
+			OldValue:         oldValue,
+			NewValue:         newValue,
+			ChangeType:       changeType,
+			ChangedBy:        changedBy,
+			ChangeReason:     reasonPtr,
+			ChangeSource:     source,
+			ValidationPassed: true,
+			DataQualityScore: 1,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].FieldName < changes[j].FieldName })
+	return changes
+}
+
+func sameFieldValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// formatFieldValue renders a field value for storage in history; nil pointers and maps are stored as NULL
+func formatFieldValue(value reflect.Value) *string {
+	if value.Kind() == reflect.Ptr || value.Kind() == reflect.Map {
+		if value.IsNil() {
+			return nil
+		}
+	}
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	var formatted string
+	switch v := value.Interface().(type) {
+	case time.Time:
+		// Stored at second precision to match the database columns
+		formatted = v.UTC().Format(time.RFC3339)
+	default:
+		if value.Kind() == reflect.String {
+			formatted = value.String()
+		} else {
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil
+			}
+			formatted = string(encoded)
+		}
+	}
+	return &formatted
+}
+
+// parseFieldValue is the inverse of formatFieldValue
+func parseFieldValue(field reflect.Value, value *string) error {
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	targetType := field.Type()
+	if targetType.Kind() == reflect.Ptr {
+		targetType = targetType.Elem()
+	}
+
+	target := reflect.New(targetType)
+	switch {
+	case targetType == reflect.TypeOf(time.Time{}):
+		parsed, err := time.Parse(time.RFC3339Nano, *value)
+		if err != nil {
+			return err
+		}
+		target.Elem().Set(reflect.ValueOf(parsed))
+	case targetType.Kind() == reflect.String:
+		target.Elem().SetString(*value)
+	default:
+		if err := json.Unmarshal([]byte(*value), target.Interface()); err != nil {
+			return err
+		}
+	}
+
+	if field.Kind() == reflect.Ptr {
+		field.Set(target)
+	} else {
+		field.Set(target.Elem())
+	}
+	return nil
+}
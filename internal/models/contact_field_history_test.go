@@ -0,0 +1,94 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildFieldHistoryRecordsChangedFields(t *testing.T) {
+	company := "Acme Corp"
+	assignee := uint(7)
+	before := &Contact{ID: 1, FirstName: "Jane", Email: "jane@example.com", Status: StatusNew}
+	after := *before
+	after.Status = StatusContacted
+	after.Company = &company
+	after.AssignedTo = &assignee
+	after.UpdatedAt = time.Now()
+
+	changedBy := uint(3)
+	changes := BuildFieldHistory(before, &after, &changedBy, ChangeSourceAPI, "Contact updated")
+	require.Len(t, changes, 3)
+
+	byField := make(map[string]ContactFieldHistory)
+	for _, change := range changes {
+		byField[change.FieldName] = change
+		assert.Equal(t, FieldChangeUpdate, change.ChangeType)
+		assert.Equal(t, ChangeSourceAPI, change.ChangeSource)
+		assert.Equal(t, &changedBy, change.ChangedBy)
+	}
+
+	assert.Equal(t, "new", *byField["status"].OldValue)
+	assert.Equal(t, "contacted", *byField["status"].NewValue)
+	assert.Nil(t, byField["company"].OldValue)
+	assert.Equal(t, "Acme Corp", *byField["company"].NewValue)
+	assert.Equal(t, "7", *byField["assigned_to"].NewValue)
+}
+
+func TestBuildFieldHistoryMarksCreateAndDelete(t *testing.T) {
+	contact := &Contact{ID: 2, FirstName: "Raj", Email: "raj@example.com", Status: StatusNew}
+
+	created := BuildFieldHistory(nil, contact, nil, ChangeSourceImport, "")
+	require.NotEmpty(t, created)
+	for _, change := range created {
+		assert.Equal(t, FieldChangeCreate, change.ChangeType)
+		assert.Nil(t, change.OldValue)
+		assert.Nil(t, change.ChangeReason)
+	}
+
+	deleted := *contact
+	now := time.Now()
+	deleted.DeletedAt = &now
+	changes := BuildFieldHistory(contact, &deleted, nil, ChangeSourceAPI, "Contact deleted")
+	require.Len(t, changes, 1)
+	assert.Equal(t, "deleted_at", changes[0].FieldName)
+	assert.Equal(t, FieldChangeDelete, changes[0].ChangeType)
+}
+
+func TestApplyFieldValuesRevertsChanges(t *testing.T) {
+	notes := "Initial call"
+	followup := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+	original := &Contact{
+		ID:               3,
+		FirstName:        "Ana",
+		Email:            "ana@example.com",
+		Status:           StatusQualified,
+		LeadScore:        40,
+		Notes:            &notes,
+		NextFollowupDate: &followup,
+		Tags:             JSONMap{"tier": "gold"},
+	}
+
+	current := *original
+	current.Status = StatusClosedLost
+	current.LeadScore = 10
+	current.Notes = nil
+	current.NextFollowupDate = nil
+	current.Tags = nil
+
+	values := current.FieldValues()
+	for _, change := range BuildFieldHistory(original, &current, nil, ChangeSourceAPI, "") {
+		values[change.FieldName] = change.OldValue
+	}
+	require.NoError(t, current.ApplyFieldValues(values))
+
+	assert.Equal(t, StatusQualified, current.Status)
+	assert.Equal(t, 40, current.LeadScore)
+	require.NotNil(t, current.Notes)
+	assert.Equal(t, "Initial call", *current.Notes)
+	require.NotNil(t, current.NextFollowupDate)
+	assert.True(t, followup.Equal(*current.NextFollowupDate))
+	assert.Equal(t, "gold", current.Tags["tier"])
+}
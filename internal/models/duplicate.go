@@ -0,0 +1,173 @@
+package models
+
+import (
+	"time"
+)
+
+// DuplicateGroupStatus represents the resolution status of a duplicate group
+type DuplicateGroupStatus string
+
+const (
+	DuplicateStatusDetected  DuplicateGroupStatus = "detected"
+	DuplicateStatusReviewing DuplicateGroupStatus = "reviewing"
+	DuplicateStatusMerged    DuplicateGroupStatus = "merged"
+	DuplicateStatusIgnored   DuplicateGroupStatus = "ignored"
+	DuplicateStatusSplit     DuplicateGroupStatus = "split"
+)
+
+// MergeStrategy represents the overall strategy used for a merge
+type MergeStrategy string
+
+const (
+	MergeStrategyKeepMaster  MergeStrategy = "keep_master"
+	MergeStrategyMergeFields MergeStrategy = "merge_fields"
+	MergeStrategyManual      MergeStrategy = "manual"
+)
+
+// FieldMergeStrategy represents how a single field is resolved during a merge
+type FieldMergeStrategy string
+
+const (
+	FieldKeepMaster    FieldMergeStrategy = "keep_master"    // Always keep the master value
+	FieldKeepDuplicate FieldMergeStrategy = "keep_duplicate" // Take the duplicate value when it is set
+	FieldFillEmpty     FieldMergeStrategy = "fill_empty"     // Take the duplicate value only when master is empty
+	FieldMostRecent    FieldMergeStrategy = "most_recent"    // Take the value from the most recently updated contact
+	FieldUnion         FieldMergeStrategy = "union"          // Merge JSON maps, master keys win
+	FieldConcatenate   FieldMergeStrategy = "concatenate"    // Join text values
+)
+
+// ContactDuplicateGroup represents a set of contacts detected as duplicates of each other
+type ContactDuplicateGroup struct {
+	ID              uint   `json:"id" gorm:"primaryKey"`
+	GroupHash       string `json:"group_hash" gorm:"column:group_hash;size:64;not null;uniqueIndex"`
+	MasterContactID *uint  `json:"master_contact_id" gorm:"column:master_contact_id;index"`
+	DuplicateCount  int    `json:"duplicate_count" gorm:"column:duplicate_count;default:0"`
+
+	// Detection
+	MatchCriteria   JSONMap `json:"match_criteria" gorm:"column:match_criteria;type:json"`
+	ConfidenceScore float64 `json:"confidence_score" gorm:"column:confidence_score;type:decimal(5,2);default:0.00;index"`
+
+	// Resolution
+	Status          DuplicateGroupStatus `json:"status" gorm:"column:status;default:detected;index"`
+	ResolutionNotes *string              `json:"resolution_notes" gorm:"column:resolution_notes;type:text"`
+
+	// Audit
+	DetectedAt time.Time  `json:"detected_at" gorm:"column:detected_at;default:CURRENT_TIMESTAMP"`
+	ResolvedAt *time.Time `json:"resolved_at" gorm:"column:resolved_at"`
+	ResolvedBy *uint      `json:"resolved_by" gorm:"column:resolved_by"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"column:updated_at"`
+
+	// Relationships
+	MasterContact *Contact                 `json:"master_contact,omitempty" gorm:"foreignKey:MasterContactID"`
+	Members       []ContactDuplicateMember `json:"members,omitempty" gorm:"foreignKey:DuplicateGroupID"`
+}
+
+// TableName specifies the table name for ContactDuplicateGroup
+func (ContactDuplicateGroup) TableName() string {
+	return "contact_duplicate_groups"
+}
+
+// ContactDuplicateMember links a contact to a duplicate group
+type ContactDuplicateMember struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	DuplicateGroupID uint      `json:"duplicate_group_id" gorm:"column:duplicate_group_id;not null;index"`
+	ContactID        uint      `json:"contact_id" gorm:"column:contact_id;not null;index"`
+	IsMaster         bool      `json:"is_master" gorm:"column:is_master;default:false"`
+	SimilarityScore  float64   `json:"similarity_score" gorm:"column:similarity_score;type:decimal(5,2);default:0.00"`
+	MatchingFields   JSONArray `json:"matching_fields" gorm:"column:matching_fields;type:json"`
+	CreatedAt        time.Time `json:"created_at" gorm:"column:created_at"`
+
+	// Relationships
+	Contact *Contact `json:"contact,omitempty" gorm:"foreignKey:ContactID"`
+}
+
+// TableName specifies the table name for ContactDuplicateMember
+func (ContactDuplicateMember) TableName() string {
+	return "contact_duplicate_members"
+}
+
+// ContactMergeHistory records a single merge of a duplicate contact into a master
+type ContactMergeHistory struct {
+	ID               uint  `json:"id" gorm:"primaryKey"`
+	MasterContactID  uint  `json:"master_contact_id" gorm:"column:master_contact_id;not null;index"`
+	MergedContactID  uint  `json:"merged_contact_id" gorm:"column:merged_contact_id;not null;index"`
+	DuplicateGroupID *uint `json:"duplicate_group_id" gorm:"column:duplicate_group_id;index"`
+
+	// Merge Details
+	MergeStrategy     MergeStrategy `json:"merge_strategy" gorm:"column:merge_strategy;default:merge_fields"`
+	MergedFields      JSONMap       `json:"merged_fields" gorm:"column:merged_fields;type:json"` // column -> strategy and value written to master
+	ConflictsResolved JSONMap       `json:"conflicts_resolved" gorm:"column:conflicts_resolved;type:json"`
+
+	// Original master/duplicate snapshots and re-parented record IDs
+	OriginalData JSONMap `json:"original_data" gorm:"column:original_data;type:json"`
+
+	// Audit
+	MergedAt   time.Time `json:"merged_at" gorm:"column:merged_at;default:CURRENT_TIMESTAMP;index"`
+	MergedBy   uint      `json:"merged_by" gorm:"column:merged_by;not null"`
+	MergeNotes *string   `json:"merge_notes" gorm:"column:merge_notes;type:text"`
+
+	// Unmerge
+	UnmergedAt   *time.Time `json:"unmerged_at" gorm:"column:unmerged_at"`
+	UnmergedBy   *uint      `json:"unmerged_by" gorm:"column:unmerged_by"`
+	UnmergeNotes *string    `json:"unmerge_notes" gorm:"column:unmerge_notes;type:text"`
+}
+
+// IsUnmerged checks if the merge has been undone
+func (h *ContactMergeHistory) IsUnmerged() bool {
+	return h.UnmergedAt != nil
+}
+
+// TableName specifies the table name for ContactMergeHistory
+func (ContactMergeHistory) TableName() string {
+	return "contact_merge_history"
+}
+
+// DuplicateDetectionRequest represents a request to run duplicate detection
+type DuplicateDetectionRequest struct {
+	ContactID *uint   `json:"contact_id"`                                  // Limit detection to a single contact
+	Threshold float64 `json:"threshold" binding:"omitempty,min=1,max=100"` // Minimum confidence score (default 70)
+}
+
+// DuplicateGroupStatusRequest represents a request to change a duplicate group's status
+type DuplicateGroupStatusRequest struct {
+	Status DuplicateGroupStatus `json:"status" binding:"required,oneof=reviewing ignored split detected"`
+	Notes  *string              `json:"notes" binding:"omitempty,max=2000"`
+}
+
+// ContactMergeRequest represents a request to merge duplicate contacts into a master
+type ContactMergeRequest struct {
+	MasterContactID     uint                          `json:"master_contact_id" binding:"required,min=1"`
+	DuplicateContactIDs []uint                        `json:"duplicate_contact_ids" binding:"required,min=1,dive,min=1"`
+	DuplicateGroupID    *uint                         `json:"duplicate_group_id"`
+	FieldStrategies     map[string]FieldMergeStrategy `json:"field_strategies"` // Per-field overrides keyed by column name
+	Notes               *string                       `json:"notes" binding:"omitempty,max=2000"`
+}
+
+// ContactUnmergeRequest represents a request to undo a merge
+type ContactUnmergeRequest struct {
+	Notes *string `json:"notes" binding:"omitempty,max=2000"`
+}
+
+// DuplicateDetectionResponse summarizes a detection run
+type DuplicateDetectionResponse struct {
+	ContactsScanned int                     `json:"contacts_scanned"`
+	PairsCompared   int                     `json:"pairs_compared"`
+	GroupsFound     int                     `json:"groups_found"`
+	Groups          []ContactDuplicateGroup `json:"groups"`
+}
+
+// ContactMergeResponse summarizes a merge operation
+type ContactMergeResponse struct {
+	MasterContact *Contact              `json:"master_contact"`
+	Merges        []ContactMergeHistory `json:"merges"`
+}
+
+// ContactUnmergeResponse summarizes an unmerge operation
+type ContactUnmergeResponse struct {
+	MasterContact   *Contact            `json:"master_contact"`
+	RestoredContact *Contact            `json:"restored_contact"`
+	RestoredFields  []string            `json:"restored_fields"`
+	MovedRecords    map[string][]uint   `json:"moved_records"`
+	History         ContactMergeHistory `json:"history"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Export job statuses
+const (
+	ExportStatusPending    = "pending"
+	ExportStatusProcessing = "processing"
+	ExportStatusCompleted  = "completed"
+	ExportStatusFailed     = "failed"
+	ExportStatusExpired    = "expired" // File removed after expires_at
+)
+
+// Export job types
+const (
+	ExportJobTypeContacts  = "contacts"
+	ExportJobTypeAnalytics = "analytics"
+)
+
+// ExportJobRequest represents a request to run an export in the background
+type ExportJobRequest struct {
+	JobType      string                 `json:"job_type" binding:"required"`      // contacts, analytics
+	ExportFormat string                 `json:"export_format" binding:"required"` // csv, json, xlsx (or excel), pdf
+	StartDate    string                 `json:"start_date"`                       // YYYY-MM-DD
+	EndDate      string                 `json:"end_date"`                         // YYYY-MM-DD
+	Filters      map[string]interface{} `json:"filters"`
+	UserIDs      []uint                 `json:"user_ids"`
+}
+
+// IsDownloadable checks if the export file is ready and has not expired
+func (j *ExportJob) IsDownloadable(now time.Time) bool {
+	if j.Status != ExportStatusCompleted || j.FilePath == nil {
+		return false
+	}
+	return j.ExpiresAt == nil || now.Before(*j.ExpiresAt)
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ImportBatchStatus represents the processing status of an import job
+type ImportBatchStatus string
+
+const (
+	ImportStatusPending    ImportBatchStatus = "pending"
+	ImportStatusProcessing ImportBatchStatus = "processing"
+	ImportStatusCompleted  ImportBatchStatus = "completed"
+	ImportStatusFailed     ImportBatchStatus = "failed"
+	ImportStatusCancelled  ImportBatchStatus = "cancelled"
+)
+
+// ImportFailedRecord is a CSV row that could not be imported
+type ImportFailedRecord struct {
+	Row    int               `json:"row"`
+	Data   map[string]string `json:"data"` // Original CSV values keyed by header
+	Errors []string          `json:"errors"`
+}
+
+// ImportFailedRecords is a JSON column holding the failed rows of an import
+type ImportFailedRecords []ImportFailedRecord
+
+// Value implements the driver Valuer interface
+func (r ImportFailedRecords) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+// Scan implements the sql Scanner interface
+func (r *ImportFailedRecords) Scan(value interface{}) error {
+	if value == nil {
+		*r = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into ImportFailedRecords", value)
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// ContactImportBatch tracks a background CSV import job
+type ContactImportBatch struct {
+	ID            uint   `json:"id" gorm:"primaryKey"`
+	BatchName     string `json:"batch_name" gorm:"column:batch_name;size:255;not null;index"`
+	FileName      string `json:"file_name" gorm:"column:file_name;size:255"`
+	FileSizeBytes int64  `json:"file_size_bytes" gorm:"column:file_size_bytes;default:0"`
+	TotalRecords  int    `json:"total_records" gorm:"column:total_records;default:0"`
+
+	// Processing Status
+	Status             ImportBatchStatus `json:"status" gorm:"column:status;default:pending;index"`
+	ProgressPercentage float64           `json:"progress_percentage" gorm:"column:progress_percentage;type:decimal(5,2);default:0.00"`
+
+	// Results
+	RecordsProcessed int `json:"records_processed" gorm:"column:records_processed;default:0"`
+	RecordsImported  int `json:"records_imported" gorm:"column:records_imported;default:0"`
+	RecordsUpdated   int `json:"records_updated" gorm:"column:records_updated;default:0"`
+	RecordsSkipped   int `json:"records_skipped" gorm:"column:records_skipped;default:0"`
+	RecordsFailed    int `json:"records_failed" gorm:"column:records_failed;default:0"`
+	DuplicatesFound  int `json:"duplicates_found" gorm:"column:duplicates_found;default:0"`
+
+	// Configuration
+	ImportOptions   JSONMap   `json:"import_options" gorm:"column:import_options;type:json"`
+	FieldMapping    JSONMap   `json:"field_mapping" gorm:"column:field_mapping;type:json"` // CSV header -> contact field
+	ValidationRules JSONArray `json:"validation_rules" gorm:"column:validation_rules;type:json"`
+
+	// Error Handling
+	ErrorLog      *string             `json:"error_log" gorm:"column:error_log;type:text"`
+	FailedRecords ImportFailedRecords `json:"failed_records,omitempty" gorm:"column:failed_records;type:json"`
+
+	// Audit Information
+	StartedAt   time.Time  `json:"started_at" gorm:"column:started_at;default:CURRENT_TIMESTAMP;index"`
+	CompletedAt *time.Time `json:"completed_at" gorm:"column:completed_at"`
+	HeartbeatAt *time.Time `json:"-" gorm:"column:heartbeat_at"` // Last progress report of a running import
+	ImportedBy  uint       `json:"imported_by" gorm:"column:imported_by;not null;index"`
+}
+
+// TableName specifies the table name for ContactImportBatch
+func (ContactImportBatch) TableName() string {
+	return "contact_import_batches"
+}
+
+// IsFinished checks if the import has stopped running
+func (b *ContactImportBatch) IsFinished() bool {
+	return b.Status == ImportStatusCompleted || b.Status == ImportStatusFailed || b.Status == ImportStatusCancelled
+}
+
+// ImportJobOptions configures a background import
+type ImportJobOptions struct {
+	BatchName      string            `json:"batch_name"`
+	HasHeader      bool              `json:"has_header"`
+	UpdateExisting bool              `json:"update_existing"` // Update contacts whose email already exists instead of skipping them
+	FieldMapping   map[string]string `json:"field_mapping"`   // CSV header -> contact field; derived from headers when empty
+}
+
+// ImportBatchSummary aggregates import jobs for the bulk operation status endpoint
+type ImportBatchSummary struct {
+	TotalImports    int64                `json:"total_imports"`
+	ActiveImports   int64                `json:"active_imports"`
+	RecordsImported int64                `json:"records_imported"`
+	RecentImports   []ContactImportBatch `json:"recent_imports"`
+}
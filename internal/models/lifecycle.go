@@ -338,7 +338,8 @@ type ContactLifecycleResponse struct {
 	CreatedAt           time.Time               `json:"created_at"`
 	UpdatedAt           time.Time               `json:"updated_at"`
 	// Computed fields
-	ScoreGrade          string                  `json:"score_grade"` // A, B, C, D, F
+	ScoreGrade          string                  `json:"score_grade"`    // Grade of the score_thresholds band covering the score
+	ScoreCategory       string                  `json:"score_category"` // hot, warm, cold, frozen
 	QualificationStatus string                  `json:"qualification_status"` // qualified, unqualified, pending
 	NextSuggestedAction string                  `json:"next_suggested_action"`
 }
@@ -350,9 +351,35 @@ type ScoringAnalysisResponse struct {
 	MaxPossibleScore    int                     `json:"max_possible_score"`
 	ScorePercentage     float64                 `json:"score_percentage"`
 	Grade               string                  `json:"grade"`
+	Category            string                  `json:"category"`
 	CategoryBreakdown   map[string]int          `json:"category_breakdown"`
 	AppliedRules        []string                `json:"applied_rules"`
 	ScoringFactors      map[string]interface{}  `json:"scoring_factors"`
 	Recommendations     []string                `json:"recommendations"`
 	LastUpdated         time.Time               `json:"last_updated"`
-}
\ No newline at end of file
+}
+// PlannedTransition is a status change a scheduled rule made, or would make in a dry run
+type PlannedTransition struct {
+	ContactID    uint          `json:"contact_id"`
+	RuleID       uint          `json:"rule_id"`
+	RuleName     string        `json:"rule_name"`
+	FromStatus   ContactStatus `json:"from_status"`
+	ToStatus     ContactStatus `json:"to_status"`
+	DaysInStatus int           `json:"days_in_status"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// TransitionSweepReport summarizes one pass of the scheduled status transition sweeper
+type TransitionSweepReport struct {
+	DryRun          bool                `json:"dry_run"`
+	StartedAt       time.Time           `json:"started_at"`
+	FinishedAt      time.Time           `json:"finished_at"`
+	RulesEvaluated  int                 `json:"rules_evaluated"`
+	ContactsScanned int                 `json:"contacts_scanned"`
+	Matched         int                 `json:"matched"`
+	Applied         int                 `json:"applied"`
+	Skipped         int                 `json:"skipped"` // Changed concurrently, e.g. by another replica
+	Failed          int                 `json:"failed"`
+	Transitions     []PlannedTransition `json:"transitions"`
+	Truncated       bool                `json:"truncated"` // Transitions lists only the first matches
+}
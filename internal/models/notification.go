@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// Notification types
+const (
+	NotificationStatusTransition = "status_transition"
+	NotificationScoreBand        = "score_band" // A contact's lead score moved into another score threshold band
+)
+
+// Notification is an in-app message for an admin user
+type Notification struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"column:user_id;not null;index"`
+	Type       string     `json:"type" gorm:"column:type;size:50;not null"`
+	Title      string     `json:"title" gorm:"column:title;size:255;not null"`
+	Body       string     `json:"body" gorm:"column:body;type:text"`
+	EntityType *string    `json:"entity_type" gorm:"column:entity_type;size:50"` // What the notification is about, e.g. "contact"
+	EntityID   *uint      `json:"entity_id" gorm:"column:entity_id"`
+	Data       JSONMap    `json:"data" gorm:"column:data;type:json"`
+	ReadAt     *time.Time `json:"read_at" gorm:"column:read_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for Notification
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationListResponse is a page of a user's notifications
+type NotificationListResponse struct {
+	Notifications []Notification `json:"notifications"`
+	Total         int64          `json:"total"`
+	Unread        int64          `json:"unread"`
+	Page          int            `json:"page"`
+	Limit         int            `json:"limit"`
+}
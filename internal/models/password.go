@@ -0,0 +1,67 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// PasswordResetToken is a single-use, expiring token that lets a user set a new password. Only the
+// hash of the token is stored; the token itself is only ever sent to the user.
+type PasswordResetToken struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"column:user_id;not null;index"`
+	TokenHash   string     `json:"-" gorm:"column:token_hash;size:64;not null;uniqueIndex"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	UsedAt      *time.Time `json:"used_at" gorm:"column:used_at"`
+	RequestedIP *string    `json:"requested_ip" gorm:"column:requested_ip;size:45"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for PasswordResetToken
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+// NewPasswordResetToken returns a random reset token and the hash it is stored under
+func NewPasswordResetToken() (string, string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", "", fmt.Errorf("failed to generate reset token: %v", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(random)
+	return token, HashPasswordResetToken(token), nil
+}
+
+// HashPasswordResetToken returns the stored hash of a reset token
+func HashPasswordResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// PasswordHistory is a password a user had before, kept so it is not chosen again
+type PasswordHistory struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"column:user_id;not null;index"`
+	PasswordHash string    `json:"-" gorm:"column:password_hash;size:255;not null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for PasswordHistory
+func (PasswordHistory) TableName() string {
+	return "password_history"
+}
+
+// ForgotPasswordRequest asks for a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest sets a new password with a reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPasswordResetToken(t *testing.T) {
+	token, hash, err := NewPasswordResetToken()
+	require.NoError(t, err)
+	assert.Len(t, token, 43)
+	assert.Equal(t, HashPasswordResetToken(token), hash)
+	assert.NotContains(t, hash, token)
+
+	other, _, err := NewPasswordResetToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestAdminUserIsLocked(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	until := now.Add(10 * time.Minute)
+
+	user := &AdminUser{}
+	assert.False(t, user.IsLocked(now))
+
+	user.LockedUntil = &until
+	assert.True(t, user.IsLocked(now))
+	assert.False(t, user.IsLocked(until))
+}
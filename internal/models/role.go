@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Role is a named set of permissions admin users are given through AdminUser.Role
+type Role struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	Name        string           `json:"name" gorm:"size:50;not null;uniqueIndex"`
+	Description *string          `json:"description" gorm:"size:255"`
+	IsSystem    bool             `json:"is_system" gorm:"not null;default:false"` // System roles cannot be changed or deleted
+	Permissions []RolePermission `json:"-" gorm:"foreignKey:RoleID"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// TableName specifies the table name for Role
+func (Role) TableName() string {
+	return "roles"
+}
+
+// PermissionNames returns the role's permissions, sorted
+func (r *Role) PermissionNames() []string {
+	names := make([]string, len(r.Permissions))
+	for i, p := range r.Permissions {
+		names[i] = p.Permission
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RolePermission is a permission a role grants: "resource:action", "resource:*" or "*"
+type RolePermission struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	RoleID     uint      `json:"role_id" gorm:"not null;uniqueIndex:uk_role_permissions"`
+	Permission string    `json:"permission" gorm:"size:100;not null;uniqueIndex:uk_role_permissions"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for RolePermission
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+// Effects of a user permission override
+const (
+	PermissionEffectGrant = "grant"
+	PermissionEffectDeny  = "deny"
+)
+
+// UserPermissionOverride grants a permission to, or denies it from, one user on top of their role.
+// Denials win over any grant.
+type UserPermissionOverride struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;uniqueIndex:uk_user_permission_overrides"`
+	Permission string    `json:"permission" gorm:"size:100;not null;uniqueIndex:uk_user_permission_overrides"`
+	Effect     string    `json:"effect" gorm:"type:enum('grant','deny');not null"`
+	GrantedBy  *uint     `json:"granted_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for UserPermissionOverride
+func (UserPermissionOverride) TableName() string {
+	return "user_permission_overrides"
+}
+
+var (
+	roleNamePattern   = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+	permissionPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*:([a-z][a-z0-9_]*|\*)$`)
+)
+
+// ValidRoleName reports whether name can name a role: lowercase letters, digits and underscores,
+// starting with a letter
+func ValidRoleName(name string) bool {
+	return roleNamePattern.MatchString(name)
+}
+
+// ValidPermission reports whether p is "*", "resource:*" or "resource:action"
+func ValidPermission(p string) bool {
+	return p == "*" || (len(p) <= 100 && permissionPattern.MatchString(p))
+}
+
+// NormalizePermissions checks a list of permissions and returns it sorted and without duplicates
+func NormalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool, len(permissions))
+	normalized := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		if !ValidPermission(p) {
+			return nil, fmt.Errorf("invalid permission: %q", p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			normalized = append(normalized, p)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// PermissionResolver resolves the effective permissions of admin users
+type PermissionResolver interface {
+	HasPermission(userID uint, permission string) bool
+}
+
+var permissionResolver PermissionResolver
+
+// SetPermissionResolver installs the resolver AdminUser.CanAccessResource consults. It is set once
+// at startup.
+func SetPermissionResolver(resolver PermissionResolver) {
+	permissionResolver = resolver
+}
+
+// RoleRequest creates a role
+type RoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description *string  `json:"description,omitempty" binding:"omitempty,max=255"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// UpdateRoleRequest changes a role's description or replaces its permissions
+type UpdateRoleRequest struct {
+	Description *string  `json:"description,omitempty" binding:"omitempty,max=255"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// RoleResponse is a role with its permissions and how many users have it
+type RoleResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	IsSystem    bool      `json:"is_system"`
+	Permissions []string  `json:"permissions"`
+	UserCount   int64     `json:"user_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UserPermissionOverrideRequest grants a permission to a user or denies it from them
+type UserPermissionOverrideRequest struct {
+	Permission string `json:"permission" binding:"required,max=100"`
+	Effect     string `json:"effect" binding:"required,oneof=grant deny"`
+}
+
+// UserPermissionsResponse describes where a user's permissions come from
+type UserPermissionsResponse struct {
+	UserID          uint                     `json:"user_id"`
+	Role            string                   `json:"role"`
+	RolePermissions []string                 `json:"role_permissions"`
+	Overrides       []UserPermissionOverride `json:"overrides"`
+}
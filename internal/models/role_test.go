@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidRoleName(t *testing.T) {
+	assert.True(t, ValidRoleName("sales_rep"))
+	assert.True(t, ValidRoleName("tier2"))
+	assert.False(t, ValidRoleName("a"))
+	assert.False(t, ValidRoleName("Sales"))
+	assert.False(t, ValidRoleName("2nd_line"))
+	assert.False(t, ValidRoleName("sales rep"))
+}
+
+func TestNormalizePermissions(t *testing.T) {
+	permissions, err := NormalizePermissions([]string{"contacts:read", "bulk:*", "contacts:read", "*"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*", "bulk:*", "contacts:read"}, permissions)
+
+	for _, invalid := range []string{"contacts", "contacts:", ":read", "Contacts:read", "contacts:read:own", "*:read"} {
+		_, err := NormalizePermissions([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+}
+
+type fakePermissionResolver map[string]bool
+
+func (f fakePermissionResolver) HasPermission(userID uint, permission string) bool {
+	return f[permission]
+}
+
+func TestAdminUserCanAccessResource(t *testing.T) {
+	user := &AdminUser{ID: 4, Role: "sales_rep"}
+
+	SetPermissionResolver(nil)
+	assert.False(t, user.CanAccessResource("contacts", "read"))
+
+	SetPermissionResolver(fakePermissionResolver{"contacts:read": true})
+	defer SetPermissionResolver(nil)
+	assert.True(t, user.CanAccessResource("contacts", "read"))
+	assert.False(t, user.CanAccessResource("contacts", "delete"))
+	assert.False(t, (&AdminUser{Role: "admin"}).CanAccessResource("contacts", "read"))
+}
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ContactScore is one lead score calculation for a contact; the newest row is the current score
+type ContactScore struct {
+	ID            uint `json:"id" gorm:"primaryKey"`
+	ContactID     uint `json:"contact_id" gorm:"column:contact_id;not null;index"`
+	CurrentScore  int  `json:"current_score" gorm:"column:current_score"`
+	PreviousScore int  `json:"previous_score" gorm:"column:previous_score"`
+	ScoreChange   int  `json:"score_change" gorm:"column:score_change"`
+
+	// Score Components
+	DemographicScore  int `json:"demographic_score" gorm:"column:demographic_score"`
+	BehavioralScore   int `json:"behavioral_score" gorm:"column:behavioral_score"`
+	EngagementScore   int `json:"engagement_score" gorm:"column:engagement_score"`
+	FirmographicScore int `json:"firmographic_score" gorm:"column:firmographic_score"`
+	LifecycleScore    int `json:"lifecycle_score" gorm:"column:lifecycle_score"`
+
+	// Band from score_thresholds; nil when no active band covers the score
+	ScoreGrade    *string `json:"score_grade" gorm:"column:score_grade"`
+	ScoreCategory *string `json:"score_category" gorm:"column:score_category"`
+
+	CalculationDate     time.Time `json:"calculation_date" gorm:"column:calculation_date"`
+	RulesApplied        JSONArray `json:"rules_applied" gorm:"column:rules_applied;type:json"`
+	CalculationMetadata JSONMap   `json:"calculation_metadata" gorm:"column:calculation_metadata;type:json"`
+
+	// Decay of stale engagement
+	DecayApplied     bool       `json:"decay_applied" gorm:"column:decay_applied"`
+	DecayAmount      int        `json:"decay_amount" gorm:"column:decay_amount"`
+	LastActivityDate *time.Time `json:"last_activity_date" gorm:"column:last_activity_date"` // Last engagement the decay was measured from
+
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for ContactScore
+func (ContactScore) TableName() string {
+	return "contact_scores"
+}
+
+// ScoreCalculationLog records one rule's contribution to a score calculation. Entries of one
+// calculation share a CalculationBatchID; PreviousScore and NewScore are the running total.
+type ScoreCalculationLog struct {
+	ID                  uint      `json:"id" gorm:"primaryKey"`
+	ContactID           uint      `json:"contact_id" gorm:"column:contact_id;not null;index"`
+	RuleID              *uint     `json:"rule_id" gorm:"column:rule_id;index"` // Nil for built-in signals
+	RuleName            string    `json:"rule_name" gorm:"column:rule_name;size:255"`
+	FieldEvaluated      string    `json:"field_evaluated" gorm:"column:field_evaluated;size:100"`
+	FieldValue          *string   `json:"field_value" gorm:"column:field_value;type:text"`
+	ScoreChange         int       `json:"score_change" gorm:"column:score_change;not null"`
+	PreviousScore       int       `json:"previous_score" gorm:"column:previous_score"`
+	NewScore            int       `json:"new_score" gorm:"column:new_score"`
+	CalculationBatchID  string    `json:"calculation_batch_id" gorm:"column:calculation_batch_id;size:100;index"`
+	ExecutionTimeMs     int       `json:"execution_time_ms" gorm:"column:execution_time_ms"`
+	CalculationMetadata JSONMap   `json:"calculation_metadata" gorm:"column:calculation_metadata;type:json"`
+	CreatedAt           time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for ScoreCalculationLog
+func (ScoreCalculationLog) TableName() string {
+	return "score_calculation_log"
+}
+
+// ScoreThreshold is a score band with its grade and the automation that runs when a contact enters
+// it; see ScoreBandAssignment and ScoreBandNotification for the shape of the rules
+type ScoreThreshold struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	Name              string    `json:"name" gorm:"column:name;size:100;not null"`
+	MinScore          int       `json:"min_score" gorm:"column:min_score;not null"`
+	MaxScore          int       `json:"max_score" gorm:"column:max_score;not null"`
+	Grade             string    `json:"grade" gorm:"column:grade;not null"`
+	Category          string    `json:"category" gorm:"column:category;not null"` // hot, warm, cold, frozen
+	Color             string    `json:"color" gorm:"column:color;size:7"`
+	AutoAssignRules   JSONMap   `json:"auto_assign_rules" gorm:"column:auto_assign_rules;type:json"`
+	NotificationRules JSONMap   `json:"notification_rules" gorm:"column:notification_rules;type:json"`
+	FollowUpRules     JSONMap   `json:"follow_up_rules" gorm:"column:follow_up_rules;type:json"`
+	Description       *string   `json:"description" gorm:"column:description;type:text"`
+	IsActive          bool      `json:"is_active" gorm:"column:is_active;default:true"`
+	CreatedAt         time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for ScoreThreshold
+func (ScoreThreshold) TableName() string {
+	return "score_thresholds"
+}
+
+// Contains reports whether the score falls in the band
+func (t *ScoreThreshold) Contains(score int) bool {
+	return score >= t.MinScore && score <= t.MaxScore
+}
+
+// ScoreBandAssignment is a band's auto_assign_rules: assign the contact to UserID, through the
+// assignee selection of assignment rule AssignmentRuleID, or with the assignment rules engine
+// (Automatic). Contacts that already have an assignee keep it unless Reassign is set.
+type ScoreBandAssignment struct {
+	UserID           uint `json:"user_id,omitempty"`
+	AssignmentRuleID uint `json:"assignment_rule_id,omitempty"`
+	Automatic        bool `json:"automatic,omitempty"`
+	Reassign         bool `json:"reassign,omitempty"`
+	UpgradesOnly     bool `json:"upgrades_only,omitempty"` // Only when the contact came from a lower band
+}
+
+// ScoreBandNotification is a band's notification_rules: notify Users and, with Assignee, the
+// contact's assignee
+type ScoreBandNotification struct {
+	Users        []uint `json:"users,omitempty"`
+	Assignee     bool   `json:"assignee,omitempty"`
+	UpgradesOnly bool   `json:"upgrades_only,omitempty"` // Only when the contact came from a lower band
+}
+
+// Automation reads the band's assignment and notification rules; either is nil when not configured
+func (t *ScoreThreshold) Automation() (*ScoreBandAssignment, *ScoreBandNotification, error) {
+	var assignment *ScoreBandAssignment
+	if len(t.AutoAssignRules) > 0 {
+		assignment = &ScoreBandAssignment{}
+		if err := decodeJSONMap(t.AutoAssignRules, assignment); err != nil {
+			return nil, nil, fmt.Errorf("invalid auto_assign_rules: %v", err)
+		}
+		if assignment.UserID == 0 && assignment.AssignmentRuleID == 0 && !assignment.Automatic {
+			return nil, nil, fmt.Errorf("invalid auto_assign_rules: set user_id, assignment_rule_id or automatic")
+		}
+	}
+
+	var notification *ScoreBandNotification
+	if len(t.NotificationRules) > 0 {
+		notification = &ScoreBandNotification{}
+		if err := decodeJSONMap(t.NotificationRules, notification); err != nil {
+			return nil, nil, fmt.Errorf("invalid notification_rules: %v", err)
+		}
+	}
+	return assignment, notification, nil
+}
+
+func decodeJSONMap(source JSONMap, target interface{}) error {
+	data, err := json.Marshal(source)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// MatchScoreThreshold returns the band containing the score, preferring the higher band where
+// bands overlap, or nil when no band covers it
+func MatchScoreThreshold(thresholds []ScoreThreshold, score int) *ScoreThreshold {
+	var match *ScoreThreshold
+	for i := range thresholds {
+		if thresholds[i].Contains(score) && (match == nil || thresholds[i].MinScore > match.MinScore) {
+			match = &thresholds[i]
+		}
+	}
+	return match
+}
+
+// ScoreDecayPolicy discounts stale engagement. Positive points from the decaying categories keep
+// their full value for GraceDays after the contact last engaged, then halve every HalfLifeDays.
+// A zero HalfLifeDays disables decay.
+type ScoreDecayPolicy struct {
+	GraceDays    int
+	HalfLifeDays int
+	Categories   []string
+}
+
+// Applies reports whether points in the category decay
+func (p ScoreDecayPolicy) Applies(category string) bool {
+	for _, c := range p.Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Factor is the share of decaying points kept for a contact who last engaged at lastEngagement
+func (p ScoreDecayPolicy) Factor(lastEngagement, now time.Time) float64 {
+	if p.HalfLifeDays <= 0 {
+		return 1
+	}
+	idleDays := now.Sub(lastEngagement).Hours()/24 - float64(p.GraceDays)
+	if idleDays <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, idleDays/float64(p.HalfLifeDays))
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchScoreThreshold(t *testing.T) {
+	thresholds := []ScoreThreshold{
+		{ID: 1, MinScore: 0, MaxScore: 19, Grade: "F"},
+		{ID: 2, MinScore: 20, MaxScore: 59, Grade: "C"},
+		{ID: 3, MinScore: 50, MaxScore: 100, Grade: "A"},
+	}
+
+	assert.Equal(t, uint(1), MatchScoreThreshold(thresholds, 0).ID)
+	assert.Equal(t, uint(2), MatchScoreThreshold(thresholds, 20).ID)
+	assert.Equal(t, uint(3), MatchScoreThreshold(thresholds, 55).ID, "overlap prefers the higher band")
+	assert.Nil(t, MatchScoreThreshold(thresholds, 101))
+	assert.Nil(t, MatchScoreThreshold(nil, 50))
+}
+
+func TestScoreThresholdAutomation(t *testing.T) {
+	threshold := ScoreThreshold{
+		AutoAssignRules:   decodeActions(t, `{"assignment_rule_id": 3, "reassign": true}`),
+		NotificationRules: decodeActions(t, `{"users": [4, 9], "assignee": true, "upgrades_only": true}`),
+	}
+	assignment, notification, err := threshold.Automation()
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), assignment.AssignmentRuleID)
+	assert.True(t, assignment.Reassign)
+	assert.Equal(t, []uint{4, 9}, notification.Users)
+	assert.True(t, notification.Assignee)
+	assert.True(t, notification.UpgradesOnly)
+
+	assignment, notification, err = (&ScoreThreshold{}).Automation()
+	assert.NoError(t, err)
+	assert.Nil(t, assignment)
+	assert.Nil(t, notification)
+
+	_, _, err = (&ScoreThreshold{AutoAssignRules: decodeActions(t, `{"reassign": true}`)}).Automation()
+	assert.Error(t, err)
+	_, _, err = (&ScoreThreshold{NotificationRules: decodeActions(t, `{"users": ["ops"]}`)}).Automation()
+	assert.Error(t, err)
+}
+
+func TestScoreDecayPolicy(t *testing.T) {
+	policy := ScoreDecayPolicy{GraceDays: 10, HalfLifeDays: 20, Categories: []string{"engagement"}}
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, policy.Applies("engagement"))
+	assert.False(t, policy.Applies("demographic"))
+
+	assert.Equal(t, 1.0, policy.Factor(now.AddDate(0, 0, -5), now))
+	assert.Equal(t, 1.0, policy.Factor(now.AddDate(0, 0, -10), now))
+	assert.InDelta(t, 0.5, policy.Factor(now.AddDate(0, 0, -30), now), 1e-9)
+	assert.InDelta(t, 0.25, policy.Factor(now.AddDate(0, 0, -50), now), 1e-9)
+
+	policy.HalfLifeDays = 0
+	assert.Equal(t, 1.0, policy.Factor(now.AddDate(-1, 0, 0), now))
+}
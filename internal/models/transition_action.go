@@ -0,0 +1,186 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// TransitionActionType is an action a status transition rule performs when it fires
+type TransitionActionType string
+
+const (
+	TransitionActionAssignUser          TransitionActionType = "assign_user"          // Assign the contact to UserID
+	TransitionActionAssignRule          TransitionActionType = "assign_rule"          // Assign the contact using assignment rule RuleID's assignee selection
+	TransitionActionAddTag              TransitionActionType = "add_tag"              // Tag the contact with the existing tag named Tag
+	TransitionActionRemoveTag           TransitionActionType = "remove_tag"           // Remove the tag named Tag
+	TransitionActionCreateActivity      TransitionActionType = "create_activity"      // Create a pending follow-up activity due in DueInDays
+	TransitionActionSetPriority         TransitionActionType = "set_priority"         // Set the contact's priority
+	TransitionActionScheduleAppointment TransitionActionType = "schedule_appointment" // Book an appointment DueInDays from now at Time
+	TransitionActionWebhook             TransitionActionType = "webhook"              // POST the transition to URL once it has committed
+)
+
+// Outcomes recorded for each transition action
+const (
+	TransitionActionSucceeded = "succeeded"
+	TransitionActionSkipped   = "skipped" // Nothing to do, e.g. the tag was already present
+	TransitionActionFailed    = "failed"
+	TransitionActionPending   = "pending" // Webhook not delivered yet
+)
+
+// TransitionAction is one step of a StatusTransitionRule's Actions, which are stored as
+// {"steps": [...]}. Steps run in order inside the status change's transaction; a failing step
+// rolls the whole transition back unless it sets ContinueOnError.
+type TransitionAction struct {
+	Type            TransitionActionType `json:"type"`
+	ContinueOnError bool                 `json:"continue_on_error,omitempty"`
+
+	UserID uint   `json:"user_id,omitempty"` // assign_user; owner of the activity or appointment (default: the contact's assignee)
+	RuleID uint   `json:"rule_id,omitempty"` // assign_rule
+	Tag    string `json:"tag,omitempty"`     // add_tag, remove_tag
+
+	// create_activity and schedule_appointment
+	Title        string          `json:"title,omitempty"`
+	Description  string          `json:"description,omitempty"`
+	ActivityType ActivityType    `json:"activity_type,omitempty"` // Default follow_up
+	Priority     ContactPriority `json:"priority,omitempty"`      // Also the value for set_priority
+	DueInDays    int             `json:"due_in_days,omitempty"`   // Appointments default to 1
+
+	// schedule_appointment
+	Time            string          `json:"time,omitempty"`     // HH:MM, default 10:00
+	Timezone        string          `json:"timezone,omitempty"` // Default UTC
+	DurationMinutes int             `json:"duration_minutes,omitempty"`
+	AppointmentType AppointmentType `json:"appointment_type,omitempty"`
+
+	// webhook
+	URL    string `json:"url,omitempty"`
+	Secret string `json:"secret,omitempty"` // Signs the body as X-Webhook-Signature: sha256=<hex HMAC>
+}
+
+// TransitionActionResult is the recorded outcome of one action, kept in the LifecycleEvent's trigger data
+type TransitionActionResult struct {
+	Type   TransitionActionType `json:"type"`
+	Status string               `json:"status"`
+	Detail string               `json:"detail,omitempty"`
+	Error  string               `json:"error,omitempty"`
+}
+
+// ParseTransitionActions reads and validates the steps of a rule's Actions
+func ParseTransitionActions(actions JSONMap) ([]TransitionAction, error) {
+	if len(actions) == 0 {
+		return nil, nil
+	}
+	for key := range actions {
+		if key != "steps" {
+			return nil, fmt.Errorf("invalid actions: unknown key %q, expected {\"steps\": [...]}", key)
+		}
+	}
+
+	data, err := json.Marshal(actions["steps"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid actions: %v", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var steps []TransitionAction
+	if err := decoder.Decode(&steps); err != nil {
+		return nil, fmt.Errorf("invalid actions: %v", err)
+	}
+
+	for i := range steps {
+		if err := steps[i].Validate(); err != nil {
+			return nil, fmt.Errorf("invalid action %d: %v", i+1, err)
+		}
+	}
+	return steps, nil
+}
+
+// Validate checks that the action has the settings its type needs
+func (a *TransitionAction) Validate() error {
+	if a.Priority != "" && !isContactPriority(a.Priority) {
+		return fmt.Errorf("unknown priority %q", a.Priority)
+	}
+	if a.DueInDays < 0 {
+		return fmt.Errorf("due_in_days cannot be negative")
+	}
+
+	switch a.Type {
+	case TransitionActionAssignUser:
+		if a.UserID == 0 {
+			return fmt.Errorf("assign_user requires user_id")
+		}
+	case TransitionActionAssignRule:
+		if a.RuleID == 0 {
+			return fmt.Errorf("assign_rule requires rule_id")
+		}
+	case TransitionActionAddTag, TransitionActionRemoveTag:
+		if a.Tag == "" {
+			return fmt.Errorf("%s requires tag", a.Type)
+		}
+	case TransitionActionCreateActivity:
+		if a.Title == "" {
+			return fmt.Errorf("create_activity requires title")
+		}
+	case TransitionActionSetPriority:
+		if a.Priority == "" {
+			return fmt.Errorf("set_priority requires priority")
+		}
+	case TransitionActionScheduleAppointment:
+		if a.Title == "" {
+			return fmt.Errorf("schedule_appointment requires title")
+		}
+		if a.Time != "" {
+			if _, err := time.Parse("15:04", a.Time); err != nil {
+				return fmt.Errorf("time must be HH:MM")
+			}
+		}
+		if a.Timezone != "" {
+			if _, err := time.LoadLocation(a.Timezone); err != nil {
+				return fmt.Errorf("unknown timezone %q", a.Timezone)
+			}
+		}
+		if a.DurationMinutes != 0 && (a.DurationMinutes < 15 || a.DurationMinutes > 480) {
+			return fmt.Errorf("duration_minutes must be between 15 and 480")
+		}
+	case TransitionActionWebhook:
+		target, err := url.Parse(a.URL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("webhook requires an http(s) url")
+		}
+	default:
+		return fmt.Errorf("unknown action type %q", a.Type)
+	}
+	return nil
+}
+
+// ParseNotifyUsers reads a rule's NotifyUsers as admin user IDs
+func ParseNotifyUsers(users JSONArray) ([]uint, error) {
+	ids := make([]uint, 0, len(users))
+	for _, value := range users {
+		number, ok := value.(float64)
+		if !ok || number < 1 || number != float64(uint(number)) {
+			return nil, fmt.Errorf("invalid notify_users: %v is not a user ID", value)
+		}
+		ids = append(ids, uint(number))
+	}
+	return ids, nil
+}
+
+func isContactPriority(priority ContactPriority) bool {
+	switch priority {
+	case PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// ValidateActions checks the request's actions and notify_users
+func (r *StatusTransitionRuleRequest) ValidateActions() error {
+	if _, err := ParseTransitionActions(r.Actions); err != nil {
+		return err
+	}
+	_, err := ParseNotifyUsers(r.NotifyUsers)
+	return err
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeActions(t *testing.T, data string) JSONMap {
+	var actions JSONMap
+	require.NoError(t, json.Unmarshal([]byte(data), &actions))
+	return actions
+}
+
+func TestParseTransitionActions(t *testing.T) {
+	steps, err := ParseTransitionActions(decodeActions(t, `{"steps": [
+		{"type": "assign_user", "user_id": 7},
+		{"type": "add_tag", "tag": "Hot Lead"},
+		{"type": "create_activity", "title": "Call back", "due_in_days": 2, "priority": "high"},
+		{"type": "schedule_appointment", "title": "Demo", "time": "14:30", "timezone": "Europe/Berlin", "duration_minutes": 45},
+		{"type": "webhook", "url": "https://hooks.example.com/crm", "secret": "s3cret", "continue_on_error": true}
+	]}`))
+	require.NoError(t, err)
+	require.Len(t, steps, 5)
+
+	assert.Equal(t, TransitionActionAssignUser, steps[0].Type)
+	assert.Equal(t, uint(7), steps[0].UserID)
+	assert.Equal(t, "Hot Lead", steps[1].Tag)
+	assert.Equal(t, 2, steps[2].DueInDays)
+	assert.Equal(t, PriorityHigh, steps[2].Priority)
+	assert.Equal(t, "14:30", steps[3].Time)
+	assert.True(t, steps[4].ContinueOnError)
+
+	steps, err = ParseTransitionActions(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, steps)
+}
+
+func TestParseTransitionActionsRejectsInvalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"legacy shape":      `{"add_tag": "Hot Lead"}`,
+		"unknown type":      `{"steps": [{"type": "send_fax"}]}`,
+		"unknown field":     `{"steps": [{"type": "add_tag", "tag": "x", "colour": "red"}]}`,
+		"missing user":      `{"steps": [{"type": "assign_user"}]}`,
+		"missing tag":       `{"steps": [{"type": "remove_tag"}]}`,
+		"bad priority":      `{"steps": [{"type": "set_priority", "priority": "asap"}]}`,
+		"bad time":          `{"steps": [{"type": "schedule_appointment", "title": "Demo", "time": "2pm"}]}`,
+		"bad timezone":      `{"steps": [{"type": "schedule_appointment", "title": "Demo", "timezone": "Mars/Olympus"}]}`,
+		"short appointment": `{"steps": [{"type": "schedule_appointment", "title": "Demo", "duration_minutes": 5}]}`,
+		"negative due":      `{"steps": [{"type": "create_activity", "title": "Call", "due_in_days": -1}]}`,
+		"webhook scheme":    `{"steps": [{"type": "webhook", "url": "ftp://example.com/hook"}]}`,
+	} {
+		_, err := ParseTransitionActions(decodeActions(t, data))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParseNotifyUsers(t *testing.T) {
+	ids, err := ParseNotifyUsers(JSONArray{float64(3), float64(12)})
+	require.NoError(t, err)
+	assert.Equal(t, []uint{3, 12}, ids)
+
+	for _, users := range []JSONArray{{"3"}, {float64(0)}, {float64(1.5)}, {float64(-2)}} {
+		_, err := ParseNotifyUsers(users)
+		assert.Error(t, err)
+	}
+}
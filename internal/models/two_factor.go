@@ -0,0 +1,103 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// recoveryCodeAlphabet leaves out characters that are easily confused when copied by hand
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// UserRecoveryCode is a one-time code that stands in for a TOTP code when the user has lost their
+// authenticator. Only the hash of the code is stored.
+type UserRecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"column:user_id;not null;index"`
+	CodeHash  string     `json:"-" gorm:"column:code_hash;size:64;not null"`
+	UsedAt    *time.Time `json:"used_at" gorm:"column:used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for UserRecoveryCode
+func (UserRecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
+
+// NewRecoveryCode returns a random recovery code formatted as xxxxx-xxxxx
+func NewRecoveryCode() (string, error) {
+	random := make([]byte, 10)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %v", err)
+	}
+
+	code := make([]byte, 0, 11)
+	for i, b := range random {
+		if i == 5 {
+			code = append(code, '-')
+		}
+		code = append(code, recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+	}
+	return string(code), nil
+}
+
+// HashRecoveryCode returns the stored hash of a recovery code. Case, spaces and dashes are ignored
+// so that codes typed back by hand still match.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(code)
+	normalized = strings.NewReplacer("-", "", " ", "").Replace(normalized)
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// TwoFactorPolicy requires the users of a role to sign in with a two-factor code before they can use
+// admin and manager endpoints
+type TwoFactorPolicy struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Role      string    `json:"role" gorm:"column:role;size:50;not null;uniqueIndex"`
+	Required  bool      `json:"required" gorm:"column:required;not null"`
+	UpdatedBy *uint     `json:"updated_by" gorm:"column:updated_by"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for TwoFactorPolicy
+func (TwoFactorPolicy) TableName() string {
+	return "two_factor_policies"
+}
+
+// TwoFactorPolicyRequest sets whether a role requires two-factor authentication
+type TwoFactorPolicyRequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
+
+// TwoFactorEnrollment is what an authenticator app needs to enroll a new secret. Enrollment completes
+// once a code generated from it is confirmed.
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	Issuer     string `json:"issuer"`
+	Account    string `json:"account"`
+}
+
+// TwoFactorStatus describes a user's two-factor setup
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Pending                bool       `json:"pending"` // Enrolled but not yet confirmed
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+	RequiredByPolicy       bool       `json:"required_by_policy"`
+}
+
+// TwoFactorCodeRequest carries a TOTP code or a recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// RecoveryCodesResponse returns newly generated recovery codes; they are not shown again
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecoveryCode(t *testing.T) {
+	format := regexp.MustCompile(`^[a-z2-9]{5}-[a-z2-9]{5}$`)
+
+	seen := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		code, err := NewRecoveryCode()
+		require.NoError(t, err)
+		assert.Regexp(t, format, code)
+		assert.NotContains(t, code, "l")
+		assert.NotContains(t, code, "o")
+		seen[code] = true
+	}
+	assert.Len(t, seen, 20)
+}
+
+func TestHashRecoveryCodeIgnoresFormatting(t *testing.T) {
+	hash := HashRecoveryCode("abcde-fghjk")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, HashRecoveryCode("ABCDE FGHJK"))
+	assert.Equal(t, hash, HashRecoveryCode("abcdefghjk"))
+	assert.NotEqual(t, hash, HashRecoveryCode("abcde-fghjm"))
+}
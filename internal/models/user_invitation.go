@@ -0,0 +1,88 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// UserInvitation lets an invited user activate their account by choosing a password. Only the hash
+// of the activation token is stored; the token itself is only ever emailed to the user.
+type UserInvitation struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"column:user_id;not null;index"`
+	TokenHash  string     `json:"-" gorm:"column:token_hash;size:64;not null;uniqueIndex"`
+	InvitedBy  *uint      `json:"invited_by" gorm:"column:invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	AcceptedAt *time.Time `json:"accepted_at" gorm:"column:accepted_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for UserInvitation
+func (UserInvitation) TableName() string {
+	return "user_invitations"
+}
+
+// NewInvitationToken returns a random activation token and the hash it is stored under
+func NewInvitationToken() (string, string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", "", fmt.Errorf("failed to generate activation token: %v", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(random)
+	return token, HashInvitationToken(token), nil
+}
+
+// HashInvitationToken returns the stored hash of an activation token
+func HashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// InviteUserRequest invites a new admin user, who activates the account from the emailed link
+type InviteUserRequest struct {
+	Email      string  `json:"email" binding:"required,email"`
+	Name       string  `json:"name" binding:"required,max=100"`
+	Role       string  `json:"role" binding:"required,max=50"`
+	Phone      *string `json:"phone,omitempty"`
+	JobTitle   *string `json:"job_title,omitempty"`
+	Department *string `json:"department,omitempty"`
+	Location   *string `json:"location,omitempty"`
+}
+
+// UpdateUserRoleRequest changes an admin user's role
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,max=50"`
+}
+
+// DeactivateUserRequest deactivates an admin user. Their open contacts go to ReassignTo, or are
+// unassigned when it is not set.
+type DeactivateUserRequest struct {
+	ReassignTo *uint  `json:"reassign_to,omitempty"`
+	Reason     string `json:"reason,omitempty" binding:"max=500"`
+}
+
+// ActivateAccountRequest activates an invited account with its activation token
+type ActivateAccountRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// UserInvitationResponse is an invited user, when their activation link expires and whether it was
+// emailed to them
+type UserInvitationResponse struct {
+	User      *AdminUserResponse `json:"user"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	EmailSent bool               `json:"email_sent"`
+}
+
+// DeactivateUserResponse reports what happened to a deactivated user's open contacts
+type DeactivateUserResponse struct {
+	User               *AdminUserResponse `json:"user"`
+	ReassignedContacts int                `json:"reassigned_contacts"`
+	UnassignedContacts int                `json:"unassigned_contacts"`
+	RevokedSessions    int64              `json:"revoked_sessions"`
+}
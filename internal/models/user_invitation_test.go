@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInvitationToken(t *testing.T) {
+	token, hash, err := NewInvitationToken()
+	require.NoError(t, err)
+	assert.Len(t, token, 43)
+	assert.Equal(t, HashInvitationToken(token), hash)
+	assert.NotContains(t, hash, token)
+
+	other, _, err := NewInvitationToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestAdminUserIsInvitationPending(t *testing.T) {
+	invited := &AdminUser{ID: 3, Email: "new@example.com"}
+	assert.True(t, invited.IsInvitationPending())
+	assert.True(t, invited.ToResponse().InvitationPending)
+
+	activated := &AdminUser{ID: 4, PasswordHash: "$2a$10$hash", IsActive: true}
+	assert.False(t, activated.IsInvitationPending())
+
+	deactivatedAt := time.Now()
+	deactivated := &AdminUser{ID: 5, DeactivatedAt: &deactivatedAt}
+	assert.False(t, deactivated.IsInvitationPending())
+	assert.Equal(t, &deactivatedAt, deactivated.ToResponse().DeactivatedAt)
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+)
+
+// ValidationRuleType represents how a validation rule checks a field
+type ValidationRuleType string
+
+const (
+	RuleTypeRequired   ValidationRuleType = "required"   // Field must be set and non-blank
+	RuleTypeFormat     ValidationRuleType = "format"     // Field must match validation_pattern as a regex
+	RuleTypeLength     ValidationRuleType = "length"     // Character count within "min,max"
+	RuleTypeRange      ValidationRuleType = "range"      // Numeric value within "min,max"
+	RuleTypeCustom     ValidationRuleType = "custom"     // validation_pattern names a built-in check
+	RuleTypeUniqueness ValidationRuleType = "uniqueness" // No other active contact has the same value
+)
+
+// ValidationSeverity represents how a rule violation is treated
+type ValidationSeverity string
+
+const (
+	ValidationSeverityError   ValidationSeverity = "error"   // Blocks the write
+	ValidationSeverityWarning ValidationSeverity = "warning" // Reported but the write goes ahead
+	ValidationSeverityInfo    ValidationSeverity = "info"    // Reported but the write goes ahead
+)
+
+// ValidationChannel identifies the path a contact write came in through
+type ValidationChannel string
+
+const (
+	ValidationChannelAPI    ValidationChannel = "api"    // Authenticated REST API
+	ValidationChannelImport ValidationChannel = "import" // CSV and other bulk imports
+	ValidationChannelManual ValidationChannel = "manual" // Hand-entered data such as the public contact form
+)
+
+// ContactValidationRule represents a configurable data quality rule for contact fields
+type ContactValidationRule struct {
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	Name        string  `json:"name" gorm:"column:name;size:255;not null"`
+	Description *string `json:"description" gorm:"column:description;type:text"`
+	FieldName   string  `json:"field_name" gorm:"column:field_name;size:100;not null;index"`
+
+	// Validation Configuration
+	RuleType          ValidationRuleType `json:"rule_type" gorm:"column:rule_type;not null;index"`
+	ValidationPattern *string            `json:"validation_pattern" gorm:"column:validation_pattern;size:500"`
+	ErrorMessage      *string            `json:"error_message" gorm:"column:error_message;size:255"`
+	Severity          ValidationSeverity `json:"severity" gorm:"column:severity;default:error"`
+
+	// Rule Execution
+	IsActive         bool `json:"is_active" gorm:"column:is_active;default:true;index"`
+	ExecutionOrder   int  `json:"execution_order" gorm:"column:execution_order;default:0;index"`
+	AppliesToImports bool `json:"applies_to_imports" gorm:"column:applies_to_imports;default:true"`
+	AppliesToAPI     bool `json:"applies_to_api" gorm:"column:applies_to_api;default:true"`
+	AppliesToManual  bool `json:"applies_to_manual" gorm:"column:applies_to_manual;default:true"`
+
+	// Usage Tracking
+	UsageCount int        `json:"usage_count" gorm:"column:usage_count;default:0"`
+	LastUsedAt *time.Time `json:"last_used_at" gorm:"column:last_used_at"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+	CreatedBy *uint     `json:"created_by" gorm:"column:created_by"`
+}
+
+// TableName specifies the table name for ContactValidationRule
+func (ContactValidationRule) TableName() string {
+	return "contact_validation_rules"
+}
+
+// AppliesTo reports whether the rule runs for the given channel
+func (r *ContactValidationRule) AppliesTo(channel ValidationChannel) bool {
+	switch channel {
+	case ValidationChannelAPI:
+		return r.AppliesToAPI
+	case ValidationChannelImport:
+		return r.AppliesToImports
+	case ValidationChannelManual:
+		return r.AppliesToManual
+	}
+	return false
+}
+
+// IsContactField reports whether name is a contact column that rules can validate
+func IsContactField(name string) bool {
+	_, ok := trackedContactFields[name]
+	return ok
+}
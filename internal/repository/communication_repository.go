@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"contact-service/internal/models"
+)
+
+// communicationRepository implements CommunicationRepository interface
+type communicationRepository struct {
+	db *gorm.DB
+}
+
+// NewCommunicationRepository creates a new communication repository
+func NewCommunicationRepository(db *gorm.DB) CommunicationRepository {
+	return &communicationRepository{db: db}
+}
+
+// CreateWithActivity creates a communication and the contact activity that records it in one transaction
+func (r *communicationRepository) CreateWithActivity(communication *models.ContactCommunication, activity *models.ContactActivity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(communication).Error; err != nil {
+			return err
+		}
+
+		entityType := models.ActivityEntityCommunication
+		activity.ContactID = communication.ContactID
+		activity.RelatedEntityType = &entityType
+		activity.RelatedEntityID = &communication.ID
+		return tx.Create(activity).Error
+	})
+}
+
+// GetByID retrieves a communication by ID
+func (r *communicationRepository) GetByID(id uint) (*models.ContactCommunication, error) {
+	var communication models.ContactCommunication
+	if err := r.db.Where("deleted_at IS NULL").First(&communication, id).Error; err != nil {
+		return nil, err
+	}
+	return &communication, nil
+}
+
+// ClaimNextQueued marks the oldest queued communication that is due as sending and returns it.
+// It returns nil when nothing is due or another worker claimed the communication first.
+func (r *communicationRepository) ClaimNextQueued(now time.Time) (*models.ContactCommunication, error) {
+	var communication models.ContactCommunication
+	err := r.db.Where("status = ? AND deleted_at IS NULL", models.CommunicationStatusQueued).
+		Where("next_attempt_at IS NULL OR next_attempt_at <= ?", now).
+		Order("created_at ASC, id ASC").
+		First(&communication).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	result := r.db.Model(&models.ContactCommunication{}).
+		Where("id = ? AND status = ?", communication.ID, models.CommunicationStatusQueued).
+		Updates(map[string]interface{}{
+			"status":     models.CommunicationStatusSending,
+			"claimed_at": now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	communication.Status = models.CommunicationStatusSending
+	communication.ClaimedAt = &now
+	return &communication, nil
+}
+
+// Update applies updates to a communication
+func (r *communicationRepository) Update(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.ContactCommunication{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// UpdateActivity applies updates to the contact activity recorded for a communication
+func (r *communicationRepository) UpdateActivity(communicationID uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.ContactActivity{}).
+		Where("related_entity_type = ? AND related_entity_id = ?", models.ActivityEntityCommunication, communicationID).
+		Updates(updates).Error
+}
+
+// RequeueSending returns communications claimed before claimedBefore, and so abandoned by a stopped
+// worker, to the queue. Sends claimed before claim times were recorded have no claimed_at and count
+// as abandoned.
+func (r *communicationRepository) RequeueSending(claimedBefore time.Time) (int64, error) {
+	result := r.db.Model(&models.ContactCommunication{}).
+		Where("status = ?", models.CommunicationStatusSending).
+		Where("claimed_at IS NULL OR claimed_at < ?", claimedBefore).
+		Updates(map[string]interface{}{
+			"status":     models.CommunicationStatusQueued,
+			"claimed_at": nil,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ListByContact retrieves a contact's communications newest first
+func (r *communicationRepository) ListByContact(contactID uint, page, limit int) ([]models.ContactCommunication, int64, error) {
+	var communications []models.ContactCommunication
+	var total int64
+
+	query := r.db.Model(&models.ContactCommunication{}).Where("contact_id = ? AND deleted_at IS NULL", contactID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&communications).Error
+	return communications, total, err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"contact-service/internal/models"
+)
+
+// contactHistoryRepository implements ContactHistoryRepository interface
+type contactHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewContactHistoryRepository creates a new contact history repository
+func NewContactHistoryRepository(db *gorm.DB) ContactHistoryRepository {
+	return &contactHistoryRepository{db: db}
+}
+
+// RecordChanges stores a history row for every tracked field that differs between before and after.
+// Pass a nil before when the contact has just been created.
+func (r *contactHistoryRepository) RecordChanges(before, after *models.Contact, changedBy *uint, source, reason string) error {
+	changes := models.BuildFieldHistory(before, after, changedBy, source, reason)
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := r.db.Create(&changes).Error; err != nil {
+		return fmt.Errorf("failed to record contact field history: %v", err)
+	}
+	return nil
+}
+
+// RecordCurrent reloads the contact and records the differences from before
+func (r *contactHistoryRepository) RecordCurrent(before *models.Contact, changedBy *uint, source, reason string) error {
+	var after models.Contact
+	if err := r.db.First(&after, before.ID).Error; err != nil {
+		return fmt.Errorf("failed to reload contact for history: %v", err)
+	}
+	return r.RecordChanges(before, &after, changedBy, source, reason)
+}
+
+// ListByContact retrieves field history for a contact, newest first
+func (r *contactHistoryRepository) ListByContact(contactID uint, page, limit int) ([]models.ContactFieldHistory, int64, error) {
+	var history []models.ContactFieldHistory
+	var total int64
+
+	query := r.db.Model(&models.ContactFieldHistory{}).Where("contact_id = ?", contactID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&history).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return history, total, nil
+}
+
+// ListAfter retrieves field changes made after the given time, newest first
+func (r *contactHistoryRepository) ListAfter(contactID uint, at time.Time) ([]models.ContactFieldHistory, error) {
+	var history []models.ContactFieldHistory
+	err := r.db.Where("contact_id = ? AND created_at > ?", contactID, at).
+		Order("created_at DESC, id DESC").
+		Find(&history).Error
+	return history, err
+}
@@ -123,4 +123,23 @@ func (r *contactRepository) GetAssignedContacts(userID uint) ([]models.Contact,
 	var contacts []models.Contact
 	err := r.db.Where("assigned_to = ?", userID).Find(&contacts).Error
 	return contacts, err
-}
\ No newline at end of file
+}
+// ListActivitiesForContacts retrieves the activities of the given contacts, oldest first
+func (r *contactRepository) ListActivitiesForContacts(contactIDs []uint) ([]models.ContactActivity, error) {
+	var activities []models.ContactActivity
+	if len(contactIDs) == 0 {
+		return activities, nil
+	}
+	err := r.db.Where("contact_id IN ? AND deleted_at IS NULL", contactIDs).Order("contact_id ASC, activity_date ASC").Find(&activities).Error
+	return activities, err
+}
+
+// ListAppointmentsForContacts retrieves the appointments of the given contacts, oldest first
+func (r *contactRepository) ListAppointmentsForContacts(contactIDs []uint) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	if len(contactIDs) == 0 {
+		return appointments, nil
+	}
+	err := r.db.Where("contact_id IN ? AND deleted_at IS NULL", contactIDs).Order("contact_id ASC, scheduled_date ASC").Find(&appointments).Error
+	return appointments, err
+}
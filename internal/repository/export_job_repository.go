@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"contact-service/internal/models"
+)
+
+// exportJobRepository implements ExportJobRepository interface
+type exportJobRepository struct {
+	db *gorm.DB
+}
+
+// NewExportJobRepository creates a new export job repository
+func NewExportJobRepository(db *gorm.DB) ExportJobRepository {
+	return &exportJobRepository{db: db}
+}
+
+// Create creates a new export job
+func (r *exportJobRepository) Create(job *models.ExportJob) error {
+	return r.db.Create(job).Error
+}
+
+// GetByExportID retrieves an export job by its public export ID
+func (r *exportJobRepository) GetByExportID(exportID string) (*models.ExportJob, error) {
+	var job models.ExportJob
+	if err := r.db.Where("export_id = ?", exportID).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimNext marks the oldest runnable pending job as processing and returns it.
+// Jobs that have failed before are only picked up once their last attempt started before retryBefore.
+// It returns nil when there is nothing to run or another worker claimed the job first.
+func (r *exportJobRepository) ClaimNext(retryBefore time.Time) (*models.ExportJob, error) {
+	var job models.ExportJob
+	err := r.db.Where("status = ?", models.ExportStatusPending).
+		Where("retry_count = 0 OR started_at IS NULL OR started_at < ?", retryBefore).
+		Order("created_at ASC, id ASC").
+		First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	result := r.db.Model(&models.ExportJob{}).
+		Where("id = ? AND status = ?", job.ID, models.ExportStatusPending).
+		Updates(map[string]interface{}{
+			"status":     models.ExportStatusProcessing,
+			"progress":   0,
+			"started_at": now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	job.Status = models.ExportStatusProcessing
+	job.Progress = 0
+	job.StartedAt = &now
+	return &job, nil
+}
+
+// Update applies updates to an export job
+func (r *exportJobRepository) Update(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.ExportJob{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// RequeueProcessing handles jobs claimed before startedBefore, and so abandoned by a stopped worker,
+// like failed attempts: they return to the queue with their retry count incremented, or fail once
+// they have used up their retries. It returns the number of jobs requeued and failed.
+func (r *exportJobRepository) RequeueProcessing(startedBefore time.Time) (int64, int64, error) {
+	var requeued, failed int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		abandoned := func() *gorm.DB {
+			return tx.Model(&models.ExportJob{}).
+				Where("status = ?", models.ExportStatusProcessing).
+				Where("started_at IS NULL OR started_at < ?", startedBefore)
+		}
+
+		result := abandoned().Where("retry_count >= max_retries").Updates(map[string]interface{}{
+			"status":        models.ExportStatusFailed,
+			"progress":      0,
+			"error_message": "export was interrupted too many times",
+			"completed_at":  time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		failed = result.RowsAffected
+
+		result = abandoned().Updates(map[string]interface{}{
+			"status":      models.ExportStatusPending,
+			"progress":    0,
+			"retry_count": gorm.Expr("retry_count + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		requeued = result.RowsAffected
+		return nil
+	})
+	return requeued, failed, err
+}
+
+// ListExpired retrieves completed jobs whose files have passed their expiry time
+func (r *exportJobRepository) ListExpired(now time.Time, limit int) ([]models.ExportJob, error) {
+	var jobs []models.ExportJob
+	err := r.db.Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", models.ExportStatusCompleted, now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// RecordDownload increments the download counter of an export job
+func (r *exportJobRepository) RecordDownload(id uint) error {
+	return r.db.Model(&models.ExportJob{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"download_count": gorm.Expr("download_count + 1"),
+		"downloaded_at":  time.Now(),
+	}).Error
+}
+
+// List retrieves export jobs newest first
+func (r *exportJobRepository) List(createdBy *uint, page, limit int) ([]models.ExportJob, int64, error) {
+	var jobs []models.ExportJob
+	var total int64
+
+	query := r.db.Model(&models.ExportJob{})
+	if createdBy != nil {
+		query = query.Where("created_by = ?", *createdBy)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&jobs).Error
+	return jobs, total, err
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"contact-service/internal/models"
+)
+
+// importBatchRepository implements ImportBatchRepository interface
+type importBatchRepository struct {
+	db *gorm.DB
+}
+
+// NewImportBatchRepository creates a new import batch repository
+func NewImportBatchRepository(db *gorm.DB) ImportBatchRepository {
+	return &importBatchRepository{db: db}
+}
+
+// Create creates a new import batch
+func (r *importBatchRepository) Create(batch *models.ContactImportBatch) error {
+	return r.db.Create(batch).Error
+}
+
+// GetByID retrieves an import batch by ID, including its failed records
+func (r *importBatchRepository) GetByID(id uint) (*models.ContactImportBatch, error) {
+	var batch models.ContactImportBatch
+	if err := r.db.First(&batch, id).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// UpdateIfStatus applies updates only while the batch is still in the given status.
+// It reports false when the status has moved on, e.g. because the job was cancelled.
+func (r *importBatchRepository) UpdateIfStatus(id uint, status models.ImportBatchStatus, updates map[string]interface{}) (bool, error) {
+	result := r.db.Model(&models.ContactImportBatch{}).
+		Where("id = ? AND status = ?", id, status).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Update applies updates regardless of status
+func (r *importBatchRepository) Update(id uint, updates map[string]interface{}) error {
+	return r.db.Model(&models.ContactImportBatch{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Cancel marks a pending or processing batch as cancelled. It reports false when the batch had already finished.
+func (r *importBatchRepository) Cancel(id uint) (bool, error) {
+	result := r.db.Model(&models.ContactImportBatch{}).
+		Where("id = ? AND status IN ?", id, []models.ImportBatchStatus{models.ImportStatusPending, models.ImportStatusProcessing}).
+		Updates(map[string]interface{}{
+			"status":       models.ImportStatusCancelled,
+			"completed_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// FailAbandoned marks pending and processing batches that have not reported progress since
+// staleBefore as failed
+func (r *importBatchRepository) FailAbandoned(staleBefore time.Time, message string) (int64, error) {
+	result := r.db.Model(&models.ContactImportBatch{}).
+		Where("status IN ?", []models.ImportBatchStatus{models.ImportStatusPending, models.ImportStatusProcessing}).
+		Where("COALESCE(heartbeat_at, started_at) < ?", staleBefore).
+		Updates(map[string]interface{}{
+			"status":       models.ImportStatusFailed,
+			"completed_at": time.Now(),
+			"error_log":    message,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// List retrieves import batches newest first without their failed records
+func (r *importBatchRepository) List(importedBy *uint, page, limit int) ([]models.ContactImportBatch, int64, error) {
+	var batches []models.ContactImportBatch
+	var total int64
+
+	query := r.db.Model(&models.ContactImportBatch{})
+	if importedBy != nil {
+		query = query.Where("imported_by = ?", *importedBy)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Omit("failed_records").Order("started_at DESC, id DESC").Offset(offset).Limit(limit).Find(&batches).Error
+	return batches, total, err
+}
+
+// Summary aggregates import batch counts and returns the most recent batches
+func (r *importBatchRepository) Summary(recent int) (*models.ImportBatchSummary, error) {
+	summary := &models.ImportBatchSummary{}
+
+	if err := r.db.Model(&models.ContactImportBatch{}).Count(&summary.TotalImports).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&models.ContactImportBatch{}).
+		Where("status IN ?", []models.ImportBatchStatus{models.ImportStatusPending, models.ImportStatusProcessing}).
+		Count(&summary.ActiveImports).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&models.ContactImportBatch{}).
+		Select("COALESCE(SUM(records_imported), 0)").
+		Scan(&summary.RecordsImported).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Omit("failed_records").Order("started_at DESC, id DESC").Limit(recent).
+		Find(&summary.RecentImports).Error; err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
@@ -1,6 +1,9 @@
 package repository
 
-import "contact-service/internal/models"
+import (
+	"contact-service/internal/models"
+	"time"
+)
 
 // ContactRepository defines the interface for contact data operations
 type ContactRepository interface {
@@ -14,6 +17,8 @@ type ContactRepository interface {
 	UpdateStatus(id uint, status string) error
 	Assign(id uint, userID uint) error
 	GetAssignedContacts(userID uint) ([]models.Contact, error)
+	ListActivitiesForContacts(contactIDs []uint) ([]models.ContactActivity, error)
+	ListAppointmentsForContacts(contactIDs []uint) ([]models.Appointment, error)
 }
 
 // UserRepository defines the interface for user data operations
@@ -26,6 +31,56 @@ type UserRepository interface {
 	List(params UserListParams) ([]models.AdminUser, int64, error)
 }
 
+// ContactHistoryRepository defines the interface for contact field history operations
+type ContactHistoryRepository interface {
+	RecordChanges(before, after *models.Contact, changedBy *uint, source, reason string) error
+	RecordCurrent(before *models.Contact, changedBy *uint, source, reason string) error
+	ListByContact(contactID uint, page, limit int) ([]models.ContactFieldHistory, int64, error)
+	ListAfter(contactID uint, at time.Time) ([]models.ContactFieldHistory, error)
+}
+
+// ValidationRuleRepository defines the interface for contact validation rule operations
+type ValidationRuleRepository interface {
+	ListActive(channel models.ValidationChannel) ([]models.ContactValidationRule, error)
+	RecordUsage(counts map[uint]int) error
+	CountContactsWithValue(field, value string, excludeID uint) (int64, error)
+}
+
+// ImportBatchRepository defines the interface for contact import batch operations
+type ImportBatchRepository interface {
+	Create(batch *models.ContactImportBatch) error
+	GetByID(id uint) (*models.ContactImportBatch, error)
+	UpdateIfStatus(id uint, status models.ImportBatchStatus, updates map[string]interface{}) (bool, error)
+	Update(id uint, updates map[string]interface{}) error
+	Cancel(id uint) (bool, error)
+	FailAbandoned(staleBefore time.Time, message string) (int64, error)
+	List(importedBy *uint, page, limit int) ([]models.ContactImportBatch, int64, error)
+	Summary(recent int) (*models.ImportBatchSummary, error)
+}
+
+// ExportJobRepository defines the interface for background export job operations
+type ExportJobRepository interface {
+	Create(job *models.ExportJob) error
+	GetByExportID(exportID string) (*models.ExportJob, error)
+	ClaimNext(retryBefore time.Time) (*models.ExportJob, error)
+	Update(id uint, updates map[string]interface{}) error
+	RequeueProcessing(startedBefore time.Time) (int64, int64, error)
+	ListExpired(now time.Time, limit int) ([]models.ExportJob, error)
+	RecordDownload(id uint) error
+	List(createdBy *uint, page, limit int) ([]models.ExportJob, int64, error)
+}
+
+// CommunicationRepository defines the interface for contact communication operations
+type CommunicationRepository interface {
+	CreateWithActivity(communication *models.ContactCommunication, activity *models.ContactActivity) error
+	GetByID(id uint) (*models.ContactCommunication, error)
+	ClaimNextQueued(now time.Time) (*models.ContactCommunication, error)
+	Update(id uint, updates map[string]interface{}) error
+	UpdateActivity(communicationID uint, updates map[string]interface{}) error
+	RequeueSending(claimedBefore time.Time) (int64, error)
+	ListByContact(contactID uint, page, limit int) ([]models.ContactCommunication, int64, error)
+}
+
 // ContactListParams represents parameters for listing contacts
 type ContactListParams struct {
 	Page     int
@@ -45,5 +100,6 @@ type UserListParams struct {
 	Sort   string
 	Order  string
 	Role   string
-	Active bool
+	Active *bool
+	Search string
 }
\ No newline at end of file
@@ -53,6 +53,15 @@ func (r *userRepository) Delete(id uint) error {
 	return r.db.Delete(&models.AdminUser{}, id).Error
 }
 
+// userSortColumns are the columns users can be sorted by
+var userSortColumns = map[string]bool{
+	"name":          true,
+	"email":         true,
+	"role":          true,
+	"created_at":    true,
+	"last_login_at": true,
+}
+
 // List retrieves users with pagination and filtering
 func (r *userRepository) List(params UserListParams) ([]models.AdminUser, int64, error) {
 	var users []models.AdminUser
@@ -64,6 +73,12 @@ func (r *userRepository) List(params UserListParams) ([]models.AdminUser, int64,
 	if params.Role != "" {
 		query = query.Where("role = ?", params.Role)
 	}
+	if params.Active != nil {
+		query = query.Where("is_active = ?", *params.Active)
+	}
+	if params.Search != "" {
+		query = query.Where("name LIKE ? OR email LIKE ?", "%"+params.Search+"%", "%"+params.Search+"%")
+	}
 
 	// Count total records
 	if err := query.Count(&total).Error; err != nil {
@@ -71,7 +86,7 @@ func (r *userRepository) List(params UserListParams) ([]models.AdminUser, int64,
 	}
 
 	// Apply sorting
-	if params.Sort != "" {
+	if userSortColumns[params.Sort] {
 		order := "DESC"
 		if params.Order == "asc" {
 			order = "ASC"
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"contact-service/internal/models"
+)
+
+// validationRuleRepository implements ValidationRuleRepository interface
+type validationRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewValidationRuleRepository creates a new validation rule repository
+func NewValidationRuleRepository(db *gorm.DB) ValidationRuleRepository {
+	return &validationRuleRepository{db: db}
+}
+
+// ListActive retrieves active rules for a channel in execution order
+func (r *validationRuleRepository) ListActive(channel models.ValidationChannel) ([]models.ContactValidationRule, error) {
+	var column string
+	switch channel {
+	case models.ValidationChannelAPI:
+		column = "applies_to_api"
+	case models.ValidationChannelImport:
+		column = "applies_to_imports"
+	case models.ValidationChannelManual:
+		column = "applies_to_manual"
+	default:
+		return nil, fmt.Errorf("unknown validation channel: %s", channel)
+	}
+
+	var rules []models.ContactValidationRule
+	err := r.db.Where("is_active = ?", true).
+		Where(clause.Eq{Column: clause.Column{Name: column}, Value: true}).
+		Order("execution_order ASC, id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// RecordUsage increments usage_count for each rule by the number of times it was evaluated
+func (r *validationRuleRepository) RecordUsage(counts map[uint]int) error {
+	now := time.Now()
+	for ruleID, count := range counts {
+		if count == 0 {
+			continue
+		}
+		if err := r.db.Model(&models.ContactValidationRule{}).Where("id = ?", ruleID).Updates(map[string]interface{}{
+			"usage_count":  gorm.Expr("usage_count + ?", count),
+			"last_used_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to record usage for validation rule %d: %v", ruleID, err)
+		}
+	}
+	return nil
+}
+
+// CountContactsWithValue counts active contacts other than excludeID whose field equals value
+func (r *validationRuleRepository) CountContactsWithValue(field, value string, excludeID uint) (int64, error) {
+	if !models.IsContactField(field) {
+		return 0, fmt.Errorf("unknown contact field: %s", field)
+	}
+
+	var count int64
+	query := r.db.Model(&models.Contact{}).
+		Where(clause.Eq{Column: clause.Column{Name: field}, Value: value}).
+		Where("deleted_at IS NULL")
+	if excludeID != 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
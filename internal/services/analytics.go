@@ -5,6 +5,7 @@ import (
 	"contact-service/pkg/logger"
 	"fmt"
 	"sort"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
@@ -470,6 +471,155 @@ func (s *AnalyticsService) GetResponseTimeMetrics(request *models.AnalyticsReque
 	}, nil
 }
 
+// GetBusinessIntelligence builds business intelligence and the KPI summary from the contact, performance and conversion analytics.
+// Revenue is the estimated value of the contacts won (closed_won) in the period, and potential revenue that of the open pipeline.
+// Sections whose analytics fail to load are left empty.
+func (s *AnalyticsService) GetBusinessIntelligence(request *models.AnalyticsRequest) *models.BusinessIntelligence {
+	intelligence := &models.BusinessIntelligence{}
+	intelligence.Period = fmt.Sprintf("%s to %s", request.StartDate.Format("2006-01-02"), request.EndDate.Format("2006-01-02"))
+	previousStart := request.StartDate.Add(-request.EndDate.Sub(request.StartDate))
+
+	// Get contact analytics
+	contactAnalytics, err := s.GetContactAnalytics(request)
+	if err == nil {
+		intelligence.LeadConversionRate = contactAnalytics.Analytics.ConversionRate
+	}
+
+	// Get won deal values
+	if won, err := s.wonDeals(request.StartDate, request.EndDate, ""); err == nil && len(won) > 0 {
+		intelligence.TotalRevenue = won[0].Total
+		if won[0].Count > 0 {
+			intelligence.AverageLeadValue = won[0].Total / float64(won[0].Count)
+		}
+	}
+	if previous, err := s.wonDeals(previousStart, request.StartDate, ""); err == nil && len(previous) > 0 {
+		intelligence.RevenueGrowth = revenueGrowth(previous[0].Total, intelligence.TotalRevenue)
+	}
+	if err := s.db.Model(&models.Contact{}).
+		Where("status NOT IN ? AND deleted_at IS NULL", []models.ContactStatus{models.StatusClosedWon, models.StatusClosedLost}).
+		Select("COALESCE(SUM(estimated_value), 0)").Scan(&intelligence.PotentialRevenue).Error; err != nil {
+		logger.Error("Failed to get pipeline value", err, nil)
+	}
+
+	// Get user performance analytics
+	userPerformance, err := s.GetUserPerformanceAnalytics(request)
+	if err == nil {
+		intelligence.TeamProductivity = float64(userPerformance.TeamAverage.ActivityScore) / 100.0 * 100
+
+		// Build top performing users
+		revenueByUser := make(map[string]float64)
+		if won, err := s.wonDeals(request.StartDate, request.EndDate, "contacts.assigned_to"); err == nil {
+			for _, deals := range won {
+				if deals.GroupKey != nil {
+					revenueByUser[*deals.GroupKey] = deals.Total
+				}
+			}
+		}
+		topUsers := make([]models.UserPerformanceMetric, 0)
+		for _, user := range userPerformance.Users {
+			if len(topUsers) < 5 { // Top 5 performers
+				topUsers = append(topUsers, models.UserPerformanceMetric{
+					UserID:         user.UserID,
+					Username:       user.Username,
+					FullName:       user.FullName,
+					Score:          user.ActivityScore,
+					ConversionRate: user.ConversionRate,
+					Revenue:        revenueByUser[strconv.FormatUint(uint64(user.UserID), 10)],
+				})
+			}
+		}
+		intelligence.TopPerformingUsers = topUsers
+	}
+
+	// Get conversion metrics
+	conversionMetrics, err := s.GetConversionMetrics(request)
+	if err == nil {
+		intelligence.ConversionFunnel = conversionMetrics.ConversionFunnel
+	}
+
+	// Build revenue by source
+	intelligence.RevenueBySource = make([]models.RevenueSourceMetric, 0)
+	if won, err := s.wonDeals(request.StartDate, request.EndDate, "contact_sources.name"); err == nil {
+		previousBySource := make(map[string]float64)
+		if previous, err := s.wonDeals(previousStart, request.StartDate, "contact_sources.name"); err == nil {
+			for _, deals := range previous {
+				if deals.GroupKey != nil {
+					previousBySource[*deals.GroupKey] = deals.Total
+				}
+			}
+		}
+		for _, deals := range won {
+			if deals.GroupKey == nil || deals.Count == 0 {
+				continue
+			}
+			intelligence.RevenueBySource = append(intelligence.RevenueBySource, models.RevenueSourceMetric{
+				Source:   *deals.GroupKey,
+				Revenue:  deals.Total,
+				Count:    deals.Count,
+				AvgValue: deals.Total / float64(deals.Count),
+				Growth:   revenueGrowth(previousBySource[*deals.GroupKey], deals.Total),
+			})
+		}
+		sort.Slice(intelligence.RevenueBySource, func(i, j int) bool {
+			return intelligence.RevenueBySource[i].Revenue > intelligence.RevenueBySource[j].Revenue
+		})
+	}
+
+	// Build KPI summary
+	intelligence.KPISummary = models.KPISummary{
+		LeadConversionRate: intelligence.LeadConversionRate,
+		AverageLeadValue:   intelligence.AverageLeadValue,
+		TotalRevenue:       intelligence.TotalRevenue,
+		RevenueGrowth:      intelligence.RevenueGrowth,
+		TeamProductivity:   intelligence.TeamProductivity,
+	}
+	if contactAnalytics != nil {
+		intelligence.KPISummary.TotalLeads = contactAnalytics.Analytics.TotalContacts
+		intelligence.KPISummary.QualifiedLeads = contactAnalytics.Analytics.ActiveContacts
+		intelligence.KPISummary.ConvertedLeads = contactAnalytics.Analytics.ConvertedContacts
+	}
+
+	return intelligence
+}
+
+// wonDealTotal is the number and estimated value of won contacts, for one group when grouped
+type wonDealTotal struct {
+	GroupKey *string
+	Count    int
+	Total    float64
+}
+
+// wonDeals totals the estimated value of the contacts won between two instants, grouped by the
+// groupBy column when it is set (contacts.assigned_to or contact_sources.name)
+func (s *AnalyticsService) wonDeals(start, end time.Time, groupBy string) ([]wonDealTotal, error) {
+	columns := "COUNT(*) AS count, COALESCE(SUM(contacts.estimated_value), 0) AS total"
+	query := s.db.Model(&models.Contact{}).
+		Where("contacts.status = ? AND contacts.updated_at BETWEEN ? AND ? AND contacts.deleted_at IS NULL", models.StatusClosedWon, start, end)
+	if groupBy != "" {
+		query = query.Joins("LEFT JOIN contact_sources ON contact_sources.id = contacts.contact_source_id").
+			Select(groupBy + " AS group_key, " + columns).Group(groupBy)
+	} else {
+		query = query.Select(columns)
+	}
+
+	var totals []wonDealTotal
+	if err := query.Scan(&totals).Error; err != nil {
+		logger.Error("Failed to get won deal values", err, map[string]interface{}{
+			"group_by": groupBy,
+		})
+		return nil, err
+	}
+	return totals, nil
+}
+
+// revenueGrowth is the change from the previous period's revenue, in percent
+func revenueGrowth(previous, current float64) float64 {
+	if previous == 0 {
+		return 0
+	}
+	return (current - previous) / previous * 100
+}
+
 // GetRealtimeMetrics gets real-time dashboard metrics
 func (s *AnalyticsService) GetRealtimeMetrics() (*models.RealtimeMetrics, error) {
 	metrics := &models.RealtimeMetrics{}
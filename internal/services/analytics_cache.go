@@ -0,0 +1,492 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/logger"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// analyticsCacheDependencies lists the cached metrics that writes to each table make stale
+var analyticsCacheDependencies = map[string][]string{
+	"contacts":           {"contacts", "conversion", "response_time", "performance", analyticsTypeBusinessIntelligence},
+	"appointments":       {"appointments", "performance", analyticsTypeBusinessIntelligence},
+	"contact_activities": {"performance", "response_time"},
+}
+
+// analyticsPrecomputeWindows are the windows, in days up to today, kept warm for every metric before
+// anyone asks for them; 30 days is the analytics endpoints' default
+var analyticsPrecomputeWindows = []int{30}
+
+// AnalyticsCacheConfig configures the analytics cache
+type AnalyticsCacheConfig struct {
+	TTL                time.Duration // How long computed analytics are served
+	PrecomputeInterval time.Duration // How often popular windows are refreshed
+	PopularEntries     int           // Most-read entries refreshed per run
+	RetainDays         int           // Entries nobody read for this long are purged
+}
+
+// LoadAnalyticsCacheConfig reads the analytics cache configuration from the environment
+func LoadAnalyticsCacheConfig() AnalyticsCacheConfig {
+	config := AnalyticsCacheConfig{
+		TTL:                15 * time.Minute,
+		PrecomputeInterval: 10 * time.Minute,
+		PopularEntries:     20,
+		RetainDays:         7,
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("ANALYTICS_CACHE_TTL_MINUTES")); err == nil && minutes > 0 {
+		config.TTL = time.Duration(minutes) * time.Minute
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("ANALYTICS_PRECOMPUTE_MINUTES")); err == nil && minutes > 0 {
+		config.PrecomputeInterval = time.Duration(minutes) * time.Minute
+	}
+	if entries, err := strconv.Atoi(os.Getenv("ANALYTICS_PRECOMPUTE_ENTRIES")); err == nil && entries >= 0 {
+		config.PopularEntries = entries
+	}
+	if days, err := strconv.Atoi(os.Getenv("ANALYTICS_CACHE_RETAIN_DAYS")); err == nil && days > 0 {
+		config.RetainDays = days
+	}
+	return config
+}
+
+// AnalyticsCacheService serves analytics from the analytics_cache table, computing them with the
+// AnalyticsService on a miss. Entries are keyed on the request's normalized filters and expire after
+// the TTL, or once a contact, appointment or activity write that makes them stale has committed. A
+// background runner refreshes the most-read entries and the default windows before they are needed.
+//
+// Writes record an invalidation in their own transaction rather than expiring entries directly: an
+// entry computed while the write was still uncommitted would otherwise be cached with the old data.
+// An invalidation is applied the first time the cache sees it, and entries whose computation started
+// before then are never served.
+type AnalyticsCacheService struct {
+	db        *gorm.DB
+	analytics *AnalyticsService
+	config    AnalyticsCacheConfig
+	startOnce sync.Once
+}
+
+// NewAnalyticsCacheService creates an analytics cache in front of the analytics service
+func NewAnalyticsCacheService(db *gorm.DB, analytics *AnalyticsService, config AnalyticsCacheConfig) *AnalyticsCacheService {
+	return &AnalyticsCacheService{db: db, analytics: analytics, config: config}
+}
+
+// Start registers the write hooks that invalidate stale entries and launches the periodic
+// precompute. Calling it again has no effect.
+func (s *AnalyticsCacheService) Start() error {
+	var err error
+	s.startOnce.Do(func() {
+		callback := s.db.Callback()
+		if err = callback.Create().After("gorm:create").Register("analytics_cache:invalidate_create", s.invalidateAfterWrite); err != nil {
+			return
+		}
+		if err = callback.Update().After("gorm:update").Register("analytics_cache:invalidate_update", s.invalidateAfterWrite); err != nil {
+			return
+		}
+		if err = callback.Delete().After("gorm:delete").Register("analytics_cache:invalidate_delete", s.invalidateAfterWrite); err != nil {
+			return
+		}
+
+		go func() {
+			ticker := time.NewTicker(s.config.PrecomputeInterval)
+			defer ticker.Stop()
+			for {
+				if _, err := s.Precompute(); err != nil {
+					logger.Error("Analytics precompute failed", err, nil)
+				}
+				<-ticker.C
+			}
+		}()
+
+		logger.Info("Analytics cache started", map[string]interface{}{
+			"ttl":                 s.config.TTL.String(),
+			"precompute_interval": s.config.PrecomputeInterval.String(),
+			"popular_entries":     s.config.PopularEntries,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register analytics cache invalidation: %v", err)
+	}
+	return nil
+}
+
+// GetContactAnalytics returns contact analytics, from the cache when fresh
+func (s *AnalyticsCacheService) GetContactAnalytics(request *models.AnalyticsRequest) (*models.ContactMetricsResponse, *models.AnalyticsCacheInfo, error) {
+	var response models.ContactMetricsResponse
+	info, err := s.get("contacts", request, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &response, info, nil
+}
+
+// GetAppointmentAnalytics returns appointment analytics, from the cache when fresh
+func (s *AnalyticsCacheService) GetAppointmentAnalytics(request *models.AnalyticsRequest) (*models.AppointmentMetricsResponse, *models.AnalyticsCacheInfo, error) {
+	var response models.AppointmentMetricsResponse
+	info, err := s.get("appointments", request, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &response, info, nil
+}
+
+// GetUserPerformanceAnalytics returns user performance analytics, from the cache when fresh
+func (s *AnalyticsCacheService) GetUserPerformanceAnalytics(request *models.AnalyticsRequest) (*models.UserPerformanceResponse, *models.AnalyticsCacheInfo, error) {
+	var response models.UserPerformanceResponse
+	info, err := s.get("performance", request, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &response, info, nil
+}
+
+// GetConversionMetrics returns conversion metrics, from the cache when fresh
+func (s *AnalyticsCacheService) GetConversionMetrics(request *models.AnalyticsRequest) (*models.ConversionMetricsResponse, *models.AnalyticsCacheInfo, error) {
+	var response models.ConversionMetricsResponse
+	info, err := s.get("conversion", request, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &response, info, nil
+}
+
+// GetResponseTimeMetrics returns response time metrics, from the cache when fresh
+func (s *AnalyticsCacheService) GetResponseTimeMetrics(request *models.AnalyticsRequest) (*models.ResponseTimeMetricsResponse, *models.AnalyticsCacheInfo, error) {
+	var response models.ResponseTimeMetricsResponse
+	info, err := s.get("response_time", request, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &response, info, nil
+}
+
+// GetBusinessIntelligence returns business intelligence, from the cache when fresh
+func (s *AnalyticsCacheService) GetBusinessIntelligence(request *models.AnalyticsRequest) (*models.BusinessIntelligence, *models.AnalyticsCacheInfo, error) {
+	var response models.BusinessIntelligence
+	info, err := s.get(analyticsTypeBusinessIntelligence, request, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &response, info, nil
+}
+
+// GetRealtimeMetrics returns realtime metrics, which are never cached
+func (s *AnalyticsCacheService) GetRealtimeMetrics() (*models.RealtimeMetrics, error) {
+	return s.analytics.GetRealtimeMetrics()
+}
+
+// Invalidate marks the cached entries of the metric types stale. They stay in the table so the
+// precompute can still see which windows are popular.
+func (s *AnalyticsCacheService) Invalidate(metricTypes ...string) error {
+	return recordAnalyticsInvalidation(s.db, metricTypes)
+}
+
+// Precompute refreshes the most-read entries that are stale or will expire before the next run,
+// fills in the default windows, and purges entries nobody has read for RetainDays. It returns the
+// number of entries computed.
+func (s *AnalyticsCacheService) Precompute() (int, error) {
+	now := time.Now()
+	refreshBefore := now.Add(s.config.PrecomputeInterval)
+	computed := 0
+
+	if err := s.applyInvalidations(now); err != nil {
+		return 0, err
+	}
+
+	var popular []models.AnalyticsCache
+	if s.config.PopularEntries > 0 {
+		if err := s.db.Where("last_accessed > ? AND expires_at < ?", now.AddDate(0, 0, -1), refreshBefore).
+			Order("access_count DESC").Limit(s.config.PopularEntries).Find(&popular).Error; err != nil {
+			return 0, fmt.Errorf("failed to get popular analytics: %v", err)
+		}
+	}
+	for _, entry := range popular {
+		filters, err := models.ParseAnalyticsCacheFilters(entry.Filters)
+		if err != nil {
+			logger.Warn("Skipping analytics cache entry", map[string]interface{}{
+				"cache_key": entry.CacheKey,
+				"error":     err.Error(),
+			})
+			continue
+		}
+		if _, err := s.refresh(entry.MetricType, filters, false); err != nil {
+			logger.Error("Failed to precompute analytics", err, map[string]interface{}{
+				"cache_key": entry.CacheKey,
+			})
+			continue
+		}
+		computed++
+	}
+
+	for _, days := range analyticsPrecomputeWindows {
+		filters := models.NewAnalyticsCacheFilters(&models.AnalyticsRequest{
+			StartDate:   now.AddDate(0, 0, -days),
+			EndDate:     now,
+			Granularity: "day",
+		})
+		for metricType := range analyticsExportTypes {
+			var fresh int64
+			if err := s.db.Model(&models.AnalyticsCache{}).
+				Where("cache_key = ? AND expires_at >= ?", filters.CacheKey(metricType), refreshBefore).
+				Count(&fresh).Error; err != nil || fresh > 0 {
+				continue
+			}
+			if _, err := s.refresh(metricType, filters, false); err != nil {
+				logger.Error("Failed to precompute analytics", err, map[string]interface{}{
+					"metric_type": metricType,
+					"days":        days,
+				})
+				continue
+			}
+			computed++
+		}
+	}
+
+	if err := s.db.Where("last_accessed < ? AND expires_at < ?", now.AddDate(0, 0, -s.config.RetainDays), now).
+		Delete(&models.AnalyticsCache{}).Error; err != nil {
+		logger.Error("Failed to purge analytics cache", err, nil)
+	}
+	// Entries computed before an invalidation older than the TTL have expired anyway
+	if err := s.db.Where("applied_at < ?", now.Add(-s.config.TTL)).
+		Delete(&models.AnalyticsCacheInvalidation{}).Error; err != nil {
+		logger.Error("Failed to purge analytics cache invalidations", err, nil)
+	}
+
+	if computed > 0 {
+		logger.Info("Analytics precomputed", map[string]interface{}{
+			"entries":  computed,
+			"duration": time.Since(now).String(),
+		})
+	}
+	return computed, nil
+}
+
+// get serves the metric from a fresh cache entry, or computes and caches it, decoding it into target
+func (s *AnalyticsCacheService) get(metricType string, request *models.AnalyticsRequest, target interface{}) (*models.AnalyticsCacheInfo, error) {
+	filters := models.NewAnalyticsCacheFilters(request)
+	now := time.Now()
+
+	if err := s.applyInvalidations(now); err != nil {
+		logger.Error("Failed to apply analytics cache invalidations", err, nil)
+	}
+
+	var entry models.AnalyticsCache
+	err := s.db.Where("cache_key = ? AND expires_at > ?", filters.CacheKey(metricType), now).First(&entry).Error
+	if err == nil && s.invalidatedSince(metricType, entry.CreatedAt) {
+		err = gorm.ErrRecordNotFound
+	}
+	if err == nil {
+		err = decodeAnalytics(entry.Data, target)
+		if err == nil {
+			s.db.Model(&entry).Updates(map[string]interface{}{
+				"access_count":  gorm.Expr("access_count + 1"),
+				"last_accessed": now,
+			})
+			return analyticsCacheInfo(&entry, true), nil
+		}
+		logger.Warn("Discarding unreadable analytics cache entry", map[string]interface{}{
+			"cache_key": entry.CacheKey,
+			"error":     err.Error(),
+		})
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("Failed to read analytics cache", err, map[string]interface{}{
+			"metric_type": metricType,
+		})
+	}
+
+	computed, err := s.refresh(metricType, filters, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeAnalytics(computed.Data, target); err != nil {
+		return nil, fmt.Errorf("failed to decode %s analytics: %v", metricType, err)
+	}
+	return analyticsCacheInfo(computed, false), nil
+}
+
+// refresh computes the metric for the filters and stores it. A refresh on behalf of a request counts
+// as a read of the entry.
+func (s *AnalyticsCacheService) refresh(metricType string, filters models.AnalyticsCacheFilters, read bool) (*models.AnalyticsCache, error) {
+	compute, ok := analyticsExportTypes[metricType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported analytics type %q", metricType)
+	}
+	now := time.Now()
+	request, err := filters.Request(now)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := compute(s.analytics, request)
+	if err != nil {
+		return nil, err
+	}
+	data, err := encodeAnalytics(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s analytics: %v", metricType, err)
+	}
+	calculationTime := int(time.Since(now).Milliseconds())
+
+	startDate, _ := time.Parse("2006-01-02", filters.StartDate)
+	endDate, _ := time.Parse("2006-01-02", filters.EndDate)
+	entry := &models.AnalyticsCache{
+		CacheKey:        filters.CacheKey(metricType),
+		MetricType:      metricType,
+		StartDate:       startDate,
+		EndDate:         endDate,
+		Granularity:     filters.Granularity,
+		Filters:         filters.JSONMap(),
+		Data:            data,
+		CalculationTime: &calculationTime,
+		CreatedAt:       now,
+		ExpiresAt:       now.Add(s.config.TTL),
+		LastAccessed:    now,
+	}
+
+	updates := clause.AssignmentColumns([]string{"data", "filters", "calculation_time", "created_at", "expires_at"})
+	if read {
+		entry.AccessCount = 1
+		updates = append(updates,
+			clause.Assignment{Column: clause.Column{Name: "access_count"}, Value: gorm.Expr("access_count + 1")},
+			clause.Assignment{Column: clause.Column{Name: "last_accessed"}, Value: now},
+		)
+	}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cache_key"}},
+		DoUpdates: updates,
+	}).Create(entry).Error; err != nil {
+		logger.Error("Failed to store analytics cache entry", err, map[string]interface{}{
+			"metric_type": metricType,
+		})
+	}
+	return entry, nil
+}
+
+// invalidateAfterWrite is the gorm callback that marks the metrics depending on the written table
+// stale. It runs inside the write's transaction, so the invalidation commits or rolls back with it.
+func (s *AnalyticsCacheService) invalidateAfterWrite(db *gorm.DB) {
+	if db.Error != nil || db.Statement == nil || db.Statement.RowsAffected == 0 {
+		return
+	}
+	table := strings.Fields(db.Statement.Table)
+	if len(table) == 0 {
+		return
+	}
+	metricTypes, ok := analyticsCacheDependencies[table[0]]
+	if !ok {
+		return
+	}
+	if err := recordAnalyticsInvalidation(db, metricTypes); err != nil {
+		logger.Error("Failed to invalidate analytics cache", err, map[string]interface{}{
+			"table": table[0],
+		})
+	}
+}
+
+// applyInvalidations stamps the invalidations committed since they were last seen and expires the
+// entries of their metric types
+func (s *AnalyticsCacheService) applyInvalidations(now time.Time) error {
+	var pending []models.AnalyticsCacheInvalidation
+	if err := s.db.Where("applied_at IS NULL").Find(&pending).Error; err != nil {
+		return fmt.Errorf("failed to get analytics cache invalidations: %v", err)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(pending))
+	seen := make(map[string]bool)
+	var metricTypes []string
+	for _, invalidation := range pending {
+		ids = append(ids, invalidation.ID)
+		if !seen[invalidation.MetricType] {
+			seen[invalidation.MetricType] = true
+			metricTypes = append(metricTypes, invalidation.MetricType)
+		}
+	}
+
+	if err := s.db.Model(&models.AnalyticsCacheInvalidation{}).Where("id IN ?", ids).
+		Update("applied_at", now).Error; err != nil {
+		return fmt.Errorf("failed to apply analytics cache invalidations: %v", err)
+	}
+	if err := s.db.Model(&models.AnalyticsCache{}).
+		Where("metric_type IN ? AND expires_at > ?", metricTypes, now).
+		Update("expires_at", now).Error; err != nil {
+		return fmt.Errorf("failed to invalidate analytics cache: %v", err)
+	}
+	return nil
+}
+
+// invalidatedSince reports whether the metric type was invalidated after an entry's computation
+// started, which catches entries computed from data a concurrent write was about to replace
+func (s *AnalyticsCacheService) invalidatedSince(metricType string, computedAt time.Time) bool {
+	var appliedAt *time.Time
+	if err := s.db.Model(&models.AnalyticsCacheInvalidation{}).
+		Where("metric_type = ? AND applied_at IS NOT NULL", metricType).
+		Select("MAX(applied_at)").Scan(&appliedAt).Error; err != nil {
+		logger.Error("Failed to check analytics cache invalidations", err, map[string]interface{}{
+			"metric_type": metricType,
+		})
+		return true
+	}
+	// Timestamps are stored to the second, so an entry computed in the same second is stale too
+	return appliedAt != nil && !computedAt.After(*appliedAt)
+}
+
+// recordAnalyticsInvalidation records that the metric types' cached entries are stale, in db's
+// transaction when it has one
+func recordAnalyticsInvalidation(db *gorm.DB, metricTypes []string) error {
+	if len(metricTypes) == 0 {
+		return nil
+	}
+	invalidations := make([]models.AnalyticsCacheInvalidation, 0, len(metricTypes))
+	for _, metricType := range metricTypes {
+		invalidations = append(invalidations, models.AnalyticsCacheInvalidation{MetricType: metricType})
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&invalidations).Error; err != nil {
+		return fmt.Errorf("failed to invalidate analytics cache: %v", err)
+	}
+	return nil
+}
+
+// analyticsCacheInfo describes how fresh the entry served is
+func analyticsCacheInfo(entry *models.AnalyticsCache, cached bool) *models.AnalyticsCacheInfo {
+	age := int64(time.Since(entry.CreatedAt).Seconds())
+	if age < 0 {
+		age = 0
+	}
+	return &models.AnalyticsCacheInfo{
+		Cached:      cached,
+		GeneratedAt: entry.CreatedAt,
+		AgeSeconds:  age,
+		ExpiresAt:   entry.ExpiresAt,
+	}
+}
+
+func encodeAnalytics(result interface{}) (models.JSONMap, error) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var data models.JSONMap
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func decodeAnalytics(data models.JSONMap, target interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, target)
+}
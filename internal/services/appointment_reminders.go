@@ -0,0 +1,191 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/logger"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Delivery status recorded on reminders voided because their appointment moved
+const reminderSuperseded = "superseded"
+
+// defaultReminderSettings returns the reminders created when a request does not specify any.
+// APPOINTMENT_REMINDER_EMAIL_MINUTES and APPOINTMENT_REMINDER_SMS_MINUTES take comma-separated minute offsets.
+func defaultReminderSettings() []models.ReminderSetting {
+	var settings []models.ReminderSetting
+	add := func(reminderType, env, fallback string) {
+		value, ok := os.LookupEnv(env)
+		if !ok {
+			value = fallback
+		}
+		for _, part := range strings.Split(value, ",") {
+			if minutes, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && minutes > 0 {
+				settings = append(settings, models.ReminderSetting{ReminderType: reminderType, MinutesBefore: minutes})
+			}
+		}
+	}
+	add(models.ReminderTypeEmail, "APPOINTMENT_REMINDER_EMAIL_MINUTES", "1440,60")
+	add(models.ReminderTypeSMS, "APPOINTMENT_REMINDER_SMS_MINUTES", "60")
+	return settings
+}
+
+// scheduleReminders materializes reminder rows for an appointment.
+// Nil settings use the defaults; reminders whose send time has already passed are skipped.
+func (s *SchedulingService) scheduleReminders(appointment *models.Appointment, settings []models.ReminderSetting) error {
+	if settings == nil {
+		settings = defaultReminderSettings()
+	}
+	if len(settings) == 0 {
+		return nil
+	}
+
+	var contact models.Contact
+	if err := s.db.First(&contact, appointment.ContactID).Error; err != nil {
+		return fmt.Errorf("failed to get appointment contact: %v", err)
+	}
+
+	now := time.Now()
+	subject, message := reminderContent(appointment, &contact)
+	var reminders []models.AppointmentReminder
+	seen := make(map[models.ReminderSetting]bool)
+	for _, setting := range settings {
+		if seen[setting] {
+			continue
+		}
+		seen[setting] = true
+
+		sendAt := appointment.ScheduledDate.Add(-time.Duration(setting.MinutesBefore) * time.Minute)
+		if !sendAt.After(now) {
+			continue
+		}
+
+		reminder := models.AppointmentReminder{
+			AppointmentID:       appointment.ID,
+			ReminderType:        setting.ReminderType,
+			ReminderTimeMinutes: setting.MinutesBefore,
+			Status:              models.ReminderStatusScheduled,
+			ScheduledSendTime:   sendAt,
+			Subject:             &subject,
+			Message:             &message,
+		}
+		switch setting.ReminderType {
+		case models.ReminderTypeEmail:
+			if contact.Unsubscribed {
+				continue
+			}
+			reminder.RecipientEmail = &contact.Email
+		case models.ReminderTypeSMS:
+			if contact.Phone == nil || *contact.Phone == "" || contact.DoNotCall {
+				continue
+			}
+			reminder.RecipientPhone = contact.Phone
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	if len(reminders) == 0 {
+		return nil
+	}
+	if err := s.db.Create(&reminders).Error; err != nil {
+		return fmt.Errorf("failed to create reminders: %v", err)
+	}
+
+	logger.Info("Appointment reminders scheduled", map[string]interface{}{
+		"appointment_id": appointment.ID,
+		"count":          len(reminders),
+	})
+	return nil
+}
+
+// rescheduleReminders voids the pending reminders of a moved appointment and recreates
+// its reminder configuration against the new time, so reminders already sent go out again
+func (s *SchedulingService) rescheduleReminders(appointment *models.Appointment) error {
+	var existing []models.AppointmentReminder
+	if err := s.db.Where("appointment_id = ? AND status <> ?", appointment.ID, models.ReminderStatusCancelled).
+		Find(&existing).Error; err != nil {
+		return fmt.Errorf("failed to get reminders: %v", err)
+	}
+	if len(existing) == 0 {
+		return nil
+	}
+
+	settings := make([]models.ReminderSetting, 0, len(existing))
+	for _, reminder := range existing {
+		settings = append(settings, models.ReminderSetting{
+			ReminderType:  reminder.ReminderType,
+			MinutesBefore: reminder.ReminderTimeMinutes,
+		})
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := voidPendingReminders(tx, appointment.ID, reminderSuperseded); err != nil {
+			return err
+		}
+		return tx.Model(&models.Appointment{}).Where("id = ?", appointment.ID).Updates(map[string]interface{}{
+			"reminder_sent":    false,
+			"reminder_sent_at": nil,
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to void reminders: %v", err)
+	}
+
+	appointment.ReminderSent = false
+	appointment.ReminderSentAt = nil
+	return s.scheduleReminders(appointment, settings)
+}
+
+// cancelReminders voids the pending reminders of an appointment
+func (s *SchedulingService) cancelReminders(appointment *models.Appointment) error {
+	if err := voidPendingReminders(s.db, appointment.ID, string(models.AppointmentCancelled)); err != nil {
+		return fmt.Errorf("failed to cancel reminders: %v", err)
+	}
+	return nil
+}
+
+// voidPendingReminders cancels reminders that have not been claimed for sending
+func voidPendingReminders(db *gorm.DB, appointmentID uint, reason string) error {
+	return db.Model(&models.AppointmentReminder{}).
+		Where("appointment_id = ? AND status = ?", appointmentID, models.ReminderStatusScheduled).
+		Updates(map[string]interface{}{
+			"status":          models.ReminderStatusCancelled,
+			"delivery_status": reason,
+		}).Error
+}
+
+// reminderContent builds the subject and body of an appointment's reminders, in the appointment's timezone
+func reminderContent(appointment *models.Appointment, contact *models.Contact) (string, string) {
+	when := appointmentLocalTime(appointment).Format("Monday, January 2 at 15:04 MST")
+
+	subject := fmt.Sprintf("Reminder: %s on %s", appointment.Title, when)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hi %s,\n\nThis is a reminder of your %s scheduled for %s (%d minutes).",
+		contact.FirstName, appointment.Title, when, appointment.DurationMinutes)
+	if appointment.MeetingLink != nil && *appointment.MeetingLink != "" {
+		fmt.Fprintf(&body, "\nJoin: %s", *appointment.MeetingLink)
+	}
+	if appointment.Location != nil && *appointment.Location != "" {
+		fmt.Fprintf(&body, "\nLocation: %s", *appointment.Location)
+	}
+	if appointment.PhoneNumber != nil && *appointment.PhoneNumber != "" {
+		fmt.Fprintf(&body, "\nDial-in: %s", *appointment.PhoneNumber)
+	}
+	body.WriteString("\n\nSee you then!")
+
+	return truncate(subject, 255), body.String()
+}
+
+// appointmentLocalTime returns the appointment start in the appointment's timezone
+func appointmentLocalTime(appointment *models.Appointment) time.Time {
+	if location, err := time.LoadLocation(appointment.Timezone); err == nil {
+		return appointment.ScheduledDate.In(location)
+	}
+	return appointment.ScheduledDate
+}
@@ -0,0 +1,691 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/ical"
+	"contact-service/pkg/logger"
+	"contact-service/pkg/rrule"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Default number of days ahead for which series occurrences exist as appointments
+const defaultSeriesHorizonDays = 90
+
+// How often the materializer extends series horizons
+const seriesMaterializeInterval = time.Hour
+
+// Statuses of occurrences that have not happened yet and may be replaced or cancelled by series edits
+var pendingAppointmentStatuses = []models.AppointmentStatus{
+	models.AppointmentRequested,
+	models.AppointmentConfirmed,
+	models.AppointmentRescheduled,
+}
+
+// seriesHorizon returns how far ahead occurrences are materialized (APPOINTMENT_SERIES_HORIZON_DAYS)
+func seriesHorizon() time.Duration {
+	days := defaultSeriesHorizonDays
+	if n, err := strconv.Atoi(os.Getenv("APPOINTMENT_SERIES_HORIZON_DAYS")); err == nil && n > 0 {
+		days = n
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// seriesRecurrence is the expanded form of a series' stored recurrence
+type seriesRecurrence struct {
+	rule    *rrule.Rule
+	dtstart time.Time // In the series timezone, so occurrences keep their wall-clock time
+	exdates []time.Time
+}
+
+func parseSeriesRecurrence(series *models.AppointmentSeries) (*seriesRecurrence, error) {
+	rule, err := rrule.Parse(series.RRule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recurrence rule: %v", err)
+	}
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %v", err)
+	}
+
+	rec := &seriesRecurrence{rule: rule, dtstart: series.DTStart.In(loc)}
+	for _, value := range series.ExDates {
+		text, _ := value.(string)
+		date, err := time.ParseInLocation("2006-01-02", text, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exdate %v: expected YYYY-MM-DD", value)
+		}
+		rec.exdates = append(rec.exdates, time.Date(date.Year(), date.Month(), date.Day(),
+			rec.dtstart.Hour(), rec.dtstart.Minute(), rec.dtstart.Second(), 0, loc))
+	}
+	return rec, nil
+}
+
+// applyRecurrenceRequest validates a recurrence request and stores it on the series in canonical form
+func applyRecurrenceRequest(series *models.AppointmentSeries, request *models.RecurrenceRequest) error {
+	rule, err := rrule.Parse(request.RRule)
+	if err != nil {
+		return fmt.Errorf("invalid recurrence rule: %v", err)
+	}
+	series.RRule = rule.String()
+
+	series.ExDates = models.JSONArray{}
+	for _, exdate := range request.ExDates {
+		if _, err := time.Parse("2006-01-02", exdate); err != nil {
+			return fmt.Errorf("invalid exdate %q: expected YYYY-MM-DD", exdate)
+		}
+		series.ExDates = append(series.ExDates, exdate)
+	}
+	return nil
+}
+
+// reminderSettingsToJSON stores reminder settings on a series; nil keeps the defaults
+func reminderSettingsToJSON(settings []models.ReminderSetting) models.JSONArray {
+	if settings == nil {
+		return nil
+	}
+	result := models.JSONArray{}
+	for _, setting := range settings {
+		result = append(result, map[string]interface{}{
+			"reminder_type":  setting.ReminderType,
+			"minutes_before": setting.MinutesBefore,
+		})
+	}
+	return result
+}
+
+// seriesReminderSettings reads a series' reminder settings; nil means the defaults
+func seriesReminderSettings(series *models.AppointmentSeries) []models.ReminderSetting {
+	if series.Reminders == nil {
+		return nil
+	}
+	settings := []models.ReminderSetting{}
+	data, err := json.Marshal(series.Reminders)
+	if err == nil {
+		err = json.Unmarshal(data, &settings)
+	}
+	if err != nil {
+		logger.Error("Invalid series reminder settings", err, map[string]interface{}{
+			"series_id": series.ID,
+		})
+		return nil
+	}
+	return settings
+}
+
+// applySeriesTemplate copies the occurrence fields of an appointment request onto a series
+func applySeriesTemplate(series *models.AppointmentSeries, request *models.AppointmentRequest) {
+	series.ContactID = request.ContactID
+	series.AssignedTo = request.AssignedTo
+	series.Title = request.Title
+	series.Description = request.Description
+	series.Location = request.Location
+	series.MeetingLink = request.MeetingLink
+	series.MeetingID = request.MeetingID
+	series.MeetingPassword = request.MeetingPassword
+	series.PhoneNumber = request.PhoneNumber
+	if request.Priority != nil {
+		series.Priority = *request.Priority
+	}
+	if request.Timezone != nil {
+		series.Timezone = *request.Timezone
+	}
+	if request.MeetingType != nil {
+		series.MeetingType = *request.MeetingType
+	}
+	if request.AppointmentType != nil {
+		series.AppointmentType = *request.AppointmentType
+	}
+	if request.DurationMinutes != nil {
+		series.DurationMinutes = *request.DurationMinutes
+	}
+	if request.Reminders != nil {
+		series.Reminders = reminderSettingsToJSON(request.Reminders)
+	}
+}
+
+// seriesTemplateUpdates returns the column updates that carry a series' template onto its occurrences
+func seriesTemplateUpdates(series *models.AppointmentSeries, userID uint) map[string]interface{} {
+	return map[string]interface{}{
+		"contact_id":       series.ContactID,
+		"assigned_to":      series.AssignedTo,
+		"title":            series.Title,
+		"description":      series.Description,
+		"appointment_type": series.AppointmentType,
+		"priority":         series.Priority,
+		"meeting_type":     series.MeetingType,
+		"location":         series.Location,
+		"meeting_link":     series.MeetingLink,
+		"meeting_id":       series.MeetingID,
+		"meeting_password": series.MeetingPassword,
+		"phone_number":     series.PhoneNumber,
+		"updated_by":       userID,
+	}
+}
+
+// newSeriesOccurrence builds the appointment for one occurrence of a series
+func newSeriesOccurrence(series *models.AppointmentSeries, start time.Time) *models.Appointment {
+	recurrenceID := start
+	return &models.Appointment{
+		ContactID:       series.ContactID,
+		Title:           series.Title,
+		Description:     series.Description,
+		AppointmentType: series.AppointmentType,
+		ScheduledDate:   start,
+		ScheduledTime:   start.Format("15:04:05"),
+		Timezone:        series.Timezone,
+		DurationMinutes: series.DurationMinutes,
+		Status:          models.AppointmentRequested,
+		Priority:        series.Priority,
+		MeetingType:     series.MeetingType,
+		Location:        series.Location,
+		MeetingLink:     series.MeetingLink,
+		MeetingID:       series.MeetingID,
+		MeetingPassword: series.MeetingPassword,
+		PhoneNumber:     series.PhoneNumber,
+		AssignedTo:      series.AssignedTo,
+		SeriesID:        &series.ID,
+		RecurrenceID:    &recurrenceID,
+		CreatedBy:       series.CreatedBy,
+		UpdatedBy:       series.UpdatedBy,
+	}
+}
+
+// createAppointmentSeries creates a series from the first occurrence of a recurring appointment
+// request and materializes its occurrences within the horizon. Any conflicting occurrence fails the request.
+func (s *SchedulingService) createAppointmentSeries(first *models.Appointment, request *models.AppointmentRequest) (*models.AppointmentResponse, error) {
+	series := &models.AppointmentSeries{
+		ContactID:       first.ContactID,
+		AssignedTo:      first.AssignedTo,
+		DTStart:         first.ScheduledDate,
+		Timezone:        first.Timezone,
+		DurationMinutes: first.DurationMinutes,
+		Status:          models.SeriesActive,
+		Title:           first.Title,
+		Description:     first.Description,
+		AppointmentType: first.AppointmentType,
+		Priority:        first.Priority,
+		MeetingType:     first.MeetingType,
+		Location:        first.Location,
+		MeetingLink:     first.MeetingLink,
+		MeetingID:       first.MeetingID,
+		MeetingPassword: first.MeetingPassword,
+		PhoneNumber:     first.PhoneNumber,
+		Reminders:       reminderSettingsToJSON(request.Reminders),
+		CreatedBy:       first.CreatedBy,
+		UpdatedBy:       first.CreatedBy,
+	}
+	if err := applyRecurrenceRequest(series, request.Recurrence); err != nil {
+		return nil, err
+	}
+
+	var created []models.Appointment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(series).Error; err != nil {
+			return fmt.Errorf("failed to create appointment series: %v", err)
+		}
+		var err error
+		created, err = (&SchedulingService{db: tx}).createRecurringInstances(series, time.Now().Add(seriesHorizon()), true)
+		if err != nil {
+			return err
+		}
+		if len(created) == 0 {
+			return fmt.Errorf("invalid recurrence: the rule produces no upcoming occurrences")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.afterOccurrencesCreated(series, created)
+
+	logger.Info("Appointment series created", map[string]interface{}{
+		"series_id":   series.ID,
+		"contact_id":  series.ContactID,
+		"assigned_to": series.AssignedTo,
+		"rrule":       series.RRule,
+		"occurrences": len(created),
+		"created_by":  series.CreatedBy,
+	})
+
+	return s.buildAppointmentResponse(&created[0])
+}
+
+// createRecurringInstances materializes the occurrences of a series that start before the given
+// time and do not exist yet. Occurrences go through checkAppointmentConflicts: with strict set a
+// conflict fails the call, otherwise the occurrence is skipped and logged. Past occurrences are
+// never created. The series' materialized_until and status are updated to match.
+func (s *SchedulingService) createRecurringInstances(series *models.AppointmentSeries, until time.Time, strict bool) ([]models.Appointment, error) {
+	rec, err := parseSeriesRecurrence(series)
+	if err != nil {
+		return nil, err
+	}
+
+	from := rec.dtstart
+	if series.MaterializedUntil != nil && series.MaterializedUntil.After(from) {
+		from = *series.MaterializedUntil
+	}
+	if now := time.Now(); from.Before(now) {
+		from = now
+	}
+
+	var existing []time.Time
+	if err := s.db.Model(&models.Appointment{}).
+		Where("series_id = ? AND recurrence_id IS NOT NULL AND deleted_at IS NULL", series.ID).
+		Pluck("recurrence_id", &existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to get series occurrences: %v", err)
+	}
+	exists := make(map[int64]bool, len(existing))
+	for _, t := range existing {
+		exists[t.Unix()] = true
+	}
+
+	duration := time.Duration(series.DurationMinutes) * time.Minute
+	var created []models.Appointment
+	for _, start := range rec.rule.Between(rec.dtstart, from, until, rec.exdates) {
+		if exists[start.Unix()] {
+			continue
+		}
+		if err := s.checkAppointmentConflicts(series.AssignedTo, start, start.Add(duration), 0); err != nil {
+			if strict {
+				return nil, fmt.Errorf("%v (occurrence on %s)", err, start.Format("2006-01-02 15:04"))
+			}
+			logger.Warn("Skipped conflicting series occurrence", map[string]interface{}{
+				"series_id":  series.ID,
+				"occurrence": start,
+				"error":      err.Error(),
+			})
+			continue
+		}
+
+		occurrence := newSeriesOccurrence(series, start)
+		if err := s.db.Create(occurrence).Error; err != nil {
+			return nil, fmt.Errorf("failed to create series occurrence: %v", err)
+		}
+		created = append(created, *occurrence)
+	}
+
+	updates := map[string]interface{}{"materialized_until": until}
+	if _, more := rec.rule.Next(rec.dtstart, until, rec.exdates); !more {
+		updates["status"] = models.SeriesEnded
+		series.Status = models.SeriesEnded
+	}
+	if err := s.db.Model(series).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update appointment series: %v", err)
+	}
+	series.MaterializedUntil = &until
+
+	return created, nil
+}
+
+// afterOccurrencesCreated schedules reminders for new occurrences, sends their calendar invites and
+// refreshes the contact's next follow-up
+func (s *SchedulingService) afterOccurrencesCreated(series *models.AppointmentSeries, created []models.Appointment) {
+	settings := seriesReminderSettings(series)
+	for i := range created {
+		if err := s.scheduleReminders(&created[i], settings); err != nil {
+			logger.Error("Failed to schedule reminders", err, map[string]interface{}{
+				"appointment_id": created[i].ID,
+				"series_id":      series.ID,
+			})
+		}
+		s.sendCalendarInvite(created[i].ID, ical.MethodRequest, false)
+	}
+	s.updateContactNextFollowup(series.ContactID)
+}
+
+// GetAppointmentSeries gets a series with its upcoming occurrences
+func (s *SchedulingService) GetAppointmentSeries(seriesID uint) (*models.AppointmentSeriesResponse, error) {
+	var series models.AppointmentSeries
+	if err := s.db.First(&series, seriesID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("appointment series not found")
+		}
+		return nil, fmt.Errorf("failed to get appointment series: %v", err)
+	}
+	return s.seriesResponse(&series)
+}
+
+func (s *SchedulingService) seriesResponse(series *models.AppointmentSeries) (*models.AppointmentSeriesResponse, error) {
+	var appointments []models.Appointment
+	if err := s.db.Where("series_id = ? AND scheduled_date >= ? AND deleted_at IS NULL", series.ID, time.Now()).
+		Order("scheduled_date ASC").Find(&appointments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get series occurrences: %v", err)
+	}
+
+	response := &models.AppointmentSeriesResponse{Series: series, Occurrences: []models.AppointmentResponse{}}
+	for i := range appointments {
+		occurrence, err := s.buildAppointmentResponse(&appointments[i])
+		if err != nil {
+			return nil, err
+		}
+		response.Occurrences = append(response.Occurrences, *occurrence)
+	}
+	return response, nil
+}
+
+// getSeriesOccurrence loads an appointment together with the series it belongs to
+func (s *SchedulingService) getSeriesOccurrence(appointmentID uint) (*models.Appointment, *models.AppointmentSeries, error) {
+	var appointment models.Appointment
+	if err := s.db.Where("deleted_at IS NULL").First(&appointment, appointmentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("appointment not found")
+		}
+		return nil, nil, fmt.Errorf("failed to get appointment: %v", err)
+	}
+	if appointment.SeriesID == nil {
+		return nil, nil, fmt.Errorf("invalid scope: appointment is not part of a series")
+	}
+
+	var series models.AppointmentSeries
+	if err := s.db.First(&series, *appointment.SeriesID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("appointment series not found")
+		}
+		return nil, nil, fmt.Errorf("failed to get appointment series: %v", err)
+	}
+	if series.Status == models.SeriesCancelled {
+		return nil, nil, fmt.Errorf("appointment series is already cancelled")
+	}
+	if appointment.RecurrenceID == nil {
+		appointment.RecurrenceID = &appointment.ScheduledDate
+	}
+	return &appointment, &series, nil
+}
+
+// UpdateSeriesAppointment edits an occurrence together with every later one ("following") or the
+// whole series ("all"). Moving the edited occurrence shifts the others by the same amount. When the
+// timing or recurrence changes, upcoming occurrences are replaced; otherwise they are updated in place.
+// Occurrences edited on their own (series exceptions) are left alone.
+func (s *SchedulingService) UpdateSeriesAppointment(appointmentID uint, scope models.SeriesEditScope, request *models.AppointmentRequest, updatedByUserID uint) (*models.AppointmentSeriesResponse, error) {
+	appointment, series, err := s.getSeriesOccurrence(appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	newStart, err := s.parseScheduledDateTime(request.ScheduledDate, request.ScheduledTime, request.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scheduled date/time: %v", err)
+	}
+	shift := newStart.Sub(*appointment.RecurrenceID)
+	if scope == models.SeriesScopeFollowing && !appointment.RecurrenceID.After(series.DTStart) {
+		scope = models.SeriesScopeAll
+	}
+
+	// "following" continues in a new series starting at the edited occurrence
+	target := *series
+	if scope == models.SeriesScopeFollowing {
+		target.ID = 0
+		target.DTStart = *appointment.RecurrenceID
+		target.MaterializedUntil = nil
+		target.Status = models.SeriesActive
+		target.ParentSeriesID = &series.ID
+		target.CreatedAt = time.Time{}
+		target.UpdatedAt = time.Time{}
+		target.CreatedBy = &updatedByUserID
+	}
+	applySeriesTemplate(&target, request)
+	target.DTStart = target.DTStart.Add(shift)
+	target.UpdatedBy = &updatedByUserID
+	if request.Recurrence != nil {
+		if err := applyRecurrenceRequest(&target, request.Recurrence); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.validateAppointmentTimes(newStart, newStart.Add(time.Duration(target.DurationMinutes)*time.Minute)); err != nil {
+		return nil, err
+	}
+	retimed := shift != 0 || request.Recurrence != nil || target.DurationMinutes != series.DurationMinutes ||
+		target.Timezone != series.Timezone || target.AssignedTo != series.AssignedTo
+
+	var created []models.Appointment
+	var updated, replaced []uint
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txService := &SchedulingService{db: tx}
+		pending := tx.Model(&models.Appointment{}).
+			Where("series_id = ? AND deleted_at IS NULL AND is_series_exception = ?", series.ID, false).
+			Where("status IN ? AND scheduled_date >= ?", pendingAppointmentStatuses, time.Now())
+
+		if scope == models.SeriesScopeFollowing {
+			pending = pending.Where("recurrence_id >= ?", *appointment.RecurrenceID)
+			if err := txService.endSeriesBefore(series, *appointment.RecurrenceID, &target, request.Recurrence != nil); err != nil {
+				return err
+			}
+			if err := tx.Create(&target).Error; err != nil {
+				return fmt.Errorf("failed to create appointment series: %v", err)
+			}
+			retimed = true
+		} else if err := tx.Save(&target).Error; err != nil {
+			return fmt.Errorf("failed to update appointment series: %v", err)
+		}
+
+		if !retimed {
+			if err := pending.Session(&gorm.Session{}).Pluck("id", &updated).Error; err != nil {
+				return fmt.Errorf("failed to get series occurrences: %v", err)
+			}
+			if len(updated) == 0 {
+				return nil
+			}
+			return tx.Model(&models.Appointment{}).Where("id IN ?", updated).
+				Updates(seriesTemplateUpdates(&target, updatedByUserID)).Error
+		}
+
+		// Replace the upcoming occurrences; the edited one is replaced too, even if it was an exception
+		if err := pending.Session(&gorm.Session{}).Pluck("id", &replaced).Error; err != nil {
+			return fmt.Errorf("failed to get series occurrences: %v", err)
+		}
+		replaced = append(replaced, appointment.ID)
+		if err := tx.Model(&models.Appointment{}).Where("id IN ?", replaced).
+			Updates(map[string]interface{}{"deleted_at": time.Now(), "updated_by": updatedByUserID}).Error; err != nil {
+			return fmt.Errorf("failed to replace series occurrences: %v", err)
+		}
+		if target.ID == series.ID {
+			target.MaterializedUntil = nil
+		}
+
+		var err error
+		created, err = txService.createRecurringInstances(&target, time.Now().Add(seriesHorizon()), true)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range updated {
+		s.sendCalendarInvite(id, ical.MethodRequest, true)
+	}
+	for _, id := range replaced {
+		if err := voidPendingReminders(s.db, id, reminderSuperseded); err != nil {
+			logger.Error("Failed to cancel reminders", err, map[string]interface{}{
+				"appointment_id": id,
+			})
+		}
+		s.sendCalendarInvite(id, ical.MethodCancel, true)
+	}
+	s.afterOccurrencesCreated(&target, created)
+
+	logger.Info("Appointment series updated", map[string]interface{}{
+		"series_id":      target.ID,
+		"from_series_id": series.ID,
+		"appointment_id": appointmentID,
+		"scope":          scope,
+		"replaced":       len(replaced),
+		"created":        len(created),
+		"updated_by":     updatedByUserID,
+	})
+
+	return s.seriesResponse(&target)
+}
+
+// endSeriesBefore truncates a series so its last occurrence starts before the given time. When next
+// continues the series with the same rule, a COUNT limit is carried over as the remaining occurrences.
+func (s *SchedulingService) endSeriesBefore(series *models.AppointmentSeries, before time.Time, next *models.AppointmentSeries, ruleReplaced bool) error {
+	rec, err := parseSeriesRecurrence(series)
+	if err != nil {
+		return err
+	}
+
+	if next != nil && !ruleReplaced && rec.rule.Count > 0 {
+		remaining := *rec.rule
+		remaining.Count -= rec.rule.CountBefore(rec.dtstart, before)
+		if remaining.Count < 1 {
+			remaining.Count = 1
+		}
+		next.RRule = remaining.String()
+	}
+
+	truncated := *rec.rule
+	truncated.Count = 0
+	truncated.Until = before.Add(-time.Second).UTC()
+	updates := map[string]interface{}{"rrule": truncated.String()}
+	if _, more := truncated.Next(rec.dtstart, time.Now(), rec.exdates); !more {
+		updates["status"] = models.SeriesEnded
+	}
+	if err := s.db.Model(series).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update appointment series: %v", err)
+	}
+	return nil
+}
+
+// CancelSeriesAppointment cancels an occurrence together with every later one ("following") or
+// every upcoming occurrence of the series ("all"). The series stops producing the cancelled occurrences.
+func (s *SchedulingService) CancelSeriesAppointment(appointmentID uint, scope models.SeriesEditScope, reason string, cancelledByUserID uint) error {
+	appointment, series, err := s.getSeriesOccurrence(appointmentID)
+	if err != nil {
+		return err
+	}
+	if scope == models.SeriesScopeFollowing && !appointment.RecurrenceID.After(series.DTStart) {
+		scope = models.SeriesScopeAll
+	}
+
+	var cancelled []uint
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		pending := tx.Model(&models.Appointment{}).
+			Where("series_id = ? AND deleted_at IS NULL", series.ID).
+			Where("status IN ?", pendingAppointmentStatuses)
+
+		if scope == models.SeriesScopeFollowing {
+			pending = pending.Where("recurrence_id >= ?", *appointment.RecurrenceID)
+			if err := (&SchedulingService{db: tx}).endSeriesBefore(series, *appointment.RecurrenceID, nil, false); err != nil {
+				return err
+			}
+		} else {
+			pending = pending.Where("scheduled_date >= ?", time.Now())
+			if err := tx.Model(series).Updates(map[string]interface{}{
+				"status":     models.SeriesCancelled,
+				"updated_by": cancelledByUserID,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to cancel appointment series: %v", err)
+			}
+		}
+
+		if err := pending.Session(&gorm.Session{}).Pluck("id", &cancelled).Error; err != nil {
+			return fmt.Errorf("failed to get series occurrences: %v", err)
+		}
+		if len(cancelled) == 0 {
+			return nil
+		}
+		return tx.Model(&models.Appointment{}).Where("id IN ?", cancelled).Updates(map[string]interface{}{
+			"status":        models.AppointmentCancelled,
+			"cancelled_at":  time.Now(),
+			"cancel_reason": reason,
+			"updated_by":    cancelledByUserID,
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel series occurrences: %v", err)
+	}
+
+	for _, id := range cancelled {
+		if err := voidPendingReminders(s.db, id, string(models.AppointmentCancelled)); err != nil {
+			logger.Error("Failed to cancel reminders", err, map[string]interface{}{
+				"appointment_id": id,
+			})
+		}
+		s.sendCalendarInvite(id, ical.MethodCancel, true)
+	}
+	s.updateContactNextFollowup(series.ContactID)
+
+	logger.Info("Appointment series cancelled", map[string]interface{}{
+		"series_id":      series.ID,
+		"appointment_id": appointmentID,
+		"scope":          scope,
+		"cancelled":      len(cancelled),
+		"cancelled_by":   cancelledByUserID,
+		"reason":         reason,
+	})
+	return nil
+}
+
+// StartSeriesMaterializer periodically extends active series to the rolling horizon. Calling it again has no effect.
+func (s *SchedulingService) StartSeriesMaterializer() {
+	s.materializeOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(seriesMaterializeInterval)
+			defer ticker.Stop()
+			for {
+				s.ExtendSeriesHorizons()
+				<-ticker.C
+			}
+		}()
+
+		logger.Info("Appointment series materializer started", map[string]interface{}{
+			"horizon_days": int(seriesHorizon().Hours() / 24),
+		})
+	})
+}
+
+// ExtendSeriesHorizons materializes new occurrences for every active series whose horizon is
+// more than a day behind. Each series is claimed with a conditional update on materialized_until,
+// so several instances can run this concurrently.
+func (s *SchedulingService) ExtendSeriesHorizons() {
+	until := time.Now().Add(seriesHorizon())
+
+	var due []models.AppointmentSeries
+	if err := s.db.Where("status = ? AND (materialized_until IS NULL OR materialized_until < ?)",
+		models.SeriesActive, until.Add(-24*time.Hour)).Find(&due).Error; err != nil {
+		logger.Error("Failed to find series to extend", err, nil)
+		return
+	}
+
+	for i := range due {
+		series := &due[i]
+		var created []models.Appointment
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			claim := tx.Model(&models.AppointmentSeries{}).Where("id = ? AND status = ?", series.ID, models.SeriesActive)
+			if series.MaterializedUntil == nil {
+				claim = claim.Where("materialized_until IS NULL")
+			} else {
+				claim = claim.Where("materialized_until = ?", *series.MaterializedUntil)
+			}
+			result := claim.Update("updated_at", time.Now())
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			var err error
+			created, err = (&SchedulingService{db: tx}).createRecurringInstances(series, until, false)
+			return err
+		})
+		if err != nil {
+			logger.Error("Failed to extend appointment series", err, map[string]interface{}{
+				"series_id": series.ID,
+			})
+			continue
+		}
+		if len(created) > 0 {
+			s.afterOccurrencesCreated(series, created)
+			logger.Info("Appointment series extended", map[string]interface{}{
+				"series_id":   series.ID,
+				"occurrences": len(created),
+			})
+		}
+	}
+}
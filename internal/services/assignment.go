@@ -67,6 +67,7 @@ func (s *AssignmentService) AssignContactAutomatically(contactID uint, contextDa
 			}
 
 			// Update contact assignment fields
+			before := contact
 			now := time.Now()
 			if err := s.db.Model(&contact).Updates(map[string]interface{}{
 				"assigned_to": assigneeID,
@@ -76,6 +77,8 @@ func (s *AssignmentService) AssignContactAutomatically(contactID uint, contextDa
 					"contact_id":      contactID,
 					"assigned_to_id":  assigneeID,
 				})
+			} else {
+				recordContactHistorySince(s.db, &before, nil, models.ChangeSourceAssignment, s.generateAssignmentReason(&rule, &contact))
 			}
 
 			// Update rule statistics
@@ -141,6 +144,8 @@ func (s *AssignmentService) AssignContactManually(request *models.ContactAssignm
 	}
 
 	// Update contact assignment fields
+	var before models.Contact
+	beforeLoaded := s.db.First(&before, request.ContactID).Error == nil
 	now := time.Now()
 	if err := s.db.Model(&models.Contact{}).Where("id = ?", request.ContactID).Updates(map[string]interface{}{
 		"assigned_to": request.AssignedToID,
@@ -150,6 +155,8 @@ func (s *AssignmentService) AssignContactManually(request *models.ContactAssignm
 			"contact_id":     request.ContactID,
 			"assigned_to_id": request.AssignedToID,
 		})
+	} else if beforeLoaded {
+		recordContactHistorySince(s.db, &before, &assignedByID, models.ChangeSourceAssignment, request.AssignmentReason)
 	}
 
 	// Update user workloads
@@ -256,6 +263,8 @@ func (s *AssignmentService) UnassignContact(contactID uint, unassignedByID uint,
 	}
 
 	// Update contact to remove assignment
+	var before models.Contact
+	beforeLoaded := s.db.First(&before, contactID).Error == nil
 	if err := s.db.Model(&models.Contact{}).Where("id = ?", contactID).Updates(map[string]interface{}{
 		"assigned_to": nil,
 		"assigned_at": nil,
@@ -263,6 +272,8 @@ func (s *AssignmentService) UnassignContact(contactID uint, unassignedByID uint,
 		logger.Error("Failed to update contact assignment", err, map[string]interface{}{
 			"contact_id": contactID,
 		})
+	} else if beforeLoaded {
+		recordContactHistorySince(s.db, &before, &unassignedByID, models.ChangeSourceAssignment, reason)
 	}
 
 	// Update user workload
@@ -281,6 +292,119 @@ func (s *AssignmentService) UnassignContact(contactID uint, unassignedByID uint,
 	return nil
 }
 
+// assignTo makes assigneeID the contact's active assignee, superseding any current assignment.
+// It returns false without changing anything when the contact is already assigned to that user.
+func (s *AssignmentService) assignTo(contact *models.Contact, assigneeID uint, assignedByID, ruleID *uint, assignmentType, reason string) (bool, error) {
+	if contact.AssignedTo != nil && *contact.AssignedTo == assigneeID {
+		return false, nil
+	}
+
+	var assignee models.AdminUser
+	if err := s.db.Where("id = ? AND is_active = ?", assigneeID, true).First(&assignee).Error; err != nil {
+		return false, fmt.Errorf("assignee %d not found or inactive: %v", assigneeID, err)
+	}
+
+	var fromUserID *uint
+	var current models.ContactAssignment
+	if s.db.Where("contact_id = ? AND status = ?", contact.ID, "active").First(&current).Error == nil {
+		fromUserID = &current.AssignedToID
+		if err := s.db.Model(&current).Update("status", "reassigned").Error; err != nil {
+			return false, fmt.Errorf("failed to update current assignment: %v", err)
+		}
+	}
+
+	assignment := &models.ContactAssignment{
+		ContactID:        contact.ID,
+		AssignedToID:     assigneeID,
+		AssignedByID:     assignedByID,
+		RuleID:           ruleID,
+		AssignmentType:   assignmentType,
+		AssignmentReason: reason,
+		Priority:         contact.Priority,
+		Status:           "active",
+	}
+	if err := s.db.Create(assignment).Error; err != nil {
+		return false, fmt.Errorf("failed to create assignment: %v", err)
+	}
+
+	before := *contact
+	if err := s.db.Model(&models.Contact{}).Where("id = ?", contact.ID).Updates(map[string]interface{}{
+		"assigned_to": assigneeID,
+		"assigned_at": time.Now(),
+	}).Error; err != nil {
+		return false, fmt.Errorf("failed to update contact assignment: %v", err)
+	}
+	recordContactHistorySince(s.db, &before, assignedByID, models.ChangeSourceAssignment, reason)
+	contact.AssignedTo = &assigneeID
+
+	if fromUserID != nil {
+		s.updateUserWorkload(*fromUserID)
+	}
+	s.updateUserWorkload(assigneeID)
+
+	changeType := "assigned"
+	if fromUserID != nil {
+		changeType = "reassigned"
+	}
+	s.logAssignmentHistory(contact.ID, fromUserID, &assigneeID, assignedByID, ruleID, changeType, reason)
+
+	return true, nil
+}
+
+// ReleaseUserContacts hands the open contacts of a user who is leaving to reassignTo, or unassigns
+// them when reassignTo is nil. It returns how many contacts were reassigned and unassigned.
+func (s *AssignmentService) ReleaseUserContacts(userID uint, reassignTo *uint, changedByID uint, reason string) (int, int, error) {
+	var contacts []models.Contact
+	if err := s.db.Where("assigned_to = ? AND status NOT IN ?", userID,
+		[]string{string(models.StatusClosedWon), string(models.StatusClosedLost)}).
+		Find(&contacts).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to get open contacts: %v", err)
+	}
+
+	reassigned, unassigned := 0, 0
+	for i := range contacts {
+		if reassignTo != nil {
+			if _, err := s.assignTo(&contacts[i], *reassignTo, &changedByID, nil, "manual", reason); err != nil {
+				return reassigned, unassigned, err
+			}
+			reassigned++
+			continue
+		}
+		if err := s.unassign(&contacts[i], changedByID, reason); err != nil {
+			return reassigned, unassigned, err
+		}
+		unassigned++
+	}
+
+	if len(contacts) > 0 {
+		s.updateUserWorkload(userID)
+	}
+	return reassigned, unassigned, nil
+}
+
+// unassign takes a contact away from its assignee, whether or not an active assignment records it
+func (s *AssignmentService) unassign(contact *models.Contact, unassignedByID uint, reason string) error {
+	fromUserID := contact.AssignedTo
+	if err := s.db.Model(&models.ContactAssignment{}).
+		Where("contact_id = ? AND status = ?", contact.ID, "active").
+		Update("status", "unassigned").Error; err != nil {
+		return fmt.Errorf("failed to update assignment status: %v", err)
+	}
+
+	before := *contact
+	if err := s.db.Model(&models.Contact{}).Where("id = ?", contact.ID).Updates(map[string]interface{}{
+		"assigned_to": nil,
+		"assigned_at": nil,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update contact assignment: %v", err)
+	}
+	recordContactHistorySince(s.db, &before, &unassignedByID, models.ChangeSourceAssignment, reason)
+	contact.AssignedTo = nil
+
+	s.logAssignmentHistory(contact.ID, fromUserID, nil, &unassignedByID, nil, "unassigned", reason)
+	return nil
+}
+
 // GetUserWorkload gets the current workload for a user
 func (s *AssignmentService) GetUserWorkload(userID uint) (*models.UserWorkloadResponse, error) {
 	var workload models.UserWorkload
@@ -802,11 +926,14 @@ func (s *AssignmentService) fallbackAssignment(contact *models.Contact) (*models
 	}
 
 	// Update contact
+	before := *contact
 	now := time.Now()
-	s.db.Model(contact).Updates(map[string]interface{}{
+	if err := s.db.Model(contact).Updates(map[string]interface{}{
 		"assigned_to": assigneeID,
 		"assigned_at": now,
-	})
+	}).Error; err == nil {
+		recordContactHistorySince(s.db, &before, nil, models.ChangeSourceAssignment, "Fallback assignment - no matching rules")
+	}
 
 	// Update user workload
 	s.updateUserWorkload(assigneeID)
@@ -868,7 +995,7 @@ func (s *AssignmentService) logAssignmentHistory(contactID uint, fromUserID, toU
 	history := &models.AssignmentHistory{
 		ContactID:    contactID,
 		FromUserID:   fromUserID,
-		ToUserID:     *toUserID,
+		ToUserID:     toUserID,
 		ChangedByID:  changedByID,
 		RuleID:       ruleID,
 		ChangeType:   changeType,
@@ -0,0 +1,485 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/logger"
+	"contact-service/pkg/rrule"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Timezone of users who have not configured a weekly schedule
+const defaultScheduleTimezone = "Asia/Kolkata"
+
+// Granularity of the slots listed by GetUserAvailability
+const availabilitySlotMinutes = 30
+
+// Working hours, Monday to Friday, of users without a weekly schedule
+var defaultWorkingHours = models.WorkingHours{StartTime: "09:00", EndTime: "17:00"}
+
+// hostSchedule is a user's weekly schedule and exceptions, resolved for slot calculation
+type hostSchedule struct {
+	loc        *time.Location
+	days       map[time.Weekday]*models.UserAvailability
+	isDefault  bool
+	exceptions []models.AvailabilityException
+}
+
+// hostDay is a host's availability on one local date
+type hostDay struct {
+	working    []models.TimeWindow
+	blocked    []models.TimeWindow // Breaks and partial out-of-office exceptions
+	hours      *models.WorkingHours
+	exceptions []models.AvailabilityException
+}
+
+// GetWeeklySchedule gets a user's weekly working schedule
+func (s *SchedulingService) GetWeeklySchedule(userID uint) (*models.WeeklyScheduleResponse, error) {
+	var days []models.UserAvailability
+	if err := s.db.Where("user_id = ?", userID).Order("day_of_week ASC").Find(&days).Error; err != nil {
+		return nil, fmt.Errorf("failed to get weekly schedule: %v", err)
+	}
+
+	response := &models.WeeklyScheduleResponse{UserID: userID, Timezone: defaultScheduleTimezone, Days: days}
+	if len(days) == 0 {
+		response.IsDefault = true
+		response.Days = []models.UserAvailability{}
+		for day := time.Monday; day <= time.Friday; day++ {
+			response.Days = append(response.Days, models.UserAvailability{
+				UserID:      userID,
+				DayOfWeek:   int(day),
+				StartTime:   defaultWorkingHours.StartTime,
+				EndTime:     defaultWorkingHours.EndTime,
+				IsAvailable: true,
+				Timezone:    defaultScheduleTimezone,
+			})
+		}
+		return response, nil
+	}
+	response.Timezone = days[0].Timezone
+	return response, nil
+}
+
+// SetWeeklySchedule replaces a user's weekly working schedule
+func (s *SchedulingService) SetWeeklySchedule(userID uint, request *models.WeeklyScheduleRequest, updatedByUserID uint) (*models.WeeklyScheduleResponse, error) {
+	if _, err := time.LoadLocation(request.Timezone); err != nil {
+		return nil, fmt.Errorf("invalid timezone: %v", err)
+	}
+
+	seen := make(map[int]bool)
+	days := make([]models.UserAvailability, 0, len(request.Days))
+	for _, day := range request.Days {
+		if seen[day.DayOfWeek] {
+			return nil, fmt.Errorf("invalid schedule: day %d is listed more than once", day.DayOfWeek)
+		}
+		seen[day.DayOfWeek] = true
+
+		reference := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		hours, err := models.ClockWindow(reference, day.StartTime, day.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule for day %d: %v", day.DayOfWeek, err)
+		}
+
+		breaks := models.JSONArray{}
+		for _, b := range day.Breaks {
+			window, err := models.ClockWindow(reference, b.Start, b.End)
+			if err != nil {
+				return nil, fmt.Errorf("invalid break on day %d: %v", day.DayOfWeek, err)
+			}
+			if window.Start.Before(hours.Start) || window.End.After(hours.End) {
+				return nil, fmt.Errorf("invalid break on day %d: %s-%s is outside working hours", day.DayOfWeek, b.Start, b.End)
+			}
+			breaks = append(breaks, map[string]interface{}{"start": b.Start, "end": b.End, "title": b.Title})
+		}
+
+		available := true
+		if day.IsAvailable != nil {
+			available = *day.IsAvailable
+		}
+		days = append(days, models.UserAvailability{
+			UserID:      userID,
+			DayOfWeek:   day.DayOfWeek,
+			StartTime:   hours.Start.Format("15:04:05"),
+			EndTime:     hours.End.Format("15:04:05"),
+			IsAvailable: available,
+			BreakTimes:  breaks,
+			Timezone:    request.Timezone,
+			Notes:       day.Notes,
+			CreatedBy:   &updatedByUserID,
+			UpdatedBy:   &updatedByUserID,
+		})
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserAvailability{}).Error; err != nil {
+			return err
+		}
+		if len(days) == 0 {
+			return nil
+		}
+		return tx.Create(&days).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save weekly schedule: %v", err)
+	}
+
+	logger.Info("Weekly schedule updated", map[string]interface{}{
+		"user_id":    userID,
+		"days":       len(days),
+		"timezone":   request.Timezone,
+		"updated_by": updatedByUserID,
+	})
+
+	return s.GetWeeklySchedule(userID)
+}
+
+// ListAvailabilityExceptions lists a user's exceptions; recurring ones are always included
+func (s *SchedulingService) ListAvailabilityExceptions(userID uint, from, to *time.Time) ([]models.AvailabilityException, error) {
+	query := s.db.Where("user_id = ? AND deleted_at IS NULL", userID)
+	if from != nil {
+		query = query.Where("(date >= ? OR is_recurring = ?)", from.Format("2006-01-02"), true)
+	}
+	if to != nil {
+		query = query.Where("date <= ?", to.Format("2006-01-02"))
+	}
+
+	var exceptions []models.AvailabilityException
+	if err := query.Order("date ASC").Find(&exceptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list availability exceptions: %v", err)
+	}
+	return exceptions, nil
+}
+
+// GetAvailabilityException gets an availability exception by ID
+func (s *SchedulingService) GetAvailabilityException(id uint) (*models.AvailabilityException, error) {
+	var exception models.AvailabilityException
+	if err := s.db.Where("deleted_at IS NULL").First(&exception, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("availability exception not found")
+		}
+		return nil, fmt.Errorf("failed to get availability exception: %v", err)
+	}
+	return &exception, nil
+}
+
+// CreateAvailabilityException adds a holiday, out-of-office block or custom working hours for a user
+func (s *SchedulingService) CreateAvailabilityException(userID uint, request *models.AvailabilityExceptionRequest, createdByUserID uint) (*models.AvailabilityException, error) {
+	exception := &models.AvailabilityException{UserID: userID, CreatedBy: &createdByUserID}
+	if err := applyAvailabilityExceptionRequest(exception, request, createdByUserID); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(exception).Error; err != nil {
+		return nil, fmt.Errorf("failed to create availability exception: %v", err)
+	}
+
+	logger.Info("Availability exception created", map[string]interface{}{
+		"exception_id": exception.ID,
+		"user_id":      userID,
+		"date":         request.Date,
+		"reason":       exception.Reason,
+		"created_by":   createdByUserID,
+	})
+	return exception, nil
+}
+
+// UpdateAvailabilityException replaces the details of an availability exception
+func (s *SchedulingService) UpdateAvailabilityException(id uint, request *models.AvailabilityExceptionRequest, updatedByUserID uint) (*models.AvailabilityException, error) {
+	exception, err := s.GetAvailabilityException(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyAvailabilityExceptionRequest(exception, request, updatedByUserID); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(exception).Error; err != nil {
+		return nil, fmt.Errorf("failed to update availability exception: %v", err)
+	}
+	return exception, nil
+}
+
+// DeleteAvailabilityException soft-deletes an availability exception
+func (s *SchedulingService) DeleteAvailabilityException(id uint, deletedByUserID uint) error {
+	exception, err := s.GetAvailabilityException(id)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Model(exception).Updates(map[string]interface{}{
+		"deleted_at": time.Now(),
+		"updated_by": deletedByUserID,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to delete availability exception: %v", err)
+	}
+
+	logger.Info("Availability exception deleted", map[string]interface{}{
+		"exception_id": id,
+		"user_id":      exception.UserID,
+		"deleted_by":   deletedByUserID,
+	})
+	return nil
+}
+
+func applyAvailabilityExceptionRequest(exception *models.AvailabilityException, request *models.AvailabilityExceptionRequest, userID uint) error {
+	date, err := time.ParseInLocation("2006-01-02", request.Date, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid date: expected YYYY-MM-DD")
+	}
+	if (request.StartTime == nil) != (request.EndTime == nil) {
+		return fmt.Errorf("invalid exception: start_time and end_time must be given together")
+	}
+	if request.IsAvailable && request.StartTime == nil {
+		return fmt.Errorf("invalid exception: custom working hours need start_time and end_time")
+	}
+	if request.StartTime != nil {
+		if _, err := models.ClockWindow(date, *request.StartTime, *request.EndTime); err != nil {
+			return fmt.Errorf("invalid exception: %v", err)
+		}
+	}
+
+	exception.IsRecurring = false
+	exception.Recurrence = nil
+	if request.RRule != nil && *request.RRule != "" {
+		rule, err := rrule.Parse(*request.RRule)
+		if err != nil {
+			return fmt.Errorf("invalid recurrence rule: %v", err)
+		}
+		exception.IsRecurring = true
+		exception.Recurrence = models.JSONMap{"rrule": rule.String()}
+	}
+
+	exception.Date = date
+	exception.IsAvailable = request.IsAvailable
+	exception.StartTime = request.StartTime
+	exception.EndTime = request.EndTime
+	exception.Reason = request.Reason
+	exception.Title = request.Title
+	exception.Description = request.Description
+	exception.UpdatedBy = &userID
+	return nil
+}
+
+// loadHostSchedule loads a user's schedule and the exceptions that may apply between two times
+func (s *SchedulingService) loadHostSchedule(userID uint, from, to time.Time) (*hostSchedule, error) {
+	var days []models.UserAvailability
+	if err := s.db.Where("user_id = ?", userID).Find(&days).Error; err != nil {
+		return nil, fmt.Errorf("failed to get weekly schedule: %v", err)
+	}
+
+	schedule := &hostSchedule{days: make(map[time.Weekday]*models.UserAvailability), isDefault: len(days) == 0}
+	timezone := defaultScheduleTimezone
+	if len(days) > 0 {
+		timezone = days[0].Timezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule timezone: %v", err)
+	}
+	schedule.loc = loc
+	for i := range days {
+		schedule.days[time.Weekday(days[i].DayOfWeek)] = &days[i]
+	}
+
+	// Pad by a day on each side: exception dates are host-local calendar days
+	fromDate, toDate := from.AddDate(0, 0, -1), to.AddDate(0, 0, 1)
+	schedule.exceptions, err = s.ListAvailabilityExceptions(userID, &fromDate, &toDate)
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// day resolves the host's working windows on a local date (midnight in the host timezone).
+// Without a configured schedule, businessHoursOnly restricts the day to the default working hours.
+func (h *hostSchedule) day(date time.Time, businessHoursOnly bool) (*hostDay, error) {
+	result := &hostDay{}
+
+	switch {
+	case h.isDefault && businessHoursOnly:
+		if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+			window, _ := models.ClockWindow(date, defaultWorkingHours.StartTime, defaultWorkingHours.EndTime)
+			result.working = append(result.working, window)
+			result.hours = &models.WorkingHours{StartTime: defaultWorkingHours.StartTime, EndTime: defaultWorkingHours.EndTime, Timezone: h.loc.String()}
+		}
+	case h.isDefault:
+		result.working = append(result.working, models.TimeWindow{Start: date, End: date.AddDate(0, 0, 1)})
+	default:
+		if day, ok := h.days[date.Weekday()]; ok && day.IsAvailable {
+			window, err := models.ClockWindow(date, day.StartTime, day.EndTime)
+			if err != nil {
+				return nil, fmt.Errorf("invalid schedule for day %d: %v", day.DayOfWeek, err)
+			}
+			result.working = append(result.working, window)
+			result.hours = &models.WorkingHours{StartTime: window.Start.Format("15:04"), EndTime: window.End.Format("15:04"), Timezone: h.loc.String()}
+
+			for _, entry := range day.BreakTimes {
+				b, _ := entry.(map[string]interface{})
+				start, _ := b["start"].(string)
+				end, _ := b["end"].(string)
+				if window, err := models.ClockWindow(date, start, end); err == nil {
+					result.blocked = append(result.blocked, window)
+				}
+			}
+		}
+	}
+
+	// Custom hours replace the day's hours; unavailability then removes time from them
+	for _, available := range []bool{true, false} {
+		for i := range h.exceptions {
+			exception := &h.exceptions[i]
+			if exception.IsAvailable != available || !exceptionOccursOn(exception, date) {
+				continue
+			}
+			result.exceptions = append(result.exceptions, *exception)
+
+			if exception.StartTime == nil {
+				result.working = nil
+				result.hours = nil
+				continue
+			}
+			window, err := models.ClockWindow(date, *exception.StartTime, *exception.EndTime)
+			if err != nil {
+				continue
+			}
+			if available {
+				result.working = []models.TimeWindow{window}
+				result.hours = &models.WorkingHours{StartTime: window.Start.Format("15:04"), EndTime: window.End.Format("15:04"), Timezone: h.loc.String()}
+			} else {
+				result.blocked = append(result.blocked, window)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// exceptionOccursOn reports whether an exception applies on a host-local date
+func exceptionOccursOn(exception *models.AvailabilityException, date time.Time) bool {
+	day := date.Format("2006-01-02")
+	if exception.Date.Format("2006-01-02") == day {
+		return true
+	}
+	value := exception.RecurrenceRule()
+	if value == "" {
+		return false
+	}
+	rule, err := rrule.Parse(value)
+	if err != nil {
+		return false
+	}
+	y, m, d := exception.Date.Date()
+	dtstart := time.Date(y, m, d, 0, 0, 0, 0, date.Location())
+	return len(rule.Between(dtstart, date, date.AddDate(0, 0, 1), nil)) > 0
+}
+
+// getUserBusyWindows returns the times a user is booked between two instants: their appointments and
+// the busy time pulled from their enabled external calendars
+func (s *SchedulingService) getUserBusyWindows(userID uint, from, to time.Time) ([]models.TimeWindow, error) {
+	var appointments []models.Appointment
+	// Appointments last at most 8 hours, so earlier starts cannot overlap the range
+	if err := s.db.Where("assigned_to = ? AND deleted_at IS NULL", userID).
+		Where("scheduled_date >= ? AND scheduled_date < ?", from.Add(-8*time.Hour), to).
+		Where("status NOT IN ?", []models.AppointmentStatus{
+			models.AppointmentCancelled,
+			models.AppointmentNoShow,
+		}).
+		Find(&appointments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user appointments: %v", err)
+	}
+
+	var busy []models.TimeWindow
+	for _, appointment := range appointments {
+		window := models.TimeWindow{
+			Start: appointment.ScheduledDate,
+			End:   appointment.ScheduledDate.Add(time.Duration(appointment.DurationMinutes) * time.Minute),
+		}
+		if window.End.After(from) {
+			busy = append(busy, window)
+		}
+	}
+
+	var blocks []models.CalendarBusyBlock
+	if err := s.db.Where("user_id = ? AND start_time < ? AND end_time > ?", userID, to, from).
+		Where("integration_id IN (?)", s.db.Model(&models.CalendarIntegration{}).Select("id").
+			Where("is_enabled = ? AND deleted_at IS NULL", true)).
+		Find(&blocks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get external busy time: %v", err)
+	}
+	for _, block := range blocks {
+		busy = append(busy, models.TimeWindow{Start: block.StartTime, End: block.EndTime})
+	}
+	return busy, nil
+}
+
+// availableWindows returns a host's free time between two instants: working hours from the weekly
+// schedule and exceptions, minus breaks, out-of-office blocks and appointments padded by the buffer
+func (s *SchedulingService) availableWindows(schedule *hostSchedule, userID uint, from, to time.Time, bufferMinutes int, businessHoursOnly bool) ([]models.TimeWindow, error) {
+	buffer := time.Duration(bufferMinutes) * time.Minute
+	busy, err := s.getUserBusyWindows(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for i := range busy {
+		busy[i].Start = busy[i].Start.Add(-buffer)
+		busy[i].End = busy[i].End.Add(buffer)
+	}
+
+	var working []models.TimeWindow
+	localFrom := from.In(schedule.loc)
+	for date := time.Date(localFrom.Year(), localFrom.Month(), localFrom.Day(), 0, 0, 0, 0, schedule.loc); date.Before(to); date = date.AddDate(0, 0, 1) {
+		day, err := schedule.day(date, businessHoursOnly)
+		if err != nil {
+			return nil, err
+		}
+		working = append(working, models.SubtractWindows(day.working, day.blocked)...)
+	}
+
+	// Clip to the requested range and never offer time that has already passed
+	earliest := from
+	if now := time.Now(); earliest.Before(now) {
+		earliest = now
+	}
+	free := models.SubtractWindows(working, append(busy,
+		models.TimeWindow{Start: time.Time{}, End: earliest},
+		models.TimeWindow{Start: to, End: to.AddDate(100, 0, 0)},
+	))
+	return free, nil
+}
+
+// findUserAvailableSlots finds available time slots for a specific user, reported in the requester's timezone
+func (s *SchedulingService) findUserAvailableSlots(userID uint, startDate, endDate time.Time, duration, bufferTime int, timezone string, businessHoursOnly *bool) ([]models.AvailabilitySlot, error) {
+	requesterLoc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %v", err)
+	}
+	if !endDate.After(startDate) {
+		return nil, fmt.Errorf("invalid range: end_date must be after start_date")
+	}
+	if endDate.Sub(startDate) > 62*24*time.Hour {
+		return nil, fmt.Errorf("invalid range: at most 62 days can be searched at once")
+	}
+
+	schedule, err := s.loadHostSchedule(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	free, err := s.availableWindows(schedule, userID, startDate, endDate, bufferTime, businessHoursOnly == nil || *businessHoursOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := []models.AvailabilitySlot{}
+	for _, window := range models.SplitWindows(free, time.Duration(duration)*time.Minute) {
+		start := window.Start.In(requesterLoc)
+		slots = append(slots, models.AvailabilitySlot{
+			StartTime:   start,
+			EndTime:     window.End.In(requesterLoc),
+			Duration:    duration,
+			IsAvailable: true,
+			UserID:      userID,
+			Date:        time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, requesterLoc),
+		})
+	}
+	return slots, nil
+}
@@ -11,20 +11,29 @@ import (
 
 	"contact-service/internal/models"
 	"contact-service/internal/repository"
+	apperrors "contact-service/pkg/errors"
+	"contact-service/pkg/logger"
 )
 
 // BulkService handles bulk operations for contacts
 type BulkService struct {
 	contactRepo repository.ContactRepository
 	userRepo    repository.UserRepository
+	historyRepo repository.ContactHistoryRepository
+	validator   *ValidationService
 }
 
 // NewBulkService creates a new bulk service instance
-func NewBulkService(contactRepo repository.ContactRepository, userRepo repository.UserRepository) *BulkService {
-	return &BulkService{
+func NewBulkService(contactRepo repository.ContactRepository, userRepo repository.UserRepository, historyRepo repository.ContactHistoryRepository, ruleRepo repository.ValidationRuleRepository) *BulkService {
+	service := &BulkService{
 		contactRepo: contactRepo,
 		userRepo:    userRepo,
+		historyRepo: historyRepo,
 	}
+	if ruleRepo != nil {
+		service.validator = NewValidationService(ruleRepo)
+	}
+	return service
 }
 
 // BulkImportResult represents the result of a bulk import operation
@@ -33,6 +42,7 @@ type BulkImportResult struct {
 	SuccessCount    int                    `json:"success_count"`
 	ErrorCount      int                    `json:"error_count"`
 	Errors          []BulkImportError      `json:"errors,omitempty"`
+	Warnings        []BulkImportError      `json:"warnings,omitempty"` // Non-blocking validation rule violations
 	ImportedIDs     []uint                 `json:"imported_ids"`
 	ProcessingTime  time.Duration          `json:"processing_time"`
 	Summary         map[string]interface{} `json:"summary"`
@@ -40,10 +50,11 @@ type BulkImportResult struct {
 
 // BulkImportError represents an error during bulk import
 type BulkImportError struct {
-	Row     int    `json:"row"`
-	Field   string `json:"field,omitempty"`
-	Value   string `json:"value,omitempty"`
-	Message string `json:"message"`
+	Row      int    `json:"row"`
+	Field    string `json:"field,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Message  string `json:"message"`
+	Severity string `json:"severity,omitempty"`
 }
 
 // BulkUpdateRequest represents a bulk update request
@@ -70,6 +81,7 @@ const (
 	ExportFormatCSV  ExportFormat = "csv"
 	ExportFormatJSON ExportFormat = "json"
 	ExportFormatXLSX ExportFormat = "xlsx"
+	ExportFormatPDF  ExportFormat = "pdf"
 )
 
 // ExportRequest represents an export request
@@ -109,6 +121,17 @@ func (s *BulkService) ImportContactsFromCSV(data io.Reader, skipHeader bool) (*B
 		Summary:     make(map[string]interface{}),
 	}
 
+	// Load the import validation rules once for the whole file
+	var rules []models.ContactValidationRule
+	ruleUsage := make(map[uint]int)
+	if s.validator != nil {
+		var err error
+		if rules, err = s.validator.LoadRules(models.ValidationChannelImport); err != nil {
+			return nil, err
+		}
+		defer s.validator.RecordUsage(ruleUsage)
+	}
+
 	reader := csv.NewReader(data)
 	reader.FieldsPerRecord = -1 // Allow variable number of fields
 
@@ -133,7 +156,7 @@ func (s *BulkService) ImportContactsFromCSV(data io.Reader, skipHeader bool) (*B
 		result.TotalRecords--
 	} else {
 		// Use default headers if no header row
-		headers = []string{"Name", "Email", "Phone", "Company", "Position", "Status", "Type", "Source", "Notes"}
+		headers = defaultImportHeaders
 	}
 
 	// Process each record
@@ -154,6 +177,21 @@ func (s *BulkService) ImportContactsFromCSV(data io.Reader, skipHeader bool) (*B
 			continue
 		}
 
+		// Apply configured validation rules
+		if s.validator != nil {
+			validation := s.validator.Evaluate(rules, contact, ruleUsage)
+			for _, warning := range validation.Warnings {
+				result.Warnings = append(result.Warnings, importErrorFromField(rowNum, warning))
+			}
+			if validation.HasErrors() {
+				for _, fieldErr := range validation.Errors {
+					result.Errors = append(result.Errors, importErrorFromField(rowNum, fieldErr))
+					result.ErrorCount++
+				}
+				continue
+			}
+		}
+
 		// Check for duplicate email
 		if existing, _ := s.contactRepo.GetByEmail(contact.Email); existing != nil {
 			result.Errors = append(result.Errors, BulkImportError{
@@ -176,6 +214,8 @@ func (s *BulkService) ImportContactsFromCSV(data io.Reader, skipHeader bool) (*B
 			continue
 		}
 
+		s.recordHistory(nil, contact, models.ChangeSourceImport, fmt.Sprintf("Imported from CSV row %d", rowNum))
+
 		result.ImportedIDs = append(result.ImportedIDs, contact.ID)
 		result.SuccessCount++
 	}
@@ -306,8 +346,50 @@ func (s *BulkService) parseCSVRow(row []string, headers []string, rowNum int) (*
 	return contact, errors
 }
 
+// ExportContacts renders the contacts selected by the request in its format and returns the number exported
+func (s *BulkService) ExportContacts(request ExportRequest) ([]byte, int, error) {
+	contacts, total, err := s.listContactsForExport(request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var data []byte
+	switch request.Format {
+	case ExportFormatCSV, "":
+		data, err = writeContactsCSV(contacts, request.Fields)
+	case ExportFormatJSON:
+		data, err = marshalContactsJSON(contacts, total, request)
+	case ExportFormatXLSX:
+		data, err = s.writeContactsWorkbook(contacts)
+	default:
+		return nil, 0, fmt.Errorf("unsupported export format: %s", request.Format)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, len(contacts), nil
+}
+
 // ExportContactsToCSV exports contacts to CSV format
 func (s *BulkService) ExportContactsToCSV(request ExportRequest) ([]byte, error) {
+	contacts, _, err := s.listContactsForExport(request)
+	if err != nil {
+		return nil, err
+	}
+	return writeContactsCSV(contacts, request.Fields)
+}
+
+// ExportContactsToJSON exports contacts to JSON format
+func (s *BulkService) ExportContactsToJSON(request ExportRequest) ([]byte, error) {
+	contacts, total, err := s.listContactsForExport(request)
+	if err != nil {
+		return nil, err
+	}
+	return marshalContactsJSON(contacts, total, request)
+}
+
+// listContactsForExport retrieves the contacts selected by an export request
+func (s *BulkService) listContactsForExport(request ExportRequest) ([]models.Contact, int64, error) {
 	// Build query parameters
 	params := repository.ContactListParams{
 		Page:  1,
@@ -334,19 +416,22 @@ func (s *BulkService) ExportContactsToCSV(request ExportRequest) ([]byte, error)
 	}
 
 	// Get contacts
-	contacts, _, err := s.contactRepo.List(params)
+	contacts, total, err := s.contactRepo.List(params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve contacts: %w", err)
+		return nil, 0, fmt.Errorf("failed to retrieve contacts: %w", err)
 	}
+	return contacts, total, nil
+}
 
+// writeContactsCSV writes the requested fields of each contact as CSV rows
+func writeContactsCSV(contacts []models.Contact, fields []string) ([]byte, error) {
 	// Create CSV buffer
 	var buf bytes.Buffer
 	writer := csv.NewWriter(&buf)
 
 	// Determine fields to export
-	fields := request.Fields
 	if len(fields) == 0 {
-		fields = []string{"FirstName", "LastName", "Email", "Phone", "Company", "JobTitle", "Status", "Notes", "CreatedAt", "UpdatedAt"}
+		fields = defaultExportFields
 	}
 
 	// Write header
@@ -356,46 +441,7 @@ func (s *BulkService) ExportContactsToCSV(request ExportRequest) ([]byte, error)
 
 	// Write data rows
 	for _, contact := range contacts {
-		row := make([]string, len(fields))
-		
-		for i, field := range fields {
-			switch strings.ToLower(field) {
-			case "firstname":
-				row[i] = contact.FirstName
-			case "lastname":
-				if contact.LastName != nil {
-					row[i] = *contact.LastName
-				}
-			case "email":
-				row[i] = contact.Email
-			case "phone":
-				if contact.Phone != nil {
-					row[i] = *contact.Phone
-				}
-			case "company":
-				if contact.Company != nil {
-					row[i] = *contact.Company
-				}
-			case "jobtitle":
-				if contact.JobTitle != nil {
-					row[i] = *contact.JobTitle
-				}
-			case "status":
-				row[i] = string(contact.Status)
-			case "notes":
-				if contact.Notes != nil {
-					row[i] = *contact.Notes
-				}
-			case "createdat":
-				row[i] = contact.CreatedAt.Format("2006-01-02 15:04:05")
-			case "updatedat":
-				row[i] = contact.UpdatedAt.Format("2006-01-02 15:04:05")
-			default:
-				row[i] = ""
-			}
-		}
-
-		if err := writer.Write(row); err != nil {
+		if err := writer.Write(contactExportRow(&contact, fields)); err != nil {
 			return nil, fmt.Errorf("failed to write CSV row: %w", err)
 		}
 	}
@@ -408,33 +454,55 @@ func (s *BulkService) ExportContactsToCSV(request ExportRequest) ([]byte, error)
 	return buf.Bytes(), nil
 }
 
-// ExportContactsToJSON exports contacts to JSON format
-func (s *BulkService) ExportContactsToJSON(request ExportRequest) ([]byte, error) {
-	// Build query parameters
-	params := repository.ContactListParams{
-		Page:  1,
-		Limit: request.Limit,
-		Sort:  request.SortBy,
-		Order: request.SortOrder,
-	}
+// Fields exported when the request does not name any
+var defaultExportFields = []string{"FirstName", "LastName", "Email", "Phone", "Company", "JobTitle", "Status", "Notes", "CreatedAt", "UpdatedAt"}
 
-	if params.Limit == 0 {
-		params.Limit = 10000 // Default large limit for export
-	}
+// contactExportRow returns the values of the requested fields for a contact
+func contactExportRow(contact *models.Contact, fields []string) []string {
+	row := make([]string, len(fields))
 
-	// Apply filters
-	if request.Filters != nil {
-		if status, ok := request.Filters["status"].(string); ok {
-			params.Status = status
+	for i, field := range fields {
+		switch strings.ToLower(field) {
+		case "firstname":
+			row[i] = contact.FirstName
+		case "lastname":
+			if contact.LastName != nil {
+				row[i] = *contact.LastName
+			}
+		case "email":
+			row[i] = contact.Email
+		case "phone":
+			if contact.Phone != nil {
+				row[i] = *contact.Phone
+			}
+		case "company":
+			if contact.Company != nil {
+				row[i] = *contact.Company
+			}
+		case "jobtitle":
+			if contact.JobTitle != nil {
+				row[i] = *contact.JobTitle
+			}
+		case "status":
+			row[i] = string(contact.Status)
+		case "notes":
+			if contact.Notes != nil {
+				row[i] = *contact.Notes
+			}
+		case "createdat":
+			row[i] = contact.CreatedAt.Format("2006-01-02 15:04:05")
+		case "updatedat":
+			row[i] = contact.UpdatedAt.Format("2006-01-02 15:04:05")
+		default:
+			row[i] = ""
 		}
 	}
 
-	// Get contacts
-	contacts, total, err := s.contactRepo.List(params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve contacts: %w", err)
-	}
+	return row
+}
 
+// marshalContactsJSON wraps contacts in the JSON export envelope
+func marshalContactsJSON(contacts []models.Contact, total int64, request ExportRequest) ([]byte, error) {
 	// Create export structure
 	exportData := map[string]interface{}{
 		"contacts": contacts,
@@ -488,6 +556,7 @@ func (s *BulkService) BulkUpdateContacts(request BulkUpdateRequest) (*BulkUpdate
 		}
 
 		// Apply updates
+		before := *contact
 		updated := false
 		if status, ok := request.Updates["status"].(string); ok && isValidStatus(status) {
 			contact.Status = models.ContactStatus(status)
@@ -528,6 +597,8 @@ func (s *BulkService) BulkUpdateContacts(request BulkUpdateRequest) (*BulkUpdate
 			continue
 		}
 
+		s.recordHistory(&before, contact, models.ChangeSourceBulk, "Bulk update")
+
 		result.UpdatedIDs = append(result.UpdatedIDs, contactID)
 		result.UpdatedCount++
 	}
@@ -536,6 +607,33 @@ func (s *BulkService) BulkUpdateContacts(request BulkUpdateRequest) (*BulkUpdate
 	return result, nil
 }
 
+// importErrorFromField converts a validation rule violation into a row-level import error
+func importErrorFromField(rowNum int, fieldErr apperrors.FieldError) BulkImportError {
+	importErr := BulkImportError{
+		Row:      rowNum,
+		Field:    fieldErr.Field,
+		Message:  "validation: " + fieldErr.Message,
+		Severity: fieldErr.Severity,
+	}
+	if fieldErr.Value != nil {
+		importErr.Value = fmt.Sprint(fieldErr.Value)
+	}
+	return importErr
+}
+
+// recordHistory records field changes when a history repository is configured
+func (s *BulkService) recordHistory(before, after *models.Contact, source, reason string) {
+	if s.historyRepo == nil {
+		return
+	}
+	if err := s.historyRepo.RecordChanges(before, after, nil, source, reason); err != nil {
+		logger.Error("Failed to record contact field history", err, map[string]interface{}{
+			"contact_id": after.ID,
+			"source":     source,
+		})
+	}
+}
+
 // BulkDeleteContacts performs bulk deletion of contacts
 func (s *BulkService) BulkDeleteContacts(contactIDs []uint) (*BulkUpdateResult, error) {
 	startTime := time.Now()
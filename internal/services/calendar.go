@@ -0,0 +1,382 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/ical"
+	"contact-service/pkg/logger"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Appointments included in a calendar feed, relative to now
+const (
+	calendarFeedPast   = 90 * 24 * time.Hour
+	calendarFeedFuture = 365 * 24 * time.Hour
+)
+
+// CalendarConfig configures iCalendar feeds and invitations
+type CalendarConfig struct {
+	UIDDomain   string // Right-hand side of event UIDs; changing it makes clients duplicate every event
+	FeedBaseURL string // Base of the feed subscription URLs
+	ProdID      string
+}
+
+// LoadCalendarConfig reads the calendar configuration from the environment.
+// UIDs use CALENDAR_UID_DOMAIN, falling back to the host of APP_URL; feeds are served under CALENDAR_FEED_BASE_URL.
+func LoadCalendarConfig() CalendarConfig {
+	appURL := strings.TrimRight(os.Getenv("APP_URL"), "/")
+	config := CalendarConfig{
+		UIDDomain:   os.Getenv("CALENDAR_UID_DOMAIN"),
+		FeedBaseURL: os.Getenv("CALENDAR_FEED_BASE_URL"),
+		ProdID:      "-//Mejona//Contact Service//EN",
+	}
+	if config.UIDDomain == "" {
+		if parsed, err := url.Parse(appURL); err == nil && parsed.Hostname() != "" {
+			config.UIDDomain = parsed.Hostname()
+		} else {
+			config.UIDDomain = "contact-service"
+		}
+	}
+	if config.FeedBaseURL == "" {
+		config.FeedBaseURL = appURL + "/api/v1/public/calendar/feeds"
+	}
+	config.FeedBaseURL = strings.TrimRight(config.FeedBaseURL, "/")
+	return config
+}
+
+// AppointmentUID returns the stable iCalendar UID of an appointment
+func (c CalendarConfig) AppointmentUID(appointmentID uint) string {
+	return fmt.Sprintf("appointment-%d@%s", appointmentID, c.UIDDomain)
+}
+
+// appointmentEvent converts an appointment into a VEVENT
+func (c CalendarConfig) appointmentEvent(appointment *models.AppointmentResponse, organizer *ical.Attendee) ical.Event {
+	event := ical.Event{
+		UID:          c.AppointmentUID(appointment.ID),
+		Sequence:     appointment.CalendarSequence,
+		Start:        appointment.ScheduledDate,
+		End:          appointment.ScheduledDate.Add(time.Duration(appointment.DurationMinutes) * time.Minute),
+		Summary:      appointment.Title,
+		Status:       ical.StatusConfirmed,
+		Organizer:    organizer,
+		LastModified: appointment.UpdatedAt,
+	}
+	switch appointment.Status {
+	case models.AppointmentCancelled:
+		event.Status = ical.StatusCancelled
+	case models.AppointmentRequested:
+		event.Status = ical.StatusTentative
+	}
+
+	var description []string
+	if appointment.Description != nil && *appointment.Description != "" {
+		description = append(description, *appointment.Description)
+	}
+	if appointment.MeetingLink != nil && *appointment.MeetingLink != "" {
+		description = append(description, "Join: "+*appointment.MeetingLink)
+		event.URL = *appointment.MeetingLink
+	}
+	if appointment.PhoneNumber != nil && *appointment.PhoneNumber != "" && appointment.MeetingType == models.MeetingPhoneCall {
+		description = append(description, "Phone: "+*appointment.PhoneNumber)
+	}
+	event.Description = strings.Join(description, "\n\n")
+
+	switch {
+	case appointment.Location != nil && *appointment.Location != "":
+		event.Location = *appointment.Location
+	case appointment.MeetingLink != nil:
+		event.Location = *appointment.MeetingLink
+	}
+
+	seen := make(map[string]bool)
+	if organizer != nil {
+		seen[strings.ToLower(organizer.Email)] = true
+	}
+	addAttendee := func(name, email string) {
+		if email == "" || seen[strings.ToLower(email)] {
+			return
+		}
+		seen[strings.ToLower(email)] = true
+		event.Attendees = append(event.Attendees, ical.Attendee{Name: name, Email: email, RSVP: true})
+	}
+	if appointment.Contact != nil {
+		addAttendee(appointment.Contact.FullName, appointment.Contact.Email)
+	}
+	for _, attendee := range appointment.Attendees {
+		if attendee.Email != nil {
+			addAttendee(attendee.Name, *attendee.Email)
+		}
+	}
+	return event
+}
+
+// calendarOrganizer returns the host of appointments as an iCalendar organizer, or nil when unknown
+func calendarOrganizer(db *gorm.DB, userID uint) *ical.Attendee {
+	var user models.AdminUser
+	if err := db.Select("id", "name", "email").First(&user, userID).Error; err != nil {
+		return nil
+	}
+	return &ical.Attendee{Name: user.Name, Email: user.Email}
+}
+
+// CalendarService manages per-user iCalendar feed subscriptions and renders the feeds
+type CalendarService struct {
+	db         *gorm.DB
+	scheduling *SchedulingService
+	config     CalendarConfig
+}
+
+// NewCalendarService creates a new calendar service
+func NewCalendarService(db *gorm.DB, scheduling *SchedulingService, config CalendarConfig) *CalendarService {
+	return &CalendarService{db: db, scheduling: scheduling, config: config}
+}
+
+// GetFeed returns a user's feed subscription, creating it on first use. Only a new feed comes with
+// its URL; the token of an existing one is not stored.
+func (s *CalendarService) GetFeed(userID uint) (*models.CalendarFeedResponse, error) {
+	var feed models.CalendarFeed
+	err := s.db.Where("user_id = ?", userID).First(&feed).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.RotateFeed(userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar feed: %v", err)
+	}
+	return s.feedResponse(&feed), nil
+}
+
+// RotateFeed issues a new feed token for a user; the previous URL stops working
+func (s *CalendarService) RotateFeed(userID uint) (*models.CalendarFeedResponse, error) {
+	token, err := newFeedToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var feed models.CalendarFeed
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ?", userID).First(&feed).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			feed = models.CalendarFeed{UserID: userID, TokenHash: models.HashCalendarFeedToken(token)}
+			return tx.Create(&feed).Error
+		}
+		if err != nil {
+			return err
+		}
+		feed.TokenHash = models.HashCalendarFeedToken(token)
+		feed.LastAccessedAt = nil
+		return tx.Save(&feed).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue calendar feed: %v", err)
+	}
+
+	logger.LogSecurityEvent("calendar_feed_issued", &userID, "", map[string]interface{}{
+		"feed_id": feed.ID,
+	})
+	response := s.feedResponse(&feed)
+	response.URL = s.config.FeedBaseURL + "/" + token + ".ics"
+	return response, nil
+}
+
+// RevokeFeed deletes a user's feed; a new one can be issued later
+func (s *CalendarService) RevokeFeed(userID uint) error {
+	result := s.db.Where("user_id = ?", userID).Delete(&models.CalendarFeed{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke calendar feed: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("calendar feed not found")
+	}
+
+	logger.LogSecurityEvent("calendar_feed_revoked", &userID, "", nil)
+	return nil
+}
+
+// RenderFeed renders the feed identified by a token: the owner's appointments from 90 days ago to a
+// year ahead, with cancelled appointments marked CANCELLED so subscribed clients drop them. Feeds of
+// deactivated or deleted users are not found.
+func (s *CalendarService) RenderFeed(token string) ([]byte, error) {
+	var feed models.CalendarFeed
+	if err := s.db.Joins("JOIN admin_users ON admin_users.id = calendar_feeds.user_id").
+		Where("calendar_feeds.token_hash = ? AND admin_users.is_active = ? AND admin_users.deleted_at IS NULL", models.HashCalendarFeedToken(token), true).
+		First(&feed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("calendar feed not found")
+		}
+		return nil, fmt.Errorf("failed to get calendar feed: %v", err)
+	}
+
+	now := time.Now()
+	appointments, err := s.scheduling.GetUserAppointments(feed.UserID, now.Add(-calendarFeedPast), now.Add(calendarFeedFuture), "")
+	if err != nil {
+		return nil, err
+	}
+
+	organizer := calendarOrganizer(s.db, feed.UserID)
+	calendar := &ical.Calendar{
+		ProdID:  s.config.ProdID,
+		Method:  ical.MethodPublish,
+		Name:    "Appointments",
+		Refresh: time.Hour,
+	}
+	for i := range appointments {
+		event := s.config.appointmentEvent(&appointments[i], organizer)
+		event.Stamp = now
+		calendar.Events = append(calendar.Events, event)
+	}
+
+	s.db.Model(&feed).UpdateColumn("last_accessed_at", now)
+	return calendar.Bytes(), nil
+}
+
+func (s *CalendarService) feedResponse(feed *models.CalendarFeed) *models.CalendarFeedResponse {
+	return &models.CalendarFeedResponse{
+		LastAccessedAt: feed.LastAccessedAt,
+		CreatedAt:      feed.CreatedAt,
+		UpdatedAt:      feed.UpdatedAt,
+	}
+}
+
+func newFeedToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate feed token: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// CalendarInviter emails iTIP invitations (METHOD:REQUEST) and cancellations (METHOD:CANCEL) for
+// appointments to the contact and attendees, as .ics attachments. Invites go through the outbound
+// email queue, which retries failed deliveries and records their status on the communication.
+type CalendarInviter struct {
+	db     *gorm.DB
+	email  *EmailService
+	config CalendarConfig
+}
+
+// NewCalendarInviter creates a new calendar inviter
+func NewCalendarInviter(db *gorm.DB, email *EmailService, config CalendarConfig) *CalendarInviter {
+	return &CalendarInviter{db: db, email: email, config: config}
+}
+
+// QueueInvite queues the current state of an appointment with the given iTIP method for delivery
+func (i *CalendarInviter) QueueInvite(appointmentID uint, method string) error {
+	var appointment models.Appointment
+	if err := i.db.Preload("Contact").Preload("Attendees").First(&appointment, appointmentID).Error; err != nil {
+		return fmt.Errorf("failed to get appointment: %v", err)
+	}
+	response, err := (&SchedulingService{db: i.db}).buildAppointmentResponse(&appointment)
+	if err != nil {
+		return err
+	}
+
+	from := &ical.Attendee{Name: i.email.config.FromName, Email: i.email.config.FromEmail}
+	organizer := calendarOrganizer(i.db, appointment.AssignedTo)
+	if organizer == nil {
+		organizer = from
+	}
+	event := i.config.appointmentEvent(response, organizer)
+	if method == ical.MethodCancel {
+		event.Status = ical.StatusCancelled
+	}
+	if len(event.Attendees) == 0 {
+		return nil
+	}
+
+	calendar := &ical.Calendar{ProdID: i.config.ProdID, Method: method, Events: []ical.Event{event}}
+	subject := inviteSubject(method, &appointment)
+	metadata := models.JSONMap{
+		emailMetadataAppointmentID:  strconv.FormatUint(uint64(appointment.ID), 10),
+		emailMetadataCalendarMethod: method,
+		emailMetadataCalendar:       string(calendar.Bytes()),
+	}
+	if organizer.Email != from.Email {
+		metadata[emailMetadataReplyTo] = organizer.Email
+	}
+	var cc []string
+	for _, attendee := range event.Attendees[1:] {
+		cc = append(cc, attendee.Email)
+	}
+
+	communication := &models.ContactCommunication{
+		ContactID:    appointment.ContactID,
+		Subject:      &subject,
+		Content:      nonEmpty(inviteBody(method, &appointment)),
+		PlainContent: nonEmpty(inviteBody(method, &appointment)),
+		ToEmail:      &event.Attendees[0].Email,
+		CCEmails:     stringsToJSONArray(cc),
+		SentBy:       &appointment.AssignedTo,
+		AssignedTo:   &appointment.AssignedTo,
+		Metadata:     metadata,
+	}
+	activity := &models.ContactActivity{
+		Title:       truncate("Calendar invite: "+subject, 255),
+		PerformedBy: appointment.AssignedTo,
+		AssignedTo:  &appointment.AssignedTo,
+	}
+	if err := i.email.queueCommunication(communication, activity); err != nil {
+		return fmt.Errorf("failed to queue calendar invite: %v", err)
+	}
+
+	now := time.Now()
+	if method == ical.MethodRequest {
+		if err := i.db.Model(&appointment).UpdateColumns(map[string]interface{}{
+			"confirmation_sent":    true,
+			"confirmation_sent_at": now,
+		}).Error; err != nil {
+			logger.Error("Failed to record appointment confirmation", err, map[string]interface{}{
+				"appointment_id": appointment.ID,
+			})
+		}
+	}
+	if err := i.db.Model(&models.AppointmentAttendee{}).Where("appointment_id = ? AND email IS NOT NULL", appointment.ID).
+		UpdateColumns(map[string]interface{}{"invitation_sent": true, "invitation_sent_at": now}).Error; err != nil {
+		logger.Error("Failed to record attendee invitations", err, map[string]interface{}{
+			"appointment_id": appointment.ID,
+		})
+	}
+
+	logger.Info("Calendar invite queued", map[string]interface{}{
+		"appointment_id":   appointment.ID,
+		"communication_id": communication.ID,
+		"method":           method,
+		"sequence":         event.Sequence,
+		"recipients":       len(event.Attendees),
+	})
+	return nil
+}
+
+func inviteSubject(method string, appointment *models.Appointment) string {
+	when := appointmentLocalTime(appointment).Format("Mon Jan 2 15:04 MST")
+	if method == ical.MethodCancel {
+		return fmt.Sprintf("Cancelled: %s @ %s", appointment.Title, when)
+	}
+	if appointment.CalendarSequence > 0 {
+		return fmt.Sprintf("Updated: %s @ %s", appointment.Title, when)
+	}
+	return fmt.Sprintf("Invitation: %s @ %s", appointment.Title, when)
+}
+
+func inviteBody(method string, appointment *models.Appointment) string {
+	when := appointmentLocalTime(appointment).Format("Monday, January 2 at 15:04 MST")
+	if method == ical.MethodCancel {
+		return fmt.Sprintf("%s on %s has been cancelled.\n", appointment.Title, when)
+	}
+	body := fmt.Sprintf("%s\n%s (%d minutes)\n", appointment.Title, when, appointment.DurationMinutes)
+	if appointment.MeetingLink != nil && *appointment.MeetingLink != "" {
+		body += "\nJoin: " + *appointment.MeetingLink + "\n"
+	}
+	if appointment.Location != nil && *appointment.Location != "" {
+		body += "\nLocation: " + *appointment.Location + "\n"
+	}
+	return body + "\nThe attached invitation adds this appointment to your calendar.\n"
+}
@@ -0,0 +1,615 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/caldav"
+	"contact-service/pkg/ical"
+	"contact-service/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxSyncErrors caps the errors recorded for one sync run
+const maxSyncErrors = 20
+
+// ExternalBusyTime is a span during which a user is busy on an external calendar
+type ExternalBusyTime struct {
+	UID   string
+	Start time.Time
+	End   time.Time
+}
+
+// CalendarProvider syncs with one kind of external calendar on behalf of an integration
+type CalendarProvider interface {
+	// PutEvent creates or replaces the event stored under name and returns its href and version tag
+	PutEvent(ctx context.Context, integration *models.CalendarIntegration, name string, data []byte) (string, string, error)
+	// DeleteEvent removes a previously pushed event; an event that is already gone is not an error
+	DeleteEvent(ctx context.Context, integration *models.CalendarIntegration, href string) error
+	// BusyTimes returns the spans that block time between two instants, with recurring events expanded
+	BusyTimes(ctx context.Context, integration *models.CalendarIntegration, from, to time.Time) ([]ExternalBusyTime, error)
+	// CheckTarget returns an error if the integration's calendar may not be connected to
+	CheckTarget(ctx context.Context, integration *models.CalendarIntegration) error
+}
+
+// CalDAVProvider syncs with a CalDAV calendar collection using basic authentication
+type CalDAVProvider struct {
+	Timeout      time.Duration
+	AllowedHosts []string // Internal hosts, addresses or CIDR ranges calendars may be on
+}
+
+func (p *CalDAVProvider) client(integration *models.CalendarIntegration) (*caldav.Client, error) {
+	username, _ := integration.Settings["username"].(string)
+	return caldav.New(caldav.Config{
+		CalendarURL:  integration.ExternalID,
+		Username:     username,
+		Password:     stringValue(integration.AccessToken),
+		Timeout:      p.Timeout,
+		AllowedHosts: p.AllowedHosts,
+	})
+}
+
+// CheckTarget implements CalendarProvider. Calendars on loopback, private or link-local addresses are
+// refused unless allowed.
+func (p *CalDAVProvider) CheckTarget(ctx context.Context, integration *models.CalendarIntegration) error {
+	client, err := p.client(integration)
+	if err != nil {
+		return err
+	}
+	return client.CheckAddress(ctx)
+}
+
+// PutEvent implements CalendarProvider
+func (p *CalDAVProvider) PutEvent(ctx context.Context, integration *models.CalendarIntegration, name string, data []byte) (string, string, error) {
+	client, err := p.client(integration)
+	if err != nil {
+		return "", "", err
+	}
+	return client.Put(ctx, name, data)
+}
+
+// DeleteEvent implements CalendarProvider
+func (p *CalDAVProvider) DeleteEvent(ctx context.Context, integration *models.CalendarIntegration, href string) error {
+	client, err := p.client(integration)
+	if err != nil {
+		return err
+	}
+	return client.Delete(ctx, href)
+}
+
+// BusyTimes implements CalendarProvider. Transparent and cancelled events do not block time.
+func (p *CalDAVProvider) BusyTimes(ctx context.Context, integration *models.CalendarIntegration, from, to time.Time) ([]ExternalBusyTime, error) {
+	client, err := p.client(integration)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := client.Events(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var busy []ExternalBusyTime
+	for _, object := range objects {
+		events, err := ical.Parse(object.Data, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", object.Href, err)
+		}
+		for _, event := range events {
+			if event.Transparent || event.Status == ical.StatusCancelled || !event.End.After(event.Start) {
+				continue
+			}
+			busy = append(busy, ExternalBusyTime{UID: event.UID, Start: event.Start, End: event.End})
+		}
+	}
+	return busy, nil
+}
+
+// CalendarSyncConfig configures calendar sync
+type CalendarSyncConfig struct {
+	Interval time.Duration // How often auto-sync integrations are synced
+	Days     int           // Days ahead that are pushed and pulled
+	// Internal hosts, addresses or CIDR ranges that calendars may be on; others are refused
+	AllowedHosts []string
+}
+
+// LoadCalendarSyncConfig reads the calendar sync configuration from the environment
+func LoadCalendarSyncConfig() CalendarSyncConfig {
+	config := CalendarSyncConfig{
+		Interval: 15 * time.Minute,
+		Days:     60,
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("CALENDAR_SYNC_INTERVAL_MINUTES")); err == nil && minutes > 0 {
+		config.Interval = time.Duration(minutes) * time.Minute
+	}
+	if days, err := strconv.Atoi(os.Getenv("CALENDAR_SYNC_DAYS")); err == nil && days > 0 {
+		config.Days = days
+	}
+	if hosts := os.Getenv("CALDAV_ALLOWED_HOSTS"); hosts != "" {
+		config.AllowedHosts = strings.Split(hosts, ",")
+	}
+	return config
+}
+
+// CalendarSyncService manages calendar_integrations and syncs them: appointments assigned to the
+// user are pushed to the external calendar, and busy time on it is pulled into calendar_busy_blocks,
+// which slot search treats like appointments.
+type CalendarSyncService struct {
+	db         *gorm.DB
+	scheduling *SchedulingService
+	calendar   CalendarConfig
+	config     CalendarSyncConfig
+	providers  map[string]CalendarProvider
+	startOnce  sync.Once
+}
+
+// NewCalendarSyncService creates a calendar sync service; providers are keyed by provider name
+func NewCalendarSyncService(db *gorm.DB, scheduling *SchedulingService, calendar CalendarConfig, config CalendarSyncConfig, providers map[string]CalendarProvider) *CalendarSyncService {
+	return &CalendarSyncService{
+		db:         db,
+		scheduling: scheduling,
+		calendar:   calendar,
+		config:     config,
+		providers:  providers,
+	}
+}
+
+// ListIntegrations returns a user's calendar integrations
+func (s *CalendarSyncService) ListIntegrations(userID uint) ([]models.CalendarIntegration, error) {
+	var integrations []models.CalendarIntegration
+	if err := s.db.Where("user_id = ? AND deleted_at IS NULL", userID).Order("id ASC").Find(&integrations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list calendar integrations: %v", err)
+	}
+	return integrations, nil
+}
+
+// GetIntegration returns a calendar integration by ID
+func (s *CalendarSyncService) GetIntegration(id uint) (*models.CalendarIntegration, error) {
+	var integration models.CalendarIntegration
+	if err := s.db.Where("id = ? AND deleted_at IS NULL", id).First(&integration).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("calendar integration not found")
+		}
+		return nil, fmt.Errorf("failed to get calendar integration: %v", err)
+	}
+	return &integration, nil
+}
+
+// CreateIntegration connects an external calendar for a user
+func (s *CalendarSyncService) CreateIntegration(userID uint, req *models.CalendarIntegrationRequest) (*models.CalendarIntegration, error) {
+	provider, ok := s.providers[req.Provider]
+	if !ok {
+		return nil, fmt.Errorf("invalid provider: %s", req.Provider)
+	}
+
+	integration := &models.CalendarIntegration{
+		UserID:         userID,
+		Provider:       req.Provider,
+		ExternalID:     req.CalendarURL,
+		CalendarName:   req.CalendarName,
+		IsEnabled:      true,
+		SyncDirection:  models.CalendarSyncBidirectional,
+		AutoSync:       true,
+		LastSyncStatus: models.CalendarSyncPending,
+		Settings:       models.JSONMap{"username": req.Username},
+		CreatedBy:      &userID,
+		UpdatedBy:      &userID,
+	}
+	if req.Password != "" {
+		integration.AccessToken = &req.Password
+	}
+	if req.SyncDirection != nil {
+		integration.SyncDirection = *req.SyncDirection
+	}
+	if req.AutoSync != nil {
+		integration.AutoSync = *req.AutoSync
+	}
+	if err := s.checkTarget(provider, integration); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(integration).Error; err != nil {
+			return err
+		}
+		// gorm skips false for columns with a default, so an opt-out has to be written separately
+		if !integration.AutoSync {
+			return tx.Model(integration).Update("auto_sync", false).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calendar integration: %v", err)
+	}
+
+	logger.LogSecurityEvent("calendar_integration_created", &userID, "", map[string]interface{}{
+		"integration_id": integration.ID,
+		"provider":       integration.Provider,
+	})
+	return integration, nil
+}
+
+// UpdateIntegration changes an integration. Moving it to another calendar forgets what was pushed to
+// and pulled from the old one.
+func (s *CalendarSyncService) UpdateIntegration(id uint, req *models.CalendarIntegrationUpdateRequest, userID uint) (*models.CalendarIntegration, error) {
+	integration, err := s.GetIntegration(id)
+	if err != nil {
+		return nil, err
+	}
+
+	moved := req.CalendarURL != nil && *req.CalendarURL != integration.ExternalID
+	if req.CalendarURL != nil {
+		integration.ExternalID = *req.CalendarURL
+	}
+	if req.CalendarName != nil {
+		integration.CalendarName = *req.CalendarName
+	}
+	if req.Username != nil {
+		if integration.Settings == nil {
+			integration.Settings = models.JSONMap{}
+		}
+		integration.Settings["username"] = *req.Username
+	}
+	if req.Password != nil {
+		integration.AccessToken = req.Password
+	}
+	if req.SyncDirection != nil {
+		integration.SyncDirection = *req.SyncDirection
+	}
+	if req.AutoSync != nil {
+		integration.AutoSync = *req.AutoSync
+	}
+	if req.IsEnabled != nil {
+		integration.IsEnabled = *req.IsEnabled
+	}
+	if moved {
+		integration.LastSyncStatus = models.CalendarSyncPending
+		integration.LastSyncAt = nil
+		integration.SyncErrors = nil
+	}
+	integration.UpdatedBy = &userID
+	if provider, ok := s.providers[integration.Provider]; ok && moved {
+		if err := s.checkTarget(provider, integration); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if moved {
+			if err := tx.Where("integration_id = ?", id).Delete(&models.CalendarSyncEvent{}).Error; err != nil {
+				return err
+			}
+		}
+		if moved || !integration.Pulls() {
+			if err := tx.Where("integration_id = ?", id).Delete(&models.CalendarBusyBlock{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(integration).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update calendar integration: %v", err)
+	}
+	return integration, nil
+}
+
+// checkTarget refuses calendars the server may not connect to
+func (s *CalendarSyncService) checkTarget(provider CalendarProvider, integration *models.CalendarIntegration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return provider.CheckTarget(ctx, integration)
+}
+
+// DeleteIntegration disconnects an external calendar. Events pushed to it are removed on a
+// best-effort basis; failures are logged and do not block the disconnect.
+func (s *CalendarSyncService) DeleteIntegration(id uint, userID uint) error {
+	integration, err := s.GetIntegration(id)
+	if err != nil {
+		return err
+	}
+
+	if provider, ok := s.providers[integration.Provider]; ok && integration.Pushes() {
+		var pushed []models.CalendarSyncEvent
+		s.db.Where("integration_id = ?", id).Find(&pushed)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		for _, event := range pushed {
+			if err := provider.DeleteEvent(ctx, integration, event.RemoteHref); err != nil {
+				logger.Error("Failed to remove pushed calendar event", err, map[string]interface{}{
+					"integration_id": id,
+					"appointment_id": event.AppointmentID,
+				})
+			}
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("integration_id = ?", id).Delete(&models.CalendarSyncEvent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("integration_id = ?", id).Delete(&models.CalendarBusyBlock{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(integration).Updates(map[string]interface{}{
+			"deleted_at":   time.Now(),
+			"access_token": nil,
+			"updated_by":   userID,
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar integration: %v", err)
+	}
+
+	logger.LogSecurityEvent("calendar_integration_deleted", &userID, "", map[string]interface{}{
+		"integration_id": id,
+	})
+	return nil
+}
+
+// Start launches periodic sync of enabled auto-sync integrations. Calling it again has no effect.
+func (s *CalendarSyncService) Start() {
+	s.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(time.Minute)
+			defer ticker.Stop()
+			for {
+				s.SyncDue()
+				<-ticker.C
+			}
+		}()
+
+		logger.Info("Calendar sync started", map[string]interface{}{
+			"interval": s.config.Interval.String(),
+			"days":     s.config.Days,
+		})
+	})
+}
+
+// SyncDue syncs every enabled auto-sync integration whose last sync is older than the interval.
+// Each integration is claimed with a conditional update on last_sync_at, so several instances can
+// run this concurrently.
+func (s *CalendarSyncService) SyncDue() {
+	now := time.Now()
+	var due []models.CalendarIntegration
+	if err := s.db.Where("is_enabled = ? AND auto_sync = ? AND deleted_at IS NULL", true, true).
+		Where("last_sync_at IS NULL OR last_sync_at < ?", now.Add(-s.config.Interval)).
+		Find(&due).Error; err != nil {
+		logger.Error("Failed to find calendar integrations to sync", err, nil)
+		return
+	}
+
+	for i := range due {
+		integration := &due[i]
+		claim := s.db.Model(&models.CalendarIntegration{}).Where("id = ?", integration.ID)
+		if integration.LastSyncAt == nil {
+			claim = claim.Where("last_sync_at IS NULL")
+		} else {
+			claim = claim.Where("last_sync_at = ?", *integration.LastSyncAt)
+		}
+		if result := claim.UpdateColumn("last_sync_at", now); result.Error != nil || result.RowsAffected == 0 {
+			continue // Claimed by another instance
+		}
+		s.sync(integration)
+	}
+}
+
+// SyncIntegration syncs an integration now and returns its updated state
+func (s *CalendarSyncService) SyncIntegration(id uint) (*models.CalendarIntegration, error) {
+	integration, err := s.GetIntegration(id)
+	if err != nil {
+		return nil, err
+	}
+	if !integration.IsEnabled {
+		return nil, fmt.Errorf("cannot sync a disabled calendar integration")
+	}
+	s.sync(integration)
+	return s.GetIntegration(id)
+}
+
+// sync pushes and pulls one integration and records the outcome on it
+func (s *CalendarSyncService) sync(integration *models.CalendarIntegration) {
+	provider, ok := s.providers[integration.Provider]
+	if !ok {
+		s.recordSync(integration, models.CalendarSyncFailed, []string{"unsupported provider: " + integration.Provider})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	status := models.CalendarSyncSuccess
+	var problems []string
+	if integration.Pushes() {
+		failures, attempted, err := s.push(ctx, provider, integration)
+		problems = append(problems, failures...)
+		switch {
+		case err != nil:
+			status = models.CalendarSyncFailed
+			problems = append(problems, "push: "+err.Error())
+		case attempted > 0 && len(failures) == attempted:
+			status = models.CalendarSyncFailed
+		case len(failures) > 0:
+			status = models.CalendarSyncPartial
+		}
+	}
+	if integration.Pulls() {
+		if err := s.pull(ctx, provider, integration); err != nil {
+			status = models.CalendarSyncFailed
+			problems = append(problems, "pull: "+err.Error())
+		}
+	}
+	s.recordSync(integration, status, problems)
+}
+
+// push writes the user's appointments in the sync window to the external calendar. Appointments are
+// re-sent when their calendar sequence or update time moved past the last push, and removed once
+// cancelled, deleted or reassigned. It returns per-appointment failures and the number of writes tried.
+func (s *CalendarSyncService) push(ctx context.Context, provider CalendarProvider, integration *models.CalendarIntegration) ([]string, int, error) {
+	now := time.Now()
+	appointments, err := s.scheduling.GetUserAppointments(integration.UserID, now.Add(-24*time.Hour), now.AddDate(0, 0, s.config.Days), "")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var pushed []models.CalendarSyncEvent
+	if err := s.db.Where("integration_id = ?", integration.ID).Find(&pushed).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get pushed events: %v", err)
+	}
+	byAppointment := make(map[uint]*models.CalendarSyncEvent, len(pushed))
+	for i := range pushed {
+		byAppointment[pushed[i].AppointmentID] = &pushed[i]
+	}
+
+	var failures []string
+	attempted := 0
+	remove := func(event *models.CalendarSyncEvent) {
+		attempted++
+		if err := provider.DeleteEvent(ctx, integration, event.RemoteHref); err != nil {
+			failures = append(failures, fmt.Sprintf("appointment %d: %v", event.AppointmentID, err))
+			return
+		}
+		s.db.Delete(event)
+	}
+
+	for i := range appointments {
+		appointment := &appointments[i]
+		event := byAppointment[appointment.ID]
+		delete(byAppointment, appointment.ID)
+
+		if appointment.Status == models.AppointmentCancelled {
+			if event != nil {
+				remove(event)
+			}
+			continue
+		}
+		if event != nil && event.SyncedSequence == appointment.CalendarSequence && !appointment.UpdatedAt.After(event.SyncedAt) {
+			continue
+		}
+
+		// Pushed copies carry no attendees: servers with implicit scheduling would otherwise send
+		// them a second invitation on top of the one CalendarInviter emails
+		vevent := s.calendar.appointmentEvent(appointment, nil)
+		vevent.Stamp = now
+		vevent.Attendees = nil
+		calendar := &ical.Calendar{ProdID: s.calendar.ProdID, Events: []ical.Event{vevent}}
+
+		attempted++
+		href, etag, err := provider.PutEvent(ctx, integration, fmt.Sprintf("appointment-%d.ics", appointment.ID), calendar.Bytes())
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("appointment %d: %v", appointment.ID, err))
+			continue
+		}
+
+		if event == nil {
+			event = &models.CalendarSyncEvent{IntegrationID: integration.ID, AppointmentID: appointment.ID}
+		}
+		event.RemoteHref = href
+		event.RemoteETag = nil
+		if etag != "" {
+			event.RemoteETag = &etag
+		}
+		event.SyncedSequence = appointment.CalendarSequence
+		event.SyncedAt = now
+		if err := s.db.Save(event).Error; err != nil {
+			failures = append(failures, fmt.Sprintf("appointment %d: failed to record push: %v", appointment.ID, err))
+		}
+	}
+
+	// Pushed appointments outside the window stay unless they no longer belong on this calendar
+	if len(byAppointment) > 0 {
+		ids := make([]uint, 0, len(byAppointment))
+		for id := range byAppointment {
+			ids = append(ids, id)
+		}
+		var live []uint
+		if err := s.db.Model(&models.Appointment{}).
+			Where("id IN ? AND assigned_to = ? AND deleted_at IS NULL AND status <> ?", ids, integration.UserID, models.AppointmentCancelled).
+			Pluck("id", &live).Error; err != nil {
+			return failures, attempted, fmt.Errorf("failed to check pushed appointments: %v", err)
+		}
+		for _, id := range live {
+			delete(byAppointment, id)
+		}
+		for _, event := range byAppointment {
+			remove(event)
+		}
+	}
+	return failures, attempted, nil
+}
+
+// pull replaces the integration's busy blocks with the busy time on the external calendar,
+// skipping the events this service pushed there itself
+func (s *CalendarSyncService) pull(ctx context.Context, provider CalendarProvider, integration *models.CalendarIntegration) error {
+	now := time.Now()
+	busy, err := provider.BusyTimes(ctx, integration, now.Add(-24*time.Hour), now.AddDate(0, 0, s.config.Days))
+	if err != nil {
+		return err
+	}
+
+	ownSuffix := "@" + s.calendar.UIDDomain
+	var blocks []models.CalendarBusyBlock
+	for _, span := range busy {
+		if strings.HasPrefix(span.UID, "appointment-") && strings.HasSuffix(span.UID, ownSuffix) {
+			continue
+		}
+		blocks = append(blocks, models.CalendarBusyBlock{
+			IntegrationID: integration.ID,
+			UserID:        integration.UserID,
+			ExternalUID:   span.UID,
+			StartTime:     span.Start,
+			EndTime:       span.End,
+		})
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("integration_id = ?", integration.ID).Delete(&models.CalendarBusyBlock{}).Error; err != nil {
+			return fmt.Errorf("failed to clear busy blocks: %v", err)
+		}
+		if len(blocks) > 0 {
+			if err := tx.CreateInBatches(blocks, 200).Error; err != nil {
+				return fmt.Errorf("failed to store busy blocks: %v", err)
+			}
+		}
+		return nil
+	})
+}
+
+// recordSync stores the outcome of a sync; sync_errors lists the problems of the latest run only
+func (s *CalendarSyncService) recordSync(integration *models.CalendarIntegration, status string, problems []string) {
+	now := time.Now()
+	var syncErrors models.JSONArray
+	for i, problem := range problems {
+		if i == maxSyncErrors {
+			syncErrors = append(syncErrors, map[string]interface{}{
+				"at":      now,
+				"message": fmt.Sprintf("%d more errors omitted", len(problems)-maxSyncErrors),
+			})
+			break
+		}
+		syncErrors = append(syncErrors, map[string]interface{}{"at": now, "message": problem})
+	}
+
+	if err := s.db.Model(integration).Updates(map[string]interface{}{
+		"last_sync_at":     now,
+		"last_sync_status": status,
+		"sync_errors":      syncErrors,
+	}).Error; err != nil {
+		logger.Error("Failed to record calendar sync", err, map[string]interface{}{
+			"integration_id": integration.ID,
+		})
+	}
+
+	if status != models.CalendarSyncSuccess {
+		logger.Warn("Calendar sync had errors", map[string]interface{}{
+			"integration_id": integration.ID,
+			"status":         status,
+			"errors":         len(problems),
+		})
+	}
+}
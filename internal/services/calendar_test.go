@@ -0,0 +1,72 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"contact-service/internal/models"
+)
+
+// newCalendarTestService returns a calendar service on an in-memory database with an active user 1
+func newCalendarTestService(t *testing.T) (*CalendarService, *gorm.DB) {
+	db := newTestDB(t,
+		&models.AdminUser{},
+		&models.ContactType{},
+		&models.ContactSource{},
+		&models.Contact{},
+		&models.Appointment{},
+		&models.CalendarFeed{},
+	)
+	require.NoError(t, db.Create(&models.AdminUser{ID: 1, Email: "asha@example.com", Name: "Asha", Role: "sales", IsActive: true}).Error)
+	return NewCalendarService(db, NewSchedulingService(db), CalendarConfig{
+		ProdID:      "-//Test//EN",
+		FeedBaseURL: "https://contacts.example.com/feeds",
+	}), db
+}
+
+// calendarTestToken returns the token in an issued feed URL
+func calendarTestToken(feed *models.CalendarFeedResponse) string {
+	return strings.TrimSuffix(strings.TrimPrefix(feed.URL, "https://contacts.example.com/feeds/"), ".ics")
+}
+
+func TestCalendarFeedStoresOnlyTokenHash(t *testing.T) {
+	service, db := newCalendarTestService(t)
+
+	issued, err := service.GetFeed(1)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(issued.URL, "https://contacts.example.com/feeds/"))
+	token := calendarTestToken(issued)
+
+	var feed models.CalendarFeed
+	require.NoError(t, db.Where("user_id = ?", 1).First(&feed).Error)
+	assert.Equal(t, models.HashCalendarFeedToken(token), feed.TokenHash)
+	assert.NotContains(t, feed.TokenHash, token)
+
+	// The URL of an existing feed cannot be shown again
+	existing, err := service.GetFeed(1)
+	require.NoError(t, err)
+	assert.Empty(t, existing.URL)
+
+	body, err := service.RenderFeed(token)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "BEGIN:VCALENDAR")
+
+	_, err = service.RenderFeed(feed.TokenHash)
+	assert.EqualError(t, err, "calendar feed not found", "the stored hash is not a token")
+}
+
+func TestCalendarFeedOfInactiveUserIsNotFound(t *testing.T) {
+	service, db := newCalendarTestService(t)
+
+	issued, err := service.RotateFeed(1)
+	require.NoError(t, err)
+	token := calendarTestToken(issued)
+
+	require.NoError(t, db.Model(&models.AdminUser{}).Where("id = ?", 1).Update("is_active", false).Error)
+	_, err = service.RenderFeed(token)
+	assert.EqualError(t, err, "calendar feed not found")
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"contact-service/internal/models"
+	"contact-service/internal/repository"
 	"contact-service/pkg/database"
 	"contact-service/pkg/logger"
 	"encoding/json"
@@ -75,8 +76,13 @@ type AdvancedSearchCriteria struct {
 	IsHighPriority      *bool
 }
 
-// CreateContact creates a new contact
+// CreateContact creates a new contact received through the REST API
 func (s *ContactService) CreateContact(req *models.ContactRequest, createdBy *uint) (*models.Contact, error) {
+	return s.CreateContactFromChannel(req, createdBy, models.ValidationChannelAPI)
+}
+
+// CreateContactFromChannel creates a new contact, applying the validation rules for the given channel
+func (s *ContactService) CreateContactFromChannel(req *models.ContactRequest, createdBy *uint, channel models.ValidationChannel) (*models.Contact, error) {
 	// Validate contact type and source exist
 	if err := s.validateContactTypeAndSource(req.ContactTypeID, req.ContactSourceID); err != nil {
 		return nil, err
@@ -151,6 +157,10 @@ func (s *ContactService) CreateContact(req *models.ContactRequest, createdBy *ui
 		contact.GDPRConsent = *req.GDPRConsent
 	}
 
+	if err := s.validateContact(contact, channel); err != nil {
+		return nil, err
+	}
+
 	// Save to database
 	if err := s.db.Create(contact).Error; err != nil {
 		logger.Error("Failed to create contact", err, map[string]interface{}{
@@ -159,16 +169,18 @@ func (s *ContactService) CreateContact(req *models.ContactRequest, createdBy *ui
 		return nil, fmt.Errorf("failed to create contact: %v", err)
 	}
 
+	recordContactHistory(s.db, nil, contact, createdBy, models.ChangeSourceAPI, "Contact created")
+
 	// Log activity
 	s.logContactActivity(contact.ID, "contact_created", map[string]interface{}{
-		"source":      "api",
+		"source":      string(channel),
 		"created_by":  createdBy,
 		"contact_type": req.ContactTypeID,
 		"source_id":   req.ContactSourceID,
 	})
 
 	// Calculate initial lead score
-	if err := s.calculateLeadScore(contact.ID); err != nil {
+	if err := s.calculateLeadScore(contact.ID, "Initial lead score", createdBy); err != nil {
 		logger.Warn("Failed to calculate initial lead score", map[string]interface{}{
 			"contact_id": contact.ID,
 			"error":      err.Error(),
@@ -201,8 +213,8 @@ func (s *ContactService) UpdateContact(id uint, req *models.ContactRequest, upda
 		return nil, err
 	}
 
-	// Store original values for activity logging
-	_ = contact.Status // originalStatus
+	// Store original values for activity logging and field history
+	before := *contact
 	originalAssignedTo := contact.AssignedTo
 
 	// Validate contact type and source if changed
@@ -275,6 +287,10 @@ func (s *ContactService) UpdateContact(id uint, req *models.ContactRequest, upda
 		contact.GDPRConsent = *req.GDPRConsent
 	}
 
+	if err := s.validateContact(contact, models.ValidationChannelAPI); err != nil {
+		return nil, err
+	}
+
 	// Save to database
 	if err := s.db.Save(contact).Error; err != nil {
 		logger.Error("Failed to update contact", err, map[string]interface{}{
@@ -284,6 +300,8 @@ func (s *ContactService) UpdateContact(id uint, req *models.ContactRequest, upda
 		return nil, fmt.Errorf("failed to update contact: %v", err)
 	}
 
+	recordContactHistory(s.db, &before, contact, updatedBy, models.ChangeSourceAPI, "Contact updated")
+
 	// Log activities for significant changes
 	if originalAssignedTo != contact.AssignedTo {
 		s.logContactActivity(contact.ID, "assignment_changed", map[string]interface{}{
@@ -294,7 +312,7 @@ func (s *ContactService) UpdateContact(id uint, req *models.ContactRequest, upda
 	}
 
 	// Recalculate lead score if relevant fields changed
-	if err := s.calculateLeadScore(contact.ID); err != nil {
+	if err := s.calculateLeadScore(contact.ID, "Lead score recalculated", updatedBy); err != nil {
 		logger.Warn("Failed to recalculate lead score", map[string]interface{}{
 			"contact_id": contact.ID,
 			"error":      err.Error(),
@@ -311,6 +329,7 @@ func (s *ContactService) DeleteContact(id uint, deletedBy *uint) error {
 		return err
 	}
 
+	before := *contact
 	now := time.Now()
 	contact.DeletedAt = &now
 	contact.UpdatedBy = deletedBy
@@ -322,6 +341,8 @@ func (s *ContactService) DeleteContact(id uint, deletedBy *uint) error {
 		return fmt.Errorf("failed to delete contact: %v", err)
 	}
 
+	recordContactHistory(s.db, &before, contact, deletedBy, models.ChangeSourceAPI, "Contact deleted")
+
 	// Log activity
 	s.logContactActivity(contact.ID, "contact_deleted", map[string]interface{}{
 		"deleted_by": deletedBy,
@@ -418,6 +439,7 @@ func (s *ContactService) UpdateContactStatus(id uint, status models.ContactStatu
 		return err
 	}
 
+	before := *contact
 	oldStatus := contact.Status
 	contact.Status = status
 	contact.UpdatedBy = updatedBy
@@ -438,6 +460,8 @@ func (s *ContactService) UpdateContactStatus(id uint, status models.ContactStatu
 		return fmt.Errorf("failed to update contact status: %v", err)
 	}
 
+	recordContactHistory(s.db, &before, contact, updatedBy, models.ChangeSourceAPI, "Status changed")
+
 	// Log status change activity
 	s.logContactActivity(contact.ID, "status_change", map[string]interface{}{
 		"old_status": oldStatus,
@@ -448,6 +472,56 @@ func (s *ContactService) UpdateContactStatus(id uint, status models.ContactStatu
 	return nil
 }
 
+// GetContactHistory retrieves the field-level change history of a contact
+func (s *ContactService) GetContactHistory(id uint, page, limit int) ([]models.ContactFieldHistory, int64, error) {
+	if _, err := s.GetContact(id); err != nil {
+		return nil, 0, err
+	}
+
+	history, total, err := repository.NewContactHistoryRepository(s.db).ListByContact(id, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get contact history: %v", err)
+	}
+	return history, total, nil
+}
+
+// GetContactAsOf reconstructs a contact as it was at the given time by
+// rolling back every recorded field change made after it
+func (s *ContactService) GetContactAsOf(id uint, at time.Time) (*models.ContactHistoryResponse, error) {
+	contact, err := s.GetContact(id)
+	if err != nil {
+		return nil, err
+	}
+	if contact.CreatedAt.After(at) {
+		return nil, fmt.Errorf("contact did not exist at %s", at.Format(time.RFC3339))
+	}
+
+	changes, err := repository.NewContactHistoryRepository(s.db).ListAfter(id, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact history: %v", err)
+	}
+
+	values := contact.FieldValues()
+	for _, change := range changes {
+		if change.ChangeType == models.FieldChangeCreate {
+			return nil, fmt.Errorf("contact did not exist at %s", at.Format(time.RFC3339))
+		}
+		values[change.FieldName] = change.OldValue
+	}
+
+	snapshot := *contact
+	if err := snapshot.ApplyFieldValues(values); err != nil {
+		return nil, fmt.Errorf("failed to reconstruct contact: %v", err)
+	}
+
+	return &models.ContactHistoryResponse{
+		ContactID: id,
+		At:        at,
+		Contact:   &snapshot,
+		Reverted:  changes,
+	}, nil
+}
+
 // SearchContacts performs advanced search on contacts
 func (s *ContactService) SearchContacts(query string, filters map[string]interface{}) ([]*models.Contact, error) {
 	dbQuery := s.db.Model(&models.Contact{}).
@@ -540,44 +614,49 @@ func (s *ContactService) checkForDuplicates(email string, phone *string) (*model
 	return &contact, nil
 }
 
-func (s *ContactService) calculateLeadScore(contactID uint) error {
-	// This is a placeholder for lead scoring logic
-	// In a real implementation, this would apply scoring rules
-	// For now, we'll set a basic score based on available data
-	
-	var contact models.Contact
-	if err := s.db.First(&contact, contactID).Error; err != nil {
-		return err
-	}
+// calculateLeadScore rescores the contact with the lead scoring engine
+func (s *ContactService) calculateLeadScore(contactID uint, reason string, changedBy *uint) error {
+	_, err := NewLifecycleService(s.db).ScoreContact(contactID, true, reason, changedBy)
+	return err
+}
 
-	score := 0
-	
-	// Basic scoring rules
-	if contact.Company != nil && *contact.Company != "" {
-		score += 10
-	}
-	if contact.Phone != nil && *contact.Phone != "" {
-		score += 15
-	}
-	if contact.JobTitle != nil && *contact.JobTitle != "" {
-		score += 12
-	}
-	if !strings.Contains(strings.ToLower(contact.Email), "gmail") &&
-	   !strings.Contains(strings.ToLower(contact.Email), "yahoo") &&
-	   !strings.Contains(strings.ToLower(contact.Email), "hotmail") {
-		score += 10 // Business email
-	}
+func (s *ContactService) logContactActivity(contactID uint, activityType string, details map[string]interface{}) {
+	logger.LogContactActivity(contactID, activityType, details)
+}
 
-	// Update lead score
-	if err := s.db.Model(&contact).Update("lead_score", score).Error; err != nil {
+// validateContact applies the configured validation rules for a channel. Error severity
+// violations are returned as a validation AppError; warnings are attached to the contact.
+func (s *ContactService) validateContact(contact *models.Contact, channel models.ValidationChannel) error {
+	result, err := NewValidationService(repository.NewValidationRuleRepository(s.db)).ValidateContact(contact, channel)
+	if err != nil {
 		return err
 	}
-
+	if err := result.Err(); err != nil {
+		return err
+	}
+	contact.ValidationWarnings = result.Warnings
 	return nil
 }
 
-func (s *ContactService) logContactActivity(contactID uint, activityType string, details map[string]interface{}) {
-	logger.LogContactActivity(contactID, activityType, details)
+// recordContactHistory writes field-level changes to contact_field_history.
+// Failures are logged rather than returned so they never undo the change itself.
+func recordContactHistory(db *gorm.DB, before, after *models.Contact, changedBy *uint, source, reason string) {
+	if err := repository.NewContactHistoryRepository(db).RecordChanges(before, after, changedBy, source, reason); err != nil {
+		logger.Error("Failed to record contact field history", err, map[string]interface{}{
+			"contact_id": after.ID,
+			"source":     source,
+		})
+	}
+}
+
+// recordContactHistorySince reloads the contact and records what changed since before
+func recordContactHistorySince(db *gorm.DB, before *models.Contact, changedBy *uint, source, reason string) {
+	if err := repository.NewContactHistoryRepository(db).RecordCurrent(before, changedBy, source, reason); err != nil {
+		logger.Error("Failed to record contact field history", err, map[string]interface{}{
+			"contact_id": before.ID,
+			"source":     source,
+		})
+	}
 }
 
 // AdvancedSearch performs advanced search with multiple criteria
@@ -0,0 +1,1130 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	apperrors "contact-service/pkg/errors"
+	"contact-service/pkg/logger"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Default confidence score (0-100) above which two contacts are considered duplicates
+const defaultDuplicateThreshold = 70.0
+
+// Similarity weights used when scoring a candidate pair
+const (
+	duplicateIdentityWeight = 0.50 // Best of email / phone match
+	duplicateNameWeight     = 0.35
+	duplicateCompanyWeight  = 0.15
+)
+
+// Columns whose values a merge may carry over from the duplicate onto the master,
+// together with the strategy applied when the request does not override it
+var defaultFieldMergeStrategies = map[string]models.FieldMergeStrategy{
+	"first_name":               models.FieldKeepMaster,
+	"last_name":                models.FieldFillEmpty,
+	"email":                    models.FieldKeepMaster,
+	"phone":                    models.FieldFillEmpty,
+	"company":                  models.FieldFillEmpty,
+	"job_title":                models.FieldFillEmpty,
+	"website":                  models.FieldFillEmpty,
+	"address_line1":            models.FieldFillEmpty,
+	"address_line2":            models.FieldFillEmpty,
+	"city":                     models.FieldFillEmpty,
+	"state":                    models.FieldFillEmpty,
+	"postal_code":              models.FieldFillEmpty,
+	"country":                  models.FieldKeepMaster,
+	"subject":                  models.FieldFillEmpty,
+	"message":                  models.FieldFillEmpty,
+	"preferred_contact_method": models.FieldKeepMaster,
+	"status":                   models.FieldKeepMaster,
+	"priority":                 models.FieldKeepMaster,
+	"lead_score":               models.FieldKeepMaster,
+	"estimated_value":          models.FieldKeepMaster,
+	"assigned_to":              models.FieldFillEmpty,
+	"next_followup_date":       models.FieldFillEmpty,
+	"last_contact_date":        models.FieldMostRecent,
+	"marketing_consent":        models.FieldKeepMaster,
+	"unsubscribed":             models.FieldFillEmpty, // an opt-out on either record wins
+	"do_not_call":              models.FieldFillEmpty,
+	"tags":                     models.FieldUnion,
+	"custom_fields":            models.FieldUnion,
+	"notes":                    models.FieldConcatenate,
+}
+
+// Tables whose rows are moved from the merged contact onto the master
+var mergeReparentTables = []string{
+	"contact_activities",
+	"appointments",
+	"contact_tag_assignments",
+	"contact_assignments",
+}
+
+// DuplicateService handles duplicate detection and contact merging
+type DuplicateService struct {
+	db *gorm.DB
+}
+
+// NewDuplicateService creates a new duplicate service
+func NewDuplicateService(db *gorm.DB) *DuplicateService {
+	return &DuplicateService{db: db}
+}
+
+// duplicateCandidate holds the normalized values used for scoring a contact
+type duplicateCandidate struct {
+	contact models.Contact
+	email   string
+	phone   string
+	name    string
+	company string
+}
+
+// duplicateMatch represents a scored pair of contacts
+type duplicateMatch struct {
+	ContactA uint     `json:"contact_a"`
+	ContactB uint     `json:"contact_b"`
+	Score    float64  `json:"score"`
+	Fields   []string `json:"fields"`
+}
+
+// DetectDuplicates scores candidate pairs and persists duplicate groups above the threshold
+func (s *DuplicateService) DetectDuplicates(req *models.DuplicateDetectionRequest) (*models.DuplicateDetectionResponse, error) {
+	threshold := req.Threshold
+	if threshold <= 0 {
+		threshold = defaultDuplicateThreshold
+	}
+
+	var contacts []models.Contact
+	if err := s.db.Select("id, first_name, last_name, email, phone, company, created_at").
+		Where("deleted_at IS NULL AND is_duplicate = ?", false).
+		Find(&contacts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load contacts: %v", err)
+	}
+
+	candidates := make(map[uint]*duplicateCandidate, len(contacts))
+	buckets := make(map[string][]uint)
+	for _, contact := range contacts {
+		candidate := newDuplicateCandidate(contact)
+		candidates[contact.ID] = candidate
+		for _, key := range candidate.blockingKeys() {
+			buckets[key] = append(buckets[key], contact.ID)
+		}
+	}
+
+	if req.ContactID != nil {
+		if _, exists := candidates[*req.ContactID]; !exists {
+			return nil, fmt.Errorf("contact not found")
+		}
+	}
+
+	// Only compare contacts that share at least one blocking key
+	compared := make(map[[2]uint]bool)
+	var matches []duplicateMatch
+	for _, ids := range buckets {
+		for i := 0; i < len(ids); i++ {
+			for j := i + 1; j < len(ids); j++ {
+				a, b := ids[i], ids[j]
+				if a > b {
+					a, b = b, a
+				}
+				if req.ContactID != nil && a != *req.ContactID && b != *req.ContactID {
+					continue
+				}
+				pair := [2]uint{a, b}
+				if compared[pair] {
+					continue
+				}
+				compared[pair] = true
+
+				score, fields := scoreDuplicatePair(candidates[a], candidates[b])
+				if score >= threshold {
+					matches = append(matches, duplicateMatch{ContactA: a, ContactB: b, Score: score, Fields: fields})
+				}
+			}
+		}
+	}
+
+	response := &models.DuplicateDetectionResponse{
+		ContactsScanned: len(contacts),
+		PairsCompared:   len(compared),
+		Groups:          []models.ContactDuplicateGroup{},
+	}
+
+	for _, cluster := range clusterDuplicateMatches(matches) {
+		group, err := s.saveDuplicateGroup(cluster, candidates, threshold)
+		if err != nil {
+			logger.Error("Failed to save duplicate group", err, map[string]interface{}{
+				"contacts": len(cluster),
+			})
+			continue
+		}
+		if group != nil {
+			response.Groups = append(response.Groups, *group)
+		}
+	}
+	response.GroupsFound = len(response.Groups)
+
+	logger.LogBusinessEvent("duplicates_detected", "contact", 0, map[string]interface{}{
+		"contacts_scanned": response.ContactsScanned,
+		"pairs_compared":   response.PairsCompared,
+		"groups_found":     response.GroupsFound,
+		"threshold":        threshold,
+	})
+
+	return response, nil
+}
+
+// ListDuplicateGroups lists duplicate groups with optional status filter
+func (s *DuplicateService) ListDuplicateGroups(status string, page, limit int) ([]models.ContactDuplicateGroup, int64, error) {
+	query := s.db.Model(&models.ContactDuplicateGroup{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count duplicate groups: %v", err)
+	}
+
+	var groups []models.ContactDuplicateGroup
+	if err := query.Preload("Members").Preload("Members.Contact").
+		Order("confidence_score DESC, detected_at DESC").
+		Limit(limit).Offset((page - 1) * limit).
+		Find(&groups).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get duplicate groups: %v", err)
+	}
+
+	return groups, total, nil
+}
+
+// GetDuplicateGroup retrieves a duplicate group with its members
+func (s *DuplicateService) GetDuplicateGroup(id uint) (*models.ContactDuplicateGroup, error) {
+	var group models.ContactDuplicateGroup
+	if err := s.db.Preload("Members").Preload("Members.Contact").First(&group, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("duplicate group not found")
+		}
+		return nil, fmt.Errorf("failed to get duplicate group: %v", err)
+	}
+	return &group, nil
+}
+
+// UpdateDuplicateGroupStatus changes the review status of a duplicate group
+func (s *DuplicateService) UpdateDuplicateGroupStatus(id uint, req *models.DuplicateGroupStatusRequest, updatedBy uint) (*models.ContactDuplicateGroup, error) {
+	group, err := s.GetDuplicateGroup(id)
+	if err != nil {
+		return nil, err
+	}
+	if group.Status == models.DuplicateStatusMerged {
+		return nil, fmt.Errorf("duplicate group has already been merged")
+	}
+
+	updates := map[string]interface{}{
+		"status":           req.Status,
+		"resolution_notes": req.Notes,
+	}
+	if req.Status == models.DuplicateStatusIgnored || req.Status == models.DuplicateStatusSplit {
+		updates["resolved_at"] = time.Now()
+		updates["resolved_by"] = updatedBy
+	}
+
+	if err := s.db.Model(group).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update duplicate group: %v", err)
+	}
+
+	return s.GetDuplicateGroup(id)
+}
+
+// MergeContacts merges the duplicate contacts into the master contact
+func (s *DuplicateService) MergeContacts(req *models.ContactMergeRequest, mergedBy uint) (*models.ContactMergeResponse, error) {
+	for field, strategy := range req.FieldStrategies {
+		if _, ok := defaultFieldMergeStrategies[field]; !ok {
+			return nil, fmt.Errorf("field %s cannot be merged", field)
+		}
+		if !isValidFieldMergeStrategy(strategy) {
+			return nil, fmt.Errorf("invalid merge strategy %s for field %s", strategy, field)
+		}
+	}
+
+	seen := map[uint]bool{req.MasterContactID: true}
+	for _, id := range req.DuplicateContactIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("contact %d is listed more than once or is the master", id)
+		}
+		seen[id] = true
+	}
+
+	response := &models.ContactMergeResponse{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var master models.Contact
+		if err := tx.Where("deleted_at IS NULL").First(&master, req.MasterContactID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("master contact not found")
+			}
+			return fmt.Errorf("failed to get master contact: %v", err)
+		}
+
+		for _, duplicateID := range req.DuplicateContactIDs {
+			var duplicate models.Contact
+			if err := tx.Where("deleted_at IS NULL").First(&duplicate, duplicateID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("contact %d not found", duplicateID)
+				}
+				return fmt.Errorf("failed to get contact %d: %v", duplicateID, err)
+			}
+
+			history, err := s.mergeContactInto(tx, &master, &duplicate, req, mergedBy)
+			if err != nil {
+				return err
+			}
+			response.Merges = append(response.Merges, *history)
+		}
+
+		if req.DuplicateGroupID != nil {
+			now := time.Now()
+			if err := tx.Model(&models.ContactDuplicateGroup{}).Where("id = ?", *req.DuplicateGroupID).
+				Updates(map[string]interface{}{
+					"status":            models.DuplicateStatusMerged,
+					"master_contact_id": master.ID,
+					"resolved_at":       now,
+					"resolved_by":       mergedBy,
+					"resolution_notes":  req.Notes,
+				}).Error; err != nil {
+				return fmt.Errorf("failed to update duplicate group: %v", err)
+			}
+		}
+
+		response.MasterContact = &master
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{
+		"merged_contact_ids": req.DuplicateContactIDs,
+		"merged_by":          mergedBy,
+	}
+	if req.DuplicateGroupID != nil {
+		fields["duplicate_group_id"] = *req.DuplicateGroupID
+	}
+	logger.LogContactActivity(req.MasterContactID, "contacts_merged", fields)
+
+	return response, nil
+}
+
+// GetMergeHistory lists merge operations involving a contact
+func (s *DuplicateService) GetMergeHistory(contactID uint) ([]models.ContactMergeHistory, error) {
+	var history []models.ContactMergeHistory
+	if err := s.db.Where("master_contact_id = ? OR merged_contact_id = ?", contactID, contactID).
+		Order("merged_at DESC").Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("failed to get merge history: %v", err)
+	}
+	return history, nil
+}
+
+// UnmergeContact reverses a merge recorded in contact_merge_history. It refuses with a conflict
+// error listing the affected fields when the master has since been edited in a merged field.
+func (s *DuplicateService) UnmergeContact(historyID uint, req *models.ContactUnmergeRequest, unmergedBy uint) (*models.ContactUnmergeResponse, error) {
+	response := &models.ContactUnmergeResponse{MovedRecords: map[string][]uint{}}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var history models.ContactMergeHistory
+		if err := tx.First(&history, historyID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("merge history not found")
+			}
+			return fmt.Errorf("failed to get merge history: %v", err)
+		}
+		if history.IsUnmerged() {
+			return fmt.Errorf("merge has already been undone")
+		}
+
+		originalMaster, err := contactFromSnapshot(history.OriginalData["master"])
+		if err != nil {
+			return fmt.Errorf("merge history has no usable master snapshot: %v", err)
+		}
+		originalDuplicate, err := contactFromSnapshot(history.OriginalData["duplicate"])
+		if err != nil {
+			return fmt.Errorf("merge history has no usable duplicate snapshot: %v", err)
+		}
+
+		var master models.Contact
+		if err := tx.Where("deleted_at IS NULL").First(&master, history.MasterContactID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("master contact not found")
+			}
+			return fmt.Errorf("failed to get master contact: %v", err)
+		}
+
+		var merged models.Contact
+		if err := tx.First(&merged, history.MergedContactID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("merged contact not found")
+			}
+			return fmt.Errorf("failed to get merged contact: %v", err)
+		}
+		if merged.DeletedAt == nil {
+			return fmt.Errorf("merged contact %d is already active", merged.ID)
+		}
+
+		// Every field the merge wrote must still hold the merged value (or have been put back)
+		masterValue := reflect.ValueOf(&master).Elem()
+		originalValue := reflect.ValueOf(originalMaster).Elem()
+		restore := make(map[string]interface{})
+		var fieldErrors []apperrors.FieldError
+		for column, entry := range history.MergedFields {
+			index, ok := contactColumnIndex[column]
+			if !ok {
+				continue
+			}
+			current := derefFieldValue(masterValue.Field(index).Interface())
+			original := originalValue.Field(index).Interface()
+
+			if details, ok := entry.(map[string]interface{}); ok {
+				if mergedValue, ok := details["value"]; ok &&
+					!sameJSONValue(current, mergedValue) && !sameJSONValue(current, derefFieldValue(original)) {
+					fieldErrors = append(fieldErrors, apperrors.FieldError{
+						Field:   column,
+						Message: fmt.Sprintf("%s was changed after the merge", column),
+						Code:    "MERGE_CONFLICT",
+						Value: map[string]interface{}{
+							"current":  current,
+							"merged":   mergedValue,
+							"original": derefFieldValue(original),
+						},
+					})
+					continue
+				}
+			}
+			restore[column] = original
+		}
+		if len(fieldErrors) > 0 {
+			sort.Slice(fieldErrors, func(i, j int) bool { return fieldErrors[i].Field < fieldErrors[j].Field })
+			conflict := apperrors.NewConflictError("master contact has been edited since the merge")
+			conflict.FieldErrors = fieldErrors
+			return conflict.WithContext("merge_history_id", history.ID)
+		}
+
+		if len(restore) > 0 {
+			restore["updated_by"] = unmergedBy
+			if err := tx.Model(&models.Contact{}).Where("id = ?", master.ID).Updates(restore).Error; err != nil {
+				return fmt.Errorf("failed to restore master contact: %v", err)
+			}
+			recordContactHistorySince(tx, &master, &unmergedBy, models.ChangeSourceMerge, fmt.Sprintf("Unmerged contact %d", merged.ID))
+			for column := range restore {
+				if column != "updated_by" {
+					response.RestoredFields = append(response.RestoredFields, column)
+				}
+			}
+			sort.Strings(response.RestoredFields)
+		}
+
+		// Move back the rows that are still attached to the master
+		reparented, _ := history.OriginalData["reparented"].(map[string]interface{})
+		for _, table := range mergeReparentTables {
+			ids := snapshotIDs(reparented[table])
+			if len(ids) == 0 {
+				continue
+			}
+			var moved []uint
+			if err := tx.Table(table).Where("id IN ? AND contact_id = ?", ids, master.ID).Pluck("id", &moved).Error; err != nil {
+				return fmt.Errorf("failed to load %s for unmerge: %v", table, err)
+			}
+			if len(moved) > 0 {
+				if err := tx.Table(table).Where("id IN ?", moved).Update("contact_id", merged.ID).Error; err != nil {
+					return fmt.Errorf("failed to move %s back: %v", table, err)
+				}
+			}
+			response.MovedRecords[table] = moved
+		}
+
+		if err := tx.Model(&models.Contact{}).Where("id = ?", merged.ID).Updates(map[string]interface{}{
+			"is_duplicate":        originalDuplicate.IsDuplicate,
+			"original_contact_id": originalDuplicate.OriginalContactID,
+			"deleted_at":          originalDuplicate.DeletedAt,
+			"updated_by":          unmergedBy,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to restore merged contact: %v", err)
+		}
+		recordContactHistorySince(tx, &merged, &unmergedBy, models.ChangeSourceMerge, fmt.Sprintf("Restored from merge into contact %d", master.ID))
+
+		now := time.Now()
+		history.UnmergedAt = &now
+		history.UnmergedBy = &unmergedBy
+		history.UnmergeNotes = req.Notes
+		if err := tx.Model(&history).Updates(map[string]interface{}{
+			"unmerged_at":   history.UnmergedAt,
+			"unmerged_by":   history.UnmergedBy,
+			"unmerge_notes": history.UnmergeNotes,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update merge history: %v", err)
+		}
+
+		// Send the group back for review
+		if history.DuplicateGroupID != nil {
+			if err := tx.Model(&models.ContactDuplicateGroup{}).
+				Where("id = ? AND status = ?", *history.DuplicateGroupID, models.DuplicateStatusMerged).
+				Updates(map[string]interface{}{
+					"status":      models.DuplicateStatusReviewing,
+					"resolved_at": nil,
+					"resolved_by": nil,
+				}).Error; err != nil {
+				return fmt.Errorf("failed to reopen duplicate group: %v", err)
+			}
+		}
+
+		var restoredMaster, restoredContact models.Contact
+		if err := tx.First(&restoredMaster, master.ID).Error; err != nil {
+			return fmt.Errorf("failed to reload master contact: %v", err)
+		}
+		if err := tx.First(&restoredContact, merged.ID).Error; err != nil {
+			return fmt.Errorf("failed to reload merged contact: %v", err)
+		}
+		response.MasterContact = &restoredMaster
+		response.RestoredContact = &restoredContact
+		response.History = history
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.LogContactActivity(response.MasterContact.ID, "contacts_unmerged", map[string]interface{}{
+		"merge_history_id": historyID,
+		"restored_contact": response.RestoredContact.ID,
+		"restored_fields":  response.RestoredFields,
+		"unmerged_by":      unmergedBy,
+	})
+
+	return response, nil
+}
+
+// mergeContactInto merges a single duplicate into the master within the given transaction
+func (s *DuplicateService) mergeContactInto(tx *gorm.DB, master, duplicate *models.Contact, req *models.ContactMergeRequest, mergedBy uint) (*models.ContactMergeHistory, error) {
+	masterSnapshot := contactSnapshot(master)
+	duplicateSnapshot := contactSnapshot(duplicate)
+
+	updates, mergedFields, conflicts := resolveMergeFields(master, duplicate, req.FieldStrategies)
+	if len(updates) > 0 {
+		updates["updated_by"] = mergedBy
+		if err := tx.Model(&models.Contact{}).Where("id = ?", master.ID).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update master contact: %v", err)
+		}
+		var reloaded models.Contact
+		if err := tx.First(&reloaded, master.ID).Error; err != nil {
+			return nil, fmt.Errorf("failed to reload master contact: %v", err)
+		}
+		recordContactHistory(tx, master, &reloaded, &mergedBy, models.ChangeSourceMerge, fmt.Sprintf("Merged contact %d", duplicate.ID))
+		*master = reloaded
+	}
+
+	reparented, err := s.reparentContactRecords(tx, duplicate.ID, master.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.Contact{}).Where("id = ?", duplicate.ID).Updates(map[string]interface{}{
+		"is_duplicate":        true,
+		"original_contact_id": master.ID,
+		"deleted_at":          now,
+		"updated_by":          mergedBy,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to mark contact %d as merged: %v", duplicate.ID, err)
+	}
+	recordContactHistorySince(tx, duplicate, &mergedBy, models.ChangeSourceMerge, fmt.Sprintf("Merged into contact %d", master.ID))
+
+	strategy := models.MergeStrategyMergeFields
+	if len(req.FieldStrategies) > 0 {
+		strategy = models.MergeStrategyManual
+	} else if len(mergedFields) == 0 {
+		strategy = models.MergeStrategyKeepMaster
+	}
+
+	history := &models.ContactMergeHistory{
+		MasterContactID:   master.ID,
+		MergedContactID:   duplicate.ID,
+		DuplicateGroupID:  req.DuplicateGroupID,
+		MergeStrategy:     strategy,
+		MergedFields:      mergedFields,
+		ConflictsResolved: conflicts,
+		OriginalData: models.JSONMap{
+			"master":     masterSnapshot,
+			"duplicate":  duplicateSnapshot,
+			"reparented": reparented,
+		},
+		MergedAt:   now,
+		MergedBy:   mergedBy,
+		MergeNotes: req.Notes,
+	}
+	if err := tx.Create(history).Error; err != nil {
+		return nil, fmt.Errorf("failed to record merge history: %v", err)
+	}
+
+	return history, nil
+}
+
+// reparentContactRecords moves related rows from one contact to another and returns the moved IDs per table
+func (s *DuplicateService) reparentContactRecords(tx *gorm.DB, fromContactID, toContactID uint) (map[string]interface{}, error) {
+	reparented := make(map[string]interface{}, len(mergeReparentTables))
+
+	for _, table := range mergeReparentTables {
+		query := tx.Table(table).Where("contact_id = ?", fromContactID)
+		if table == "contact_tag_assignments" {
+			// Leave tags the master already carries on the merged contact
+			query = query.Where("tag_id NOT IN (?)",
+				tx.Table(table).Select("tag_id").Where("contact_id = ?", toContactID))
+		}
+
+		var ids []uint
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return nil, fmt.Errorf("failed to load %s for merge: %v", table, err)
+		}
+		if len(ids) > 0 {
+			if err := tx.Table(table).Where("id IN ?", ids).Update("contact_id", toContactID).Error; err != nil {
+				return nil, fmt.Errorf("failed to move %s: %v", table, err)
+			}
+		}
+		reparented[table] = ids
+	}
+
+	return reparented, nil
+}
+
+// saveDuplicateGroup creates or refreshes the group for a cluster of matching contacts
+func (s *DuplicateService) saveDuplicateGroup(cluster []duplicateMatch, candidates map[uint]*duplicateCandidate, threshold float64) (*models.ContactDuplicateGroup, error) {
+	// Collect members and each member's best match
+	best := make(map[uint]duplicateMatch)
+	totalScore := 0.0
+	for _, match := range cluster {
+		for _, id := range []uint{match.ContactA, match.ContactB} {
+			if current, ok := best[id]; !ok || match.Score > current.Score {
+				best[id] = match
+			}
+		}
+		totalScore += match.Score
+	}
+
+	memberIDs := make([]uint, 0, len(best))
+	for id := range best {
+		memberIDs = append(memberIDs, id)
+	}
+	sort.Slice(memberIDs, func(i, j int) bool { return memberIDs[i] < memberIDs[j] })
+
+	// The oldest contact is proposed as master
+	masterID := memberIDs[0]
+	for _, id := range memberIDs[1:] {
+		if candidates[id].contact.CreatedAt.Before(candidates[masterID].contact.CreatedAt) {
+			masterID = id
+		}
+	}
+
+	idParts := make([]string, len(memberIDs))
+	for i, id := range memberIDs {
+		idParts[i] = fmt.Sprintf("%d", id)
+	}
+	hash := sha256.Sum256([]byte("contacts:" + strings.Join(idParts, ",")))
+	groupHash := hex.EncodeToString(hash[:])
+
+	var group models.ContactDuplicateGroup
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("group_hash = ?", groupHash).First(&group).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to look up duplicate group: %v", err)
+		}
+		if err == nil && (group.Status == models.DuplicateStatusIgnored || group.Status == models.DuplicateStatusMerged) {
+			// Already resolved by a reviewer, don't resurface it
+			group = models.ContactDuplicateGroup{}
+			return nil
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Reuse an open group that already contains one of these contacts
+			err = tx.Where("status IN ?", []models.DuplicateGroupStatus{models.DuplicateStatusDetected, models.DuplicateStatusReviewing}).
+				Where("id IN (?)", tx.Model(&models.ContactDuplicateMember{}).Select("duplicate_group_id").Where("contact_id IN ?", memberIDs)).
+				First(&group).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to look up duplicate group: %v", err)
+			}
+		}
+
+		group.GroupHash = groupHash
+		group.MasterContactID = &masterID
+		group.DuplicateCount = len(memberIDs) - 1
+		group.ConfidenceScore = roundScore(totalScore / float64(len(cluster)))
+		group.MatchCriteria = models.JSONMap{
+			"threshold": threshold,
+			"weights": map[string]float64{
+				"identity": duplicateIdentityWeight,
+				"name":     duplicateNameWeight,
+				"company":  duplicateCompanyWeight,
+			},
+			"pairs": cluster,
+		}
+		if group.ID == 0 {
+			group.Status = models.DuplicateStatusDetected
+			group.DetectedAt = time.Now()
+		}
+		if err := tx.Save(&group).Error; err != nil {
+			return fmt.Errorf("failed to save duplicate group: %v", err)
+		}
+
+		if err := tx.Where("duplicate_group_id = ?", group.ID).Delete(&models.ContactDuplicateMember{}).Error; err != nil {
+			return fmt.Errorf("failed to clear duplicate group members: %v", err)
+		}
+
+		members := make([]models.ContactDuplicateMember, 0, len(memberIDs))
+		for _, id := range memberIDs {
+			match := best[id]
+			fields := make(models.JSONArray, len(match.Fields))
+			for i, field := range match.Fields {
+				fields[i] = field
+			}
+			members = append(members, models.ContactDuplicateMember{
+				DuplicateGroupID: group.ID,
+				ContactID:        id,
+				IsMaster:         id == masterID,
+				SimilarityScore:  match.Score,
+				MatchingFields:   fields,
+			})
+		}
+		if err := tx.Create(&members).Error; err != nil {
+			return fmt.Errorf("failed to save duplicate group members: %v", err)
+		}
+		group.Members = members
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if group.ID == 0 {
+		return nil, nil
+	}
+
+	return &group, nil
+}
+
+// newDuplicateCandidate normalizes the fields of a contact used for scoring
+func newDuplicateCandidate(contact models.Contact) *duplicateCandidate {
+	candidate := &duplicateCandidate{
+		contact: contact,
+		email:   normalizeEmail(contact.Email),
+		name:    normalizeText(contact.GetFullName()),
+	}
+	if contact.Phone != nil {
+		candidate.phone = normalizePhone(*contact.Phone)
+	}
+	if contact.Company != nil {
+		candidate.company = normalizeCompany(*contact.Company)
+	}
+	return candidate
+}
+
+// blockingKeys returns the keys used to bucket contacts that are worth comparing
+func (c *duplicateCandidate) blockingKeys() []string {
+	var keys []string
+	if c.email != "" {
+		keys = append(keys, "email:"+c.email)
+	}
+	if c.phone != "" {
+		keys = append(keys, "phone:"+c.phone)
+	}
+	if parts := strings.Fields(c.name); len(parts) > 0 {
+		// Last name plus first initial tolerates typos in the first name
+		initial := string([]rune(parts[0])[0])
+		keys = append(keys, "name:"+parts[len(parts)-1]+":"+initial)
+		if c.company != "" {
+			keys = append(keys, "company:"+c.company+":"+initial)
+		}
+	}
+	return keys
+}
+
+// scoreDuplicatePair returns a 0-100 confidence score and the fields that matched
+func scoreDuplicatePair(a, b *duplicateCandidate) (float64, []string) {
+	var fields []string
+
+	identity := 0.0
+	if a.email != "" && a.email == b.email {
+		identity = 1
+		fields = append(fields, "email")
+	}
+	if a.phone != "" && a.phone == b.phone {
+		identity = 1
+		fields = append(fields, "phone")
+	}
+
+	nameScore := stringSimilarity(a.name, b.name)
+	if nameScore >= 0.85 {
+		fields = append(fields, "name")
+	}
+
+	// Without company data on both sides its weight goes to the name
+	nameWeight := duplicateNameWeight
+	companyScore := 0.0
+	if a.company != "" && b.company != "" {
+		companyScore = stringSimilarity(a.company, b.company)
+		if companyScore >= 0.85 {
+			fields = append(fields, "company")
+		}
+	} else {
+		nameWeight += duplicateCompanyWeight
+	}
+
+	score := duplicateIdentityWeight*identity + nameWeight*nameScore
+	if a.company != "" && b.company != "" {
+		score += duplicateCompanyWeight * companyScore
+	}
+
+	return roundScore(score * 100), fields
+}
+
+// clusterDuplicateMatches groups matched pairs into connected clusters
+func clusterDuplicateMatches(matches []duplicateMatch) [][]duplicateMatch {
+	parent := make(map[uint]uint)
+	var find func(uint) uint
+	find = func(id uint) uint {
+		if _, ok := parent[id]; !ok {
+			parent[id] = id
+		}
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+
+	for _, match := range matches {
+		rootA, rootB := find(match.ContactA), find(match.ContactB)
+		if rootA != rootB {
+			parent[rootB] = rootA
+		}
+	}
+
+	clusters := make(map[uint][]duplicateMatch)
+	var roots []uint
+	for _, match := range matches {
+		root := find(match.ContactA)
+		if _, ok := clusters[root]; !ok {
+			roots = append(roots, root)
+		}
+		clusters[root] = append(clusters[root], match)
+	}
+
+	result := make([][]duplicateMatch, 0, len(roots))
+	for _, root := range roots {
+		result = append(result, clusters[root])
+	}
+	return result
+}
+
+// resolveMergeFields computes master column updates, the fields taken from the duplicate (with the
+// value written to the master) and resolved conflicts
+func resolveMergeFields(master, duplicate *models.Contact, overrides map[string]models.FieldMergeStrategy) (map[string]interface{}, models.JSONMap, models.JSONMap) {
+	updates := make(map[string]interface{})
+	mergedFields := models.JSONMap{}
+	conflicts := models.JSONMap{}
+
+	masterValue := reflect.ValueOf(master).Elem()
+	duplicateValue := reflect.ValueOf(duplicate).Elem()
+
+	for column, strategy := range defaultFieldMergeStrategies {
+		if override, ok := overrides[column]; ok {
+			strategy = override
+		}
+
+		index, ok := contactColumnIndex[column]
+		if !ok {
+			continue
+		}
+		masterField := masterValue.Field(index).Interface()
+		duplicateField := duplicateValue.Field(index).Interface()
+
+		masterEmpty := isEmptyFieldValue(masterField)
+		duplicateEmpty := isEmptyFieldValue(duplicateField)
+		if duplicateEmpty || reflect.DeepEqual(derefFieldValue(masterField), derefFieldValue(duplicateField)) {
+			continue
+		}
+
+		var chosen interface{}
+		switch strategy {
+		case models.FieldKeepDuplicate:
+			chosen = duplicateField
+		case models.FieldFillEmpty:
+			if masterEmpty {
+				chosen = duplicateField
+			}
+		case models.FieldMostRecent:
+			if masterEmpty || duplicate.UpdatedAt.After(master.UpdatedAt) {
+				chosen = duplicateField
+			}
+		case models.FieldUnion:
+			masterMap, _ := masterField.(models.JSONMap)
+			duplicateMap, _ := duplicateField.(models.JSONMap)
+			union := models.JSONMap{}
+			for key, value := range duplicateMap {
+				union[key] = value
+			}
+			for key, value := range masterMap {
+				union[key] = value
+			}
+			if len(union) != len(masterMap) {
+				chosen = union
+			}
+		case models.FieldConcatenate:
+			masterText, _ := masterField.(*string)
+			duplicateText, _ := duplicateField.(*string)
+			if masterEmpty {
+				chosen = duplicateText
+			} else if duplicateText != nil {
+				combined := *masterText + "\n\n" + *duplicateText
+				chosen = &combined
+			}
+		}
+
+		if !masterEmpty {
+			resolution := "master"
+			if chosen != nil {
+				resolution = "duplicate"
+				if strategy == models.FieldUnion || strategy == models.FieldConcatenate {
+					resolution = "combined"
+				}
+			}
+			conflicts[column] = map[string]interface{}{
+				"master":    derefFieldValue(masterField),
+				"duplicate": derefFieldValue(duplicateField),
+				"strategy":  strategy,
+				"resolved":  resolution,
+			}
+		}
+
+		if chosen != nil {
+			updates[column] = chosen
+			mergedFields[column] = map[string]interface{}{
+				"strategy": strategy,
+				"value":    derefFieldValue(chosen),
+			}
+		}
+	}
+
+	return updates, mergedFields, conflicts
+}
+
+// contactColumnIndex maps contact column names to struct field indexes
+var contactColumnIndex = func() map[string]int {
+	index := make(map[string]int)
+	contactType := reflect.TypeOf(models.Contact{})
+	for i := 0; i < contactType.NumField(); i++ {
+		tag := strings.Split(contactType.Field(i).Tag.Get("json"), ",")[0]
+		if tag != "" && tag != "-" {
+			index[tag] = i
+		}
+	}
+	return index
+}()
+
+// contactSnapshot serializes a contact for storage in merge history
+func contactSnapshot(contact *models.Contact) models.JSONMap {
+	data, err := json.Marshal(contact)
+	if err != nil {
+		return nil
+	}
+	snapshot := models.JSONMap{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil
+	}
+	return snapshot
+}
+
+// contactFromSnapshot rebuilds a contact from a snapshot stored in merge history
+func contactFromSnapshot(snapshot interface{}) (*models.Contact, error) {
+	if snapshot == nil {
+		return nil, fmt.Errorf("snapshot is missing")
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var contact models.Contact
+	if err := json.Unmarshal(data, &contact); err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// snapshotIDs converts a JSON-decoded list of IDs into uints
+func snapshotIDs(value interface{}) []uint {
+	list, _ := value.([]interface{})
+	ids := make([]uint, 0, len(list))
+	for _, item := range list {
+		if id, ok := item.(float64); ok {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// sameJSONValue compares two values by their JSON encoding, so typed and decoded values match
+func sameJSONValue(a, b interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	var decodedA, decodedB interface{}
+	if json.Unmarshal(encodedA, &decodedA) != nil || json.Unmarshal(encodedB, &decodedB) != nil {
+		return false
+	}
+	return reflect.DeepEqual(decodedA, decodedB)
+}
+
+func isValidFieldMergeStrategy(strategy models.FieldMergeStrategy) bool {
+	switch strategy {
+	case models.FieldKeepMaster, models.FieldKeepDuplicate, models.FieldFillEmpty,
+		models.FieldMostRecent, models.FieldUnion, models.FieldConcatenate:
+		return true
+	}
+	return false
+}
+
+func isEmptyFieldValue(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr:
+		return v.IsNil() || v.Elem().IsZero()
+	case reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func derefFieldValue(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		return v.Elem().Interface()
+	}
+	return value
+}
+
+// normalizeEmail lowercases an email and strips plus-addressing and gmail dots
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+	if domain == "gmail.com" || domain == "googlemail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+	return local + "@" + domain
+}
+
+// normalizePhone keeps the last 10 digits of a phone number
+func normalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if unicode.IsDigit(r) {
+			digits.WriteRune(r)
+		}
+	}
+	result := digits.String()
+	if len(result) < 7 {
+		return ""
+	}
+	if len(result) > 10 {
+		result = result[len(result)-10:]
+	}
+	return result
+}
+
+// normalizeText lowercases and strips punctuation, collapsing whitespace
+func normalizeText(value string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		} else {
+			builder.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// normalizeCompany normalizes a company name and drops common legal suffixes
+func normalizeCompany(company string) string {
+	suffixes := map[string]bool{
+		"inc": true, "llc": true, "ltd": true, "limited": true, "pvt": true,
+		"private": true, "corp": true, "corporation": true, "co": true, "plc": true, "gmbh": true,
+	}
+	var words []string
+	for _, word := range strings.Fields(normalizeText(company)) {
+		if !suffixes[word] {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// stringSimilarity returns a 0-1 similarity based on Levenshtein distance
+func stringSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(previous[len(rb)])/float64(longest)
+}
+
+func roundScore(score float64) float64 {
+	return float64(int(score*100+0.5)) / 100
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
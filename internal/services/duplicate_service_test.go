@@ -0,0 +1,338 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"contact-service/internal/models"
+	apperrors "contact-service/pkg/errors"
+)
+
+// newDuplicateTestService returns a duplicate service on an in-memory database
+func newDuplicateTestService(t *testing.T) (*DuplicateService, *gorm.DB) {
+	db := newTestDB(t,
+		&models.Contact{},
+		&models.ContactFieldHistory{},
+		&models.ContactDuplicateGroup{},
+		&models.ContactDuplicateMember{},
+		&models.ContactMergeHistory{},
+		&models.ContactActivity{},
+		&models.Appointment{},
+		&models.ContactTagAssignment{},
+		&models.ContactAssignment{},
+	)
+	return NewDuplicateService(db), db
+}
+
+func createDuplicateTestContact(t *testing.T, db *gorm.DB, contact *models.Contact) *models.Contact {
+	contact.ContactTypeID = 1
+	contact.ContactSourceID = 1
+	require.NoError(t, db.Create(contact).Error)
+	return contact
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	assert.Equal(t, "asharao@gmail.com", normalizeEmail(" Asha.Rao+crm@GoogleMail.com "))
+	assert.Equal(t, "asha.rao@example.com", normalizeEmail("Asha.Rao+news@example.com"))
+	assert.Equal(t, "not-an-email", normalizeEmail("Not-An-Email"))
+}
+
+func TestNormalizePhone(t *testing.T) {
+	assert.Equal(t, "9876543210", normalizePhone("+91 98765-43210"))
+	assert.Equal(t, "9876543210", normalizePhone("(987) 654 3210"))
+	assert.Empty(t, normalizePhone("12-34"), "numbers too short to identify anyone are ignored")
+}
+
+func TestNormalizeCompany(t *testing.T) {
+	assert.Equal(t, "acme widgets", normalizeCompany("ACME Widgets Pvt. Ltd."))
+	assert.Equal(t, "acme widgets", normalizeCompany("Acme-Widgets, Inc"))
+}
+
+func TestScoreDuplicatePair(t *testing.T) {
+	candidate := func(first, last, email, phone, company string) *duplicateCandidate {
+		contact := models.Contact{FirstName: first, LastName: &last, Email: email}
+		if phone != "" {
+			contact.Phone = &phone
+		}
+		if company != "" {
+			contact.Company = &company
+		}
+		return newDuplicateCandidate(contact)
+	}
+
+	score, fields := scoreDuplicatePair(
+		candidate("Asha", "Rao", "asha.rao+crm@gmail.com", "+91 98765 43210", "Acme Ltd"),
+		candidate("Asha", "Rao", "asharao@gmail.com", "09876543210", "ACME"),
+	)
+	assert.Equal(t, 100.0, score)
+	assert.Equal(t, []string{"email", "phone", "name", "company"}, fields)
+
+	// Without company on both sides its weight goes to the name
+	score, fields = scoreDuplicatePair(
+		candidate("Asha", "Rao", "asha@example.com", "", ""),
+		candidate("Asha", "Rao", "asha@example.org", "", "Acme"),
+	)
+	assert.Equal(t, 50.0, score)
+	assert.Equal(t, []string{"name"}, fields)
+
+	score, fields = scoreDuplicatePair(
+		candidate("Asha", "Rao", "asha@example.com", "", ""),
+		candidate("Vikram", "Shah", "vikram@example.com", "", ""),
+	)
+	assert.Less(t, score, defaultDuplicateThreshold)
+	assert.Empty(t, fields)
+}
+
+func TestClusterDuplicateMatches(t *testing.T) {
+	clusters := clusterDuplicateMatches([]duplicateMatch{
+		{ContactA: 1, ContactB: 2, Score: 90},
+		{ContactA: 5, ContactB: 6, Score: 80},
+		{ContactA: 2, ContactB: 3, Score: 75},
+		{ContactA: 3, ContactB: 4, Score: 85},
+	})
+	require.Len(t, clusters, 2)
+
+	contacts := func(cluster []duplicateMatch) map[uint]bool {
+		ids := make(map[uint]bool)
+		for _, match := range cluster {
+			ids[match.ContactA], ids[match.ContactB] = true, true
+		}
+		return ids
+	}
+	assert.Equal(t, map[uint]bool{1: true, 2: true, 3: true, 4: true}, contacts(clusters[0]))
+	assert.Len(t, clusters[0], 3)
+	assert.Equal(t, map[uint]bool{5: true, 6: true}, contacts(clusters[1]))
+}
+
+func TestResolveMergeFields(t *testing.T) {
+	now := time.Now()
+	master := &models.Contact{
+		FirstName:    "Asha",
+		Email:        "asha@example.com",
+		City:         stringPtr("Pune"),
+		Notes:        stringPtr("Met at the expo"),
+		CustomFields: models.JSONMap{"industry": "retail"},
+		UpdatedAt:    now.Add(-time.Hour),
+	}
+	duplicate := &models.Contact{
+		FirstName:       "Asha R",
+		Email:           "asha.rao@example.com",
+		Phone:           stringPtr("+919876543210"),
+		City:            stringPtr("Mumbai"),
+		Notes:           stringPtr("Asked for pricing"),
+		CustomFields:    models.JSONMap{"industry": "wholesale", "size": "50"},
+		LastContactDate: &now,
+		UpdatedAt:       now,
+	}
+
+	updates, merged, conflicts := resolveMergeFields(master, duplicate, map[string]models.FieldMergeStrategy{
+		"city": models.FieldKeepDuplicate,
+	})
+
+	// keep_master leaves the master's values alone but records the conflict
+	assert.NotContains(t, updates, "first_name")
+	assert.NotContains(t, updates, "email")
+	assert.Equal(t, "master", conflicts["email"].(map[string]interface{})["resolved"])
+
+	// fill_empty takes the duplicate's value where the master has none
+	assert.Equal(t, duplicate.Phone, updates["phone"])
+	assert.NotContains(t, conflicts, "phone")
+
+	// Per-request overrides replace the default strategy
+	assert.Equal(t, duplicate.City, updates["city"])
+	assert.Equal(t, "duplicate", conflicts["city"].(map[string]interface{})["resolved"])
+
+	// most_recent fills an empty master value
+	assert.Equal(t, duplicate.LastContactDate, updates["last_contact_date"])
+
+	// union keeps the master's keys and adds the duplicate's
+	assert.Equal(t, models.JSONMap{"industry": "retail", "size": "50"}, updates["custom_fields"])
+
+	// concatenate keeps both texts
+	assert.Equal(t, "Met at the expo\n\nAsked for pricing", *updates["notes"].(*string))
+	assert.Equal(t, "combined", conflicts["notes"].(map[string]interface{})["resolved"])
+
+	for column := range updates {
+		assert.Contains(t, merged, column)
+	}
+}
+
+func TestDetectDuplicatesGroupsMatchingContacts(t *testing.T) {
+	service, db := newDuplicateTestService(t)
+	older := createDuplicateTestContact(t, db, &models.Contact{
+		FirstName: "Asha", LastName: stringPtr("Rao"), Email: "asha.rao@gmail.com",
+		CreatedAt: time.Now().Add(-48 * time.Hour),
+	})
+	newer := createDuplicateTestContact(t, db, &models.Contact{
+		FirstName: "Asha", LastName: stringPtr("Rao"), Email: "AshaRao+web@gmail.com",
+	})
+	createDuplicateTestContact(t, db, &models.Contact{
+		FirstName: "Vikram", LastName: stringPtr("Shah"), Email: "vikram@example.com",
+	})
+
+	response, err := service.DetectDuplicates(&models.DuplicateDetectionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, response.ContactsScanned)
+	require.Equal(t, 1, response.GroupsFound)
+
+	group := response.Groups[0]
+	assert.Equal(t, models.DuplicateStatusDetected, group.Status)
+	assert.Equal(t, older.ID, *group.MasterContactID, "the oldest contact should be proposed as master")
+	assert.Equal(t, 1, group.DuplicateCount)
+	require.Len(t, group.Members, 2)
+	assert.Equal(t, newer.ID, group.Members[1].ContactID)
+
+	// Running detection again refreshes the same group rather than adding one
+	response, err = service.DetectDuplicates(&models.DuplicateDetectionRequest{})
+	require.NoError(t, err)
+	require.Equal(t, 1, response.GroupsFound)
+	assert.Equal(t, group.ID, response.Groups[0].ID)
+	var groups int64
+	require.NoError(t, db.Model(&models.ContactDuplicateGroup{}).Count(&groups).Error)
+	assert.Equal(t, int64(1), groups)
+}
+
+func TestMergeContactsReparentsRecords(t *testing.T) {
+	service, db := newDuplicateTestService(t)
+	master := createDuplicateTestContact(t, db, &models.Contact{FirstName: "Asha", Email: "asha@example.com"})
+	duplicate := createDuplicateTestContact(t, db, &models.Contact{
+		FirstName: "Asha", Email: "asha.rao@example.com", Phone: stringPtr("+919876543210"),
+	})
+
+	activity := &models.ContactActivity{ContactID: duplicate.ID, ActivityType: models.ActivityType("call"), Title: "Intro call"}
+	require.NoError(t, db.Create(activity).Error)
+	sharedTag := &models.ContactTagAssignment{ContactID: duplicate.ID, TagID: 1}
+	ownTag := &models.ContactTagAssignment{ContactID: duplicate.ID, TagID: 2}
+	require.NoError(t, db.Create(&models.ContactTagAssignment{ContactID: master.ID, TagID: 1}).Error)
+	require.NoError(t, db.Create(sharedTag).Error)
+	require.NoError(t, db.Create(ownTag).Error)
+
+	response, err := service.MergeContacts(&models.ContactMergeRequest{
+		MasterContactID:     master.ID,
+		DuplicateContactIDs: []uint{duplicate.ID},
+	}, 1)
+	require.NoError(t, err)
+	require.Len(t, response.Merges, 1)
+	assert.Equal(t, "+919876543210", *response.MasterContact.Phone)
+
+	require.NoError(t, db.First(activity, activity.ID).Error)
+	assert.Equal(t, master.ID, activity.ContactID)
+	require.NoError(t, db.First(ownTag, ownTag.ID).Error)
+	assert.Equal(t, master.ID, ownTag.ContactID)
+	require.NoError(t, db.First(sharedTag, sharedTag.ID).Error)
+	assert.Equal(t, duplicate.ID, sharedTag.ContactID, "a tag the master already has should stay on the merged contact")
+
+	var merged models.Contact
+	require.NoError(t, db.First(&merged, duplicate.ID).Error)
+	assert.NotNil(t, merged.DeletedAt)
+	assert.True(t, merged.IsDuplicate)
+	assert.Equal(t, master.ID, *merged.OriginalContactID)
+}
+
+// mergeDuplicateTestContacts merges a contact with a phone number, one activity and one tag into a
+// master without them, and returns the master, the merged contact and the merge history
+func mergeDuplicateTestContacts(t *testing.T, service *DuplicateService, db *gorm.DB) (*models.Contact, *models.Contact, *models.ContactMergeHistory) {
+	master := createDuplicateTestContact(t, db, &models.Contact{FirstName: "Asha", Email: "asha@example.com", Notes: stringPtr("Met at the expo")})
+	duplicate := createDuplicateTestContact(t, db, &models.Contact{
+		FirstName: "Asha", Email: "asha.rao@example.com", Phone: stringPtr("+919876543210"), Notes: stringPtr("Asked for pricing"),
+	})
+	require.NoError(t, db.Create(&models.ContactActivity{ContactID: duplicate.ID, ActivityType: models.ActivityType("call"), Title: "Intro call"}).Error)
+	require.NoError(t, db.Create(&models.ContactTagAssignment{ContactID: duplicate.ID, TagID: 2}).Error)
+
+	response, err := service.MergeContacts(&models.ContactMergeRequest{
+		MasterContactID:     master.ID,
+		DuplicateContactIDs: []uint{duplicate.ID},
+	}, 1)
+	require.NoError(t, err)
+	require.Len(t, response.Merges, 1)
+	return master, duplicate, &response.Merges[0]
+}
+
+func TestUnmergeContactRestoresBothContacts(t *testing.T) {
+	service, db := newDuplicateTestService(t)
+	master, duplicate, history := mergeDuplicateTestContacts(t, service, db)
+
+	response, err := service.UnmergeContact(history.ID, &models.ContactUnmergeRequest{}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"notes", "phone"}, response.RestoredFields)
+	assert.Nil(t, response.MasterContact.Phone)
+	assert.Equal(t, "Met at the expo", *response.MasterContact.Notes)
+
+	assert.Nil(t, response.RestoredContact.DeletedAt)
+	assert.False(t, response.RestoredContact.IsDuplicate)
+	assert.Nil(t, response.RestoredContact.OriginalContactID)
+	assert.Equal(t, "+919876543210", *response.RestoredContact.Phone)
+
+	var restored models.ContactMergeHistory
+	require.NoError(t, db.First(&restored, history.ID).Error)
+	assert.True(t, restored.IsUnmerged())
+	assert.Equal(t, uint(2), *restored.UnmergedBy)
+
+	_, err = service.UnmergeContact(history.ID, &models.ContactUnmergeRequest{}, 2)
+	assert.EqualError(t, err, "merge has already been undone")
+
+	require.NoError(t, db.First(master, master.ID).Error)
+	assert.Nil(t, master.DeletedAt)
+	require.NoError(t, db.First(duplicate, duplicate.ID).Error)
+	assert.Nil(t, duplicate.DeletedAt)
+}
+
+func TestUnmergeContactReportsConflictingEdits(t *testing.T) {
+	service, db := newDuplicateTestService(t)
+	master, duplicate, history := mergeDuplicateTestContacts(t, service, db)
+
+	// The master's phone was edited after the merge; its notes were not
+	require.NoError(t, db.Model(&models.Contact{}).Where("id = ?", master.ID).Update("phone", "+911234567890").Error)
+
+	_, err := service.UnmergeContact(history.ID, &models.ContactUnmergeRequest{}, 2)
+	appErr, ok := err.(*apperrors.AppError)
+	require.True(t, ok, "expected a conflict error, got %v", err)
+	assert.Equal(t, apperrors.ErrCodeConflict, appErr.Code)
+	require.Len(t, appErr.FieldErrors, 1)
+	assert.Equal(t, "phone", appErr.FieldErrors[0].Field)
+	assert.Equal(t, "MERGE_CONFLICT", appErr.FieldErrors[0].Code)
+	assert.Equal(t, map[string]interface{}{
+		"current":  "+911234567890",
+		"merged":   "+919876543210",
+		"original": nil,
+	}, appErr.FieldErrors[0].Value)
+
+	// Nothing was undone
+	var merged models.Contact
+	require.NoError(t, db.First(&merged, duplicate.ID).Error)
+	assert.NotNil(t, merged.DeletedAt)
+	var pending models.ContactMergeHistory
+	require.NoError(t, db.First(&pending, history.ID).Error)
+	assert.False(t, pending.IsUnmerged())
+}
+
+func TestUnmergeContactMovesRecordsBack(t *testing.T) {
+	service, db := newDuplicateTestService(t)
+	master, duplicate, history := mergeDuplicateTestContacts(t, service, db)
+
+	// Records added to the master after the merge stay with it
+	later := &models.ContactActivity{ContactID: master.ID, ActivityType: models.ActivityType("email"), Title: "Follow-up"}
+	require.NoError(t, db.Create(later).Error)
+
+	response, err := service.UnmergeContact(history.ID, &models.ContactUnmergeRequest{}, 2)
+	require.NoError(t, err)
+	assert.Len(t, response.MovedRecords["contact_activities"], 1)
+	assert.Len(t, response.MovedRecords["contact_tag_assignments"], 1)
+
+	var activities, tags int64
+	require.NoError(t, db.Model(&models.ContactActivity{}).Where("contact_id = ?", duplicate.ID).Count(&activities).Error)
+	require.NoError(t, db.Model(&models.ContactTagAssignment{}).Where("contact_id = ?", duplicate.ID).Count(&tags).Error)
+	assert.Equal(t, int64(1), activities)
+	assert.Equal(t, int64(1), tags)
+
+	require.NoError(t, db.First(later, later.ID).Error)
+	assert.Equal(t, master.ID, later.ContactID)
+}
@@ -0,0 +1,605 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/internal/repository"
+	"contact-service/pkg/ical"
+	"contact-service/pkg/logger"
+	"contact-service/pkg/mailer"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Default number of delivery attempts for an outbound email
+const defaultEmailMaxAttempts = 3
+
+// Metadata keys stored on queued communications and read back when the message is built
+const (
+	emailMetadataReplyTo        = "reply_to"
+	emailMetadataInReplyTo      = "in_reply_to"
+	emailMetadataAppointmentID  = "appointment_id"
+	emailMetadataCalendarMethod = "calendar_method" // iTIP method of an attached calendar invite
+	emailMetadataCalendar       = "calendar"        // iCalendar object attached as invite.ics
+)
+
+// EmailConfig configures outbound email delivery
+type EmailConfig struct {
+	FromEmail    string
+	FromName     string
+	Workers      int           // Number of messages sent concurrently
+	PollInterval time.Duration // How often idle workers look for due messages
+	RetryDelay   time.Duration // Wait before the first retry; doubles on every further attempt
+	MaxAttempts  int
+	ClaimTimeout time.Duration // Sends claimed longer ago than this are assumed abandoned by a stopped worker
+}
+
+// LoadEmailConfig reads the email delivery configuration from the environment
+func LoadEmailConfig() EmailConfig {
+	config := EmailConfig{
+		FromEmail:    os.Getenv("SMTP_FROM_EMAIL"),
+		FromName:     os.Getenv("SMTP_FROM_NAME"),
+		Workers:      2,
+		PollInterval: 10 * time.Second,
+		RetryDelay:   time.Minute,
+		MaxAttempts:  defaultEmailMaxAttempts,
+		ClaimTimeout: 10 * time.Minute,
+	}
+
+	if config.FromEmail == "" {
+		config.FromEmail = "no-reply@localhost"
+	}
+	if workers, err := strconv.Atoi(os.Getenv("EMAIL_WORKERS")); err == nil && workers > 0 {
+		config.Workers = workers
+	}
+	if attempts, err := strconv.Atoi(os.Getenv("EMAIL_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		config.MaxAttempts = attempts
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("EMAIL_RETRY_DELAY_SECONDS")); err == nil && seconds > 0 {
+		config.RetryDelay = time.Duration(seconds) * time.Second
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("EMAIL_CLAIM_TIMEOUT_SECONDS")); err == nil && seconds > 0 {
+		config.ClaimTimeout = time.Duration(seconds) * time.Second
+	}
+
+	return config
+}
+
+// EmailService queues outbound email in contact_communications and delivers it on background workers
+type EmailService struct {
+	commRepo        repository.CommunicationRepository
+	contactRepo     repository.ContactRepository
+	templateService *TemplateService
+	mailer          mailer.Mailer
+	tracker         *mailer.Tracker
+	config          EmailConfig
+	wake            chan struct{}
+	startOnce       sync.Once
+}
+
+// NewEmailService creates a new email service. A nil tracker sends HTML without open and click tracking.
+func NewEmailService(commRepo repository.CommunicationRepository, contactRepo repository.ContactRepository, templateService *TemplateService, m mailer.Mailer, tracker *mailer.Tracker, config EmailConfig) *EmailService {
+	return &EmailService{
+		commRepo:        commRepo,
+		contactRepo:     contactRepo,
+		templateService: templateService,
+		mailer:          m,
+		tracker:         tracker,
+		config:          config,
+		wake:            make(chan struct{}, 1),
+	}
+}
+
+// Start requeues abandoned sends and launches the delivery workers. Only sends claimed longer ago
+// than the claim timeout are requeued, so messages other instances are delivering are left alone.
+// Calling it again has no effect.
+func (s *EmailService) Start() error {
+	var startErr error
+	s.startOnce.Do(func() {
+		if err := s.recoverStaleClaims(); err != nil {
+			startErr = fmt.Errorf("failed to requeue interrupted emails: %v", err)
+			return
+		}
+
+		for i := 0; i < s.config.Workers; i++ {
+			go s.worker()
+		}
+		go s.recoverer()
+
+		logger.Info("Email delivery workers started", map[string]interface{}{
+			"workers": s.config.Workers,
+		})
+	})
+	return startErr
+}
+
+// QueueEmail renders an email for a contact, records it with a matching activity and queues it for delivery
+func (s *EmailService) QueueEmail(req *models.SendEmailRequest, userID uint) (*models.ContactCommunication, error) {
+	contact, err := s.contactRepo.GetByID(req.ContactID)
+	if err != nil || contact.DeletedAt != nil {
+		if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("contact not found")
+		}
+		return nil, fmt.Errorf("failed to get contact: %v", err)
+	}
+	if contact.Unsubscribed {
+		return nil, fmt.Errorf("contact has unsubscribed from email")
+	}
+
+	template, err := s.emailTemplate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := s.templateService.ResolveTemplateVariables(contact.ID, req.AppointmentID, &userID)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range req.Variables {
+		values[name] = value
+	}
+	rendered := template.Render(values)
+	if !rendered.IsComplete() {
+		return nil, fmt.Errorf("missing template variables: %s", strings.Join(rendered.MissingVariables, ", "))
+	}
+
+	messageID, err := mailer.NewMessageID(s.config.FromEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate message ID: %v", err)
+	}
+
+	now := time.Now()
+	metadata := models.JSONMap{}
+	if replyTo := values["sender.email"]; replyTo != "" {
+		metadata[emailMetadataReplyTo] = replyTo
+	}
+	threadID := messageID
+	if req.ParentCommunicationID != nil {
+		parent, err := s.commRepo.GetByID(*req.ParentCommunicationID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("parent communication not found")
+			}
+			return nil, fmt.Errorf("failed to get parent communication: %v", err)
+		}
+		if parent.ContactID != contact.ID {
+			return nil, fmt.Errorf("invalid parent communication: it belongs to another contact")
+		}
+		if parent.EmailMessageID != nil {
+			metadata[emailMetadataInReplyTo] = *parent.EmailMessageID
+			threadID = *parent.EmailMessageID
+		}
+		if parent.EmailThreadID != nil {
+			threadID = *parent.EmailThreadID
+		}
+	}
+
+	priority := req.Priority
+	if priority == "" {
+		priority = models.PriorityMedium
+	}
+
+	communication := &models.ContactCommunication{
+		ContactID:             contact.ID,
+		CommunicationType:     models.CommunicationEmail,
+		Direction:             models.DirectionOutbound,
+		Subject:               &rendered.Subject,
+		Content:               nonEmpty(rendered.PlainContent),
+		Status:                models.CommunicationStatusQueued,
+		Priority:              priority,
+		FromEmail:             &s.config.FromEmail,
+		ToEmail:               &contact.Email,
+		CCEmails:              stringsToJSONArray(req.CC),
+		BCCEmails:             stringsToJSONArray(req.BCC),
+		EmailMessageID:        &messageID,
+		EmailThreadID:         &threadID,
+		EmailTemplateID:       req.TemplateID,
+		TemplateID:            req.TemplateID,
+		HTMLContent:           nonEmpty(rendered.HTMLContent),
+		PlainContent:          nonEmpty(rendered.PlainContent),
+		MaxSendAttempts:       s.config.MaxAttempts,
+		NextAttemptAt:         &now,
+		ParentCommunicationID: req.ParentCommunicationID,
+		SentBy:                &userID,
+		AssignedTo:            contact.AssignedTo,
+		Metadata:              metadata,
+		CreatedBy:             &userID,
+		UpdatedBy:             &userID,
+	}
+	activity := &models.ContactActivity{
+		ActivityType: models.ActivityEmailSent,
+		Title:        truncate("Email: "+rendered.Subject, 255),
+		ActivityDate: now,
+		Status:       models.ActivityStatusPending,
+		Priority:     priority,
+		Direction:    models.DirectionOutbound,
+		Channel:      models.ChannelEmail,
+		PerformedBy:  userID,
+		AssignedTo:   contact.AssignedTo,
+	}
+
+	if err := s.commRepo.CreateWithActivity(communication, activity); err != nil {
+		return nil, fmt.Errorf("failed to queue email: %v", err)
+	}
+	if req.TemplateID != nil {
+		if err := s.templateService.IncrementUsage(*req.TemplateID); err != nil {
+			logger.Error("Failed to record template usage", err, map[string]interface{}{
+				"template_id": *req.TemplateID,
+			})
+		}
+	}
+
+	logger.Info("Email queued", map[string]interface{}{
+		"communication_id": communication.ID,
+		"contact_id":       contact.ID,
+		"template_id":      req.TemplateID,
+		"sent_by":          userID,
+	})
+
+	s.notify()
+	return communication, nil
+}
+
+// queueCommunication records an outbound email built by another service, with a matching
+// activity, and queues it for delivery
+func (s *EmailService) queueCommunication(communication *models.ContactCommunication, activity *models.ContactActivity) error {
+	messageID, err := mailer.NewMessageID(s.config.FromEmail)
+	if err != nil {
+		return fmt.Errorf("failed to generate message ID: %v", err)
+	}
+
+	now := time.Now()
+	communication.CommunicationType = models.CommunicationEmail
+	communication.Direction = models.DirectionOutbound
+	communication.Status = models.CommunicationStatusQueued
+	communication.FromEmail = &s.config.FromEmail
+	communication.EmailMessageID = &messageID
+	communication.EmailThreadID = &messageID
+	communication.MaxSendAttempts = s.config.MaxAttempts
+	communication.NextAttemptAt = &now
+	if communication.Priority == "" {
+		communication.Priority = models.PriorityMedium
+	}
+
+	activity.ActivityType = models.ActivityEmailSent
+	activity.ActivityDate = now
+	activity.Status = models.ActivityStatusPending
+	activity.Priority = communication.Priority
+	activity.Direction = models.DirectionOutbound
+	activity.Channel = models.ChannelEmail
+
+	if err := s.commRepo.CreateWithActivity(communication, activity); err != nil {
+		return fmt.Errorf("failed to queue email: %v", err)
+	}
+
+	s.notify()
+	return nil
+}
+
+// GetCommunication retrieves a communication by ID
+func (s *EmailService) GetCommunication(id uint) (*models.ContactCommunication, error) {
+	communication, err := s.commRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("communication not found")
+		}
+		return nil, fmt.Errorf("failed to get communication: %v", err)
+	}
+	return communication, nil
+}
+
+// ListContactCommunications retrieves a contact's communications newest first
+func (s *EmailService) ListContactCommunications(contactID uint, page, limit int) ([]models.ContactCommunication, int64, error) {
+	communications, total, err := s.commRepo.ListByContact(contactID, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list communications: %v", err)
+	}
+	return communications, total, nil
+}
+
+// RetryCommunication requeues a failed email with a fresh set of attempts
+func (s *EmailService) RetryCommunication(id uint, userID uint) (*models.ContactCommunication, error) {
+	communication, err := s.GetCommunication(id)
+	if err != nil {
+		return nil, err
+	}
+	if communication.CommunicationType != models.CommunicationEmail || communication.Status != models.CommunicationStatusFailed {
+		return nil, fmt.Errorf("only failed emails can be retried (status: %s)", communication.Status)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":          models.CommunicationStatusQueued,
+		"send_attempts":   0,
+		"next_attempt_at": now,
+		"last_error":      nil,
+		"updated_by":      userID,
+	}
+	if err := s.commRepo.Update(id, updates); err != nil {
+		return nil, fmt.Errorf("failed to requeue email: %v", err)
+	}
+	if err := s.commRepo.UpdateActivity(id, map[string]interface{}{
+		"status":  models.ActivityStatusPending,
+		"outcome": nil,
+	}); err != nil {
+		logger.Error("Failed to reset email activity", err, map[string]interface{}{
+			"communication_id": id,
+		})
+	}
+
+	communication.Status = models.CommunicationStatusQueued
+	communication.SendAttempts = 0
+	communication.NextAttemptAt = &now
+	communication.LastError = nil
+
+	s.notify()
+	return communication, nil
+}
+
+// emailTemplate returns the stored template of the request, or an unsaved one wrapping its ad-hoc content
+func (s *EmailService) emailTemplate(req *models.SendEmailRequest) (*models.CommunicationTemplate, error) {
+	if req.TemplateID == nil {
+		if isBlank(req.Subject) {
+			return nil, fmt.Errorf("invalid email: subject is required without a template")
+		}
+		if isBlank(req.HTMLContent) && isBlank(req.PlainContent) {
+			return nil, fmt.Errorf("invalid email: html_content or plain_content is required without a template")
+		}
+		return &models.CommunicationTemplate{
+			CommunicationType: models.TemplateChannelEmail,
+			Subject:           req.Subject,
+			HTMLContent:       req.HTMLContent,
+			PlainContent:      req.PlainContent,
+		}, nil
+	}
+
+	template, err := s.templateService.GetTemplate(*req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if template.CommunicationType != models.TemplateChannelEmail {
+		return nil, fmt.Errorf("invalid template: %s templates cannot be sent as email", template.CommunicationType)
+	}
+	if !template.IsActive {
+		return nil, fmt.Errorf("communication template is inactive")
+	}
+	return template, nil
+}
+
+// recoverStaleClaims returns sends abandoned by a stopped worker to the queue
+func (s *EmailService) recoverStaleClaims() error {
+	requeued, err := s.commRepo.RequeueSending(time.Now().Add(-s.config.ClaimTimeout))
+	if err != nil {
+		return err
+	}
+	if requeued > 0 {
+		logger.Warn("Requeued interrupted outbound emails", map[string]interface{}{
+			"count": requeued,
+		})
+		s.notify()
+	}
+	return nil
+}
+
+// recoverer periodically requeues sends abandoned by instances that stopped while running
+func (s *EmailService) recoverer() {
+	ticker := time.NewTicker(s.config.ClaimTimeout)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.recoverStaleClaims(); err != nil {
+			logger.Error("Failed to requeue interrupted emails", err, nil)
+		}
+	}
+}
+
+// notify wakes an idle worker without blocking
+func (s *EmailService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *EmailService) worker() {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for s.sendNext() {
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// sendNext claims and delivers one due email, reporting whether there was one
+func (s *EmailService) sendNext() bool {
+	communication, err := s.commRepo.ClaimNextQueued(time.Now())
+	if err != nil {
+		logger.Error("Failed to claim queued email", err, nil)
+		return false
+	}
+	if communication == nil {
+		return false
+	}
+
+	s.deliver(communication)
+	return true
+}
+
+func (s *EmailService) deliver(communication *models.ContactCommunication) {
+	err := s.mailer.Send(s.buildMessage(communication))
+	attempts := communication.SendAttempts + 1
+	now := time.Now()
+
+	if err == nil {
+		if err := s.commRepo.Update(communication.ID, map[string]interface{}{
+			"status":          models.CommunicationStatusSent,
+			"claimed_at":      nil,
+			"send_attempts":   attempts,
+			"sent_at":         now,
+			"next_attempt_at": nil,
+			"last_error":      nil,
+		}); err != nil {
+			logger.Error("Failed to mark email sent", err, map[string]interface{}{
+				"communication_id": communication.ID,
+			})
+			return
+		}
+		if err := s.commRepo.UpdateActivity(communication.ID, map[string]interface{}{
+			"status":         models.ActivityStatusCompleted,
+			"activity_date":  now,
+			"completed_date": now,
+		}); err != nil {
+			logger.Error("Failed to complete email activity", err, map[string]interface{}{
+				"communication_id": communication.ID,
+			})
+		}
+
+		logger.Info("Email sent", map[string]interface{}{
+			"communication_id": communication.ID,
+			"contact_id":       communication.ContactID,
+			"attempts":         attempts,
+		})
+		return
+	}
+
+	message := err.Error()
+	if !mailer.IsPermanent(err) && attempts < communication.MaxSendAttempts {
+		nextAttempt := now.Add(s.config.RetryDelay << (attempts - 1))
+		if updateErr := s.commRepo.Update(communication.ID, map[string]interface{}{
+			"status":          models.CommunicationStatusQueued,
+			"claimed_at":      nil,
+			"send_attempts":   attempts,
+			"next_attempt_at": nextAttempt,
+			"last_error":      message,
+		}); updateErr != nil {
+			logger.Error("Failed to requeue email", updateErr, map[string]interface{}{
+				"communication_id": communication.ID,
+			})
+		}
+
+		logger.Warn("Email delivery failed, will retry", map[string]interface{}{
+			"communication_id": communication.ID,
+			"attempt":          attempts,
+			"next_attempt_at":  nextAttempt,
+			"error":            message,
+		})
+		return
+	}
+
+	if updateErr := s.commRepo.Update(communication.ID, map[string]interface{}{
+		"status":          models.CommunicationStatusFailed,
+		"claimed_at":      nil,
+		"send_attempts":   attempts,
+		"next_attempt_at": nil,
+		"last_error":      message,
+	}); updateErr != nil {
+		logger.Error("Failed to mark email failed", updateErr, map[string]interface{}{
+			"communication_id": communication.ID,
+		})
+	}
+	if updateErr := s.commRepo.UpdateActivity(communication.ID, map[string]interface{}{
+		"status":  models.ActivityStatusCancelled,
+		"outcome": "Delivery failed: " + message,
+	}); updateErr != nil {
+		logger.Error("Failed to cancel email activity", updateErr, map[string]interface{}{
+			"communication_id": communication.ID,
+		})
+	}
+
+	logger.Error("Email delivery failed", err, map[string]interface{}{
+		"communication_id": communication.ID,
+		"attempts":         attempts,
+	})
+}
+
+// buildMessage converts a stored communication into a mailer message
+func (s *EmailService) buildMessage(communication *models.ContactCommunication) *mailer.Message {
+	msg := &mailer.Message{
+		From:    mailer.Address{Name: s.config.FromName, Email: stringValue(communication.FromEmail)},
+		To:      []mailer.Address{{Email: stringValue(communication.ToEmail)}},
+		CC:      jsonArrayToAddresses(communication.CCEmails),
+		BCC:     jsonArrayToAddresses(communication.BCCEmails),
+		Subject: stringValue(communication.Subject),
+		Headers: map[string]string{
+			"X-Communication-ID": strconv.FormatUint(uint64(communication.ID), 10),
+		},
+		HTMLBody:  stringValue(communication.HTMLContent),
+		PlainBody: stringValue(communication.PlainContent),
+	}
+	if s.tracker != nil && msg.HTMLBody != "" {
+		msg.HTMLBody = s.tracker.InstrumentHTML(communication.ID, msg.HTMLBody)
+	}
+	if communication.EmailMessageID != nil {
+		msg.MessageID = *communication.EmailMessageID
+	}
+	if replyTo, ok := communication.Metadata[emailMetadataReplyTo].(string); ok && replyTo != "" {
+		msg.Headers["Reply-To"] = replyTo
+	}
+	if inReplyTo, ok := communication.Metadata[emailMetadataInReplyTo].(string); ok {
+		msg.InReplyTo = inReplyTo
+	}
+	if appointmentID, ok := communication.Metadata[emailMetadataAppointmentID].(string); ok && appointmentID != "" {
+		msg.Headers["X-Appointment-ID"] = appointmentID
+	}
+	if calendar, ok := communication.Metadata[emailMetadataCalendar].(string); ok && calendar != "" {
+		method, _ := communication.Metadata[emailMetadataCalendarMethod].(string)
+		msg.Attachments = append(msg.Attachments, mailer.Attachment{
+			Filename:    "invite.ics",
+			ContentType: ical.ContentType(method),
+			Data:        []byte(calendar),
+		})
+	}
+	return msg
+}
+
+func stringsToJSONArray(values []string) models.JSONArray {
+	array := make(models.JSONArray, len(values))
+	for i, value := range values {
+		array[i] = value
+	}
+	return array
+}
+
+func jsonArrayToAddresses(values models.JSONArray) []mailer.Address {
+	var addresses []mailer.Address
+	for _, value := range values {
+		if email, ok := value.(string); ok && email != "" {
+			addresses = append(addresses, mailer.Address{Email: email})
+		}
+	}
+	return addresses
+}
+
+func nonEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+	return string(runes[:length])
+}
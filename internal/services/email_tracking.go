@@ -0,0 +1,145 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/auth"
+	"contact-service/pkg/logger"
+	"contact-service/pkg/mailer"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoadEmailTracker builds the open/click tracker from the environment.
+// Links point at EMAIL_TRACKING_BASE_URL, falling back to APP_URL; URLs are signed with EMAIL_TRACKING_SECRET,
+// or a key derived for tracking when it is not set.
+func LoadEmailTracker() *mailer.Tracker {
+	baseURL := os.Getenv("EMAIL_TRACKING_BASE_URL")
+	if baseURL == "" {
+		baseURL = strings.TrimRight(os.Getenv("APP_URL"), "/") + "/api/v1/track"
+	}
+	secret := []byte(os.Getenv("EMAIL_TRACKING_SECRET"))
+	if len(secret) == 0 {
+		secret = auth.DeriveKey("email-tracking")
+	}
+	return mailer.NewTracker(baseURL, secret)
+}
+
+// EmailTrackingService records email opens and clicks against contact_communications and the contact
+type EmailTrackingService struct {
+	db               *gorm.DB
+	tracker          *mailer.Tracker
+	lifecycleService *LifecycleService
+}
+
+// NewEmailTrackingService creates a new email tracking service
+func NewEmailTrackingService(db *gorm.DB, tracker *mailer.Tracker, lifecycleService *LifecycleService) *EmailTrackingService {
+	return &EmailTrackingService{
+		db:               db,
+		tracker:          tracker,
+		lifecycleService: lifecycleService,
+	}
+}
+
+// RecordOpen records a tracking pixel load. Requests with an invalid signature are ignored.
+func (s *EmailTrackingService) RecordOpen(communicationID uint, signature string) error {
+	if !s.tracker.VerifyOpen(communicationID, signature) {
+		return fmt.Errorf("invalid tracking signature")
+	}
+
+	now := time.Now()
+	return s.record(communicationID, false, map[string]interface{}{
+		"open_count":      gorm.Expr("open_count + 1"),
+		"opened_at":       gorm.Expr("COALESCE(opened_at, ?)", now),
+		"first_opened_at": gorm.Expr("COALESCE(first_opened_at, ?)", now),
+		"last_opened_at":  now,
+		"status":          readStatusExpr(),
+	})
+}
+
+// RecordClick records a tracked link click and returns the URL to redirect to.
+// A click implies an open, so the open timestamps are filled in if the pixel was blocked.
+func (s *EmailTrackingService) RecordClick(communicationID uint, target, signature string) (string, error) {
+	if !s.tracker.VerifyClick(communicationID, target, signature) {
+		return "", fmt.Errorf("invalid tracking signature")
+	}
+
+	now := time.Now()
+	err := s.record(communicationID, true, map[string]interface{}{
+		"click_count":      gorm.Expr("click_count + 1"),
+		"clicked_at":       gorm.Expr("COALESCE(clicked_at, ?)", now),
+		"first_clicked_at": gorm.Expr("COALESCE(first_clicked_at, ?)", now),
+		"last_clicked_at":  now,
+		"opened_at":        gorm.Expr("COALESCE(opened_at, ?)", now),
+		"first_opened_at":  gorm.Expr("COALESCE(first_opened_at, ?)", now),
+		"last_opened_at":   gorm.Expr("COALESCE(last_opened_at, ?)", now),
+		"open_count":       gorm.Expr("GREATEST(open_count, 1)"),
+		"status":           readStatusExpr(),
+	})
+	return target, err
+}
+
+// record applies the engagement updates, flags the contact and rescores it
+func (s *EmailTrackingService) record(communicationID uint, clicked bool, updates map[string]interface{}) error {
+	var communication models.ContactCommunication
+	if err := s.db.Select("id", "contact_id").First(&communication, communicationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("communication not found")
+		}
+		return fmt.Errorf("failed to get communication: %v", err)
+	}
+
+	if err := s.db.Model(&models.ContactCommunication{}).Where("id = ?", communicationID).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record email engagement: %v", err)
+	}
+
+	var contact models.Contact
+	if err := s.db.Where("deleted_at IS NULL").First(&contact, communication.ContactID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get contact: %v", err)
+	}
+
+	flags := make(map[string]interface{})
+	if !contact.EmailOpened {
+		flags["email_opened"] = true
+	}
+	if clicked && !contact.EmailClicked {
+		flags["email_clicked"] = true
+	}
+
+	reason := "Email opened"
+	if clicked {
+		reason = "Email link clicked"
+	}
+	if len(flags) > 0 {
+		before := contact
+		if err := s.db.Model(&contact).Updates(flags).Error; err != nil {
+			return fmt.Errorf("failed to update contact engagement: %v", err)
+		}
+		recordContactHistorySince(s.db, &before, nil, models.ChangeSourceTracking, reason)
+	}
+
+	// A first open or click always rescores; repeat engagement respects the rescoring interval
+	go func(contactID uint, force bool) {
+		if _, err := s.lifecycleService.ScoreContact(contactID, force, reason, nil); err != nil {
+			logger.Error("Failed to rescore contact after email engagement", err, map[string]interface{}{
+				"contact_id": contactID,
+			})
+		}
+	}(contact.ID, len(flags) > 0)
+
+	return nil
+}
+
+// readStatusExpr moves sent and delivered communications to read, leaving later statuses untouched
+func readStatusExpr() interface{} {
+	return gorm.Expr("CASE WHEN status IN (?, ?) THEN ? ELSE status END",
+		models.CommunicationStatusSent, models.CommunicationStatusDelivered, models.CommunicationStatusRead)
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/document"
+	"fmt"
+	"strconv"
+)
+
+// Content types and file extensions of the supported export formats
+var exportFormatFiles = map[ExportFormat]struct {
+	ContentType string
+	Extension   string
+}{
+	ExportFormatCSV:  {"text/csv", "csv"},
+	ExportFormatJSON: {"application/json", "json"},
+	ExportFormatXLSX: {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx"},
+	ExportFormatPDF:  {"application/pdf", "pdf"},
+}
+
+// NormalizeExportFormat maps format aliases such as "excel" to their canonical format
+func NormalizeExportFormat(format string) ExportFormat {
+	switch format {
+	case "excel", "xls":
+		return ExportFormatXLSX
+	}
+	return ExportFormat(format)
+}
+
+// ExportContentType returns the MIME type of an export format
+func ExportContentType(format ExportFormat) string {
+	if file, ok := exportFormatFiles[format]; ok {
+		return file.ContentType
+	}
+	return "application/octet-stream"
+}
+
+// ExportFileExtension returns the file extension of an export format
+func ExportFileExtension(format ExportFormat) string {
+	if file, ok := exportFormatFiles[format]; ok {
+		return file.Extension
+	}
+	return string(format)
+}
+
+// writeContactsWorkbook renders contacts with their activities and appointments, one sheet per entity
+func (s *BulkService) writeContactsWorkbook(contacts []models.Contact) ([]byte, error) {
+	contactIDs := make([]uint, len(contacts))
+	for i := range contacts {
+		contactIDs[i] = contacts[i].ID
+	}
+
+	activities, err := s.contactRepo.ListActivitiesForContacts(contactIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve contact activities: %w", err)
+	}
+	appointments, err := s.contactRepo.ListAppointmentsForContacts(contactIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve contact appointments: %w", err)
+	}
+
+	wb := document.NewWorkbook()
+
+	contactSheet := wb.AddSheet("Contacts",
+		"ID", "First Name", "Last Name", "Email", "Phone", "Company", "Job Title",
+		"City", "State", "Country", "Status", "Priority", "Lead Score", "Estimated Value", "Probability",
+		"Assigned To", "Last Contact", "Next Follow-up", "Created At", "Updated At")
+	for i := range contacts {
+		c := &contacts[i]
+		contactSheet.AddRow(
+			document.Integer(int64(c.ID)),
+			document.Text(c.FirstName),
+			document.OptionalText(c.LastName),
+			document.Text(c.Email),
+			document.OptionalText(c.Phone),
+			document.OptionalText(c.Company),
+			document.OptionalText(c.JobTitle),
+			document.OptionalText(c.City),
+			document.OptionalText(c.State),
+			document.Text(c.Country),
+			document.Text(string(c.Status)),
+			document.Text(string(c.Priority)),
+			document.Integer(int64(c.LeadScore)),
+			document.Decimal(c.EstimatedValue),
+			document.Integer(int64(c.Probability)),
+			document.OptionalInteger(c.AssignedTo),
+			document.OptionalDateTime(c.LastContactDate),
+			document.OptionalDateTime(c.NextFollowupDate),
+			document.DateTime(c.CreatedAt),
+			document.DateTime(c.UpdatedAt),
+		)
+	}
+
+	activitySheet := wb.AddSheet("Activities",
+		"ID", "Contact ID", "Type", "Title", "Status", "Channel", "Direction",
+		"Activity Date", "Duration (min)", "Performed By", "Billable", "Billable Amount", "Outcome")
+	for i := range activities {
+		a := &activities[i]
+		activitySheet.AddRow(
+			document.Integer(int64(a.ID)),
+			document.Integer(int64(a.ContactID)),
+			document.Text(string(a.ActivityType)),
+			document.Text(a.Title),
+			document.Text(string(a.Status)),
+			document.Text(string(a.Channel)),
+			document.Text(string(a.Direction)),
+			document.DateTime(a.ActivityDate),
+			document.Integer(int64(a.DurationMinutes)),
+			document.Integer(int64(a.PerformedBy)),
+			document.Bool(a.IsBillable),
+			document.Decimal(a.BillableAmount),
+			document.OptionalText(a.Outcome),
+		)
+	}
+
+	appointmentSheet := wb.AddSheet("Appointments",
+		"ID", "Contact ID", "Title", "Type", "Status", "Scheduled Date", "Scheduled Time", "Timezone",
+		"Duration (min)", "Meeting Type", "Location", "Assigned To", "Completed At")
+	for i := range appointments {
+		a := &appointments[i]
+		appointmentSheet.AddRow(
+			document.Integer(int64(a.ID)),
+			document.Integer(int64(a.ContactID)),
+			document.Text(a.Title),
+			document.Text(string(a.AppointmentType)),
+			document.Text(string(a.Status)),
+			document.Date(a.ScheduledDate),
+			document.Text(a.ScheduledTime),
+			document.Text(a.Timezone),
+			document.Integer(int64(a.DurationMinutes)),
+			document.Text(string(a.MeetingType)),
+			document.OptionalText(a.Location),
+			document.Integer(int64(a.AssignedTo)),
+			document.OptionalDateTime(a.CompletedAt),
+		)
+	}
+
+	return wb.Bytes()
+}
+
+// writeIntelligenceWorkbook renders the business intelligence report with one sheet per section
+func writeIntelligenceWorkbook(bi *models.BusinessIntelligence) ([]byte, error) {
+	wb := document.NewWorkbook()
+
+	summary := wb.AddSheet("KPI Summary", "Metric", "Value")
+	for _, kpi := range kpiRows(bi) {
+		summary.AddRow(document.Text(kpi.label), kpi.cell)
+	}
+
+	performers := wb.AddSheet("Top Performers", "User ID", "Username", "Full Name", "Score", "Conversion Rate (%)", "Revenue")
+	for _, user := range bi.TopPerformingUsers {
+		performers.AddRow(
+			document.Integer(int64(user.UserID)),
+			document.Text(user.Username),
+			document.Text(user.FullName),
+			document.Integer(int64(user.Score)),
+			document.Decimal(user.ConversionRate),
+			document.Decimal(user.Revenue),
+		)
+	}
+
+	sources := wb.AddSheet("Revenue by Source", "Source", "Won Deals", "Revenue", "Average Value", "Growth (%)")
+	for _, source := range bi.RevenueBySource {
+		sources.AddRow(
+			document.Text(source.Source),
+			document.Integer(int64(source.Count)),
+			document.Decimal(source.Revenue),
+			document.Decimal(source.AvgValue),
+			document.Decimal(source.Growth),
+		)
+	}
+
+	funnel := wb.AddSheet("Conversion Funnel", "Stage", "Count", "Conversion Rate (%)", "Drop-off Rate (%)", "Avg Days in Stage")
+	for _, stage := range bi.ConversionFunnel.Stages {
+		funnel.AddRow(
+			document.Text(stage.Name),
+			document.Integer(int64(stage.Count)),
+			document.Decimal(stage.ConversionRate),
+			document.Decimal(stage.DropOffRate),
+			document.Decimal(stage.AverageTimeInStage),
+		)
+	}
+
+	return wb.Bytes()
+}
+
+// writeMetricsWorkbook renders flattened metric/value rows as a single sheet, typing numeric values
+func writeMetricsWorkbook(name string, rows [][]string) ([]byte, error) {
+	wb := document.NewWorkbook()
+	sheet := wb.AddSheet(name, "Metric", "Value")
+	for _, row := range rows {
+		value := document.Text(row[1])
+		if number, err := strconv.ParseFloat(row[1], 64); err == nil {
+			value = document.Decimal(number)
+		}
+		sheet.AddRow(document.Text(row[0]), value)
+	}
+	return wb.Bytes()
+}
+
+// writeIntelligencePDF renders the business intelligence report for leadership decks
+func writeIntelligencePDF(bi *models.BusinessIntelligence) ([]byte, error) {
+	doc := document.NewPDF("Business Intelligence Report")
+	doc.Paragraph("Period: " + bi.Period)
+
+	doc.Heading("KPI Summary")
+	pairs := make([][2]string, 0)
+	for _, kpi := range kpiRows(bi) {
+		pairs = append(pairs, [2]string{kpi.label, kpi.text})
+	}
+	doc.KeyValues(pairs)
+
+	doc.Heading("Top Performers")
+	rows := make([][]string, 0, len(bi.TopPerformingUsers))
+	for _, user := range bi.TopPerformingUsers {
+		rows = append(rows, []string{user.FullName, strconv.Itoa(user.Score), formatPercent(user.ConversionRate), formatAmount(user.Revenue)})
+	}
+	doc.Table([]string{"User", "Score", "Conversion", "Revenue"}, rows)
+
+	doc.Heading("Revenue by Source")
+	rows = make([][]string, 0, len(bi.RevenueBySource))
+	for _, source := range bi.RevenueBySource {
+		rows = append(rows, []string{source.Source, strconv.Itoa(source.Count), formatAmount(source.Revenue), formatAmount(source.AvgValue), formatPercent(source.Growth)})
+	}
+	doc.Table([]string{"Source", "Won Deals", "Revenue", "Avg Value", "Growth"}, rows)
+
+	doc.Heading("Conversion Funnel")
+	rows = make([][]string, 0, len(bi.ConversionFunnel.Stages))
+	for _, stage := range bi.ConversionFunnel.Stages {
+		rows = append(rows, []string{stage.Name, strconv.Itoa(stage.Count), formatPercent(stage.ConversionRate), formatPercent(stage.DropOffRate)})
+	}
+	doc.Table([]string{"Stage", "Count", "Conversion", "Drop-off"}, rows)
+
+	return doc.Bytes()
+}
+
+// kpiRow is one KPI with its spreadsheet cell and display text
+type kpiRow struct {
+	label string
+	cell  document.Cell
+	text  string
+}
+
+func kpiRows(bi *models.BusinessIntelligence) []kpiRow {
+	kpi := bi.KPISummary
+	count := func(label string, value int) kpiRow {
+		return kpiRow{label, document.Integer(int64(value)), strconv.Itoa(value)}
+	}
+	amount := func(label string, value float64) kpiRow {
+		return kpiRow{label, document.Decimal(value), formatAmount(value)}
+	}
+	percent := func(label string, value float64) kpiRow {
+		return kpiRow{label, document.Decimal(value), formatPercent(value)}
+	}
+
+	return []kpiRow{
+		count("Total Leads", kpi.TotalLeads),
+		count("Qualified Leads", kpi.QualifiedLeads),
+		count("Converted Leads", kpi.ConvertedLeads),
+		percent("Lead Conversion Rate", kpi.LeadConversionRate),
+		amount("Average Won Deal Value", kpi.AverageLeadValue),
+		amount("Total Revenue", kpi.TotalRevenue),
+		amount("Potential Revenue", bi.PotentialRevenue),
+		percent("Revenue Growth", kpi.RevenueGrowth),
+		amount("Average Response Time (hours)", kpi.AverageResponseTime),
+		percent("Team Productivity", kpi.TeamProductivity),
+		percent("Customer Satisfaction", kpi.CustomerSatisfaction),
+	}
+}
+
+func formatAmount(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+func formatPercent(value float64) string {
+	return strconv.FormatFloat(value, 'f', 1, 64) + "%"
+}
@@ -0,0 +1,687 @@
+package services
+
+import (
+	"bytes"
+	"contact-service/internal/models"
+	"contact-service/internal/repository"
+	"contact-service/pkg/logger"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Maximum number of expired exports removed per cleanup pass
+const exportCleanupBatchSize = 100
+
+// Default number of retries for a failed export
+const defaultExportMaxRetries = 3
+
+// ExportJobConfig configures the background export runner
+type ExportJobConfig struct {
+	Directory       string        // Local directory export files are written to
+	Workers         int           // Number of jobs processed concurrently
+	PollInterval    time.Duration // How often idle workers look for pending jobs
+	RetryDelay      time.Duration // Minimum wait before a failed job is retried
+	Retention       time.Duration // How long a finished export stays downloadable
+	CleanupInterval time.Duration // How often expired files are removed
+	ClaimTimeout    time.Duration // Jobs processing for longer than this are assumed abandoned by a stopped worker
+}
+
+// LoadExportJobConfig reads the export runner configuration from the environment
+func LoadExportJobConfig() ExportJobConfig {
+	config := ExportJobConfig{
+		Directory:       "./exports",
+		Workers:         2,
+		PollInterval:    5 * time.Second,
+		RetryDelay:      time.Minute,
+		Retention:       24 * time.Hour,
+		CleanupInterval: time.Hour,
+		ClaimTimeout:    time.Hour,
+	}
+
+	if dir := os.Getenv("EXPORT_DIR"); dir != "" {
+		config.Directory = dir
+	}
+	if workers, err := strconv.Atoi(os.Getenv("EXPORT_WORKERS")); err == nil && workers > 0 {
+		config.Workers = workers
+	}
+	if hours, err := strconv.Atoi(os.Getenv("EXPORT_RETENTION_HOURS")); err == nil && hours > 0 {
+		config.Retention = time.Duration(hours) * time.Hour
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("EXPORT_CLAIM_TIMEOUT_MINUTES")); err == nil && minutes > 0 {
+		config.ClaimTimeout = time.Duration(minutes) * time.Minute
+	}
+
+	return config
+}
+
+// ExportJobService queues exports in export_jobs and runs them on a pool of background workers
+type ExportJobService struct {
+	jobRepo          repository.ExportJobRepository
+	bulkService      *BulkService
+	analyticsService *AnalyticsService
+	config           ExportJobConfig
+	wake             chan struct{}
+	startOnce        sync.Once
+}
+
+// NewExportJobService creates a new export job service
+func NewExportJobService(jobRepo repository.ExportJobRepository, bulkService *BulkService, analyticsService *AnalyticsService, config ExportJobConfig) *ExportJobService {
+	return &ExportJobService{
+		jobRepo:          jobRepo,
+		bulkService:      bulkService,
+		analyticsService: analyticsService,
+		config:           config,
+		wake:             make(chan struct{}, 1),
+	}
+}
+
+// Start requeues abandoned jobs and launches the workers and the cleanup loop. Only jobs started
+// longer ago than the claim timeout are requeued, so jobs other instances are running are left
+// alone. Calling it again has no effect.
+func (s *ExportJobService) Start() error {
+	var startErr error
+	s.startOnce.Do(func() {
+		if err := os.MkdirAll(s.config.Directory, 0o750); err != nil {
+			startErr = fmt.Errorf("failed to create export directory: %v", err)
+			return
+		}
+
+		if err := s.recoverStaleClaims(); err != nil {
+			startErr = fmt.Errorf("failed to requeue interrupted exports: %v", err)
+			return
+		}
+
+		for i := 0; i < s.config.Workers; i++ {
+			go s.worker()
+		}
+		go s.cleanupLoop()
+		go s.recoverer()
+
+		logger.Info("Export job runner started", map[string]interface{}{
+			"workers":   s.config.Workers,
+			"directory": s.config.Directory,
+		})
+	})
+	return startErr
+}
+
+// CreateJob validates and queues an export
+func (s *ExportJobService) CreateJob(request models.ExportJobRequest, createdBy uint) (*models.ExportJob, error) {
+	if err := s.validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	exportID, err := newExportID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate export ID: %v", err)
+	}
+
+	job := &models.ExportJob{
+		ExportID:     exportID,
+		JobType:      request.JobType,
+		ExportFormat: string(NormalizeExportFormat(strings.ToLower(request.ExportFormat))),
+		Filters:      models.JSONMap(request.Filters),
+		Status:       models.ExportStatusPending,
+		MaxRetries:   defaultExportMaxRetries,
+		CreatedAt:    time.Now(),
+		CreatedBy:    createdBy,
+	}
+	if len(request.UserIDs) > 0 {
+		job.UserIDs = make(models.JSONArray, len(request.UserIDs))
+		for i, id := range request.UserIDs {
+			job.UserIDs[i] = id
+		}
+	}
+	if job.StartDate, err = parseExportDate(request.StartDate); err != nil {
+		return nil, fmt.Errorf("invalid start_date: %v", err)
+	}
+	if job.EndDate, err = parseExportDate(request.EndDate); err != nil {
+		return nil, fmt.Errorf("invalid end_date: %v", err)
+	}
+	if job.StartDate != nil && job.EndDate != nil && job.EndDate.Before(*job.StartDate) {
+		return nil, fmt.Errorf("invalid end_date: must be after start_date")
+	}
+
+	if err := s.jobRepo.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to create export job: %v", err)
+	}
+
+	logger.Info("Export job queued", map[string]interface{}{
+		"export_id":  job.ExportID,
+		"job_type":   job.JobType,
+		"format":     job.ExportFormat,
+		"created_by": createdBy,
+	})
+
+	s.notify()
+	return job, nil
+}
+
+// GetJob retrieves an export job by its export ID
+func (s *ExportJobService) GetJob(exportID string) (*models.ExportJob, error) {
+	job, err := s.jobRepo.GetByExportID(exportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("export job not found")
+		}
+		return nil, fmt.Errorf("failed to get export job: %v", err)
+	}
+	return job, nil
+}
+
+// ListJobs retrieves export jobs newest first, optionally only those created by one user
+func (s *ExportJobService) ListJobs(createdBy *uint, page, limit int) ([]models.ExportJob, int64, error) {
+	jobs, total, err := s.jobRepo.List(createdBy, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list export jobs: %v", err)
+	}
+	return jobs, total, nil
+}
+
+// PrepareDownload checks that an export file can be served and counts the download
+func (s *ExportJobService) PrepareDownload(job *models.ExportJob) (string, error) {
+	now := time.Now()
+	if job.Status == models.ExportStatusExpired || (job.Status == models.ExportStatusCompleted && !job.IsDownloadable(now)) {
+		return "", fmt.Errorf("export has expired")
+	}
+	if !job.IsDownloadable(now) {
+		return "", fmt.Errorf("export is not ready (status: %s)", job.Status)
+	}
+	if _, err := os.Stat(*job.FilePath); err != nil {
+		return "", fmt.Errorf("export file is missing: %v", err)
+	}
+
+	if err := s.jobRepo.RecordDownload(job.ID); err != nil {
+		logger.Error("Failed to record export download", err, map[string]interface{}{
+			"export_id": job.ExportID,
+		})
+	}
+	return *job.FilePath, nil
+}
+
+// DownloadFileName returns the file name offered to the client for an export
+func (s *ExportJobService) DownloadFileName(job *models.ExportJob) string {
+	return fmt.Sprintf("%s_%s%s", job.JobType, job.ExportID, filepath.Ext(*job.FilePath))
+}
+
+// DownloadContentType returns the MIME type of an export's file
+func (s *ExportJobService) DownloadContentType(job *models.ExportJob) string {
+	return ExportContentType(NormalizeExportFormat(job.ExportFormat))
+}
+
+func (s *ExportJobService) validateRequest(request models.ExportJobRequest) error {
+	format := NormalizeExportFormat(strings.ToLower(request.ExportFormat))
+	switch format {
+	case ExportFormatCSV, ExportFormatJSON, ExportFormatXLSX, ExportFormatPDF:
+	default:
+		return fmt.Errorf("unsupported export format: %s (supported: csv, json, xlsx, pdf)", request.ExportFormat)
+	}
+
+	switch request.JobType {
+	case models.ExportJobTypeContacts:
+		if format == ExportFormatPDF {
+			return fmt.Errorf("unsupported export format for contacts: pdf")
+		}
+		return nil
+	case models.ExportJobTypeAnalytics:
+		analyticsType, _ := request.Filters["type"].(string)
+		if _, ok := analyticsExportTypes[analyticsType]; !ok {
+			return fmt.Errorf("unsupported analytics type: %q", analyticsType)
+		}
+		if format == ExportFormatPDF && analyticsType != analyticsTypeBusinessIntelligence {
+			return fmt.Errorf("unsupported export format for %s analytics: pdf is only available for %s", analyticsType, analyticsTypeBusinessIntelligence)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported export job type: %s", request.JobType)
+}
+
+// recoverStaleClaims returns jobs abandoned by a stopped worker to the queue, or fails them once
+// they have used up their retries
+func (s *ExportJobService) recoverStaleClaims() error {
+	requeued, failed, err := s.jobRepo.RequeueProcessing(time.Now().Add(-s.config.ClaimTimeout))
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		logger.Warn("Failed export jobs interrupted too many times", map[string]interface{}{
+			"count": failed,
+		})
+	}
+	if requeued > 0 {
+		logger.Warn("Requeued interrupted export jobs", map[string]interface{}{
+			"count": requeued,
+		})
+		s.notify()
+	}
+	return nil
+}
+
+// recoverer periodically requeues jobs abandoned by instances that stopped while running
+func (s *ExportJobService) recoverer() {
+	ticker := time.NewTicker(s.config.ClaimTimeout)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.recoverStaleClaims(); err != nil {
+			logger.Error("Failed to requeue interrupted exports", err, nil)
+		}
+	}
+}
+
+// notify wakes an idle worker without blocking
+func (s *ExportJobService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *ExportJobService) worker() {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for s.processNext() {
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// processNext claims and runs one pending job, reporting whether there was one
+func (s *ExportJobService) processNext() bool {
+	job, err := s.jobRepo.ClaimNext(time.Now().Add(-s.config.RetryDelay))
+	if err != nil {
+		logger.Error("Failed to claim export job", err, nil)
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	s.runJob(job)
+	return true
+}
+
+func (s *ExportJobService) runJob(job *models.ExportJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.failJob(job, fmt.Errorf("export crashed: %v", r))
+		}
+	}()
+
+	started := time.Now()
+	data, recordCount, err := s.render(job)
+	if err != nil {
+		s.failJob(job, err)
+		return
+	}
+	s.setProgress(job, 80)
+
+	path, err := s.writeFile(job, data)
+	if err != nil {
+		s.failJob(job, err)
+		return
+	}
+
+	now := time.Now()
+	fileSize := len(data)
+	downloadURL := fmt.Sprintf("/api/v1/exports/%s/download", job.ExportID)
+	if err := s.jobRepo.Update(job.ID, map[string]interface{}{
+		"status":        models.ExportStatusCompleted,
+		"progress":      100,
+		"file_path":     path,
+		"file_size":     fileSize,
+		"record_count":  recordCount,
+		"download_url":  downloadURL,
+		"error_message": nil,
+		"completed_at":  now,
+		"expires_at":    now.Add(s.config.Retention),
+	}); err != nil {
+		logger.Error("Failed to mark export job completed", err, map[string]interface{}{
+			"export_id": job.ExportID,
+		})
+		return
+	}
+
+	logger.Info("Export job completed", map[string]interface{}{
+		"export_id":    job.ExportID,
+		"record_count": recordCount,
+		"file_size":    fileSize,
+		"duration_ms":  time.Since(started).Milliseconds(),
+	})
+}
+
+// failJob requeues a failed job until it runs out of retries, then marks it failed
+func (s *ExportJobService) failJob(job *models.ExportJob, jobErr error) {
+	message := jobErr.Error()
+	updates := map[string]interface{}{
+		"error_message": message,
+		"progress":      0,
+	}
+
+	if job.RetryCount < job.MaxRetries {
+		updates["status"] = models.ExportStatusPending
+		updates["retry_count"] = job.RetryCount + 1
+	} else {
+		updates["status"] = models.ExportStatusFailed
+		updates["completed_at"] = time.Now()
+	}
+
+	if err := s.jobRepo.Update(job.ID, updates); err != nil {
+		logger.Error("Failed to record export job failure", err, map[string]interface{}{
+			"export_id": job.ExportID,
+		})
+	}
+
+	logger.Error("Export job failed", jobErr, map[string]interface{}{
+		"export_id":   job.ExportID,
+		"retry_count": job.RetryCount,
+		"max_retries": job.MaxRetries,
+		"status":      updates["status"],
+	})
+}
+
+func (s *ExportJobService) setProgress(job *models.ExportJob, progress int) {
+	if err := s.jobRepo.Update(job.ID, map[string]interface{}{"progress": progress}); err != nil {
+		logger.Warn("Failed to update export progress", map[string]interface{}{
+			"export_id": job.ExportID,
+			"error":     err.Error(),
+		})
+	}
+}
+
+// render produces the file contents of a job and the number of records in it
+func (s *ExportJobService) render(job *models.ExportJob) ([]byte, int, error) {
+	format := NormalizeExportFormat(job.ExportFormat)
+
+	switch job.JobType {
+	case models.ExportJobTypeContacts:
+		return s.bulkService.ExportContacts(contactExportRequest(job, format))
+	case models.ExportJobTypeAnalytics:
+		return s.renderAnalytics(job, format)
+	}
+	return nil, 0, fmt.Errorf("unsupported export job type: %s", job.JobType)
+}
+
+// contactExportRequest converts the stored job parameters into a bulk export request
+func contactExportRequest(job *models.ExportJob, format ExportFormat) ExportRequest {
+	request := ExportRequest{
+		Format:      format,
+		Filters:     map[string]interface{}(job.Filters),
+		IncludeMeta: true,
+	}
+	if job.Filters == nil {
+		return request
+	}
+
+	if fields, ok := job.Filters["fields"].([]interface{}); ok {
+		for _, field := range fields {
+			if name, ok := field.(string); ok {
+				request.Fields = append(request.Fields, name)
+			}
+		}
+	}
+	if sortBy, ok := job.Filters["sort_by"].(string); ok {
+		request.SortBy = sortBy
+	}
+	if sortOrder, ok := job.Filters["sort_order"].(string); ok {
+		request.SortOrder = sortOrder
+	}
+	if limit, ok := job.Filters["limit"].(float64); ok {
+		request.Limit = int(limit)
+	}
+	return request
+}
+
+// Analytics type of the business intelligence report, the only one available as PDF
+const analyticsTypeBusinessIntelligence = "business_intelligence"
+
+// Analytics reports that can be exported, keyed by the filters "type" value
+var analyticsExportTypes = map[string]func(s *AnalyticsService, request *models.AnalyticsRequest) (interface{}, error){
+	"contacts": func(s *AnalyticsService, request *models.AnalyticsRequest) (interface{}, error) {
+		return s.GetContactAnalytics(request)
+	},
+	"appointments": func(s *AnalyticsService, request *models.AnalyticsRequest) (interface{}, error) {
+		return s.GetAppointmentAnalytics(request)
+	},
+	"performance": func(s *AnalyticsService, request *models.AnalyticsRequest) (interface{}, error) {
+		return s.GetUserPerformanceAnalytics(request)
+	},
+	"conversion": func(s *AnalyticsService, request *models.AnalyticsRequest) (interface{}, error) {
+		return s.GetConversionMetrics(request)
+	},
+	"response_time": func(s *AnalyticsService, request *models.AnalyticsRequest) (interface{}, error) {
+		return s.GetResponseTimeMetrics(request)
+	},
+	analyticsTypeBusinessIntelligence: func(s *AnalyticsService, request *models.AnalyticsRequest) (interface{}, error) {
+		return s.GetBusinessIntelligence(request), nil
+	},
+}
+
+func (s *ExportJobService) renderAnalytics(job *models.ExportJob, format ExportFormat) ([]byte, int, error) {
+	analyticsType, _ := job.Filters["type"].(string)
+	report, ok := analyticsExportTypes[analyticsType]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported analytics type: %q", analyticsType)
+	}
+
+	request := &models.AnalyticsRequest{
+		StartDate:   time.Now().AddDate(0, 0, -30),
+		EndDate:     time.Now(),
+		Granularity: "day",
+	}
+	if job.StartDate != nil {
+		request.StartDate = *job.StartDate
+	}
+	if job.EndDate != nil {
+		request.EndDate = *job.EndDate
+	}
+	for _, id := range job.UserIDs {
+		if value, ok := id.(float64); ok {
+			request.UserIDs = append(request.UserIDs, uint(value))
+		}
+	}
+	if granularity, ok := job.Filters["granularity"].(string); ok && granularity != "" {
+		request.Granularity = granularity
+	}
+
+	result, err := report(s.analyticsService, request)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build %s analytics: %v", analyticsType, err)
+	}
+
+	switch format {
+	case ExportFormatJSON:
+		data, err := json.MarshalIndent(result, "", "  ")
+		return data, 1, err
+	case ExportFormatCSV:
+		rows, err := flattenForCSV(result)
+		if err != nil {
+			return nil, 0, err
+		}
+		data, err := writeCSVRows([]string{"metric", "value"}, rows)
+		return data, len(rows), err
+	case ExportFormatXLSX:
+		if bi, ok := result.(*models.BusinessIntelligence); ok {
+			data, err := writeIntelligenceWorkbook(bi)
+			return data, 1, err
+		}
+		rows, err := flattenForCSV(result)
+		if err != nil {
+			return nil, 0, err
+		}
+		data, err := writeMetricsWorkbook(analyticsType, rows)
+		return data, len(rows), err
+	case ExportFormatPDF:
+		if bi, ok := result.(*models.BusinessIntelligence); ok {
+			data, err := writeIntelligencePDF(bi)
+			return data, 1, err
+		}
+	}
+	return nil, 0, fmt.Errorf("unsupported export format: %s", format)
+}
+
+// flattenForCSV turns a report into metric/value rows with dotted paths as metric names
+func flattenForCSV(report interface{}) ([][]string, error) {
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %v", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %v", err)
+	}
+
+	var rows [][]string
+	var walk func(path string, value interface{})
+	walk = func(path string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(joinMetricPath(path, key), v[key])
+			}
+		case []interface{}:
+			for i, item := range v {
+				walk(joinMetricPath(path, strconv.Itoa(i)), item)
+			}
+		case nil:
+			rows = append(rows, []string{path, ""})
+		default:
+			rows = append(rows, []string{path, fmt.Sprint(v)})
+		}
+	}
+	walk("", decoded)
+	return rows, nil
+}
+
+func joinMetricPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func writeCSVRows(header []string, rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("failed to write CSV rows: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeFile stores the export under the configured directory, replacing any earlier attempt
+func (s *ExportJobService) writeFile(job *models.ExportJob, data []byte) (string, error) {
+	path := filepath.Join(s.config.Directory, job.ExportID+"."+ExportFileExtension(NormalizeExportFormat(job.ExportFormat)))
+
+	tmp, err := os.CreateTemp(s.config.Directory, job.ExportID+"-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %v", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write export file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write export file: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to store export file: %v", err)
+	}
+	return path, nil
+}
+
+func (s *ExportJobService) cleanupLoop() {
+	ticker := time.NewTicker(s.config.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		s.CleanupExpired()
+		<-ticker.C
+	}
+}
+
+// CleanupExpired deletes the files of exports past their expiry time and marks them expired
+func (s *ExportJobService) CleanupExpired() {
+	jobs, err := s.jobRepo.ListExpired(time.Now(), exportCleanupBatchSize)
+	if err != nil {
+		logger.Error("Failed to list expired exports", err, nil)
+		return
+	}
+
+	for _, job := range jobs {
+		if job.FilePath != nil {
+			if err := os.Remove(*job.FilePath); err != nil && !os.IsNotExist(err) {
+				logger.Error("Failed to delete expired export file", err, map[string]interface{}{
+					"export_id": job.ExportID,
+					"file_path": *job.FilePath,
+				})
+				continue
+			}
+		}
+
+		if err := s.jobRepo.Update(job.ID, map[string]interface{}{
+			"status":       models.ExportStatusExpired,
+			"file_path":    nil,
+			"download_url": nil,
+		}); err != nil {
+			logger.Error("Failed to mark export expired", err, map[string]interface{}{
+				"export_id": job.ExportID,
+			})
+		}
+	}
+
+	if len(jobs) > 0 {
+		logger.Info("Expired export files removed", map[string]interface{}{
+			"count": len(jobs),
+		})
+	}
+}
+
+func parseExportDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+func newExportID() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "exp_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"contact-service/internal/models"
+	"contact-service/internal/repository"
+)
+
+func TestRecoverStaleClaimsCountsRetries(t *testing.T) {
+	db := newTestDB(t, &models.AdminUser{}, &models.ExportJob{})
+	service := NewExportJobService(repository.NewExportJobRepository(db), nil, nil, ExportJobConfig{ClaimTimeout: time.Minute})
+
+	abandoned := time.Now().Add(-time.Hour)
+	running := time.Now()
+	jobs := map[string]*models.ExportJob{
+		"retry":     {RetryCount: 1, StartedAt: &abandoned},
+		"exhausted": {RetryCount: 3, StartedAt: &abandoned},
+		"running":   {RetryCount: 3, StartedAt: &running},
+	}
+	for id, job := range jobs {
+		job.ExportID = id
+		job.JobType = "contacts"
+		job.ExportFormat = "csv"
+		job.Status = models.ExportStatusProcessing
+		job.MaxRetries = 3
+		job.CreatedBy = 1
+		require.NoError(t, db.Create(job).Error)
+	}
+
+	require.NoError(t, service.recoverStaleClaims())
+
+	reload := func(id string) models.ExportJob {
+		var job models.ExportJob
+		require.NoError(t, db.Where("export_id = ?", id).First(&job).Error)
+		return job
+	}
+
+	retried := reload("retry")
+	assert.Equal(t, models.ExportStatusPending, retried.Status)
+	assert.Equal(t, 2, retried.RetryCount)
+
+	exhausted := reload("exhausted")
+	assert.Equal(t, models.ExportStatusFailed, exhausted.Status)
+	assert.Equal(t, 3, exhausted.RetryCount)
+	assert.NotNil(t, exhausted.CompletedAt)
+	require.NotNil(t, exhausted.ErrorMessage)
+
+	assert.Equal(t, models.ExportStatusProcessing, reload("running").Status)
+}
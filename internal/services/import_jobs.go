@@ -0,0 +1,649 @@
+package services
+
+import (
+	"bytes"
+	"contact-service/internal/models"
+	"contact-service/internal/repository"
+	"contact-service/pkg/logger"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Number of rows processed between progress updates and cancellation checks
+const importProgressInterval = 100
+
+// Maximum size of the warning log stored on an import batch
+const maxImportErrorLogBytes = 64 * 1024
+
+// Headers assumed when the CSV file has no header row
+var defaultImportHeaders = []string{"Name", "Email", "Phone", "Company", "Position", "Status", "Type", "Source", "Notes"}
+
+// Target accepted in a field mapping that splits a full name into first_name and last_name
+const importFullNameField = "name"
+
+// Contact fields that an import may never write
+var protectedImportFields = map[string]bool{
+	"deleted_at":          true,
+	"is_duplicate":        true,
+	"original_contact_id": true,
+}
+
+// Common CSV header spellings and the contact field they map to
+var importHeaderAliases = map[string]string{
+	"name":          importFullNameField,
+	"full_name":     importFullNameField,
+	"first":         "first_name",
+	"last":          "last_name",
+	"surname":       "last_name",
+	"e_mail":        "email",
+	"email_address": "email",
+	"phone_number":  "phone",
+	"mobile":        "phone",
+	"organization":  "company",
+	"organisation":  "company",
+	"position":      "job_title",
+	"title":         "job_title",
+	"address":       "address_line1",
+	"zip":           "postal_code",
+	"zip_code":      "postal_code",
+}
+
+// importColumn maps a CSV column to the contact field it populates
+type importColumn struct {
+	index  int
+	header string
+	field  string
+}
+
+// importRowOutcome is the result of importing a single row
+type importRowOutcome int
+
+const (
+	importRowImported importRowOutcome = iota
+	importRowUpdated
+	importRowSkipped
+	importRowFailed
+)
+
+// importProgress holds the running counters of an import job
+type importProgress struct {
+	processed  int
+	imported   int
+	updated    int
+	skipped    int
+	failed     int
+	duplicates int
+	failures   models.ImportFailedRecords
+	warnings   strings.Builder
+}
+
+// ImportJobConfig configures the background import runner
+type ImportJobConfig struct {
+	// Imports that have not reported progress for this long are assumed orphaned by an instance
+	// that stopped, and are marked failed
+	HeartbeatTimeout time.Duration
+}
+
+// LoadImportJobConfig reads the import runner configuration from the environment
+func LoadImportJobConfig() ImportJobConfig {
+	config := ImportJobConfig{
+		HeartbeatTimeout: 10 * time.Minute,
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("IMPORT_HEARTBEAT_TIMEOUT_MINUTES")); err == nil && minutes > 0 {
+		config.HeartbeatTimeout = time.Duration(minutes) * time.Minute
+	}
+	return config
+}
+
+// ImportJobService runs CSV imports as background jobs tracked in contact_import_batches.
+// The parsed rows of a running import live only in memory; a running import reports a heartbeat
+// with its progress, and imports whose instance stopped are marked failed once it goes stale.
+type ImportJobService struct {
+	importRepo  repository.ImportBatchRepository
+	contactRepo repository.ContactRepository
+	historyRepo repository.ContactHistoryRepository
+	validator   *ValidationService
+	config      ImportJobConfig
+	startOnce   sync.Once
+}
+
+// NewImportJobService creates a new import job service
+func NewImportJobService(importRepo repository.ImportBatchRepository, contactRepo repository.ContactRepository, historyRepo repository.ContactHistoryRepository, ruleRepo repository.ValidationRuleRepository, config ImportJobConfig) *ImportJobService {
+	return &ImportJobService{
+		importRepo:  importRepo,
+		contactRepo: contactRepo,
+		historyRepo: historyRepo,
+		validator:   NewValidationService(ruleRepo),
+		config:      config,
+	}
+}
+
+// Start marks imports orphaned by stopped instances as failed and keeps doing so periodically.
+// Imports other instances are running keep reporting a heartbeat and are left alone. Calling it
+// again has no effect.
+func (s *ImportJobService) Start() error {
+	var startErr error
+	s.startOnce.Do(func() {
+		if err := s.failAbandoned(); err != nil {
+			startErr = fmt.Errorf("failed to recover interrupted imports: %v", err)
+			return
+		}
+		go s.recoverer()
+	})
+	return startErr
+}
+
+// failAbandoned marks imports whose heartbeat has gone stale as failed
+func (s *ImportJobService) failAbandoned() error {
+	failed, err := s.importRepo.FailAbandoned(time.Now().Add(-s.config.HeartbeatTimeout),
+		"import interrupted: the service stopped while it was running. Rows up to records_processed were handled; upload the file again to import the rest.")
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		logger.Warn("Marked interrupted contact imports as failed", map[string]interface{}{
+			"count": failed,
+		})
+	}
+	return nil
+}
+
+// recoverer periodically fails imports orphaned by instances that stopped while running
+func (s *ImportJobService) recoverer() {
+	ticker := time.NewTicker(s.config.HeartbeatTimeout)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.failAbandoned(); err != nil {
+			logger.Error("Failed to recover interrupted imports", err, nil)
+		}
+	}
+}
+
+// StartImport parses the CSV, records an import batch and processes the rows in the background
+func (s *ImportJobService) StartImport(data io.Reader, fileName string, fileSize int64, opts models.ImportJobOptions, importedBy uint) (*models.ContactImportBatch, error) {
+	reader := csv.NewReader(data)
+	reader.FieldsPerRecord = -1 // Allow variable number of fields
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV data: %w", err)
+	}
+
+	headers := defaultImportHeaders
+	if opts.HasHeader {
+		if len(records) == 0 {
+			return nil, fmt.Errorf("CSV file has no header row")
+		}
+		headers = records[0]
+		records = records[1:]
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV file contains no records")
+	}
+
+	columns, err := resolveImportMapping(headers, opts.FieldMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.validator.LoadRules(models.ValidationChannelImport)
+	if err != nil {
+		return nil, err
+	}
+	appliedRules := make(models.JSONArray, 0, len(rules))
+	for _, rule := range rules {
+		appliedRules = append(appliedRules, map[string]interface{}{
+			"id":        rule.ID,
+			"name":      rule.Name,
+			"field":     rule.FieldName,
+			"rule_type": rule.RuleType,
+			"severity":  rule.Severity,
+		})
+	}
+
+	fieldMapping := make(models.JSONMap, len(columns))
+	for _, column := range columns {
+		fieldMapping[column.header] = column.field
+	}
+
+	batchName := strings.TrimSpace(opts.BatchName)
+	if batchName == "" {
+		batchName = fileName
+	}
+	if batchName == "" {
+		batchName = fmt.Sprintf("Import %s", time.Now().Format("2006-01-02 15:04"))
+	}
+
+	batch := &models.ContactImportBatch{
+		BatchName:     batchName,
+		FileName:      fileName,
+		FileSizeBytes: fileSize,
+		TotalRecords:  len(records),
+		Status:        models.ImportStatusPending,
+		ImportOptions: models.JSONMap{
+			"has_header":      opts.HasHeader,
+			"update_existing": opts.UpdateExisting,
+			"headers":         headers,
+		},
+		FieldMapping:    fieldMapping,
+		ValidationRules: appliedRules,
+		StartedAt:       time.Now(),
+		ImportedBy:      importedBy,
+	}
+	if err := s.importRepo.Create(batch); err != nil {
+		return nil, fmt.Errorf("failed to create import batch: %v", err)
+	}
+
+	firstRow := 1
+	if opts.HasHeader {
+		firstRow = 2
+	}
+	go s.runImport(batch.ID, headers, records, firstRow, columns, rules, opts.UpdateExisting, importedBy)
+
+	logger.LogBusinessEvent("contact_import_started", "import_batch", batch.ID, map[string]interface{}{
+		"file_name":     fileName,
+		"total_records": batch.TotalRecords,
+		"imported_by":   importedBy,
+	})
+
+	return batch, nil
+}
+
+// GetImport retrieves an import batch
+func (s *ImportJobService) GetImport(id uint) (*models.ContactImportBatch, error) {
+	batch, err := s.importRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("import batch not found")
+		}
+		return nil, fmt.Errorf("failed to get import batch: %v", err)
+	}
+	return batch, nil
+}
+
+// ListImports retrieves import batches, optionally limited to one user
+func (s *ImportJobService) ListImports(importedBy *uint, page, limit int) ([]models.ContactImportBatch, int64, error) {
+	batches, total, err := s.importRepo.List(importedBy, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list import batches: %v", err)
+	}
+	return batches, total, nil
+}
+
+// GetSummary aggregates import activity for the bulk operation status endpoint
+func (s *ImportJobService) GetSummary() (*models.ImportBatchSummary, error) {
+	summary, err := s.importRepo.Summary(5)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize import batches: %v", err)
+	}
+	return summary, nil
+}
+
+// CancelImport stops a pending or running import. Rows already imported are kept.
+func (s *ImportJobService) CancelImport(id uint) (*models.ContactImportBatch, error) {
+	batch, err := s.GetImport(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled, err := s.importRepo.Cancel(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel import: %v", err)
+	}
+	if !cancelled {
+		return nil, fmt.Errorf("import has already %s", batch.Status)
+	}
+
+	return s.GetImport(id)
+}
+
+// FailedRecordsCSV renders the failed rows of an import as CSV with their original columns and the errors
+func (s *ImportJobService) FailedRecordsCSV(batch *models.ContactImportBatch) ([]byte, error) {
+	var headers []string
+	if raw, ok := batch.ImportOptions["headers"].([]interface{}); ok {
+		for _, header := range raw {
+			headers = append(headers, fmt.Sprint(header))
+		}
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(append(append([]string{"row"}, headers...), "errors")); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, record := range batch.FailedRecords {
+		line := make([]string, 0, len(headers)+2)
+		line = append(line, fmt.Sprint(record.Row))
+		for _, header := range headers {
+			line = append(line, record.Data[header])
+		}
+		line = append(line, strings.Join(record.Errors, "; "))
+		if err := writer.Write(line); err != nil {
+			return nil, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("CSV writer error: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// runImport processes the rows of a batch, persisting progress until it finishes or is cancelled
+func (s *ImportJobService) runImport(batchID uint, headers []string, records [][]string, firstRow int, columns []importColumn, rules []models.ContactValidationRule, updateExisting bool, importedBy uint) {
+	progress := &importProgress{}
+	usage := make(map[uint]int, len(rules))
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Contact import crashed", fmt.Errorf("%v", r), map[string]interface{}{
+				"batch_id": batchID,
+			})
+			updates := progress.updates(len(records))
+			updates["status"] = models.ImportStatusFailed
+			updates["completed_at"] = time.Now()
+			updates["error_log"] = fmt.Sprintf("import aborted: %v", r)
+			s.importRepo.UpdateIfStatus(batchID, models.ImportStatusProcessing, updates)
+		}
+	}()
+
+	started, err := s.importRepo.UpdateIfStatus(batchID, models.ImportStatusPending, map[string]interface{}{
+		"status":       models.ImportStatusProcessing,
+		"heartbeat_at": time.Now(),
+	})
+	if err != nil || !started {
+		return
+	}
+
+	cancelled := false
+	for i, row := range records {
+		rowNum := firstRow + i
+		outcome, rowErrors, warnings := s.importRow(row, columns, rules, usage, updateExisting, importedBy)
+
+		progress.processed++
+		switch outcome {
+		case importRowImported:
+			progress.imported++
+		case importRowUpdated:
+			progress.updated++
+		case importRowSkipped:
+			progress.skipped++
+			progress.duplicates++
+		case importRowFailed:
+			progress.failed++
+			progress.failures = append(progress.failures, failedImportRecord(rowNum, headers, row, rowErrors))
+		}
+		for _, warning := range warnings {
+			progress.warn(fmt.Sprintf("row %d: %s", rowNum, warning))
+		}
+
+		if progress.processed%importProgressInterval == 0 {
+			running, err := s.importRepo.UpdateIfStatus(batchID, models.ImportStatusProcessing, progress.updates(len(records)))
+			if err != nil {
+				logger.Error("Failed to update import progress", err, map[string]interface{}{
+					"batch_id": batchID,
+				})
+			} else if !running {
+				cancelled = true
+				break
+			}
+		}
+	}
+
+	s.validator.RecordUsage(usage)
+
+	updates := progress.updates(len(records))
+	updates["failed_records"] = progress.failures
+	if progress.warnings.Len() > 0 {
+		updates["error_log"] = progress.warnings.String()
+	}
+
+	if !cancelled {
+		final := make(map[string]interface{}, len(updates)+2)
+		for key, value := range updates {
+			final[key] = value
+		}
+		final["status"] = models.ImportStatusCompleted
+		final["completed_at"] = time.Now()
+		completed, err := s.importRepo.UpdateIfStatus(batchID, models.ImportStatusProcessing, final)
+		if err != nil {
+			logger.Error("Failed to complete import batch", err, map[string]interface{}{
+				"batch_id": batchID,
+			})
+			return
+		}
+		cancelled = !completed
+	}
+	if cancelled {
+		// Keep the cancelled status but record how far the job got
+		if err := s.importRepo.Update(batchID, updates); err != nil {
+			logger.Error("Failed to record cancelled import progress", err, map[string]interface{}{
+				"batch_id": batchID,
+			})
+		}
+	}
+
+	logger.LogBusinessEvent("contact_import_finished", "import_batch", batchID, map[string]interface{}{
+		"cancelled": cancelled,
+		"processed": progress.processed,
+		"imported":  progress.imported,
+		"updated":   progress.updated,
+		"skipped":   progress.skipped,
+		"failed":    progress.failed,
+	})
+}
+
+// importRow imports a single CSV row, creating a contact or updating the one with the same email
+func (s *ImportJobService) importRow(row []string, columns []importColumn, rules []models.ContactValidationRule, usage map[uint]int, updateExisting bool, importedBy uint) (importRowOutcome, []string, []string) {
+	values := importRowValues(row, columns)
+
+	email := ""
+	if v := values["email"]; v != nil {
+		email = *v
+	}
+	if email == "" {
+		return importRowFailed, []string{"email is required"}, nil
+	}
+	if !isValidEmail(email) {
+		return importRowFailed, []string{"invalid email format"}, nil
+	}
+
+	var before *models.Contact
+	contact, err := s.contactRepo.GetByEmail(email)
+	if err == nil && contact != nil {
+		if !updateExisting {
+			return importRowSkipped, nil, nil
+		}
+		snapshot := *contact
+		before = &snapshot
+	} else {
+		contact = &models.Contact{
+			Status:                models.StatusNew,
+			Priority:              models.PriorityMedium,
+			Country:               "India",
+			DataProcessingConsent: true,
+			ContactTypeID:         1, // General Inquiry
+			ContactSourceID:       1,
+			DataSource:            "import",
+			CreatedBy:             &importedBy,
+		}
+	}
+
+	if err := contact.ApplyFieldValues(values); err != nil {
+		return importRowFailed, []string{err.Error()}, nil
+	}
+	if contact.FirstName == "" {
+		return importRowFailed, []string{"first name is required"}, nil
+	}
+
+	validation := s.validator.Evaluate(rules, contact, usage)
+	var warnings []string
+	for _, warning := range validation.Warnings {
+		warnings = append(warnings, fmt.Sprintf("%s: %s", warning.Field, warning.Message))
+	}
+	if validation.HasErrors() {
+		var rowErrors []string
+		for _, fieldErr := range validation.Errors {
+			rowErrors = append(rowErrors, fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message))
+		}
+		return importRowFailed, rowErrors, warnings
+	}
+
+	if before != nil {
+		contact.UpdatedBy = &importedBy
+		if err := s.contactRepo.Update(contact); err != nil {
+			return importRowFailed, []string{fmt.Sprintf("failed to update contact: %v", err)}, warnings
+		}
+		s.recordHistory(before, contact, &importedBy)
+		return importRowUpdated, nil, warnings
+	}
+
+	if err := s.contactRepo.Create(contact); err != nil {
+		return importRowFailed, []string{fmt.Sprintf("failed to create contact: %v", err)}, warnings
+	}
+	s.recordHistory(nil, contact, &importedBy)
+	return importRowImported, nil, warnings
+}
+
+func (s *ImportJobService) recordHistory(before, after *models.Contact, importedBy *uint) {
+	if err := s.historyRepo.RecordChanges(before, after, importedBy, models.ChangeSourceImport, "CSV import"); err != nil {
+		logger.Error("Failed to record contact field history", err, map[string]interface{}{
+			"contact_id": after.ID,
+			"source":     models.ChangeSourceImport,
+		})
+	}
+}
+
+// updates returns the counter columns to persist for the current progress
+func (p *importProgress) updates(total int) map[string]interface{} {
+	percentage := 100.0
+	if total > 0 {
+		percentage = math.Round(float64(p.processed)/float64(total)*10000) / 100
+	}
+	return map[string]interface{}{
+		"heartbeat_at":        time.Now(),
+		"progress_percentage": percentage,
+		"records_processed":   p.processed,
+		"records_imported":    p.imported,
+		"records_updated":     p.updated,
+		"records_skipped":     p.skipped,
+		"records_failed":      p.failed,
+		"duplicates_found":    p.duplicates,
+	}
+}
+
+// warn appends a line to the warning log until it reaches its size limit
+func (p *importProgress) warn(line string) {
+	if p.warnings.Len()+len(line)+1 > maxImportErrorLogBytes {
+		return
+	}
+	p.warnings.WriteString(line)
+	p.warnings.WriteByte('\n')
+}
+
+// resolveImportMapping matches CSV headers to contact fields using the supplied mapping,
+// or by header name and common aliases when none is given
+func resolveImportMapping(headers []string, mapping map[string]string) ([]importColumn, error) {
+	var columns []importColumn
+
+	if len(mapping) > 0 {
+		headerIndex := make(map[string]int, len(headers))
+		for i, header := range headers {
+			headerIndex[strings.TrimSpace(header)] = i
+		}
+		for header, field := range mapping {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue // Explicitly ignored column
+			}
+			index, ok := headerIndex[strings.TrimSpace(header)]
+			if !ok {
+				return nil, fmt.Errorf("column %q in field mapping not found in CSV", header)
+			}
+			if field != importFullNameField && (!models.IsContactField(field) || protectedImportFields[field]) {
+				return nil, fmt.Errorf("column %q maps to unknown contact field %q", header, field)
+			}
+			columns = append(columns, importColumn{index: index, header: strings.TrimSpace(header), field: field})
+		}
+	} else {
+		for i, header := range headers {
+			key := strings.ToLower(strings.TrimSpace(header))
+			key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+			field, ok := importHeaderAliases[key]
+			if !ok && models.IsContactField(key) && !protectedImportFields[key] {
+				field, ok = key, true
+			}
+			if ok {
+				columns = append(columns, importColumn{index: i, header: strings.TrimSpace(header), field: field})
+			}
+		}
+	}
+
+	hasEmail, hasName := false, false
+	for _, column := range columns {
+		switch column.field {
+		case "email":
+			hasEmail = true
+		case "first_name", importFullNameField:
+			hasName = true
+		}
+	}
+	if !hasEmail {
+		return nil, fmt.Errorf("field mapping must include an email column")
+	}
+	if !hasName {
+		return nil, fmt.Errorf("field mapping must include a first_name or name column")
+	}
+
+	return columns, nil
+}
+
+// importRowValues extracts the non-blank mapped values of a row keyed by contact field
+func importRowValues(row []string, columns []importColumn) map[string]*string {
+	values := make(map[string]*string, len(columns))
+	for _, column := range columns {
+		if column.index >= len(row) {
+			continue
+		}
+		value := strings.TrimSpace(row[column.index])
+		if value == "" {
+			continue
+		}
+
+		if column.field == importFullNameField {
+			parts := strings.Fields(value)
+			first := parts[0]
+			values["first_name"] = &first
+			if len(parts) > 1 {
+				last := strings.Join(parts[1:], " ")
+				values["last_name"] = &last
+			}
+			continue
+		}
+		values[column.field] = &value
+	}
+	return values
+}
+
+// failedImportRecord captures a failed row with its original values keyed by header
+func failedImportRecord(rowNum int, headers, row []string, rowErrors []string) models.ImportFailedRecord {
+	data := make(map[string]string, len(headers))
+	for i, header := range headers {
+		if i < len(row) {
+			data[header] = row[i]
+		}
+	}
+	return models.ImportFailedRecord{Row: rowNum, Data: data, Errors: rowErrors}
+}
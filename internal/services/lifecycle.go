@@ -9,20 +9,38 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// Points the built-in email engagement signal adds to a contact's lead score
+const (
+	emailEngagementWindowDays = 30
+	emailClickScore           = 10
+	emailOpenScore            = 5
+	emailPastEngagementScore  = 2
+)
+
 // LifecycleService handles contact lifecycle management, lead scoring, and status transitions
 type LifecycleService struct {
-	db *gorm.DB
+	db         *gorm.DB
+	scheduling *SchedulingService // Optional; sends invites for appointments booked by transition rules
+	config     ScoringConfig
 }
 
 // NewLifecycleService creates a new lifecycle service
 func NewLifecycleService(db *gorm.DB) *LifecycleService {
-	return &LifecycleService{db: db}
+	return &LifecycleService{db: db, config: LoadScoringConfig()}
+}
+
+// SetScheduling lets transition rule actions send calendar invites for the appointments they book
+func (s *LifecycleService) SetScheduling(scheduling *SchedulingService) {
+	s.scheduling = scheduling
 }
 
-// ScoreContact calculates and updates the lead score for a contact
+// ScoreContact calculates and updates the lead score for a contact. The lifecycle record, the contact's
+// lead score, the contact_scores row and the per-rule score_calculation_log entries are written in one
+// transaction, together with the automation of the score band the contact moved into, if any.
 func (s *LifecycleService) ScoreContact(contactID uint, forceRescore bool, reason string, scoredByUserID *uint) (*models.ContactLifecycleResponse, error) {
 	// Get the contact with related data
 	var contact models.Contact
@@ -40,7 +58,7 @@ func (s *LifecycleService) ScoreContact(contactID uint, forceRescore bool, reaso
 				ContactID:       contactID,
 				CurrentStatus:   contact.Status,
 				CurrentStage:    models.StageUnknown,
-				CurrentScore:    0,
+				CurrentScore:    contact.LeadScore,
 				StageEnteredAt:  time.Now(),
 				StatusEnteredAt: time.Now(),
 				LastScoredAt:    time.Now(),
@@ -54,71 +72,26 @@ func (s *LifecycleService) ScoreContact(contactID uint, forceRescore bool, reaso
 	}
 
 	// Check if we need to rescore
-	if !forceRescore && s.isRecentlyScored(&lifecycle) {
+	if !forceRescore && lifecycle.ID != 0 && s.isRecentlyScored(&lifecycle) {
 		return s.buildLifecycleResponse(&lifecycle, &contact)
 	}
 
-	// Get active scoring rules
-	var rules []models.LeadScoringRule
-	if err := s.db.Where("is_active = ? AND deleted_at IS NULL", true).
-		Order("priority DESC, created_at ASC").Find(&rules).Error; err != nil {
-		return nil, fmt.Errorf("failed to get scoring rules: %v", err)
-	}
-
-	// Calculate scores
-	totalScore := 0
-	demoScore := 0
-	behavioralScore := 0
-	engagementScore := 0
-	firmoScore := 0
-	scoringFactors := make(models.JSONMap)
-	appliedRules := make([]string, 0)
-
-	for _, rule := range rules {
-		if s.isRuleApplicable(&rule, &contact) {
-			ruleScore := s.calculateRuleScore(&rule, &contact)
-			totalScore += ruleScore
-
-			// Categorize scores
-			switch rule.Category {
-			case "demographic":
-				demoScore += ruleScore
-			case "behavioral":
-				behavioralScore += ruleScore
-			case "engagement":
-				engagementScore += ruleScore
-			case "firmographic":
-				firmoScore += ruleScore
-			}
-
-			if ruleScore > 0 {
-				appliedRules = append(appliedRules, rule.Name)
-				scoringFactors[rule.Name] = map[string]interface{}{
-					"score":    ruleScore,
-					"category": rule.Category,
-					"reason":   s.getRuleScoreReason(&rule, &contact),
-				}
-
-				// Update rule statistics
-				s.updateRuleStatistics(&rule)
-			}
-		}
-	}
-
-	// Ensure score doesn't exceed maximum
-	if totalScore > 100 {
-		totalScore = 100
+	calculation, err := s.calculateScore(&contact)
+	if err != nil {
+		return nil, err
 	}
+	totalScore := calculation.total
+	scoringFactors := calculation.factors()
 
 	// Save score history
 	scoreSnapshot := models.ScoreSnapshot{
 		ContactID:       contactID,
 		Timestamp:       time.Now(),
 		TotalScore:      totalScore,
-		DemoScore:       demoScore,
-		BehavioralScore: behavioralScore,
-		EngagementScore: engagementScore,
-		FirmoScore:      firmoScore,
+		DemoScore:       calculation.components["demographic"],
+		BehavioralScore: calculation.components["behavioral"],
+		EngagementScore: calculation.components["engagement"],
+		FirmoScore:      calculation.components["firmographic"],
 		Factors:         scoringFactors,
 	}
 
@@ -132,73 +105,115 @@ func (s *LifecycleService) ScoreContact(contactID uint, forceRescore bool, reaso
 		scoreHistory = scoreHistory[1:]
 	}
 
-	// Update lifecycle record
 	previousScore := lifecycle.CurrentScore
+	changeAmount := totalScore - previousScore
+	thresholds := s.scoreThresholds()
+	previousBand := models.MatchScoreThreshold(thresholds, previousScore)
+	band := models.MatchScoreThreshold(thresholds, totalScore)
+	batchID := uuid.New().String()
 	now := time.Now()
-	
-	updates := map[string]interface{}{
-		"current_score":      totalScore,
-		"demographic_score":  demoScore,
-		"behavioral_score":   behavioralScore,
-		"engagement_score":   engagementScore,
-		"firmographic_score": firmoScore,
-		"score_history":      scoreHistory,
-		"scoring_factors":    scoringFactors,
-		"last_scored_at":     now,
-	}
-
-	// Update lifecycle stage based on score
-	newStage := s.determineLifecycleStage(totalScore, &contact)
-	if newStage != lifecycle.CurrentStage {
-		updates["current_stage"] = newStage
-		updates["stage_entered_at"] = now
-		
-		// Record stage change event
-		s.recordLifecycleEvent(contactID, lifecycle.ID, "stage_change", 
-			fmt.Sprintf("Stage changed from %s to %s", lifecycle.CurrentStage, newStage),
-			string(lifecycle.CurrentStage), string(newStage), nil, scoredByUserID, "automatic", "scoring")
-	}
-
-	// Calculate days in current status/stage
-	updates["days_in_current_status"] = int(time.Since(lifecycle.StatusEnteredAt).Hours() / 24)
-	updates["days_in_current_stage"] = int(time.Since(lifecycle.StageEnteredAt).Hours() / 24)
-	updates["total_lifecycle_days"] = int(time.Since(lifecycle.CreatedAt).Hours() / 24)
-
-	// Update the lifecycle record
-	if lifecycle.ID == 0 {
-		lifecycle.CurrentScore = totalScore
-		lifecycle.DemographicScore = demoScore
-		lifecycle.BehavioralScore = behavioralScore
-		lifecycle.EngagementScore = engagementScore
-		lifecycle.FirmographicScore = firmoScore
-		lifecycle.ScoreHistory = scoreHistory
-		lifecycle.ScoringFactors = scoringFactors
-		lifecycle.LastScoredAt = now
-		lifecycle.CurrentStage = newStage
-		if err := s.db.Create(&lifecycle).Error; err != nil {
-			return nil, fmt.Errorf("failed to create lifecycle record: %v", err)
-		}
-	} else {
-		if err := s.db.Model(&lifecycle).Updates(updates).Error; err != nil {
-			return nil, fmt.Errorf("failed to update lifecycle record: %v", err)
-		}
-		// Reload the record
-		s.db.First(&lifecycle, lifecycle.ID)
-	}
-
-	// Update contact's lead score
-	if err := s.db.Model(&contact).Update("lead_score", totalScore).Error; err != nil {
-		logger.Error("Failed to update contact lead score", err, map[string]interface{}{
-			"contact_id": contactID,
-			"new_score":  totalScore,
-		})
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		txService := s.withDB(tx)
+
+		// Update lifecycle stage based on score
+		previousStage := lifecycle.CurrentStage
+		newStage := s.determineLifecycleStage(totalScore, &contact)
+
+		if lifecycle.ID == 0 {
+			lifecycle.CurrentScore = totalScore
+			lifecycle.DemographicScore = calculation.components["demographic"]
+			lifecycle.BehavioralScore = calculation.components["behavioral"]
+			lifecycle.EngagementScore = calculation.components["engagement"]
+			lifecycle.FirmographicScore = calculation.components["firmographic"]
+			lifecycle.ScoreHistory = scoreHistory
+			lifecycle.ScoringFactors = scoringFactors
+			lifecycle.LastScoredAt = now
+			lifecycle.CurrentStage = newStage
+			if err := tx.Create(&lifecycle).Error; err != nil {
+				return fmt.Errorf("failed to create lifecycle record: %v", err)
+			}
+		} else {
+			updates := map[string]interface{}{
+				"current_score":          totalScore,
+				"demographic_score":      calculation.components["demographic"],
+				"behavioral_score":       calculation.components["behavioral"],
+				"engagement_score":       calculation.components["engagement"],
+				"firmographic_score":     calculation.components["firmographic"],
+				"score_history":          scoreHistory,
+				"scoring_factors":        scoringFactors,
+				"last_scored_at":         now,
+				"days_in_current_status": int(time.Since(lifecycle.StatusEnteredAt).Hours() / 24),
+				"days_in_current_stage":  int(time.Since(lifecycle.StageEnteredAt).Hours() / 24),
+				"total_lifecycle_days":   int(time.Since(lifecycle.CreatedAt).Hours() / 24),
+			}
+			if newStage != previousStage {
+				updates["current_stage"] = newStage
+				updates["stage_entered_at"] = now
+			}
+			if err := tx.Model(&lifecycle).Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to update lifecycle record: %v", err)
+			}
+			if err := tx.First(&lifecycle, lifecycle.ID).Error; err != nil {
+				return fmt.Errorf("failed to reload lifecycle record: %v", err)
+			}
+		}
+
+		if newStage != previousStage {
+			txService.recordLifecycleEvent(contactID, lifecycle.ID, "stage_change",
+				fmt.Sprintf("Stage changed from %s to %s", previousStage, newStage),
+				string(previousStage), string(newStage), nil, scoredByUserID, "automatic", "scoring")
+		}
+
+		// Update contact's lead score
+		beforeScore := contact
+		if err := tx.Model(&contact).Update("lead_score", totalScore).Error; err != nil {
+			return fmt.Errorf("failed to update contact lead score: %v", err)
+		}
+		recordContactHistorySince(tx, &beforeScore, scoredByUserID, models.ChangeSourceLifecycle, reason)
+
+		if err := txService.recordScoreCalculation(&contact, calculation, previousScore, band, batchID, reason); err != nil {
+			return err
+		}
+
+		// Record scoring event
+		txService.recordLifecycleEvent(contactID, lifecycle.ID, "score_change",
+			fmt.Sprintf("Lead score updated from %d to %d (%+d)", previousScore, totalScore, changeAmount),
+			strconv.Itoa(previousScore), strconv.Itoa(totalScore), &changeAmount, scoredByUserID, "automatic", "scoring")
+
+		// Fire the automation of the band the contact moved into
+		if band != nil && scoreBandChanged(previousBand, band) {
+			description := fmt.Sprintf("Score band changed from %s to %s", bandName(previousBand), band.Name)
+			previous := bandName(previousBand)
+			event := &models.LifecycleEvent{
+				ContactID:        contactID,
+				LifecycleID:      lifecycle.ID,
+				EventType:        "score_band_change",
+				EventName:        description,
+				EventDescription: description,
+				PreviousValue:    &previous,
+				NewValue:         band.Name,
+				TriggerType:      "automatic",
+				TriggerSource:    "scoring",
+				TriggeredBy:      scoredByUserID,
+				TriggerData:      txService.runScoreBandAutomation(&contact, previousBand, band, scoredByUserID),
+			}
+			if err := tx.Create(event).Error; err != nil {
+				return fmt.Errorf("failed to record score band change: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Record scoring event
-	changeAmount := totalScore - previousScore
-	s.recordLifecycleEvent(contactID, lifecycle.ID, "score_change",
-		fmt.Sprintf("Lead score updated from %d to %d (%+d)", previousScore, totalScore, changeAmount),
-		strconv.Itoa(previousScore), strconv.Itoa(totalScore), &changeAmount, scoredByUserID, "automatic", "scoring")
+	// Update rule statistics
+	for _, contribution := range calculation.contributions {
+		if contribution.rule != nil && contribution.points > 0 {
+			s.updateRuleStatistics(contribution.rule)
+		}
+	}
 
 	// Check for automatic status transitions
 	s.checkAutomaticStatusTransitions(&contact, &lifecycle)
@@ -208,7 +223,9 @@ func (s *LifecycleService) ScoreContact(contactID uint, forceRescore bool, reaso
 		"previous_score": previousScore,
 		"new_score":      totalScore,
 		"change":         changeAmount,
-		"applied_rules":  len(appliedRules),
+		"applied_rules":  len(calculation.contributions),
+		"decay_amount":   calculation.decayAmount,
+		"batch_id":       batchID,
 		"reason":         reason,
 	})
 
@@ -217,95 +234,161 @@ func (s *LifecycleService) ScoreContact(contactID uint, forceRescore bool, reaso
 
 // ChangeContactStatus manually changes a contact's status
 func (s *LifecycleService) ChangeContactStatus(request *models.StatusChangeRequest, changedByUserID uint) error {
-	// Get the contact
-	var contact models.Contact
-	if err := s.db.First(&contact, request.ContactID).Error; err != nil {
-		return fmt.Errorf("contact not found: %v", err)
-	}
+	return s.changeContactStatus(request, "", &changedByUserID, "manual", "user_action", nil)
+}
 
-	// Check if status change is valid
-	if contact.Status == request.NewStatus {
-		return fmt.Errorf("contact is already in %s status", request.NewStatus)
-	}
+// changeContactStatus changes a contact's status and records the lifecycle event. A non-empty
+// expected status must still be current. The update only applies while the contact is in the status
+// it was read in, so concurrent changes (another user, or a sweep on another replica) cannot both apply.
+// When a transition rule drives the change, its actions and notifications run in the same transaction
+// and their outcomes are recorded on the event; webhooks and calendar invites go out after commit.
+func (s *LifecycleService) changeContactStatus(request *models.StatusChangeRequest, expected models.ContactStatus, changedBy *uint, triggerType, triggerSource string, rule *models.StatusTransitionRule) error {
+	var previousStatus models.ContactStatus
+	var effects *transitionEffects
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		txService := s.withDB(tx)
+
+		// Get the contact
+		var contact models.Contact
+		if err := tx.First(&contact, request.ContactID).Error; err != nil {
+			return fmt.Errorf("contact not found: %v", err)
+		}
+		if expected != "" && contact.Status != expected {
+			return fmt.Errorf("contact status was already changed concurrently")
+		}
 
-	// Check transition rules if not forced
-	if !request.ForceChange {
-		if !s.isStatusTransitionAllowed(contact.Status, request.NewStatus) {
-			return fmt.Errorf("transition from %s to %s is not allowed", contact.Status, request.NewStatus)
+		// Check if status change is valid
+		if contact.Status == request.NewStatus {
+			return fmt.Errorf("contact is already in %s status", request.NewStatus)
 		}
-	}
 
-	// Get lifecycle record
-	var lifecycle models.ContactLifecycle
-	if err := s.db.Where("contact_id = ?", request.ContactID).First(&lifecycle).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// Create lifecycle record if it doesn't exist
-			lifecycle = models.ContactLifecycle{
-				ContactID:       request.ContactID,
-				CurrentStatus:   contact.Status,
-				CurrentStage:    models.StageUnknown,
-				CurrentScore:    contact.LeadScore,
-				StageEnteredAt:  time.Now(),
-				StatusEnteredAt: time.Now(),
-				LastScoredAt:    time.Now(),
+		// Check transition rules if not forced
+		if !request.ForceChange {
+			if !s.isStatusTransitionAllowed(contact.Status, request.NewStatus) {
+				return fmt.Errorf("transition from %s to %s is not allowed", contact.Status, request.NewStatus)
 			}
-			s.db.Create(&lifecycle)
-		} else {
-			return fmt.Errorf("failed to get lifecycle record: %v", err)
 		}
-	}
 
-	// Update contact status
-	previousStatus := contact.Status
-	now := time.Now()
-	
-	if err := s.db.Model(&contact).Updates(map[string]interface{}{
-		"status":              request.NewStatus,
-		"last_activity_date": now,
-	}).Error; err != nil {
-		return fmt.Errorf("failed to update contact status: %v", err)
-	}
+		// Get lifecycle record
+		var lifecycle models.ContactLifecycle
+		if err := tx.Where("contact_id = ?", request.ContactID).First(&lifecycle).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// Create lifecycle record if it doesn't exist
+				lifecycle = models.ContactLifecycle{
+					ContactID:       request.ContactID,
+					CurrentStatus:   contact.Status,
+					CurrentStage:    models.StageUnknown,
+					CurrentScore:    contact.LeadScore,
+					StageEnteredAt:  time.Now(),
+					StatusEnteredAt: time.Now(),
+					LastScoredAt:    time.Now(),
+				}
+				if err := tx.Create(&lifecycle).Error; err != nil {
+					return fmt.Errorf("failed to create lifecycle record: %v", err)
+				}
+			} else {
+				return fmt.Errorf("failed to get lifecycle record: %v", err)
+			}
+		}
 
-	// Update lifecycle record
-	updates := map[string]interface{}{
-		"current_status":     request.NewStatus,
-		"status_entered_at":  now,
-		"days_in_current_status": 0,
-	}
+		// Update contact status
+		previousStatus = contact.Status
+		before := contact
+		now := time.Now()
 
-	// Update milestones based on new status
-	switch request.NewStatus {
-	case models.StatusQualified:
-		if lifecycle.QualificationAt == nil {
-			updates["qualification_at"] = now
+		result := tx.Model(&contact).Where("status = ?", previousStatus).Updates(map[string]interface{}{
+			"status":             request.NewStatus,
+			"last_activity_date": now,
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update contact status: %v", result.Error)
 		}
-	case models.StatusProposal, models.StatusNegotiation:
-		if lifecycle.OpportunityAt == nil {
-			updates["opportunity_at"] = now
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("contact status was already changed concurrently")
 		}
-	case models.StatusClosedWon:
-		if lifecycle.ConversionAt == nil {
-			updates["conversion_at"] = now
+		contact.Status = request.NewStatus
+
+		recordContactHistorySince(tx, &before, changedBy, models.ChangeSourceLifecycle, request.Reason)
+
+		// Update lifecycle record
+		updates := map[string]interface{}{
+			"current_status":         request.NewStatus,
+			"status_entered_at":      now,
+			"days_in_current_status": 0,
 		}
-	}
 
-	if err := s.db.Model(&lifecycle).Updates(updates).Error; err != nil {
-		logger.Error("Failed to update lifecycle on status change", err, map[string]interface{}{
-			"contact_id": request.ContactID,
-			"new_status": request.NewStatus,
-		})
+		// Update milestones based on new status
+		switch request.NewStatus {
+		case models.StatusQualified:
+			if lifecycle.QualificationAt == nil {
+				updates["qualification_at"] = now
+			}
+		case models.StatusProposal, models.StatusNegotiation:
+			if lifecycle.OpportunityAt == nil {
+				updates["opportunity_at"] = now
+			}
+		case models.StatusClosedWon:
+			if lifecycle.ConversionAt == nil {
+				updates["conversion_at"] = now
+			}
+		}
+
+		if err := tx.Model(&lifecycle).Updates(updates).Error; err != nil {
+			logger.Error("Failed to update lifecycle on status change", err, map[string]interface{}{
+				"contact_id": request.ContactID,
+				"new_status": request.NewStatus,
+			})
+		}
+
+		// Record status change event, with the outcome of the rule's actions
+		description := fmt.Sprintf("Status changed from %s to %s: %s", previousStatus, request.NewStatus, request.Reason)
+		previous := string(previousStatus)
+		event := &models.LifecycleEvent{
+			ContactID:        request.ContactID,
+			LifecycleID:      lifecycle.ID,
+			EventType:        "status_change",
+			EventName:        description,
+			EventDescription: description,
+			PreviousValue:    &previous,
+			NewValue:         string(request.NewStatus),
+			TriggerType:      triggerType,
+			TriggerSource:    triggerSource,
+			TriggeredBy:      changedBy,
+		}
+		if rule != nil {
+			var err error
+			if effects, err = txService.runTransitionActions(rule, &contact, previousStatus, changedBy, triggerType); err != nil {
+				return err
+			}
+			effects.event = event
+			event.TriggerData = effects.triggerData()
+
+			if err := tx.Model(rule).Updates(map[string]interface{}{
+				"times_triggered":   gorm.Expr("times_triggered + 1"),
+				"last_triggered_at": now,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update transition rule statistics: %v", err)
+			}
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return fmt.Errorf("failed to record lifecycle event: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// Record status change event
-	s.recordLifecycleEvent(request.ContactID, lifecycle.ID, "status_change",
-		fmt.Sprintf("Status changed from %s to %s: %s", previousStatus, request.NewStatus, request.Reason),
-		string(previousStatus), string(request.NewStatus), nil, &changedByUserID, "manual", "user_action")
+	if effects != nil {
+		s.finishTransition(effects)
+	}
 
 	logger.Info("Contact status changed", map[string]interface{}{
 		"contact_id":      request.ContactID,
 		"previous_status": previousStatus,
 		"new_status":      request.NewStatus,
-		"changed_by":      changedByUserID,
+		"changed_by":      changedBy,
+		"trigger":         triggerSource,
 		"reason":          request.Reason,
 	})
 
@@ -333,7 +416,7 @@ func (s *LifecycleService) BulkChangeContactStatus(request *models.BulkStatusCha
 		}
 
 		// Use a new service instance with the transaction
-		txService := &LifecycleService{db: tx}
+		txService := s.withDB(tx)
 		if err := txService.ChangeContactStatus(statusRequest, changedByUserID); err != nil {
 			logger.Error("Failed to change status in bulk operation", err, map[string]interface{}{
 				"contact_id": contactID,
@@ -460,13 +543,15 @@ func (s *LifecycleService) AnalyzeContactScoring(contactID uint) (*models.Scorin
 
 	// Generate recommendations
 	recommendations := s.generateScoringRecommendations(&lifecycle, &contact)
+	grade, category := s.scoreGrade(lifecycle.CurrentScore)
 
 	response := &models.ScoringAnalysisResponse{
 		ContactID:         contactID,
 		TotalScore:        lifecycle.CurrentScore,
 		MaxPossibleScore:  maxPossibleScore,
 		ScorePercentage:   scorePercentage,
-		Grade:             s.calculateScoreGrade(lifecycle.CurrentScore),
+		Grade:             grade,
+		Category:          category,
 		CategoryBreakdown: categoryBreakdown,
 		AppliedRules:      appliedRules,
 		ScoringFactors:    lifecycle.ScoringFactors,
@@ -581,6 +666,22 @@ func (s *LifecycleService) evaluateScoringCriteria(criteria models.ScoringCriter
 	}
 }
 
+// emailEngagementScore scores recent opens and clicks of outbound email; older engagement counts for less
+func (s *LifecycleService) emailEngagementScore(contact *models.Contact, recent engagementSignals) (int, string) {
+	if !contact.EmailOpened && !contact.EmailClicked {
+		return 0, ""
+	}
+
+	window := time.Now().AddDate(0, 0, -emailEngagementWindowDays)
+	switch {
+	case recent.LastClickedAt != nil && recent.LastClickedAt.After(window):
+		return emailClickScore, fmt.Sprintf("Clicked an email link in the last %d days", emailEngagementWindowDays)
+	case recent.LastOpenedAt != nil && recent.LastOpenedAt.After(window):
+		return emailOpenScore, fmt.Sprintf("Opened an email in the last %d days", emailEngagementWindowDays)
+	}
+	return emailPastEngagementScore, "Engaged with email previously"
+}
+
 // getContactFieldValue gets field value from contact
 func (s *LifecycleService) getContactFieldValue(fieldName string, contact *models.Contact) interface{} {
 	switch fieldName {
@@ -720,16 +821,16 @@ func (s *LifecycleService) shouldTriggerTransition(rule *models.StatusTransition
 	return true
 }
 
-// executeStatusTransition executes an automatic status transition
+// executeStatusTransition executes an automatic status transition, running the rule's actions
 func (s *LifecycleService) executeStatusTransition(rule *models.StatusTransitionRule, contact *models.Contact, lifecycle *models.ContactLifecycle) {
 	previousStatus := contact.Status
-	now := time.Now()
-
-	// Update contact status
-	if err := s.db.Model(contact).Updates(map[string]interface{}{
-		"status":              rule.ToStatus,
-		"last_activity_date": now,
-	}).Error; err != nil {
+	err := s.changeContactStatus(&models.StatusChangeRequest{
+		ContactID:   contact.ID,
+		NewStatus:   rule.ToStatus,
+		Reason:      fmt.Sprintf("Automatic transition via rule: %s", rule.Name),
+		ForceChange: true,
+	}, previousStatus, nil, string(models.TransitionAutomatic), fmt.Sprintf("rule:%d", rule.ID), rule)
+	if err != nil {
 		logger.Error("Failed to execute automatic status transition", err, map[string]interface{}{
 			"contact_id": contact.ID,
 			"rule_id":    rule.ID,
@@ -738,25 +839,10 @@ func (s *LifecycleService) executeStatusTransition(rule *models.StatusTransition
 		return
 	}
 
-	// Update lifecycle
-	updates := map[string]interface{}{
-		"current_status":     rule.ToStatus,
-		"status_entered_at":  now,
-		"days_in_current_status": 0,
-	}
-
-	s.db.Model(lifecycle).Updates(updates)
-
-	// Record transition event
-	s.recordLifecycleEvent(contact.ID, lifecycle.ID, "status_change",
-		fmt.Sprintf("Automatic status transition from %s to %s via rule: %s", previousStatus, rule.ToStatus, rule.Name),
-		string(previousStatus), string(rule.ToStatus), nil, nil, "automatic", fmt.Sprintf("rule:%d", rule.ID))
-
-	// Update rule statistics
-	s.db.Model(rule).Updates(map[string]interface{}{
-		"times_triggered":   gorm.Expr("times_triggered + 1"),
-		"last_triggered_at": now,
-	})
+	contact.Status = rule.ToStatus
+	lifecycle.CurrentStatus = rule.ToStatus
+	lifecycle.StatusEnteredAt = time.Now()
+	lifecycle.DaysInCurrentStatus = 0
 
 	logger.Info("Automatic status transition executed", map[string]interface{}{
 		"contact_id":      contact.ID,
@@ -810,6 +896,7 @@ func (s *LifecycleService) buildLifecycleResponse(lifecycle *models.ContactLifec
 		}
 	}
 
+	grade, category := s.scoreGrade(lifecycle.CurrentScore)
 	response := &models.ContactLifecycleResponse{
 		ID:                  lifecycle.ID,
 		ContactID:           lifecycle.ContactID,
@@ -837,7 +924,8 @@ func (s *LifecycleService) buildLifecycleResponse(lifecycle *models.ContactLifec
 		ConversionRate:      lifecycle.ConversionRate,
 		CreatedAt:           lifecycle.CreatedAt,
 		UpdatedAt:           lifecycle.UpdatedAt,
-		ScoreGrade:          s.calculateScoreGrade(lifecycle.CurrentScore),
+		ScoreGrade:          grade,
+		ScoreCategory:       category,
 		QualificationStatus: s.determineQualificationStatus(lifecycle.CurrentScore, lifecycle.CurrentStatus),
 		NextSuggestedAction: s.suggestNextAction(lifecycle.CurrentScore, lifecycle.CurrentStatus, lifecycle.CurrentStage),
 	}
@@ -845,22 +933,6 @@ func (s *LifecycleService) buildLifecycleResponse(lifecycle *models.ContactLifec
 	return response, nil
 }
 
-// calculateScoreGrade calculates letter grade based on score
-func (s *LifecycleService) calculateScoreGrade(score int) string {
-	switch {
-	case score >= 90:
-		return "A"
-	case score >= 80:
-		return "B"
-	case score >= 70:
-		return "C"
-	case score >= 60:
-		return "D"
-	default:
-		return "F"
-	}
-}
-
 // determineQualificationStatus determines qualification status
 func (s *LifecycleService) determineQualificationStatus(score int, status models.ContactStatus) string {
 	if score >= 60 || status == models.StatusQualified {
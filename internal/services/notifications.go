@@ -0,0 +1,106 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationService manages admin users' in-app notifications
+type NotificationService struct {
+	db *gorm.DB
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{db: db}
+}
+
+// ListNotifications returns a page of the user's notifications, newest first
+func (s *NotificationService) ListNotifications(userID uint, unreadOnly bool, page, limit int) (*models.NotificationListResponse, error) {
+	query := s.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count notifications: %v", err)
+	}
+	notifications := []models.Notification{}
+	if err := query.Order("created_at DESC, id DESC").Offset((page - 1) * limit).Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %v", err)
+	}
+
+	var unread int64
+	if err := s.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&unread).Error; err != nil {
+		return nil, fmt.Errorf("failed to count unread notifications: %v", err)
+	}
+
+	return &models.NotificationListResponse{
+		Notifications: notifications,
+		Total:         total,
+		Unread:        unread,
+		Page:          page,
+		Limit:         limit,
+	}, nil
+}
+
+// MarkRead marks one of the user's notifications as read
+func (s *NotificationService) MarkRead(userID, notificationID uint) (*models.Notification, error) {
+	var notification models.Notification
+	if err := s.db.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("notification not found")
+		}
+		return nil, fmt.Errorf("failed to get notification: %v", err)
+	}
+	if notification.ReadAt != nil {
+		return &notification, nil
+	}
+
+	now := time.Now()
+	if err := s.db.Model(&notification).Update("read_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to mark notification read: %v", err)
+	}
+	notification.ReadAt = &now
+	return &notification, nil
+}
+
+// MarkAllRead marks all of the user's notifications as read and returns how many changed
+func (s *NotificationService) MarkAllRead(userID uint) (int64, error) {
+	result := s.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Update("read_at", time.Now())
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %v", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// notifyUsers stores a copy of the notification for each active user, skipping unknown or
+// deactivated accounts, and returns the users notified. Pass a transaction to make the
+// notifications part of a larger change.
+func notifyUsers(db *gorm.DB, userIDs []uint, notification models.Notification) ([]uint, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var active []uint
+	if err := db.Model(&models.AdminUser{}).Where("id IN ? AND is_active = ?", userIDs, true).Pluck("id", &active).Error; err != nil {
+		return nil, fmt.Errorf("failed to get users to notify: %v", err)
+	}
+	if len(active) == 0 {
+		return nil, nil
+	}
+
+	notifications := make([]models.Notification, len(active))
+	for i, userID := range active {
+		notifications[i] = notification
+		notifications[i].UserID = userID
+	}
+	if err := db.Create(&notifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to create notifications: %v", err)
+	}
+	return active, nil
+}
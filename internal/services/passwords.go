@@ -0,0 +1,377 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/auth"
+	"contact-service/pkg/logger"
+	"contact-service/pkg/mailer"
+	"contact-service/pkg/passwords"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// PasswordConfig configures the password policy and password resets
+type PasswordConfig struct {
+	MinLength        int           // Shortest password accepted, in characters
+	BreachedListFile string        // Local list of breached passwords; empty disables the check
+	HistorySize      int           // New passwords may not be any of the last HistorySize passwords, the current one included
+	ResetTokenTTL    time.Duration // How long a reset link works
+	ResetThrottle    time.Duration // Minimum time between reset links sent to one user
+	ResetURL         string        // Page the reset link opens; the token is added as ?token=
+}
+
+// LoadPasswordConfig reads the password configuration from the environment
+func LoadPasswordConfig() PasswordConfig {
+	config := PasswordConfig{
+		MinLength:        8,
+		BreachedListFile: os.Getenv("PASSWORD_BREACHED_LIST_FILE"),
+		HistorySize:      5,
+		ResetTokenTTL:    30 * time.Minute,
+		ResetThrottle:    time.Minute,
+		ResetURL:         os.Getenv("PASSWORD_RESET_URL"),
+	}
+	if length, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH")); err == nil && length > 0 {
+		config.MinLength = length
+	}
+	if size, err := strconv.Atoi(os.Getenv("PASSWORD_HISTORY_SIZE")); err == nil && size >= 0 {
+		config.HistorySize = size
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("PASSWORD_RESET_TTL_MINUTES")); err == nil && minutes > 0 {
+		config.ResetTokenTTL = time.Duration(minutes) * time.Minute
+	}
+	if config.ResetURL == "" {
+		config.ResetURL = strings.TrimRight(os.Getenv("ADMIN_DASHBOARD_URL"), "/") + "/reset-password"
+	}
+	return config
+}
+
+// Reset requests waiting for the worker; further requests are dropped until it catches up
+const passwordResetQueueSize = 100
+
+// PasswordResetNotifier delivers password reset links to users
+type PasswordResetNotifier interface {
+	SendPasswordReset(user *models.AdminUser, link string, expiresAt time.Time) error
+}
+
+// EmailPasswordResetNotifier sends password reset links through the outbound mailer
+type EmailPasswordResetNotifier struct {
+	Mailer mailer.Mailer
+	From   mailer.Address
+}
+
+// SendPasswordReset implements PasswordResetNotifier
+func (n *EmailPasswordResetNotifier) SendPasswordReset(user *models.AdminUser, link string, expiresAt time.Time) error {
+	minutes := int(time.Until(expiresAt).Round(time.Minute).Minutes())
+	return n.Mailer.Send(&mailer.Message{
+		From:    n.From,
+		To:      []mailer.Address{{Name: user.Name, Email: user.Email}},
+		Subject: "Reset your password",
+		PlainBody: fmt.Sprintf("Hello %s,\n\n"+
+			"Someone asked to reset the password of your account. To choose a new password, open this link within %d minutes:\n\n"+
+			"%s\n\n"+
+			"The link works once. If you did not ask for it, you can ignore this email; your password stays the same.\n",
+			user.GetDisplayName(), minutes, link),
+	})
+}
+
+// PasswordService changes and resets passwords under the password policy
+type PasswordService struct {
+	db        *gorm.DB
+	config    PasswordConfig
+	policy    passwords.Policy
+	notifier  PasswordResetNotifier
+	sessions  *SessionService
+	resets    chan resetRequest
+	startOnce sync.Once
+}
+
+// resetRequest is a password reset request waiting for the worker
+type resetRequest struct {
+	email     string
+	ipAddress string
+}
+
+// NewPasswordService creates a new password service, loading the breached password list if one is
+// configured
+func NewPasswordService(db *gorm.DB, config PasswordConfig, notifier PasswordResetNotifier, sessions *SessionService) (*PasswordService, error) {
+	policy := passwords.Policy{MinLength: config.MinLength}
+	if config.BreachedListFile != "" {
+		list, err := passwords.LoadBreachedList(config.BreachedListFile)
+		if err != nil {
+			return nil, err
+		}
+		policy.Breached = list
+	}
+
+	return &PasswordService{
+		db:       db,
+		config:   config,
+		policy:   policy,
+		notifier: notifier,
+		sessions: sessions,
+		resets:   make(chan resetRequest, passwordResetQueueSize),
+	}, nil
+}
+
+// Start launches the worker that sends requested reset links. Calling it again has no effect.
+func (s *PasswordService) Start() {
+	s.startOnce.Do(func() {
+		go func() {
+			for request := range s.resets {
+				if err := s.sendReset(request.email, request.ipAddress); err != nil {
+					logger.Error("Failed to send password reset", err, map[string]interface{}{
+						"email": request.email,
+					})
+				}
+			}
+		}()
+	})
+}
+
+// BreachedPasswords is the number of entries on the breached password list
+func (s *PasswordService) BreachedPasswords() int {
+	return s.policy.Breached.Len()
+}
+
+// ChangePassword sets a new password for a user who knows the current one. The user's other
+// sessions are ended; the session of the request stays signed in.
+func (s *PasswordService) ChangePassword(claims *auth.JWTClaims, currentPassword, newPassword string) error {
+	user, err := s.getUser(claims.UserID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return fmt.Errorf("current password is incorrect")
+	}
+	if err := s.checkNewPassword(user, newPassword); err != nil {
+		return err
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.setPassword(tx, user, newPassword, nil)
+	}); err != nil {
+		return err
+	}
+
+	if _, err := s.sessions.RevokeOtherSessions(claims, models.SessionRevokedPassword); err != nil {
+		logger.Error("Failed to revoke sessions after password change", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+	}
+	return nil
+}
+
+// RequestReset queues a reset link for the active user with the email address and returns at once.
+// The account lookup and the email happen in the background, so neither the response nor how long
+// it takes reveals which accounts exist.
+func (s *PasswordService) RequestReset(email, ipAddress string) {
+	select {
+	case s.resets <- resetRequest{email: email, ipAddress: ipAddress}:
+	default:
+		logger.LogSecurityEvent("password_reset_dropped", nil, ipAddress, map[string]interface{}{
+			"email": email,
+		})
+	}
+}
+
+// sendReset sends a reset link to the active user with the email address. Unknown addresses are
+// ignored.
+func (s *PasswordService) sendReset(email, ipAddress string) error {
+	var user models.AdminUser
+	if err := s.db.Where("email = ? AND is_active = ?", strings.ToLower(email), true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.LogSecurityEvent("password_reset_unknown_email", nil, ipAddress, map[string]interface{}{
+				"email": email,
+			})
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %v", err)
+	}
+
+	var recent int64
+	if err := s.db.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND created_at > ?", user.ID, time.Now().Add(-s.config.ResetThrottle)).
+		Count(&recent).Error; err != nil {
+		return fmt.Errorf("failed to check reset requests: %v", err)
+	}
+	if recent > 0 {
+		logger.LogSecurityEvent("password_reset_throttled", &user.ID, ipAddress, nil)
+		return nil
+	}
+
+	token, hash, err := models.NewPasswordResetToken()
+	if err != nil {
+		return err
+	}
+	record := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.config.ResetTokenTTL),
+	}
+	if ipAddress != "" {
+		record.RequestedIP = &ipAddress
+	}
+
+	// Only the newest link works
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return fmt.Errorf("failed to invalidate reset tokens: %v", err)
+		}
+		if err := tx.Create(record).Error; err != nil {
+			return fmt.Errorf("failed to save reset token: %v", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	link := s.config.ResetURL + "?token=" + url.QueryEscape(token)
+	if err := s.notifier.SendPasswordReset(&user, link, record.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to send reset link: %v", err)
+	}
+
+	logger.LogSecurityEvent("password_reset_requested", &user.ID, ipAddress, map[string]interface{}{
+		"expires_at": record.ExpiresAt,
+	})
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token. The token is spent only when the password
+// is accepted. A reset lifts any lockout and ends all of the user's sessions.
+func (s *PasswordService) ResetPassword(token, newPassword string) (uint, error) {
+	var record models.PasswordResetToken
+	if err := s.db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", models.HashPasswordResetToken(token), time.Now()).
+		First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("invalid or expired reset token")
+		}
+		return 0, fmt.Errorf("failed to get reset token: %v", err)
+	}
+
+	user, err := s.getUser(record.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if !user.IsActive {
+		return 0, fmt.Errorf("invalid or expired reset token")
+	}
+	if err := s.checkNewPassword(user, newPassword); err != nil {
+		return 0, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// A token used concurrently is only spent once
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", record.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return fmt.Errorf("failed to use reset token: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("invalid or expired reset token")
+		}
+
+		return s.setPassword(tx, user, newPassword, map[string]interface{}{
+			"login_attempts": 0,
+			"locked_until":   nil,
+			"lockout_count":  0,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := s.sessions.RevokeAllSessions(user.ID, models.SessionRevokedPassword); err != nil {
+		logger.Error("Failed to revoke sessions after password reset", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+	}
+	return user.ID, nil
+}
+
+// checkNewPassword applies the password policy, including the user's password history
+func (s *PasswordService) checkNewPassword(user *models.AdminUser, newPassword string) error {
+	if err := s.policy.Check(newPassword); err != nil {
+		return fmt.Errorf("invalid password: %v", err)
+	}
+	if s.config.HistorySize == 0 {
+		return nil
+	}
+
+	hashes := []string{user.PasswordHash}
+	if s.config.HistorySize > 1 {
+		var previous []string
+		if err := s.db.Model(&models.PasswordHistory{}).
+			Where("user_id = ?", user.ID).
+			Order("created_at DESC, id DESC").
+			Limit(s.config.HistorySize-1).
+			Pluck("password_hash", &previous).Error; err != nil {
+			return fmt.Errorf("failed to get password history: %v", err)
+		}
+		hashes = append(hashes, previous...)
+	}
+
+	if passwords.MatchesAny(newPassword, hashes) {
+		return fmt.Errorf("invalid password: it must differ from your last %d passwords", s.config.HistorySize)
+	}
+	return nil
+}
+
+// setPassword stores a new password, keeping the old hash in the history, along with any other
+// column updates
+func (s *PasswordService) setPassword(tx *gorm.DB, user *models.AdminUser, newPassword string, updates map[string]interface{}) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %v", err)
+	}
+
+	if updates == nil {
+		updates = map[string]interface{}{}
+	}
+	updates["password_hash"] = string(hash)
+	updates["password_changed_at"] = time.Now()
+	if err := tx.Model(&models.AdminUser{}).Where("id = ?", user.ID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update password: %v", err)
+	}
+
+	// Invited users have no password before they activate the account
+	if s.config.HistorySize <= 1 || user.PasswordHash == "" {
+		return nil
+	}
+	if err := tx.Create(&models.PasswordHistory{
+		UserID:       user.ID,
+		PasswordHash: user.PasswordHash,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to save password history: %v", err)
+	}
+
+	// Keep only the entries the policy looks at
+	if err := tx.Exec(`DELETE FROM password_history WHERE user_id = ? AND id NOT IN (
+		SELECT id FROM (
+			SELECT id FROM password_history WHERE user_id = ? ORDER BY created_at DESC, id DESC LIMIT ?
+		) AS kept
+	)`, user.ID, user.ID, s.config.HistorySize-1).Error; err != nil {
+		return fmt.Errorf("failed to prune password history: %v", err)
+	}
+	return nil
+}
+
+func (s *PasswordService) getUser(userID uint) (*models.AdminUser, error) {
+	var user models.AdminUser
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+	return &user, nil
+}
@@ -0,0 +1,486 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/auth"
+	"contact-service/pkg/logger"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PermissionConfig configures permission resolution
+type PermissionConfig struct {
+	CacheTTL time.Duration // How long roles and user overrides are cached
+}
+
+// LoadPermissionConfig reads the permission configuration from the environment
+func LoadPermissionConfig() PermissionConfig {
+	config := PermissionConfig{
+		CacheTTL: 30 * time.Second,
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("PERMISSION_CACHE_TTL_SECONDS")); err == nil && seconds >= 0 {
+		config.CacheTTL = time.Duration(seconds) * time.Second
+	}
+	return config
+}
+
+// userPermissions is what a user has on top of nothing: their role and their overrides
+type userPermissions struct {
+	role     string
+	granted  []string
+	denied   []string
+	loadedAt time.Time
+}
+
+// permissionCache caches role permissions and user overrides. It is shared by every
+// PermissionService so that a change made through the API is seen at once by the middleware
+// checking permissions; other instances see it once their entries expire.
+var permissionCache struct {
+	sync.RWMutex
+	roles         map[string][]string
+	rolesLoadedAt time.Time
+	users         map[uint]*userPermissions
+}
+
+// PermissionService keeps roles, their permissions and per-user overrides, and resolves whether a
+// user has a permission
+type PermissionService struct {
+	db     *gorm.DB
+	config PermissionConfig
+}
+
+// NewPermissionService creates a new permission service
+func NewPermissionService(db *gorm.DB, config PermissionConfig) *PermissionService {
+	return &PermissionService{
+		db:     db,
+		config: config,
+	}
+}
+
+// HasPermission reports whether a user's role and overrides grant a permission. Inactive and
+// unknown users have none. When permissions cannot be loaded the last known ones are used, and
+// without any the permission is refused.
+func (s *PermissionService) HasPermission(userID uint, permission string) bool {
+	user, err := s.userPermissions(userID)
+	if err != nil {
+		logger.Error("Failed to resolve user permissions", err, map[string]interface{}{
+			"user_id":    userID,
+			"permission": permission,
+		})
+		return false
+	}
+	if user == nil {
+		return false
+	}
+
+	roles, err := s.rolePermissions()
+	if err != nil && roles == nil {
+		logger.Error("Failed to resolve role permissions", err, map[string]interface{}{
+			"role": user.role,
+		})
+		return false
+	}
+
+	granted := append(append([]string{}, roles[user.role]...), user.granted...)
+	return auth.Authorize(granted, user.denied, permission)
+}
+
+// userPermissions returns a user's role and overrides, or nil for an unknown or inactive user
+func (s *PermissionService) userPermissions(userID uint) (*userPermissions, error) {
+	permissionCache.RLock()
+	cached, ok := permissionCache.users[userID]
+	permissionCache.RUnlock()
+	if ok && time.Since(cached.loadedAt) < s.config.CacheTTL {
+		return cached, nil
+	}
+
+	var user models.AdminUser
+	err := s.db.Select("id", "role").Where("id = ? AND is_active = ?", userID, true).First(&user).Error
+	loaded := &userPermissions{loadedAt: time.Now()}
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		loaded = nil
+	case err != nil:
+		if ok {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	default:
+		loaded.role = user.Role
+		var overrides []models.UserPermissionOverride
+		if err := s.db.Where("user_id = ?", userID).Find(&overrides).Error; err != nil {
+			if ok {
+				return cached, nil
+			}
+			return nil, fmt.Errorf("failed to get permission overrides: %v", err)
+		}
+		for _, o := range overrides {
+			if o.Effect == models.PermissionEffectDeny {
+				loaded.denied = append(loaded.denied, o.Permission)
+			} else {
+				loaded.granted = append(loaded.granted, o.Permission)
+			}
+		}
+	}
+
+	if loaded != nil {
+		permissionCache.Lock()
+		if permissionCache.users == nil {
+			permissionCache.users = make(map[uint]*userPermissions)
+		}
+		permissionCache.users[userID] = loaded
+		permissionCache.Unlock()
+	}
+	return loaded, nil
+}
+
+// rolePermissions returns the permissions of every role. If they cannot be loaded the last known
+// ones are returned along with the error.
+func (s *PermissionService) rolePermissions() (map[string][]string, error) {
+	permissionCache.RLock()
+	roles := permissionCache.roles
+	fresh := roles != nil && time.Since(permissionCache.rolesLoadedAt) < s.config.CacheTTL
+	permissionCache.RUnlock()
+	if fresh {
+		return roles, nil
+	}
+
+	var all []models.Role
+	if err := s.db.Preload("Permissions").Find(&all).Error; err != nil {
+		return roles, fmt.Errorf("failed to get roles: %v", err)
+	}
+	loaded := make(map[string][]string, len(all))
+	for i := range all {
+		loaded[all[i].Name] = all[i].PermissionNames()
+	}
+
+	permissionCache.Lock()
+	permissionCache.roles = loaded
+	permissionCache.rolesLoadedAt = time.Now()
+	permissionCache.Unlock()
+	return loaded, nil
+}
+
+// invalidatePermissions drops every cached role and user, so that the next check reloads them
+func invalidatePermissions() {
+	permissionCache.Lock()
+	permissionCache.roles = nil
+	permissionCache.users = nil
+	permissionCache.Unlock()
+}
+
+// ListRoles returns every role with its permissions and how many users have it
+func (s *PermissionService) ListRoles() ([]models.RoleResponse, error) {
+	var roles []models.Role
+	if err := s.db.Preload("Permissions").Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to get roles: %v", err)
+	}
+
+	var counts []struct {
+		Role  string
+		Users int64
+	}
+	if err := s.db.Model(&models.AdminUser{}).
+		Select("role, COUNT(*) AS users").
+		Group("role").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count users by role: %v", err)
+	}
+	users := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		users[c.Role] = c.Users
+	}
+
+	responses := make([]models.RoleResponse, len(roles))
+	for i := range roles {
+		responses[i] = *roleResponse(&roles[i], users[roles[i].Name])
+	}
+	return responses, nil
+}
+
+// GetRole returns a role with its permissions and how many users have it
+func (s *PermissionService) GetRole(name string) (*models.RoleResponse, error) {
+	role, err := s.getRole(s.db, name)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.countRoleUsers(name)
+	if err != nil {
+		return nil, err
+	}
+	return roleResponse(role, users), nil
+}
+
+// CreateRole creates a custom role
+func (s *PermissionService) CreateRole(req *models.RoleRequest) (*models.RoleResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if !models.ValidRoleName(name) {
+		return nil, fmt.Errorf("invalid role name: use 2-50 lowercase letters, digits and underscores, starting with a letter")
+	}
+	permissions, err := models.NormalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{Name: name, Description: req.Description}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.Role{}).Where("name = ?", name).Count(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check role name: %v", err)
+		}
+		if existing > 0 {
+			return fmt.Errorf("role %s already exists", name)
+		}
+		if err := tx.Create(role).Error; err != nil {
+			return fmt.Errorf("failed to create role: %v", err)
+		}
+		return s.replacePermissions(tx, role, permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invalidatePermissions()
+	logger.Info("Role created", map[string]interface{}{
+		"role":        name,
+		"permissions": permissions,
+	})
+	return roleResponse(role, 0), nil
+}
+
+// UpdateRole changes a role's description and, when given, replaces its permissions
+func (s *PermissionService) UpdateRole(name string, req *models.UpdateRoleRequest) (*models.RoleResponse, error) {
+	var role *models.Role
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		role, err = s.getRole(tx, name)
+		if err != nil {
+			return err
+		}
+		if role.IsSystem {
+			return fmt.Errorf("cannot change the system role %s", name)
+		}
+
+		if req.Description != nil {
+			if err := tx.Model(role).Update("description", req.Description).Error; err != nil {
+				return fmt.Errorf("failed to update role: %v", err)
+			}
+			role.Description = req.Description
+		}
+		if req.Permissions != nil {
+			permissions, err := models.NormalizePermissions(req.Permissions)
+			if err != nil {
+				return err
+			}
+			if err := s.replacePermissions(tx, role, permissions); err != nil {
+				return err
+			}
+			// The role's permissions changed even if no column of the role did
+			if err := tx.Model(role).Update("updated_at", time.Now()).Error; err != nil {
+				return fmt.Errorf("failed to update role: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invalidatePermissions()
+	users, err := s.countRoleUsers(name)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Role updated", map[string]interface{}{
+		"role":        name,
+		"permissions": role.PermissionNames(),
+	})
+	return roleResponse(role, users), nil
+}
+
+// DeleteRole deletes a custom role no user has
+func (s *PermissionService) DeleteRole(name string) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		role, err := s.getRole(tx, name)
+		if err != nil {
+			return err
+		}
+		if role.IsSystem {
+			return fmt.Errorf("cannot delete the system role %s", name)
+		}
+
+		var users int64
+		if err := tx.Model(&models.AdminUser{}).Where("role = ?", name).Count(&users).Error; err != nil {
+			return fmt.Errorf("failed to count role users: %v", err)
+		}
+		if users > 0 {
+			return fmt.Errorf("cannot delete role %s: %d users have it", name, users)
+		}
+
+		if err := tx.Where("role = ?", name).Delete(&models.TwoFactorPolicy{}).Error; err != nil {
+			return fmt.Errorf("failed to delete two-factor policy: %v", err)
+		}
+		if err := tx.Delete(role).Error; err != nil {
+			return fmt.Errorf("failed to delete role: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	invalidatePermissions()
+	logger.Info("Role deleted", map[string]interface{}{
+		"role": name,
+	})
+	return nil
+}
+
+// GetUserPermissions describes a user's role permissions and overrides
+func (s *PermissionService) GetUserPermissions(userID uint) (*models.UserPermissionsResponse, error) {
+	var user models.AdminUser
+	if err := s.db.Select("id", "role").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+
+	response := &models.UserPermissionsResponse{
+		UserID:          user.ID,
+		Role:            user.Role,
+		RolePermissions: []string{},
+	}
+	role, err := s.getRole(s.db, user.Role)
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return nil, err
+	}
+	if role != nil {
+		response.RolePermissions = role.PermissionNames()
+	}
+	if err := s.db.Where("user_id = ?", userID).Order("permission ASC").Find(&response.Overrides).Error; err != nil {
+		return nil, fmt.Errorf("failed to get permission overrides: %v", err)
+	}
+	return response, nil
+}
+
+// SetUserOverride grants a permission to a user or denies it from them, replacing any override of
+// the same permission
+func (s *PermissionService) SetUserOverride(userID uint, req *models.UserPermissionOverrideRequest, grantedBy uint) (*models.UserPermissionOverride, error) {
+	if !models.ValidPermission(req.Permission) {
+		return nil, fmt.Errorf("invalid permission: %q", req.Permission)
+	}
+	if req.Effect != models.PermissionEffectGrant && req.Effect != models.PermissionEffectDeny {
+		return nil, fmt.Errorf("invalid effect: %q", req.Effect)
+	}
+	var user models.AdminUser
+	if err := s.db.Select("id").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+
+	override := &models.UserPermissionOverride{
+		UserID:     userID,
+		Permission: req.Permission,
+		Effect:     req.Effect,
+		GrantedBy:  &grantedBy,
+	}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "permission"}},
+		DoUpdates: clause.AssignmentColumns([]string{"effect", "granted_by", "updated_at"}),
+	}).Create(override).Error; err != nil {
+		return nil, fmt.Errorf("failed to save permission override: %v", err)
+	}
+	if err := s.db.Where("user_id = ? AND permission = ?", userID, req.Permission).First(override).Error; err != nil {
+		return nil, fmt.Errorf("failed to get permission override: %v", err)
+	}
+
+	invalidatePermissions()
+	return override, nil
+}
+
+// DeleteUserOverride removes a user's override of a permission, leaving it to their role
+func (s *PermissionService) DeleteUserOverride(userID uint, permission string) error {
+	result := s.db.Where("user_id = ? AND permission = ?", userID, permission).Delete(&models.UserPermissionOverride{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete permission override: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("permission override not found")
+	}
+
+	invalidatePermissions()
+	return nil
+}
+
+// replacePermissions makes permissions the role's permissions
+func (s *PermissionService) replacePermissions(tx *gorm.DB, role *models.Role, permissions []string) error {
+	if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+		return fmt.Errorf("failed to replace role permissions: %v", err)
+	}
+	role.Permissions = make([]models.RolePermission, len(permissions))
+	for i, p := range permissions {
+		role.Permissions[i] = models.RolePermission{RoleID: role.ID, Permission: p}
+	}
+	if len(role.Permissions) == 0 {
+		return nil
+	}
+	if err := tx.Create(&role.Permissions).Error; err != nil {
+		return fmt.Errorf("failed to save role permissions: %v", err)
+	}
+	return nil
+}
+
+func (s *PermissionService) getRole(db *gorm.DB, name string) (*models.Role, error) {
+	var role models.Role
+	if err := db.Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("role not found")
+		}
+		return nil, fmt.Errorf("failed to get role: %v", err)
+	}
+	return &role, nil
+}
+
+func (s *PermissionService) countRoleUsers(name string) (int64, error) {
+	var users int64
+	if err := s.db.Model(&models.AdminUser{}).Where("role = ?", name).Count(&users).Error; err != nil {
+		return 0, fmt.Errorf("failed to count role users: %v", err)
+	}
+	return users, nil
+}
+
+func roleResponse(role *models.Role, users int64) *models.RoleResponse {
+	return &models.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		IsSystem:    role.IsSystem,
+		Permissions: role.PermissionNames(),
+		UserCount:   users,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+// requireRole checks that a role exists, for assigning it to users or policies
+func requireRole(db *gorm.DB, name string) error {
+	var roles int64
+	if err := db.Model(&models.Role{}).Where("name = ?", name).Count(&roles).Error; err != nil {
+		return fmt.Errorf("failed to check role: %v", err)
+	}
+	if roles == 0 {
+		return fmt.Errorf("invalid role: %s", name)
+	}
+	return nil
+}
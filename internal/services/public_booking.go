@@ -0,0 +1,773 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/auth"
+	"contact-service/pkg/ical"
+	"contact-service/pkg/logger"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Longest range the public slot listing covers in one request
+const maxPublicSlotDays = 31
+
+// bookingSlug is the allowed form of booking page slugs
+var bookingSlug = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// BookingConfig configures public self-service booking
+type BookingConfig struct {
+	HoldDuration  time.Duration // How long a held slot stays reserved
+	MaxHoldsPerIP int           // Unexpired holds one client IP may have on a page; 0 for no limit
+	LinkBaseURL   string        // Base of the reschedule/cancel links sent to visitors
+	LinkSecret    []byte        // Signs booking links
+}
+
+// LoadBookingConfig reads the booking configuration from the environment.
+// Links point at BOOKING_LINK_BASE_URL, falling back to APP_URL; they are signed with BOOKING_LINK_SECRET,
+// or a key derived for booking links when it is not set.
+func LoadBookingConfig() BookingConfig {
+	config := BookingConfig{
+		HoldDuration:  10 * time.Minute,
+		MaxHoldsPerIP: 3,
+		LinkBaseURL:   os.Getenv("BOOKING_LINK_BASE_URL"),
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("BOOKING_HOLD_MINUTES")); err == nil && minutes > 0 {
+		config.HoldDuration = time.Duration(minutes) * time.Minute
+	}
+	if holds, err := strconv.Atoi(os.Getenv("BOOKING_MAX_HOLDS_PER_IP")); err == nil && holds >= 0 {
+		config.MaxHoldsPerIP = holds
+	}
+	if config.LinkBaseURL == "" {
+		config.LinkBaseURL = strings.TrimRight(os.Getenv("APP_URL"), "/") + "/api/v1/public/bookings"
+	}
+	config.LinkBaseURL = strings.TrimRight(config.LinkBaseURL, "/")
+
+	config.LinkSecret = []byte(os.Getenv("BOOKING_LINK_SECRET"))
+	if len(config.LinkSecret) == 0 {
+		config.LinkSecret = auth.DeriveKey("booking-links")
+	}
+	return config
+}
+
+// BookingService manages booking pages and the public booking flow: listing open slots across a
+// page's host pool, holding a slot, booking it and self-service changes through signed links
+type BookingService struct {
+	db         *gorm.DB
+	scheduling *SchedulingService
+	config     BookingConfig
+}
+
+// NewBookingService creates a new booking service
+func NewBookingService(db *gorm.DB, scheduling *SchedulingService, config BookingConfig) *BookingService {
+	return &BookingService{db: db, scheduling: scheduling, config: config}
+}
+
+// hostSlot is an open slot with one host
+type hostSlot struct {
+	userID uint
+	window models.TimeWindow
+}
+
+// ListBookingPages lists booking pages
+func (s *BookingService) ListBookingPages() ([]models.BookingPage, error) {
+	var pages []models.BookingPage
+	if err := s.db.Where("deleted_at IS NULL").Order("name ASC").Find(&pages).Error; err != nil {
+		return nil, fmt.Errorf("failed to list booking pages: %v", err)
+	}
+	return pages, nil
+}
+
+// GetBookingPage gets a booking page by ID
+func (s *BookingService) GetBookingPage(id uint) (*models.BookingPage, error) {
+	var page models.BookingPage
+	if err := s.db.Where("deleted_at IS NULL").First(&page, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("booking page not found")
+		}
+		return nil, fmt.Errorf("failed to get booking page: %v", err)
+	}
+	return &page, nil
+}
+
+// CreateBookingPage creates a booking page
+func (s *BookingService) CreateBookingPage(request *models.BookingPageRequest, createdByUserID uint) (*models.BookingPage, error) {
+	page := &models.BookingPage{
+		AssignmentMode:   models.BookingRoundRobin,
+		MinNoticeMinutes: 240,
+		MaxDaysAhead:     30,
+		Timezone:         defaultScheduleTimezone,
+		AppointmentType:  models.AppointmentConsultation,
+		MeetingType:      models.MeetingVideoCall,
+		IsActive:         true,
+		CreatedBy:        &createdByUserID,
+	}
+	if err := s.applyBookingPageRequest(page, request, createdByUserID); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(page).Error; err != nil {
+		return nil, fmt.Errorf("failed to create booking page: %v", err)
+	}
+
+	logger.Info("Booking page created", map[string]interface{}{
+		"booking_page_id": page.ID,
+		"slug":            page.Slug,
+		"hosts":           len(request.HostUserIDs),
+		"created_by":      createdByUserID,
+	})
+	return page, nil
+}
+
+// UpdateBookingPage replaces the settings of a booking page
+func (s *BookingService) UpdateBookingPage(id uint, request *models.BookingPageRequest, updatedByUserID uint) (*models.BookingPage, error) {
+	page, err := s.GetBookingPage(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyBookingPageRequest(page, request, updatedByUserID); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(page).Error; err != nil {
+		return nil, fmt.Errorf("failed to update booking page: %v", err)
+	}
+	return page, nil
+}
+
+func (s *BookingService) applyBookingPageRequest(page *models.BookingPage, request *models.BookingPageRequest, userID uint) error {
+	if !bookingSlug.MatchString(request.Slug) {
+		return fmt.Errorf("invalid slug: use lowercase letters, digits and hyphens")
+	}
+	var taken int64
+	if err := s.db.Model(&models.BookingPage{}).Where("slug = ? AND id <> ?", request.Slug, page.ID).Count(&taken).Error; err != nil {
+		return fmt.Errorf("failed to check slug: %v", err)
+	}
+	if taken > 0 {
+		return fmt.Errorf("booking page slug %q already exists", request.Slug)
+	}
+
+	hosts := models.JSONArray{}
+	for _, id := range request.HostUserIDs {
+		hosts = append(hosts, id)
+	}
+	page.Slug = request.Slug
+	page.Name = request.Name
+	page.Description = request.Description
+	page.HostUserIDs = hosts
+	page.DurationMinutes = request.DurationMinutes
+	page.Location = request.Location
+	page.MeetingLink = request.MeetingLink
+	page.ContactTypeID = request.ContactTypeID
+	page.ContactSourceID = request.ContactSourceID
+	page.UpdatedBy = &userID
+
+	if request.AssignmentMode != nil {
+		if *request.AssignmentMode != models.BookingRoundRobin && *request.AssignmentMode != models.BookingFirstAvailable {
+			return fmt.Errorf("invalid assignment_mode %q", *request.AssignmentMode)
+		}
+		page.AssignmentMode = *request.AssignmentMode
+	}
+	if request.Timezone != nil {
+		if _, err := time.LoadLocation(*request.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone: %v", err)
+		}
+		page.Timezone = *request.Timezone
+	}
+	if request.BufferMinutes != nil {
+		page.BufferMinutes = *request.BufferMinutes
+	}
+	if request.MinNoticeMinutes != nil {
+		page.MinNoticeMinutes = *request.MinNoticeMinutes
+	}
+	if request.MaxDaysAhead != nil {
+		page.MaxDaysAhead = *request.MaxDaysAhead
+	}
+	if request.AppointmentType != nil {
+		page.AppointmentType = *request.AppointmentType
+	}
+	if request.MeetingType != nil {
+		page.MeetingType = *request.MeetingType
+	}
+	if request.IsActive != nil {
+		page.IsActive = *request.IsActive
+	}
+	return nil
+}
+
+// GetPublicBookingPage gets the visitor-facing details of an active booking page
+func (s *BookingService) GetPublicBookingPage(slug string) (*models.PublicBookingPage, error) {
+	page, err := s.activePage(s.db, slug)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PublicBookingPage{
+		Slug:            page.Slug,
+		Name:            page.Name,
+		Description:     page.Description,
+		DurationMinutes: page.DurationMinutes,
+		Timezone:        page.Timezone,
+		AppointmentType: page.AppointmentType,
+		MeetingType:     page.MeetingType,
+		MaxDaysAhead:    page.MaxDaysAhead,
+	}, nil
+}
+
+// ListPublicSlots lists the times between two instants at which at least one host of the page is free
+func (s *BookingService) ListPublicSlots(slug string, from, to time.Time, timezone string) ([]models.PublicSlot, error) {
+	page, err := s.activePage(s.db, slug)
+	if err != nil {
+		return nil, err
+	}
+	if timezone == "" {
+		timezone = page.Timezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %v", err)
+	}
+	if to.Sub(from) > maxPublicSlotDays*24*time.Hour {
+		return nil, fmt.Errorf("invalid range: at most %d days can be listed at once", maxPublicSlotDays)
+	}
+
+	open, err := s.openSlots(s.db, page, from, to, "")
+	if err != nil {
+		return nil, err
+	}
+
+	slots := []models.PublicSlot{}
+	for i, slot := range open {
+		if i > 0 && slot.window.Start.Equal(open[i-1].window.Start) {
+			continue
+		}
+		slots = append(slots, models.PublicSlot{StartTime: slot.window.Start.In(loc), EndTime: slot.window.End.In(loc)})
+	}
+	return slots, nil
+}
+
+// HoldSlot reserves an open slot for the page's hold duration so a visitor can fill in the booking
+// form. A client IP may only hold a few slots on a page at a time, so holds cannot block a page.
+func (s *BookingService) HoldSlot(slug string, start time.Time, clientIP string) (*models.BookingHoldResponse, error) {
+	var hold *models.BookingHold
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		page, err := s.lockPage(tx, "slug = ?", slug)
+		if err != nil {
+			return err
+		}
+		if s.config.MaxHoldsPerIP > 0 {
+			var held int64
+			if err := tx.Model(&models.BookingHold{}).
+				Where("booking_page_id = ? AND client_ip = ? AND expires_at > ?", page.ID, clientIP, time.Now()).
+				Count(&held).Error; err != nil {
+				return fmt.Errorf("failed to count slot holds: %v", err)
+			}
+			if held >= int64(s.config.MaxHoldsPerIP) {
+				return fmt.Errorf("too many held slots; book one or wait for them to expire")
+			}
+		}
+		hold, err = s.holdSlot(tx, page, start, clientIP)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BookingHoldResponse{
+		HoldToken: hold.Token,
+		StartTime: hold.StartTime,
+		EndTime:   hold.EndTime,
+		ExpiresAt: hold.ExpiresAt,
+	}, nil
+}
+
+// Book books a slot for a visitor: the contact is matched by email or created, and the appointment is
+// assigned to the host holding the slot. Without a hold token the preferred time is held on the spot.
+// The contact is created in the booking's transaction, so a booking that fails leaves no contact behind.
+func (s *BookingService) Book(slug string, request *models.PublicAppointmentRequest) (*models.PublicBookingResponse, error) {
+	page, err := s.activePage(s.db, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	timezone := page.Timezone
+	if request.Timezone != nil && *request.Timezone != "" {
+		timezone = *request.Timezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %v", err)
+	}
+	date, err := time.ParseInLocation("2006-01-02", request.PreferredDate, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid preferred_date: expected YYYY-MM-DD")
+	}
+	hour, minute, err := models.ParseClock(request.PreferredTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid preferred_time: %v", err)
+	}
+	start := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc)
+
+	var contact *models.Contact
+	appointment := &models.Appointment{
+		Title:           request.Title,
+		Description:     request.Description,
+		AppointmentType: page.AppointmentType,
+		Status:          models.AppointmentConfirmed,
+		Priority:        models.PriorityMedium,
+		Timezone:        timezone,
+		MeetingType:     page.MeetingType,
+		Location:        page.Location,
+		MeetingLink:     page.MeetingLink,
+		PhoneNumber:     request.Phone,
+		BookingSource:   models.BookingSourcePublic,
+		BookingPageID:   &page.ID,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		page, err := s.lockPage(tx, "id = ?", page.ID)
+		if err != nil {
+			return err
+		}
+
+		var hold *models.BookingHold
+		if request.HoldToken != nil && *request.HoldToken != "" {
+			if hold, err = s.claimHold(tx, page, *request.HoldToken); err != nil {
+				return err
+			}
+			if !hold.StartTime.Equal(start) {
+				return fmt.Errorf("invalid booking: preferred time does not match the held slot")
+			}
+		} else if hold, err = s.holdSlot(tx, page, start, ""); err != nil {
+			return err
+		}
+
+		if contact, err = s.findOrCreateContact(tx, page, request); err != nil {
+			return err
+		}
+
+		appointment.ContactID = contact.ID
+		appointment.AssignedTo = hold.UserID
+		appointment.ScheduledDate = hold.StartTime
+		appointment.ScheduledTime = hold.StartTime.In(loc).Format("15:04:05")
+		appointment.DurationMinutes = int(hold.EndTime.Sub(hold.StartTime).Minutes())
+		if err := tx.Create(appointment).Error; err != nil {
+			return fmt.Errorf("failed to create appointment: %v", err)
+		}
+		return s.releaseHold(tx, page, hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduling.scheduleReminders(appointment, nil); err != nil {
+		logger.Error("Failed to schedule reminders", err, map[string]interface{}{
+			"appointment_id": appointment.ID,
+		})
+	}
+	s.scheduling.sendCalendarInvite(appointment.ID, ical.MethodRequest, false)
+	s.scheduling.updateContactNextFollowup(appointment.ContactID)
+
+	logger.LogBusinessEvent("public_appointment_booked", "appointment", appointment.ID, map[string]interface{}{
+		"booking_page_id": page.ID,
+		"contact_id":      contact.ID,
+		"assigned_to":     appointment.AssignedTo,
+		"scheduled_date":  appointment.ScheduledDate,
+	})
+
+	return s.bookingResponse(appointment), nil
+}
+
+// GetPublicBooking gets a booking through its signed link
+func (s *BookingService) GetPublicBooking(appointmentID uint, signature string) (*models.PublicBookingResponse, error) {
+	appointment, err := s.signedBooking(appointmentID, signature)
+	if err != nil {
+		return nil, err
+	}
+	return s.bookingResponse(appointment), nil
+}
+
+// CancelPublicBooking cancels a booking through its signed link
+func (s *BookingService) CancelPublicBooking(appointmentID uint, signature, reason string) error {
+	appointment, err := s.signedBooking(appointmentID, signature)
+	if err != nil {
+		return err
+	}
+	if reason == "" {
+		reason = "Cancelled by visitor"
+	}
+	return s.scheduling.cancelAppointment(appointment, reason, nil)
+}
+
+// ReschedulePublicBooking moves a booking to a slot the visitor has held on the same booking page.
+// The booking may move to another host of the pool when its host is not free at the new time.
+func (s *BookingService) ReschedulePublicBooking(appointmentID uint, signature string, request *models.PublicRescheduleRequest) (*models.PublicBookingResponse, error) {
+	appointment, err := s.signedBooking(appointmentID, signature)
+	if err != nil {
+		return nil, err
+	}
+	// Public bookings are confirmed as soon as they are made, so unlike internal reschedules a
+	// confirmed booking can move
+	if appointment.Status != models.AppointmentConfirmed && !s.scheduling.canRescheduleAppointment(appointment) {
+		return nil, fmt.Errorf("appointment cannot be rescheduled (status: %s)", appointment.Status)
+	}
+
+	loc, err := time.LoadLocation(appointment.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		page, err := s.lockPage(tx, "id = ?", *appointment.BookingPageID)
+		if err != nil {
+			return err
+		}
+		hold, err := s.claimHold(tx, page, request.HoldToken)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"scheduled_date":   hold.StartTime,
+			"scheduled_time":   hold.StartTime.In(loc).Format("15:04:05"),
+			"duration_minutes": int(hold.EndTime.Sub(hold.StartTime).Minutes()),
+			"assigned_to":      hold.UserID,
+			"status":           models.AppointmentRescheduled,
+			"updated_at":       time.Now(),
+		}
+		if appointment.OriginalScheduledDate == nil {
+			updates["original_scheduled_date"] = appointment.ScheduledDate
+			updates["original_scheduled_time"] = appointment.ScheduledTime
+		}
+		if request.Reason != nil {
+			updates["reschedule_reason"] = *request.Reason
+		}
+		if err := tx.Model(appointment).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to reschedule appointment: %v", err)
+		}
+		return s.releaseHold(tx, page, hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.First(appointment, appointmentID).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload appointment: %v", err)
+	}
+	if err := s.scheduling.rescheduleReminders(appointment); err != nil {
+		logger.Error("Failed to reschedule reminders", err, map[string]interface{}{
+			"appointment_id": appointmentID,
+		})
+	}
+	s.scheduling.sendCalendarInvite(appointment.ID, ical.MethodRequest, true)
+	s.scheduling.updateContactNextFollowup(appointment.ContactID)
+
+	logger.LogBusinessEvent("public_appointment_rescheduled", "appointment", appointment.ID, map[string]interface{}{
+		"booking_page_id": *appointment.BookingPageID,
+		"assigned_to":     appointment.AssignedTo,
+		"scheduled_date":  appointment.ScheduledDate,
+	})
+
+	return s.bookingResponse(appointment), nil
+}
+
+// activePage gets an active booking page by slug
+func (s *BookingService) activePage(db *gorm.DB, slug string) (*models.BookingPage, error) {
+	var page models.BookingPage
+	if err := db.Where("slug = ? AND is_active = ? AND deleted_at IS NULL", slug, true).First(&page).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("booking page not found")
+		}
+		return nil, fmt.Errorf("failed to get booking page: %v", err)
+	}
+	return &page, nil
+}
+
+// lockPage loads a booking page FOR UPDATE, serializing holds and bookings on the page, then locks
+// the user rows of its hosts in id order. A host can be on several pages, so the host locks are what
+// keep two pages from holding or booking the same host for the same time.
+func (s *BookingService) lockPage(tx *gorm.DB, query string, arg interface{}) (*models.BookingPage, error) {
+	var page models.BookingPage
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(query, arg).Where("is_active = ? AND deleted_at IS NULL", true).
+		First(&page).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("booking page not found")
+		}
+		return nil, fmt.Errorf("failed to get booking page: %v", err)
+	}
+
+	if hosts := page.HostIDs(); len(hosts) > 0 {
+		var locked []uint
+		if err := tx.Model(&models.AdminUser{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", hosts).Order("id ASC").
+			Pluck("id", &locked).Error; err != nil {
+			return nil, fmt.Errorf("failed to lock booking page hosts: %v", err)
+		}
+	}
+	return &page, nil
+}
+
+// openSlots returns every host's open slots of the page's length between two instants, within the
+// page's notice and booking window, sorted by start time and then host order. Holds other than
+// excludeToken take their host's time.
+func (s *BookingService) openSlots(db *gorm.DB, page *models.BookingPage, from, to time.Time, excludeToken string) ([]hostSlot, error) {
+	now := time.Now()
+	if earliest := now.Add(time.Duration(page.MinNoticeMinutes) * time.Minute); from.Before(earliest) {
+		from = earliest
+	}
+	if latest := now.AddDate(0, 0, page.MaxDaysAhead); to.After(latest) {
+		to = latest
+	}
+	hosts := page.HostIDs()
+	if !to.After(from) || len(hosts) == 0 {
+		return nil, nil
+	}
+
+	duration := time.Duration(page.DurationMinutes) * time.Minute
+	buffer := time.Duration(page.BufferMinutes) * time.Minute
+	var holds []models.BookingHold
+	if err := db.Where("user_id IN ? AND expires_at > ? AND token <> ?", hosts, now, excludeToken).
+		Where("start_time < ? AND end_time > ?", to.Add(buffer), from.Add(-buffer)).
+		Find(&holds).Error; err != nil {
+		return nil, fmt.Errorf("failed to get booking holds: %v", err)
+	}
+
+	scheduling := &SchedulingService{db: db}
+	businessHoursOnly := true
+	var slots []hostSlot
+	order := make(map[uint]int)
+	for i, userID := range hosts {
+		order[userID] = i
+		available, err := scheduling.findUserAvailableSlots(userID, from, to, page.DurationMinutes, page.BufferMinutes, "UTC", &businessHoursOnly)
+		if err != nil {
+			return nil, err
+		}
+
+	slot:
+		for _, slot := range available {
+			window := models.TimeWindow{Start: slot.StartTime, End: slot.StartTime.Add(duration)}
+			for _, hold := range holds {
+				if hold.UserID == userID && window.Start.Before(hold.EndTime.Add(buffer)) && window.End.After(hold.StartTime.Add(-buffer)) {
+					continue slot
+				}
+			}
+			slots = append(slots, hostSlot{userID: userID, window: window})
+		}
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		if !slots[i].window.Start.Equal(slots[j].window.Start) {
+			return slots[i].window.Start.Before(slots[j].window.Start)
+		}
+		return order[slots[i].userID] < order[slots[j].userID]
+	})
+	return slots, nil
+}
+
+// freeHosts returns the hosts free for a whole slot starting at start
+func (s *BookingService) freeHosts(db *gorm.DB, page *models.BookingPage, start time.Time, excludeToken string) ([]uint, error) {
+	end := start.Add(time.Duration(page.DurationMinutes) * time.Minute)
+	open, err := s.openSlots(db, page, start, end, excludeToken)
+	if err != nil {
+		return nil, err
+	}
+	var hosts []uint
+	for _, slot := range open {
+		if slot.window.Start.Equal(start) {
+			hosts = append(hosts, slot.userID)
+		}
+	}
+	return hosts, nil
+}
+
+// pickHost chooses among the free hosts according to the page's assignment mode
+func (s *BookingService) pickHost(page *models.BookingPage, free []uint) uint {
+	hosts := page.HostIDs()
+	isFree := make(map[uint]bool)
+	for _, id := range free {
+		isFree[id] = true
+	}
+
+	first := 0
+	if page.AssignmentMode == models.BookingRoundRobin && page.LastAssignedUserID != nil {
+		for i, id := range hosts {
+			if id == *page.LastAssignedUserID {
+				first = i + 1
+				break
+			}
+		}
+	}
+	for i := range hosts {
+		if id := hosts[(first+i)%len(hosts)]; isFree[id] {
+			return id
+		}
+	}
+	return free[0]
+}
+
+// holdSlot holds a slot starting at start with a free host of the page, which the caller has locked
+func (s *BookingService) holdSlot(tx *gorm.DB, page *models.BookingPage, start time.Time, clientIP string) (*models.BookingHold, error) {
+	now := time.Now()
+	if err := tx.Where("expires_at < ?", now.Add(-time.Hour)).Delete(&models.BookingHold{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to clear expired holds: %v", err)
+	}
+
+	free, err := s.freeHosts(tx, page, start, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(free) == 0 {
+		return nil, fmt.Errorf("slot is no longer available")
+	}
+
+	token, err := newBookingToken()
+	if err != nil {
+		return nil, err
+	}
+	hold := &models.BookingHold{
+		BookingPageID: page.ID,
+		UserID:        s.pickHost(page, free),
+		StartTime:     start,
+		EndTime:       start.Add(time.Duration(page.DurationMinutes) * time.Minute),
+		Token:         token,
+		ExpiresAt:     now.Add(s.config.HoldDuration),
+	}
+	if clientIP != "" {
+		hold.ClientIP = &clientIP
+	}
+	if err := tx.Create(hold).Error; err != nil {
+		return nil, fmt.Errorf("failed to hold slot: %v", err)
+	}
+	return hold, nil
+}
+
+// claimHold loads an unexpired hold on the page and checks its host is still free,
+// since internal appointments can be created for the host while the slot is held
+func (s *BookingService) claimHold(tx *gorm.DB, page *models.BookingPage, token string) (*models.BookingHold, error) {
+	var hold models.BookingHold
+	if err := tx.Where("token = ? AND booking_page_id = ? AND expires_at > ?", token, page.ID, time.Now()).
+		First(&hold).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("slot hold has expired; please choose a time again")
+		}
+		return nil, fmt.Errorf("failed to get slot hold: %v", err)
+	}
+
+	free, err := s.freeHosts(tx, page, hold.StartTime, hold.Token)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range free {
+		if id == hold.UserID {
+			return &hold, nil
+		}
+	}
+	return nil, fmt.Errorf("slot is no longer available")
+}
+
+// releaseHold removes a hold that became an appointment and advances the round-robin cursor
+func (s *BookingService) releaseHold(tx *gorm.DB, page *models.BookingPage, hold *models.BookingHold) error {
+	if err := tx.Delete(hold).Error; err != nil {
+		return fmt.Errorf("failed to release slot hold: %v", err)
+	}
+	if err := tx.Model(page).Update("last_assigned_user_id", hold.UserID).Error; err != nil {
+		return fmt.Errorf("failed to update booking page: %v", err)
+	}
+	return nil
+}
+
+// findOrCreateContact matches the visitor to a contact by email, creating one when there is none
+func (s *BookingService) findOrCreateContact(tx *gorm.DB, page *models.BookingPage, request *models.PublicAppointmentRequest) (*models.Contact, error) {
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	var contact models.Contact
+	err := tx.Where("LOWER(email) = ? AND deleted_at IS NULL", email).Order("id ASC").First(&contact).Error
+	if err == nil {
+		return &contact, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up contact: %v", err)
+	}
+
+	contactTypeID, contactSourceID := uint(1), uint(1) // General Inquiry, Website Contact Form
+	if page.ContactTypeID != nil {
+		contactTypeID = *page.ContactTypeID
+	}
+	if page.ContactSourceID != nil {
+		contactSourceID = *page.ContactSourceID
+	}
+	subject := "Booked: " + request.Title
+	contactService := &ContactService{db: tx}
+	return contactService.CreateContactFromChannel(&models.ContactRequest{
+		FirstName:       request.FirstName,
+		LastName:        request.LastName,
+		Email:           email,
+		Phone:           request.Phone,
+		Company:         request.Company,
+		Subject:         &subject,
+		Message:         request.Description,
+		ContactTypeID:   contactTypeID,
+		ContactSourceID: contactSourceID,
+	}, nil, models.ValidationChannelManual)
+}
+
+// signedBooking loads a publicly booked appointment after checking its link signature
+func (s *BookingService) signedBooking(appointmentID uint, signature string) (*models.Appointment, error) {
+	if !hmac.Equal([]byte(signature), []byte(s.signBooking(appointmentID))) {
+		return nil, fmt.Errorf("booking not found")
+	}
+	var appointment models.Appointment
+	if err := s.db.Where("booking_page_id IS NOT NULL AND deleted_at IS NULL").First(&appointment, appointmentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("booking not found")
+		}
+		return nil, fmt.Errorf("failed to get booking: %v", err)
+	}
+	return &appointment, nil
+}
+
+func (s *BookingService) signBooking(appointmentID uint) string {
+	mac := hmac.New(sha256.New, s.config.LinkSecret)
+	mac.Write([]byte("booking:" + strconv.FormatUint(uint64(appointmentID), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:18])
+}
+
+func (s *BookingService) bookingResponse(appointment *models.Appointment) *models.PublicBookingResponse {
+	manage := fmt.Sprintf("%s/%d/%s", s.config.LinkBaseURL, appointment.ID, s.signBooking(appointment.ID))
+	start := appointment.ScheduledDate
+	if loc, err := time.LoadLocation(appointment.Timezone); err == nil {
+		start = start.In(loc)
+	}
+	return &models.PublicBookingResponse{
+		AppointmentID: appointment.ID,
+		Title:         appointment.Title,
+		Status:        appointment.Status,
+		StartTime:     start,
+		EndTime:       start.Add(time.Duration(appointment.DurationMinutes) * time.Minute),
+		Timezone:      appointment.Timezone,
+		MeetingType:   appointment.MeetingType,
+		Location:      appointment.Location,
+		MeetingLink:   appointment.MeetingLink,
+		ManageURL:     manage,
+		RescheduleURL: manage + "/reschedule",
+		CancelURL:     manage + "/cancel",
+	}
+}
+
+func newBookingToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate hold token: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
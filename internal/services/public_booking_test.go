@@ -0,0 +1,250 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"contact-service/internal/models"
+)
+
+// newBookingTestService returns a booking service on an in-memory database with two hosts, users 1
+// and 2, and a page "consult" whose only host is user 1
+func newBookingTestService(t *testing.T) (*BookingService, *gorm.DB) {
+	db := newTestDB(t,
+		&models.AdminUser{},
+		&models.ContactType{},
+		&models.ContactSource{},
+		&models.Contact{},
+		&models.ContactFieldHistory{},
+		&models.ContactValidationRule{},
+		&models.Appointment{},
+		&models.AppointmentReminder{},
+		&models.UserAvailability{},
+		&models.AvailabilityException{},
+		&models.CalendarIntegration{},
+		&models.CalendarBusyBlock{},
+		&models.BookingPage{},
+		&models.BookingHold{},
+	)
+	// Columns of the appointments migration the Appointment model does not map
+	require.NoError(t, db.Exec("ALTER TABLE appointments ADD COLUMN cancelled_at DATETIME").Error)
+	require.NoError(t, db.Exec("ALTER TABLE appointments ADD COLUMN cancel_reason TEXT").Error)
+	require.NoError(t, db.Create(&models.AdminUser{ID: 1, Email: "host1@example.com", Name: "Host One", Role: "sales", IsActive: true}).Error)
+	require.NoError(t, db.Create(&models.AdminUser{ID: 2, Email: "host2@example.com", Name: "Host Two", Role: "sales", IsActive: true}).Error)
+	require.NoError(t, db.Create(&models.ContactType{ID: 1, Name: "General Inquiry", IsActive: true}).Error)
+	require.NoError(t, db.Create(&models.ContactSource{ID: 1, Name: "Website Contact Form", IsActive: true}).Error)
+
+	createBookingTestPage(t, db, "consult", 1)
+
+	service := NewBookingService(db, NewSchedulingService(db), BookingConfig{
+		HoldDuration:  10 * time.Minute,
+		MaxHoldsPerIP: 2,
+		LinkBaseURL:   "https://contacts.example.com/api/v1/public/bookings",
+		LinkSecret:    []byte("test-secret"),
+	})
+	return service, db
+}
+
+func createBookingTestPage(t *testing.T, db *gorm.DB, slug string, hosts ...uint) *models.BookingPage {
+	hostIDs := models.JSONArray{}
+	for _, id := range hosts {
+		hostIDs = append(hostIDs, id)
+	}
+	page := &models.BookingPage{
+		Slug:             slug,
+		Name:             "Consultation",
+		HostUserIDs:      hostIDs,
+		AssignmentMode:   models.BookingRoundRobin,
+		DurationMinutes:  30,
+		MinNoticeMinutes: 0,
+		MaxDaysAhead:     30,
+		Timezone:         defaultScheduleTimezone,
+		AppointmentType:  models.AppointmentConsultation,
+		MeetingType:      models.MeetingVideoCall,
+		IsActive:         true,
+	}
+	require.NoError(t, db.Create(page).Error)
+	return page
+}
+
+// bookingTestSlot returns 10:00 host time on the next weekday at least two days ahead, which falls in
+// the default working hours of hosts without a schedule
+func bookingTestSlot(t *testing.T) time.Time {
+	loc, err := time.LoadLocation(defaultScheduleTimezone)
+	require.NoError(t, err)
+	day := time.Now().In(loc).AddDate(0, 0, 2)
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), 10, 0, 0, 0, loc)
+}
+
+// Client IP of the visitor in booking tests
+const bookingTestIP = "203.0.113.7"
+
+func bookingTestRequest(start time.Time, holdToken string) *models.PublicAppointmentRequest {
+	lastName := "Rao"
+	request := &models.PublicAppointmentRequest{
+		FirstName:     "Asha",
+		LastName:      &lastName,
+		Email:         "Asha.Rao@example.com",
+		Title:         "Product demo",
+		PreferredDate: start.Format("2006-01-02"),
+		PreferredTime: start.Format("15:04"),
+	}
+	if holdToken != "" {
+		request.HoldToken = &holdToken
+	}
+	return request
+}
+
+func TestHoldSlotReservesHost(t *testing.T) {
+	service, _ := newBookingTestService(t)
+	start := bookingTestSlot(t)
+
+	hold, err := service.HoldSlot("consult", start, bookingTestIP)
+	require.NoError(t, err)
+	assert.NotEmpty(t, hold.HoldToken)
+	assert.True(t, hold.StartTime.Equal(start))
+
+	_, err = service.HoldSlot("consult", start, bookingTestIP)
+	assert.EqualError(t, err, "slot is no longer available")
+
+	slots, err := service.ListPublicSlots("consult", start.Add(-time.Hour), start.Add(time.Hour), "")
+	require.NoError(t, err)
+	for _, slot := range slots {
+		assert.False(t, slot.StartTime.Equal(start), "held slot is still listed")
+	}
+}
+
+func TestHoldSlotBlocksHostOnOtherPages(t *testing.T) {
+	service, db := newBookingTestService(t)
+	createBookingTestPage(t, db, "consult-again", 1)
+	start := bookingTestSlot(t)
+
+	_, err := service.HoldSlot("consult", start, bookingTestIP)
+	require.NoError(t, err)
+
+	_, err = service.HoldSlot("consult-again", start, bookingTestIP)
+	assert.EqualError(t, err, "slot is no longer available")
+}
+
+func TestExpiredHoldFreesSlot(t *testing.T) {
+	service, db := newBookingTestService(t)
+	start := bookingTestSlot(t)
+
+	hold, err := service.HoldSlot("consult", start, bookingTestIP)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&models.BookingHold{}).Where("token = ?", hold.HoldToken).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	_, err = service.Book("consult", bookingTestRequest(start, hold.HoldToken))
+	assert.EqualError(t, err, "slot hold has expired; please choose a time again")
+
+	_, err = service.HoldSlot("consult", start, bookingTestIP)
+	assert.NoError(t, err)
+}
+
+func TestHoldSlotCapsHoldsPerClient(t *testing.T) {
+	service, db := newBookingTestService(t)
+	start := bookingTestSlot(t)
+
+	for i := 0; i < 2; i++ {
+		_, err := service.HoldSlot("consult", start.Add(time.Duration(i)*time.Hour), bookingTestIP)
+		require.NoError(t, err)
+	}
+	_, err := service.HoldSlot("consult", start.Add(2*time.Hour), bookingTestIP)
+	assert.EqualError(t, err, "too many held slots; book one or wait for them to expire")
+
+	// Other visitors are not affected, and expired holds do not count
+	_, err = service.HoldSlot("consult", start.Add(2*time.Hour), "198.51.100.4")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&models.BookingHold{}).Where("client_ip = ?", bookingTestIP).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	_, err = service.HoldSlot("consult", start.Add(3*time.Hour), bookingTestIP)
+	assert.NoError(t, err)
+}
+
+func TestBookClaimsHold(t *testing.T) {
+	service, db := newBookingTestService(t)
+	start := bookingTestSlot(t)
+
+	hold, err := service.HoldSlot("consult", start, bookingTestIP)
+	require.NoError(t, err)
+
+	// The visitor cannot choose a type the page does not offer
+	request := bookingTestRequest(start, hold.HoldToken)
+	appointmentType, meetingType := models.AppointmentDemo, models.MeetingInPerson
+	request.AppointmentType, request.MeetingType = &appointmentType, &meetingType
+
+	booking, err := service.Book("consult", request)
+	require.NoError(t, err)
+	assert.True(t, booking.StartTime.Equal(start))
+
+	var appointment models.Appointment
+	require.NoError(t, db.First(&appointment, booking.AppointmentID).Error)
+	assert.Equal(t, uint(1), appointment.AssignedTo)
+	assert.Equal(t, models.BookingSourcePublic, appointment.BookingSource)
+	assert.Equal(t, models.AppointmentConsultation, appointment.AppointmentType)
+	assert.Equal(t, models.MeetingVideoCall, appointment.MeetingType)
+
+	var contact models.Contact
+	require.NoError(t, db.First(&contact, appointment.ContactID).Error)
+	assert.Equal(t, "asha.rao@example.com", contact.Email)
+
+	var holds int64
+	require.NoError(t, db.Model(&models.BookingHold{}).Count(&holds).Error)
+	assert.Zero(t, holds)
+}
+
+func TestFailedBookingCreatesNoContact(t *testing.T) {
+	service, db := newBookingTestService(t)
+	start := bookingTestSlot(t)
+
+	_, err := service.HoldSlot("consult", start, bookingTestIP)
+	require.NoError(t, err)
+
+	_, err = service.Book("consult", bookingTestRequest(start, ""))
+	assert.EqualError(t, err, "slot is no longer available")
+
+	var contacts int64
+	require.NoError(t, db.Model(&models.Contact{}).Count(&contacts).Error)
+	assert.Zero(t, contacts)
+}
+
+func TestSignedBookingLinks(t *testing.T) {
+	service, db := newBookingTestService(t)
+	start := bookingTestSlot(t)
+
+	booking, err := service.Book("consult", bookingTestRequest(start, ""))
+	require.NoError(t, err)
+	assert.Contains(t, booking.ManageURL, service.signBooking(booking.AppointmentID))
+
+	_, err = service.GetPublicBooking(booking.AppointmentID, "forged")
+	assert.EqualError(t, err, "booking not found")
+	_, err = service.GetPublicBooking(booking.AppointmentID+1, service.signBooking(booking.AppointmentID))
+	assert.EqualError(t, err, "booking not found")
+	assert.EqualError(t, service.CancelPublicBooking(booking.AppointmentID, "forged", ""), "booking not found")
+
+	signature := service.signBooking(booking.AppointmentID)
+	later := start.Add(2 * time.Hour)
+	hold, err := service.HoldSlot("consult", later, bookingTestIP)
+	require.NoError(t, err)
+
+	rescheduled, err := service.ReschedulePublicBooking(booking.AppointmentID, signature, &models.PublicRescheduleRequest{HoldToken: hold.HoldToken})
+	require.NoError(t, err)
+	assert.True(t, rescheduled.StartTime.Equal(later))
+
+	require.NoError(t, service.CancelPublicBooking(booking.AppointmentID, signature, ""))
+	var appointment models.Appointment
+	require.NoError(t, db.First(&appointment, booking.AppointmentID).Error)
+	assert.Equal(t, models.AppointmentCancelled, appointment.Status)
+
+	var reason string
+	require.NoError(t, db.Raw("SELECT cancel_reason FROM appointments WHERE id = ?", booking.AppointmentID).Scan(&reason).Error)
+	assert.Equal(t, "Cancelled by visitor", reason)
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/logger"
+	"contact-service/pkg/mailer"
+	"contact-service/pkg/sms"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReminderSender delivers a claimed appointment reminder over one channel
+type ReminderSender interface {
+	SendReminder(reminder *models.AppointmentReminder) error
+}
+
+// EmailReminderSender sends reminders through the outbound mailer
+type EmailReminderSender struct {
+	Mailer mailer.Mailer
+	From   mailer.Address
+}
+
+// SendReminder implements ReminderSender
+func (s *EmailReminderSender) SendReminder(reminder *models.AppointmentReminder) error {
+	if reminder.RecipientEmail == nil || *reminder.RecipientEmail == "" {
+		return fmt.Errorf("reminder has no recipient email")
+	}
+	return s.Mailer.Send(&mailer.Message{
+		From:    s.From,
+		To:      []mailer.Address{{Email: *reminder.RecipientEmail}},
+		Subject: stringValue(reminder.Subject),
+		Headers: map[string]string{
+			"X-Appointment-ID": strconv.FormatUint(uint64(reminder.AppointmentID), 10),
+		},
+		PlainBody: stringValue(reminder.Message),
+	})
+}
+
+// SMSReminderSender sends reminders as text messages
+type SMSReminderSender struct {
+	Sender sms.Sender
+}
+
+// SendReminder implements ReminderSender
+func (s *SMSReminderSender) SendReminder(reminder *models.AppointmentReminder) error {
+	if reminder.RecipientPhone == nil || *reminder.RecipientPhone == "" {
+		return fmt.Errorf("reminder has no recipient phone")
+	}
+	return s.Sender.Send(*reminder.RecipientPhone, stringValue(reminder.Message))
+}
+
+// ReminderDispatcherConfig configures the reminder dispatcher
+type ReminderDispatcherConfig struct {
+	PollInterval time.Duration // How often due reminders are looked for
+	BatchSize    int           // Maximum reminders claimed per poll
+	ClaimTimeout time.Duration // Claims older than this are assumed abandoned by a crashed instance
+}
+
+// LoadReminderDispatcherConfig reads the dispatcher configuration from the environment
+func LoadReminderDispatcherConfig() ReminderDispatcherConfig {
+	config := ReminderDispatcherConfig{
+		PollInterval: 30 * time.Second,
+		BatchSize:    50,
+		ClaimTimeout: 10 * time.Minute,
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("REMINDER_POLL_INTERVAL_SECONDS")); err == nil && seconds > 0 {
+		config.PollInterval = time.Duration(seconds) * time.Second
+	}
+	if size, err := strconv.Atoi(os.Getenv("REMINDER_BATCH_SIZE")); err == nil && size > 0 {
+		config.BatchSize = size
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("REMINDER_CLAIM_TIMEOUT_SECONDS")); err == nil && seconds > 0 {
+		config.ClaimTimeout = time.Duration(seconds) * time.Second
+	}
+	return config
+}
+
+// ReminderDispatcher sends due appointment_reminders rows. Reminders are claimed with a
+// conditional update, so several service instances can poll the same table without double-sending.
+type ReminderDispatcher struct {
+	db         *gorm.DB
+	senders    map[string]ReminderSender
+	config     ReminderDispatcherConfig
+	instanceID string
+	startOnce  sync.Once
+}
+
+// NewReminderDispatcher creates a reminder dispatcher; senders are keyed by reminder type
+func NewReminderDispatcher(db *gorm.DB, senders map[string]ReminderSender, config ReminderDispatcherConfig) *ReminderDispatcher {
+	hostname, _ := os.Hostname()
+	return &ReminderDispatcher{
+		db:         db,
+		senders:    senders,
+		config:     config,
+		instanceID: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+	}
+}
+
+// Start launches the dispatch loop. Calling it again has no effect.
+func (d *ReminderDispatcher) Start() error {
+	var startErr error
+	d.startOnce.Do(func() {
+		var ids []uint
+		if err := d.db.Model(&models.AppointmentReminder{}).Limit(1).Pluck("id", &ids).Error; err != nil {
+			startErr = fmt.Errorf("failed to access appointment reminders: %v", err)
+			return
+		}
+
+		go d.run()
+
+		logger.Info("Appointment reminder dispatcher started", map[string]interface{}{
+			"instance":      d.instanceID,
+			"poll_interval": d.config.PollInterval.String(),
+			"claim_timeout": d.config.ClaimTimeout.String(),
+		})
+	})
+	return startErr
+}
+
+func (d *ReminderDispatcher) run() {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.recoverStaleClaims()
+		for d.dispatchDue() {
+		}
+		<-ticker.C
+	}
+}
+
+// recoverStaleClaims returns reminders left in sending by an instance that stopped mid-send
+func (d *ReminderDispatcher) recoverStaleClaims() {
+	result := d.db.Model(&models.AppointmentReminder{}).
+		Where("status = ? AND claimed_at < ?", models.ReminderStatusSending, time.Now().Add(-d.config.ClaimTimeout)).
+		Updates(map[string]interface{}{
+			"status":     models.ReminderStatusScheduled,
+			"claimed_by": nil,
+			"claimed_at": nil,
+		})
+	if result.Error != nil {
+		logger.Error("Failed to recover stale reminder claims", result.Error, nil)
+		return
+	}
+	if result.RowsAffected > 0 {
+		logger.Warn("Recovered stale reminder claims", map[string]interface{}{
+			"count": result.RowsAffected,
+		})
+	}
+}
+
+// dispatchDue claims and sends up to a batch of due reminders. It reports whether a full batch was
+// handled without errors, in which case more reminders may be due; after a failure the remaining
+// reminders wait for the next poll rather than being retried at once.
+func (d *ReminderDispatcher) dispatchDue() bool {
+	now := time.Now()
+	var ids []uint
+	if err := d.db.Model(&models.AppointmentReminder{}).
+		Where("status = ? AND scheduled_send_time <= ?", models.ReminderStatusScheduled, now).
+		Order("scheduled_send_time ASC").
+		Limit(d.config.BatchSize).
+		Pluck("id", &ids).Error; err != nil {
+		logger.Error("Failed to find due reminders", err, nil)
+		return false
+	}
+
+	failed := 0
+	for _, id := range ids {
+		reminder, err := d.claim(id, now)
+		if err != nil {
+			failed++
+			logger.Error("Failed to claim reminder", err, map[string]interface{}{
+				"reminder_id": id,
+			})
+			continue
+		}
+		if reminder != nil {
+			d.dispatch(reminder)
+		}
+	}
+	return failed == 0 && len(ids) == d.config.BatchSize
+}
+
+// claim marks a scheduled reminder as being sent by this instance. It returns nil when
+// another instance claimed it first.
+func (d *ReminderDispatcher) claim(id uint, now time.Time) (*models.AppointmentReminder, error) {
+	result := d.db.Model(&models.AppointmentReminder{}).
+		Where("id = ? AND status = ?", id, models.ReminderStatusScheduled).
+		Updates(map[string]interface{}{
+			"status":     models.ReminderStatusSending,
+			"claimed_by": d.instanceID,
+			"claimed_at": now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	var reminder models.AppointmentReminder
+	if err := d.db.First(&reminder, id).Error; err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+func (d *ReminderDispatcher) dispatch(reminder *models.AppointmentReminder) {
+	var appointment models.Appointment
+	if err := d.db.First(&appointment, reminder.AppointmentID).Error; err != nil {
+		d.finish(reminder, models.ReminderStatusCancelled, "appointment_missing", nil)
+		return
+	}
+
+	switch {
+	case appointment.DeletedAt != nil,
+		appointment.Status == models.AppointmentCancelled,
+		appointment.Status == models.AppointmentCompleted,
+		appointment.Status == models.AppointmentNoShow:
+		d.finish(reminder, models.ReminderStatusCancelled, string(appointment.Status), nil)
+		return
+	case !appointment.ScheduledDate.After(time.Now()):
+		d.finish(reminder, models.ReminderStatusCancelled, "expired", nil)
+		return
+	}
+
+	sender, ok := d.senders[reminder.ReminderType]
+	if !ok {
+		d.finish(reminder, models.ReminderStatusFailed, "failed", fmt.Errorf("no sender configured for %s reminders", reminder.ReminderType))
+		return
+	}
+	if err := sender.SendReminder(reminder); err != nil {
+		d.finish(reminder, models.ReminderStatusFailed, "failed", err)
+		return
+	}
+
+	now := time.Now()
+	d.finish(reminder, models.ReminderStatusSent, "sent", nil)
+	if err := d.db.Model(&models.Appointment{}).Where("id = ?", appointment.ID).Updates(map[string]interface{}{
+		"reminder_sent":    true,
+		"reminder_sent_at": now,
+	}).Error; err != nil {
+		logger.Error("Failed to flag appointment reminder sent", err, map[string]interface{}{
+			"appointment_id": appointment.ID,
+		})
+	}
+}
+
+// finish records the outcome of a claimed reminder
+func (d *ReminderDispatcher) finish(reminder *models.AppointmentReminder, status, deliveryStatus string, sendErr error) {
+	updates := map[string]interface{}{
+		"status":          status,
+		"delivery_status": deliveryStatus,
+		"claimed_by":      nil,
+		"claimed_at":      nil,
+	}
+	if status == models.ReminderStatusSent {
+		updates["actual_send_time"] = time.Now()
+	}
+	if sendErr != nil {
+		updates["delivery_error"] = sendErr.Error()
+	}
+
+	if err := d.db.Model(&models.AppointmentReminder{}).
+		Where("id = ? AND status = ?", reminder.ID, models.ReminderStatusSending).
+		Updates(updates).Error; err != nil {
+		logger.Error("Failed to record reminder outcome", err, map[string]interface{}{
+			"reminder_id": reminder.ID,
+		})
+		return
+	}
+
+	fields := map[string]interface{}{
+		"reminder_id":    reminder.ID,
+		"appointment_id": reminder.AppointmentID,
+		"type":           reminder.ReminderType,
+		"status":         status,
+	}
+	if sendErr != nil {
+		logger.Error("Appointment reminder failed", sendErr, fields)
+		return
+	}
+	logger.Info("Appointment reminder processed", fields)
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"contact-service/internal/models"
+)
+
+// recordingReminderSender records the reminders it is asked to send
+type recordingReminderSender struct {
+	sent []uint
+}
+
+func (s *recordingReminderSender) SendReminder(reminder *models.AppointmentReminder) error {
+	s.sent = append(s.sent, reminder.ID)
+	return nil
+}
+
+// newReminderTestDispatcher returns a dispatcher on db whose email reminders go to sender
+func newReminderTestDispatcher(db *gorm.DB, instanceID string, sender ReminderSender) *ReminderDispatcher {
+	dispatcher := NewReminderDispatcher(db, map[string]ReminderSender{"email": sender}, ReminderDispatcherConfig{
+		PollInterval: time.Minute,
+		BatchSize:    10,
+		ClaimTimeout: 10 * time.Minute,
+	})
+	dispatcher.instanceID = instanceID
+	return dispatcher
+}
+
+// createDueReminders creates an upcoming appointment with count email reminders that are due
+func createDueReminders(t *testing.T, db *gorm.DB, count int) []uint {
+	appointment := &models.Appointment{
+		ContactID:       1,
+		Title:           "Discovery call",
+		AppointmentType: models.AppointmentConsultation,
+		Status:          models.AppointmentConfirmed,
+		ScheduledDate:   time.Now().Add(24 * time.Hour),
+		ScheduledTime:   "10:00:00",
+		DurationMinutes: 30,
+		Timezone:        "UTC",
+		MeetingType:     models.MeetingVideoCall,
+		AssignedTo:      1,
+	}
+	require.NoError(t, db.Create(appointment).Error)
+
+	email := "visitor@example.com"
+	ids := make([]uint, 0, count)
+	for i := 0; i < count; i++ {
+		reminder := &models.AppointmentReminder{
+			AppointmentID:       appointment.ID,
+			ReminderType:        "email",
+			ReminderTimeMinutes: 60,
+			Status:              models.ReminderStatusScheduled,
+			ScheduledSendTime:   time.Now().Add(-time.Minute),
+			RecipientEmail:      &email,
+		}
+		require.NoError(t, db.Create(reminder).Error)
+		ids = append(ids, reminder.ID)
+	}
+	return ids
+}
+
+func TestReminderClaimIsExclusive(t *testing.T) {
+	db := newTestDB(t, &models.Appointment{}, &models.AppointmentReminder{})
+	ids := createDueReminders(t, db, 1)
+	first := newReminderTestDispatcher(db, "replica-a", &recordingReminderSender{})
+	second := newReminderTestDispatcher(db, "replica-b", &recordingReminderSender{})
+
+	claimed, err := first.claim(ids[0], time.Now())
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, "replica-a", *claimed.ClaimedBy)
+
+	claimed, err = second.claim(ids[0], time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, claimed, "a reminder claimed by one replica was claimed again")
+}
+
+func TestRemindersAreSentOnceAcrossReplicas(t *testing.T) {
+	db := newTestDB(t, &models.Appointment{}, &models.AppointmentReminder{})
+	ids := createDueReminders(t, db, 3)
+	senderA, senderB := &recordingReminderSender{}, &recordingReminderSender{}
+	first := newReminderTestDispatcher(db, "replica-a", senderA)
+	second := newReminderTestDispatcher(db, "replica-b", senderB)
+
+	// Both replicas found the same due reminders; the first claims them before the second gets to them
+	for _, id := range ids {
+		if reminder, err := first.claim(id, time.Now()); assert.NoError(t, err) && reminder != nil {
+			first.dispatch(reminder)
+		}
+		if reminder, err := second.claim(id, time.Now()); assert.NoError(t, err) && reminder != nil {
+			second.dispatch(reminder)
+		}
+	}
+	assert.False(t, second.dispatchDue())
+
+	assert.ElementsMatch(t, ids, senderA.sent)
+	assert.Empty(t, senderB.sent)
+
+	var sent int64
+	require.NoError(t, db.Model(&models.AppointmentReminder{}).Where("status = ?", models.ReminderStatusSent).Count(&sent).Error)
+	assert.Equal(t, int64(3), sent)
+}
+
+func TestRecoverStaleClaims(t *testing.T) {
+	db := newTestDB(t, &models.Appointment{}, &models.AppointmentReminder{})
+	ids := createDueReminders(t, db, 2)
+	dispatcher := newReminderTestDispatcher(db, "replica-a", &recordingReminderSender{})
+
+	// One claim was abandoned by a stopped replica, the other is still being sent
+	require.NoError(t, db.Model(&models.AppointmentReminder{}).Where("id = ?", ids[0]).Updates(map[string]interface{}{
+		"status":     models.ReminderStatusSending,
+		"claimed_by": "stopped-replica",
+		"claimed_at": time.Now().Add(-time.Hour),
+	}).Error)
+	require.NoError(t, db.Model(&models.AppointmentReminder{}).Where("id = ?", ids[1]).Updates(map[string]interface{}{
+		"status":     models.ReminderStatusSending,
+		"claimed_by": "replica-b",
+		"claimed_at": time.Now().Add(-time.Minute),
+	}).Error)
+
+	dispatcher.recoverStaleClaims()
+
+	var stale, fresh models.AppointmentReminder
+	require.NoError(t, db.First(&stale, ids[0]).Error)
+	require.NoError(t, db.First(&fresh, ids[1]).Error)
+	assert.Equal(t, models.ReminderStatusScheduled, stale.Status)
+	assert.Nil(t, stale.ClaimedBy)
+	assert.Nil(t, stale.ClaimedAt)
+	assert.Equal(t, models.ReminderStatusSending, fresh.Status)
+	assert.Equal(t, "replica-b", *fresh.ClaimedBy)
+}
+
+func TestDispatchDueStopsWhenClaimsFail(t *testing.T) {
+	db := newTestDB(t, &models.Appointment{}, &models.AppointmentReminder{})
+	createDueReminders(t, db, 10)
+	sender := &recordingReminderSender{}
+	dispatcher := newReminderTestDispatcher(db, "replica-a", sender)
+
+	require.NoError(t, db.Callback().Update().Before("gorm:update").Register("test:fail_updates", func(tx *gorm.DB) {
+		tx.AddError(errors.New("database unavailable"))
+	}))
+
+	assert.False(t, dispatcher.dispatchDue(), "a batch whose claims failed was reported as fully dispatched")
+	assert.Empty(t, sender.sent)
+}
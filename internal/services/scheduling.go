@@ -2,10 +2,11 @@ package services
 
 import (
 	"contact-service/internal/models"
+	"contact-service/pkg/ical"
 	"contact-service/pkg/logger"
 	"errors"
 	"fmt"
-	"strings"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
@@ -13,7 +14,9 @@ import (
 
 // SchedulingService handles appointment scheduling and calendar management
 type SchedulingService struct {
-	db *gorm.DB
+	db              *gorm.DB
+	inviter         *CalendarInviter // Optional; emails .ics invitations when set
+	materializeOnce sync.Once
 }
 
 // NewSchedulingService creates a new scheduling service
@@ -21,6 +24,34 @@ func NewSchedulingService(db *gorm.DB) *SchedulingService {
 	return &SchedulingService{db: db}
 }
 
+// SetCalendarInviter enables emailed calendar invitations on create, reschedule and cancel
+func (s *SchedulingService) SetCalendarInviter(inviter *CalendarInviter) {
+	s.inviter = inviter
+}
+
+// sendCalendarInvite queues an email of the appointment's current state to its contact and attendees.
+// Changes to an existing event bump its SEQUENCE first so calendar clients apply them.
+func (s *SchedulingService) sendCalendarInvite(appointmentID uint, method string, changed bool) {
+	if changed {
+		if err := s.db.Model(&models.Appointment{}).Where("id = ?", appointmentID).
+			UpdateColumn("calendar_sequence", gorm.Expr("calendar_sequence + 1")).Error; err != nil {
+			logger.Error("Failed to bump calendar sequence", err, map[string]interface{}{
+				"appointment_id": appointmentID,
+			})
+		}
+	}
+	if s.inviter == nil {
+		return
+	}
+
+	if err := s.inviter.QueueInvite(appointmentID, method); err != nil {
+		logger.Error("Failed to queue calendar invite", err, map[string]interface{}{
+			"appointment_id": appointmentID,
+			"method":         method,
+		})
+	}
+}
+
 // CreateAppointment creates a new appointment
 func (s *SchedulingService) CreateAppointment(request *models.AppointmentRequest, createdByUserID uint) (*models.AppointmentResponse, error) {
 	// Parse scheduled date and time into start time
@@ -41,10 +72,10 @@ func (s *SchedulingService) CreateAppointment(request *models.AppointmentRequest
 		return nil, err
 	}
 
-	// Check for conflicts - skip for now as AssignedTo not in request
-	// if err := s.checkAppointmentConflicts(assignedUserID, startTime, endTime, 0); err != nil {
-	//	return nil, err
-	// }
+	// Check for conflicts
+	if err := s.checkAppointmentConflicts(request.AssignedTo, startTime, endTime, 0); err != nil {
+		return nil, err
+	}
 
 	// Create appointment
 	appointment := &models.Appointment{
@@ -64,6 +95,7 @@ func (s *SchedulingService) CreateAppointment(request *models.AppointmentRequest
 		MeetingPassword:  request.MeetingPassword,
 		Location:         request.Location,
 		PhoneNumber:      request.PhoneNumber,
+		AssignedTo:       request.AssignedTo,
 		CreatedBy:        &createdByUserID,
 	}
 
@@ -81,8 +113,10 @@ func (s *SchedulingService) CreateAppointment(request *models.AppointmentRequest
 		appointment.AppointmentType = *request.AppointmentType
 	}
 
-	// Note: Recurring appointments not implemented in current model
-	// Skip recurring logic for now
+	// Recurring appointments become the first occurrence of a new series
+	if request.Recurrence != nil {
+		return s.createAppointmentSeries(appointment, request)
+	}
 
 	// Create the appointment
 	if err := s.db.Create(appointment).Error; err != nil {
@@ -90,11 +124,12 @@ func (s *SchedulingService) CreateAppointment(request *models.AppointmentRequest
 	}
 
 	// Schedule reminders
-	if err := s.scheduleReminders(appointment); err != nil {
+	if err := s.scheduleReminders(appointment, request.Reminders); err != nil {
 		logger.Error("Failed to schedule reminders", err, map[string]interface{}{
 			"appointment_id": appointment.ID,
 		})
 	}
+	s.sendCalendarInvite(appointment.ID, ical.MethodRequest, false)
 
 	// Update contact's next followup date if this is the earliest
 	s.updateContactNextFollowup(appointment.ContactID)
@@ -184,6 +219,11 @@ func (s *SchedulingService) UpdateAppointment(appointmentID uint, request *model
 		"updated_at":         time.Now(),
 	}
 
+	// Editing a single occurrence detaches it from later series-wide edits
+	if appointment.SeriesID != nil {
+		updates["is_series_exception"] = true
+	}
+
 	// Set optional fields
 	if request.Priority != nil {
 		updates["priority"] = *request.Priority
@@ -203,14 +243,18 @@ func (s *SchedulingService) UpdateAppointment(appointmentID uint, request *model
 	if err := s.db.Model(&appointment).Updates(updates).Error; err != nil {
 		return nil, fmt.Errorf("failed to update appointment: %v", err)
 	}
+	s.sendCalendarInvite(appointmentID, ical.MethodRequest, true)
 
 	// Reload updated appointment
 	s.db.Preload("Contact").Preload("AssignedUser").First(&appointment, appointmentID)
 
 	// Reschedule reminders if time changed
 	if request.ScheduledDate != "" && request.ScheduledTime != "" {
-		// Time was updated, could reschedule reminders here
-		// s.rescheduleReminders(&appointment) - skip for now
+		if err := s.rescheduleReminders(&appointment); err != nil {
+			logger.Error("Failed to reschedule reminders", err, map[string]interface{}{
+				"appointment_id": appointmentID,
+			})
+		}
 	}
 
 	logger.Info("Appointment updated successfully", map[string]interface{}{
@@ -262,6 +306,18 @@ func (s *SchedulingService) UpdateAppointmentStatus(appointmentID uint, request
 		return fmt.Errorf("failed to update appointment status: %v", err)
 	}
 
+	// Appointments that will not take place no longer need reminders
+	if newStatus == models.AppointmentCancelled || newStatus == models.AppointmentCompleted || newStatus == models.AppointmentNoShow {
+		if err := s.cancelReminders(&appointment); err != nil {
+			logger.Error("Failed to cancel reminders", err, map[string]interface{}{
+				"appointment_id": appointmentID,
+			})
+		}
+	}
+	if newStatus == models.AppointmentCancelled {
+		s.sendCalendarInvite(appointmentID, ical.MethodCancel, true)
+	}
+
 	// Update contact's next followup date
 	s.updateContactNextFollowup(appointment.ContactID)
 
@@ -309,16 +365,24 @@ func (s *SchedulingService) RescheduleAppointment(appointmentID uint, request *m
 		"status":            models.AppointmentRescheduled,
 		"updated_at":        time.Now(),
 	}
+	if appointment.SeriesID != nil {
+		updates["is_series_exception"] = true
+	}
 
 	if err := s.db.Model(&appointment).Updates(updates).Error; err != nil {
 		return nil, fmt.Errorf("failed to reschedule appointment: %v", err)
 	}
+	s.sendCalendarInvite(appointmentID, ical.MethodRequest, true)
 
 	// Reload updated appointment
 	s.db.Preload("Contact").Preload("AssignedUser").First(&appointment, appointmentID)
 
-	// Skip rescheduling reminders for now
-	// s.rescheduleReminders(&appointment)
+	// Shift reminders to the new time
+	if err := s.rescheduleReminders(&appointment); err != nil {
+		logger.Error("Failed to reschedule reminders", err, map[string]interface{}{
+			"appointment_id": appointmentID,
+		})
+	}
 
 	logger.Info("Appointment rescheduled successfully", map[string]interface{}{
 		"appointment_id":   appointmentID,
@@ -341,8 +405,13 @@ func (s *SchedulingService) CancelAppointment(appointmentID uint, reason string,
 		return fmt.Errorf("failed to get appointment: %v", err)
 	}
 
+	return s.cancelAppointment(&appointment, reason, &cancelledByUserID)
+}
+
+// cancelAppointment cancels a loaded appointment; cancelledByUserID is nil when a visitor cancels through a booking link
+func (s *SchedulingService) cancelAppointment(appointment *models.Appointment, reason string, cancelledByUserID *uint) error {
 	// Check if appointment can be cancelled
-	if !s.canCancelAppointment(&appointment) {
+	if !s.canCancelAppointment(appointment) {
 		return fmt.Errorf("appointment cannot be cancelled (status: %s)", appointment.Status)
 	}
 
@@ -355,21 +424,30 @@ func (s *SchedulingService) CancelAppointment(appointmentID uint, reason string,
 		"updated_by":    cancelledByUserID,
 	}
 
-	if err := s.db.Model(&appointment).Updates(updates).Error; err != nil {
+	if err := s.db.Model(appointment).Updates(updates).Error; err != nil {
 		return fmt.Errorf("failed to cancel appointment: %v", err)
 	}
+	s.sendCalendarInvite(appointment.ID, ical.MethodCancel, true)
 
 	// Cancel reminders
-	s.cancelReminders(&appointment)
+	if err := s.cancelReminders(appointment); err != nil {
+		logger.Error("Failed to cancel reminders", err, map[string]interface{}{
+			"appointment_id": appointment.ID,
+		})
+	}
 
 	// Update contact's next followup date
 	s.updateContactNextFollowup(appointment.ContactID)
 
-	logger.Info("Appointment cancelled successfully", map[string]interface{}{
-		"appointment_id": appointmentID,
-		"cancelled_by":   cancelledByUserID,
+	fields := map[string]interface{}{
+		"appointment_id": appointment.ID,
+		"cancelled_by":   "booking_link",
 		"reason":         reason,
-	})
+	}
+	if cancelledByUserID != nil {
+		fields["cancelled_by"] = *cancelledByUserID
+	}
+	logger.Info("Appointment cancelled successfully", fields)
 
 	return nil
 }
@@ -377,13 +455,13 @@ func (s *SchedulingService) CancelAppointment(appointmentID uint, reason string,
 // GetUserAppointments gets appointments for a specific user
 func (s *SchedulingService) GetUserAppointments(userID uint, startDate, endDate time.Time, status string) ([]models.AppointmentResponse, error) {
 	query := s.db.Where("assigned_to = ? AND deleted_at IS NULL", userID).
-		Preload("Contact")
+		Preload("Contact").Preload("Attendees")
 	// Date range filter
 	if !startDate.IsZero() {
-		query = query.Where("start_time >= ?", startDate)
+		query = query.Where("scheduled_date >= ?", startDate)
 	}
 	if !endDate.IsZero() {
-		query = query.Where("end_time <= ?", endDate)
+		query = query.Where("scheduled_date <= ?", endDate)
 	}
 
 	// Status filter
@@ -392,7 +470,7 @@ func (s *SchedulingService) GetUserAppointments(userID uint, startDate, endDate
 	}
 
 	var appointments []models.Appointment
-	if err := query.Order("start_time ASC").Find(&appointments).Error; err != nil {
+	if err := query.Order("scheduled_date ASC").Find(&appointments).Error; err != nil {
 		return nil, fmt.Errorf("failed to get user appointments: %v", err)
 	}
 
@@ -417,7 +495,7 @@ func (s *SchedulingService) GetContactAppointments(contactID uint) ([]models.App
 	var appointments []models.Appointment
 	if err := s.db.Where("contact_id = ? AND deleted_at IS NULL", contactID).
 		Preload("Contact").Preload("AssignedUser").
-		Order("start_time DESC").Find(&appointments).Error; err != nil {
+		Order("scheduled_date DESC").Find(&appointments).Error; err != nil {
 		return nil, fmt.Errorf("failed to get contact appointments: %v", err)
 	}
 
@@ -439,8 +517,8 @@ func (s *SchedulingService) GetContactAppointments(contactID uint) ([]models.App
 
 // FindAvailableSlots finds available time slots for scheduling
 func (s *SchedulingService) FindAvailableSlots(request *models.AvailabilitySlotRequest) ([]models.AvailabilitySlot, error) {
-	var availableSlots []models.AvailabilitySlot
-	
+	availableSlots := []models.AvailabilitySlot{}
+
 	bufferTime := 0
 	if request.BufferTime != nil {
 		bufferTime = *request.BufferTime
@@ -461,17 +539,61 @@ func (s *SchedulingService) FindAvailableSlots(request *models.AvailabilitySlotR
 	return availableSlots, nil
 }
 
-// GetUserAvailability gets availability information for a user
+// GetUserAvailability gets a user's working hours, free slots and appointments on a date in their schedule's timezone
 func (s *SchedulingService) GetUserAvailability(userID uint, date time.Time) (*models.AvailabilityResponse, error) {
-	// Note: UserAvailability model not implemented yet
-	// For now, return default availability
-	return &models.AvailabilityResponse{
-		UserID:      userID,
-		Date:        date,
-		IsAvailable: true,
-		Timezone:    "Asia/Kolkata",
-	}, nil
+	schedule, err := s.loadHostSchedule(userID, date, date.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, schedule.loc)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	day, err := schedule.day(dayStart, true)
+	if err != nil {
+		return nil, err
+	}
+	free, err := s.availableWindows(schedule, userID, dayStart, dayEnd, 0, true)
+	if err != nil {
+		return nil, err
+	}
+	busy, err := s.getUserBusyWindows(userID, dayStart, dayEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.AvailabilityResponse{
+		UserID:         userID,
+		Date:           dayStart,
+		IsAvailable:    len(day.working) > 0,
+		WorkingHours:   day.hours,
+		AvailableSlots: []models.AvailabilitySlot{},
+		BusySlots:      []models.AvailabilitySlot{},
+		Timezone:       schedule.loc.String(),
+	}
+	for _, window := range models.SplitWindows(free, availabilitySlotMinutes*time.Minute) {
+		response.AvailableSlots = append(response.AvailableSlots, models.AvailabilitySlot{
+			StartTime:   window.Start.In(schedule.loc),
+			EndTime:     window.End.In(schedule.loc),
+			Duration:    availabilitySlotMinutes,
+			IsAvailable: true,
+			UserID:      userID,
+			Date:        dayStart,
+		})
+	}
+	for _, window := range busy {
+		response.BusySlots = append(response.BusySlots, models.AvailabilitySlot{
+			StartTime: window.Start.In(schedule.loc),
+			EndTime:   window.End.In(schedule.loc),
+			Duration:  int(window.End.Sub(window.Start).Minutes()),
+			UserID:    userID,
+			Date:      dayStart,
+		})
+	}
+	response.TotalAvailable = len(response.AvailableSlots)
+
+	return response, nil
 }
+
 func (s *SchedulingService) validateAppointmentTimes(startTime, endTime time.Time) error {
 	now := time.Now()
 
@@ -506,8 +628,7 @@ func (s *SchedulingService) checkAppointmentConflicts(userID uint, startTime, en
 			models.AppointmentCancelled,
 			models.AppointmentCompleted,
 		}).
-		Where("((start_time < ? AND end_time > ?) OR (start_time < ? AND end_time > ?) OR (start_time >= ? AND end_time <= ?))",
-			startTime, startTime, endTime, endTime, startTime, endTime)
+		Where("scheduled_date < ? AND DATE_ADD(scheduled_date, INTERVAL duration_minutes MINUTE) > ?", endTime, startTime)
 
 	if excludeAppointmentID > 0 {
 		query = query.Where("id != ?", excludeAppointmentID)
@@ -616,7 +737,7 @@ func (s *SchedulingService) buildAppointmentResponse(appointment *models.Appoint
 		PhoneNumber:               appointment.PhoneNumber,
 		ScheduledDateTime:         appointment.ScheduledDate, // Computed field
 		ConfirmationSent:          false, // TODO: implement
-		ReminderSent:              false, // TODO: implement
+		ReminderSent:              appointment.ReminderSent,
 		RescheduleCount:           0,     // TODO: implement
 		CompletedAt:               appointment.CompletedAt,
 		EstimatedValue:            0.0,   // TODO: implement
@@ -627,6 +748,11 @@ func (s *SchedulingService) buildAppointmentResponse(appointment *models.Appoint
 		IsToday:                   s.isToday(appointment.ScheduledDate),
 		IsUpcoming:                s.isUpcoming(appointment.ScheduledDate),
 		IsOverdue:                 s.isOverdue(appointment.ScheduledDate, appointment.Status),
+		SeriesID:                  appointment.SeriesID,
+		RecurrenceID:              appointment.RecurrenceID,
+		IsSeriesException:         appointment.IsSeriesException,
+		CalendarSequence:          appointment.CalendarSequence,
+		Attendees:                 appointment.Attendees,
 	}
 
 	// Note: Time until calculation removed as field not in response model
@@ -679,12 +805,12 @@ func (s *SchedulingService) updateContactNextFollowup(contactID uint) {
 	var appointment models.Appointment
 	now := time.Now()
 	
-	if err := s.db.Where("contact_id = ? AND start_time > ? AND deleted_at IS NULL", contactID, now).
+	if err := s.db.Where("contact_id = ? AND scheduled_date > ? AND deleted_at IS NULL", contactID, now).
 		Where("status NOT IN ?", []models.AppointmentStatus{
 			models.AppointmentCancelled,
 			models.AppointmentCompleted,
 		}).
-		Order("start_time ASC").
+		Order("scheduled_date ASC").
 		First(&appointment).Error; err == nil {
 		
 		// Update contact's next followup date
@@ -699,123 +825,6 @@ func (s *SchedulingService) updateContactNextFollowup(contactID uint) {
 	}
 }
 
-// Placeholder methods for complex functionality
-
-// createRecurringInstances creates recurring appointment instances
-func (s *SchedulingService) createRecurringInstances(appointment *models.Appointment) error {
-	// TODO: Implement recurring appointment creation logic
-	logger.Info("Creating recurring instances", map[string]interface{}{
-		"appointment_id": appointment.ID,
-		// Note: SeriesID not in current model
-	})
-	return nil
-}
-
-// scheduleReminders schedules appointment reminders
-func (s *SchedulingService) scheduleReminders(appointment *models.Appointment) error {
-	// TODO: Implement reminder scheduling logic
-	logger.Info("Scheduling reminders", map[string]interface{}{
-		"appointment_id": appointment.ID,
-	})
-	return nil
-}
-
-// rescheduleReminders reschedules appointment reminders
-func (s *SchedulingService) rescheduleReminders(appointment *models.Appointment) error {
-	// TODO: Implement reminder rescheduling logic
-	logger.Info("Rescheduling reminders", map[string]interface{}{
-		"appointment_id": appointment.ID,
-	})
-	return nil
-}
-
-// cancelReminders cancels appointment reminders
-func (s *SchedulingService) cancelReminders(appointment *models.Appointment) error {
-	// TODO: Implement reminder cancellation logic
-	logger.Info("Cancelling reminders", map[string]interface{}{
-		"appointment_id": appointment.ID,
-	})
-	return nil
-}
-
-// findUserAvailableSlots finds available time slots for a specific user
-func (s *SchedulingService) findUserAvailableSlots(userID uint, startDate, endDate time.Time, duration, bufferTime int, timezone string, businessHoursOnly *bool) ([]models.AvailabilitySlot, error) {
-	// TODO: Implement available slot finding logic
-	return []models.AvailabilitySlot{}, nil
-}
-
-// getUserBusySlots gets busy time slots for a user on a specific date
-func (s *SchedulingService) getUserBusySlots(userID uint, date time.Time) ([]models.AvailabilitySlot, error) {
-	// Get appointments for the user on this date
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
-
-	var appointments []models.Appointment
-	if err := s.db.Where("assigned_to = ? AND start_time >= ? AND start_time < ? AND deleted_at IS NULL", 
-		userID, startOfDay, endOfDay).
-		Where("status NOT IN ?", []models.AppointmentStatus{
-			models.AppointmentCancelled,
-		}).
-		Find(&appointments).Error; err != nil {
-		return nil, fmt.Errorf("failed to get user appointments: %v", err)
-	}
-
-	var busySlots []models.AvailabilitySlot
-	for _, appointment := range appointments {
-		busySlots = append(busySlots, models.AvailabilitySlot{
-			StartTime:   appointment.ScheduledDate,
-			EndTime:     appointment.ScheduledDate.Add(time.Duration(appointment.DurationMinutes) * time.Minute),
-			Duration:    appointment.DurationMinutes,
-			IsAvailable: false,
-		})
-	}
-
-	return busySlots, nil
-}
-
-// calculateAvailableSlots calculates available time slots based on working hours and busy slots
-func (s *SchedulingService) calculateAvailableSlots(workingStart, workingEnd string, busySlots []models.AvailabilitySlot, date time.Time, userID uint) []models.AvailabilitySlot {
-	// TODO: Implement available slot calculation logic
-	// This would parse working hours, subtract busy slots, and return available slots
-	var availableSlots []models.AvailabilitySlot
-	
-	// Simplified implementation - just return one slot if no busy slots
-	if len(busySlots) == 0 {
-		startTime := s.parseTimeOnDate(workingStart, date)
-		endTime := s.parseTimeOnDate(workingEnd, date)
-		if !startTime.IsZero() && !endTime.IsZero() {
-			availableSlots = append(availableSlots, models.AvailabilitySlot{
-				UserID:    userID,
-				StartTime: startTime,
-				EndTime:   endTime,
-				Duration:  int(endTime.Sub(startTime).Minutes()),
-			})
-		}
-	}
-	
-	return availableSlots
-}
-
-// parseTimeOnDate parses a time string (HH:MM) and combines it with a date
-func (s *SchedulingService) parseTimeOnDate(timeStr string, date time.Time) time.Time {
-	parts := strings.Split(timeStr, ":")
-	if len(parts) != 2 && len(parts) != 3 {
-		return time.Time{}
-	}
-	
-	hour := 0
-	minute := 0
-	
-	if h, err := time.Parse("15", parts[0]); err == nil {
-		hour = h.Hour()
-	}
-	if m, err := time.Parse("04", parts[1]); err == nil {
-		minute = m.Minute()
-	}
-	
-	return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, date.Location())
-}
-
 // parseScheduledDateTime parses scheduled date and time strings into a time.Time
 func (s *SchedulingService) parseScheduledDateTime(dateStr, timeStr string, timezone *string) (time.Time, error) {
 	// Parse date (YYYY-MM-DD format)
@@ -834,22 +843,22 @@ func (s *SchedulingService) parseScheduledDateTime(dateStr, timeStr string, time
 		}
 	}
 
-	// Combine date and time
-	combinedTime := time.Date(
-		date.Year(), date.Month(), date.Day(),
-		timeOnly.Hour(), timeOnly.Minute(), timeOnly.Second(),
-		0, time.UTC,
-	)
-
-	// Handle timezone if provided
+	// Date and time are wall-clock values in the given timezone (UTC when none is given)
+	loc := time.UTC
 	if timezone != nil && *timezone != "" {
-		loc, err := time.LoadLocation(*timezone)
+		loc, err = time.LoadLocation(*timezone)
 		if err != nil {
 			return time.Time{}, fmt.Errorf("invalid timezone: %v", err)
 		}
-		combinedTime = combinedTime.In(loc)
 	}
 
+	// Combine date and time
+	combinedTime := time.Date(
+		date.Year(), date.Month(), date.Day(),
+		timeOnly.Hour(), timeOnly.Minute(), timeOnly.Second(),
+		0, loc,
+	)
+
 	return combinedTime, nil
 }
 
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/logger"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Reason recorded on scores recalculated by the rescoring sweep
+const scoreSweepReason = "Scheduled rescore (score decay)"
+
+// ScoreSweeperConfig configures the periodic lead score recalculation
+type ScoreSweeperConfig struct {
+	Interval     time.Duration // How often the sweep runs
+	RescoreAfter time.Duration // Contacts scored longer ago than this are recalculated
+	BatchSize    int           // Contacts loaded per query
+}
+
+// LoadScoreSweeperConfig reads the rescoring sweep configuration from the environment
+func LoadScoreSweeperConfig() ScoreSweeperConfig {
+	config := ScoreSweeperConfig{
+		Interval:     time.Hour,
+		RescoreAfter: 24 * time.Hour,
+		BatchSize:    200,
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("SCORE_SWEEP_MINUTES")); err == nil && minutes > 0 {
+		config.Interval = time.Duration(minutes) * time.Minute
+	}
+	if hours, err := strconv.Atoi(os.Getenv("SCORE_RESCORE_AFTER_HOURS")); err == nil && hours > 0 {
+		config.RescoreAfter = time.Duration(hours) * time.Hour
+	}
+	if size, err := strconv.Atoi(os.Getenv("SCORE_SWEEP_BATCH_SIZE")); err == nil && size > 0 {
+		config.BatchSize = size
+	}
+	return config
+}
+
+// ScoreSweepReport summarizes a rescoring sweep
+type ScoreSweepReport struct {
+	Scanned   int // Contacts due for rescoring
+	Rescored  int // Contacts whose score changed
+	Unchanged int // Contacts whose score stayed the same
+	Skipped   int // Contacts another replica claimed first
+	Failed    int
+}
+
+// ScoreSweeper periodically recalculates the lead scores of contacts that have not been scored
+// recently. Scores otherwise only change when a contact is edited or engages, so without it the
+// points of contacts that stopped engaging would never decay and their band automation would never
+// fire. Each contact is claimed with a conditional update on its last_scored_at, so replicas
+// sweeping at the same time rescore each contact once.
+type ScoreSweeper struct {
+	db        *gorm.DB
+	lifecycle *LifecycleService
+	config    ScoreSweeperConfig
+	startOnce sync.Once
+}
+
+// NewScoreSweeper creates a rescoring sweeper
+func NewScoreSweeper(db *gorm.DB, lifecycle *LifecycleService, config ScoreSweeperConfig) *ScoreSweeper {
+	return &ScoreSweeper{db: db, lifecycle: lifecycle, config: config}
+}
+
+// Start launches the periodic sweep. Calling it again has no effect.
+func (s *ScoreSweeper) Start() {
+	s.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(s.config.Interval)
+			defer ticker.Stop()
+			for {
+				if _, err := s.Sweep(); err != nil {
+					logger.Error("Score sweep failed", err, nil)
+				}
+				<-ticker.C
+			}
+		}()
+
+		logger.Info("Score sweeper started", map[string]interface{}{
+			"interval":      s.config.Interval.String(),
+			"rescore_after": s.config.RescoreAfter.String(),
+			"batch_size":    s.config.BatchSize,
+		})
+	})
+}
+
+// Sweep recalculates the score of every scored contact whose last calculation is older than
+// RescoreAfter. Contacts whose score would not change only have their last_scored_at refreshed, so
+// the sweep does not fill the score history and calculation log with identical entries.
+func (s *ScoreSweeper) Sweep() (*ScoreSweepReport, error) {
+	report := &ScoreSweepReport{}
+	started := time.Now()
+	cutoff := started.Add(-s.config.RescoreAfter)
+
+	var lastID uint
+	for {
+		var lifecycles []models.ContactLifecycle
+		if err := s.db.Joins("JOIN contacts ON contacts.id = contact_lifecycles.contact_id AND contacts.deleted_at IS NULL").
+			Where("contact_lifecycles.last_scored_at < ? AND contact_lifecycles.id > ?", cutoff, lastID).
+			Order("contact_lifecycles.id ASC").Limit(s.config.BatchSize).
+			Find(&lifecycles).Error; err != nil {
+			return nil, fmt.Errorf("failed to get contacts due for rescoring: %v", err)
+		}
+		if len(lifecycles) == 0 {
+			break
+		}
+		lastID = lifecycles[len(lifecycles)-1].ID
+		report.Scanned += len(lifecycles)
+
+		for i := range lifecycles {
+			s.rescore(&lifecycles[i], report)
+		}
+
+		if len(lifecycles) < s.config.BatchSize {
+			break
+		}
+	}
+
+	if report.Rescored > 0 || report.Failed > 0 {
+		logger.Info("Score sweep completed", map[string]interface{}{
+			"scanned":   report.Scanned,
+			"rescored":  report.Rescored,
+			"unchanged": report.Unchanged,
+			"skipped":   report.Skipped,
+			"failed":    report.Failed,
+			"duration":  time.Since(started).String(),
+		})
+	}
+	return report, nil
+}
+
+// rescore claims a contact's lifecycle and recalculates its score when the result would differ
+func (s *ScoreSweeper) rescore(lifecycle *models.ContactLifecycle, report *ScoreSweepReport) {
+	claim := s.db.Model(&models.ContactLifecycle{}).
+		Where("id = ? AND last_scored_at = ?", lifecycle.ID, lifecycle.LastScoredAt).
+		Update("last_scored_at", time.Now())
+	if claim.Error != nil {
+		report.Failed++
+		logger.Error("Failed to claim contact for rescoring", claim.Error, map[string]interface{}{
+			"contact_id": lifecycle.ContactID,
+		})
+		return
+	}
+	if claim.RowsAffected == 0 {
+		report.Skipped++
+		return
+	}
+
+	var contact models.Contact
+	if err := s.db.Preload("ContactType").Preload("ContactSource").First(&contact, lifecycle.ContactID).Error; err != nil {
+		report.Failed++
+		logger.Error("Failed to get contact for rescoring", err, map[string]interface{}{
+			"contact_id": lifecycle.ContactID,
+		})
+		return
+	}
+	calculation, err := s.lifecycle.calculateScore(&contact)
+	if err != nil {
+		report.Failed++
+		logger.Error("Failed to calculate contact score", err, map[string]interface{}{
+			"contact_id": contact.ID,
+		})
+		return
+	}
+	if calculation.total == lifecycle.CurrentScore && calculation.total == contact.LeadScore {
+		report.Unchanged++
+		return
+	}
+
+	if _, err := s.lifecycle.ScoreContact(contact.ID, true, scoreSweepReason, nil); err != nil {
+		report.Failed++
+		logger.Error("Failed to rescore contact", err, map[string]interface{}{
+			"contact_id": contact.ID,
+		})
+		return
+	}
+	report.Rescored++
+}
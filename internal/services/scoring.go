@@ -0,0 +1,450 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/logger"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScoringConfig configures lead score calculation
+type ScoringConfig struct {
+	Decay models.ScoreDecayPolicy
+}
+
+// LoadScoringConfig reads the scoring configuration from the environment
+func LoadScoringConfig() ScoringConfig {
+	config := ScoringConfig{
+		Decay: models.ScoreDecayPolicy{
+			GraceDays:    14,
+			HalfLifeDays: 30,
+			Categories:   []string{"engagement", "behavioral"},
+		},
+	}
+	if days, err := strconv.Atoi(os.Getenv("SCORE_DECAY_GRACE_DAYS")); err == nil && days >= 0 {
+		config.Decay.GraceDays = days
+	}
+	if days, err := strconv.Atoi(os.Getenv("SCORE_DECAY_HALF_LIFE_DAYS")); err == nil && days >= 0 {
+		config.Decay.HalfLifeDays = days
+	}
+	if categories := os.Getenv("SCORE_DECAY_CATEGORIES"); categories != "" {
+		config.Decay.Categories = config.Decay.Categories[:0]
+		for _, category := range strings.Split(categories, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				config.Decay.Categories = append(config.Decay.Categories, category)
+			}
+		}
+	}
+	return config
+}
+
+// scoreContribution is what one rule or built-in signal added to a score
+type scoreContribution struct {
+	rule        *models.LeadScoringRule // Nil for built-in signals
+	name        string
+	category    string
+	field       string
+	value       *string
+	points      int // After decay
+	decayed     int // Points removed by decay
+	reason      string
+	executionMs int
+}
+
+// scoreCalculation is the outcome of running the scoring rules against a contact
+type scoreCalculation struct {
+	total          int
+	components     map[string]int // Points per rule category
+	contributions  []scoreContribution
+	lastEngagement time.Time
+	decayFactor    float64
+	decayAmount    int
+}
+
+// factors is the per-rule breakdown kept on the lifecycle record
+func (c *scoreCalculation) factors() models.JSONMap {
+	factors := make(models.JSONMap)
+	for _, contribution := range c.contributions {
+		if contribution.points <= 0 {
+			continue
+		}
+		factor := map[string]interface{}{
+			"score":    contribution.points,
+			"category": contribution.category,
+			"reason":   contribution.reason,
+		}
+		if contribution.decayed > 0 {
+			factor["decayed"] = contribution.decayed
+		}
+		factors[contribution.name] = factor
+	}
+	return factors
+}
+
+// engagementSignals are the contact's latest tracked interactions
+type engagementSignals struct {
+	LastOpenedAt  *time.Time
+	LastClickedAt *time.Time
+	LastInboundAt *time.Time
+}
+
+// calculateScore runs the active scoring rules and the email engagement signal against the contact.
+// Positive points from the decaying categories are discounted by how long ago the contact last
+// engaged; the total is kept within 0..100.
+func (s *LifecycleService) calculateScore(contact *models.Contact) (*scoreCalculation, error) {
+	var rules []models.LeadScoringRule
+	if err := s.db.Where("is_active = ? AND deleted_at IS NULL", true).
+		Order("priority DESC, created_at ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get scoring rules: %v", err)
+	}
+
+	signals := s.engagementSignals(contact)
+	calculation := &scoreCalculation{
+		components:     make(map[string]int),
+		lastEngagement: lastEngagement(contact, signals),
+	}
+	calculation.decayFactor = s.config.Decay.Factor(calculation.lastEngagement, time.Now())
+
+	for i := range rules {
+		rule := &rules[i]
+		started := time.Now()
+		if !s.isRuleApplicable(rule, contact) {
+			continue
+		}
+		points := s.calculateRuleScore(rule, contact)
+		if points == 0 {
+			continue
+		}
+		field, value := s.matchedScoringFields(rule, contact)
+		calculation.add(scoreContribution{
+			rule:        rule,
+			name:        rule.Name,
+			category:    rule.Category,
+			field:       field,
+			value:       value,
+			points:      points,
+			reason:      s.getRuleScoreReason(rule, contact),
+			executionMs: int(time.Since(started).Milliseconds()),
+		}, s.config.Decay)
+	}
+
+	// Built-in email engagement signal from tracked opens and clicks
+	if points, reason := s.emailEngagementScore(contact, signals); points > 0 {
+		value := calculation.lastEngagement.Format(time.RFC3339)
+		calculation.add(scoreContribution{
+			name:     "Email engagement",
+			category: "engagement",
+			field:    "email_engagement",
+			value:    &value,
+			points:   points,
+			reason:   reason,
+		}, s.config.Decay)
+	}
+
+	if calculation.total > 100 {
+		calculation.total = 100
+	}
+	if calculation.total < 0 {
+		calculation.total = 0
+	}
+	return calculation, nil
+}
+
+// add applies decay to the contribution and adds it to the calculation
+func (c *scoreCalculation) add(contribution scoreContribution, decay models.ScoreDecayPolicy) {
+	if contribution.points > 0 && c.decayFactor < 1 && decay.Applies(contribution.category) {
+		kept := int(math.Round(float64(contribution.points) * c.decayFactor))
+		contribution.decayed = contribution.points - kept
+		contribution.points = kept
+		c.decayAmount += contribution.decayed
+	}
+	c.total += contribution.points
+	c.components[contribution.category] += contribution.points
+	c.contributions = append(c.contributions, contribution)
+}
+
+// matchedScoringFields describes the contact fields a rule's matching criteria looked at
+func (s *LifecycleService) matchedScoringFields(rule *models.LeadScoringRule, contact *models.Contact) (string, *string) {
+	fields := make([]string, 0, len(rule.Criteria))
+	values := make([]string, 0, len(rule.Criteria))
+	for _, criteria := range rule.Criteria {
+		if s.evaluateScoringCriteria(criteria, contact) {
+			fields = append(fields, criteria.Field)
+			values = append(values, fmt.Sprintf("%v", s.getContactFieldValue(criteria.Field, contact)))
+		}
+	}
+	if len(fields) == 0 {
+		return "", nil
+	}
+	value := strings.Join(values, ", ")
+	return strings.Join(fields, ","), &value
+}
+
+// engagementSignals reads the contact's latest email opens and clicks and inbound communication
+func (s *LifecycleService) engagementSignals(contact *models.Contact) engagementSignals {
+	var signals engagementSignals
+	if err := s.db.Model(&models.ContactCommunication{}).
+		Select(`MAX(CASE WHEN direction = ? THEN last_opened_at END) AS last_opened_at,
+			MAX(CASE WHEN direction = ? THEN last_clicked_at END) AS last_clicked_at,
+			MAX(CASE WHEN direction = ? THEN created_at END) AS last_inbound_at`,
+			models.DirectionOutbound, models.DirectionOutbound, models.DirectionInbound).
+		Where("contact_id = ? AND deleted_at IS NULL", contact.ID).
+		Scan(&signals).Error; err != nil {
+		logger.Error("Failed to get engagement signals", err, map[string]interface{}{
+			"contact_id": contact.ID,
+		})
+	}
+	return signals
+}
+
+// lastEngagement is when the contact last showed interest, which decay is measured from. The
+// contact's last activity date is not used because every update of the contact touches it.
+func lastEngagement(contact *models.Contact, signals engagementSignals) time.Time {
+	latest := contact.CreatedAt
+	for _, at := range []*time.Time{signals.LastOpenedAt, signals.LastClickedAt, signals.LastInboundAt, contact.LastContactDate} {
+		if at != nil && at.After(latest) {
+			latest = *at
+		}
+	}
+	return latest
+}
+
+// scoreThresholds returns the active score bands, highest first
+func (s *LifecycleService) scoreThresholds() []models.ScoreThreshold {
+	var thresholds []models.ScoreThreshold
+	if err := s.db.Where("is_active = ?", true).Order("min_score DESC").Find(&thresholds).Error; err != nil {
+		logger.Error("Failed to get score thresholds", err, nil)
+	}
+	return thresholds
+}
+
+// scoreGrade returns the grade and category of the band covering the score, or empty strings when no
+// active band covers it
+func (s *LifecycleService) scoreGrade(score int) (string, string) {
+	band := models.MatchScoreThreshold(s.scoreThresholds(), score)
+	if band == nil {
+		return "", ""
+	}
+	return band.Grade, band.Category
+}
+
+// recordScoreCalculation stores the calculation in contact_scores and its per-rule contributions
+// in score_calculation_log
+func (s *LifecycleService) recordScoreCalculation(contact *models.Contact, calculation *scoreCalculation, previousScore int, band *models.ScoreThreshold, batchID, reason string) error {
+	rulesApplied := make(models.JSONArray, 0, len(calculation.contributions))
+	logs := make([]models.ScoreCalculationLog, 0, len(calculation.contributions))
+	running := 0
+	for _, contribution := range calculation.contributions {
+		var ruleID *uint
+		if contribution.rule != nil {
+			ruleID = &contribution.rule.ID
+		}
+		rulesApplied = append(rulesApplied, contribution.name)
+
+		metadata := models.JSONMap{
+			"category": contribution.category,
+			"reason":   contribution.reason,
+		}
+		if contribution.decayed > 0 {
+			metadata["decayed"] = contribution.decayed
+		}
+		logs = append(logs, models.ScoreCalculationLog{
+			ContactID:           contact.ID,
+			RuleID:              ruleID,
+			RuleName:            contribution.name,
+			FieldEvaluated:      contribution.field,
+			FieldValue:          contribution.value,
+			ScoreChange:         contribution.points,
+			PreviousScore:       running,
+			NewScore:            running + contribution.points,
+			CalculationBatchID:  batchID,
+			ExecutionTimeMs:     contribution.executionMs,
+			CalculationMetadata: metadata,
+		})
+		running += contribution.points
+	}
+	if len(logs) > 0 {
+		if err := s.db.Create(&logs).Error; err != nil {
+			return fmt.Errorf("failed to record score calculation log: %v", err)
+		}
+	}
+
+	lastEngagement := calculation.lastEngagement
+	score := models.ContactScore{
+		ContactID:         contact.ID,
+		CurrentScore:      calculation.total,
+		PreviousScore:     previousScore,
+		ScoreChange:       calculation.total - previousScore,
+		DemographicScore:  calculation.components["demographic"],
+		BehavioralScore:   calculation.components["behavioral"],
+		EngagementScore:   calculation.components["engagement"],
+		FirmographicScore: calculation.components["firmographic"],
+		LifecycleScore:    calculation.components["lifecycle"],
+		CalculationDate:   time.Now(),
+		RulesApplied:      rulesApplied,
+		CalculationMetadata: models.JSONMap{
+			"batch_id":     batchID,
+			"reason":       reason,
+			"decay_factor": calculation.decayFactor,
+			"uncapped":     running,
+		},
+		DecayApplied:     calculation.decayAmount > 0,
+		DecayAmount:      calculation.decayAmount,
+		LastActivityDate: &lastEngagement,
+	}
+	if band != nil {
+		score.ScoreGrade = &band.Grade
+		score.ScoreCategory = &band.Category
+	}
+	if err := s.db.Create(&score).Error; err != nil {
+		return fmt.Errorf("failed to record contact score: %v", err)
+	}
+	return nil
+}
+
+// runScoreBandAutomation fires the auto-assignment and notification rules of the band the contact
+// moved into and returns what they did. It must be called on a transaction-scoped service; a failing
+// rule is rolled back to a savepoint and recorded without failing the score update.
+func (s *LifecycleService) runScoreBandAutomation(contact *models.Contact, from, to *models.ScoreThreshold, scoredBy *uint) models.JSONMap {
+	outcome := models.JSONMap{
+		"to_band": to.Name,
+		"grade":   to.Grade,
+	}
+	if from != nil {
+		outcome["from_band"] = from.Name
+	}
+	upgrade := from == nil || to.MinScore > from.MinScore
+
+	assignmentRules, notificationRules, err := to.Automation()
+	if err != nil {
+		outcome["error"] = fmt.Sprintf("score threshold %d has %v", to.ID, err)
+		return outcome
+	}
+
+	if assignmentRules != nil && (upgrade || !assignmentRules.UpgradesOnly) {
+		result := models.TransitionActionResult{Status: models.TransitionActionSucceeded}
+		if err := s.db.SavePoint("score_band_assignment").Error; err != nil {
+			result.Status, result.Error = models.TransitionActionFailed, err.Error()
+		} else if status, detail, err := s.assignForScoreBand(assignmentRules, contact, to, scoredBy); err != nil {
+			s.db.RollbackTo("score_band_assignment")
+			result.Status, result.Error = models.TransitionActionFailed, err.Error()
+		} else {
+			result.Status, result.Detail = status, detail
+		}
+		outcome["assignment"] = result
+	}
+
+	if notificationRules != nil && (upgrade || !notificationRules.UpgradesOnly) {
+		userIDs := append([]uint(nil), notificationRules.Users...)
+		if notificationRules.Assignee && contact.AssignedTo != nil {
+			userIDs = append(userIDs, *contact.AssignedTo)
+		}
+		entityType := "contact"
+		notified, err := notifyUsers(s.db, userIDs, models.Notification{
+			Type:       models.NotificationScoreBand,
+			Title:      fmt.Sprintf("%s is now %s", contact.GetFullName(), to.Name),
+			Body:       fmt.Sprintf("%s's lead score moved into %s (grade %s).", contact.GetFullName(), to.Name, to.Grade),
+			EntityType: &entityType,
+			EntityID:   &contact.ID,
+			Data: models.JSONMap{
+				"threshold_id": to.ID,
+				"grade":        to.Grade,
+				"category":     to.Category,
+				"score":        contact.LeadScore,
+			},
+		})
+		if err != nil {
+			outcome["notification_error"] = err.Error()
+		} else {
+			outcome["notified_users"] = notified
+		}
+	}
+	return outcome
+}
+
+// assignForScoreBand applies a band's assignment rules to the contact
+func (s *LifecycleService) assignForScoreBand(rules *models.ScoreBandAssignment, contact *models.Contact, band *models.ScoreThreshold, scoredBy *uint) (string, string, error) {
+	if contact.AssignedTo != nil && !rules.Reassign {
+		return models.TransitionActionSkipped, fmt.Sprintf("already assigned to user %d", *contact.AssignedTo), nil
+	}
+
+	assignment := &AssignmentService{db: s.db}
+	reason := fmt.Sprintf("Lead score reached %s", band.Name)
+	var assigneeID uint
+	var assignmentRule *models.AssignmentRule
+
+	switch {
+	case rules.UserID != 0:
+		assigneeID = rules.UserID
+	case rules.AssignmentRuleID != 0:
+		assignmentRule = &models.AssignmentRule{}
+		if err := s.db.Where("id = ? AND status = ? AND deleted_at IS NULL", rules.AssignmentRuleID, models.AssignmentRuleActive).
+			First(assignmentRule).Error; err != nil {
+			return "", "", fmt.Errorf("assignment rule %d not found or inactive", rules.AssignmentRuleID)
+		}
+	default:
+		var candidates []models.AssignmentRule
+		if err := s.db.Where("status = ? AND deleted_at IS NULL", models.AssignmentRuleActive).
+			Order("priority DESC, created_at ASC").Find(&candidates).Error; err != nil {
+			return "", "", fmt.Errorf("failed to get assignment rules: %v", err)
+		}
+		for i := range candidates {
+			if assignment.evaluateRule(&candidates[i], contact, nil) {
+				assignmentRule = &candidates[i]
+				break
+			}
+		}
+		if assignmentRule == nil {
+			return models.TransitionActionSkipped, "no assignment rule matched", nil
+		}
+	}
+
+	var ruleID *uint
+	if assignmentRule != nil {
+		selected, err := assignment.selectAssignee(assignmentRule, contact)
+		if err != nil {
+			return "", "", fmt.Errorf("assignment rule %d selected no assignee: %v", assignmentRule.ID, err)
+		}
+		assigneeID, ruleID = selected, &assignmentRule.ID
+		reason = fmt.Sprintf("%s (assignment rule: %s)", reason, assignmentRule.Name)
+	}
+
+	assigned, err := assignment.assignTo(contact, assigneeID, scoredBy, ruleID, "automatic", reason)
+	if err != nil {
+		return "", "", err
+	}
+	if !assigned {
+		return models.TransitionActionSkipped, fmt.Sprintf("already assigned to user %d", assigneeID), nil
+	}
+	if assignmentRule != nil {
+		assignment.updateRuleStatistics(assignmentRule)
+	}
+	return models.TransitionActionSucceeded, fmt.Sprintf("assigned to user %d", assigneeID), nil
+}
+
+// bandName names a score band for event descriptions
+func bandName(band *models.ScoreThreshold) string {
+	if band == nil {
+		return "no band"
+	}
+	return band.Name
+}
+
+// scoreBandChanged reports whether a contact moved from one band to another
+func scoreBandChanged(from, to *models.ScoreThreshold) bool {
+	if from == nil || to == nil {
+		return from != to
+	}
+	return from.ID != to.ID
+}
+
+// withDB returns a copy of the service bound to a transaction
+func (s *LifecycleService) withDB(db *gorm.DB) *LifecycleService {
+	return &LifecycleService{db: db, scheduling: s.scheduling, config: s.config}
+}
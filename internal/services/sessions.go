@@ -0,0 +1,295 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/auth"
+	"contact-service/pkg/logger"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SessionConfig configures server-side sessions
+type SessionConfig struct {
+	IdleTimeout      time.Duration // Sessions without requests for this long are ended
+	ActivityInterval time.Duration // How stale last_activity may get before a request records it
+}
+
+// LoadSessionConfig reads the session configuration from the environment
+func LoadSessionConfig() SessionConfig {
+	config := SessionConfig{
+		IdleTimeout:      120 * time.Minute,
+		ActivityInterval: time.Minute,
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("SESSION_IDLE_TIMEOUT_MINUTES")); err == nil && minutes > 0 {
+		config.IdleTimeout = time.Duration(minutes) * time.Minute
+	}
+	if seconds, err := strconv.Atoi(os.Getenv("SESSION_ACTIVITY_INTERVAL_SECONDS")); err == nil && seconds >= 0 {
+		config.ActivityInterval = time.Duration(seconds) * time.Second
+	}
+	return config
+}
+
+// SessionService keeps the user_sessions tokens are bound to. Access tokens are accepted while their
+// session is active and not idle; refresh tokens are single use, and presenting one that was already
+// exchanged ends the session, since either the client or someone who stole the token is replaying it.
+type SessionService struct {
+	db     *gorm.DB
+	config SessionConfig
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(db *gorm.DB, config SessionConfig) *SessionService {
+	return &SessionService{
+		db:     db,
+		config: config,
+	}
+}
+
+// CreateSession opens a session for a user who just authenticated and issues its tokens;
+// mfaVerified records that the login included a two-factor code
+func (s *SessionService) CreateSession(user *models.AdminUser, ipAddress, userAgent string, mfaVerified bool) (*auth.TokenPair, *models.UserSession, error) {
+	now := time.Now()
+	refreshID := uuid.New().String()
+	expiresAt := now.Add(auth.RefreshTokenTTL())
+
+	session := &models.UserSession{
+		UserID:         user.ID,
+		SessionToken:   uuid.New().String(),
+		RefreshTokenID: &refreshID,
+		LoginAt:        now,
+		LastActivity:   now,
+		ExpiresAt:      &expiresAt,
+		IsActive:       true,
+		MFAVerified:    mfaVerified,
+	}
+	if ipAddress != "" {
+		session.IPAddress = &ipAddress
+	}
+	if userAgent != "" {
+		session.BrowserInfo = &userAgent
+	}
+
+	tokens, err := auth.GenerateTokenPair(user.ID, user.Email, user.Role, session.SessionToken, refreshID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.db.Create(session).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to create session: %v", err)
+	}
+
+	return tokens, session, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token is spent: the session
+// only accepts the refresh token issued with the new pair.
+func (s *SessionService) Refresh(refreshToken, ipAddress string) (*auth.TokenPair, error) {
+	claims, err := auth.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.SessionID == "" || claims.ID == "" {
+		return nil, fmt.Errorf("invalid refresh token: no session")
+	}
+
+	var session models.UserSession
+	if err := s.db.Where("session_token = ? AND user_id = ?", claims.SessionID, claims.UserID).
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("session not found")
+		}
+		return nil, fmt.Errorf("failed to get session: %v", err)
+	}
+	if !session.IsActive {
+		return nil, fmt.Errorf("session has been revoked")
+	}
+
+	now := time.Now()
+	if session.RefreshTokenID == nil || *session.RefreshTokenID != claims.ID {
+		return nil, s.refreshTokenReused(&session, claims, ipAddress)
+	}
+	if session.ExpiresAt != nil && now.After(*session.ExpiresAt) {
+		s.revoke(s.db.Where("id = ?", session.ID), models.SessionRevokedExpired)
+		return nil, fmt.Errorf("session has expired")
+	}
+	if s.idle(&session, now) {
+		s.revoke(s.db.Where("id = ?", session.ID), models.SessionRevokedIdle)
+		return nil, fmt.Errorf("session expired due to inactivity")
+	}
+
+	// Tokens carry the user's current role and email, so changes apply from the next refresh
+	var user models.AdminUser
+	if err := s.db.Where("id = ? AND is_active = ?", claims.UserID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.revoke(s.db.Where("id = ?", session.ID), models.SessionRevokedByAdmin)
+			return nil, fmt.Errorf("user not found or inactive")
+		}
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+
+	refreshID := uuid.New().String()
+	expiresAt := now.Add(auth.RefreshTokenTTL())
+	tokens, err := auth.GenerateTokenPair(user.ID, user.Email, user.Role, session.SessionToken, refreshID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only one exchange of the presented token can win; a concurrent one is a replay
+	result := s.db.Model(&models.UserSession{}).
+		Where("id = ? AND refresh_token_id = ? AND is_active = ?", session.ID, claims.ID, true).
+		Updates(map[string]interface{}{
+			"refresh_token_id": refreshID,
+			"refreshed_at":     now,
+			"expires_at":       expiresAt,
+			"last_activity":    now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, s.refreshTokenReused(&session, claims, ipAddress)
+	}
+
+	return tokens, nil
+}
+
+// refreshTokenReused ends a session whose spent refresh token was presented again
+func (s *SessionService) refreshTokenReused(session *models.UserSession, claims *auth.JWTClaims, ipAddress string) error {
+	s.revoke(s.db.Where("id = ?", session.ID), models.SessionRevokedRefreshReuse)
+	logger.LogSecurityEvent("refresh_token_reuse", &session.UserID, ipAddress, map[string]interface{}{
+		"session_id":       session.ID,
+		"refresh_token_id": claims.ID,
+	})
+	return fmt.Errorf("refresh token has already been used; session revoked")
+}
+
+// ValidateSession checks that an access token's session is still active and records activity on it
+func (s *SessionService) ValidateSession(claims *auth.JWTClaims) (*models.UserSession, error) {
+	if claims.ID == "" {
+		return nil, fmt.Errorf("token is not bound to a session")
+	}
+
+	var session models.UserSession
+	if err := s.db.Where("session_token = ? AND user_id = ?", claims.ID, claims.UserID).
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("session not found")
+		}
+		return nil, fmt.Errorf("failed to get session: %v", err)
+	}
+	if !session.IsActive {
+		return nil, fmt.Errorf("session has been revoked")
+	}
+
+	now := time.Now()
+	if s.idle(&session, now) {
+		s.revoke(s.db.Where("id = ?", session.ID), models.SessionRevokedIdle)
+		return nil, fmt.Errorf("session expired due to inactivity")
+	}
+
+	// Writing on every request would make each one a write; a little staleness is fine
+	if now.Sub(session.LastActivity) >= s.config.ActivityInterval {
+		if err := s.db.Model(&models.UserSession{}).Where("id = ?", session.ID).
+			Updates(map[string]interface{}{
+				"last_activity": now,
+				"page_views":    gorm.Expr("page_views + 1"),
+			}).Error; err != nil {
+			logger.Error("Failed to record session activity", err, map[string]interface{}{
+				"session_id": session.ID,
+			})
+		}
+		session.LastActivity = now
+	}
+
+	return &session, nil
+}
+
+func (s *SessionService) idle(session *models.UserSession, now time.Time) bool {
+	return s.config.IdleTimeout > 0 && now.Sub(session.LastActivity) > s.config.IdleTimeout
+}
+
+// ListSessions returns a user's active sessions, most recently used first, marking the one the
+// current access token belongs to
+func (s *SessionService) ListSessions(userID uint, currentToken string) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	if err := s.db.Where("user_id = ? AND is_active = ?", userID, true).
+		Order("last_activity DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %v", err)
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].SessionToken == currentToken
+	}
+	return sessions, nil
+}
+
+// RevokeSession ends one of a user's sessions
+func (s *SessionService) RevokeSession(userID, sessionID uint, reason string) error {
+	revoked, err := s.revoke(s.db.Where("id = ? AND user_id = ?", sessionID, userID), reason)
+	if err != nil {
+		return err
+	}
+	if revoked == 0 {
+		return fmt.Errorf("session not found")
+	}
+	return nil
+}
+
+// RevokeToken ends the session an access token belongs to
+func (s *SessionService) RevokeToken(claims *auth.JWTClaims, reason string) error {
+	_, err := s.revoke(s.db.Where("session_token = ? AND user_id = ?", claims.ID, claims.UserID), reason)
+	return err
+}
+
+// MarkMFAVerified records that the user completed two-factor authentication in the session an
+// access token belongs to
+func (s *SessionService) MarkMFAVerified(claims *auth.JWTClaims) error {
+	if err := s.db.Model(&models.UserSession{}).
+		Where("session_token = ? AND user_id = ? AND is_active = ?", claims.ID, claims.UserID, true).
+		Update("mfa_verified", true).Error; err != nil {
+		return fmt.Errorf("failed to update session: %v", err)
+	}
+	return nil
+}
+
+// RevokeOtherSessions ends every active session of a user except the one an access token belongs to
+func (s *SessionService) RevokeOtherSessions(claims *auth.JWTClaims, reason string) (int64, error) {
+	return s.revoke(s.db.Where("user_id = ? AND session_token <> ?", claims.UserID, claims.ID), reason)
+}
+
+// RevokeAllSessions ends every active session of a user and returns how many were ended
+func (s *SessionService) RevokeAllSessions(userID uint, reason string) (int64, error) {
+	var user models.AdminUser
+	if err := s.db.Select("id").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("user not found")
+		}
+		return 0, fmt.Errorf("failed to get user: %v", err)
+	}
+
+	return s.revoke(s.db.Where("user_id = ?", userID), reason)
+}
+
+// revoke ends the active sessions matched by query
+func (s *SessionService) revoke(query *gorm.DB, reason string) (int64, error) {
+	now := time.Now()
+	result := query.Model(&models.UserSession{}).
+		Where("is_active = ?", true).
+		Updates(map[string]interface{}{
+			"is_active":        false,
+			"logout_at":        now,
+			"revoked_reason":   reason,
+			"refresh_token_id": nil,
+			"session_duration": gorm.Expr("TIMESTAMPDIFF(SECOND, login_at, ?)", now),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %v", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/logger"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxReportedTransitions caps the transitions listed in a sweep report
+const maxReportedTransitions = 500
+
+// statusEnteredAtSQL is when a contact entered its current status: the last recorded status change,
+// else the lifecycle record if it agrees with the contact, else the contact's creation
+const statusEnteredAtSQL = `COALESCE(
+	(SELECT MAX(h.created_at) FROM contact_field_history h WHERE h.contact_id = contacts.id AND h.field_name = 'status'),
+	(SELECT l.status_entered_at FROM contact_lifecycles l WHERE l.contact_id = contacts.id AND l.current_status = contacts.status),
+	contacts.created_at)`
+
+// StatusTransitionSweeperConfig configures the scheduled status transition sweeper
+type StatusTransitionSweeperConfig struct {
+	Interval  time.Duration // How often scheduled rules are evaluated
+	BatchSize int           // Contacts loaded per query
+}
+
+// LoadStatusTransitionSweeperConfig reads the sweeper configuration from the environment
+func LoadStatusTransitionSweeperConfig() StatusTransitionSweeperConfig {
+	config := StatusTransitionSweeperConfig{
+		Interval:  time.Hour,
+		BatchSize: 200,
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("STATUS_TRANSITION_SWEEP_MINUTES")); err == nil && minutes > 0 {
+		config.Interval = time.Duration(minutes) * time.Minute
+	}
+	if size, err := strconv.Atoi(os.Getenv("STATUS_TRANSITION_BATCH_SIZE")); err == nil && size > 0 {
+		config.BatchSize = size
+	}
+	return config
+}
+
+// StatusTransitionSweeper applies scheduled StatusTransitionRules (transition_type "scheduled") to
+// every contact that has been in the rule's from-status for at least DaysInStatus days. Transitions go
+// through the lifecycle service's conditional status update, so replicas sweeping at the same time
+// apply each transition once and skip the contacts another replica already moved.
+type StatusTransitionSweeper struct {
+	db        *gorm.DB
+	lifecycle *LifecycleService
+	config    StatusTransitionSweeperConfig
+	startOnce sync.Once
+}
+
+// NewStatusTransitionSweeper creates a status transition sweeper
+func NewStatusTransitionSweeper(db *gorm.DB, lifecycle *LifecycleService, config StatusTransitionSweeperConfig) *StatusTransitionSweeper {
+	return &StatusTransitionSweeper{db: db, lifecycle: lifecycle, config: config}
+}
+
+// Start launches the periodic sweep. Calling it again has no effect.
+func (s *StatusTransitionSweeper) Start() {
+	s.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(s.config.Interval)
+			defer ticker.Stop()
+			for {
+				if _, err := s.Sweep(false); err != nil {
+					logger.Error("Status transition sweep failed", err, nil)
+				}
+				<-ticker.C
+			}
+		}()
+
+		logger.Info("Status transition sweeper started", map[string]interface{}{
+			"interval":   s.config.Interval.String(),
+			"batch_size": s.config.BatchSize,
+		})
+	})
+}
+
+// Sweep evaluates every active scheduled rule, highest priority first, against the contacts in its
+// from-status. Each contact moves at most once per sweep. A dry run reports the transitions without
+// applying them.
+func (s *StatusTransitionSweeper) Sweep(dryRun bool) (*models.TransitionSweepReport, error) {
+	report := &models.TransitionSweepReport{DryRun: dryRun, StartedAt: time.Now(), Transitions: []models.PlannedTransition{}}
+
+	var rules []models.StatusTransitionRule
+	if err := s.db.Where("is_active = ? AND transition_type = ? AND deleted_at IS NULL", true, models.TransitionScheduled).
+		Order("priority DESC, id ASC").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get scheduled transition rules: %v", err)
+	}
+
+	moved := make(map[uint]bool)
+	for i := range rules {
+		if err := s.sweepRule(&rules[i], dryRun, moved, report); err != nil {
+			return nil, err
+		}
+		report.RulesEvaluated++
+	}
+	report.FinishedAt = time.Now()
+
+	if report.Matched > 0 || report.Failed > 0 {
+		logger.Info("Status transition sweep completed", map[string]interface{}{
+			"dry_run":  dryRun,
+			"rules":    report.RulesEvaluated,
+			"scanned":  report.ContactsScanned,
+			"matched":  report.Matched,
+			"applied":  report.Applied,
+			"skipped":  report.Skipped,
+			"failed":   report.Failed,
+			"duration": report.FinishedAt.Sub(report.StartedAt).String(),
+		})
+	}
+	return report, nil
+}
+
+// sweepRule walks the rule's eligible contacts in id order, one batch at a time
+func (s *StatusTransitionSweeper) sweepRule(rule *models.StatusTransitionRule, dryRun bool, moved map[uint]bool, report *models.TransitionSweepReport) error {
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -rule.DaysInStatus)
+	var lastID uint
+	for {
+		query := s.db.Where("status = ? AND deleted_at IS NULL AND id > ?", rule.FromStatus, lastID).
+			Where(statusEnteredAtSQL+" <= ?", cutoff)
+		if rule.RequiredScore > 0 {
+			query = query.Where("lead_score >= ?", rule.RequiredScore)
+		}
+		var contacts []models.Contact
+		if err := query.Order("id ASC").Limit(s.config.BatchSize).Find(&contacts).Error; err != nil {
+			return fmt.Errorf("failed to get contacts for rule %d: %v", rule.ID, err)
+		}
+		if len(contacts) == 0 {
+			return nil
+		}
+		lastID = contacts[len(contacts)-1].ID
+		report.ContactsScanned += len(contacts)
+
+		entered, err := s.statusEnteredAt(contacts)
+		if err != nil {
+			return err
+		}
+
+		for i := range contacts {
+			contact := &contacts[i]
+			if moved[contact.ID] {
+				continue
+			}
+			days := int(now.Sub(entered[contact.ID]).Hours() / 24)
+			lifecycle := &models.ContactLifecycle{CurrentScore: contact.LeadScore, DaysInCurrentStatus: days}
+			if !s.lifecycle.shouldTriggerTransition(rule, contact, lifecycle) {
+				continue
+			}
+
+			report.Matched++
+			moved[contact.ID] = true
+			planned := models.PlannedTransition{
+				ContactID:    contact.ID,
+				RuleID:       rule.ID,
+				RuleName:     rule.Name,
+				FromStatus:   rule.FromStatus,
+				ToStatus:     rule.ToStatus,
+				DaysInStatus: days,
+			}
+			if !dryRun {
+				if err := s.apply(rule, contact, days); err != nil {
+					if strings.Contains(err.Error(), "concurrently") {
+						report.Skipped++
+						continue
+					}
+					report.Failed++
+					planned.Error = err.Error()
+				} else {
+					report.Applied++
+				}
+			}
+			if len(report.Transitions) < maxReportedTransitions {
+				report.Transitions = append(report.Transitions, planned)
+			} else {
+				report.Truncated = true
+			}
+		}
+
+		if len(contacts) < s.config.BatchSize {
+			return nil
+		}
+	}
+}
+
+// statusEnteredAt returns when each contact entered its current status, resolved the same way as
+// statusEnteredAtSQL. The sources are read as typed columns rather than as the computed expression,
+// whose type not every driver reports.
+func (s *StatusTransitionSweeper) statusEnteredAt(contacts []models.Contact) (map[uint]time.Time, error) {
+	ids := make([]uint, len(contacts))
+	for i := range contacts {
+		ids[i] = contacts[i].ID
+	}
+
+	entered := make(map[uint]time.Time, len(contacts))
+	var changes []models.ContactFieldHistory
+	if err := s.db.Select("contact_id, created_at").Where("contact_id IN ? AND field_name = ?", ids, "status").
+		Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get status entry times: %v", err)
+	}
+	for _, change := range changes {
+		if change.CreatedAt.After(entered[change.ContactID]) {
+			entered[change.ContactID] = change.CreatedAt
+		}
+	}
+
+	var lifecycles []models.ContactLifecycle
+	if err := s.db.Select("contact_id, current_status, status_entered_at").Where("contact_id IN ?", ids).
+		Find(&lifecycles).Error; err != nil {
+		return nil, fmt.Errorf("failed to get status entry times: %v", err)
+	}
+	lifecycleEntered := make(map[uint]models.ContactLifecycle, len(lifecycles))
+	for _, lifecycle := range lifecycles {
+		lifecycleEntered[lifecycle.ContactID] = lifecycle
+	}
+
+	for i := range contacts {
+		contact := &contacts[i]
+		if _, ok := entered[contact.ID]; ok {
+			continue
+		}
+		if lifecycle, ok := lifecycleEntered[contact.ID]; ok && lifecycle.CurrentStatus == contact.Status {
+			entered[contact.ID] = lifecycle.StatusEnteredAt
+		} else {
+			entered[contact.ID] = contact.CreatedAt
+		}
+	}
+	return entered, nil
+}
+
+// apply moves a contact through the lifecycle service, which runs the rule's actions and counts the
+// trigger on the rule. The rule is an explicit administrator decision, so the default transition map
+// is not consulted.
+func (s *StatusTransitionSweeper) apply(rule *models.StatusTransitionRule, contact *models.Contact, days int) error {
+	return s.lifecycle.changeContactStatus(&models.StatusChangeRequest{
+		ContactID:   contact.ID,
+		NewStatus:   rule.ToStatus,
+		Reason:      fmt.Sprintf("%d days in %s (rule: %s)", days, rule.FromStatus, rule.Name),
+		ForceChange: true,
+	}, rule.FromStatus, nil, string(models.TransitionScheduled), fmt.Sprintf("rule:%d", rule.ID), rule)
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"contact-service/internal/models"
+)
+
+// newTransitionTestSweeper returns a status transition sweeper on an in-memory database
+func newTransitionTestSweeper(t *testing.T) (*StatusTransitionSweeper, *gorm.DB) {
+	db := newTestDB(t,
+		&models.ContactType{},
+		&models.ContactSource{},
+		&models.Contact{},
+		&models.ContactFieldHistory{},
+		&models.ContactLifecycle{},
+		&models.LifecycleEvent{},
+		&models.StatusTransitionRule{},
+	)
+	sweeper := NewStatusTransitionSweeper(db, NewLifecycleService(db), StatusTransitionSweeperConfig{
+		Interval:  time.Hour,
+		BatchSize: 2,
+	})
+	return sweeper, db
+}
+
+// createTransitionTestContact creates a contact that has been in status for days days
+func createTransitionTestContact(t *testing.T, db *gorm.DB, status models.ContactStatus, days, score int) *models.Contact {
+	contact := &models.Contact{
+		FirstName:       "Asha",
+		Email:           "asha@example.com",
+		ContactTypeID:   1,
+		ContactSourceID: 1,
+		Status:          status,
+		LeadScore:       score,
+		CreatedAt:       time.Now().AddDate(0, 0, -days).Add(-time.Hour),
+	}
+	require.NoError(t, db.Create(contact).Error)
+	return contact
+}
+
+// createTransitionTestRule creates an active scheduled rule moving contacts from one status to another
+func createTransitionTestRule(t *testing.T, db *gorm.DB, name string, from, to models.ContactStatus, days, score, priority int) *models.StatusTransitionRule {
+	rule := &models.StatusTransitionRule{
+		Name:           name,
+		IsActive:       true,
+		Priority:       priority,
+		FromStatus:     from,
+		ToStatus:       to,
+		TransitionType: models.TransitionScheduled,
+		RequiredScore:  score,
+		DaysInStatus:   days,
+	}
+	require.NoError(t, db.Create(rule).Error)
+	return rule
+}
+
+func contactStatus(t *testing.T, db *gorm.DB, id uint) models.ContactStatus {
+	var contact models.Contact
+	require.NoError(t, db.First(&contact, id).Error)
+	return contact.Status
+}
+
+func TestSweepMatchesContactsByStatusAgeAndScore(t *testing.T) {
+	sweeper, db := newTransitionTestSweeper(t)
+	rule := createTransitionTestRule(t, db, "Stale new leads", models.StatusNew, models.StatusContacted, 7, 50, 0)
+
+	due := createTransitionTestContact(t, db, models.StatusNew, 10, 60)
+	recent := createTransitionTestContact(t, db, models.StatusNew, 3, 60)
+	lowScore := createTransitionTestContact(t, db, models.StatusNew, 10, 10)
+	otherStatus := createTransitionTestContact(t, db, models.StatusQualified, 10, 60)
+	deleted := createTransitionTestContact(t, db, models.StatusNew, 10, 60)
+	require.NoError(t, db.Model(deleted).Update("deleted_at", time.Now()).Error)
+
+	// Created long ago, but its status was last changed two days ago
+	reentered := createTransitionTestContact(t, db, models.StatusNew, 30, 60)
+	require.NoError(t, db.Create(&models.ContactFieldHistory{
+		ContactID:    reentered.ID,
+		FieldName:    "status",
+		ChangeType:   models.FieldChangeUpdate,
+		ChangeSource: models.ChangeSourceLifecycle,
+		CreatedAt:    time.Now().AddDate(0, 0, -2),
+	}).Error)
+
+	report, err := sweeper.Sweep(false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.RulesEvaluated)
+	assert.Equal(t, 1, report.Matched)
+	assert.Equal(t, 1, report.Applied)
+	assert.Zero(t, report.Skipped)
+	assert.Zero(t, report.Failed)
+	require.Len(t, report.Transitions, 1)
+	assert.Equal(t, due.ID, report.Transitions[0].ContactID)
+	assert.Empty(t, report.Transitions[0].Error)
+
+	assert.Equal(t, models.StatusContacted, contactStatus(t, db, due.ID))
+	for _, contact := range []*models.Contact{recent, lowScore, deleted, reentered} {
+		assert.Equal(t, models.StatusNew, contactStatus(t, db, contact.ID), "contact %d moved", contact.ID)
+	}
+	assert.Equal(t, models.StatusQualified, contactStatus(t, db, otherStatus.ID))
+
+	var event models.LifecycleEvent
+	require.NoError(t, db.Where("contact_id = ?", due.ID).First(&event).Error)
+	assert.Equal(t, string(models.TransitionScheduled), event.TriggerType)
+	require.NoError(t, db.First(rule, rule.ID).Error)
+	assert.Equal(t, 1, rule.TimesTriggered)
+}
+
+func TestSweepDryRunReportsWithoutApplying(t *testing.T) {
+	sweeper, db := newTransitionTestSweeper(t)
+	urgent := createTransitionTestRule(t, db, "Qualify engaged leads", models.StatusNew, models.StatusQualified, 5, 0, 10)
+	general := createTransitionTestRule(t, db, "Follow up new leads", models.StatusNew, models.StatusContacted, 5, 0, 0)
+
+	// Spans several batches, so every contact is reported once, by the highest priority rule
+	contacts := make([]*models.Contact, 0, 3)
+	for i := 0; i < 3; i++ {
+		contacts = append(contacts, createTransitionTestContact(t, db, models.StatusNew, 8, 0))
+	}
+
+	report, err := sweeper.Sweep(true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 2, report.RulesEvaluated)
+	assert.Equal(t, 3, report.Matched)
+	assert.Zero(t, report.Applied)
+	require.Len(t, report.Transitions, 3)
+	for i, planned := range report.Transitions {
+		assert.Equal(t, contacts[i].ID, planned.ContactID)
+		assert.Equal(t, urgent.ID, planned.RuleID)
+		assert.Equal(t, urgent.Name, planned.RuleName)
+		assert.Equal(t, models.StatusNew, planned.FromStatus)
+		assert.Equal(t, models.StatusQualified, planned.ToStatus)
+		assert.Equal(t, 8, planned.DaysInStatus)
+	}
+
+	for _, contact := range contacts {
+		assert.Equal(t, models.StatusNew, contactStatus(t, db, contact.ID))
+	}
+	var events int64
+	require.NoError(t, db.Model(&models.LifecycleEvent{}).Count(&events).Error)
+	assert.Zero(t, events)
+	for _, rule := range []*models.StatusTransitionRule{urgent, general} {
+		require.NoError(t, db.First(rule, rule.ID).Error)
+		assert.Zero(t, rule.TimesTriggered)
+	}
+}
+
+func TestSweepSkipsContactsChangedConcurrently(t *testing.T) {
+	sweeper, db := newTransitionTestSweeper(t)
+	rule := createTransitionTestRule(t, db, "Stale new leads", models.StatusNew, models.StatusContacted, 7, 0, 0)
+	contact := createTransitionTestContact(t, db, models.StatusNew, 10, 0)
+
+	// Another replica moves the contact between the sweeper reading it and its conditional update. The
+	// test database has one connection, so the change is made in, and rolled back with, the sweeper's
+	// transaction.
+	moved := false
+	require.NoError(t, db.Callback().Update().Before("gorm:update").Register("test:concurrent_change", func(tx *gorm.DB) {
+		if moved || tx.Statement.Table != "contacts" {
+			return
+		}
+		moved = true
+		tx.AddError(tx.Session(&gorm.Session{NewDB: true}).
+			Exec("UPDATE contacts SET status = ? WHERE id = ?", models.StatusQualified, contact.ID).Error)
+	}))
+
+	report, err := sweeper.Sweep(false)
+	require.NoError(t, err)
+	require.True(t, moved)
+	assert.Equal(t, 1, report.Matched)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Zero(t, report.Applied)
+	assert.Zero(t, report.Failed)
+	assert.Empty(t, report.Transitions)
+
+	var events int64
+	require.NoError(t, db.Model(&models.LifecycleEvent{}).Count(&events).Error)
+	assert.Zero(t, events)
+	require.NoError(t, db.First(rule, rule.ID).Error)
+	assert.Zero(t, rule.TimesTriggered)
+}
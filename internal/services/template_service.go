@@ -0,0 +1,411 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/logger"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Display layouts used when template variables are resolved from dates
+const (
+	templateDateLayout     = "January 2, 2006"
+	templateDateTimeLayout = "January 2, 2006 15:04 MST"
+)
+
+// Variables resolved from the appointment passed to a render
+var appointmentTemplateVariables = map[string]string{
+	"appointment.title":            "Appointment title",
+	"appointment.description":      "Appointment description",
+	"appointment.type":             "Appointment type (consultation, demo, ...)",
+	"appointment.scheduled_date":   "Scheduled date (YYYY-MM-DD)",
+	"appointment.scheduled_time":   "Scheduled time (HH:MM)",
+	"appointment.timezone":         "Appointment timezone",
+	"appointment.duration_minutes": "Duration in minutes",
+	"appointment.status":           "Appointment status",
+	"appointment.meeting_type":     "Meeting type (video_call, phone_call, in_person, ...)",
+	"appointment.location":         "Meeting location",
+	"appointment.meeting_link":     "Video meeting link",
+	"appointment.meeting_id":       "Video meeting ID",
+	"appointment.meeting_password": "Video meeting password",
+	"appointment.phone_number":     "Dial-in phone number",
+}
+
+// Variables resolved from the admin user the message is sent as
+var senderTemplateVariables = map[string]string{
+	"sender.name":       "Sender name",
+	"sender.email":      "Sender email",
+	"sender.phone":      "Sender phone",
+	"sender.job_title":  "Sender job title",
+	"sender.department": "Sender department",
+	"sender.location":   "Sender location",
+}
+
+// Variables resolved at render time
+var systemTemplateVariables = map[string]string{
+	"current_date": "Date of rendering",
+	"current_year": "Year of rendering",
+}
+
+// TemplateService manages communication templates and renders them for contacts
+type TemplateService struct {
+	db *gorm.DB
+}
+
+// NewTemplateService creates a new template service
+func NewTemplateService(db *gorm.DB) *TemplateService {
+	return &TemplateService{db: db}
+}
+
+// ListTemplates retrieves templates filtered by channel, category and active flag
+func (s *TemplateService) ListTemplates(communicationType, category string, activeOnly bool, page, limit int) ([]models.CommunicationTemplate, int64, error) {
+	query := s.db.Model(&models.CommunicationTemplate{})
+	if communicationType != "" {
+		query = query.Where("communication_type = ?", communicationType)
+	}
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count communication templates: %v", err)
+	}
+
+	var templates []models.CommunicationTemplate
+	if err := query.Order("usage_count DESC, name ASC").
+		Limit(limit).Offset((page - 1) * limit).
+		Find(&templates).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get communication templates: %v", err)
+	}
+
+	return templates, total, nil
+}
+
+// GetTemplate retrieves a template by ID
+func (s *TemplateService) GetTemplate(id uint) (*models.CommunicationTemplate, error) {
+	var template models.CommunicationTemplate
+	if err := s.db.First(&template, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("communication template not found")
+		}
+		return nil, fmt.Errorf("failed to get communication template: %v", err)
+	}
+	return &template, nil
+}
+
+// CreateTemplate creates a template and records the variables its content references
+func (s *TemplateService) CreateTemplate(req *models.CommunicationTemplateRequest, userID uint) (*models.CommunicationTemplate, error) {
+	template := &models.CommunicationTemplate{
+		IsActive:  true,
+		CreatedBy: &userID,
+		UpdatedBy: &userID,
+	}
+	if err := applyTemplateRequest(template, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(template).Error; err != nil {
+		return nil, fmt.Errorf("failed to create communication template: %v", err)
+	}
+
+	logger.Info("Communication template created", map[string]interface{}{
+		"template_id": template.ID,
+		"name":        template.Name,
+		"created_by":  userID,
+	})
+
+	return template, nil
+}
+
+// UpdateTemplate replaces the content and configuration of a template
+func (s *TemplateService) UpdateTemplate(id uint, req *models.CommunicationTemplateRequest, userID uint) (*models.CommunicationTemplate, error) {
+	template, err := s.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyTemplateRequest(template, req); err != nil {
+		return nil, err
+	}
+	template.UpdatedBy = &userID
+
+	if err := s.db.Save(template).Error; err != nil {
+		return nil, fmt.Errorf("failed to update communication template: %v", err)
+	}
+
+	logger.Info("Communication template updated", map[string]interface{}{
+		"template_id": template.ID,
+		"updated_by":  userID,
+	})
+
+	return template, nil
+}
+
+// DeleteTemplate deletes a template; system templates cannot be deleted
+func (s *TemplateService) DeleteTemplate(id uint, userID uint) error {
+	template, err := s.GetTemplate(id)
+	if err != nil {
+		return err
+	}
+	if template.IsSystem {
+		return fmt.Errorf("system templates cannot be deleted")
+	}
+
+	if err := s.db.Delete(&models.CommunicationTemplate{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete communication template: %v", err)
+	}
+
+	logger.Info("Communication template deleted", map[string]interface{}{
+		"template_id": id,
+		"deleted_by":  userID,
+	})
+
+	return nil
+}
+
+// RenderTemplate renders a template for a contact, optionally with an appointment and a sending admin user.
+// Renders that resolve every variable count towards usage_count unless they are previews.
+func (s *TemplateService) RenderTemplate(id uint, req *models.TemplateRenderRequest) (*models.RenderedTemplate, error) {
+	template, err := s.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	if !template.IsActive {
+		return nil, fmt.Errorf("communication template is inactive")
+	}
+
+	values, err := s.ResolveTemplateVariables(req.ContactID, req.AppointmentID, req.SenderID)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range req.Variables {
+		values[name] = value
+	}
+
+	rendered := template.Render(values)
+	if !req.Preview && rendered.IsComplete() {
+		if err := s.IncrementUsage(template.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return rendered, nil
+}
+
+// ResolveTemplateVariables loads the contact, appointment and sender and returns their values by variable name
+func (s *TemplateService) ResolveTemplateVariables(contactID uint, appointmentID, senderID *uint) (map[string]string, error) {
+	var contact models.Contact
+	if err := s.db.Where("deleted_at IS NULL").First(&contact, contactID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("contact not found")
+		}
+		return nil, fmt.Errorf("failed to get contact: %v", err)
+	}
+	values := contactTemplateValues(&contact)
+
+	if appointmentID != nil {
+		var appointment models.Appointment
+		if err := s.db.Where("deleted_at IS NULL").First(&appointment, *appointmentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("appointment not found")
+			}
+			return nil, fmt.Errorf("failed to get appointment: %v", err)
+		}
+		if appointment.ContactID != contact.ID {
+			return nil, fmt.Errorf("invalid appointment: appointment %d does not belong to contact %d", appointment.ID, contact.ID)
+		}
+		for name, value := range appointmentTemplateValues(&appointment) {
+			values[name] = value
+		}
+	}
+
+	if senderID != nil {
+		var sender models.AdminUser
+		if err := s.db.Where("deleted_at IS NULL").First(&sender, *senderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("sender not found")
+			}
+			return nil, fmt.Errorf("failed to get sender: %v", err)
+		}
+		for name, value := range senderTemplateValues(&sender) {
+			values[name] = value
+		}
+	}
+
+	now := time.Now()
+	values["current_date"] = now.Format(templateDateLayout)
+	values["current_year"] = strconv.Itoa(now.Year())
+
+	return values, nil
+}
+
+// IncrementUsage counts one use of a template
+func (s *TemplateService) IncrementUsage(id uint) error {
+	if err := s.db.Model(&models.CommunicationTemplate{}).Where("id = ?", id).
+		UpdateColumn("usage_count", gorm.Expr("usage_count + 1")).Error; err != nil {
+		return fmt.Errorf("failed to update template usage: %v", err)
+	}
+	return nil
+}
+
+// GetAvailableVariables lists every variable a template can reference
+func (s *TemplateService) GetAvailableVariables() []models.TemplateVariable {
+	var variables []models.TemplateVariable
+	for name := range (&models.Contact{}).FieldValues() {
+		variables = append(variables, models.TemplateVariable{
+			Name:        name,
+			Source:      "contact",
+			Description: "Contact " + strings.ReplaceAll(name, "_", " ") + " (also available as contact." + name + ")",
+		})
+	}
+	variables = append(variables, models.TemplateVariable{
+		Name:        "full_name",
+		Source:      "contact",
+		Description: "Contact first and last name (also available as contact.full_name)",
+	})
+	add := func(source string, descriptions map[string]string) {
+		for name, description := range descriptions {
+			variables = append(variables, models.TemplateVariable{Name: name, Source: source, Description: description})
+		}
+	}
+	add("appointment", appointmentTemplateVariables)
+	add("sender", senderTemplateVariables)
+	add("system", systemTemplateVariables)
+
+	sort.Slice(variables, func(i, j int) bool {
+		if variables[i].Source != variables[j].Source {
+			return variables[i].Source < variables[j].Source
+		}
+		return variables[i].Name < variables[j].Name
+	})
+	return variables
+}
+
+// applyTemplateRequest copies a create/update request onto a template after validating its content
+func applyTemplateRequest(template *models.CommunicationTemplate, req *models.CommunicationTemplateRequest) error {
+	if req.CommunicationType == models.TemplateChannelEmail {
+		if isBlank(req.Subject) {
+			return fmt.Errorf("invalid template: email templates require a subject")
+		}
+		if isBlank(req.HTMLContent) && isBlank(req.PlainContent) {
+			return fmt.Errorf("invalid template: email templates require html_content or plain_content")
+		}
+	} else if isBlank(req.PlainContent) {
+		return fmt.Errorf("invalid template: %s templates require plain_content", req.CommunicationType)
+	}
+	if err := models.ValidatePersonalizationRules(req.PersonalizationRules); err != nil {
+		return fmt.Errorf("invalid personalization rules: %v", err)
+	}
+
+	template.Name = strings.TrimSpace(req.Name)
+	template.Description = req.Description
+	template.CommunicationType = req.CommunicationType
+	template.Category = req.Category
+	template.Subject = req.Subject
+	template.HTMLContent = req.HTMLContent
+	template.PlainContent = req.PlainContent
+	template.PersonalizationRules = models.JSONMap(req.PersonalizationRules)
+	if req.IsActive != nil {
+		template.IsActive = *req.IsActive
+	}
+
+	template.Tags = models.JSONArray{}
+	for _, tag := range req.Tags {
+		template.Tags = append(template.Tags, tag)
+	}
+	template.Variables = models.JSONArray{}
+	for _, name := range template.ReferencedVariables() {
+		template.Variables = append(template.Variables, name)
+	}
+
+	return nil
+}
+
+// contactTemplateValues exposes the contact's fields under their bare and contact.-prefixed names
+func contactTemplateValues(contact *models.Contact) map[string]string {
+	values := make(map[string]string)
+	for name, value := range contact.FieldValues() {
+		if value == nil {
+			continue
+		}
+		text := *value
+		if t, err := time.Parse(time.RFC3339, text); err == nil {
+			text = t.Format(templateDateTimeLayout)
+		}
+		values[name] = text
+		values["contact."+name] = text
+	}
+
+	fullName := contact.FirstName
+	if contact.LastName != nil && *contact.LastName != "" {
+		fullName += " " + *contact.LastName
+	}
+	values["full_name"] = fullName
+	values["contact.full_name"] = fullName
+
+	return values
+}
+
+func appointmentTemplateValues(appointment *models.Appointment) map[string]string {
+	scheduledTime := appointment.ScheduledTime
+	if len(scheduledTime) == len("15:04:05") {
+		scheduledTime = scheduledTime[:len("15:04")]
+	}
+	values := map[string]string{
+		"appointment.title":            appointment.Title,
+		"appointment.type":             string(appointment.AppointmentType),
+		"appointment.scheduled_date":   appointment.ScheduledDate.Format("2006-01-02"),
+		"appointment.scheduled_time":   scheduledTime,
+		"appointment.timezone":         appointment.Timezone,
+		"appointment.duration_minutes": strconv.Itoa(appointment.DurationMinutes),
+		"appointment.status":           string(appointment.Status),
+		"appointment.meeting_type":     string(appointment.MeetingType),
+	}
+	optional := map[string]*string{
+		"appointment.description":      appointment.Description,
+		"appointment.location":         appointment.Location,
+		"appointment.meeting_link":     appointment.MeetingLink,
+		"appointment.meeting_id":       appointment.MeetingID,
+		"appointment.meeting_password": appointment.MeetingPassword,
+		"appointment.phone_number":     appointment.PhoneNumber,
+	}
+	for name, value := range optional {
+		if value != nil {
+			values[name] = *value
+		}
+	}
+	return values
+}
+
+func senderTemplateValues(sender *models.AdminUser) map[string]string {
+	values := map[string]string{
+		"sender.name":  sender.GetFullName(),
+		"sender.email": sender.Email,
+	}
+	optional := map[string]*string{
+		"sender.phone":      sender.Phone,
+		"sender.job_title":  sender.JobTitle,
+		"sender.department": sender.Department,
+		"sender.location":   sender.Location,
+	}
+	for name, value := range optional {
+		if value != nil {
+			values[name] = *value
+		}
+	}
+	return values
+}
+
+func isBlank(value *string) bool {
+	return value == nil || strings.TrimSpace(*value) == ""
+}
@@ -0,0 +1,409 @@
+package services
+
+import (
+	"bytes"
+	"contact-service/internal/models"
+	"contact-service/pkg/ical"
+	"contact-service/pkg/logger"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// transitionWebhookClient delivers transition rule webhooks
+var transitionWebhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// transitionEffects is what a rule-driven transition did inside its transaction, and what is left to
+// do once the transaction commits
+type transitionEffects struct {
+	rule         *models.StatusTransitionRule
+	event        *models.LifecycleEvent
+	results      []models.TransitionActionResult
+	notified     []uint
+	webhooks     []pendingWebhook
+	appointments []uint // Booked appointments whose invites go out after commit
+}
+
+// pendingWebhook is a webhook action waiting for the transition to commit
+type pendingWebhook struct {
+	result int // Index into transitionEffects.results
+	action models.TransitionAction
+	body   []byte
+}
+
+// transitionWebhookPayload is the body POSTed by webhook actions
+type transitionWebhookPayload struct {
+	Event       string               `json:"event"`
+	RuleID      uint                 `json:"rule_id"`
+	RuleName    string               `json:"rule_name"`
+	ContactID   uint                 `json:"contact_id"`
+	FromStatus  models.ContactStatus `json:"from_status"`
+	ToStatus    models.ContactStatus `json:"to_status"`
+	TriggerType string               `json:"trigger_type"`
+	OccurredAt  time.Time            `json:"occurred_at"`
+}
+
+// triggerData is the record of the rule's actions kept on the lifecycle event
+func (e *transitionEffects) triggerData() models.JSONMap {
+	return models.JSONMap{
+		"rule_id":        e.rule.ID,
+		"rule_name":      e.rule.Name,
+		"actions":        e.results,
+		"notified_users": e.notified,
+	}
+}
+
+// runTransitionActions runs the rule's actions against the contact, which is already in its new
+// status, and notifies the rule's users. It must be called on a transaction-scoped service; a failed
+// action aborts the transition unless the action continues on error, in which case its changes are
+// rolled back to a savepoint and the failure is recorded.
+func (s *LifecycleService) runTransitionActions(rule *models.StatusTransitionRule, contact *models.Contact, previousStatus models.ContactStatus, changedBy *uint, triggerType string) (*transitionEffects, error) {
+	steps, err := models.ParseTransitionActions(rule.Actions)
+	if err != nil {
+		return nil, fmt.Errorf("transition rule %d has %v", rule.ID, err)
+	}
+	notifyIDs, err := models.ParseNotifyUsers(rule.NotifyUsers)
+	if err != nil {
+		return nil, fmt.Errorf("transition rule %d has %v", rule.ID, err)
+	}
+
+	effects := &transitionEffects{rule: rule, results: make([]models.TransitionActionResult, 0, len(steps))}
+	for i := range steps {
+		step := &steps[i]
+		savepoint := fmt.Sprintf("transition_action_%d", i+1)
+		if step.ContinueOnError {
+			if err := s.db.SavePoint(savepoint).Error; err != nil {
+				return nil, fmt.Errorf("failed to create savepoint: %v", err)
+			}
+		}
+
+		result := models.TransitionActionResult{Type: step.Type, Status: models.TransitionActionSucceeded}
+		status, detail, err := s.runTransitionAction(step, rule, contact, changedBy, effects)
+		if err != nil {
+			if !step.ContinueOnError {
+				return nil, fmt.Errorf("transition rule %d action %d (%s) failed: %v", rule.ID, i+1, step.Type, err)
+			}
+			if rollbackErr := s.db.RollbackTo(savepoint).Error; rollbackErr != nil {
+				return nil, fmt.Errorf("failed to roll back action %d: %v", i+1, rollbackErr)
+			}
+			result.Status, result.Error = models.TransitionActionFailed, err.Error()
+		} else {
+			result.Status, result.Detail = status, detail
+		}
+
+		if step.Type == models.TransitionActionWebhook && err == nil {
+			body, _ := json.Marshal(transitionWebhookPayload{
+				Event:       "contact.status_changed",
+				RuleID:      rule.ID,
+				RuleName:    rule.Name,
+				ContactID:   contact.ID,
+				FromStatus:  previousStatus,
+				ToStatus:    contact.Status,
+				TriggerType: triggerType,
+				OccurredAt:  time.Now(),
+			})
+			effects.webhooks = append(effects.webhooks, pendingWebhook{result: len(effects.results), action: *step, body: body})
+		}
+		effects.results = append(effects.results, result)
+	}
+
+	entityType := "contact"
+	notified, err := notifyUsers(s.db, notifyIDs, models.Notification{
+		Type:       models.NotificationStatusTransition,
+		Title:      fmt.Sprintf("%s moved to %s", contact.GetFullName(), contact.Status),
+		Body:       fmt.Sprintf("Rule %q moved %s from %s to %s.", rule.Name, contact.GetFullName(), previousStatus, contact.Status),
+		EntityType: &entityType,
+		EntityID:   &contact.ID,
+		Data: models.JSONMap{
+			"rule_id":     rule.ID,
+			"from_status": previousStatus,
+			"to_status":   contact.Status,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	effects.notified = notified
+	return effects, nil
+}
+
+// runTransitionAction performs one action and returns its status and a short description of what it did
+func (s *LifecycleService) runTransitionAction(step *models.TransitionAction, rule *models.StatusTransitionRule, contact *models.Contact, changedBy *uint, effects *transitionEffects) (string, string, error) {
+	reason := fmt.Sprintf("Status transition rule: %s", rule.Name)
+
+	switch step.Type {
+	case models.TransitionActionAssignUser:
+		assigned, err := (&AssignmentService{db: s.db}).assignTo(contact, step.UserID, changedBy, nil, "automatic", reason)
+		if err != nil {
+			return "", "", err
+		}
+		if !assigned {
+			return models.TransitionActionSkipped, fmt.Sprintf("already assigned to user %d", step.UserID), nil
+		}
+		return models.TransitionActionSucceeded, fmt.Sprintf("assigned to user %d", step.UserID), nil
+
+	case models.TransitionActionAssignRule:
+		assignment := &AssignmentService{db: s.db}
+		var assignmentRule models.AssignmentRule
+		if err := s.db.Where("id = ? AND status = ? AND deleted_at IS NULL", step.RuleID, models.AssignmentRuleActive).
+			First(&assignmentRule).Error; err != nil {
+			return "", "", fmt.Errorf("assignment rule %d not found or inactive", step.RuleID)
+		}
+		assigneeID, err := assignment.selectAssignee(&assignmentRule, contact)
+		if err != nil {
+			return "", "", fmt.Errorf("assignment rule %d selected no assignee: %v", step.RuleID, err)
+		}
+		assigned, err := assignment.assignTo(contact, assigneeID, changedBy, &assignmentRule.ID, "automatic",
+			fmt.Sprintf("%s (assignment rule: %s)", reason, assignmentRule.Name))
+		if err != nil {
+			return "", "", err
+		}
+		if !assigned {
+			return models.TransitionActionSkipped, fmt.Sprintf("already assigned to user %d", assigneeID), nil
+		}
+		assignment.updateRuleStatistics(&assignmentRule)
+		return models.TransitionActionSucceeded, fmt.Sprintf("assigned to user %d", assigneeID), nil
+
+	case models.TransitionActionAddTag, models.TransitionActionRemoveTag:
+		return s.changeTransitionTag(step, contact, changedBy)
+
+	case models.TransitionActionCreateActivity:
+		activityType := step.ActivityType
+		if activityType == "" {
+			activityType = models.ActivityFollowUp
+		}
+		priority := step.Priority
+		if priority == "" {
+			priority = models.PriorityMedium
+		}
+		relatedType := "status_transition_rule"
+		owner := contact.AssignedTo
+		if step.UserID != 0 {
+			owner = &step.UserID
+		}
+		due := time.Now().AddDate(0, 0, step.DueInDays)
+		activity := &models.ContactActivity{
+			ContactID:         contact.ID,
+			ActivityType:      activityType,
+			Title:             step.Title,
+			Status:            models.ActivityStatusPending,
+			Priority:          priority,
+			Direction:         models.DirectionOutbound,
+			Channel:           models.ChannelEmail,
+			ScheduledDate:     &due,
+			PerformedBy:       transitionActor(rule, contact, changedBy),
+			AssignedTo:        owner,
+			RelatedEntityType: &relatedType,
+			RelatedEntityID:   &rule.ID,
+			CreatedBy:         changedBy,
+		}
+		if step.Description != "" {
+			activity.Description = &step.Description
+		}
+		if err := s.db.Create(activity).Error; err != nil {
+			return "", "", fmt.Errorf("failed to create activity: %v", err)
+		}
+		return models.TransitionActionSucceeded, fmt.Sprintf("activity %d", activity.ID), nil
+
+	case models.TransitionActionSetPriority:
+		if contact.Priority == step.Priority {
+			return models.TransitionActionSkipped, fmt.Sprintf("priority already %s", step.Priority), nil
+		}
+		before := *contact
+		if err := s.db.Model(&models.Contact{}).Where("id = ?", contact.ID).Update("priority", step.Priority).Error; err != nil {
+			return "", "", fmt.Errorf("failed to set priority: %v", err)
+		}
+		recordContactHistorySince(s.db, &before, changedBy, models.ChangeSourceLifecycle, reason)
+		contact.Priority = step.Priority
+		return models.TransitionActionSucceeded, fmt.Sprintf("priority %s", step.Priority), nil
+
+	case models.TransitionActionScheduleAppointment:
+		return s.scheduleTransitionAppointment(step, rule, contact, changedBy, effects)
+
+	case models.TransitionActionWebhook:
+		return models.TransitionActionPending, "delivered after commit", nil
+	}
+	return "", "", fmt.Errorf("unknown action type %q", step.Type)
+}
+
+// changeTransitionTag adds or removes an existing tag on the contact
+func (s *LifecycleService) changeTransitionTag(step *models.TransitionAction, contact *models.Contact, changedBy *uint) (string, string, error) {
+	var tag models.ContactTag
+	if err := s.db.Where("name = ?", step.Tag).First(&tag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", fmt.Errorf("tag %q not found", step.Tag)
+		}
+		return "", "", fmt.Errorf("failed to get tag: %v", err)
+	}
+
+	if step.Type == models.TransitionActionRemoveTag {
+		result := s.db.Where("contact_id = ? AND tag_id = ?", contact.ID, tag.ID).Delete(&models.ContactTagAssignment{})
+		if result.Error != nil {
+			return "", "", fmt.Errorf("failed to remove tag: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return models.TransitionActionSkipped, fmt.Sprintf("tag %q not present", tag.Name), nil
+		}
+		s.db.Model(&tag).UpdateColumn("usage_count", gorm.Expr("GREATEST(usage_count - 1, 0)"))
+		return models.TransitionActionSucceeded, fmt.Sprintf("removed tag %q", tag.Name), nil
+	}
+
+	var existing int64
+	if err := s.db.Model(&models.ContactTagAssignment{}).Where("contact_id = ? AND tag_id = ?", contact.ID, tag.ID).
+		Count(&existing).Error; err != nil {
+		return "", "", fmt.Errorf("failed to check tag: %v", err)
+	}
+	if existing > 0 {
+		return models.TransitionActionSkipped, fmt.Sprintf("tag %q already present", tag.Name), nil
+	}
+	if err := s.db.Create(&models.ContactTagAssignment{
+		ContactID:  contact.ID,
+		TagID:      tag.ID,
+		AssignedAt: time.Now(),
+		AssignedBy: changedBy,
+	}).Error; err != nil {
+		return "", "", fmt.Errorf("failed to add tag: %v", err)
+	}
+	s.db.Model(&tag).UpdateColumn("usage_count", gorm.Expr("usage_count + 1"))
+	return models.TransitionActionSucceeded, fmt.Sprintf("added tag %q", tag.Name), nil
+}
+
+// scheduleTransitionAppointment books an appointment with the action's user, or the contact's
+// assignee, DueInDays (default 1) from today at the action's time
+func (s *LifecycleService) scheduleTransitionAppointment(step *models.TransitionAction, rule *models.StatusTransitionRule, contact *models.Contact, changedBy *uint, effects *transitionEffects) (string, string, error) {
+	host := step.UserID
+	if host == 0 {
+		if contact.AssignedTo == nil {
+			return "", "", fmt.Errorf("no user to host the appointment; set user_id or assign the contact first")
+		}
+		host = *contact.AssignedTo
+	}
+
+	timezone := step.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid timezone: %v", err)
+	}
+	days := step.DueInDays
+	if days == 0 {
+		days = 1
+	}
+	at := step.Time
+	if at == "" {
+		at = "10:00"
+	}
+
+	request := &models.AppointmentRequest{
+		ContactID:     contact.ID,
+		Title:         step.Title,
+		ScheduledDate: time.Now().In(loc).AddDate(0, 0, days).Format("2006-01-02"),
+		ScheduledTime: at,
+		Timezone:      &timezone,
+		AssignedTo:    host,
+	}
+	if step.Description != "" {
+		request.Description = &step.Description
+	}
+	if step.AppointmentType != "" {
+		request.AppointmentType = &step.AppointmentType
+	}
+	if step.DurationMinutes != 0 {
+		request.DurationMinutes = &step.DurationMinutes
+	}
+	if step.Priority != "" {
+		request.Priority = &step.Priority
+	}
+
+	// A transaction-scoped scheduler has no inviter; invites are sent once the transition commits
+	appointment, err := (&SchedulingService{db: s.db}).CreateAppointment(request, transitionActor(rule, contact, changedBy))
+	if err != nil {
+		return "", "", err
+	}
+	effects.appointments = append(effects.appointments, appointment.ID)
+	return models.TransitionActionSucceeded, fmt.Sprintf("appointment %d", appointment.ID), nil
+}
+
+// finishTransition does the work that must wait for the transition to commit: calendar invites for
+// booked appointments, then webhook delivery, whose outcomes are written back to the lifecycle event
+func (s *LifecycleService) finishTransition(effects *transitionEffects) {
+	if s.scheduling != nil {
+		for _, appointmentID := range effects.appointments {
+			s.scheduling.sendCalendarInvite(appointmentID, ical.MethodRequest, false)
+		}
+	}
+	if len(effects.webhooks) == 0 {
+		return
+	}
+
+	go func() {
+		for _, webhook := range effects.webhooks {
+			result := &effects.results[webhook.result]
+			if err := deliverTransitionWebhook(&webhook.action, webhook.body); err != nil {
+				result.Status, result.Detail, result.Error = models.TransitionActionFailed, "", err.Error()
+				logger.Warn("Transition rule webhook failed", map[string]interface{}{
+					"rule_id":    effects.rule.ID,
+					"contact_id": effects.event.ContactID,
+					"url":        webhook.action.URL,
+					"error":      err.Error(),
+				})
+			} else {
+				result.Status, result.Detail = models.TransitionActionSucceeded, "delivered"
+			}
+		}
+
+		if err := s.db.Model(effects.event).Update("trigger_data", effects.triggerData()).Error; err != nil {
+			logger.Error("Failed to record transition webhook outcomes", err, map[string]interface{}{
+				"event_id": effects.event.ID,
+			})
+		}
+	}()
+}
+
+// deliverTransitionWebhook POSTs the payload, signed with the action's secret when it has one
+func deliverTransitionWebhook(action *models.TransitionAction, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, action.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", "contact.status_changed")
+	if action.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(action.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := transitionWebhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// transitionActor is the user that activities and appointments created by a rule are attributed to:
+// whoever changed the status, else the rule's author, else the contact's assignee
+func transitionActor(rule *models.StatusTransitionRule, contact *models.Contact, changedBy *uint) uint {
+	switch {
+	case changedBy != nil:
+		return *changedBy
+	case rule.CreatedBy != nil:
+		return *rule.CreatedBy
+	case contact.AssignedTo != nil:
+		return *contact.AssignedTo
+	}
+	return 0
+}
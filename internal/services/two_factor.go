@@ -0,0 +1,498 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/pkg/auth"
+	"contact-service/pkg/logger"
+	"contact-service/pkg/totp"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TwoFactorConfig configures TOTP two-factor authentication
+type TwoFactorConfig struct {
+	Issuer         string        // Shown by authenticator apps next to the account
+	Skew           int           // Steps before and after the current one whose codes are accepted
+	RecoveryCodes  int           // Recovery codes issued at a time
+	PolicyCacheTTL time.Duration // How long role policies are cached
+	SecretKey      []byte        // AES-256 key that encrypts TOTP secrets at rest
+}
+
+// Prefix of TOTP secrets encrypted with TwoFactorConfig.SecretKey; secrets stored before they were
+// encrypted have none
+const sealedSecretPrefix = "v1:"
+
+// LoadTwoFactorConfig reads the two-factor configuration from the environment
+func LoadTwoFactorConfig() TwoFactorConfig {
+	config := TwoFactorConfig{
+		Issuer:         "Mejona Contact Service",
+		Skew:           1,
+		RecoveryCodes:  10,
+		PolicyCacheTTL: time.Minute,
+	}
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		config.Issuer = issuer
+	}
+	if skew, err := strconv.Atoi(os.Getenv("TOTP_SKEW_STEPS")); err == nil && skew >= 0 {
+		config.Skew = skew
+	}
+	if codes, err := strconv.Atoi(os.Getenv("TOTP_RECOVERY_CODES")); err == nil && codes > 0 {
+		config.RecoveryCodes = codes
+	}
+	if key := os.Getenv("TOTP_ENCRYPTION_KEY"); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		config.SecretKey = sum[:]
+	} else {
+		config.SecretKey = auth.DeriveKey("totp-secrets")
+	}
+	return config
+}
+
+// twoFactorPolicies caches which roles require two-factor authentication. It is shared by every
+// TwoFactorService so that a policy change is seen at once by the middleware enforcing it.
+var twoFactorPolicies struct {
+	sync.RWMutex
+	required map[string]bool
+	loadedAt time.Time
+}
+
+// TwoFactorService enrolls users in TOTP two-factor authentication, verifies their codes and keeps
+// the per-role policies that make it mandatory
+type TwoFactorService struct {
+	db     *gorm.DB
+	config TwoFactorConfig
+}
+
+// NewTwoFactorService creates a new two-factor service
+func NewTwoFactorService(db *gorm.DB, config TwoFactorConfig) *TwoFactorService {
+	return &TwoFactorService{
+		db:     db,
+		config: config,
+	}
+}
+
+// Status describes a user's two-factor setup
+func (s *TwoFactorService) Status(userID uint) (*models.TwoFactorStatus, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var remaining int64
+	if err := s.db.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&remaining).Error; err != nil {
+		return nil, fmt.Errorf("failed to count recovery codes: %v", err)
+	}
+
+	return &models.TwoFactorStatus{
+		Enabled:                user.TwoFactorEnabled,
+		Pending:                !user.TwoFactorEnabled && user.TwoFactorSecret != nil,
+		ConfirmedAt:            user.TwoFactorConfirmedAt,
+		RecoveryCodesRemaining: remaining,
+		RequiredByPolicy:       s.RequiredForRole(user.Role),
+	}, nil
+}
+
+// Enroll generates a new secret for a user. Two-factor authentication stays off until a code from
+// the secret is confirmed; enrolling again replaces an unconfirmed secret.
+func (s *TwoFactorService) Enroll(userID uint) (*models.TwoFactorEnrollment, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.sealSecret(userID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&models.AdminUser{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"two_factor_secret":       sealed,
+			"two_factor_confirmed_at": nil,
+			"two_factor_last_step":    nil,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to save two-factor secret: %v", err)
+	}
+
+	return &models.TwoFactorEnrollment{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.config.Issuer, user.Email, secret),
+		Issuer:     s.config.Issuer,
+		Account:    user.Email,
+	}, nil
+}
+
+// Confirm turns two-factor authentication on once the user proves their authenticator produces
+// codes for the enrolled secret, and returns the first set of recovery codes
+func (s *TwoFactorService) Confirm(userID uint, code string) ([]string, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+	if user.TwoFactorSecret == nil {
+		return nil, fmt.Errorf("two-factor enrollment has not been started")
+	}
+
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.useTOTP(tx, user, code); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.AdminUser{}).Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"two_factor_enabled":      true,
+				"two_factor_confirmed_at": time.Now(),
+			}).Error; err != nil {
+			return fmt.Errorf("failed to enable two-factor authentication: %v", err)
+		}
+
+		codes, err = s.replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verify checks a login's second factor, which is either a TOTP code or an unused recovery code. It
+// returns the method that was used.
+func (s *TwoFactorService) Verify(userID uint, code string) (string, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return "", err
+	}
+	if !user.TwoFactorEnabled || user.TwoFactorSecret == nil {
+		return "", fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	if isTOTPCode(code) {
+		if err := s.useTOTP(s.db, user, code); err != nil {
+			return "", err
+		}
+		return "totp", nil
+	}
+
+	if err := s.useRecoveryCode(userID, code); err != nil {
+		return "", err
+	}
+	return "recovery_code", nil
+}
+
+// RegenerateRecoveryCodes replaces a user's recovery codes after checking a TOTP code
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled {
+		return nil, fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.useTOTP(tx, user, code); err != nil {
+			return err
+		}
+		codes, err = s.replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off after checking a TOTP or recovery code. Users whose
+// role requires it cannot turn it off.
+func (s *TwoFactorService) Disable(userID uint, code string) error {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return err
+	}
+	if s.RequiredForRole(user.Role) {
+		return fmt.Errorf("cannot disable two-factor authentication: it is required for role %s", user.Role)
+	}
+	if _, err := s.Verify(userID, code); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AdminUser{}).Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"two_factor_enabled":      false,
+				"two_factor_secret":       nil,
+				"two_factor_confirmed_at": nil,
+				"two_factor_last_step":    nil,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to disable two-factor authentication: %v", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %v", err)
+		}
+		return nil
+	})
+}
+
+// useTOTP accepts a TOTP code once: the step it belongs to is recorded, and codes from that step or
+// earlier are refused afterwards
+func (s *TwoFactorService) useTOTP(db *gorm.DB, user *models.AdminUser, code string) error {
+	secret, err := s.openSecret(user.ID, *user.TwoFactorSecret)
+	if err != nil {
+		return err
+	}
+	step, ok := totp.Validate(secret, code, time.Now(), s.config.Skew)
+	if !ok {
+		return fmt.Errorf("invalid two-factor code")
+	}
+
+	result := db.Model(&models.AdminUser{}).
+		Where("id = ? AND (two_factor_last_step IS NULL OR two_factor_last_step < ?)", user.ID, step).
+		Update("two_factor_last_step", step)
+	if result.Error != nil {
+		return fmt.Errorf("failed to record two-factor code: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("invalid two-factor code: already used")
+	}
+	return nil
+}
+
+// EncryptStoredSecrets encrypts the TOTP secrets stored before secrets were encrypted at rest
+func (s *TwoFactorService) EncryptStoredSecrets() error {
+	var users []models.AdminUser
+	if err := s.db.Select("id", "two_factor_secret").
+		Where("two_factor_secret IS NOT NULL AND two_factor_secret NOT LIKE ?", sealedSecretPrefix+"%").
+		Find(&users).Error; err != nil {
+		return fmt.Errorf("failed to find unencrypted two-factor secrets: %v", err)
+	}
+
+	for _, user := range users {
+		sealed, err := s.sealSecret(user.ID, *user.TwoFactorSecret)
+		if err != nil {
+			return err
+		}
+		// Left alone if the user re-enrolled or disabled two-factor authentication meanwhile
+		if err := s.db.Model(&models.AdminUser{}).
+			Where("id = ? AND two_factor_secret = ?", user.ID, *user.TwoFactorSecret).
+			Update("two_factor_secret", sealed).Error; err != nil {
+			return fmt.Errorf("failed to encrypt two-factor secret: %v", err)
+		}
+	}
+	if len(users) > 0 {
+		logger.Info("Encrypted stored two-factor secrets", map[string]interface{}{
+			"count": len(users),
+		})
+	}
+	return nil
+}
+
+// sealSecret encrypts a user's TOTP secret for storage. The user ID is authenticated with it, so a
+// secret copied to another user's row does not decrypt.
+func (s *TwoFactorService) sealSecret(userID uint, secret string) (string, error) {
+	gcm, err := s.secretCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to encrypt two-factor secret: %v", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), []byte(strconv.FormatUint(uint64(userID), 10)))
+	return sealedSecretPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// openSecret decrypts a stored TOTP secret. Secrets stored before they were encrypted are returned
+// as they are.
+func (s *TwoFactorService) openSecret(userID uint, stored string) (string, error) {
+	if !strings.HasPrefix(stored, sealedSecretPrefix) {
+		return stored, nil
+	}
+	gcm, err := s.secretCipher()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(stored, sealedSecretPrefix))
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("failed to decrypt two-factor secret: malformed value")
+	}
+	secret, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(strconv.FormatUint(uint64(userID), 10)))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt two-factor secret: %v", err)
+	}
+	return string(secret), nil
+}
+
+func (s *TwoFactorService) secretCipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.config.SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid two-factor secret key: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *TwoFactorService) useRecoveryCode(userID uint, code string) error {
+	result := s.db.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, models.HashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to use recovery code: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("invalid two-factor code")
+	}
+	return nil
+}
+
+func (s *TwoFactorService) replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %v", err)
+	}
+
+	codes := make([]string, 0, s.config.RecoveryCodes)
+	records := make([]models.UserRecoveryCode, 0, s.config.RecoveryCodes)
+	for len(codes) < s.config.RecoveryCodes {
+		code, err := models.NewRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, models.UserRecoveryCode{
+			UserID:   userID,
+			CodeHash: models.HashRecoveryCode(code),
+		})
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %v", err)
+	}
+	return codes, nil
+}
+
+func (s *TwoFactorService) getUser(userID uint) (*models.AdminUser, error) {
+	var user models.AdminUser
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+	return &user, nil
+}
+
+// isTOTPCode tells TOTP codes, which are all digits, from recovery codes
+func isTOTPCode(code string) bool {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ListPolicies returns the two-factor policies of every role that has one
+func (s *TwoFactorService) ListPolicies() ([]models.TwoFactorPolicy, error) {
+	var policies []models.TwoFactorPolicy
+	if err := s.db.Order("role ASC").Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to get two-factor policies: %v", err)
+	}
+	return policies, nil
+}
+
+// SetPolicy sets whether a role requires two-factor authentication. An admin cannot require it for
+// their own role before enabling it themselves, which would lock them out of the admin endpoints.
+func (s *TwoFactorService) SetPolicy(role string, required bool, adminID uint) (*models.TwoFactorPolicy, error) {
+	if err := requireRole(s.db, role); err != nil {
+		return nil, err
+	}
+
+	if required {
+		admin, err := s.getUser(adminID)
+		if err != nil {
+			return nil, err
+		}
+		if admin.Role == role && !admin.TwoFactorEnabled {
+			return nil, fmt.Errorf("cannot require two-factor authentication for your own role before enabling it")
+		}
+	}
+
+	policy := &models.TwoFactorPolicy{
+		Role:      role,
+		Required:  required,
+		UpdatedBy: &adminID,
+	}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "role"}},
+		DoUpdates: clause.AssignmentColumns([]string{"required", "updated_by", "updated_at"}),
+	}).Create(policy).Error; err != nil {
+		return nil, fmt.Errorf("failed to save two-factor policy: %v", err)
+	}
+	if err := s.db.Where("role = ?", role).First(policy).Error; err != nil {
+		return nil, fmt.Errorf("failed to get two-factor policy: %v", err)
+	}
+
+	s.invalidatePolicies()
+	return policy, nil
+}
+
+// RequiredForRole reports whether a role's policy requires two-factor authentication. Policies are
+// cached briefly; if they cannot be loaded the last known policies are used.
+func (s *TwoFactorService) RequiredForRole(role string) bool {
+	twoFactorPolicies.RLock()
+	fresh := twoFactorPolicies.required != nil && time.Since(twoFactorPolicies.loadedAt) < s.config.PolicyCacheTTL
+	required := twoFactorPolicies.required[role]
+	twoFactorPolicies.RUnlock()
+	if fresh {
+		return required
+	}
+
+	var policies []models.TwoFactorPolicy
+	if err := s.db.Where("required = ?", true).Find(&policies).Error; err != nil {
+		return required
+	}
+
+	loaded := make(map[string]bool, len(policies))
+	for _, policy := range policies {
+		loaded[policy.Role] = true
+	}
+
+	twoFactorPolicies.Lock()
+	twoFactorPolicies.required = loaded
+	twoFactorPolicies.loadedAt = time.Now()
+	twoFactorPolicies.Unlock()
+
+	return loaded[role]
+}
+
+func (s *TwoFactorService) invalidatePolicies() {
+	twoFactorPolicies.Lock()
+	twoFactorPolicies.required = nil
+	twoFactorPolicies.Unlock()
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"contact-service/internal/models"
+	"contact-service/pkg/totp"
+)
+
+// newTwoFactorTestService returns a two-factor service on an in-memory database with users 1 and 2
+func newTwoFactorTestService(t *testing.T) (*TwoFactorService, *gorm.DB) {
+	db := newTestDB(t, &models.AdminUser{}, &models.UserRecoveryCode{}, &models.TwoFactorPolicy{})
+	require.NoError(t, db.Create(&models.AdminUser{ID: 1, Email: "asha@example.com", Name: "Asha", Role: "sales", IsActive: true}).Error)
+	require.NoError(t, db.Create(&models.AdminUser{ID: 2, Email: "ravi@example.com", Name: "Ravi", Role: "sales", IsActive: true}).Error)
+	return NewTwoFactorService(db, TwoFactorConfig{
+		Issuer:        "Test",
+		Skew:          1,
+		RecoveryCodes: 2,
+		SecretKey:     []byte("0123456789abcdef0123456789abcdef"),
+	}), db
+}
+
+func storedTwoFactorSecret(t *testing.T, db *gorm.DB, userID uint) string {
+	var user models.AdminUser
+	require.NoError(t, db.First(&user, userID).Error)
+	require.NotNil(t, user.TwoFactorSecret)
+	return *user.TwoFactorSecret
+}
+
+func TestTwoFactorSecretIsEncryptedAtRest(t *testing.T) {
+	service, db := newTwoFactorTestService(t)
+
+	enrollment, err := service.Enroll(1)
+	require.NoError(t, err)
+	stored := storedTwoFactorSecret(t, db, 1)
+	assert.True(t, strings.HasPrefix(stored, sealedSecretPrefix))
+	assert.NotContains(t, stored, enrollment.Secret)
+
+	code, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	codes, err := service.Confirm(1, code)
+	require.NoError(t, err)
+	assert.Len(t, codes, 2)
+
+	// A secret copied to another user's row does not decrypt
+	_, err = service.openSecret(2, stored)
+	assert.Error(t, err)
+}
+
+func TestEncryptStoredSecrets(t *testing.T) {
+	service, db := newTwoFactorTestService(t)
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&models.AdminUser{}).Where("id = ?", 1).Update("two_factor_secret", secret).Error)
+
+	// Secrets stored before encryption still work until they are encrypted
+	opened, err := service.openSecret(1, secret)
+	require.NoError(t, err)
+	assert.Equal(t, secret, opened)
+
+	require.NoError(t, service.EncryptStoredSecrets())
+	stored := storedTwoFactorSecret(t, db, 1)
+	assert.True(t, strings.HasPrefix(stored, sealedSecretPrefix))
+	opened, err = service.openSecret(1, stored)
+	require.NoError(t, err)
+	assert.Equal(t, secret, opened)
+
+	// Already encrypted secrets are left alone
+	require.NoError(t, service.EncryptStoredSecrets())
+	assert.Equal(t, stored, storedTwoFactorSecret(t, db, 1))
+}
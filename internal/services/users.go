@@ -0,0 +1,474 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/internal/repository"
+	"contact-service/pkg/logger"
+	"contact-service/pkg/mailer"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserInvitationConfig configures the invitation of new admin users
+type UserInvitationConfig struct {
+	TokenTTL      time.Duration // How long an activation link works
+	ActivationURL string        // Page the activation link opens; the token is added as ?token=
+}
+
+// LoadUserInvitationConfig reads the invitation configuration from the environment
+func LoadUserInvitationConfig() UserInvitationConfig {
+	config := UserInvitationConfig{
+		TokenTTL:      72 * time.Hour,
+		ActivationURL: os.Getenv("USER_ACTIVATION_URL"),
+	}
+	if hours, err := strconv.Atoi(os.Getenv("USER_INVITATION_TTL_HOURS")); err == nil && hours > 0 {
+		config.TokenTTL = time.Duration(hours) * time.Hour
+	}
+	if config.ActivationURL == "" {
+		config.ActivationURL = strings.TrimRight(os.Getenv("ADMIN_DASHBOARD_URL"), "/") + "/activate"
+	}
+	return config
+}
+
+// UserInvitationNotifier delivers activation links to invited users
+type UserInvitationNotifier interface {
+	SendInvitation(user, invitedBy *models.AdminUser, link string, expiresAt time.Time) error
+}
+
+// EmailUserInvitationNotifier sends activation links through the outbound mailer
+type EmailUserInvitationNotifier struct {
+	Mailer mailer.Mailer
+	From   mailer.Address
+}
+
+// SendInvitation implements UserInvitationNotifier
+func (n *EmailUserInvitationNotifier) SendInvitation(user, invitedBy *models.AdminUser, link string, expiresAt time.Time) error {
+	inviter := "An administrator"
+	if invitedBy != nil {
+		inviter = invitedBy.GetDisplayName()
+	}
+	hours := int(time.Until(expiresAt).Round(time.Hour).Hours())
+	return n.Mailer.Send(&mailer.Message{
+		From:    n.From,
+		To:      []mailer.Address{{Name: user.Name, Email: user.Email}},
+		Subject: "You have been invited to the contact dashboard",
+		PlainBody: fmt.Sprintf("Hello %s,\n\n"+
+			"%s has created an account for you. To activate it, open this link within %d hours and choose a password:\n\n"+
+			"%s\n\n"+
+			"The link works once. If you were not expecting this invitation, you can ignore this email.\n",
+			user.GetDisplayName(), inviter, hours, link),
+	})
+}
+
+// UserManagementService invites, activates, edits and deactivates admin users
+type UserManagementService struct {
+	db        *gorm.DB
+	users     repository.UserRepository
+	config    UserInvitationConfig
+	notifier  UserInvitationNotifier
+	passwords *PasswordService
+	sessions  *SessionService
+}
+
+// NewUserManagementService creates a new user management service
+func NewUserManagementService(db *gorm.DB, config UserInvitationConfig, notifier UserInvitationNotifier, passwords *PasswordService, sessions *SessionService) *UserManagementService {
+	return &UserManagementService{
+		db:        db,
+		users:     repository.NewUserRepository(db),
+		config:    config,
+		notifier:  notifier,
+		passwords: passwords,
+		sessions:  sessions,
+	}
+}
+
+// ListUsers returns a page of users
+func (s *UserManagementService) ListUsers(params repository.UserListParams) ([]models.AdminUser, int64, error) {
+	users, total, err := s.users.List(params)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %v", err)
+	}
+	return users, total, nil
+}
+
+// GetUser returns a user
+func (s *UserManagementService) GetUser(userID uint) (*models.AdminUser, error) {
+	return s.getUser(userID)
+}
+
+// InviteUser creates an inactive account and emails the user a link to activate it. When the email
+// cannot be sent the account and invitation are kept and returned with the error, so the
+// invitation can be resent.
+func (s *UserManagementService) InviteUser(req *models.InviteUserRequest, invitedBy uint) (*models.AdminUser, *models.UserInvitation, error) {
+	if err := requireRole(s.db, req.Role); err != nil {
+		return nil, nil, err
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if _, err := s.users.GetByEmail(email); err == nil {
+		return nil, nil, fmt.Errorf("user with this email already exists")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, fmt.Errorf("failed to check email: %v", err)
+	}
+
+	// No password until the invitation is accepted; an empty hash never matches one
+	user := &models.AdminUser{
+		Email:      email,
+		Name:       req.Name,
+		Role:       req.Role,
+		Phone:      req.Phone,
+		JobTitle:   req.JobTitle,
+		Department: req.Department,
+		Location:   req.Location,
+	}
+
+	var invitation *models.UserInvitation
+	var token string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewUserRepository(tx).Create(user); err != nil {
+			return fmt.Errorf("failed to create user: %v", err)
+		}
+		// is_active defaults to true, so a false value is not part of the insert
+		if err := tx.Model(&models.AdminUser{}).Where("id = ?", user.ID).Update("is_active", false).Error; err != nil {
+			return fmt.Errorf("failed to create user: %v", err)
+		}
+		user.IsActive = false
+
+		var err error
+		invitation, token, err = s.createInvitation(tx, user.ID, invitedBy)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.sendInvitation(user, invitedBy, token, invitation.ExpiresAt); err != nil {
+		return user, invitation, err
+	}
+
+	logger.Info("User invited", map[string]interface{}{
+		"user_id":    user.ID,
+		"email":      user.Email,
+		"role":       user.Role,
+		"invited_by": invitedBy,
+	})
+	return user, invitation, nil
+}
+
+// ResendInvitation emails a user who has not activated their account a new activation link; the
+// previous link stops working. A user deactivated before activating is invited again.
+func (s *UserManagementService) ResendInvitation(userID, invitedBy uint) (*models.AdminUser, *models.UserInvitation, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.PasswordHash != "" {
+		return nil, nil, fmt.Errorf("cannot resend invitation: user has already activated their account")
+	}
+
+	var invitation *models.UserInvitation
+	var token string
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if user.DeactivatedAt != nil {
+			if err := tx.Model(&models.AdminUser{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+				"deactivated_at": nil,
+				"deactivated_by": nil,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update user: %v", err)
+			}
+		}
+		var err error
+		invitation, token, err = s.createInvitation(tx, user.ID, invitedBy)
+		return err
+	}); err != nil {
+		return nil, nil, err
+	}
+	user.DeactivatedAt = nil
+	user.DeactivatedBy = nil
+
+	if err := s.sendInvitation(user, invitedBy, token, invitation.ExpiresAt); err != nil {
+		return nil, nil, err
+	}
+	return user, invitation, nil
+}
+
+// ActivateAccount accepts an invitation: the user sets their first password under the password
+// policy and the account becomes active
+func (s *UserManagementService) ActivateAccount(token, password string) (uint, error) {
+	var invitation models.UserInvitation
+	if err := s.db.Where("token_hash = ? AND accepted_at IS NULL AND expires_at > ?", models.HashInvitationToken(token), time.Now()).
+		First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("invalid or expired activation token")
+		}
+		return 0, fmt.Errorf("failed to get invitation: %v", err)
+	}
+
+	user, err := s.getUser(invitation.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if !user.IsInvitationPending() {
+		return 0, fmt.Errorf("invalid or expired activation token")
+	}
+	if err := s.passwords.checkNewPassword(user, password); err != nil {
+		return 0, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// An invitation accepted concurrently is only accepted once
+		result := tx.Model(&models.UserInvitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", time.Now())
+		if result.Error != nil {
+			return fmt.Errorf("failed to accept invitation: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("invalid or expired activation token")
+		}
+
+		return s.passwords.setPassword(tx, user, password, map[string]interface{}{
+			"is_active": true,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Info("User account activated", map[string]interface{}{
+		"user_id": user.ID,
+	})
+	return user.ID, nil
+}
+
+// UpdateRole changes a user's role. The user's sessions are ended, since their tokens carry the
+// old role.
+func (s *UserManagementService) UpdateRole(userID uint, role string, changedBy uint) (*models.AdminUser, error) {
+	if err := requireRole(s.db, role); err != nil {
+		return nil, err
+	}
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+	if user.IsAdmin() && user.IsActive {
+		if err := s.requireAnotherAdmin(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	previous := user.Role
+	if err := s.db.Model(&models.AdminUser{}).Where("id = ?", user.ID).Update("role", role).Error; err != nil {
+		return nil, fmt.Errorf("failed to update user role: %v", err)
+	}
+	user.Role = role
+	invalidatePermissions()
+
+	if _, err := s.sessions.RevokeAllSessions(user.ID, models.SessionRevokedRoleChanged); err != nil {
+		logger.Error("Failed to revoke sessions after role change", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+	}
+
+	logger.Info("User role changed", map[string]interface{}{
+		"user_id":       user.ID,
+		"previous_role": previous,
+		"role":          role,
+		"changed_by":    changedBy,
+	})
+	return user, nil
+}
+
+// DeactivateUser disables a user's account, ends their sessions and hands their open contacts to
+// req.ReassignTo, or unassigns them when it is not set
+func (s *UserManagementService) DeactivateUser(userID uint, req *models.DeactivateUserRequest, deactivatedBy uint) (*models.DeactivateUserResponse, error) {
+	if userID == deactivatedBy {
+		return nil, fmt.Errorf("cannot deactivate your own account")
+	}
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeactivatedAt != nil {
+		return nil, fmt.Errorf("cannot deactivate user: already deactivated")
+	}
+	if user.IsAdmin() && user.IsActive {
+		if err := s.requireAnotherAdmin(user.ID); err != nil {
+			return nil, err
+		}
+	}
+	if req.ReassignTo != nil {
+		if *req.ReassignTo == user.ID {
+			return nil, fmt.Errorf("cannot reassign contacts to the user being deactivated")
+		}
+		var assignee models.AdminUser
+		if err := s.db.Select("id").Where("id = ? AND is_active = ?", *req.ReassignTo, true).First(&assignee).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("invalid reassign_to: assignee is not an active user")
+			}
+			return nil, fmt.Errorf("failed to get assignee: %v", err)
+		}
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = fmt.Sprintf("Assignee %s was deactivated", user.GetDisplayName())
+	}
+
+	now := time.Now()
+	response := &models.DeactivateUserResponse{}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AdminUser{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"is_active":      false,
+			"deactivated_at": now,
+			"deactivated_by": deactivatedBy,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to deactivate user: %v", err)
+		}
+		// A deactivated user cannot accept an invitation still waiting for them
+		if err := tx.Where("user_id = ? AND accepted_at IS NULL", user.ID).Delete(&models.UserInvitation{}).Error; err != nil {
+			return fmt.Errorf("failed to cancel invitations: %v", err)
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.CalendarFeed{}).Error; err != nil {
+			return fmt.Errorf("failed to revoke calendar feed: %v", err)
+		}
+
+		reassigned, unassigned, err := (&AssignmentService{db: tx}).ReleaseUserContacts(user.ID, req.ReassignTo, deactivatedBy, reason)
+		if err != nil {
+			return err
+		}
+		response.ReassignedContacts = reassigned
+		response.UnassignedContacts = unassigned
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidatePermissions()
+
+	revoked, err := s.sessions.RevokeAllSessions(user.ID, models.SessionRevokedDeactivated)
+	if err != nil {
+		logger.Error("Failed to revoke sessions of deactivated user", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+	}
+	response.RevokedSessions = revoked
+
+	user.IsActive = false
+	user.DeactivatedAt = &now
+	user.DeactivatedBy = &deactivatedBy
+	response.User = user.ToResponse()
+
+	logger.Info("User deactivated", map[string]interface{}{
+		"user_id":             user.ID,
+		"deactivated_by":      deactivatedBy,
+		"reassign_to":         req.ReassignTo,
+		"reassigned_contacts": response.ReassignedContacts,
+		"unassigned_contacts": response.UnassignedContacts,
+	})
+	return response, nil
+}
+
+// ReactivateUser re-enables a deactivated account. Contacts released on deactivation stay where
+// they went.
+func (s *UserManagementService) ReactivateUser(userID, reactivatedBy uint) (*models.AdminUser, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsActive {
+		return nil, fmt.Errorf("cannot reactivate user: already active")
+	}
+	if user.PasswordHash == "" {
+		return nil, fmt.Errorf("cannot reactivate user: invitation not accepted; resend the invitation instead")
+	}
+
+	if err := s.db.Model(&models.AdminUser{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"is_active":      true,
+		"deactivated_at": nil,
+		"deactivated_by": nil,
+		"login_attempts": 0,
+		"locked_until":   nil,
+		"lockout_count":  0,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to reactivate user: %v", err)
+	}
+	invalidatePermissions()
+	user.IsActive = true
+	user.DeactivatedAt = nil
+	user.DeactivatedBy = nil
+	user.LoginAttempts = 0
+	user.LockedUntil = nil
+	user.LockoutCount = 0
+
+	logger.Info("User reactivated", map[string]interface{}{
+		"user_id":        user.ID,
+		"reactivated_by": reactivatedBy,
+	})
+	return user, nil
+}
+
+// createInvitation replaces a user's pending invitations with a new one and returns its token
+func (s *UserManagementService) createInvitation(tx *gorm.DB, userID, invitedBy uint) (*models.UserInvitation, string, error) {
+	token, hash, err := models.NewInvitationToken()
+	if err != nil {
+		return nil, "", err
+	}
+	invitation := &models.UserInvitation{
+		UserID:    userID,
+		TokenHash: hash,
+		InvitedBy: &invitedBy,
+		ExpiresAt: time.Now().Add(s.config.TokenTTL),
+	}
+
+	if err := tx.Where("user_id = ? AND accepted_at IS NULL", userID).Delete(&models.UserInvitation{}).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to invalidate invitations: %v", err)
+	}
+	if err := tx.Create(invitation).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to save invitation: %v", err)
+	}
+	return invitation, token, nil
+}
+
+func (s *UserManagementService) sendInvitation(user *models.AdminUser, invitedBy uint, token string, expiresAt time.Time) error {
+	inviter, _ := s.users.GetByID(invitedBy)
+	link := s.config.ActivationURL + "?token=" + url.QueryEscape(token)
+	if err := s.notifier.SendInvitation(user, inviter, link, expiresAt); err != nil {
+		return fmt.Errorf("failed to send invitation: %v", err)
+	}
+	return nil
+}
+
+// requireAnotherAdmin refuses changes that would leave no active admin besides userID
+func (s *UserManagementService) requireAnotherAdmin(userID uint) error {
+	var admins int64
+	if err := s.db.Model(&models.AdminUser{}).
+		Where("role = ? AND is_active = ? AND id <> ? AND deleted_at IS NULL", "admin", true, userID).
+		Count(&admins).Error; err != nil {
+		return fmt.Errorf("failed to count admins: %v", err)
+	}
+	if admins == 0 {
+		return fmt.Errorf("cannot remove the last active admin")
+	}
+	return nil
+}
+
+func (s *UserManagementService) getUser(userID uint) (*models.AdminUser, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+	return user, nil
+}
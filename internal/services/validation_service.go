@@ -0,0 +1,264 @@
+package services
+
+import (
+	"contact-service/internal/models"
+	"contact-service/internal/repository"
+	apperrors "contact-service/pkg/errors"
+	"contact-service/pkg/logger"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Built-in checks that a custom rule can name in its validation_pattern
+var customValidators = map[string]func(value string) bool{
+	"email": func(value string) bool {
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	},
+	"url": func(value string) bool {
+		parsed, err := url.ParseRequestURI(value)
+		return err == nil && parsed.Scheme != "" && parsed.Host != ""
+	},
+	"e164_phone": func(value string) bool {
+		return e164Pattern.MatchString(value)
+	},
+	"no_html": func(value string) bool {
+		return !strings.ContainsAny(value, "<>")
+	},
+}
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9]\d{6,14}$`)
+
+// Compiled validation patterns keyed by pattern text
+var validationPatternCache sync.Map
+
+// ValidationResult holds the rule violations found for a contact
+type ValidationResult struct {
+	Errors   []apperrors.FieldError `json:"errors,omitempty"`
+	Warnings []apperrors.FieldError `json:"warnings,omitempty"` // Warning and info severity violations
+}
+
+// HasErrors reports whether any error severity rule failed
+func (r *ValidationResult) HasErrors() bool {
+	return len(r.Errors) > 0
+}
+
+// Err returns a validation AppError carrying every violation, or nil when no error severity rule failed
+func (r *ValidationResult) Err() error {
+	if !r.HasErrors() {
+		return nil
+	}
+	fieldErrors := append(append([]apperrors.FieldError{}, r.Errors...), r.Warnings...)
+	return apperrors.NewValidationError("Contact failed validation", fieldErrors...)
+}
+
+// ValidationService evaluates contact_validation_rules against contacts
+type ValidationService struct {
+	ruleRepo repository.ValidationRuleRepository
+}
+
+// NewValidationService creates a new validation service
+func NewValidationService(ruleRepo repository.ValidationRuleRepository) *ValidationService {
+	return &ValidationService{ruleRepo: ruleRepo}
+}
+
+// ValidateContact loads the rules for a channel, evaluates them and records their usage
+func (s *ValidationService) ValidateContact(contact *models.Contact, channel models.ValidationChannel) (*ValidationResult, error) {
+	rules, err := s.LoadRules(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[uint]int, len(rules))
+	result := s.Evaluate(rules, contact, usage)
+	s.RecordUsage(usage)
+	return result, nil
+}
+
+// LoadRules retrieves the active rules for a channel in execution order
+func (s *ValidationService) LoadRules(channel models.ValidationChannel) ([]models.ContactValidationRule, error) {
+	rules, err := s.ruleRepo.ListActive(channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load validation rules: %v", err)
+	}
+	return rules, nil
+}
+
+// Evaluate runs the rules against a contact and adds one to usage for every rule executed.
+// Callers validating many contacts load the rules once and record usage after the batch.
+func (s *ValidationService) Evaluate(rules []models.ContactValidationRule, contact *models.Contact, usage map[uint]int) *ValidationResult {
+	result := &ValidationResult{}
+	values := contact.FieldValues()
+
+	for i := range rules {
+		rule := &rules[i]
+		if !models.IsContactField(rule.FieldName) {
+			logger.Warn("Skipping validation rule for unknown field", map[string]interface{}{
+				"rule_id": rule.ID,
+				"field":   rule.FieldName,
+			})
+			continue
+		}
+
+		value := ""
+		if v := values[rule.FieldName]; v != nil {
+			value = strings.TrimSpace(*v)
+		}
+
+		passed, err := s.check(rule, value, contact.ID)
+		if usage != nil {
+			usage[rule.ID]++
+		}
+		if err != nil {
+			logger.Warn("Skipping misconfigured validation rule", map[string]interface{}{
+				"rule_id": rule.ID,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		if passed {
+			continue
+		}
+
+		severity := rule.Severity
+		if severity == "" {
+			severity = models.ValidationSeverityError
+		}
+		violation := apperrors.FieldError{
+			Field:    rule.FieldName,
+			Message:  ruleErrorMessage(rule),
+			Code:     string(rule.RuleType),
+			Severity: string(severity),
+		}
+		if value != "" {
+			violation.Value = value
+		}
+
+		if severity == models.ValidationSeverityError {
+			result.Errors = append(result.Errors, violation)
+		} else {
+			result.Warnings = append(result.Warnings, violation)
+		}
+	}
+
+	return result
+}
+
+// RecordUsage persists rule usage counts; failures are logged so they never block a write
+func (s *ValidationService) RecordUsage(usage map[uint]int) {
+	if len(usage) == 0 {
+		return
+	}
+	if err := s.ruleRepo.RecordUsage(usage); err != nil {
+		logger.Error("Failed to record validation rule usage", err, nil)
+	}
+}
+
+// check reports whether value satisfies the rule. Rules other than required pass on empty values.
+func (s *ValidationService) check(rule *models.ContactValidationRule, value string, contactID uint) (bool, error) {
+	pattern := ""
+	if rule.ValidationPattern != nil {
+		pattern = strings.TrimSpace(*rule.ValidationPattern)
+	}
+
+	if rule.RuleType == models.RuleTypeRequired {
+		return value != "", nil
+	}
+	if value == "" {
+		return true, nil
+	}
+
+	switch rule.RuleType {
+	case models.RuleTypeFormat:
+		re, err := compileValidationPattern(pattern)
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(value), nil
+
+	case models.RuleTypeLength:
+		min, max, err := parseRuleBounds(pattern)
+		if err != nil {
+			return false, err
+		}
+		length := float64(utf8.RuneCountInString(value))
+		return length >= min && length <= max, nil
+
+	case models.RuleTypeRange:
+		min, max, err := parseRuleBounds(pattern)
+		if err != nil {
+			return false, err
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false, nil
+		}
+		return number >= min && number <= max, nil
+
+	case models.RuleTypeCustom:
+		validator, ok := customValidators[pattern]
+		if !ok {
+			return false, fmt.Errorf("unknown custom validator: %s", pattern)
+		}
+		return validator(value), nil
+
+	case models.RuleTypeUniqueness:
+		count, err := s.ruleRepo.CountContactsWithValue(rule.FieldName, value, contactID)
+		if err != nil {
+			return false, err
+		}
+		return count == 0, nil
+	}
+
+	return false, fmt.Errorf("unknown rule type: %s", rule.RuleType)
+}
+
+func compileValidationPattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := validationPatternCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	validationPatternCache.Store(pattern, re)
+	return re, nil
+}
+
+// parseRuleBounds parses a "min,max" pattern; either side may be left empty
+func parseRuleBounds(pattern string) (float64, float64, error) {
+	parts := strings.Split(pattern, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("bounds must be \"min,max\", got %q", pattern)
+	}
+
+	min, max := -1e308, 1e308
+	if s := strings.TrimSpace(parts[0]); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid minimum %q", s)
+		}
+		min = v
+	}
+	if s := strings.TrimSpace(parts[1]); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid maximum %q", s)
+		}
+		max = v
+	}
+	return min, max, nil
+}
+
+func ruleErrorMessage(rule *models.ContactValidationRule) string {
+	if rule.ErrorMessage != nil && *rule.ErrorMessage != "" {
+		return *rule.ErrorMessage
+	}
+	return fmt.Sprintf("%s failed %s validation", rule.FieldName, rule.RuleType)
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"contact-service/internal/models"
+	"contact-service/internal/repository"
+	apperrors "contact-service/pkg/errors"
+)
+
+// newValidationTestService returns a validation service on an in-memory database
+func newValidationTestService(t *testing.T) (*ValidationService, *gorm.DB) {
+	db := newTestDB(t, &models.Contact{}, &models.ContactValidationRule{})
+	return NewValidationService(repository.NewValidationRuleRepository(db)), db
+}
+
+// createValidationTestRule creates an active rule that applies to every channel
+func createValidationTestRule(t *testing.T, db *gorm.DB, field string, ruleType models.ValidationRuleType, pattern string, severity models.ValidationSeverity) *models.ContactValidationRule {
+	rule := &models.ContactValidationRule{
+		Name:             field + " " + string(ruleType),
+		FieldName:        field,
+		RuleType:         ruleType,
+		Severity:         severity,
+		IsActive:         true,
+		AppliesToImports: true,
+		AppliesToAPI:     true,
+		AppliesToManual:  true,
+	}
+	if pattern != "" {
+		rule.ValidationPattern = &pattern
+	}
+	require.NoError(t, db.Create(rule).Error)
+	return rule
+}
+
+func validationTestContact() *models.Contact {
+	message := "<b>Call me</b>"
+	return &models.Contact{
+		FirstName: "A",
+		Email:     "asha@example.com",
+		Message:   &message,
+	}
+}
+
+func TestValidateContactBlocksOnErrorSeverity(t *testing.T) {
+	service, db := newValidationTestService(t)
+	createValidationTestRule(t, db, "company", models.RuleTypeRequired, "", models.ValidationSeverityError)
+	createValidationTestRule(t, db, "message", models.RuleTypeCustom, "no_html", models.ValidationSeverityWarning)
+	createValidationTestRule(t, db, "email", models.RuleTypeCustom, "email", models.ValidationSeverityError)
+
+	result, err := service.ValidateContact(validationTestContact(), models.ValidationChannelAPI)
+	require.NoError(t, err)
+	assert.True(t, result.HasErrors())
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "company", result.Errors[0].Field)
+	assert.Equal(t, string(models.RuleTypeRequired), result.Errors[0].Code)
+	assert.Equal(t, string(models.ValidationSeverityError), result.Errors[0].Severity)
+	require.Len(t, result.Warnings, 1)
+	assert.Equal(t, "message", result.Warnings[0].Field)
+	assert.Equal(t, "<b>Call me</b>", result.Warnings[0].Value)
+
+	appErr, ok := result.Err().(*apperrors.AppError)
+	require.True(t, ok, "blocking violations did not produce a validation error")
+	assert.Equal(t, apperrors.ErrCodeValidation, appErr.Code)
+	assert.Len(t, appErr.FieldErrors, 2, "the validation error should carry the warnings too")
+}
+
+func TestValidateContactWarningsDoNotBlock(t *testing.T) {
+	service, db := newValidationTestService(t)
+	createValidationTestRule(t, db, "message", models.RuleTypeCustom, "no_html", models.ValidationSeverityWarning)
+	createValidationTestRule(t, db, "first_name", models.RuleTypeLength, "2,100", models.ValidationSeverityInfo)
+
+	result, err := service.ValidateContact(validationTestContact(), models.ValidationChannelAPI)
+	require.NoError(t, err)
+	assert.False(t, result.HasErrors())
+	assert.NoError(t, result.Err())
+	require.Len(t, result.Warnings, 2)
+	assert.Equal(t, string(models.ValidationSeverityWarning), result.Warnings[0].Severity)
+	assert.Equal(t, string(models.ValidationSeverityInfo), result.Warnings[1].Severity)
+}
+
+func TestEvaluateTreatsMissingSeverityAsError(t *testing.T) {
+	service, _ := newValidationTestService(t)
+	rules := []models.ContactValidationRule{{ID: 1, FieldName: "company", RuleType: models.RuleTypeRequired}}
+
+	result := service.Evaluate(rules, validationTestContact(), nil)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, string(models.ValidationSeverityError), result.Errors[0].Severity)
+	assert.Error(t, result.Err())
+}
+
+func TestEvaluateSkipsMisconfiguredRules(t *testing.T) {
+	service, db := newValidationTestService(t)
+	rule := createValidationTestRule(t, db, "first_name", models.RuleTypeFormat, "([", models.ValidationSeverityError)
+
+	result, err := service.ValidateContact(validationTestContact(), models.ValidationChannelAPI)
+	require.NoError(t, err)
+	assert.False(t, result.HasErrors(), "a rule with an invalid pattern blocked the write")
+
+	require.NoError(t, db.First(rule, rule.ID).Error)
+	assert.Equal(t, 1, rule.UsageCount)
+	assert.NotNil(t, rule.LastUsedAt)
+}
+
+func TestValidateContactOnlyRunsChannelRules(t *testing.T) {
+	service, db := newValidationTestService(t)
+	rule := createValidationTestRule(t, db, "company", models.RuleTypeRequired, "", models.ValidationSeverityError)
+	require.NoError(t, db.Model(rule).Update("applies_to_imports", false).Error)
+
+	result, err := service.ValidateContact(validationTestContact(), models.ValidationChannelImport)
+	require.NoError(t, err)
+	assert.False(t, result.HasErrors())
+
+	result, err = service.ValidateContact(validationTestContact(), models.ValidationChannelAPI)
+	require.NoError(t, err)
+	assert.True(t, result.HasErrors())
+}
@@ -0,0 +1,9 @@
+-- Migration: Track undone contact merges
+-- Created: 2025-01-01 16:00:00
+-- Description: Adds unmerge audit columns to contact_merge_history so a merge can be reversed once
+
+ALTER TABLE contact_merge_history
+    ADD COLUMN unmerged_at TIMESTAMP NULL AFTER merge_notes,
+    ADD COLUMN unmerged_by INT NULL AFTER unmerged_at,
+    ADD COLUMN unmerge_notes TEXT AFTER unmerged_by,
+    ADD INDEX idx_unmerged_at (unmerged_at);
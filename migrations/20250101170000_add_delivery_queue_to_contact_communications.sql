@@ -0,0 +1,11 @@
+-- Migration: Outbound email delivery queue
+-- Created: 2025-01-01 17:00:00
+-- Description: Adds a queued status and retry bookkeeping to contact_communications so outbound email can be sent by background workers
+
+ALTER TABLE contact_communications
+    MODIFY COLUMN status ENUM('draft', 'queued', 'sending', 'sent', 'delivered', 'read', 'replied', 'failed', 'bounced') DEFAULT 'draft',
+    ADD COLUMN send_attempts INT DEFAULT 0 AFTER replied_at,
+    ADD COLUMN max_send_attempts INT DEFAULT 3 AFTER send_attempts,
+    ADD COLUMN next_attempt_at TIMESTAMP NULL AFTER max_send_attempts,
+    ADD COLUMN last_error TEXT AFTER next_attempt_at,
+    ADD INDEX idx_status_next_attempt (status, next_attempt_at);
@@ -0,0 +1,9 @@
+-- Migration: Appointment reminder dispatch claims
+-- Created: 2025-01-01 18:00:00
+-- Description: Adds a sending status and claim columns so reminder dispatchers on several replicas never send the same reminder twice
+
+ALTER TABLE appointment_reminders
+    MODIFY COLUMN status ENUM('scheduled', 'sending', 'sent', 'failed', 'cancelled') DEFAULT 'scheduled',
+    ADD COLUMN claimed_by VARCHAR(100) NULL AFTER clicked,
+    ADD COLUMN claimed_at TIMESTAMP NULL AFTER claimed_by,
+    ADD INDEX idx_status_send_time (status, scheduled_send_time);
@@ -0,0 +1,53 @@
+-- Migration: Recurring appointment series
+-- Created: 2025-01-01 19:00:00
+-- Description: Creates appointment_series for RRULE-based recurrence and links materialized occurrences to their series
+
+CREATE TABLE IF NOT EXISTS appointment_series (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    contact_id INT NOT NULL,
+    assigned_to INT UNSIGNED NOT NULL,
+
+    -- Recurrence
+    rrule VARCHAR(500) NOT NULL, -- RFC 5545 RRULE, e.g. FREQ=WEEKLY;BYDAY=TU;COUNT=12
+    dtstart DATETIME NOT NULL,
+    timezone VARCHAR(50) DEFAULT 'Asia/Kolkata',
+    exdates JSON, -- Excluded occurrence dates (YYYY-MM-DD)
+    duration_minutes INT DEFAULT 60,
+    materialized_until DATETIME NULL, -- Occurrences up to this time exist as appointments
+    status ENUM('active', 'ended', 'cancelled') DEFAULT 'active',
+    parent_series_id INT NULL, -- Series split off by a "this and following" edit
+
+    -- Occurrence Template
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    appointment_type ENUM('consultation', 'demo', 'meeting', 'call', 'presentation', 'follow_up', 'other') DEFAULT 'consultation',
+    priority ENUM('low', 'medium', 'high', 'urgent') DEFAULT 'medium',
+    meeting_type ENUM('in_person', 'video_call', 'phone_call', 'hybrid') DEFAULT 'video_call',
+    location VARCHAR(500),
+    meeting_link VARCHAR(500),
+    meeting_id VARCHAR(100),
+    meeting_password VARCHAR(100),
+    phone_number VARCHAR(20),
+    reminders JSON, -- Reminder settings copied to each occurrence; NULL uses the defaults
+
+    -- Audit Fields
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    created_by INT UNSIGNED,
+    updated_by INT UNSIGNED,
+
+    INDEX idx_appointment_series_contact (contact_id),
+    INDEX idx_appointment_series_assigned (assigned_to),
+    INDEX idx_appointment_series_horizon (status, materialized_until),
+    INDEX idx_appointment_series_parent (parent_series_id),
+
+    FOREIGN KEY (contact_id) REFERENCES contacts(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_series_id) REFERENCES appointment_series(id) ON DELETE SET NULL
+);
+
+ALTER TABLE appointments
+    ADD COLUMN series_id INT NULL AFTER booking_source,
+    ADD COLUMN recurrence_id DATETIME NULL AFTER series_id,
+    ADD COLUMN is_series_exception BOOLEAN DEFAULT FALSE AFTER recurrence_id,
+    ADD UNIQUE INDEX idx_appointments_series_occurrence (series_id, recurrence_id),
+    ADD FOREIGN KEY (series_id) REFERENCES appointment_series(id) ON DELETE SET NULL;
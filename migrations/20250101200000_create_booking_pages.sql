@@ -0,0 +1,63 @@
+-- Migration: Public self-service booking
+-- Created: 2025-01-01 20:00:00
+-- Description: Creates booking_pages (public booking links for a host pool) and booking_holds (short-lived slot reservations), and links appointments to the page they were booked through
+
+CREATE TABLE IF NOT EXISTS booking_pages (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    slug VARCHAR(100) NOT NULL UNIQUE, -- Public URL segment, e.g. /public/booking/sales-demo
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+
+    -- Host Pool
+    host_user_ids JSON NOT NULL, -- Array of user IDs who take bookings
+    assignment_mode ENUM('round_robin', 'first_available') DEFAULT 'round_robin',
+    last_assigned_user_id INT UNSIGNED NULL, -- Round-robin cursor
+
+    -- Booking Rules
+    duration_minutes INT DEFAULT 30,
+    buffer_minutes INT DEFAULT 0, -- Free time kept around the host's other appointments
+    min_notice_minutes INT DEFAULT 240,
+    max_days_ahead INT DEFAULT 30,
+    timezone VARCHAR(50) DEFAULT 'Asia/Kolkata', -- Default display timezone for visitors
+
+    -- Appointment Template
+    appointment_type ENUM('consultation', 'demo', 'meeting', 'call', 'presentation', 'follow_up', 'other') DEFAULT 'consultation',
+    meeting_type ENUM('in_person', 'video_call', 'phone_call', 'hybrid') DEFAULT 'video_call',
+    location VARCHAR(500),
+    meeting_link VARCHAR(500),
+    contact_type_id INT NULL, -- Type and source of contacts created by bookings
+    contact_source_id INT NULL,
+
+    is_active BOOLEAN DEFAULT TRUE,
+
+    -- Audit Fields
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    created_by INT UNSIGNED,
+    updated_by INT UNSIGNED,
+    deleted_at TIMESTAMP NULL,
+
+    INDEX idx_booking_pages_active (is_active),
+    INDEX idx_booking_pages_deleted_at (deleted_at)
+);
+
+CREATE TABLE IF NOT EXISTS booking_holds (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    booking_page_id INT NOT NULL,
+    user_id INT UNSIGNED NOT NULL, -- Host the slot is held with
+    start_time DATETIME NOT NULL,
+    end_time DATETIME NOT NULL,
+    token VARCHAR(64) NOT NULL UNIQUE, -- Returned to the visitor and presented when booking
+    expires_at DATETIME NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_booking_holds_user_time (user_id, start_time),
+    INDEX idx_booking_holds_expires (expires_at),
+
+    FOREIGN KEY (booking_page_id) REFERENCES booking_pages(id) ON DELETE CASCADE
+);
+
+ALTER TABLE appointments
+    ADD COLUMN booking_page_id INT NULL AFTER booking_source,
+    ADD INDEX idx_appointments_booking_page (booking_page_id),
+    ADD FOREIGN KEY (booking_page_id) REFERENCES booking_pages(id) ON DELETE SET NULL;
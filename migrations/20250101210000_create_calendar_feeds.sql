@@ -0,0 +1,15 @@
+-- Migration: iCalendar feeds and invitations
+-- Created: 2025-01-01 21:00:00
+-- Description: Creates calendar_feeds (per-user secret feed tokens) and tracks the iCalendar SEQUENCE of each appointment
+
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL UNIQUE,
+    token VARCHAR(64) NOT NULL UNIQUE, -- Secret in the subscription URL; rotating it revokes the old URL
+    last_accessed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+ALTER TABLE appointments
+    ADD COLUMN calendar_sequence INT DEFAULT 0 AFTER calendar_event_id; -- Incremented on every change sent to calendars
@@ -0,0 +1,36 @@
+-- Migration: External calendar sync
+-- Created: 2025-01-01 22:00:00
+-- Description: Creates calendar_sync_events (appointments pushed to each integration) and calendar_busy_blocks (busy time pulled from external calendars)
+
+CREATE TABLE IF NOT EXISTS calendar_sync_events (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    integration_id INT UNSIGNED NOT NULL,
+    appointment_id INT NOT NULL,
+    remote_href VARCHAR(1000) NOT NULL, -- Location of the event on the external calendar
+    remote_etag VARCHAR(255),
+    synced_sequence INT NOT NULL DEFAULT 0, -- Appointment calendar_sequence at the last push
+    synced_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_calendar_sync_events (integration_id, appointment_id),
+    INDEX idx_calendar_sync_events_appointment (appointment_id),
+
+    FOREIGN KEY (integration_id) REFERENCES calendar_integrations(id) ON DELETE CASCADE,
+    FOREIGN KEY (appointment_id) REFERENCES appointments(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS calendar_busy_blocks (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    integration_id INT UNSIGNED NOT NULL,
+    user_id INT UNSIGNED NOT NULL,
+    external_uid VARCHAR(500) NOT NULL,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_calendar_busy_blocks_user_time (user_id, start_time, end_time),
+    INDEX idx_calendar_busy_blocks_integration (integration_id),
+
+    FOREIGN KEY (integration_id) REFERENCES calendar_integrations(id) ON DELETE CASCADE
+);
@@ -0,0 +1,21 @@
+-- Migration: In-app notifications
+-- Created: 2025-01-01 23:00:00
+-- Description: Creates notifications, the in-app inbox for admin users (e.g. status transition rule notify_users)
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id INT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+    user_id INT UNSIGNED NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT,
+    entity_type VARCHAR(50), -- What the notification is about, e.g. contact
+    entity_id INT UNSIGNED,
+    data JSON,
+    read_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_notifications_user_unread (user_id, read_at),
+    INDEX idx_notifications_user_created (user_id, created_at),
+
+    FOREIGN KEY (user_id) REFERENCES admin_users(id) ON DELETE CASCADE
+);
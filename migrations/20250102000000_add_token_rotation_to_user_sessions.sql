@@ -0,0 +1,10 @@
+-- Migration: Server-side sessions with refresh token rotation
+-- Created: 2025-01-02 00:00:00
+-- Description: Binds access and refresh tokens to user_sessions so sessions can be revoked, expire when idle and detect refresh token reuse
+
+ALTER TABLE user_sessions
+    ADD COLUMN refresh_token_id VARCHAR(64) NULL AFTER session_token,
+    ADD COLUMN refreshed_at TIMESTAMP NULL AFTER last_activity,
+    ADD COLUMN expires_at TIMESTAMP NULL AFTER refreshed_at,
+    ADD COLUMN revoked_reason VARCHAR(50) NULL AFTER logout_at,
+    ADD INDEX idx_user_sessions_user_active (user_id, is_active);
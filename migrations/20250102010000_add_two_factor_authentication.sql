@@ -0,0 +1,37 @@
+-- Migration: Two-factor authentication
+-- Created: 2025-01-02 01:00:00
+-- Description: TOTP secrets for admin users, hashed one-time recovery codes, per-role two-factor policies and the two-factor state of sessions
+
+ALTER TABLE admin_users
+    ADD COLUMN two_factor_secret VARCHAR(64) NULL AFTER two_factor_enabled,
+    ADD COLUMN two_factor_confirmed_at TIMESTAMP NULL AFTER two_factor_secret,
+    ADD COLUMN two_factor_last_step BIGINT NULL AFTER two_factor_confirmed_at;
+
+ALTER TABLE user_sessions
+    ADD COLUMN mfa_verified BOOLEAN DEFAULT FALSE AFTER is_active;
+
+-- One-time recovery codes, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id INT UNSIGNED NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_user_recovery_codes_user (user_id),
+    UNIQUE KEY uk_user_recovery_codes_code (user_id, code_hash),
+
+    FOREIGN KEY (user_id) REFERENCES admin_users(id) ON DELETE CASCADE
+);
+
+-- Roles whose users must use two-factor authentication for admin and manager endpoints
+CREATE TABLE IF NOT EXISTS two_factor_policies (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    role VARCHAR(50) NOT NULL UNIQUE,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by INT UNSIGNED NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (updated_by) REFERENCES admin_users(id) ON DELETE SET NULL
+);
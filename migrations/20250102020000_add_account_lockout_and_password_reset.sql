@@ -0,0 +1,34 @@
+-- Migration: Account lockout and password reset
+-- Created: 2025-01-02 02:00:00
+-- Description: Time-based login lockout with exponential back-off, single-use password reset tokens and password history
+
+ALTER TABLE admin_users
+    ADD COLUMN locked_until TIMESTAMP NULL AFTER login_attempts,
+    ADD COLUMN lockout_count INT DEFAULT 0 AFTER locked_until;
+
+-- Password reset tokens, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id INT UNSIGNED NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    requested_ip VARCHAR(45),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_password_reset_tokens_user (user_id, created_at),
+
+    FOREIGN KEY (user_id) REFERENCES admin_users(id) ON DELETE CASCADE
+);
+
+-- Previous password hashes, so passwords are not reused
+CREATE TABLE IF NOT EXISTS password_history (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id INT UNSIGNED NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_password_history_user (user_id, created_at),
+
+    FOREIGN KEY (user_id) REFERENCES admin_users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,27 @@
+-- Migration: User invitations and account deactivation
+-- Created: 2025-01-02 03:00:00
+-- Description: Emailed activation tokens for invited admin users, deactivation tracking, and unassignment entries in assignment history
+
+ALTER TABLE admin_users
+    ADD COLUMN deactivated_at TIMESTAMP NULL AFTER is_active,
+    ADD COLUMN deactivated_by INT UNSIGNED NULL AFTER deactivated_at;
+
+-- Invitations, with the activation token stored as a SHA-256 hash
+CREATE TABLE IF NOT EXISTS user_invitations (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id INT UNSIGNED NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    invited_by INT UNSIGNED,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_user_invitations_user (user_id, accepted_at),
+
+    FOREIGN KEY (user_id) REFERENCES admin_users(id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES admin_users(id) ON DELETE SET NULL
+);
+
+-- Unassigning a contact leaves it without a new assignee
+ALTER TABLE assignment_history
+    MODIFY COLUMN to_user_id INT UNSIGNED NULL;
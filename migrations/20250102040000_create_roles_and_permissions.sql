@@ -0,0 +1,82 @@
+-- Migration: Roles and permissions
+-- Created: 2025-01-02 04:00:00
+-- Description: Database-backed roles with their permissions and per-user permission overrides, replacing the hard-coded role map and the admin_users.role enum
+
+CREATE TABLE IF NOT EXISTS roles (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255),
+    is_system BOOLEAN NOT NULL DEFAULT FALSE, -- System roles cannot be changed or deleted
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- Permissions are "resource:action", "resource:*" or "*"
+CREATE TABLE IF NOT EXISTS role_permissions (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    role_id INT UNSIGNED NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_role_permissions (role_id, permission),
+
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE
+);
+
+-- Permissions granted to or denied from one user on top of their role; denials win
+CREATE TABLE IF NOT EXISTS user_permission_overrides (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id INT UNSIGNED NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    effect ENUM('grant', 'deny') NOT NULL,
+    granted_by INT UNSIGNED NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_user_permission_overrides (user_id, permission),
+
+    FOREIGN KEY (user_id) REFERENCES admin_users(id) ON DELETE CASCADE,
+    FOREIGN KEY (granted_by) REFERENCES admin_users(id) ON DELETE SET NULL
+);
+
+-- The roles and permissions that used to be hard-coded
+INSERT IGNORE INTO roles (name, description, is_system) VALUES
+    ('admin', 'Full access to everything', TRUE),
+    ('hr_manager', 'Manages contacts, assignments, analytics and bulk operations', FALSE),
+    ('editor', 'Works contacts and activities', FALSE),
+    ('content_writer', 'Reads contacts and writes blog content', FALSE);
+
+INSERT IGNORE INTO role_permissions (role_id, permission)
+SELECT r.id, p.permission FROM roles r JOIN (
+    SELECT 'admin' AS role, '*' AS permission
+    UNION ALL SELECT 'hr_manager', 'contacts:read'
+    UNION ALL SELECT 'hr_manager', 'contacts:write'
+    UNION ALL SELECT 'hr_manager', 'contacts:update'
+    UNION ALL SELECT 'hr_manager', 'contacts:assign'
+    UNION ALL SELECT 'hr_manager', 'activities:read'
+    UNION ALL SELECT 'hr_manager', 'activities:write'
+    UNION ALL SELECT 'hr_manager', 'analytics:read'
+    UNION ALL SELECT 'hr_manager', 'search:read'
+    UNION ALL SELECT 'hr_manager', 'search:write'
+    UNION ALL SELECT 'hr_manager', 'bulk:read'
+    UNION ALL SELECT 'hr_manager', 'bulk:write'
+    UNION ALL SELECT 'editor', 'contacts:read'
+    UNION ALL SELECT 'editor', 'contacts:write'
+    UNION ALL SELECT 'editor', 'contacts:update'
+    UNION ALL SELECT 'editor', 'activities:read'
+    UNION ALL SELECT 'editor', 'activities:write'
+    UNION ALL SELECT 'editor', 'search:read'
+    UNION ALL SELECT 'editor', 'search:write'
+    UNION ALL SELECT 'editor', 'blogs:read'
+    UNION ALL SELECT 'editor', 'blogs:write'
+    UNION ALL SELECT 'content_writer', 'contacts:read'
+    UNION ALL SELECT 'content_writer', 'activities:read'
+    UNION ALL SELECT 'content_writer', 'search:read'
+    UNION ALL SELECT 'content_writer', 'blogs:read'
+    UNION ALL SELECT 'content_writer', 'blogs:write'
+) p ON p.role = r.name;
+
+-- Roles are no longer a fixed enum; a user's role must exist and cannot be deleted while in use
+ALTER TABLE admin_users
+    MODIFY COLUMN role VARCHAR(50) NOT NULL DEFAULT 'editor',
+    ADD CONSTRAINT fk_admin_users_role FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
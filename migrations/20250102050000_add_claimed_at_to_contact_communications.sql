@@ -0,0 +1,7 @@
+-- Migration: Outbound email claim time
+-- Created: 2025-01-02 05:00:00
+-- Description: Records when a worker claimed an outbound email so only abandoned sends are requeued, never ones another replica is still delivering
+
+ALTER TABLE contact_communications
+    ADD COLUMN claimed_at TIMESTAMP NULL AFTER next_attempt_at,
+    ADD INDEX idx_status_claimed_at (status, claimed_at);
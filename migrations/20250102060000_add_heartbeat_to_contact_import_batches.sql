@@ -0,0 +1,7 @@
+-- Migration: Import batch heartbeat
+-- Created: 2025-01-02 06:00:00
+-- Description: Records when a running import last reported progress so imports orphaned by a stopped instance can be marked failed
+
+ALTER TABLE contact_import_batches
+    ADD COLUMN heartbeat_at TIMESTAMP NULL AFTER completed_at,
+    ADD INDEX idx_status_heartbeat (status, heartbeat_at);
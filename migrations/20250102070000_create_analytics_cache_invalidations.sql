@@ -0,0 +1,12 @@
+-- Migration: Analytics cache invalidations
+-- Created: 2025-01-02 07:00:00
+-- Description: Records contact, appointment and activity writes in their own transaction so analytics cache entries are invalidated only once the write has committed
+
+CREATE TABLE analytics_cache_invalidations (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    metric_type VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    applied_at TIMESTAMP NULL,
+    INDEX idx_metric_applied (metric_type, applied_at),
+    INDEX idx_applied_at (applied_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,6 @@
+-- Migration: Contact merge permission
+-- Created: 2025-01-02 08:00:00
+-- Description: Merging duplicates soft-deletes contacts and re-parents their records, so it requires contacts:merge; admins have it through "*"
+
+INSERT IGNORE INTO role_permissions (role_id, permission)
+SELECT id, 'contacts:merge' FROM roles WHERE name = 'hr_manager';
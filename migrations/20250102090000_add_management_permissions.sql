@@ -0,0 +1,6 @@
+-- Migration: Management permissions
+-- Created: 2025-01-02 09:00:00
+-- Description: Route guards that used to check for the admin or manager role now check permissions (users:manage, roles:manage, booking_pages:manage, lifecycle:manage, and exports/imports/calendar/availability:manage to act on other users' records); admins have them through "*", and HR managers keep managing booking pages
+
+INSERT IGNORE INTO role_permissions (role_id, permission)
+SELECT id, 'booking_pages:manage' FROM roles WHERE name = 'hr_manager';
@@ -0,0 +1,18 @@
+-- Migration: Hash calendar feed tokens
+-- Created: 2025-01-02 10:00:00
+-- Description: Stores the SHA-256 of each feed token instead of the token, so existing subscription URLs keep working but cannot be read back from the database
+
+ALTER TABLE calendar_feeds
+    ADD COLUMN token_hash VARCHAR(64) NULL AFTER user_id;
+
+UPDATE calendar_feeds SET token_hash = SHA2(token, 256);
+
+ALTER TABLE calendar_feeds
+    MODIFY COLUMN token_hash VARCHAR(64) NOT NULL,
+    ADD UNIQUE KEY uk_calendar_feeds_token_hash (token_hash),
+    DROP COLUMN token;
+
+-- Feeds of users who are already deactivated stop working
+DELETE calendar_feeds FROM calendar_feeds
+JOIN admin_users ON admin_users.id = calendar_feeds.user_id
+WHERE admin_users.is_active = FALSE OR admin_users.deleted_at IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Migration: Client IP on booking holds
+-- Created: 2025-01-02 11:00:00
+-- Description: Records the client IP that placed each slot hold, so the unexpired holds of one visitor on a page can be capped
+
+ALTER TABLE booking_holds
+    ADD COLUMN client_ip VARCHAR(45) NULL AFTER token, -- Visitor that placed the hold; empty for holds taken while booking
+    ADD INDEX idx_booking_holds_page_client (booking_page_id, client_ip, expires_at);
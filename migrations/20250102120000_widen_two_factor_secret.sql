@@ -0,0 +1,6 @@
+-- Migration: Encrypted two-factor secrets
+-- Created: 2025-01-02 12:00:00
+-- Description: Widens two_factor_secret to hold TOTP secrets encrypted at rest; the service encrypts existing secrets when it starts
+
+ALTER TABLE admin_users
+    MODIFY COLUMN two_factor_secret VARCHAR(255) NULL;
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"os"
@@ -10,11 +12,14 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWTClaims represents the claims in our JWT token
+// JWTClaims represents the claims in our JWT token. Every token belongs to a server-side session:
+// an access token's ID (the jti claim) is its session's token, while a refresh token's jti names
+// that one refresh token and SessionID binds it to the session.
 type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"` // Refresh tokens only
 	jwt.RegisteredClaims
 }
 
@@ -31,18 +36,33 @@ var (
 	refreshTokenSecret = []byte(getEnv("JWT_REFRESH_SECRET", "mejona-contact-service-refresh-secret-2024"))
 	accessTokenTTL     = time.Duration(getEnvInt("JWT_ACCESS_TTL_MINUTES", 60)) * time.Minute
 	refreshTokenTTL    = time.Duration(getEnvInt("JWT_REFRESH_TTL_HOURS", 168)) * time.Hour // 7 days
+	mfaTokenTTL        = time.Duration(getEnvInt("JWT_MFA_TTL_MINUTES", 5)) * time.Minute
 )
 
-// GenerateTokenPair generates both access and refresh tokens
-func GenerateTokenPair(userID uint, email, role string) (*TokenPair, error) {
+// MFAPendingAudience marks the tokens a password login returns when the user still has to enter a
+// two-factor code. They are signed like access tokens but are not accepted as one.
+const MFAPendingAudience = "mfa_pending"
+
+// RefreshTokenTTL is how long a refresh token, and so a session without activity limits, lasts
+func RefreshTokenTTL() time.Duration {
+	return refreshTokenTTL
+}
+
+// GenerateTokenPair generates the access and refresh tokens of a session. refreshID is the jti of
+// the refresh token, which the session accepts once.
+func GenerateTokenPair(userID uint, email, role, sessionID, refreshID string) (*TokenPair, error) {
+	if sessionID == "" || refreshID == "" {
+		return nil, errors.New("session and refresh token IDs are required")
+	}
+
 	// Generate access token
-	accessToken, err := GenerateAccessToken(userID, email, role)
+	accessToken, err := GenerateAccessToken(userID, email, role, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %v", err)
 	}
 
 	// Generate refresh token
-	refreshToken, err := GenerateRefreshToken(userID, email, role)
+	refreshToken, err := GenerateRefreshToken(userID, email, role, sessionID, refreshID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %v", err)
 	}
@@ -55,8 +75,8 @@ func GenerateTokenPair(userID uint, email, role string) (*TokenPair, error) {
 	}, nil
 }
 
-// GenerateAccessToken generates a JWT access token
-func GenerateAccessToken(userID uint, email, role string) (string, error) {
+// GenerateAccessToken generates a JWT access token for a session
+func GenerateAccessToken(userID uint, email, role, sessionID string) (string, error) {
 	claims := JWTClaims{
 		UserID: userID,
 		Email:  email,
@@ -67,7 +87,7 @@ func GenerateAccessToken(userID uint, email, role string) (string, error) {
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "mejona-contact-service",
 			Subject:   fmt.Sprintf("user:%d", userID),
-			ID:        fmt.Sprintf("%d-%d", userID, time.Now().Unix()),
+			ID:        sessionID,
 		},
 	}
 
@@ -75,29 +95,85 @@ func GenerateAccessToken(userID uint, email, role string) (string, error) {
 	return token.SignedString(accessTokenSecret)
 }
 
-// GenerateRefreshToken generates a JWT refresh token
-func GenerateRefreshToken(userID uint, email, role string) (string, error) {
+// GenerateRefreshToken generates a JWT refresh token for a session
+func GenerateRefreshToken(userID uint, email, role, sessionID, refreshID string) (string, error) {
+	claims := JWTClaims{
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(refreshTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    "mejona-contact-service",
+			Subject:   fmt.Sprintf("user:%d", userID),
+			ID:        refreshID,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(refreshTokenSecret)
+}
+
+// GenerateMFAToken generates the short-lived token that stands for a password login awaiting its
+// two-factor code
+func GenerateMFAToken(userID uint, email, role string) (string, error) {
 	claims := JWTClaims{
 		UserID: userID,
 		Email:  email,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(refreshTokenTTL)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "mejona-contact-service",
 			Subject:   fmt.Sprintf("user:%d", userID),
-			ID:        fmt.Sprintf("refresh-%d-%d", userID, time.Now().Unix()),
+			Audience:  jwt.ClaimStrings{MFAPendingAudience},
+			ID:        fmt.Sprintf("mfa-%d-%d", userID, time.Now().UnixNano()),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(refreshTokenSecret)
+	return token.SignedString(accessTokenSecret)
+}
+
+// MFATokenTTL is how long a login may wait for its two-factor code
+func MFATokenTTL() time.Duration {
+	return mfaTokenTTL
 }
 
 // ValidateAccessToken validates and parses an access token
 func ValidateAccessToken(tokenString string) (*JWTClaims, error) {
-	return validateToken(tokenString, accessTokenSecret)
+	claims, err := validateToken(tokenString, accessTokenSecret)
+	if err != nil {
+		return nil, err
+	}
+	if claims.mfaPending() {
+		return nil, errors.New("invalid token: two-factor verification pending")
+	}
+	return claims, nil
+}
+
+// ValidateMFAToken validates and parses a token returned by a login awaiting its two-factor code
+func ValidateMFAToken(tokenString string) (*JWTClaims, error) {
+	claims, err := validateToken(tokenString, accessTokenSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !claims.mfaPending() {
+		return nil, errors.New("invalid token: not a two-factor token")
+	}
+	return claims, nil
+}
+
+func (c *JWTClaims) mfaPending() bool {
+	for _, audience := range c.Audience {
+		if audience == MFAPendingAudience {
+			return true
+		}
+	}
+	return false
 }
 
 // ValidateRefreshToken validates and parses a refresh token
@@ -150,90 +226,43 @@ func ExtractTokenFromHeader(authHeader string) (string, error) {
 	return token, nil
 }
 
-// RefreshAccessToken generates a new access token using a valid refresh token
-func RefreshAccessToken(refreshToken string) (*TokenPair, error) {
-	claims, err := ValidateRefreshToken(refreshToken)
-	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %v", err)
+// PermissionMatches reports whether a granted permission covers the required one. "*" covers
+// every permission and "resource:*" every action on the resource.
+func PermissionMatches(granted, required string) bool {
+	if granted == "*" || granted == required {
+		return true
 	}
-
-	// Generate new token pair
-	return GenerateTokenPair(claims.UserID, claims.Email, claims.Role)
-}
-
-// HasPermission checks if a role has a specific permission
-func HasPermission(role, permission string) bool {
-	permissions := getPermissionsForRole(role)
-	for _, p := range permissions {
-		if p == permission || p == "*" {
-			return true
-		}
+	if resource, ok := strings.CutSuffix(granted, ":*"); ok {
+		return strings.HasPrefix(required, resource+":")
 	}
 	return false
 }
 
-// getPermissionsForRole returns the permissions for a given role
-func getPermissionsForRole(role string) []string {
-	rolePermissions := map[string][]string{
-		"admin": {
-			"*", // Admin has all permissions
-		},
-		"hr_manager": {
-			"contacts:read",
-			"contacts:write",
-			"contacts:update",
-			"contacts:assign",
-			"activities:read",
-			"activities:write",
-			"analytics:read",
-			"search:read",
-			"search:write",
-			"bulk:read",
-			"bulk:write",
-		},
-		"editor": {
-			"contacts:read",
-			"contacts:write",
-			"contacts:update",
-			"activities:read",
-			"activities:write",
-			"search:read",
-			"search:write",
-			"blogs:read",
-			"blogs:write",
-		},
-		"content_writer": {
-			"contacts:read",
-			"activities:read",
-			"search:read",
-			"blogs:read",
-			"blogs:write",
-		},
-	}
-
-	if permissions, exists := rolePermissions[role]; exists {
-		return permissions
+// Authorize reports whether a permission is granted and not denied. A denial wins over any grant,
+// however broad.
+func Authorize(granted, denied []string, permission string) bool {
+	for _, d := range denied {
+		if PermissionMatches(d, permission) {
+			return false
+		}
 	}
-
-	return []string{} // No permissions for unknown roles
-}
-
-// GetUserRoles returns all available user roles
-func GetUserRoles() []string {
-	return []string{"admin", "hr_manager", "editor", "content_writer"}
-}
-
-// IsValidRole checks if a role is valid
-func IsValidRole(role string) bool {
-	validRoles := GetUserRoles()
-	for _, validRole := range validRoles {
-		if role == validRole {
+	for _, g := range granted {
+		if PermissionMatches(g, permission) {
 			return true
 		}
 	}
 	return false
 }
 
+// DeriveKey returns a key for one purpose, such as signing tracking links, derived from
+// JWT_ACCESS_SECRET. It is the fallback for features whose own secret is not configured: each purpose
+// gets a different key, and none of them reveals the access token secret or can sign access tokens.
+func DeriveKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, accessTokenSecret)
+	mac.Write([]byte("contact-service derived key: " + purpose))
+	return mac.Sum(nil)
+}
+
 // Helper functions
 
 func getEnv(key, defaultValue string) string {
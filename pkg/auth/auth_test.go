@@ -0,0 +1,119 @@
+package auth
+
+import "testing"
+
+func TestGenerateTokenPairBindsSession(t *testing.T) {
+	pair, err := GenerateTokenPair(7, "jane@example.com", "sales", "session-1", "refresh-1")
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	access, err := ValidateAccessToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken() error = %v", err)
+	}
+	if access.ID != "session-1" || access.UserID != 7 || access.Role != "sales" {
+		t.Errorf("access claims = %+v, want jti session-1 for user 7", access)
+	}
+
+	refresh, err := ValidateRefreshToken(pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("ValidateRefreshToken() error = %v", err)
+	}
+	if refresh.ID != "refresh-1" || refresh.SessionID != "session-1" {
+		t.Errorf("refresh claims jti = %q sid = %q, want refresh-1 and session-1", refresh.ID, refresh.SessionID)
+	}
+
+	// Each token is only accepted where it belongs
+	if _, err := ValidateRefreshToken(pair.AccessToken); err == nil {
+		t.Error("access token accepted as refresh token")
+	}
+	if _, err := ValidateAccessToken(pair.RefreshToken); err == nil {
+		t.Error("refresh token accepted as access token")
+	}
+}
+
+func TestGenerateTokenPairRequiresSession(t *testing.T) {
+	if _, err := GenerateTokenPair(7, "jane@example.com", "sales", "", "refresh-1"); err == nil {
+		t.Error("expected error without a session ID")
+	}
+	if _, err := GenerateTokenPair(7, "jane@example.com", "sales", "session-1", ""); err == nil {
+		t.Error("expected error without a refresh token ID")
+	}
+}
+
+func TestMFATokenIsNotAnAccessToken(t *testing.T) {
+	token, err := GenerateMFAToken(7, "jane@example.com", "admin")
+	if err != nil {
+		t.Fatalf("GenerateMFAToken() error = %v", err)
+	}
+
+	claims, err := ValidateMFAToken(token)
+	if err != nil {
+		t.Fatalf("ValidateMFAToken() error = %v", err)
+	}
+	if claims.UserID != 7 {
+		t.Errorf("UserID = %d, want 7", claims.UserID)
+	}
+	if _, err := ValidateAccessToken(token); err == nil {
+		t.Error("pending two-factor token accepted as access token")
+	}
+
+	pair, err := GenerateTokenPair(7, "jane@example.com", "admin", "session-1", "refresh-1")
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+	if _, err := ValidateMFAToken(pair.AccessToken); err == nil {
+		t.Error("access token accepted as pending two-factor token")
+	}
+}
+
+func TestPermissionMatches(t *testing.T) {
+	tests := []struct {
+		granted, required string
+		want              bool
+	}{
+		{"*", "contacts:delete", true},
+		{"contacts:read", "contacts:read", true},
+		{"contacts:read", "contacts:write", false},
+		{"contacts:*", "contacts:assign", true},
+		{"contacts:*", "contact_types:read", false},
+		{"contacts:*", "contacts", false},
+	}
+	for _, tt := range tests {
+		if got := PermissionMatches(tt.granted, tt.required); got != tt.want {
+			t.Errorf("PermissionMatches(%q, %q) = %v, want %v", tt.granted, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorizeDenialWins(t *testing.T) {
+	granted := []string{"*"}
+	denied := []string{"bulk:*"}
+
+	if !Authorize(granted, denied, "contacts:read") {
+		t.Error("contacts:read should be granted")
+	}
+	if Authorize(granted, denied, "bulk:write") {
+		t.Error("bulk:write should be denied")
+	}
+	if Authorize(nil, nil, "contacts:read") {
+		t.Error("nothing granted should deny")
+	}
+}
+
+func TestDeriveKeyIsPerPurpose(t *testing.T) {
+	tracking, booking := DeriveKey("email-tracking"), DeriveKey("booking-links")
+	if len(tracking) != 32 {
+		t.Errorf("len(DeriveKey()) = %d, want 32", len(tracking))
+	}
+	if string(tracking) == string(booking) {
+		t.Error("different purposes derived the same key")
+	}
+	if string(tracking) != string(DeriveKey("email-tracking")) {
+		t.Error("DeriveKey() is not stable")
+	}
+	if string(tracking) == string(accessTokenSecret) {
+		t.Error("DeriveKey() returned the access token secret")
+	}
+}
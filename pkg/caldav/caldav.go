@@ -0,0 +1,292 @@
+// Package caldav is a minimal CalDAV (RFC 4791) client for one calendar collection: it stores and
+// deletes event resources and queries events in a time range.
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Config identifies a calendar collection and the credentials used to access it
+type Config struct {
+	CalendarURL string // URL of the calendar collection, e.g. https://dav.example.com/calendars/asha/work/
+	Username    string
+	Password    string // Account or app-specific password
+	Timeout     time.Duration
+	// Hosts, IP addresses or CIDR ranges that may be reached even though they are loopback, private
+	// or link-local, e.g. a calendar server on the internal network
+	AllowedHosts []string
+}
+
+// Object is a calendar object resource returned by a query
+type Object struct {
+	Href string
+	ETag string
+	Data []byte
+}
+
+// Client talks to one CalDAV calendar collection
+type Client struct {
+	config   Config
+	endpoint *url.URL
+	allowed  allowlist
+	client   *http.Client
+}
+
+// New creates a client for the configured calendar collection. Loopback, private and link-local
+// addresses are refused, both in the URL and whenever a connection is made, unless allowed by
+// config.AllowedHosts.
+func New(config Config) (*Client, error) {
+	endpoint, err := url.Parse(config.CalendarURL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid calendar URL: %q", config.CalendarURL)
+	}
+	if !strings.HasSuffix(endpoint.Path, "/") {
+		endpoint.Path += "/"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	allowed := newAllowlist(config.AllowedHosts)
+	host := endpoint.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if err := allowed.check(host, ip); err != nil {
+			return nil, fmt.Errorf("invalid calendar URL: %v", err)
+		}
+	} else if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		if err := allowed.check(host, net.IPv4(127, 0, 0, 1)); err != nil {
+			return nil, fmt.Errorf("invalid calendar URL: %v", err)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would connect to the calendar server itself, out of reach of the address check
+	transport.Proxy = nil
+	transport.DialContext = allowed.dialContext
+	return &Client{
+		config:   config,
+		endpoint: endpoint,
+		allowed:  allowed,
+		client:   &http.Client{Timeout: config.Timeout, Transport: transport},
+	}, nil
+}
+
+// CheckAddress resolves the calendar server's host and returns an error if any of its addresses
+// may not be reached. Connections are checked anyway; this reports a bad URL before it is saved.
+func (c *Client) CheckAddress(ctx context.Context) error {
+	host := c.endpoint.Hostname()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("invalid calendar URL: cannot resolve %s", host)
+	}
+	for _, addr := range addrs {
+		if err := c.allowed.check(host, addr.IP); err != nil {
+			return fmt.Errorf("invalid calendar URL: %v", err)
+		}
+	}
+	return nil
+}
+
+// Put stores an event under name in the collection, replacing any existing resource, and returns
+// the resource's href and new ETag. The ETag is empty when the server does not return one.
+func (c *Client) Put(ctx context.Context, name string, data []byte) (string, string, error) {
+	target := c.endpoint.ResolveReference(&url.URL{Path: name})
+	resp, err := c.do(ctx, http.MethodPut, target.String(), "text/calendar; charset=utf-8", data, nil)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return "", "", statusError("store event", resp)
+	}
+	return target.Path, resp.Header.Get("ETag"), nil
+}
+
+// Delete removes the resource at href. A resource that is already gone is not an error.
+func (c *Client) Delete(ctx context.Context, href string) error {
+	target, err := c.endpoint.Parse(href)
+	if err != nil {
+		return fmt.Errorf("invalid href %q: %v", href, err)
+	}
+	resp, err := c.do(ctx, http.MethodDelete, target.String(), "", nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusGone {
+		return statusError("delete event", resp)
+	}
+	return nil
+}
+
+// Events returns the events overlapping [from, to). Recurring events are expanded by the server into
+// one instance per occurrence, with times in UTC.
+func (c *Client) Events(ctx context.Context, from, to time.Time) ([]Object, error) {
+	start, end := from.UTC().Format("20060102T150405Z"), to.UTC().Format("20060102T150405Z")
+	body := `<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <D:getetag/>
+    <C:calendar-data>
+      <C:expand start="` + start + `" end="` + end + `"/>
+    </C:calendar-data>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT">
+        <C:time-range start="` + start + `" end="` + end + `"/>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+	resp, err := c.do(ctx, "REPORT", c.endpoint.String(), "application/xml; charset=utf-8", []byte(body), map[string]string{"Depth": "1"})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, statusError("query events", resp)
+	}
+
+	var result multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse calendar query response: %v", err)
+	}
+
+	var objects []Object
+	for _, response := range result.Responses {
+		for _, propstat := range response.Propstats {
+			if !strings.Contains(propstat.Status, " 200 ") || strings.TrimSpace(propstat.Prop.CalendarData) == "" {
+				continue
+			}
+			objects = append(objects, Object{
+				Href: response.Href,
+				ETag: propstat.Prop.ETag,
+				Data: []byte(propstat.Prop.CalendarData),
+			})
+		}
+	}
+	return objects, nil
+}
+
+func (c *Client) do(ctx context.Context, method, target, contentType string, body []byte, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if c.config.Username != "" || c.config.Password != "" {
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach calendar server: %w", err)
+	}
+	return resp, nil
+}
+
+// statusError reports an unexpected response. The body is left out: it is the remote server's
+// content, and sync errors are shown to users.
+func statusError(action string, resp *http.Response) error {
+	return fmt.Errorf("calendar server refused to %s (status %d)", action, resp.StatusCode)
+}
+
+// allowlist holds the loopback, private and link-local targets a client may reach anyway
+type allowlist struct {
+	hosts map[string]bool
+	nets  []*net.IPNet
+}
+
+func newAllowlist(entries []string) allowlist {
+	allowed := allowlist{hosts: make(map[string]bool)}
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			allowed.nets = append(allowed.nets, network)
+		} else if ip := net.ParseIP(entry); ip != nil {
+			bits := len(ip) * 8
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 32
+			}
+			allowed.nets = append(allowed.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		} else {
+			allowed.hosts[entry] = true
+		}
+	}
+	return allowed
+}
+
+// check returns an error if ip, an address of host, is internal and not allowed
+func (a allowlist) check(host string, ip net.IP) error {
+	if ip == nil {
+		return fmt.Errorf("%s did not resolve to an IP address", host)
+	}
+	internal := ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+	if !internal || a.hosts[strings.ToLower(host)] {
+		return nil
+	}
+	for _, network := range a.nets {
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is a loopback, private or link-local address", ip)
+}
+
+// dialContext connects like the default transport, but checks each address the host resolves to
+// just before connecting, so redirects and DNS changes cannot reach internal addresses
+func (a allowlist) dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(_, resolved string, _ syscall.RawConn) error {
+			ip, _, err := net.SplitHostPort(resolved)
+			if err != nil {
+				return err
+			}
+			return a.check(host, net.ParseIP(ip))
+		},
+	}
+	return dialer.DialContext(ctx, network, address)
+}
+
+// multistatus is a WebDAV 207 Multi-Status response body
+type multistatus struct {
+	Responses []struct {
+		Href      string `xml:"DAV: href"`
+		Propstats []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				ETag         string `xml:"DAV: getetag"`
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
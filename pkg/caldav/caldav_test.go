@@ -0,0 +1,159 @@
+package caldav
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer is an in-memory calendar collection at /cal/ that requires basic auth
+type fakeServer struct {
+	mu      sync.Mutex
+	objects map[string]string
+	report  string
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != "asha" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		_, existed := f.objects[r.URL.Path]
+		f.objects[r.URL.Path] = string(body)
+		w.Header().Set("ETag", fmt.Sprintf(`"%d"`, len(body)))
+		if existed {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case http.MethodDelete:
+		if _, ok := f.objects[r.URL.Path]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	case "REPORT":
+		body, _ := io.ReadAll(r.Body)
+		f.report = string(body)
+		if r.Header.Get("Depth") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var out strings.Builder
+		out.WriteString(`<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav">`)
+		for href, data := range f.objects {
+			fmt.Fprintf(&out, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:getetag>"%d"</d:getetag><cal:calendar-data>%s</cal:calendar-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`, href, len(data), data)
+		}
+		out.WriteString(`</d:multistatus>`)
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, out.String())
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestClientRoundTrip(t *testing.T) {
+	fake := &fakeServer{objects: make(map[string]string)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := New(Config{CalendarURL: server.URL + "/cal", Username: "asha", Password: "secret", AllowedHosts: []string{"127.0.0.1"}})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	event := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:appointment-1@example.com\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	href, etag, err := client.Put(ctx, "appointment-1.ics", []byte(event))
+	require.NoError(t, err)
+	assert.Equal(t, "/cal/appointment-1.ics", href)
+	assert.NotEmpty(t, etag)
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	objects, err := client.Events(ctx, from, from.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, href, objects[0].Href)
+	assert.Equal(t, strings.ReplaceAll(event, "\r\n", "\n"), string(objects[0].Data), "XML parsing normalizes line endings")
+	assert.Contains(t, fake.report, `<C:expand start="20250301T000000Z" end="20250401T000000Z"/>`)
+
+	require.NoError(t, client.Delete(ctx, href))
+	assert.Empty(t, fake.objects)
+	assert.NoError(t, client.Delete(ctx, href), "deleting a missing resource succeeds")
+}
+
+func TestClientErrors(t *testing.T) {
+	server := httptest.NewServer(&fakeServer{objects: make(map[string]string)})
+	defer server.Close()
+
+	client, err := New(Config{CalendarURL: server.URL + "/cal/", Username: "asha", Password: "wrong", AllowedHosts: []string{"127.0.0.0/8"}})
+	require.NoError(t, err)
+	_, _, err = client.Put(context.Background(), "x.ics", []byte("data"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 401")
+	assert.NotContains(t, err.Error(), "Unauthorized", "the response body is not echoed")
+
+	_, err = New(Config{CalendarURL: "ftp://example.com/cal"})
+	assert.Error(t, err)
+}
+
+func TestClientRefusesInternalAddresses(t *testing.T) {
+	for _, target := range []string{
+		"http://127.0.0.1/cal/",
+		"http://localhost:8080/cal/",
+		"http://10.0.0.5/cal/",
+		"http://192.168.1.10/cal/",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/cal/",
+		"http://[fd00::1]/cal/",
+		"http://0.0.0.0/cal/",
+	} {
+		_, err := New(Config{CalendarURL: target})
+		require.Error(t, err, target)
+		assert.Contains(t, err.Error(), "invalid calendar URL", target)
+	}
+
+	client, err := New(Config{CalendarURL: "http://10.0.0.5/cal/", AllowedHosts: []string{"10.0.0.0/8"}})
+	require.NoError(t, err)
+	assert.NoError(t, client.CheckAddress(context.Background()))
+
+	client, err = New(Config{CalendarURL: "http://localhost/cal/", AllowedHosts: []string{"localhost"}})
+	require.NoError(t, err)
+	assert.NoError(t, client.CheckAddress(context.Background()))
+}
+
+func TestClientChecksAddressWhenConnecting(t *testing.T) {
+	fake := &fakeServer{objects: make(map[string]string)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	// An allowed server that redirects to an internal address that is not allowed
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, server.URL+r.URL.Path, http.StatusTemporaryRedirect)
+	}))
+	defer redirect.Close()
+	_, port, err := net.SplitHostPort(redirect.Listener.Addr().String())
+	require.NoError(t, err)
+
+	client, err := New(Config{CalendarURL: "http://localhost:" + port + "/cal/", Username: "asha", Password: "secret", AllowedHosts: []string{"localhost"}})
+	require.NoError(t, err)
+	_, _, err = client.Put(context.Background(), "x.ics", []byte("data"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "loopback, private or link-local")
+	assert.Empty(t, fake.objects)
+}
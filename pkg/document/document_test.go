@@ -0,0 +1,93 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkbookWritesTypedCells(t *testing.T) {
+	wb := NewWorkbook()
+	sheet := wb.AddSheet("Contacts", "Name", "Value", "Created")
+	sheet.AddRow(Text("Ana <&>"), Decimal(1250.5), DateTime(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)))
+	wb.AddSheet("Contacts")
+
+	data, err := wb.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("workbook is not a zip archive: %v", err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "xl/workbook.xml", "xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+
+	sheet1 := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A2" s="0" t="inlineStr"><is><t xml:space="preserve">Ana &lt;&amp;&gt;</t></is></c>`,
+		`<c r="B2" s="3"><v>1250.5</v></c>`,
+		`<c r="C2" s="2"><v>45352.500000</v></c>`,
+	} {
+		if !strings.Contains(sheet1, want) {
+			t.Errorf("sheet1 missing %s", want)
+		}
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Contacts (2)"`) {
+		t.Errorf("duplicate sheet name was not made unique")
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	cases := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+	for index, want := range cases {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d) = %s, want %s", index, got, want)
+		}
+	}
+}
+
+func TestPDFStructure(t *testing.T) {
+	doc := NewPDF("Weekly (KPI) Report")
+	doc.Heading("Summary")
+	doc.KeyValues([][2]string{{"Total leads", "42"}})
+	rows := make([][]string, 80)
+	for i := range rows {
+		rows[i] = []string{"user", "1.5"}
+	}
+	doc.Table([]string{"User", "Score"}, rows)
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	out := string(data)
+
+	if !strings.HasPrefix(out, "%PDF-1.4") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatalf("missing PDF header or trailer")
+	}
+	if !strings.Contains(out, `(Weekly \(KPI\) Report)`) {
+		t.Errorf("title parentheses were not escaped")
+	}
+	if !strings.Contains(out, "/Count 2") {
+		t.Errorf("expected the table to overflow onto a second page")
+	}
+}
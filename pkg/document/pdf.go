@@ -0,0 +1,236 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A4 page geometry in points
+const (
+	pdfPageWidth    = 595.0
+	pdfPageHeight   = 842.0
+	pdfMargin       = 50.0
+	pdfContentWidth = pdfPageWidth - 2*pdfMargin
+)
+
+// Font resource names registered on every page
+const (
+	pdfFontRegular = "F1" // Helvetica
+	pdfFontBold    = "F2" // Helvetica-Bold
+)
+
+// PDF builds a simple paginated A4 report using the standard Helvetica fonts
+type PDF struct {
+	title   string
+	pages   []*bytes.Buffer
+	current *bytes.Buffer
+	y       float64
+}
+
+// NewPDF creates a report whose first page starts with the given title
+func NewPDF(title string) *PDF {
+	doc := &PDF{title: title}
+	doc.newPage()
+	doc.writeLine(title, pdfFontBold, 18, 0)
+	doc.writeLine("Generated "+time.Now().Format("2006-01-02 15:04 MST"), pdfFontRegular, 9, 0)
+	doc.y -= 10
+	return doc
+}
+
+// Heading adds a section heading
+func (d *PDF) Heading(text string) {
+	d.ensureSpace(40)
+	d.y -= 8
+	d.writeLine(text, pdfFontBold, 13, 0)
+	d.y -= 2
+}
+
+// Paragraph adds wrapped body text
+func (d *PDF) Paragraph(text string) {
+	for _, line := range wrapText(text, pdfCharWidth(10), pdfContentWidth) {
+		d.writeLine(line, pdfFontRegular, 10, 0)
+	}
+	d.y -= 4
+}
+
+// KeyValues adds a two column list of labels and values
+func (d *PDF) KeyValues(pairs [][2]string) {
+	labelWidth := pdfContentWidth * 0.45
+	for _, pair := range pairs {
+		d.ensureSpace(14)
+		d.text(pdfMargin, d.y, pdfFontRegular, 10, fitText(pair[0], 10, labelWidth-6))
+		d.text(pdfMargin+labelWidth, d.y, pdfFontBold, 10, fitText(pair[1], 10, pdfContentWidth-labelWidth))
+		d.y -= 14
+	}
+	d.y -= 4
+}
+
+// Table adds a table with a shaded header row; columns share the page width equally
+func (d *PDF) Table(header []string, rows [][]string) {
+	if len(header) == 0 {
+		return
+	}
+	colWidth := pdfContentWidth / float64(len(header))
+
+	drawHeader := func() {
+		d.ensureSpace(36)
+		fmt.Fprintf(d.current, "0.85 0.88 0.95 rg %.2f %.2f %.2f 16 re f 0 g\n", pdfMargin, d.y-4, pdfContentWidth)
+		for i, name := range header {
+			d.text(pdfMargin+float64(i)*colWidth+2, d.y, pdfFontBold, 9, fitText(name, 9, colWidth-4))
+		}
+		d.y -= 16
+	}
+
+	drawHeader()
+	for _, row := range rows {
+		if d.y-13 < pdfMargin {
+			d.newPage()
+			drawHeader()
+		}
+		for i := range header {
+			value := ""
+			if i < len(row) {
+				value = row[i]
+			}
+			d.text(pdfMargin+float64(i)*colWidth+2, d.y, pdfFontRegular, 9, fitText(value, 9, colWidth-4))
+		}
+		d.y -= 13
+	}
+	d.y -= 6
+}
+
+// Bytes renders the document as a PDF file
+func (d *PDF) Bytes() ([]byte, error) {
+	var out bytes.Buffer
+	offsets := []int{0}
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets)-1, body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-4 are fixed; each page then takes a page object and a content stream
+	pageCount := len(d.pages)
+	kids := make([]string, pageCount)
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pageCount))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		footer := fmt.Sprintf("BT /%s 8 Tf %.2f %.2f Td (%s) Tj ET\n", pdfFontRegular, pdfMargin, pdfMargin/2,
+			pdfString(fmt.Sprintf("%s - page %d of %d", d.title, i+1, pageCount)))
+		content := page.String() + footer
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, pdfFontRegular, pdfFontBold, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	infoID := len(offsets)
+	object(fmt.Sprintf("<< /Title (%s) /CreationDate (D:%s) >>", pdfString(d.title), time.Now().UTC().Format("20060102150405Z")))
+
+	xrefOffset := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets))
+	for _, offset := range offsets[1:] {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets), infoID, xrefOffset)
+
+	return out.Bytes(), nil
+}
+
+func (d *PDF) newPage() {
+	d.current = &bytes.Buffer{}
+	d.pages = append(d.pages, d.current)
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// ensureSpace starts a new page when fewer than height points remain
+func (d *PDF) ensureSpace(height float64) {
+	if d.y-height < pdfMargin {
+		d.newPage()
+	}
+}
+
+func (d *PDF) writeLine(text, font string, size, indent float64) {
+	d.ensureSpace(size + 4)
+	d.text(pdfMargin+indent, d.y, font, size, text)
+	d.y -= size + 4
+}
+
+func (d *PDF) text(x, y float64, font string, size float64, value string) {
+	fmt.Fprintf(d.current, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(value))
+}
+
+// pdfCharWidth is the average Helvetica glyph width at a font size, used for wrapping and truncation
+func pdfCharWidth(size float64) float64 {
+	return size * 0.52
+}
+
+// wrapText splits text into lines no wider than width
+func wrapText(text string, charWidth, width float64) []string {
+	maxChars := int(width / charWidth)
+	if maxChars < 1 {
+		maxChars = 1
+	}
+
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			switch {
+			case line == "":
+				line = word
+			case len([]rune(line))+1+len([]rune(word)) <= maxChars:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fitText truncates text with an ellipsis so it fits in width
+func fitText(text string, size, width float64) string {
+	maxChars := int(width / pdfCharWidth(size))
+	runes := []rune(text)
+	if len(runes) <= maxChars {
+		return text
+	}
+	if maxChars <= 3 {
+		return string(runes[:maxChars])
+	}
+	return string(runes[:maxChars-3]) + "..."
+}
+
+// pdfString encodes text as a WinAnsi literal string, replacing characters the standard fonts lack
+func pdfString(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
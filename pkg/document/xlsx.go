@@ -0,0 +1,413 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CellType represents how a spreadsheet cell is stored and formatted
+type CellType int
+
+const (
+	CellEmpty    CellType = iota
+	CellString            // Inline text
+	CellInteger           // Whole number
+	CellDecimal           // Number shown with two decimal places
+	CellDate              // Calendar date
+	CellDateTime          // Date and time of day
+	CellBool              // TRUE/FALSE
+)
+
+// Cell is a single typed spreadsheet value
+type Cell struct {
+	Type   CellType
+	Text   string
+	Number float64
+	Time   time.Time
+	Bool   bool
+}
+
+// Text returns a string cell
+func Text(value string) Cell {
+	return Cell{Type: CellString, Text: value}
+}
+
+// OptionalText returns a string cell, or an empty cell for nil
+func OptionalText(value *string) Cell {
+	if value == nil {
+		return Cell{}
+	}
+	return Text(*value)
+}
+
+// Integer returns a whole number cell
+func Integer(value int64) Cell {
+	return Cell{Type: CellInteger, Number: float64(value)}
+}
+
+// OptionalInteger returns a whole number cell, or an empty cell for nil
+func OptionalInteger(value *uint) Cell {
+	if value == nil {
+		return Cell{}
+	}
+	return Integer(int64(*value))
+}
+
+// Decimal returns a number cell formatted with two decimal places
+func Decimal(value float64) Cell {
+	return Cell{Type: CellDecimal, Number: value}
+}
+
+// Date returns a date cell
+func Date(value time.Time) Cell {
+	return Cell{Type: CellDate, Time: value}
+}
+
+// DateTime returns a date and time cell
+func DateTime(value time.Time) Cell {
+	return Cell{Type: CellDateTime, Time: value}
+}
+
+// OptionalDateTime returns a date and time cell, or an empty cell for nil
+func OptionalDateTime(value *time.Time) Cell {
+	if value == nil {
+		return Cell{}
+	}
+	return DateTime(*value)
+}
+
+// Bool returns a boolean cell
+func Bool(value bool) Cell {
+	return Cell{Type: CellBool, Bool: value}
+}
+
+// Sheet is a worksheet with a bold, frozen header row
+type Sheet struct {
+	name   string
+	header []string
+	rows   [][]Cell
+}
+
+// AddRow appends a row of cells
+func (s *Sheet) AddRow(cells ...Cell) {
+	s.rows = append(s.rows, cells)
+}
+
+// RowCount returns the number of data rows, excluding the header
+func (s *Sheet) RowCount() int {
+	return len(s.rows)
+}
+
+// Workbook builds an Office Open XML (.xlsx) spreadsheet
+type Workbook struct {
+	sheets []*Sheet
+}
+
+// NewWorkbook creates an empty workbook
+func NewWorkbook() *Workbook {
+	return &Workbook{}
+}
+
+// AddSheet appends a worksheet with the given header row
+func (w *Workbook) AddSheet(name string, header ...string) *Sheet {
+	sheet := &Sheet{name: w.uniqueSheetName(name), header: header}
+	w.sheets = append(w.sheets, sheet)
+	return sheet
+}
+
+// Bytes renders the workbook as an .xlsx file
+func (w *Workbook) Bytes() ([]byte, error) {
+	if len(w.sheets) == 0 {
+		w.AddSheet("Sheet1")
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", w.contentTypesXML()},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", w.workbookXML()},
+		{"xl/_rels/workbook.xml.rels", w.workbookRelsXML()},
+		{"xl/styles.xml", stylesXML},
+	}
+	for i, sheet := range w.sheets {
+		parts = append(parts, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheet.xml()})
+	}
+
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", part.name, err)
+		}
+		if _, err := f.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish workbook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// uniqueSheetName strips characters Excel rejects, truncates to 31 characters and de-duplicates
+func (w *Workbook) uniqueSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "Sheet"
+	}
+	name = truncateRunes(name, 31)
+
+	candidate := name
+	for n := 2; w.hasSheet(candidate); n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		candidate = truncateRunes(name, 31-len(suffix)) + suffix
+	}
+	return candidate
+}
+
+func (w *Workbook) hasSheet(name string) bool {
+	for _, sheet := range w.sheets {
+		if strings.EqualFold(sheet.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Workbook) contentTypesXML() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range w.sheets {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+func (w *Workbook) workbookXML() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range w.sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(sheet.name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+func (w *Workbook) workbookRelsXML() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range w.sheets {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(w.sheets)+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+// Style indexes into cellXfs in stylesXML
+const (
+	styleDefault  = 0
+	styleHeader   = 1
+	styleDateTime = 2
+	styleDecimal  = 3
+	styleInteger  = 4
+	styleDate     = 5
+)
+
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm"/><numFmt numFmtId="165" formatCode="yyyy-mm-dd"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+	`<fill><patternFill patternType="solid"><fgColor rgb="FFD9E1F2"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="6">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="1" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`
+
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+func (s *Sheet) xml() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if len(s.header) > 0 {
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+
+	if widths := s.columnWidths(); len(widths) > 0 {
+		b.WriteString(`<cols>`)
+		for i, width := range widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%.1f" customWidth="1"/>`, i+1, i+1, width)
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+	rowNum := 1
+	if len(s.header) > 0 {
+		fmt.Fprintf(&b, `<row r="%d">`, rowNum)
+		for col, name := range s.header {
+			writeCell(&b, columnName(col)+strconv.Itoa(rowNum), Text(name), styleHeader)
+		}
+		b.WriteString(`</row>`)
+		rowNum++
+	}
+	for _, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, rowNum)
+		for col, cell := range row {
+			writeCell(&b, columnName(col)+strconv.Itoa(rowNum), cell, -1)
+		}
+		b.WriteString(`</row>`)
+		rowNum++
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// columnWidths sizes each column to its longest value, within sensible bounds
+func (s *Sheet) columnWidths() []float64 {
+	columns := len(s.header)
+	for _, row := range s.rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+
+	widths := make([]float64, columns)
+	measure := func(col int, length int) {
+		width := math.Min(math.Max(float64(length)+2, 8), 60)
+		if width > widths[col] {
+			widths[col] = width
+		}
+	}
+	for col, name := range s.header {
+		measure(col, utf8.RuneCountInString(name))
+	}
+	for _, row := range s.rows {
+		for col, cell := range row {
+			switch cell.Type {
+			case CellString:
+				measure(col, utf8.RuneCountInString(cell.Text))
+			case CellDateTime:
+				measure(col, 16)
+			case CellDate:
+				measure(col, 10)
+			case CellInteger, CellDecimal:
+				measure(col, len(strconv.FormatFloat(cell.Number, 'f', 2, 64))+2)
+			}
+		}
+	}
+	return widths
+}
+
+func writeCell(b *strings.Builder, ref string, cell Cell, style int) {
+	switch cell.Type {
+	case CellEmpty:
+		if style >= 0 {
+			fmt.Fprintf(b, `<c r="%s" s="%d"/>`, ref, style)
+		}
+	case CellString:
+		if style < 0 {
+			style = styleDefault
+		}
+		fmt.Fprintf(b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escapeXML(cell.Text))
+	case CellInteger:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, pickStyle(style, styleInteger), strconv.FormatFloat(math.Round(cell.Number), 'f', 0, 64))
+	case CellDecimal:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, pickStyle(style, styleDecimal), strconv.FormatFloat(cell.Number, 'f', -1, 64))
+	case CellDate:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, pickStyle(style, styleDate), strconv.FormatFloat(math.Floor(excelSerial(cell.Time)), 'f', 0, 64))
+	case CellDateTime:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, pickStyle(style, styleDateTime), strconv.FormatFloat(excelSerial(cell.Time), 'f', 6, 64))
+	case CellBool:
+		value := 0
+		if cell.Bool {
+			value = 1
+		}
+		fmt.Fprintf(b, `<c r="%s" s="%d" t="b"><v>%d</v></c>`, ref, pickStyle(style, styleDefault), value)
+	}
+}
+
+func pickStyle(style, fallback int) int {
+	if style >= 0 {
+		return style
+	}
+	return fallback
+}
+
+// Day zero of the Excel 1900 date system, adjusted for its fictitious 1900-02-29
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// excelSerial converts a time to an Excel serial date in the time's own zone
+func excelSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(excelEpoch).Hours() / 24
+}
+
+// columnName converts a zero-based column index to its letter reference (0 -> A, 26 -> AA)
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// escapeXML escapes text for XML and drops characters XML 1.0 cannot represent
+func escapeXML(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || (r >= 0x20 && r != 0xFFFE && r != 0xFFFF && r != utf8.RuneError) {
+			return r
+		}
+		return -1
+	}, value)
+
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
+
+func truncateRunes(value string, max int) string {
+	if utf8.RuneCountInString(value) <= max {
+		return value
+	}
+	runes := []rune(value)
+	return string(runes[:max])
+}
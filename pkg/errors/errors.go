@@ -114,10 +114,11 @@ type AppError struct {
 
 // FieldError represents validation errors for specific fields
 type FieldError struct {
-	Field   string `json:"field"`
-	Message string `json:"message"`
-	Code    string `json:"code"`
-	Value   interface{} `json:"value,omitempty"`
+	Field    string `json:"field"`
+	Message  string `json:"message"`
+	Code     string `json:"code"`
+	Value    interface{} `json:"value,omitempty"`
+	Severity string `json:"severity,omitempty"` // error, warning or info; empty means error
 }
 
 // Error implements the error interface
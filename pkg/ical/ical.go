@@ -0,0 +1,184 @@
+// Package ical renders RFC 5545 iCalendar objects for calendar feeds and iTIP invitations (RFC 5546).
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// iTIP methods
+const (
+	MethodPublish = "PUBLISH" // Feed or download; no scheduling semantics
+	MethodRequest = "REQUEST" // Invitation or update of an existing event
+	MethodCancel  = "CANCEL"  // Cancellation of an event
+)
+
+// Event statuses
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// ContentType returns the MIME type of a calendar object with the given method
+func ContentType(method string) string {
+	return "text/calendar; charset=UTF-8; method=" + method
+}
+
+// Calendar is a VCALENDAR object
+type Calendar struct {
+	ProdID  string // e.g. -//Mejona//Contact Service//EN
+	Method  string
+	Name    string        // X-WR-CALNAME shown by subscribing clients
+	Refresh time.Duration // Suggested polling interval for feeds; zero omits it
+	Events  []Event
+}
+
+// Event is a VEVENT. Times are written in UTC.
+type Event struct {
+	UID          string // Stable across updates so clients replace rather than duplicate the event
+	Sequence     int    // Incremented on every significant change
+	Stamp        time.Time
+	Start        time.Time
+	End          time.Time
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Status       string
+	Organizer    *Attendee
+	Attendees    []Attendee
+	LastModified time.Time
+	Transparent  bool // TRANSP:TRANSPARENT; the event does not block time
+}
+
+// Attendee is an ORGANIZER or ATTENDEE
+type Attendee struct {
+	Name     string
+	Email    string
+	Role     string // REQ-PARTICIPANT by default
+	PartStat string // NEEDS-ACTION by default
+	RSVP     bool
+}
+
+// Bytes renders the calendar with CRLF line endings and lines folded at 75 octets
+func (c *Calendar) Bytes() []byte {
+	w := &writer{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", c.ProdID)
+	w.line("CALSCALE", "GREGORIAN")
+	if c.Method != "" {
+		w.line("METHOD", c.Method)
+	}
+	if c.Name != "" {
+		w.line("X-WR-CALNAME", Escape(c.Name))
+	}
+	if c.Refresh > 0 {
+		duration := formatDuration(c.Refresh)
+		w.line("REFRESH-INTERVAL;VALUE=DURATION", duration)
+		w.line("X-PUBLISHED-TTL", duration)
+	}
+	for i := range c.Events {
+		c.Events[i].write(w)
+	}
+	w.line("END", "VCALENDAR")
+	return []byte(w.String())
+}
+
+func (e *Event) write(w *writer) {
+	w.line("BEGIN", "VEVENT")
+	w.line("UID", Escape(e.UID))
+	w.line("SEQUENCE", fmt.Sprint(e.Sequence))
+	stamp := e.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	w.line("DTSTAMP", formatTime(stamp))
+	w.line("DTSTART", formatTime(e.Start))
+	w.line("DTEND", formatTime(e.End))
+	w.line("SUMMARY", Escape(e.Summary))
+	if e.Description != "" {
+		w.line("DESCRIPTION", Escape(e.Description))
+	}
+	if e.Location != "" {
+		w.line("LOCATION", Escape(e.Location))
+	}
+	if e.URL != "" {
+		w.line("URL", e.URL)
+	}
+	if e.Status != "" {
+		w.line("STATUS", e.Status)
+	}
+	if e.Transparent {
+		w.line("TRANSP", "TRANSPARENT")
+	}
+	if !e.LastModified.IsZero() {
+		w.line("LAST-MODIFIED", formatTime(e.LastModified))
+	}
+	if e.Organizer != nil {
+		w.line("ORGANIZER"+commonName(e.Organizer.Name), "mailto:"+e.Organizer.Email)
+	}
+	for _, attendee := range e.Attendees {
+		role, partStat := attendee.Role, attendee.PartStat
+		if role == "" {
+			role = "REQ-PARTICIPANT"
+		}
+		if partStat == "" {
+			partStat = "NEEDS-ACTION"
+		}
+		params := commonName(attendee.Name) + ";ROLE=" + role + ";PARTSTAT=" + partStat
+		if attendee.RSVP {
+			params += ";RSVP=TRUE"
+		}
+		w.line("ATTENDEE"+params, "mailto:"+attendee.Email)
+	}
+	w.line("END", "VEVENT")
+}
+
+// Escape escapes a TEXT value
+func Escape(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(value)
+}
+
+// commonName renders a CN parameter, quoted because names may contain separators
+func commonName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return `;CN="` + strings.NewReplacer(`"`, "'", "\r", "", "\n", " ").Replace(name) + `"`
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func formatDuration(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("PT%dH", d/time.Hour)
+	}
+	return fmt.Sprintf("PT%dM", d/time.Minute)
+}
+
+// writer accumulates content lines, folding them at 75 octets without splitting UTF-8 sequences
+type writer struct {
+	strings.Builder
+}
+
+func (w *writer) line(name, value string) {
+	line := name + ":" + value
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // Continuation lines start with a space
+	}
+	w.WriteString(line + "\r\n")
+}
@@ -0,0 +1,56 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarBytes(t *testing.T) {
+	start := time.Date(2025, 3, 10, 9, 30, 0, 0, time.FixedZone("IST", 19800))
+	calendar := &Calendar{
+		ProdID: "-//Test//Test//EN",
+		Method: MethodRequest,
+		Events: []Event{{
+			UID:       "appointment-42@example.com",
+			Sequence:  2,
+			Stamp:     start,
+			Start:     start,
+			End:       start.Add(30 * time.Minute),
+			Summary:   "Demo; pricing, Q&A",
+			Status:    StatusConfirmed,
+			Organizer: &Attendee{Name: "Asha Rao", Email: "asha@example.com"},
+			Attendees: []Attendee{{Name: "Sam", Email: "sam@example.com", RSVP: true}},
+		}},
+	}
+
+	out := strings.ReplaceAll(string(calendar.Bytes()), "\r\n ", "") // Unfold
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.Contains(t, out, "METHOD:REQUEST\r\n")
+	assert.Contains(t, out, "DTSTART:20250310T040000Z\r\n")
+	assert.Contains(t, out, "SEQUENCE:2\r\n")
+	assert.Contains(t, out, `SUMMARY:Demo\; pricing\, Q&A`+"\r\n")
+	assert.Contains(t, out, `ORGANIZER;CN="Asha Rao":mailto:asha@example.com`)
+	assert.Contains(t, out, `ATTENDEE;CN="Sam";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:sam@example.com`)
+	assert.True(t, strings.HasSuffix(out, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+}
+
+func TestLinesAreFolded(t *testing.T) {
+	w := &writer{}
+	w.line("DESCRIPTION", Escape(strings.Repeat("é", 100)+"\nnext"))
+
+	lines := strings.Split(strings.TrimSuffix(w.String(), "\r\n"), "\r\n")
+	assert.Greater(t, len(lines), 1)
+	var unfolded string
+	for i, line := range lines {
+		assert.LessOrEqual(t, len(line), 75)
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(line, " "))
+			line = line[1:]
+		}
+		unfolded += line
+	}
+	assert.Equal(t, "DESCRIPTION:"+strings.Repeat("é", 100)+`\nnext`, unfolded)
+}
@@ -0,0 +1,169 @@
+package ical
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse reads the VEVENTs of an iCalendar object. Times with a TZID are read in that zone, and
+// floating times and all-day dates in loc. Recurrence rules are not expanded; ask the server for
+// expanded instances instead (CalDAV calendar-query with <C:expand>).
+func Parse(data []byte, loc *time.Location) ([]Event, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.NewReplacer("\n ", "", "\n\t", "").Replace(text) // Unfold
+
+	var events []Event
+	var event *Event
+	var duration time.Duration
+	var allDay bool
+	var components []string
+	for number, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, params, value, err := splitLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", number+1, err)
+		}
+
+		switch name {
+		case "BEGIN":
+			components = append(components, strings.ToUpper(value))
+			if components[len(components)-1] == "VEVENT" {
+				event, duration, allDay = &Event{}, 0, false
+			}
+			continue
+		case "END":
+			if len(components) == 0 {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", number+1, value)
+			}
+			components = components[:len(components)-1]
+			if strings.EqualFold(value, "VEVENT") && event != nil {
+				if event.End.IsZero() {
+					switch {
+					case duration != 0:
+						event.End = event.Start.Add(duration)
+					case allDay:
+						event.End = event.Start.AddDate(0, 0, 1)
+					default:
+						event.End = event.Start
+					}
+				}
+				events = append(events, *event)
+				event = nil
+			}
+			continue
+		}
+
+		// Properties of nested components such as VALARM do not describe the event
+		if event == nil || components[len(components)-1] != "VEVENT" {
+			continue
+		}
+		switch name {
+		case "UID":
+			event.UID = value
+		case "SEQUENCE":
+			event.Sequence, _ = strconv.Atoi(value)
+		case "SUMMARY":
+			event.Summary = unescape(value)
+		case "DESCRIPTION":
+			event.Description = unescape(value)
+		case "LOCATION":
+			event.Location = unescape(value)
+		case "STATUS":
+			event.Status = strings.ToUpper(value)
+		case "TRANSP":
+			event.Transparent = strings.EqualFold(value, "TRANSPARENT")
+		case "DTSTART", "DTEND":
+			t, date, err := parseTime(value, params, loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s: %v", number+1, name, err)
+			}
+			if name == "DTSTART" {
+				event.Start, allDay = t, date
+			} else {
+				event.End = t
+			}
+		case "DURATION":
+			if duration, err = parseDuration(value); err != nil {
+				return nil, fmt.Errorf("line %d: invalid DURATION: %v", number+1, err)
+			}
+		}
+	}
+	return events, nil
+}
+
+// splitLine splits a content line into its upper-cased name, parameters and value.
+// Colons and semicolons inside quoted parameter values are not separators.
+func splitLine(line string) (string, map[string]string, string, error) {
+	quoted := false
+	start := 0
+	var parts []string
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == ';':
+			parts = append(parts, line[start:i])
+			start = i + 1
+		case r == ':':
+			parts = append(parts, line[start:i])
+			params := make(map[string]string)
+			for _, part := range parts[1:] {
+				if key, value, ok := strings.Cut(part, "="); ok {
+					params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+				}
+			}
+			return strings.ToUpper(parts[0]), params, line[i+1:], nil
+		}
+	}
+	return "", nil, "", fmt.Errorf("missing ':' in %q", line)
+}
+
+func parseTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	if tzid := params["TZID"]; tzid != "" {
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			loc = zone
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses an RFC 5545 DURATION value such as PT1H30M or P1D
+func parseDuration(value string) (time.Duration, error) {
+	match := durationPattern.FindStringSubmatch(strings.ToUpper(value))
+	if match == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("malformed duration %q", value)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if match[i+2] != "" {
+			n, _ := strconv.Atoi(match[i+2])
+			d += time.Duration(n) * unit
+		}
+	}
+	if match[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(value)
+}
@@ -0,0 +1,92 @@
+package ical
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VTIMEZONE\r\nTZID:Asia/Kolkata\r\nBEGIN:STANDARD\r\nDTSTART:19700101T000000\r\nEND:STANDARD\r\nEND:VTIMEZONE\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:abc@example.com\r\n" +
+		"DTSTART;TZID=\"Asia/Kolkata\":20250310T093000\r\n" +
+		"DURATION:PT1H30M\r\n" +
+		"SUMMARY:Board meeting\\, Q1\r\n" +
+		"DESCRIPTION:Agenda to fol\r\n low\r\n" +
+		"BEGIN:VALARM\r\nTRIGGER:-PT15M\r\nDESCRIPTION:Reminder\r\nEND:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:holiday@example.com\r\n" +
+		"DTSTART;VALUE=DATE:20250314\r\n" +
+		"TRANSP:TRANSPARENT\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:utc@example.com\r\n" +
+		"DTSTART:20250311T040000Z\r\n" +
+		"DTEND:20250311T050000Z\r\n" +
+		"STATUS:cancelled\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events, err := Parse([]byte(data), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	assert.Equal(t, "abc@example.com", events[0].UID)
+	assert.True(t, events[0].Start.Equal(time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 90*time.Minute, events[0].End.Sub(events[0].Start))
+	assert.Equal(t, "Board meeting, Q1", events[0].Summary)
+	assert.Equal(t, "Agenda to follow", events[0].Description)
+
+	assert.True(t, events[1].Start.Equal(time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 24*time.Hour, events[1].End.Sub(events[1].Start))
+	assert.True(t, events[1].Transparent)
+
+	assert.Equal(t, StatusCancelled, events[2].Status)
+	assert.Equal(t, time.Hour, events[2].End.Sub(events[2].Start))
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	start := time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC)
+	calendar := &Calendar{ProdID: "-//Test//Test//EN", Events: []Event{{
+		UID:         "appointment-7@example.com",
+		Sequence:    3,
+		Start:       start,
+		End:         start.Add(45 * time.Minute),
+		Summary:     "Call; follow-up",
+		Description: "Line one\nLine two",
+	}}}
+
+	events, err := Parse(calendar.Bytes(), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "appointment-7@example.com", events[0].UID)
+	assert.Equal(t, 3, events[0].Sequence)
+	assert.True(t, events[0].End.Equal(start.Add(45*time.Minute)))
+	assert.Equal(t, "Call; follow-up", events[0].Summary)
+	assert.Equal(t, "Line one\nLine two", events[0].Description)
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"PT15M":    15 * time.Minute,
+		"P1D":      24 * time.Hour,
+		"P1W":      7 * 24 * time.Hour,
+		"-PT1H":    -time.Hour,
+		"P1DT2H3S": 26*time.Hour + 3*time.Second,
+	}
+	for value, want := range cases {
+		got, err := parseDuration(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+	for _, value := range []string{"P", "PT", "1H", "P1H"} {
+		_, err := parseDuration(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,164 @@
+package mailer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported delivery drivers
+const (
+	DriverSMTP   = "smtp"   // Deliver through an SMTP relay
+	DriverFile   = "file"   // Write .eml files to a local directory
+	DriverMemory = "memory" // Keep messages in memory, for tests
+)
+
+// Address is an email address with an optional display name
+type Address struct {
+	Name  string
+	Email string
+}
+
+// String formats the address for a message header
+func (a Address) String() string {
+	if a.Name == "" {
+		return "<" + a.Email + ">"
+	}
+	return fmt.Sprintf("%s <%s>", encodeHeader(a.Name), a.Email)
+}
+
+// Message is a single outbound email
+type Message struct {
+	MessageID   string // Message-ID without angle brackets
+	InReplyTo   string // Message-ID of the message being replied to, if any
+	From        Address
+	To          []Address
+	CC          []Address
+	BCC         []Address
+	Subject     string
+	HTMLBody    string
+	PlainBody   string
+	Headers     map[string]string // Additional headers, e.g. X-Communication-ID
+	Attachments []Attachment
+	Date        time.Time
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename    string
+	ContentType string // May carry parameters, e.g. text/calendar; charset=UTF-8; method=REQUEST
+	Data        []byte
+}
+
+// Recipients returns every envelope recipient, including BCC
+func (m *Message) Recipients() []string {
+	var recipients []string
+	for _, list := range [][]Address{m.To, m.CC, m.BCC} {
+		for _, address := range list {
+			recipients = append(recipients, address.Email)
+		}
+	}
+	return recipients
+}
+
+// Mailer delivers messages
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// Config configures the mailer built by New
+type Config struct {
+	Driver    string
+	Host      string
+	Port      int
+	Username  string
+	Password  string
+	Directory string // Output directory of the file driver
+	Timeout   time.Duration
+}
+
+// LoadConfig reads the mailer configuration from the environment. Without SMTP_HOST the file driver is used.
+func LoadConfig() Config {
+	config := Config{
+		Driver:    os.Getenv("MAIL_DRIVER"),
+		Host:      os.Getenv("SMTP_HOST"),
+		Port:      587,
+		Username:  os.Getenv("SMTP_USERNAME"),
+		Password:  os.Getenv("SMTP_PASSWORD"),
+		Directory: "./mail",
+		Timeout:   30 * time.Second,
+	}
+
+	if port, err := strconv.Atoi(os.Getenv("SMTP_PORT")); err == nil && port > 0 {
+		config.Port = port
+	}
+	if dir := os.Getenv("MAIL_SINK_DIR"); dir != "" {
+		config.Directory = dir
+	}
+	if config.Driver == "" {
+		config.Driver = DriverFile
+		if config.Host != "" {
+			config.Driver = DriverSMTP
+		}
+	}
+
+	return config
+}
+
+// New creates the mailer selected by the configuration
+func New(config Config) (Mailer, error) {
+	switch config.Driver {
+	case DriverSMTP:
+		if config.Host == "" {
+			return nil, fmt.Errorf("smtp driver requires SMTP_HOST")
+		}
+		return NewSMTPMailer(config), nil
+	case DriverFile:
+		return NewFileMailer(config.Directory)
+	case DriverMemory:
+		return NewMemoryMailer(), nil
+	}
+	return nil, fmt.Errorf("unsupported mail driver: %s", config.Driver)
+}
+
+// NewMessageID generates a unique Message-ID for the given sender domain
+func NewMessageID(fromEmail string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(fromEmail, "@"); at >= 0 && at < len(fromEmail)-1 {
+		domain = fromEmail[at+1:]
+	}
+	return fmt.Sprintf("%d.%s@%s", time.Now().UnixNano(), hex.EncodeToString(buf), domain), nil
+}
+
+// IsPermanent reports whether a delivery error will not go away on retry, such as an SMTP 5xx rejection
+func IsPermanent(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 500
+	}
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// PermanentError marks an error that retrying cannot fix
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,146 @@
+package mailer
+
+import (
+	"errors"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMessageBytesMultipart(t *testing.T) {
+	msg := &Message{
+		MessageID: "abc@example.com",
+		InReplyTo: "parent@example.com",
+		From:      Address{Name: "Sales Team", Email: "sales@example.com"},
+		To:        []Address{{Email: "jane@example.com"}},
+		BCC:       []Address{{Email: "audit@example.com"}},
+		Subject:   "Café hours",
+		HTMLBody:  "<p>Hello</p>",
+		PlainBody: "Hello",
+		Headers:   map[string]string{"X-Communication-ID": "42"},
+	}
+
+	data, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	out := string(data)
+
+	for _, want := range []string{
+		"From: Sales Team <sales@example.com>\r\n",
+		"To: <jane@example.com>\r\n",
+		"Subject: =?UTF-8?q?Caf=C3=A9_hours?=\r\n",
+		"Message-ID: <abc@example.com>\r\n",
+		"In-Reply-To: <parent@example.com>\r\n",
+		"X-Communication-ID: 42\r\n",
+		"multipart/alternative",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Type: text/html; charset=UTF-8",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("message missing %q", want)
+		}
+	}
+	if strings.Contains(out, "audit@example.com") {
+		t.Errorf("BCC recipient leaked into headers")
+	}
+	if got := msg.Recipients(); len(got) != 2 || got[1] != "audit@example.com" {
+		t.Errorf("Recipients() = %v", got)
+	}
+}
+
+func TestMessageBytesAttachments(t *testing.T) {
+	msg := &Message{
+		From:        Address{Email: "sales@example.com"},
+		To:          []Address{{Email: "jane@example.com"}},
+		Subject:     "Invitation",
+		PlainBody:   "See attached",
+		Attachments: []Attachment{{Filename: "invite.ics", ContentType: "text/calendar; method=REQUEST", Data: []byte("BEGIN:VCALENDAR")}},
+	}
+
+	data, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	out := string(data)
+
+	for _, want := range []string{
+		"Content-Type: multipart/mixed; boundary=",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Type: text/calendar; method=REQUEST; name=\"invite.ics\"\r\n",
+		"Content-Disposition: attachment; filename=\"invite.ics\"\r\n",
+		"QkVHSU46VkNBTEVOREFS\r\n", // base64 of BEGIN:VCALENDAR
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("message missing %q", want)
+		}
+	}
+}
+
+func TestSinks(t *testing.T) {
+	msg := &Message{MessageID: "m1@example.com", From: Address{Email: "a@example.com"}, To: []Address{{Email: "b@example.com"}}, PlainBody: "hi"}
+
+	memory := NewMemoryMailer()
+	if err := memory.Send(msg); err != nil {
+		t.Fatalf("memory Send() error = %v", err)
+	}
+	if len(memory.Messages()) != 1 {
+		t.Errorf("expected one recorded message")
+	}
+	memory.FailWith(errors.New("unavailable"))
+	if err := memory.Send(msg); err == nil {
+		t.Errorf("expected FailWith error")
+	}
+
+	dir := t.TempDir()
+	file, err := NewFileMailer(dir)
+	if err != nil {
+		t.Fatalf("NewFileMailer() error = %v", err)
+	}
+	if err := file.Send(msg); err != nil {
+		t.Fatalf("file Send() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "m1_example.com.eml")); err != nil {
+		t.Errorf("expected .eml file: %v", err)
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	if !IsPermanent(&textproto.Error{Code: 550, Msg: "mailbox unavailable"}) {
+		t.Errorf("5xx should be permanent")
+	}
+	if IsPermanent(&textproto.Error{Code: 421, Msg: "try again later"}) {
+		t.Errorf("4xx should be temporary")
+	}
+	if _, err := (&Message{}).Bytes(); !IsPermanent(err) {
+		t.Errorf("message without recipients should be permanent")
+	}
+}
+
+func TestTrackerInstrumentsLinks(t *testing.T) {
+	tracker := NewTracker("https://crm.example.com/api/v1/track/", []byte("secret"))
+	body := `<html><body><a class="cta" href="https://example.com/offer?a=1&amp;b=2">Offer</a> <a href="mailto:x@example.com">Mail</a></body></html>`
+
+	out := tracker.InstrumentHTML(7, body)
+
+	clickURL := tracker.ClickURL(7, "https://example.com/offer?a=1&b=2")
+	if !strings.Contains(out, `href="`+strings.ReplaceAll(clickURL, "&", "&amp;")+`"`) {
+		t.Errorf("link was not rewritten: %s", out)
+	}
+	if !strings.Contains(out, `href="mailto:x@example.com"`) {
+		t.Errorf("non-http link should be left alone")
+	}
+	if !strings.Contains(out, tracker.OpenURL(7)+`" width="1"`) || !strings.HasSuffix(out, "</body></html>") {
+		t.Errorf("pixel not inserted before </body>: %s", out)
+	}
+
+	signature := strings.Split(strings.TrimPrefix(clickURL, "https://crm.example.com/api/v1/track/click/7/"), "?")[0]
+	if !tracker.VerifyClick(7, "https://example.com/offer?a=1&b=2", signature) {
+		t.Errorf("valid click signature rejected")
+	}
+	if tracker.VerifyClick(7, "https://evil.example.com", signature) || tracker.VerifyClick(8, "https://example.com/offer?a=1&b=2", signature) {
+		t.Errorf("click signature accepted for another target or communication")
+	}
+}
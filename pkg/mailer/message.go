@@ -0,0 +1,155 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Bytes renders the message as RFC 5322 text. BCC recipients are never written to the headers.
+func (m *Message) Bytes() ([]byte, error) {
+	if len(m.To) == 0 {
+		return nil, &PermanentError{Err: fmt.Errorf("message has no recipients")}
+	}
+
+	var buf bytes.Buffer
+	date := m.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	writeHeader := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	writeHeader("From", m.From.String())
+	writeHeader("To", joinAddresses(m.To))
+	if len(m.CC) > 0 {
+		writeHeader("Cc", joinAddresses(m.CC))
+	}
+	writeHeader("Subject", encodeHeader(m.Subject))
+	writeHeader("Date", date.Format(time.RFC1123Z))
+	if m.MessageID != "" {
+		writeHeader("Message-ID", "<"+m.MessageID+">")
+	}
+	if m.InReplyTo != "" {
+		writeHeader("In-Reply-To", "<"+m.InReplyTo+">")
+		writeHeader("References", "<"+m.InReplyTo+">")
+	}
+	names := make([]string, 0, len(m.Headers))
+	for name := range m.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeHeader(name, encodeHeader(m.Headers[name]))
+	}
+	writeHeader("MIME-Version", "1.0")
+
+	if len(m.Attachments) == 0 {
+		if err := m.writeContent(&buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	boundary, err := newBoundary()
+	if err != nil {
+		return nil, err
+	}
+	writeHeader("Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", boundary))
+	buf.WriteString("\r\n")
+	fmt.Fprintf(&buf, "--%s\r\n", boundary)
+	if err := m.writeContent(&buf); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\r\n")
+	for _, attachment := range m.Attachments {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		writeAttachment(&buf, attachment)
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+// writeContent writes the Content-Type header and body of the message text: multipart/alternative when
+// there are both HTML and plain bodies, otherwise a single part
+func (m *Message) writeContent(buf *bytes.Buffer) error {
+	if m.HTMLBody != "" && m.PlainBody != "" {
+		boundary, err := newBoundary()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain", m.PlainBody},
+			{"text/html", m.HTMLBody},
+		} {
+			fmt.Fprintf(buf, "--%s\r\n", boundary)
+			if err := writeBody(buf, part.contentType, part.body); err != nil {
+				return err
+			}
+			buf.WriteString("\r\n")
+		}
+		fmt.Fprintf(buf, "--%s--\r\n", boundary)
+		return nil
+	}
+	if m.HTMLBody != "" {
+		return writeBody(buf, "text/html", m.HTMLBody)
+	}
+	return writeBody(buf, "text/plain", m.PlainBody)
+}
+
+// writeAttachment writes an attachment part with a base64 body wrapped at 76 characters
+func writeAttachment(buf *bytes.Buffer, attachment Attachment) {
+	fmt.Fprintf(buf, "Content-Type: %s; name=%q\r\n", attachment.ContentType, attachment.Filename)
+	fmt.Fprintf(buf, "Content-Disposition: attachment; filename=%q\r\n", attachment.Filename)
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
+
+// writeBody writes the part headers and a quoted-printable encoded body
+func writeBody(buf *bytes.Buffer, contentType, body string) error {
+	fmt.Fprintf(buf, "Content-Type: %s; charset=UTF-8\r\n", contentType)
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	writer := quotedprintable.NewWriter(buf)
+	if _, err := writer.Write([]byte(strings.ReplaceAll(body, "\r\n", "\n"))); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func joinAddresses(addresses []Address) string {
+	formatted := make([]string, len(addresses))
+	for i, address := range addresses {
+		formatted[i] = address.String()
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// encodeHeader Q-encodes header values containing non-ASCII characters
+func encodeHeader(value string) string {
+	return mime.QEncoding.Encode("UTF-8", value)
+}
+
+func newBoundary() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "contact-service-" + hex.EncodeToString(buf), nil
+}
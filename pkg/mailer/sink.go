@@ -0,0 +1,79 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileMailer writes every message to a directory as an .eml file instead of delivering it
+type FileMailer struct {
+	directory string
+}
+
+// NewFileMailer creates a file mailer, creating the directory if needed
+func NewFileMailer(directory string) (*FileMailer, error) {
+	if err := os.MkdirAll(directory, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %v", err)
+	}
+	return &FileMailer{directory: directory}, nil
+}
+
+// Send writes a message to the directory
+func (m *FileMailer) Send(msg *Message) error {
+	body, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	name := strings.NewReplacer("@", "_", "/", "_").Replace(msg.MessageID)
+	if name == "" {
+		name = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	path := filepath.Join(m.directory, name+".eml")
+	return os.WriteFile(path, body, 0o640)
+}
+
+// MemoryMailer keeps sent messages in memory. An error set with FailWith is returned by every Send.
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+// NewMemoryMailer creates a memory mailer
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// Send records a message
+func (m *MemoryMailer) Send(msg *Message) error {
+	if _, err := msg.Bytes(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, *msg)
+	return nil
+}
+
+// Messages returns the messages sent so far
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
+
+// FailWith makes subsequent sends fail with err; nil restores delivery
+func (m *MemoryMailer) FailWith(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
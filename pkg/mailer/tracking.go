@@ -0,0 +1,68 @@
+package mailer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// trackedLink matches absolute http(s) hrefs of anchor tags
+var trackedLink = regexp.MustCompile(`(?i)(<a\b[^>]*?\bhref\s*=\s*)(["'])(https?://[^"']+)(["'])`)
+
+// Tracker signs open-pixel and click-redirect URLs and instruments outgoing HTML with them
+type Tracker struct {
+	baseURL string
+	secret  []byte
+}
+
+// NewTracker creates a tracker whose URLs point at baseURL, e.g. https://contacts.example.com/api/v1/track
+func NewTracker(baseURL string, secret []byte) *Tracker {
+	return &Tracker{baseURL: strings.TrimRight(baseURL, "/"), secret: secret}
+}
+
+// OpenURL returns the tracking pixel URL of a communication
+func (t *Tracker) OpenURL(communicationID uint) string {
+	return fmt.Sprintf("%s/open/%d/%s", t.baseURL, communicationID, t.sign("open", communicationID, ""))
+}
+
+// ClickURL returns a signed redirect URL to target for a communication
+func (t *Tracker) ClickURL(communicationID uint, target string) string {
+	return fmt.Sprintf("%s/click/%d/%s?url=%s", t.baseURL, communicationID, t.sign("click", communicationID, target), url.QueryEscape(target))
+}
+
+// VerifyOpen checks the signature of a tracking pixel request
+func (t *Tracker) VerifyOpen(communicationID uint, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(t.sign("open", communicationID, "")))
+}
+
+// VerifyClick checks the signature of a click redirect, so the endpoint cannot be used as an open redirect
+func (t *Tracker) VerifyClick(communicationID uint, target, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(t.sign("click", communicationID, target)))
+}
+
+// InstrumentHTML rewrites absolute links through the click redirect and appends the open pixel
+func (t *Tracker) InstrumentHTML(communicationID uint, body string) string {
+	body = trackedLink.ReplaceAllStringFunc(body, func(tag string) string {
+		parts := trackedLink.FindStringSubmatch(tag)
+		target := html.UnescapeString(parts[3])
+		return parts[1] + parts[2] + html.EscapeString(t.ClickURL(communicationID, target)) + parts[4]
+	})
+
+	pixel := fmt.Sprintf(`<img src="%s" width="1" height="1" alt="" style="display:none">`, html.EscapeString(t.OpenURL(communicationID)))
+	if i := strings.LastIndex(strings.ToLower(body), "</body>"); i >= 0 {
+		return body[:i] + pixel + body[i:]
+	}
+	return body + pixel
+}
+
+func (t *Tracker) sign(kind string, communicationID uint, target string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(kind + ":" + strconv.FormatUint(uint64(communicationID), 10) + ":" + target))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:18])
+}
@@ -0,0 +1,122 @@
+// Package passwords checks new passwords against a password policy: a minimum
+// length, a list of known breached passwords and the user's previous
+// passwords.
+//
+// Breached lists are local files with one entry per line. An entry is either a
+// password in plain text, compared case-insensitively, or the hex SHA-1 of a
+// password with an optional ":count" suffix, as in the Pwned Passwords dumps.
+// Blank lines and lines starting with # are ignored.
+package passwords
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MaxLength is the longest password accepted; bcrypt ignores bytes after the 72nd
+const MaxLength = 72
+
+// BreachedList is a set of known breached passwords
+type BreachedList struct {
+	plain map[string]struct{}
+	sha1  map[string]struct{}
+}
+
+// LoadBreachedList reads a breached password file
+func LoadBreachedList(path string) (*BreachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %v", err)
+	}
+	defer file.Close()
+
+	list := &BreachedList{
+		plain: make(map[string]struct{}),
+		sha1:  make(map[string]struct{}),
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		list.add(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %v", err)
+	}
+	return list, nil
+}
+
+func (l *BreachedList) add(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	hash := line
+	if i := strings.IndexByte(line, ':'); i == 40 {
+		hash = line[:i]
+	}
+	if len(hash) == 40 {
+		if _, err := hex.DecodeString(hash); err == nil {
+			l.sha1[strings.ToLower(hash)] = struct{}{}
+			return
+		}
+	}
+	l.plain[strings.ToLower(line)] = struct{}{}
+}
+
+// Contains reports whether the password is on the list
+func (l *BreachedList) Contains(password string) bool {
+	if l == nil {
+		return false
+	}
+	if _, ok := l.plain[strings.ToLower(password)]; ok {
+		return true
+	}
+	sum := sha1.Sum([]byte(password))
+	_, ok := l.sha1[hex.EncodeToString(sum[:])]
+	return ok
+}
+
+// Len is the number of entries on the list
+func (l *BreachedList) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.plain) + len(l.sha1)
+}
+
+// Policy is what a new password has to satisfy
+type Policy struct {
+	MinLength int           // In characters
+	Breached  *BreachedList // Nil when no list is configured
+}
+
+// Check returns why a password violates the policy, or nil
+func (p Policy) Check(password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return fmt.Errorf("password must be at least %d characters", p.MinLength)
+	}
+	if len(password) > MaxLength {
+		return fmt.Errorf("password must be at most %d bytes", MaxLength)
+	}
+	if p.Breached.Contains(password) {
+		return fmt.Errorf("password appears in a list of breached passwords; choose another")
+	}
+	return nil
+}
+
+// MatchesAny reports whether the password is the one behind any of the bcrypt hashes
+func MatchesAny(password string, hashes []string) bool {
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return true
+		}
+	}
+	return false
+}
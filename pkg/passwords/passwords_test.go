@@ -0,0 +1,91 @@
+package passwords
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func writeList(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadBreachedList(t *testing.T) {
+	// 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8 is SHA-1("password")
+	list, err := LoadBreachedList(writeList(t, strings.Join([]string{
+		"# common passwords",
+		"",
+		"Summer2024!",
+		"  letmein123  ",
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493",
+	}, "\n")))
+	if err != nil {
+		t.Fatalf("LoadBreachedList() error = %v", err)
+	}
+
+	if list.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", list.Len())
+	}
+	for _, password := range []string{"Summer2024!", "summer2024!", "letmein123", "password"} {
+		if !list.Contains(password) {
+			t.Errorf("Contains(%q) = false", password)
+		}
+	}
+	for _, password := range []string{"Password", "correct horse battery", "# common passwords"} {
+		if list.Contains(password) {
+			t.Errorf("Contains(%q) = true", password)
+		}
+	}
+
+	if _, err := LoadBreachedList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	list, err := LoadBreachedList(writeList(t, "letmein12345\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := Policy{MinLength: 10, Breached: list}
+
+	if err := policy.Check("correct horse"); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	for _, password := range []string{"short", "äääää", "LetMeIn12345", strings.Repeat("x", 73)} {
+		if err := policy.Check(password); err == nil {
+			t.Errorf("Check(%q) accepted", password)
+		}
+	}
+
+	// Length counts characters, not bytes
+	if err := (Policy{MinLength: 10}).Check("ääääääääää"); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+}
+
+func TestMatchesAny(t *testing.T) {
+	var hashes []string
+	for _, password := range []string{"first password", "second password"} {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashes = append(hashes, string(hash))
+	}
+
+	if !MatchesAny("second password", hashes) {
+		t.Error("MatchesAny() = false for a previous password")
+	}
+	if MatchesAny("third password", hashes) || MatchesAny("first password", nil) {
+		t.Error("MatchesAny() = true for a new password")
+	}
+}
@@ -0,0 +1,445 @@
+// Package rrule parses and expands RFC 5545 recurrence rules.
+//
+// The supported subset covers what appointment series need: FREQ (DAILY, WEEKLY,
+// MONTHLY, YEARLY), INTERVAL, BYDAY (with ordinals for MONTHLY, e.g. -1FR),
+// BYMONTHDAY, COUNT, UNTIL and WKST. Occurrences keep the wall-clock time of
+// DTSTART in its location, so a 09:00 weekly meeting stays at 09:00 across DST.
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ rule part
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum is a BYDAY entry; N is the optional ordinal within the month (1 = first, -1 = last, 0 = every)
+type WeekdayNum struct {
+	Day time.Weekday
+	N   int
+}
+
+// Rule is a parsed recurrence rule
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      time.Time // Zero when the rule has no UNTIL
+	WeekStart  time.Weekday
+}
+
+// Upper bound on the periods scanned while looking for occurrences, so sparse or
+// contradictory rules (e.g. BYMONTHDAY=31 with INTERVAL=12 from February) terminate
+const maxPeriods = 10000
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+var untilLayouts = []string{"20060102T150405Z", "20060102T150405", "20060102"}
+
+// Parse parses an RRULE value such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10".
+// A leading "RRULE:" is accepted. UNTIL values without a trailing Z are read as UTC.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "RRULE:")
+	if value == "" {
+		return nil, fmt.Errorf("empty recurrence rule")
+	}
+
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("malformed rule part %q", part)
+		}
+		key = strings.ToUpper(strings.TrimSpace(key))
+		val = strings.ToUpper(strings.TrimSpace(val))
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate rule part %s", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			switch Frequency(val) {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = Frequency(val)
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %s", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("INTERVAL must be a positive integer")
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("COUNT must be a positive integer")
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, item := range strings.Split(val, ",") {
+				day, err := parseWeekdayNum(item)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, item := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(item)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %s", item)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "WKST":
+			day, ok := weekdayCodes[val]
+			if !ok {
+				return nil, fmt.Errorf("invalid WKST %s", val)
+			}
+			rule.WeekStart = day
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot both be set")
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != Monthly {
+			return nil, fmt.Errorf("BYDAY ordinals are only supported with FREQ=MONTHLY")
+		}
+	}
+	if len(rule.ByMonthDay) > 0 && rule.Freq == Weekly {
+		return nil, fmt.Errorf("BYMONTHDAY cannot be used with FREQ=WEEKLY")
+	}
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range untilLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// A date-only UNTIL includes that whole day
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %s", value)
+}
+
+func parseWeekdayNum(value string) (WeekdayNum, error) {
+	if len(value) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %s", value)
+	}
+	day, ok := weekdayCodes[value[len(value)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %s", value)
+	}
+	result := WeekdayNum{Day: day}
+	if prefix := value[:len(value)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY %s", value)
+		}
+		result.N = n
+	}
+	return result, nil
+}
+
+// String formats the rule in canonical RRULE form (without the "RRULE:" prefix)
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayouts[0]))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCode(r.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+// String formats the entry as in BYDAY, e.g. "MO" or "-1FR"
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayCode(w.Day)
+	}
+	return strconv.Itoa(w.N) + weekdayCode(w.Day)
+}
+
+func weekdayCode(day time.Weekday) string {
+	for code, d := range weekdayCodes {
+		if d == day {
+			return code
+		}
+	}
+	return ""
+}
+
+// Between returns the occurrences that start in [after, before), in order.
+// DTSTART is always the first occurrence. Excluded start times are skipped but
+// still count towards COUNT, as RFC 5545 applies EXDATE after expansion.
+func (r *Rule) Between(dtstart, after, before time.Time, exdates []time.Time) []time.Time {
+	var result []time.Time
+	r.iterate(dtstart, func(occurrence time.Time) bool {
+		if !occurrence.Before(before) {
+			return false
+		}
+		if !occurrence.Before(after) && !isExcluded(occurrence, exdates) {
+			result = append(result, occurrence)
+		}
+		return true
+	})
+	return result
+}
+
+// Next returns the first occurrence strictly after t, or false when the rule has ended
+func (r *Rule) Next(dtstart, t time.Time, exdates []time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	r.iterate(dtstart, func(occurrence time.Time) bool {
+		if occurrence.After(t) && !isExcluded(occurrence, exdates) {
+			next, found = occurrence, true
+			return false
+		}
+		return true
+	})
+	return next, found
+}
+
+// CountBefore returns how many occurrences, excluded ones included, start before t.
+// It is used to carry COUNT over when a series is split.
+func (r *Rule) CountBefore(dtstart, t time.Time) int {
+	count := 0
+	r.iterate(dtstart, func(occurrence time.Time) bool {
+		if !occurrence.Before(t) {
+			return false
+		}
+		count++
+		return true
+	})
+	return count
+}
+
+func isExcluded(occurrence time.Time, exdates []time.Time) bool {
+	for _, exdate := range exdates {
+		if occurrence.Equal(exdate) {
+			return true
+		}
+	}
+	return false
+}
+
+// iterate calls yield with each occurrence in order until it returns false or the rule ends
+func (r *Rule) iterate(dtstart time.Time, yield func(time.Time) bool) {
+	emitted := 0
+	emit := func(occurrence time.Time) bool {
+		if !r.Until.IsZero() && occurrence.After(r.Until) {
+			return false
+		}
+		if r.Count > 0 && emitted >= r.Count {
+			return false
+		}
+		emitted++
+		return yield(occurrence)
+	}
+
+	if !emit(dtstart) {
+		return
+	}
+	for period := 0; period < maxPeriods; period++ {
+		for _, candidate := range r.periodCandidates(dtstart, period) {
+			if !candidate.After(dtstart) {
+				continue
+			}
+			if !emit(candidate) {
+				return
+			}
+		}
+	}
+}
+
+// periodCandidates returns the sorted occurrences generated by the n-th period (day, week, month or year) of the rule
+func (r *Rule) periodCandidates(dtstart time.Time, n int) []time.Time {
+	step := n * r.Interval
+	y, m, d := dtstart.Date()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+	}
+
+	var candidates []time.Time
+	switch r.Freq {
+	case Daily:
+		day := at(y, m, d+step)
+		if r.matchesWeekday(day) && r.matchesMonthDay(day) {
+			candidates = append(candidates, day)
+		}
+
+	case Weekly:
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := at(y, m, d-offset+7*step)
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []WeekdayNum{{Day: dtstart.Weekday()}}
+		}
+		for _, wd := range days {
+			delta := (int(wd.Day) - int(r.WeekStart) + 7) % 7
+			candidates = append(candidates, weekStart.AddDate(0, 0, delta))
+		}
+
+	case Monthly:
+		first := time.Date(y, m+time.Month(step), 1, 0, 0, 0, 0, dtstart.Location())
+		candidates = r.monthCandidates(first.Year(), first.Month(), d, at)
+
+	case Yearly:
+		year := y + step
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			if d <= daysIn(year, m) {
+				candidates = append(candidates, at(year, m, d))
+			}
+		} else {
+			candidates = r.monthCandidates(year, m, d, at)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+	return dedupe(candidates)
+}
+
+// monthCandidates expands BYMONTHDAY and BYDAY within one month; without either, the DTSTART day is used
+func (r *Rule) monthCandidates(year int, month time.Month, dtstartDay int, at func(int, time.Month, int) time.Time) []time.Time {
+	length := daysIn(year, month)
+	var candidates []time.Time
+
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, md := range r.ByMonthDay {
+			day := md
+			if md < 0 {
+				day = length + md + 1
+			}
+			if day < 1 || day > length {
+				continue
+			}
+			candidate := at(year, month, day)
+			if r.matchesWeekday(candidate) {
+				candidates = append(candidates, candidate)
+			}
+		}
+	case len(r.ByDay) > 0:
+		for _, wd := range r.ByDay {
+			var matches []time.Time
+			for day := 1; day <= length; day++ {
+				candidate := at(year, month, day)
+				if candidate.Weekday() == wd.Day {
+					matches = append(matches, candidate)
+				}
+			}
+			switch {
+			case wd.N == 0:
+				candidates = append(candidates, matches...)
+			case wd.N > 0 && wd.N <= len(matches):
+				candidates = append(candidates, matches[wd.N-1])
+			case wd.N < 0 && -wd.N <= len(matches):
+				candidates = append(candidates, matches[len(matches)+wd.N])
+			}
+		}
+	default:
+		if dtstartDay <= length {
+			candidates = append(candidates, at(year, month, dtstartDay))
+		}
+	}
+	return candidates
+}
+
+func (r *Rule) matchesWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Day == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	length := daysIn(t.Year(), t.Month())
+	for _, md := range r.ByMonthDay {
+		if md == t.Day() || (md < 0 && length+md+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func dedupe(times []time.Time) []time.Time {
+	if len(times) < 2 {
+		return times
+	}
+	result := times[:1]
+	for _, t := range times[1:] {
+		if !t.Equal(result[len(result)-1]) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
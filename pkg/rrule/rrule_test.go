@@ -0,0 +1,106 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func dates(times []time.Time) []string {
+	out := make([]string, len(times))
+	for i, t := range times {
+		out[i] = t.Format("2006-01-02 15:04")
+	}
+	return out
+}
+
+func assertDates(t *testing.T, got []time.Time, want ...string) {
+	t.Helper()
+	g := dates(got)
+	if len(g) != len(want) {
+		t.Fatalf("got %v, want %v", g, want)
+	}
+	for i := range want {
+		if g[i] != want[i] {
+			t.Fatalf("got %v, want %v", g, want)
+		}
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	rule, err := Parse("RRULE:freq=weekly;interval=2;byday=MO,WE;until=20250301T000000Z")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got, want := rule.String(), "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;UNTIL=20250301T000000Z"; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+
+	for _, invalid := range []string{
+		"", "INTERVAL=2", "FREQ=HOURLY", "FREQ=DAILY;COUNT=3;UNTIL=20250101",
+		"FREQ=WEEKLY;BYDAY=1MO", "FREQ=DAILY;BYSETPOS=1", "FREQ=DAILY;COUNT=0",
+	} {
+		if _, err := Parse(invalid); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", invalid)
+		}
+	}
+}
+
+func TestWeeklyWithCountAndExdate(t *testing.T) {
+	rule, _ := Parse("FREQ=WEEKLY;BYDAY=TU,TH;COUNT=5")
+	start := time.Date(2025, 1, 7, 10, 0, 0, 0, time.UTC) // Tuesday
+	exdate := time.Date(2025, 1, 14, 10, 0, 0, 0, time.UTC)
+
+	got := rule.Between(start, start, start.AddDate(1, 0, 0), []time.Time{exdate})
+	assertDates(t, got, "2025-01-07 10:00", "2025-01-09 10:00", "2025-01-16 10:00", "2025-01-21 10:00")
+}
+
+func TestMonthlyLastFriday(t *testing.T) {
+	rule, _ := Parse("FREQ=MONTHLY;BYDAY=-1FR;COUNT=3")
+	start := time.Date(2025, 1, 31, 15, 0, 0, 0, time.UTC)
+
+	got := rule.Between(start, start, start.AddDate(1, 0, 0), nil)
+	assertDates(t, got, "2025-01-31 15:00", "2025-02-28 15:00", "2025-03-28 15:00")
+}
+
+func TestMonthlySkipsShortMonths(t *testing.T) {
+	rule, _ := Parse("FREQ=MONTHLY;UNTIL=20250601")
+	start := time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)
+
+	got := rule.Between(start, start, start.AddDate(1, 0, 0), nil)
+	assertDates(t, got, "2025-01-31 09:00", "2025-03-31 09:00", "2025-05-31 09:00")
+}
+
+func TestWallClockKeptAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+	rule, _ := Parse("FREQ=DAILY;INTERVAL=7")
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, loc)
+
+	got := rule.Between(start, start, time.Date(2025, 3, 20, 0, 0, 0, 0, loc), nil)
+	for _, occurrence := range got {
+		if occurrence.Hour() != 9 {
+			t.Errorf("occurrence %v lost its wall-clock time", occurrence)
+		}
+	}
+	if len(got) != 3 {
+		t.Errorf("got %d occurrences, want 3", len(got))
+	}
+}
+
+func TestNextAndCountBefore(t *testing.T) {
+	rule, _ := Parse("FREQ=DAILY;COUNT=3")
+	start := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	next, ok := rule.Next(start, start, nil)
+	if !ok || !next.Equal(start.AddDate(0, 0, 1)) {
+		t.Errorf("Next() = %v, %v", next, ok)
+	}
+	if _, ok := rule.Next(start, start.AddDate(0, 0, 2), nil); ok {
+		t.Errorf("Next() after the last occurrence should report the rule ended")
+	}
+	if got := rule.CountBefore(start, start.AddDate(0, 0, 2)); got != 2 {
+		t.Errorf("CountBefore() = %d, want 2", got)
+	}
+}
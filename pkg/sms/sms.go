@@ -0,0 +1,106 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"contact-service/pkg/logger"
+)
+
+// Supported SMS providers
+const (
+	ProviderTwilio = "twilio"
+	ProviderLog    = "log" // Log messages instead of sending them, for development
+)
+
+// Sender delivers text messages
+type Sender interface {
+	Send(to, body string) error
+}
+
+// Config configures the sender built by New
+type Config struct {
+	Provider   string
+	APIKey     string // Twilio account SID
+	APISecret  string // Twilio auth token
+	FromNumber string
+	Timeout    time.Duration
+}
+
+// LoadConfig reads the SMS configuration from the environment. Without credentials the log provider is used.
+func LoadConfig() Config {
+	config := Config{
+		Provider:   os.Getenv("SMS_PROVIDER"),
+		APIKey:     os.Getenv("SMS_API_KEY"),
+		APISecret:  os.Getenv("SMS_API_SECRET"),
+		FromNumber: os.Getenv("SMS_FROM_NUMBER"),
+		Timeout:    15 * time.Second,
+	}
+	if config.Provider == "" || config.APIKey == "" || config.APISecret == "" {
+		config.Provider = ProviderLog
+	}
+	return config
+}
+
+// New creates the sender selected by the configuration
+func New(config Config) (Sender, error) {
+	switch config.Provider {
+	case ProviderTwilio:
+		if config.FromNumber == "" {
+			return nil, fmt.Errorf("twilio provider requires SMS_FROM_NUMBER")
+		}
+		return &TwilioSender{config: config, client: &http.Client{Timeout: config.Timeout}}, nil
+	case ProviderLog:
+		return LogSender{}, nil
+	}
+	return nil, fmt.Errorf("unsupported sms provider: %s", config.Provider)
+}
+
+// TwilioSender sends messages through the Twilio Messages API
+type TwilioSender struct {
+	config Config
+	client *http.Client
+}
+
+// Send delivers a message
+func (s *TwilioSender) Send(to, body string) error {
+	endpoint := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", url.PathEscape(s.config.APIKey))
+	form := url.Values{"To": {to}, "From": {s.config.FromNumber}, "Body": {body}}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.config.APIKey, s.config.APISecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach twilio: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("twilio rejected message (status %d): %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// LogSender logs messages instead of sending them
+type LogSender struct{}
+
+// Send logs a message
+func (LogSender) Send(to, body string) error {
+	logger.Info("SMS not sent (log provider)", map[string]interface{}{
+		"to":     to,
+		"length": len(body),
+	})
+	return nil
+}
@@ -0,0 +1,84 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B, SHA1, truncated to 6 digits
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeMatchesRFC6238(t *testing.T) {
+	for unix, want := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		got, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("Code(T=%d) = %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	step, ok := Validate(rfcSecret, "081804", now, 1)
+	if !ok || step != Step(now) {
+		t.Errorf("Validate() = %d, %v; want step %d", step, ok, Step(now))
+	}
+
+	// The previous step is accepted within the skew, not beyond it
+	previous, _ := Code(rfcSecret, Step(now)-1)
+	if _, ok := Validate(rfcSecret, previous, now, 1); !ok {
+		t.Error("code of the previous step rejected with skew 1")
+	}
+	if _, ok := Validate(rfcSecret, previous, now, 0); ok {
+		t.Error("code of the previous step accepted with skew 0")
+	}
+
+	for _, code := range []string{"", "08180", "0818045", "abcdef", "000000"} {
+		if _, ok := Validate(rfcSecret, code, now, 1); ok {
+			t.Errorf("Validate(%q) accepted", code)
+		}
+	}
+	if _, ok := Validate("not base32!", "081804", now, 1); ok {
+		t.Error("invalid secret accepted")
+	}
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("secret length = %d, want 32", len(secret))
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Errorf("generated secret does not decode: %v", err)
+	}
+
+	uri, err := url.Parse(URI("Mejona CRM", "jane@example.com", secret))
+	if err != nil {
+		t.Fatalf("URI() does not parse: %v", err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" {
+		t.Errorf("URI = %s, want otpauth://totp/...", uri)
+	}
+	if uri.Path != "/Mejona CRM:jane@example.com" {
+		t.Errorf("label = %q", uri.Path)
+	}
+	if uri.Query().Get("secret") != secret || uri.Query().Get("issuer") != "Mejona CRM" {
+		t.Errorf("query = %v", uri.Query())
+	}
+}
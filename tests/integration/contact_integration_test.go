@@ -101,7 +101,7 @@ func (suite *ContactIntegrationTestSuite) setupRouter() {
 	
 	// Setup handlers
 	contactHandler := handlers.NewContactHandler(contactService)
-	authHandler := handlers.NewAuthHandler(nil) // Mock auth for tests
+	authHandler := handlers.NewAuthHandler(nil, nil, nil, nil) // Mock auth for tests
 
 	// Setup routes
 	api := suite.router.Group("/api/v1")